@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// annotateCmd attaches a searchable note to a code location. Notes are
+// embedded with the project's model and returned by search alongside code,
+// marked as annotations.
+var annotateCmd = &cobra.Command{
+	Use:   "annotate <file:line[-end]> <note>",
+	Short: "Attach a searchable note to a code location",
+	Long: `Attach a free-text note to a file:line or file:start-end location.
+
+The note is embedded with the project's embedding model and returned by
+semantic and hybrid searches next to code results, marked as an annotation
+(a "=== Annotation ===" block, or "annotation": true in JSON). Annotations are
+stored per project outside the index, so they survive re-indexing.`,
+	Example: `  vecgrep annotate internal/embed/throttle.go:40-60 "cache key must include the model"
+  vecgrep annotate list
+  vecgrep annotate remove 3`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: runAnnotate,
+}
+
+var annotateListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List annotations, optionally for one file",
+	Args:  cobra.NoArgs,
+	RunE:  runAnnotateList,
+}
+
+var annotateRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remove an annotation by ID",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAnnotateRemove,
+}
+
+func runAnnotate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	session, err := app.OpenReadOnlySession(ctx, "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	key, err := projectRelativeKey(session.ProjectRoot, args[0])
+	if err != nil {
+		return err
+	}
+	a, err := app.AddAnnotation(ctx, session.Config.DataDir, session.Provider, key, strings.Join(args[1:], " "))
+	if err != nil {
+		return fmt.Errorf("annotate failed: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Annotated %s (id %d)\n", a.Key, a.ID)
+	return nil
+}
+
+// annotationOutput is the JSON shape of `annotate list`; embeddings are
+// omitted.
+type annotationOutput struct {
+	ID        uint64 `json:"id"`
+	Key       string `json:"key"`
+	Text      string `json:"text"`
+	Model     string `json:"model"`
+	CreatedAt string `json:"created_at"`
+}
+
+func runAnnotateList(cmd *cobra.Command, args []string) error {
+	file, _ := cmd.Flags().GetString("file")
+	format, _ := cmd.Flags().GetString("format")
+
+	_, dataDir, err := resolveProjectDataDir()
+	if err != nil {
+		return err
+	}
+	annotations, err := app.ListAnnotations(dataDir, file)
+	if err != nil {
+		return fmt.Errorf("list annotations: %w", err)
+	}
+
+	w := cmd.OutOrStdout()
+	if format == "json" {
+		out := make([]annotationOutput, 0, len(annotations))
+		for _, a := range annotations {
+			out = append(out, annotationOutput{
+				ID:        a.ID,
+				Key:       a.Key,
+				Text:      a.Text,
+				Model:     a.Model,
+				CreatedAt: a.CreatedAt.Format(time.RFC3339),
+			})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	if len(annotations) == 0 {
+		fmt.Fprintln(w, "No annotations.")
+		return nil
+	}
+	for _, a := range annotations {
+		fmt.Fprintf(w, "%d. %s — %s\n", a.ID, a.Key, a.Text)
+	}
+	return nil
+}
+
+func runAnnotateRemove(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid annotation id %q", args[0])
+	}
+	_, dataDir, err := resolveProjectDataDir()
+	if err != nil {
+		return err
+	}
+	removed, err := app.RemoveAnnotation(dataDir, id)
+	if err != nil {
+		return fmt.Errorf("remove annotation: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("annotation %d not found", id)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed annotation %d\n", id)
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// exportCmd writes the project index to a portable archive.
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the index to a portable archive",
+	Long: `Write the project's chunks, metadata, and vectors to a gzip-compressed tar
+archive. Paths are stored relative to the project root, so a CI job can
+publish the archive as an artifact and developers can import it into their
+own checkout instead of re-embedding the repository.
+
+Use --output - to write the archive to stdout.`,
+	Example: `  vecgrep export --output index.tar.gz
+  vecgrep export -o - | gzip -t`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+// importCmd replaces the project index with an exported archive.
+var importCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Import an index archive written by 'vecgrep export'",
+	Long: `Replace the project's index with an archive written by 'vecgrep export'.
+The archive's embedding profile (provider, model, dimensions) must match the
+active configuration. File hashes are restored too, so a following
+'vecgrep index' only re-embeds files that differ from the exported checkout.
+
+An existing index is only replaced with --force. Use - to read the archive
+from stdin.`,
+	Example: `  vecgrep import index.tar.gz
+  vecgrep import index.tar.gz --force && vecgrep index`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	if output == "-" {
+		manifest, err := app.NewService(session).ExportIndex(cmd.Context(), os.Stdout)
+		if err != nil {
+			return fmt.Errorf("export index: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d chunks from %d files (%s)\n", manifest.Chunks, manifest.Files, manifest.Profile.Model)
+		return nil
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("create archive: %w", err)
+	}
+	manifest, err := app.NewService(session).ExportIndex(cmd.Context(), f)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(output)
+		return fmt.Errorf("export index: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d chunks from %d files (%s) to %s\n", manifest.Chunks, manifest.Files, manifest.Profile.Model, output)
+	return nil
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	force, _ := cmd.Flags().GetBool("force")
+
+	var r io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("open archive: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	session, err := app.OpenSession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	service := app.NewService(session)
+
+	if !force {
+		stats, err := session.DB.StatsForProject(session.ProjectRoot)
+		if err != nil {
+			return fmt.Errorf("get stats: %w", err)
+		}
+		if stats["chunks"] > 0 {
+			return fmt.Errorf("%s already has an index with %d chunks; re-run with --force to replace it", session.ProjectRoot, stats["chunks"])
+		}
+	}
+
+	manifest, err := service.ImportIndex(cmd.Context(), r)
+	if err != nil {
+		return fmt.Errorf("import index: %w", err)
+	}
+	fmt.Printf("Imported %d chunks from %d files into %s\n", manifest.Chunks, manifest.Files, session.ProjectRoot)
+	fmt.Printf("  Exported: %s by vecgrep %s\n", manifest.ExportedAt.Format("2006-01-02 15:04:05 MST"), manifest.VecgrepVersion)
+	fmt.Println("Run 'vecgrep index' to pick up local changes since the export.")
+	return nil
+}
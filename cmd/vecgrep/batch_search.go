@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+	"github.com/spf13/cobra"
+)
+
+// batchSearchCmd runs many queries in one session, like the
+// vecgrep_batch_search tool.
+var batchSearchCmd = &cobra.Command{
+	Use:   "batch-search",
+	Short: "Run many search queries and report results grouped by query",
+	Long: `Run many search queries against the index at once and report the results
+grouped by query, for scripted audits over a list of questions.
+
+Queries come from --queries-file, one per line (blank lines and lines starting
+with # are skipped; - reads standard input), and from repeated -q flags. They
+run concurrently with the same filters, and by default a chunk returned for an
+earlier query is left out of later ones (--dedupe none keeps every hit, file
+keeps one result per file across the batch).
+
+The report is Markdown by default, or a JSON document with --format json:
+
+  {"queries": [{"query": "...", "results": [...], "error": "..."}], "warnings": [...]}
+
+A query whose search fails is reported in its group and the command exits
+with an error once every query has run.`,
+	Example: `  vecgrep batch-search --queries-file audit.txt --format json > audit.json
+  vecgrep batch-search -q "where are passwords hashed" -q "SQL built from strings"`,
+	Args:         cobra.NoArgs,
+	RunE:         runBatchSearch,
+	SilenceUsage: true,
+}
+
+// batchSearchOutput is the JSON document batch-search writes.
+type batchSearchOutput struct {
+	Queries  []search.QueryResults `json:"queries"`
+	Warnings []string              `json:"warnings,omitempty"`
+}
+
+func runBatchSearch(cmd *cobra.Command, args []string) error {
+	queries, _ := cmd.Flags().GetStringArray("query")
+	if file, _ := cmd.Flags().GetString("queries-file"); file != "" {
+		fromFile, err := readBatchQueries(file, os.Stdin)
+		if err != nil {
+			return err
+		}
+		queries = append(fromFile, queries...)
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("no queries: pass --queries-file or -q")
+	}
+	format, _ := cmd.Flags().GetString("format")
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("invalid --format %q: expected markdown or json", format)
+	}
+	dedupeStr, _ := cmd.Flags().GetString("dedupe")
+	dedupe, err := search.ParseDedupeMode(dedupeStr)
+	if err != nil {
+		return err
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	if limit <= 0 || concurrency <= 0 {
+		return fmt.Errorf("--limit and --concurrency must be positive")
+	}
+	lang, _ := cmd.Flags().GetString("lang")
+	chunkType, _ := cmd.Flags().GetString("type")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	modeStr, _ := cmd.Flags().GetString("mode")
+	maxSnippetLines, _ := cmd.Flags().GetInt("max-snippet-lines")
+	outPath, _ := cmd.Flags().GetString("out")
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	resp, err := app.NewService(session).BatchSearch(cmd.Context(), app.BatchSearchRequest{
+		Queries: queries,
+		Search: app.SearchRequest{
+			Limit:     limit,
+			Language:  lang,
+			ChunkType: chunkType,
+			Tags:      tags,
+			Mode:      app.ParseSearchMode(modeStr, session.Config.Search.DefaultMode),
+		},
+		Dedupe:      dedupe,
+		Concurrency: concurrency,
+	})
+	if err != nil {
+		return err
+	}
+	for _, w := range resp.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	for _, group := range resp.Groups {
+		search.TruncateResults(group.Results, group.Query, maxSnippetLines)
+	}
+
+	out := io.Writer(os.Stdout)
+	var outFile *os.File
+	if outPath != "" {
+		if outFile, err = os.Create(outPath); err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer outFile.Close()
+		out = outFile
+	}
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(batchSearchOutput{Queries: resp.Groups, Warnings: resp.Warnings}); err != nil {
+			return fmt.Errorf("write results: %w", err)
+		}
+	} else {
+		markdown := search.MarkdownOptions{LinkBase: reportLinkBase(session.ProjectRoot, outPath)}
+		fmt.Fprint(out, search.FormatBatchMarkdownReport(resp.Groups, markdown))
+	}
+	if outFile != nil {
+		if err := outFile.Close(); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote results for %d queries to %s\n", len(resp.Groups), outPath)
+	}
+	if resp.Failed > 0 {
+		return fmt.Errorf("%d of %d queries failed", resp.Failed, len(resp.Groups))
+	}
+	return nil
+}
+
+// readBatchQueries reads one query per line from path, or from stdin when
+// path is "-", skipping blank lines and # comments.
+func readBatchQueries(path string, stdin io.Reader) ([]string, error) {
+	in := stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open queries file: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	var queries []string
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		queries = append(queries, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read queries: %w", err)
+	}
+	return queries, nil
+}
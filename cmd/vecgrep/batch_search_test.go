@@ -0,0 +1,29 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadBatchQueries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.txt")
+	if err := os.WriteFile(path, []byte("# audit\nwhere are passwords hashed\n\n  SQL built from strings  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"where are passwords hashed", "SQL built from strings"}
+
+	queries, err := readBatchQueries(path, nil)
+	if err != nil || !slices.Equal(queries, want) {
+		t.Fatalf("file queries = %q, %v; want %q", queries, err, want)
+	}
+	queries, err = readBatchQueries("-", strings.NewReader("where are passwords hashed\r\nSQL built from strings\n"))
+	if err != nil || !slices.Equal(queries, want) {
+		t.Fatalf("stdin queries = %q, %v; want %q", queries, err, want)
+	}
+	if _, err := readBatchQueries(filepath.Join(t.TempDir(), "missing.txt"), nil); err == nil {
+		t.Fatal("missing queries file should fail")
+	}
+}
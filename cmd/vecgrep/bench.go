@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+	"github.com/spf13/cobra"
+)
+
+// benchCmd measures indexing throughput and search latency on the current
+// project so indexing and HNSW parameters can be tuned against real code.
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure indexing throughput and search latency on this project",
+	Long: `Index the project from scratch into a temporary database and time it, then
+time searches against the result, so indexing.workers, embedding.batch_size,
+and the vector.veclite HNSW parameters can be tuned against this codebase.
+
+The report covers files and chunks per second, the latency of each
+embedding request the indexer made, and p50/p90/p99 search latency per mode.
+Search queries are symbol names drawn evenly from the index. Embeddings
+bypass the embedding cache, so semantic and hybrid latency include embedding
+the query. The project's own index is never written; the temporary database
+is removed when the run ends.
+
+With --search-only no index is built and searches run against the existing
+index; only --ef-search applies. Compare runs by changing one flag at a time.
+'vecgrep benchmark embeddings' compares embedding models instead.`,
+	Example: `  vecgrep bench
+  vecgrep bench --workers 8 --batch-size 64
+  vecgrep bench --hnsw-m 32 --ef-construction 400 --ef-search 200
+  vecgrep bench --search-only --mode semantic -f json`,
+	Args: cobra.NoArgs,
+	RunE: runBench,
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	flags := cmd.Flags()
+	format, _ := flags.GetString("format")
+	if format != "default" && format != "json" {
+		return fmt.Errorf("unsupported format %q (use default or json)", format)
+	}
+	opts := app.BenchOptions{}
+	opts.Workers, _ = flags.GetInt("workers")
+	opts.BatchSize, _ = flags.GetInt("batch-size")
+	opts.HNSWM, _ = flags.GetInt("hnsw-m")
+	opts.EfConstruction, _ = flags.GetInt("ef-construction")
+	opts.EfSearch, _ = flags.GetInt("ef-search")
+	opts.Queries, _ = flags.GetInt("queries")
+	opts.Limit, _ = flags.GetInt("limit")
+	opts.SearchOnly, _ = flags.GetBool("search-only")
+	modes, _ := flags.GetStringSlice("mode")
+	for _, mode := range modes {
+		switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+		case "keyword", "semantic", "hybrid":
+			opts.Modes = append(opts.Modes, search.SearchMode(mode))
+		default:
+			return fmt.Errorf("unsupported mode %q (use keyword, semantic, or hybrid)", mode)
+		}
+	}
+	if format == "default" && !opts.SearchOnly {
+		opts.Progress = func(p index.Progress) {
+			fmt.Fprintf(os.Stderr, "\rIndexing: %d/%d files, %d chunks", p.ProcessedFiles, p.QueuedFiles, p.TotalChunks)
+		}
+	}
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	report, err := app.NewService(session).Bench(cmd.Context(), opts)
+	if opts.Progress != nil {
+		fmt.Fprintln(os.Stderr)
+	}
+	if err != nil {
+		return fmt.Errorf("bench: %w", err)
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Printf("Benchmark of %s (%s %s)\n", report.ProjectRoot, report.Provider, report.Model)
+	params := report.Params
+	if report.Index != nil {
+		fmt.Printf("  workers=%d batch_size=%d hnsw_m=%d ef_construction=%d ef_search=%d\n\n",
+			params.Workers, params.BatchSize, params.HNSWM, params.EfConstruction, params.EfSearch)
+		idx := report.Index
+		fmt.Printf("Indexing: %d files, %d chunks in %.1fs (%.1f files/s, %.1f chunks/s)\n",
+			idx.Files, idx.Chunks, idx.DurationMS/1000, idx.FilesPerSec, idx.ChunksPerSec)
+		if idx.Errors > 0 {
+			fmt.Printf("  %d file(s) failed to index\n", idx.Errors)
+		}
+		fmt.Printf("  %-12s %8s %9s %9s %9s %9s\n", "", "requests", "mean", "p50", "p90", "p99")
+		fmt.Printf("  %-12s %8d %9s %9s %9s %9s\n", "embed", idx.Embed.Count,
+			benchMS(idx.Embed.MeanMS), benchMS(idx.Embed.P50MS), benchMS(idx.Embed.P90MS), benchMS(idx.Embed.P99MS))
+	} else {
+		fmt.Printf("  ef_search=%d (existing index)\n", params.EfSearch)
+	}
+
+	fmt.Printf("\nSearch:\n")
+	fmt.Printf("  %-12s %8s %9s %9s %9s %9s %8s\n", "mode", "queries", "mean", "p50", "p90", "p99", "results")
+	for _, s := range report.Search {
+		fmt.Printf("  %-12s %8d %9s %9s %9s %9s %8.1f\n", s.Mode, s.Latency.Count,
+			benchMS(s.Latency.MeanMS), benchMS(s.Latency.P50MS), benchMS(s.Latency.P90MS), benchMS(s.Latency.P99MS), s.MeanResults)
+	}
+	return nil
+}
+
+func benchMS(ms float64) string {
+	return fmt.Sprintf("%.1fms", ms)
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// bookmarkCmd is the parent for project bookmarks: pinned chunk locations
+// with a note and tags, recalled by listing rather than by re-querying.
+var bookmarkCmd = &cobra.Command{
+	Use:   "bookmark",
+	Short: "Pin and recall important chunks",
+	Long: `Pin chunk locations found during a search session so they can be
+recalled later regardless of how the original query was phrased.
+
+Bookmarks are keyed by location (file:start-end, as printed by search) and are
+stored per project next to the index.`,
+}
+
+var bookmarkAddCmd = &cobra.Command{
+	Use:   "add <file:start-end>",
+	Short: "Bookmark a chunk location",
+	Long: `Bookmark a chunk location. Re-bookmarking a pinned location updates its
+note and merges its tags.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBookmarkAdd,
+}
+
+var bookmarkListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List bookmarks, optionally filtered by tags (AND)",
+	Args:  cobra.NoArgs,
+	RunE:  runBookmarkList,
+}
+
+var bookmarkRemoveCmd = &cobra.Command{
+	Use:   "remove <id>",
+	Short: "Remove a bookmark by ID",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runBookmarkRemove,
+}
+
+// resolveProjectDataDir returns the current project's root and data dir
+// without opening the index.
+func resolveProjectDataDir() (projectRoot, dataDir string, err error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", "", fmt.Errorf("get cwd: %w", err)
+	}
+	projectRoot, err = config.FindProjectRootFrom(cwd)
+	if err != nil {
+		return "", "", fmt.Errorf("no vecgrep project found: %w; run 'vecgrep init' first", err)
+	}
+	resolved, err := config.LoadResolved(projectRoot)
+	if err != nil {
+		return "", "", fmt.Errorf("load config: %w", err)
+	}
+	return projectRoot, resolved.Config.DataDir, nil
+}
+
+// projectRelativeKey parses a file:start-end location and rewrites an
+// absolute path inside the project as project-relative, so keys match the
+// paths printed by search.
+func projectRelativeKey(projectRoot, location string) (string, error) {
+	file, startLine, endLine, err := app.ParseChunkKey(location)
+	if err != nil {
+		return "", err
+	}
+	if filepath.IsAbs(file) {
+		if rel, relErr := filepath.Rel(projectRoot, file); relErr == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	return app.ChunkKey(file, startLine, endLine), nil
+}
+
+func runBookmarkAdd(cmd *cobra.Command, args []string) error {
+	note, _ := cmd.Flags().GetString("note")
+	tagsCSV, _ := cmd.Flags().GetString("tags")
+
+	projectRoot, dataDir, err := resolveProjectDataDir()
+	if err != nil {
+		return err
+	}
+	key, err := projectRelativeKey(projectRoot, args[0])
+	if err != nil {
+		return err
+	}
+
+	b, err := app.AddBookmark(dataDir, app.BookmarkInput{
+		Key:  key,
+		Note: note,
+		Tags: parseTags(tagsCSV),
+	})
+	if err != nil {
+		return fmt.Errorf("add bookmark: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Bookmarked %s (id %d)\n", b.Key, b.ID)
+	return nil
+}
+
+func runBookmarkList(cmd *cobra.Command, args []string) error {
+	tagsCSV, _ := cmd.Flags().GetString("tags")
+	format, _ := cmd.Flags().GetString("format")
+
+	_, dataDir, err := resolveProjectDataDir()
+	if err != nil {
+		return err
+	}
+	bookmarks, err := app.ListBookmarks(dataDir, parseTags(tagsCSV))
+	if err != nil {
+		return fmt.Errorf("list bookmarks: %w", err)
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(bookmarks)
+	}
+	writeBookmarksHuman(cmd, bookmarks)
+	return nil
+}
+
+func writeBookmarksHuman(cmd *cobra.Command, bookmarks []app.Bookmark) {
+	w := cmd.OutOrStdout()
+	if len(bookmarks) == 0 {
+		fmt.Fprintln(w, "No bookmarks.")
+		return
+	}
+	for _, b := range bookmarks {
+		fmt.Fprintf(w, "%d. %s", b.ID, b.Key)
+		if b.Note != "" {
+			fmt.Fprintf(w, " — %s", b.Note)
+		}
+		fmt.Fprintln(w)
+		if len(b.Tags) > 0 {
+			fmt.Fprintf(w, "   tags: %s\n", strings.Join(b.Tags, ", "))
+		}
+	}
+}
+
+func runBookmarkRemove(cmd *cobra.Command, args []string) error {
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid bookmark id %q", args[0])
+	}
+	_, dataDir, err := resolveProjectDataDir()
+	if err != nil {
+		return err
+	}
+	removed, err := app.RemoveBookmark(dataDir, id)
+	if err != nil {
+		return fmt.Errorf("remove bookmark: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("bookmark %d not found", id)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Removed bookmark %d\n", id)
+	return nil
+}
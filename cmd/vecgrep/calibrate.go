@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// calibrateCmd measures each search mode's score distribution so scores and
+// --min-score mean the same thing in every mode.
+var calibrateCmd = &cobra.Command{
+	Use:   "calibrate",
+	Short: "Calibrate search scores so thresholds match across modes",
+	Long: `Sample the scores each search mode returns on this index and store them, so
+later searches report calibrated scores.
+
+Raw scores are on different scales: semantic search reports cosine
+similarity, keyword search BM25 relative to the best hit, and hybrid search a
+weighted blend of the two. A calibrated score is the share of sampled results
+from the same mode that the raw score beats, so --min-score 0.8 keeps roughly
+the top fifth of typical results whether the search is semantic, keyword, or
+hybrid. JSON output keeps the mode's own score in raw_score.
+
+Sample queries are symbol names drawn evenly from the index. Semantic and
+hybrid search are sampled when an embedding provider is configured. A
+calibration applies only while the same provider and model are configured;
+run calibrate again after switching models or reindexing a very different
+codebase.`,
+	Example: `  vecgrep calibrate
+  vecgrep calibrate -n 100
+  vecgrep calibrate --reset`,
+	Args: cobra.NoArgs,
+	RunE: runCalibrate,
+}
+
+func runCalibrate(cmd *cobra.Command, args []string) error {
+	queries, _ := cmd.Flags().GetInt("queries")
+	reset, _ := cmd.Flags().GetBool("reset")
+	format, _ := cmd.Flags().GetString("format")
+	if format != "default" && format != "json" {
+		return fmt.Errorf("unsupported format %q (use default or json)", format)
+	}
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	if reset {
+		if err := app.ResetScoreCalibration(session.Config.DataDir); err != nil {
+			return err
+		}
+		fmt.Println("Score calibration removed; searches report raw scores.")
+		return nil
+	}
+
+	calibration, err := app.NewService(session).CalibrateScores(cmd.Context(), app.CalibrateOptions{Queries: queries})
+	if err != nil {
+		return fmt.Errorf("calibrate: %w", err)
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(calibration, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	fmt.Printf("Calibrated %d mode(s) from %d sample queries (%s %s)\n\n", len(calibration.Curves), calibration.Queries, calibration.Provider, calibration.Model)
+	keys := make([]string, 0, len(calibration.Curves))
+	for key := range calibration.Curves {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Printf("  %-12s %8s %8s %8s %8s\n", "mode", "samples", "p50", "p90", "p99")
+	for _, key := range keys {
+		curve := calibration.Curves[key]
+		fmt.Printf("  %-12s %8d %8.4f %8.4f %8.4f\n", key, calibration.Samples[key], curve[50], curve[90], curve[99])
+	}
+	fmt.Println("\nRaw scores at these percentiles now report as 0.50, 0.90, and 0.99.")
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+	"github.com/spf13/cobra"
+)
+
+// chunkCmd shows how the built-in chunker splits one file.
+var chunkCmd = &cobra.Command{
+	Use:   "chunk <file>",
+	Short: "Show how a file is split into chunks",
+	Long: `Split a file with the built-in chunker and print each chunk's line range,
+type, symbol, and size, using the project's chunk size settings when run
+inside a project. Nothing is read from or written to the index.
+
+--debug also prints the boundary decisions behind the chunks: the blocks the
+language parser recognized and where each ended, uncovered source kept as
+generic chunks, line windows, and size splits. The chunk lines use the same
+form as the chunker golden files in internal/index/testdata/chunker.`,
+	Example: `  vecgrep chunk internal/search/search.go
+  vecgrep chunk --debug scripts/deploy.py
+  vecgrep chunk --debug -f json src/client.ts`,
+	Args: cobra.ExactArgs(1),
+	RunE: runChunk,
+}
+
+// chunkOutput is the JSON shape of `vecgrep chunk --format json`.
+type chunkOutput struct {
+	Path      string                   `json:"path"`
+	Language  string                   `json:"language"`
+	Chunks    []chunkOutputChunk       `json:"chunks"`
+	Decisions []index.BoundaryDecision `json:"decisions,omitempty"`
+}
+
+type chunkOutputChunk struct {
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Type      string `json:"type"`
+	Symbol    string `json:"symbol,omitempty"`
+	Bytes     int    `json:"bytes"`
+	// Language is set for code embedded in another language, such as a
+	// fenced block in Markdown.
+	Language string `json:"language,omitempty"`
+	// Parent is the position in chunks of the enclosing chunk; omitted for
+	// top-level chunks.
+	Parent *int `json:"parent,omitempty"`
+}
+
+func runChunk(cmd *cobra.Command, args []string) error {
+	debug, _ := cmd.Flags().GetBool("debug")
+	format, _ := cmd.Flags().GetString("format")
+	if format != "default" && format != "json" {
+		return fmt.Errorf("unsupported format %q (use default or json)", format)
+	}
+
+	path := args[0]
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if !index.IsTextFile(content) {
+		return fmt.Errorf("%s is not a text file; the indexer skips it", path)
+	}
+
+	cfg := config.DefaultConfig()
+	if projectRoot, err := config.GetProjectRoot(); err == nil {
+		if cfg, err = config.Load(projectRoot); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+	}
+	indexerCfg := app.BuildIndexerConfig(cfg, nil)
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	if spec, ok := indexerCfg.ExternalChunkers[ext]; ok {
+		fmt.Fprintf(os.Stderr, "Note: %q is configured as the chunker for .%s files; showing the built-in chunker it falls back to.\n", spec.Command[0], ext)
+	}
+
+	chunks, decisions := index.ExplainChunks(indexerCfg, content, path)
+	if !debug {
+		decisions = nil
+	}
+
+	if format == "json" {
+		out := chunkOutput{
+			Path:      path,
+			Language:  string(index.DetectLanguage(path)),
+			Chunks:    make([]chunkOutputChunk, 0, len(chunks)),
+			Decisions: decisions,
+		}
+		parents := index.ChunkParents(chunks)
+		for i, c := range chunks {
+			chunk := chunkOutputChunk{
+				StartLine: c.StartLine,
+				EndLine:   c.EndLine,
+				Type:      string(c.ChunkType),
+				Symbol:    c.SymbolName,
+				Bytes:     len(c.Content),
+				Language:  string(c.Language),
+			}
+			if parents[i] >= 0 {
+				chunk.Parent = &parents[i]
+			}
+			out.Chunks = append(out.Chunks, chunk)
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if debug {
+		fmt.Println("Decisions:")
+		for _, d := range decisions {
+			fmt.Printf("  %s\n", d)
+		}
+		fmt.Println()
+		fmt.Println("Chunks:")
+	}
+	fmt.Print(index.ChunkBoundaries(chunks))
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/daemon"
+	"github.com/spf13/cobra"
+)
+
+// daemonStartWait bounds how long 'daemon start --background' waits for the
+// detached hub to answer on its socket. Pre-opening a large project loads
+// its HNSW index first, so this is generous.
+const daemonStartWait = 30 * time.Second
+
+// startDaemonBackground re-executes this binary as a detached
+// 'vecgrep daemon start' and returns once the hub answers on its socket, so
+// the next CLI search can use it right away. The child's own output goes to
+// daemon.log next to the hub's log lines.
+func startDaemonBackground(cmd *cobra.Command, args []string, globalDir string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate vecgrep binary: %w", err)
+	}
+	childArgs := []string{"daemon", "start"}
+	if configPath, _ := cmd.Flags().GetString("config"); configPath != "" {
+		childArgs = append(childArgs, "--config", configPath)
+	}
+	for _, name := range []string{"log-level", "log-format"} {
+		if cmd.Flags().Changed(name) {
+			value, _ := cmd.Flags().GetString(name)
+			childArgs = append(childArgs, "--"+name, value)
+		}
+	}
+	childArgs = append(childArgs, args...)
+
+	if err := os.MkdirAll(globalDir, 0o755); err != nil {
+		return fmt.Errorf("create data dir: %w", err)
+	}
+	logPath := filepath.Join(globalDir, "daemon.log")
+	logFile, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open daemon log: %w", err)
+	}
+	defer logFile.Close()
+
+	child := exec.Command(exe, childArgs...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.SysProcAttr = detachedProcAttr()
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("start daemon hub: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- child.Wait() }()
+
+	deadline := time.After(daemonStartWait)
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for {
+		select {
+		case err := <-exited:
+			if err == nil {
+				err = errors.New("exited before serving")
+			}
+			return fmt.Errorf("daemon hub failed to start (%v); see %s", err, logPath)
+		case <-deadline:
+			return fmt.Errorf("daemon hub (pid %d) did not answer within %s; see %s", child.Process.Pid, daemonStartWait, logPath)
+		case <-tick.C:
+			if daemon.IsRunning(globalDir) {
+				fmt.Println("Started vecgrep daemon hub in the background")
+				fmt.Printf("  Socket: %s\n", filepath.Join(globalDir, "daemon.sock"))
+				fmt.Printf("  PID: %d\n", child.Process.Pid)
+				fmt.Printf("  Log: %s\n", logPath)
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "syscall"
+
+// detachedProcAttr has nothing to add on platforms without POSIX sessions;
+// the hub still runs as a separate process.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
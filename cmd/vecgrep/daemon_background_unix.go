@@ -0,0 +1,11 @@
+//go:build unix
+
+package main
+
+import "syscall"
+
+// detachedProcAttr starts the background hub in its own session so it
+// outlives the terminal that launched it and ignores its hangups.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -83,7 +83,7 @@ func TestTryDaemonSearchSurfacesWarnings(t *testing.T) {
 		t.Fatalf("pipe: %v", err)
 	}
 	os.Stdout = w
-	handled := tryDaemonSearch(context.Background(), "sessionStorage zod", 5, "hybrid", "", nil, nil, "", "", "", 0, 0, 0, false, "default", nil, "")
+	_, _, handled := tryDaemonSearch(context.Background(), "sessionStorage zod", 5, "hybrid", "", nil, nil, "", "", "", 0, 0, 0, nil, 0, false, "default", nil, nil, "", 0, 0, 0, nil, 0)
 	_ = w.Close()
 	os.Stdout = oldStdout
 	out, _ := io.ReadAll(r)
@@ -95,3 +95,92 @@ func TestTryDaemonSearchSurfacesWarnings(t *testing.T) {
 		t.Errorf("daemon-served degraded search must surface the warning; output was:\n%s", out)
 	}
 }
+
+// TestTryDaemonSearchForwardsFilters checks that filtered searches stay on
+// the warm daemon instead of falling back to a cold read-only session.
+func TestTryDaemonSearchForwardsFilters(t *testing.T) {
+	home, err := os.MkdirTemp("/tmp", "vecgrep-home-")
+	if err != nil {
+		t.Fatalf("mkdtemp: %v", err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(home) })
+	t.Setenv("HOME", home)
+
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "vecgrep.yaml"), []byte("{}\n"), 0o644); err != nil {
+		t.Fatalf("write project config: %v", err)
+	}
+	oldWD, wdErr := os.Getwd()
+	if wdErr != nil {
+		t.Fatalf("getwd: %v", wdErr)
+	}
+	if err := os.Chdir(project); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(oldWD) })
+
+	sockDir := filepath.Join(home, ".vecgrep")
+	if err := os.MkdirAll(sockDir, 0o755); err != nil {
+		t.Fatalf("mkdir global config dir: %v", err)
+	}
+	ln, err := net.Listen("unix", filepath.Join(sockDir, "daemon.sock"))
+	if err != nil {
+		t.Fatalf("listen on fake daemon socket: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	received := make(chan map[string]any, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Params map[string]any  `json:"params"`
+		}
+		if err := json.NewDecoder(conn).Decode(&req); err != nil {
+			return
+		}
+		received <- req.Params
+		_ = json.NewEncoder(conn).Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"result":  map[string]any{"results": []any{}, "mode": "hybrid"},
+		})
+	}()
+
+	oldStdout := os.Stdout
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = devNull
+	_, _, handled := tryDaemonSearch(context.Background(), "retry", 5, "hybrid", "", []string{"go", "rust"}, nil, "function", "*.go", "internal", 10, 200, 0, []string{"api"}, 64, false, "default", nil, []string{"a.go"}, "", 0, 0, 0, nil, 0)
+	os.Stdout = oldStdout
+	_ = devNull.Close()
+
+	if !handled {
+		t.Fatal("filtered search should have been served by the daemon")
+	}
+	params := <-received
+	if params["chunk_type"] != "function" || params["file_pattern"] != "*.go" || params["directory"] != "internal" {
+		t.Errorf("params = %v, want filters forwarded", params)
+	}
+	if params["min_line"] != float64(10) || params["max_line"] != float64(200) {
+		t.Errorf("line range = %v-%v", params["min_line"], params["max_line"])
+	}
+	if langs, _ := params["languages"].([]any); len(langs) != 2 {
+		t.Errorf("languages = %v", params["languages"])
+	}
+	if paths, _ := params["file_paths"].([]any); len(paths) != 1 || paths[0] != "a.go" {
+		t.Errorf("file_paths = %v", params["file_paths"])
+	}
+	if tags, _ := params["tags"].([]any); len(tags) != 1 || tags[0] != "api" {
+		t.Errorf("tags = %v", params["tags"])
+	}
+	if params["ef_search"] != float64(64) {
+		t.Errorf("ef_search = %v, want 64", params["ef_search"])
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// deadcodeCmd lists exported symbols nothing else in the project refers to.
+var deadcodeCmd = &cobra.Command{
+	Use:   "deadcode",
+	Short: "List exported symbols no other file uses",
+	Long: `List exported functions, types, and constants that appear in no other
+indexed file, ranked as cleanup candidates. No embedding provider is needed;
+the command reads the index.
+
+A symbol counts as used when another file mentions its name. For Go the
+mention only counts from the same package or from a file that imports it,
+resolved with the same import parsing as related files. Go,
+JavaScript/TypeScript, Python, and Rust symbols are checked.
+
+Confidence starts at 1 and drops when the symbol is used by tests, is used
+inside its own file, or is named by unrelated files, and when its imports
+cannot be resolved. Code reached through reflection, generated code, or
+callers outside the index shows up too, so review candidates before deleting
+them.`,
+	Example: `  vecgrep deadcode
+  vecgrep deadcode --lang go -n 20
+  vecgrep deadcode --include-tests -f json`,
+	Args: cobra.NoArgs,
+	RunE: runDeadcode,
+}
+
+func runDeadcode(cmd *cobra.Command, args []string) error {
+	languages, _ := cmd.Flags().GetStringSlice("lang")
+	includeTests, _ := cmd.Flags().GetBool("include-tests")
+	limit, _ := cmd.Flags().GetInt("limit")
+	format, _ := cmd.Flags().GetString("format")
+
+	if format != "default" && format != "json" {
+		return fmt.Errorf("unsupported format %q (use default or json)", format)
+	}
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	report, err := app.NewService(session).DeadCode(cmd.Context(), app.DeadCodeOptions{
+		Languages:    languages,
+		IncludeTests: includeTests,
+		Limit:        limit,
+	})
+	if err != nil {
+		return fmt.Errorf("deadcode: %w", err)
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if len(report.Candidates) == 0 {
+		fmt.Printf("No unused exported symbols among %d checked in %d files.\n", report.Exported, report.Files)
+		return nil
+	}
+	fmt.Printf("%d of %d exported symbols look unused", report.Total, report.Exported)
+	if report.Total > len(report.Candidates) {
+		fmt.Printf(" (showing %d)", len(report.Candidates))
+	}
+	fmt.Print("\n\n")
+	for i, c := range report.Candidates {
+		fmt.Printf("%3d. %.2f  %s %s  %s:%d-%d\n", i+1, c.Confidence, c.ChunkType, c.Name, c.RelativePath, c.StartLine, c.EndLine)
+		fmt.Printf("       %s\n", strings.Join(c.Reasons, "; "))
+	}
+	return nil
+}
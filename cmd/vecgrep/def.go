@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// defCmd guesses where a symbol is defined from the index alone.
+var defCmd = &cobra.Command{
+	Use:   "def <symbol>",
+	Short: "Find the likely definition of a symbol without a language server",
+	Long: `Find where a symbol is most likely defined, in any indexed language, without
+a language server or an embedding provider.
+
+Candidates are the indexed chunks named after the symbol (methods also match
+by their bare name). Each gets a confidence from 0 to 1 built from how exactly
+the name matches, whether the chunk is a function, type, or constant rather
+than a generic block or doc comment, whether it lives in a test file, and,
+with --from, how close it is to the file the symbol was seen in. Confidences
+are shares of the evidence, so two equally likely sites get 0.5 each.`,
+	Example: `  vecgrep def NewSearcher
+  vecgrep def Search --from internal/mcp/server_sdk.go
+  vecgrep def parse_config -n 3 -f json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDef,
+}
+
+func runDef(cmd *cobra.Command, args []string) error {
+	from, _ := cmd.Flags().GetString("from")
+	limit, _ := cmd.Flags().GetInt("limit")
+	format, _ := cmd.Flags().GetString("format")
+	if format != "default" && format != "json" {
+		return fmt.Errorf("unsupported format %q (use default or json)", format)
+	}
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	if from != "" {
+		abs, err := filepath.Abs(from)
+		if err != nil {
+			return fmt.Errorf("resolve --from: %w", err)
+		}
+		rel, err := filepath.Rel(session.ProjectRoot, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("--from %s is outside the project root %s", from, session.ProjectRoot)
+		}
+		from = filepath.ToSlash(rel)
+	}
+
+	matches, err := app.NewService(session).Definitions(cmd.Context(), app.DefinitionRequest{Symbol: args[0], From: from, Limit: limit})
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, m := range matches {
+		location := fmt.Sprintf("%s:%d-%d", m.RelativePath, m.StartLine, m.EndLine)
+		if m.Root != "" {
+			location = m.Root + ":" + location
+		}
+		fmt.Fprintf(w, "%.2f\t%s\t%s\t%s\n", m.Confidence, location, m.Name, strings.Join(m.Reasons, ", "))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+	"github.com/spf13/cobra"
+)
+
+// diffIndexCmd shows how the last index run changed each file's chunks.
+var diffIndexCmd = &cobra.Command{
+	Use:   "diff-index [path]",
+	Short: "Show chunks added, removed, or modified by the last index run",
+	Long: `Compare the chunks stored by the most recent index run with the generation
+it replaced, file by file. Use it to check that a chunking or config change
+did what you expected before relying on the new index.
+
+A chunk whose content survived unchanged is counted but not listed, even if
+it moved. Of the rest, an old and a new chunk declaring the same symbol, or
+else starting on the same line, are reported as one modified chunk; anything
+left over was removed or added.
+
+The diff is kept until the next run that touches a file, so a run that finds
+nothing to re-index does not hide it. Pass a path to show only files under it.`,
+	Example: `  vecgrep index --full && vecgrep diff-index
+  vecgrep diff-index internal/config
+  vecgrep diff-index -f json`,
+	Args:         cobra.MaximumNArgs(1),
+	RunE:         runDiffIndex,
+	SilenceUsage: true,
+}
+
+func runDiffIndex(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+
+	_, dataDir, err := resolveProjectDataDir()
+	if err != nil {
+		return err
+	}
+	diff, err := app.LoadIndexDiff(dataDir)
+	if err != nil {
+		return err
+	}
+	if diff == nil {
+		return fmt.Errorf("no index diff recorded yet; run 'vecgrep index' first")
+	}
+	if len(args) > 0 {
+		diff.Files = filterChunkDiffFiles(diff.Files, args[0])
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	printIndexDiff(diff)
+	return nil
+}
+
+// filterChunkDiffFiles keeps the files at or under prefix.
+func filterChunkDiffFiles(files []index.FileChunkDiff, prefix string) []index.FileChunkDiff {
+	prefix = strings.TrimSuffix(filepath.ToSlash(filepath.Clean(prefix)), "/")
+	filtered := []index.FileChunkDiff{}
+	for _, file := range files {
+		path := filepath.ToSlash(file.Path)
+		if prefix == "." || path == prefix || strings.HasPrefix(path, prefix+"/") {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+func printIndexDiff(diff *app.IndexDiff) {
+	kind := "incremental"
+	if diff.FullReindex {
+		kind = "full"
+	}
+	fmt.Printf("Last index run: %s (%s)\n", diff.IndexedAt.Local().Format("2006-01-02 15:04:05"), kind)
+	fmt.Printf("  Added: %d  Removed: %d  Modified: %d  Unchanged: %d\n", diff.Added, diff.Removed, diff.Modified, diff.Unchanged)
+	if len(diff.Files) == 0 {
+		fmt.Println("\nNo chunk changes.")
+		return
+	}
+	for _, file := range diff.Files {
+		path := file.Path
+		if file.Root != "" {
+			path = file.Root + ":" + path
+		}
+		fmt.Printf("\n%s (%d unchanged)\n", path, file.Unchanged)
+		for _, change := range file.Changes {
+			switch change.Kind {
+			case index.ChunkAdded:
+				fmt.Printf("  + %s\n", describeChunk(change.New))
+			case index.ChunkRemoved:
+				fmt.Printf("  - %s\n", describeChunk(change.Old))
+			case index.ChunkModified:
+				fmt.Printf("  ~ %s (was lines %d-%d)\n", describeChunk(change.New), change.Old.StartLine, change.Old.EndLine)
+			}
+		}
+	}
+}
+
+func describeChunk(chunk *index.ChunkFingerprint) string {
+	name := chunk.ChunkType
+	if chunk.SymbolName != "" {
+		name += " " + chunk.SymbolName
+	}
+	return fmt.Sprintf("%s lines %d-%d", name, chunk.StartLine, chunk.EndLine)
+}
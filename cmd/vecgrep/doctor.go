@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd diagnoses common setup problems and prints a fix for each.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose setup problems and suggest fixes",
+	Long: `Check the current project and vecgrep installation for common problems
+and print a concrete fix for each one found:
+
+  project         a vecgrep project encloses the current directory
+  config          the resolved configuration loads
+  provider        the embedding provider is reachable
+  model           the configured model is installed and embeds text
+  dimensions      embedding.dimensions, the provider's output, and the
+                  stored vectors agree, and the index was built with
+                  these settings
+  database        the index opens and its records read back
+  orphaned data   indexed files still exist and belong to this project
+  registry        registered projects still exist and every data
+                  directory under ~/.vecgrep/projects belongs to one
+
+Doctor never modifies anything. It exits non-zero when a check fails;
+warnings alone do not fail it.`,
+	Example: `  vecgrep doctor
+  vecgrep doctor -f json`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+
+	report := app.RunDoctor(cmd.Context(), "")
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printDoctorReport(report)
+	}
+	if !report.Healthy {
+		return fmt.Errorf("doctor found problems")
+	}
+	return nil
+}
+
+func printDoctorReport(report *app.DoctorReport) {
+	fmt.Println("vecgrep doctor")
+	fmt.Println("==============")
+	for _, check := range report.Checks {
+		fmt.Printf("[%-4s] %-18s %s\n", check.Status, check.Name, check.Detail)
+		if check.Fix != "" {
+			fmt.Printf("       %-18s fix: %s\n", "", check.Fix)
+		}
+	}
+	if report.Healthy {
+		fmt.Println("\nResult: no problems found")
+	} else {
+		fmt.Println("\nResult: problems found; apply the fixes above and rerun vecgrep doctor")
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// dupesCmd reports near-duplicate chunks and, with a baseline, fails only on
+// duplication introduced since the baseline was written.
+var dupesCmd = &cobra.Command{
+	Use:   "dupes",
+	Short: "Find near-duplicate code in the index",
+	Long: `Find chunks whose stored embeddings are nearly identical and group them
+into clusters. No embedding provider is needed; the command reads the index.
+
+Pairs are identified by file path and a hash of the chunk content, so a
+baseline stays valid when unrelated edits shift line numbers. Commit a
+baseline with --write-baseline, then run with --baseline and --fail-over in
+CI: the command exits non-zero only when a pair missing from the baseline
+scores at or above the --fail-over similarity.`,
+	Example: `  vecgrep dupes
+  vecgrep dupes --threshold 0.9 -f json
+  vecgrep dupes --baseline dupes.json --write-baseline
+  vecgrep dupes --baseline dupes.json --fail-over 0.95`,
+	Args: cobra.NoArgs,
+	RunE: runDupes,
+}
+
+// dupesOutput is the JSON shape of 'vecgrep dupes'. New and Resolved are
+// only set when a baseline was loaded.
+type dupesOutput struct {
+	*app.DuplicateReport
+	Baseline string              `json:"baseline,omitempty"`
+	New      []app.DuplicatePair `json:"new,omitempty"`
+	Resolved []string            `json:"resolved,omitempty"`
+}
+
+func runDupes(cmd *cobra.Command, args []string) error {
+	threshold, _ := cmd.Flags().GetFloat32("threshold")
+	minLines, _ := cmd.Flags().GetInt("min-lines")
+	failOver, _ := cmd.Flags().GetFloat32("fail-over")
+	baselinePath, _ := cmd.Flags().GetString("baseline")
+	writeBaseline, _ := cmd.Flags().GetBool("write-baseline")
+	format, _ := cmd.Flags().GetString("format")
+
+	if format != "default" && format != "json" {
+		return fmt.Errorf("unsupported format %q (use default or json)", format)
+	}
+	if writeBaseline && baselinePath == "" {
+		return fmt.Errorf("--write-baseline requires --baseline")
+	}
+	if failOver < 0 || failOver > 1 {
+		return fmt.Errorf("--fail-over must be between 0 and 1")
+	}
+	// Pairs scoring below the detection threshold are never seen, so lower it
+	// to --fail-over unless the user set it explicitly.
+	if failOver > 0 && failOver < threshold && !cmd.Flags().Changed("threshold") {
+		threshold = failOver
+	}
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	report, err := app.NewService(session).FindDuplicates(cmd.Context(), app.DuplicateOptions{Threshold: threshold, MinLines: minLines})
+	if err != nil {
+		return fmt.Errorf("find duplicates: %w", err)
+	}
+
+	if writeBaseline {
+		if err := app.WriteDuplicateBaseline(baselinePath, report.Baseline()); err != nil {
+			return fmt.Errorf("write baseline: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d duplicate pair(s) to %s\n", len(report.Pairs), baselinePath)
+		return nil
+	}
+
+	var baseline *app.DuplicateBaseline
+	if baselinePath != "" {
+		baseline, err = app.LoadDuplicateBaseline(baselinePath)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("load baseline: %w", err)
+		}
+		if baseline == nil {
+			fmt.Fprintf(os.Stderr, "Baseline %s not found; every pair counts as new\n", baselinePath)
+		}
+	}
+	cmp := report.Compare(baseline)
+
+	out := dupesOutput{DuplicateReport: report}
+	if baselinePath != "" {
+		out.Baseline = baselinePath
+		out.New = cmp.New
+		out.Resolved = cmp.Resolved
+	}
+	if format == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printDupes(out, cmp)
+	}
+
+	if failOver > 0 {
+		failing := 0
+		for _, pair := range cmp.New {
+			if pair.Score >= failOver {
+				failing++
+			}
+		}
+		if failing > 0 {
+			return fmt.Errorf("%d new duplicate pair(s) at or above %.2f similarity", failing, failOver)
+		}
+	}
+	return nil
+}
+
+func printDupes(out dupesOutput, cmp app.DuplicateComparison) {
+	if len(out.Clusters) == 0 {
+		fmt.Printf("No duplicates at or above %.2f similarity.\n", out.Threshold)
+	} else {
+		fmt.Printf("Found %d duplicate pair(s) in %d cluster(s) at or above %.2f similarity\n", len(out.Pairs), len(out.Clusters), out.Threshold)
+		for i, cluster := range out.Clusters {
+			fmt.Printf("\nCluster %d (%d chunks, max %.4f)\n", i+1, len(cluster.Chunks), cluster.MaxScore)
+			for _, chunk := range cluster.Chunks {
+				fmt.Printf("  %s:%d-%d\n", chunk.File, chunk.StartLine, chunk.EndLine)
+			}
+		}
+	}
+	if out.Baseline == "" {
+		return
+	}
+	fmt.Printf("\nBaseline %s: %d known, %d new, %d resolved\n", out.Baseline, cmp.Known, len(cmp.New), len(cmp.Resolved))
+	for _, pair := range cmp.New {
+		fmt.Printf("  new: %s:%d-%d <-> %s:%d-%d (%.4f)\n", pair.A.File, pair.A.StartLine, pair.A.EndLine, pair.B.File, pair.B.StartLine, pair.B.EndLine, pair.Score)
+	}
+	if len(cmp.Resolved) > 0 {
+		fmt.Println("  Resolved pairs can be dropped with --write-baseline.")
+	}
+}
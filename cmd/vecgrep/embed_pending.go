@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// embedPendingCmd embeds the chunks queued by `index --defer-embeddings`.
+var embedPendingCmd = &cobra.Command{
+	Use:   "embed-pending",
+	Short: "Embed chunks queued by index --defer-embeddings",
+	Long: `Embed the chunks that 'vecgrep index --defer-embeddings' queued while the
+embedding provider was unavailable, and store them in place of each file's
+previous chunks.
+
+Queued files that changed on disk since they were chunked, or that a normal
+index run has embedded since, are dropped instead of embedded; the next
+'vecgrep index' picks up their current content. Files that fail to embed stay
+queued for the next run.`,
+	Args:         cobra.NoArgs,
+	RunE:         runEmbedPending,
+	SilenceUsage: true,
+}
+
+func runEmbedPending(cmd *cobra.Command, args []string) error {
+	session, err := app.OpenSession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	pending, err := app.LoadPendingEmbeddings(session.Config.DataDir)
+	if err != nil {
+		return err
+	}
+	if pending.Files == 0 {
+		fmt.Println("No pending embeddings.")
+		return nil
+	}
+	fmt.Printf("Embedding %d queued files (%d chunks)...\n", pending.Files, pending.Chunks)
+	fmt.Printf("  Model: %s\n", session.Config.Embedding.Model)
+
+	verbose, _ := rootCmd.PersistentFlags().GetBool("verbose")
+	var progress func(app.EmbedPendingProgress)
+	if verbose {
+		progress = func(p app.EmbedPendingProgress) {
+			fmt.Printf("\r  %s (%d/%d files)\033[K", p.File, p.Done, p.Total)
+		}
+	}
+	result, err := app.NewService(session).EmbedPending(cmd.Context(), progress)
+	if verbose {
+		fmt.Println()
+	}
+	if result != nil {
+		fmt.Printf("\nEmbedding complete:\n")
+		fmt.Printf("  Files embedded: %d\n", result.FilesEmbedded)
+		fmt.Printf("  Chunks embedded: %d\n", result.ChunksEmbedded)
+		fmt.Printf("  Files dropped (changed since queued): %d\n", result.FilesStale)
+		fmt.Printf("  Files still queued: %d\n", result.FilesRemaining)
+		fmt.Printf("  Duration: %s\n", result.Duration.Round(100*1000000))
+		if len(result.Errors) > 0 {
+			fmt.Printf("\nWarnings: %d\n", len(result.Errors))
+			for _, e := range result.Errors {
+				fmt.Printf("  - %v\n", e)
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("embed pending failed: %w", err)
+	}
+	return nil
+}
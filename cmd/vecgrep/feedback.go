@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// feedbackCmd records whether a search result answered its query. Judgments
+// boost or demote the chunk when the same query is searched again and can
+// be exported as a benchmark dataset.
+var feedbackCmd = &cobra.Command{
+	Use:   "feedback <chunk-id|file:start-end> --query <query> (--relevant | --irrelevant)",
+	Short: "Judge a search result relevant or irrelevant for a query",
+	Long: `Record whether a search result answered a query. The chunk is a chunk ID
+(chunk_id in 'search -f json') or the file:start-end location search prints.
+
+When the same query is searched again, chunks judged relevant are boosted and
+chunks judged irrelevant are demoted, marked "Feedback: relevant" (or
+"feedback" in JSON). Queries match after inline filters are removed and case
+and spacing are normalized. Judging a chunk again for the same query replaces
+the earlier judgment. Judgments are stored per project outside the index and
+keep the chunk text, so 'feedback export' can turn them into a dataset for
+'vecgrep benchmark embeddings --dataset'.`,
+	Example: `  vecgrep feedback internal/embed/throttle.go:40-60 --query "rate limiting" --relevant
+  vecgrep feedback 1842 --query "rate limiting" --irrelevant
+  vecgrep feedback list --query "rate limiting"
+  vecgrep feedback export -o feedback-eval.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runFeedback,
+}
+
+var feedbackListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded judgments, optionally for one query",
+	Args:  cobra.NoArgs,
+	RunE:  runFeedbackList,
+}
+
+var feedbackExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export judgments as an embedding benchmark dataset",
+	Args:  cobra.NoArgs,
+	RunE:  runFeedbackExport,
+}
+
+func runFeedback(cmd *cobra.Command, args []string) error {
+	query, _ := cmd.Flags().GetString("query")
+	relevant, _ := cmd.Flags().GetBool("relevant")
+	irrelevant, _ := cmd.Flags().GetBool("irrelevant")
+	if relevant == irrelevant {
+		return fmt.Errorf("pass exactly one of --relevant or --irrelevant")
+	}
+
+	ctx := context.Background()
+	session, err := app.OpenReadOnlySession(ctx, "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	target := args[0]
+	if _, err := strconv.ParseInt(target, 10, 64); err != nil {
+		if target, err = projectRelativeKey(session.ProjectRoot, target); err != nil {
+			return err
+		}
+	}
+	chunk, err := app.FeedbackChunk(session.DB, session.ProjectRoot, target)
+	if err != nil {
+		return err
+	}
+	j, err := app.RecordFeedback(session.Config.DataDir, app.JudgmentInput{
+		Query:    query,
+		Key:      app.ChunkKey(chunk.RelativePath, chunk.StartLine, chunk.EndLine),
+		Relevant: relevant,
+		Language: chunk.Language,
+		Content:  chunk.Content,
+	})
+	if err != nil {
+		return fmt.Errorf("record feedback: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Marked %s %s for %q (id %d)\n", j.Key, feedbackLabel(j.Relevant), j.Query, j.ID)
+	return nil
+}
+
+func runFeedbackList(cmd *cobra.Command, args []string) error {
+	query, _ := cmd.Flags().GetString("query")
+	format, _ := cmd.Flags().GetString("format")
+
+	_, dataDir, err := resolveProjectDataDir()
+	if err != nil {
+		return err
+	}
+	judgments, err := app.ListFeedback(dataDir, query)
+	if err != nil {
+		return fmt.Errorf("list feedback: %w", err)
+	}
+
+	w := cmd.OutOrStdout()
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(judgments)
+	}
+	if len(judgments) == 0 {
+		fmt.Fprintln(w, "No feedback.")
+		return nil
+	}
+	for _, j := range judgments {
+		fmt.Fprintf(w, "%d. %q → %s (%s)\n", j.ID, j.Query, j.Key, feedbackLabel(j.Relevant))
+	}
+	return nil
+}
+
+func runFeedbackExport(cmd *cobra.Command, args []string) error {
+	output, _ := cmd.Flags().GetString("output")
+
+	_, dataDir, err := resolveProjectDataDir()
+	if err != nil {
+		return err
+	}
+	dataset, err := app.FeedbackDataset(dataDir)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(dataset, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode dataset: %w", err)
+	}
+	data = append(data, '\n')
+	if output == "" || output == "-" {
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return fmt.Errorf("write dataset: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Wrote %d queries over %d chunks to %s\n", len(dataset.Queries), len(dataset.Documents), output)
+	return nil
+}
+
+func feedbackLabel(relevant bool) string {
+	if relevant {
+		return app.FeedbackRelevant
+	}
+	return app.FeedbackIrrelevant
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// runPostSearchHooks runs hooks.post_search after a search's results are
+// printed. cfg is nil on the daemon path, which never opened a session, so
+// the project config is loaded here. Hook failures are warnings on stderr;
+// the search itself already succeeded.
+func runPostSearchHooks(ctx context.Context, cfg *config.Config, projectRoot, query, mode string, results []search.Result) {
+	if cfg == nil {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return
+		}
+		root, err := config.FindProjectRootFrom(cwd)
+		if err != nil {
+			return
+		}
+		resolved, err := config.LoadResolved(root)
+		if err != nil {
+			return
+		}
+		cfg, projectRoot = resolved.Config, root
+	}
+	event := app.PostSearchEvent{Query: query, Mode: mode, ProjectRoot: projectRoot, Results: results}
+	for _, err := range app.RunPostSearchHooks(ctx, cfg.Hooks, event, os.Stderr) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
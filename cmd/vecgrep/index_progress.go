@@ -210,8 +210,8 @@ func runIndexWithBar(ctx context.Context, service *app.Service, req app.IndexReq
 	defer cancel()
 
 	// The bar is an inline (non-AltScreen) one-liner, so any concurrent write to
-	// stderr — slog warnings from the embedder, the standard logger's warm-up
-	// lines — interleaves with it and garbles the display. Hold both back while
+	// stderr — slog warnings from the embedder, the model warm-up lines —
+	// interleaves with it and garbles the display. Hold both back while
 	// the bar is on screen; warnings are counted and surfaced once it clears.
 	// (This is why codemap's identical bar looks clean: indexing stays silent.)
 	logs := &barLogHandler{}
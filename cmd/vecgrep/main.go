@@ -5,13 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"syscall"
+	"text/template"
 	"time"
 
 	"github.com/abdul-hamid-achik/vecgrep/internal/app"
@@ -22,6 +26,7 @@ import (
 	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
 	"github.com/abdul-hamid-achik/vecgrep/internal/git"
 	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+	"github.com/abdul-hamid-achik/vecgrep/internal/logging"
 	"github.com/abdul-hamid-achik/vecgrep/internal/mcp"
 	"github.com/abdul-hamid-achik/vecgrep/internal/render"
 	"github.com/abdul-hamid-achik/vecgrep/internal/search"
@@ -47,6 +52,9 @@ embeddings to find similar code across your codebase.
 
 It supports Ollama for local embeddings, ensuring your code never
 leaves your machine.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return setupLogging(cmd)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if !isInteractiveTerminal() {
 			return cmd.Help()
@@ -82,14 +90,23 @@ indexes the current directory recursively.
 
 In an interactive terminal, empty indexes and --full reindexes print a plan
 and ask for confirmation before embedding (wrong-folder protection). Use
---yes to skip the prompt (scripts/CI). --dry-run prints the plan only.`,
+--yes to skip the prompt (scripts/CI). --dry-run prints the plan only.
+
+--defer-embeddings chunks changed files into a queue without contacting the
+embedding provider, so indexing works offline; run 'vecgrep embed-pending'
+once the provider is reachable. Until then, deferred files keep their
+previous chunks in search.
+
+--ref indexes a git ref (branch, tag, or commit) alongside the working tree
+without checking it out; repeat it to index several. Search an indexed ref
+with 'vecgrep search --ref <ref>'.`,
 	RunE: runIndex,
 	// Silence usage on intentional cancel / nothing-to-do.
 	SilenceUsage: true,
 }
 
 var searchCmd = &cobra.Command{
-	Use:   "search <query>",
+	Use:   "search <query> | -q <query> [path...]",
 	Short: "Search the codebase semantically",
 	Long: `Search the indexed codebase using natural language queries.
 Returns the most relevant code chunks ranked by similarity.
@@ -99,8 +116,28 @@ good matches typically land around 0.45-0.69) and semantic mode (raw cosine).
 Keyword mode normalizes BM25 to 0-1 within each result set (top hit = 1.0),
 so --min-score applies in every mode. If the embedding provider is
 unreachable, hybrid search degrades to keyword-only with an explicit warning;
-degraded results carry the same normalized keyword scores.`,
-	Args: cobra.MinimumNArgs(1),
+degraded results carry the same normalized keyword scores.
+
+The query may carry inline filters: lang:go, type:function, path:internal/**
+(or a glob such as path:*_test.go), dir:internal/search, lines:10-200, and
+score:0.5. Flags take precedence over inline filters.
+
+With --query, positional arguments are paths that scope results, as with grep:
+directories limit results to files under them and files to those files.
+Paths are resolved from the working directory.
+
+-C/--context, -B/--before-context, and -A/--after-context widen each result
+with surrounding lines read from the file on disk, as grep does.`,
+	Example: `  vecgrep search "retry backoff"
+  vecgrep search 'lang:go type:interface path:internal/** payment provider'
+  vecgrep search -q "retry backoff" ./internal/search ./internal/embed
+  vecgrep search -C 5 "retry backoff"`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if query, _ := cmd.Flags().GetString("query"); query == "" && len(args) == 0 {
+			return fmt.Errorf("requires a query argument or --query")
+		}
+		return nil
+	},
 	RunE: runSearch,
 }
 
@@ -115,9 +152,39 @@ var studioCmd = &cobra.Command{
 
 var serveCmd = &cobra.Command{
 	Use:   "serve",
-	Short: "Start the MCP stdio server",
-	Long: `Start the Model Context Protocol (MCP) server over stdio
-for integration with AI assistants.`,
+	Short: "Start the MCP server (stdio or HTTP)",
+	Long: `Start the Model Context Protocol (MCP) server for integration with AI
+assistants. It speaks stdio by default.
+
+--mcp-http serves the Streamable HTTP transport at http://<host>:<port>/mcp
+(and the older HTTP+SSE transport at /sse) instead, so remote or containerized
+assistants can connect without spawning the binary. It binds to 127.0.0.1
+unless --host says otherwise. Every request must carry the bearer token from
+--auth-token, $VECGREP_AUTH_TOKEN, or server.auth_token when one is set. Bound
+beyond loopback without one, the server generates a token on its first run,
+prints it, and keeps it in the project's data directory for later runs.
+Browsers sign in once by opening /files?token=<token>, which stores the token
+in a cookie.
+
+--audit-log appends one JSON line per tool call (and per /api/suggest request)
+to a file: the time, the caller (a fingerprint of its bearer token, the
+forwarding proxy's X-Forwarded-For, the MCP session and client name), the
+tool, the query and filters, the result count for search tools, and whether
+the call failed. Bearer tokens themselves are never written.
+
+--expose-read-only is the one-flag profile for sharing a search-only
+instance with teammates. It implies --mcp-http on 0.0.0.0 (unless --host is
+given) and --read-only, which leaves out every tool that indexes, deletes,
+registers projects, or stores bookmarks, feedback, or memories, as well as
+vecgrep_search_all and the memory tools, which reach beyond this project. It
+requires a bearer token on every request, configured or generated as above,
+and limits each client address to 120 requests a minute unless --rate-limit
+says otherwise. Each of these settings is also available as its own flag.`,
+	Example: `  vecgrep serve --mcp
+  vecgrep serve --mcp-http --port 8765
+  vecgrep serve --mcp-http --host 0.0.0.0 --port 8765   # inside a container
+  vecgrep serve --mcp-http --audit-log /var/log/vecgrep/audit.jsonl
+  vecgrep serve --expose-read-only --audit-log /var/log/vecgrep/audit.jsonl`,
 	RunE: runServe,
 }
 
@@ -139,8 +206,14 @@ Targets:
 For text snippets, use the --text flag:
   vecgrep similar --text "func NewSearcher"
 
+For a named function or type, use the --symbol flag. When the name is
+defined in more than one place, the candidates are listed with their chunk
+IDs so you can pick one:
+  vecgrep similar --symbol NewSearcher
+
 Examples:
   vecgrep similar 42
+  vecgrep similar --symbol NewSearcher
   vecgrep similar internal/search/search.go:50
   vecgrep similar --text "error handling" --lang go
   vecgrep similar 42 --exclude-same-file`,
@@ -150,9 +223,14 @@ Examples:
 var deleteCmd = &cobra.Command{
 	Use:   "delete <file-path>",
 	Short: "Delete a file from the index",
-	Long:  `Remove a file and all its chunks from the search index.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  runDelete,
+	Long: `Remove a file and all its chunks from the search index.
+
+The path is relative to the project root ("./" and Windows separators are
+accepted) or absolute within the project. The command fails when no indexed
+file matches. --dry-run reports how many chunks would be removed without
+changing the index.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDelete,
 }
 
 var cleanCmd = &cobra.Command{
@@ -164,7 +242,9 @@ With veclite-only storage all data is self-contained in collection records, so
 there are no orphaned rows to reclaim. This command flushes pending writes and
 prints record/file counts so you can confirm the index is consistent. If a
 future veclite release exposes a collection-level Compact() API, HNSW tombstone
-compaction will be wired in here.`,
+compaction will be wired in here.
+
+--dry-run reports the current counts without flushing.`,
 	RunE: runClean,
 }
 
@@ -399,7 +479,7 @@ embedding requests, and is the sole writer to each project's index — the CLI
 and MCP server connect over the socket or fall back to read-only sessions.
 
 Subcommands:
-  start    Start the daemon hub (foreground)
+  start    Start the daemon hub (foreground, or detached with --background)
   stop     Stop the running daemon hub
   status   Show daemon hub status and open projects`,
 }
@@ -412,7 +492,14 @@ var daemonStartCmd = &cobra.Command{
 The hub serves all projects over one socket. Any project roots given as
 arguments are pre-opened (warmed) at startup; others open lazily on first
 request. With no arguments, the current project (if cwd is inside one) is
-pre-opened.`,
+pre-opened.
+
+With --background the hub detaches from the terminal, writes its output to
+daemon.log in the data dir, and the command returns once the hub answers on
+its socket. 'vecgrep search' then uses the warm hub automatically.`,
+	Example: `  vecgrep daemon start
+  vecgrep daemon start --background
+  vecgrep daemon start --background ~/src/app ~/src/lib`,
 	RunE: runDaemonStart,
 }
 
@@ -435,6 +522,8 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringP("config", "c", "", "config file path")
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().String("log-level", "info", "log level: debug, info, warn, or error")
+	rootCmd.PersistentFlags().String("log-format", logging.FormatText, "log format: text or json")
 
 	// Bind flags to viper
 	viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
@@ -450,42 +539,95 @@ func init() {
 	indexCmd.Flags().Bool("dry-run", false, "preview changes without calling the embedding provider")
 	indexCmd.Flags().Bool("yes", false, "skip interactive plan confirmation (scripts/CI)")
 	indexCmd.Flags().String("structural-chunks", "", "codemap symbol chunks: auto, off, or required (overrides config)")
+	indexCmd.Flags().String("events", "", "write JSON-lines indexing events to this file, or to an inherited descriptor as fd:N")
+	indexCmd.Flags().Bool("rechunk-stale", false, "re-chunk only files whose chunk provenance differs from the current chunker settings")
+	indexCmd.Flags().Bool("defer-embeddings", false, "queue chunks without calling the embedding provider; embed them later with 'vecgrep embed-pending'")
+	indexCmd.Flags().StringSlice("ref", nil, "index these git refs (branches, tags, or commits) instead of the working tree; repeatable")
+
+	// Batch search command flags
+	batchSearchCmd.Flags().String("queries-file", "", "read queries from this file, one per line (- for stdin; blank and # lines skipped)")
+	batchSearchCmd.Flags().StringArrayP("query", "q", nil, "a query to run; repeatable")
+	batchSearchCmd.Flags().IntP("limit", "n", 3, "maximum results per query")
+	batchSearchCmd.Flags().String("dedupe", "chunk", "drop hits an earlier query returned: chunk, file (one result per file), or none")
+	batchSearchCmd.Flags().StringP("format", "f", "markdown", "output format (markdown or json)")
+	batchSearchCmd.Flags().String("out", "", "write results to this file instead of stdout")
+	batchSearchCmd.Flags().Int("concurrency", app.DefaultBatchSearchConcurrency, "queries searched at once")
+	batchSearchCmd.Flags().StringP("lang", "l", "", "filter by programming language")
+	batchSearchCmd.Flags().StringP("type", "t", "", "filter by chunk type (function, class, interface, const, config, block)")
+	batchSearchCmd.Flags().StringSlice("tag", nil, "filter by indexing.tags labels (repeatable or comma-separated; any matches)")
+	batchSearchCmd.Flags().StringP("mode", "m", "hybrid", "search mode: semantic, keyword, or hybrid")
+	batchSearchCmd.Flags().Int("max-snippet-lines", 0, "trim each result to N lines, keeping the signature and the lines that best match its query (0 = full chunk)")
+
+	// Reembed command flags
+	reembedCmd.Flags().Bool("only-stale", false, "re-embed only files whose vectors were produced by another embedding version")
+	reembedCmd.Flags().Bool("dry-run", false, "list the files that would be re-embedded without embedding them")
 
 	// Search command flags
 	searchCmd.Flags().IntP("limit", "n", 10, "maximum number of results")
-	searchCmd.Flags().StringP("format", "f", "default", "output format (default, json, compact, json-envelope)")
+	searchCmd.Flags().StringP("query", "q", "", "search query; positional arguments then scope results to those paths")
+	searchCmd.Flags().Bool("all-projects", false, "search every project registered in ~/.vecgrep/config.yaml and merge results")
+	searchCmd.Flags().StringP("format", "f", "default", "output format (default, json, compact, citations, json-envelope, openai, markdown, template)")
+	searchCmd.Flags().String("template", "", "Go template rendered once per result for --format template, e.g. '{{.RelativePath}}:{{.StartLine}} {{.Score}}'")
+	searchCmd.Flags().String("out", "", "write results to this file instead of stdout")
+	searchCmd.Flags().Bool("group-by-file", false, "markdown format: group results under one heading per file")
 	searchCmd.Flags().StringP("lang", "l", "", "filter by programming language")
 	searchCmd.Flags().StringSlice("languages", nil, "filter by multiple languages (comma-separated)")
-	searchCmd.Flags().StringP("type", "t", "", "filter by chunk type (function, class, block)")
+	searchCmd.Flags().StringP("type", "t", "", "filter by chunk type (function, class, interface, const, config, block)")
 	searchCmd.Flags().StringSlice("types", nil, "filter by multiple chunk types (comma-separated)")
 	searchCmd.Flags().String("file", "", "filter by file pattern (glob)")
 	searchCmd.Flags().String("dir", "", "filter by directory prefix")
 	searchCmd.Flags().String("lines", "", "filter by line range (e.g., '1-100')")
+	searchCmd.Flags().StringSlice("tag", nil, "filter by indexing.tags labels (repeatable or comma-separated; any matches)")
+	searchCmd.Flags().Int("ef-search", 0, "HNSW candidate list size for this search; higher trades latency for recall (default: vector.veclite.ef_search)")
 	searchCmd.Flags().StringP("mode", "m", "hybrid", "search mode: semantic, keyword, or hybrid")
 	searchCmd.Flags().Bool("explain", false, "show search diagnostics")
 	searchCmd.Flags().StringSlice("scope-files", nil, "restrict search to these relative paths (comma-separated)")
 	searchCmd.Flags().String("symbol", "", "scope search to a symbol's blast radius via codemap impact")
+	searchCmd.Flags().String("ref", "", "search a git ref indexed with 'vecgrep index --ref' instead of the working tree")
+	searchCmd.Flags().String("dedupe", "none", "collapse repeated hits: none, chunk, or file (one result per file)")
+	searchCmd.Flags().String("require-fresh", "", "refuse to search when files changed since the last index run or, with a duration (e.g. --require-fresh=24h), when that run is older")
+	searchCmd.Flags().Lookup("require-fresh").NoOptDefVal = "0"
+	searchCmd.Flags().String("stale-action", "fail", "what --require-fresh does with a stale index: fail or warn")
+	searchCmd.Flags().Bool("expand", false, "also search paraphrases of the query (search.expander_model, or built-in synonyms) and fuse the rankings; improves recall for vague queries")
+	searchCmd.Flags().Bool("rerank", false, "re-score the top results with the configured reranker (search.reranker); --rerank=false skips it")
+	searchCmd.Flags().Duration("timeout", 0, "return the results found within this time, marked partial, instead of waiting for slow stages (e.g. 500ms; default search.timeout, 0 = no limit)")
+	searchCmd.Flags().Int("max-snippet-lines", 0, "trim each result to N lines, keeping the signature and the lines that best match the query (0 = full chunk)")
 	searchCmd.Flags().Float32("min-score", 0, "drop results with score below this threshold (0-1 in all modes; keyword-mode scores are BM25 normalized to 0-1 within each result set)")
+	searchCmd.Flags().IntP("context", "C", 0, "show N lines of surrounding code before and after each result")
+	searchCmd.Flags().IntP("before-context", "B", 0, "show N lines of code before each result (overrides -C)")
+	searchCmd.Flags().IntP("after-context", "A", 0, "show N lines of code after each result (overrides -C)")
 
 	// Serve command flags
 	serveCmd.Flags().Bool("mcp", false, "start MCP server (stdio)")
+	serveCmd.Flags().Bool("mcp-http", false, "serve MCP over Streamable HTTP at /mcp instead of stdio")
+	serveCmd.Flags().String("host", "127.0.0.1", "address the --mcp-http server binds to")
+	serveCmd.Flags().Int("port", 8765, "port the --mcp-http server listens on")
+	serveCmd.Flags().String("audit-log", "", "append a JSON line per tool call to this file")
+	serveCmd.Flags().Bool("read-only", false, "serve only tools that neither write nor reach beyond this project")
+	serveCmd.Flags().String("auth-token", "", "bearer token every --mcp-http request must carry (default $VECGREP_AUTH_TOKEN, then server.auth_token)")
+	serveCmd.Flags().Int("rate-limit", 0, "cap each --mcp-http client address at this many requests a minute (0 = unlimited)")
+	serveCmd.Flags().Bool("expose-read-only", false, "serve a hardened search-only instance for teammates: --mcp-http on all interfaces, --read-only, a bearer token, and a rate limit")
 
 	// Similar command flags
 	similarCmd.Flags().IntP("limit", "n", 10, "maximum number of results")
-	similarCmd.Flags().StringP("format", "f", "default", "output format (default, json, compact)")
+	similarCmd.Flags().StringP("format", "f", "default", "output format (default, json, compact, template)")
+	similarCmd.Flags().String("template", "", "Go template rendered once per result for --format template")
 	similarCmd.Flags().StringP("lang", "l", "", "filter by programming language")
 	similarCmd.Flags().StringSlice("languages", nil, "filter by multiple languages (comma-separated)")
-	similarCmd.Flags().StringP("type", "t", "", "filter by chunk type (function, class, block)")
+	similarCmd.Flags().StringP("type", "t", "", "filter by chunk type (function, class, interface, const, config, block)")
 	similarCmd.Flags().StringSlice("types", nil, "filter by multiple chunk types (comma-separated)")
 	similarCmd.Flags().String("file", "", "filter by file pattern (glob)")
 	similarCmd.Flags().String("dir", "", "filter by directory prefix")
 	similarCmd.Flags().String("lines", "", "filter by line range (e.g., '1-100')")
+	similarCmd.Flags().StringSlice("tag", nil, "filter by indexing.tags labels (repeatable or comma-separated; any matches)")
 	similarCmd.Flags().Bool("exclude-same-file", false, "exclude results from the same file as the source")
 	similarCmd.Flags().StringP("text", "T", "", "find code similar to this text snippet")
+	similarCmd.Flags().String("symbol", "", "find code similar to the definition of this symbol name")
 	similarCmd.Flags().Float32("min-score", 0, "drop results with cosine similarity below this threshold (0-1)")
 
 	// Status command flags
 	statusCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+	statusCmd.Flags().Bool("cost", false, "show embedding token usage and estimated spend")
 
 	// Reset command flags
 	resetCmd.Flags().Bool("force", false, "skip confirmation prompt")
@@ -524,14 +666,145 @@ func init() {
 	memoryRecallCmd.Flags().String("tags", "", "comma-separated tags; a memory must carry ALL of them (AND)")
 	memoryRecallCmd.Flags().Float64("min-importance", 0, "minimum importance threshold (0-1)")
 	memoryRecallCmd.Flags().IntP("limit", "n", 10, "maximum number of results")
+	memoryRecallCmd.Flags().String("since", "", "only memories created at or after this time (RFC 3339, YYYY-MM-DD, or an age like 7d)")
+	memoryRecallCmd.Flags().String("until", "", "only memories created at or before this time (RFC 3339, YYYY-MM-DD, or an age like 7d)")
 	memoryRecallCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+	memoryRecallCmd.Flags().String("scope", "global", "memory store to search (global, project)")
 	memoryRememberCmd.Flags().String("tags", "", "comma-separated tags (e.g. codemap,<project_key>)")
 	memoryRememberCmd.Flags().Float64("importance", 0.5, "importance (0-1)")
 	memoryRememberCmd.Flags().Int("ttl-hours", 0, "expiration in hours (0 = never)")
+	memoryRememberCmd.Flags().String("scope", "global", "memory store to write (global, project)")
+	memoryListCmd.Flags().String("tags", "", "comma-separated tags; a memory must carry ALL of them (AND)")
+	memoryListCmd.Flags().String("since", "", "only memories created at or after this time (RFC 3339, YYYY-MM-DD, or an age like 7d)")
+	memoryListCmd.Flags().String("until", "", "only memories created at or before this time (RFC 3339, YYYY-MM-DD, or an age like 7d)")
+	memoryListCmd.Flags().IntP("limit", "n", 0, "maximum number of memories (0 = all)")
+	memoryListCmd.Flags().Bool("expired", false, "also list memories past their expiration")
+	memoryListCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+	memoryListCmd.Flags().String("scope", "global", "memory store to list (global, project)")
+	memoryForgetCmd.Flags().String("tags", "", "delete memories carrying ALL of these comma-separated tags")
+	memoryForgetCmd.Flags().String("older-than", "", "delete memories created before this time (RFC 3339, YYYY-MM-DD, or an age like 30d)")
+	memoryForgetCmd.Flags().Bool("expired", false, "delete memories past their expiration")
+	memoryForgetCmd.Flags().Bool("yes", false, "delete the memories a --tags/--older-than selection matches instead of listing them")
+	memoryForgetCmd.Flags().String("scope", "global", "memory store to delete from (global, project)")
+	memoryStatsCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+	memoryStatsCmd.Flags().String("scope", "global", "memory store to summarize (global, project)")
 
 	// Add memory subcommands
 	memoryCmd.AddCommand(memoryRecallCmd)
 	memoryCmd.AddCommand(memoryRememberCmd)
+	memoryCmd.AddCommand(memoryListCmd)
+	memoryCmd.AddCommand(memoryForgetCmd)
+	memoryCmd.AddCommand(memoryStatsCmd)
+
+	// Bookmark command flags
+	bookmarkAddCmd.Flags().String("note", "", "why this chunk matters")
+	bookmarkAddCmd.Flags().String("tags", "", "comma-separated tags")
+	bookmarkListCmd.Flags().String("tags", "", "comma-separated tags; a bookmark must carry ALL of them (AND)")
+	bookmarkListCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Add bookmark subcommands
+	bookmarkCmd.AddCommand(bookmarkAddCmd)
+	bookmarkCmd.AddCommand(bookmarkListCmd)
+	bookmarkCmd.AddCommand(bookmarkRemoveCmd)
+
+	// Annotate command flags and subcommands
+	annotateListCmd.Flags().String("file", "", "only list annotations on this file")
+	annotateListCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+	annotateCmd.AddCommand(annotateListCmd)
+	annotateCmd.AddCommand(annotateRemoveCmd)
+
+	// Feedback command flags and subcommands
+	feedbackCmd.Flags().StringP("query", "q", "", "the query the chunk was returned for")
+	feedbackCmd.Flags().Bool("relevant", false, "the chunk answers the query")
+	feedbackCmd.Flags().Bool("irrelevant", false, "the chunk does not answer the query")
+	feedbackListCmd.Flags().StringP("query", "q", "", "only list judgments for this query")
+	feedbackListCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+	feedbackExportCmd.Flags().StringP("output", "o", "", "write the dataset to this file instead of stdout")
+	feedbackCmd.AddCommand(feedbackListCmd)
+	feedbackCmd.AddCommand(feedbackExportCmd)
+
+	// Watch command flags
+	watchCmd.Flags().StringP("query", "q", "", "query to re-run after each batch of file changes")
+	watchCmd.Flags().Float32("min-score", 0.8, "report matches scoring at or above this threshold (0-1)")
+	watchCmd.Flags().String("exec", "", "command to run with new matches as JSON on stdin (default: print them)")
+	watchCmd.Flags().IntP("limit", "n", 20, "results considered per run")
+	watchCmd.Flags().StringP("mode", "m", "", "search mode: semantic, keyword, or hybrid (default from config)")
+	watchCmd.Flags().Duration("debounce", 0, "wait this long after the last change before re-indexing (default daemon.debounce)")
+	watchCmd.Flags().StringSlice("ignore", nil, "extra ignore pattern for this run (repeatable)")
+	watchCmd.Flags().Bool("poll", false, "find changes by rescanning the tree instead of file system events (default daemon.poll)")
+	watchCmd.Flags().Duration("poll-interval", 0, "time between rescans when polling (default daemon.poll_interval)")
+
+	// Verify command flags
+	verifyCmd.Flags().Bool("embeddings", false, "re-embed a sample of stored chunks and report drift")
+	verifyCmd.Flags().Int("sample", app.DefaultEmbeddingVerifySample, "number of chunks to re-embed")
+	verifyCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Doctor command flags
+	doctorCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Diff-index command flags
+	diffIndexCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Dupes command flags
+	dupesCmd.Flags().Float32("threshold", app.DefaultDuplicateThreshold, "minimum similarity for a duplicate pair")
+	dupesCmd.Flags().Int("min-lines", app.DefaultDuplicateMinLines, "ignore chunks shorter than this many lines")
+	dupesCmd.Flags().Float32("fail-over", 0, "exit non-zero when a pair not in the baseline scores at or above this similarity")
+	dupesCmd.Flags().String("baseline", "", "JSON file of accepted duplicate pairs")
+	dupesCmd.Flags().Bool("write-baseline", false, "write the current pairs to --baseline and exit")
+	dupesCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Top command flags
+	topCmd.Flags().String("by", app.TopBySimilarity, "leaderboard: similarity, size, or chunks")
+	topCmd.Flags().IntP("limit", "n", app.DefaultTopLimit, "number of entries to list")
+	topCmd.Flags().Int("min-lines", app.DefaultDuplicateMinLines, "similarity: ignore chunks shorter than this many lines")
+	topCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Deadcode command flags
+	deadcodeCmd.Flags().StringSliceP("lang", "l", nil, "only check symbols in these languages")
+	deadcodeCmd.Flags().Bool("include-tests", false, "also report symbols defined in test files")
+	deadcodeCmd.Flags().IntP("limit", "n", app.DefaultDeadCodeLimit, "maximum number of candidates")
+	deadcodeCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Calibrate command flags
+	calibrateCmd.Flags().IntP("queries", "n", app.DefaultCalibrationQueries, "number of sample queries per mode")
+	calibrateCmd.Flags().Bool("reset", false, "remove the stored calibration so searches report raw scores")
+	calibrateCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Bench command flags
+	benchCmd.Flags().Int("workers", 0, "files chunked and embedded concurrently (default: indexing.workers)")
+	benchCmd.Flags().Int("batch-size", 0, "chunks per embedding request (default: embedding.batch_size)")
+	benchCmd.Flags().Int("hnsw-m", 0, "HNSW links per node (default: vector.veclite.m)")
+	benchCmd.Flags().Int("ef-construction", 0, "HNSW build candidate list size (default: vector.veclite.ef_construction)")
+	benchCmd.Flags().Int("ef-search", 0, "HNSW search candidate list size (default: vector.veclite.ef_search)")
+	benchCmd.Flags().IntP("queries", "n", app.DefaultBenchQueries, "number of timed queries per mode")
+	benchCmd.Flags().Int("limit", 10, "results requested per query")
+	benchCmd.Flags().StringSlice("mode", nil, "search modes to time (keyword, semantic, hybrid; default all)")
+	benchCmd.Flags().Bool("search-only", false, "time searches on the existing index instead of building a scratch one")
+	benchCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Symbols command flags
+	symbolsCmd.Flags().StringP("lang", "l", "", "filter by programming language")
+	symbolsCmd.Flags().StringP("type", "t", "", "filter by chunk type (function, class, interface, const, config, block)")
+	symbolsCmd.Flags().IntP("limit", "n", app.DefaultSymbolsLimit, "maximum number of symbols to list")
+	symbolsCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Def command flags
+	defCmd.Flags().String("from", "", "file the symbol was seen in; nearer definitions rank higher")
+	defCmd.Flags().IntP("limit", "n", app.DefaultDefinitionLimit, "maximum number of candidates")
+	defCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+
+	// Chunk command flags
+	chunkCmd.Flags().Bool("debug", false, "print the boundary decisions behind the chunks")
+	chunkCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+	exportCmd.Flags().StringP("output", "o", "vecgrep-index.tar.gz", "archive path, or - for stdout")
+	importCmd.Flags().Bool("force", false, "replace an existing index")
+
+	// Summarize command flags and subcommands
+	summarizeCmd.Flags().String("command", "", "command that reads the summary request as JSON on stdin and prints the summary")
+	summarizeCmd.Flags().Duration("timeout", app.DefaultSummaryCommandTimeout, "time limit for --command")
+	summarizeCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+	summarizeListCmd.Flags().StringP("format", "f", "default", "output format (default, json)")
+	summarizeCmd.AddCommand(summarizeListCmd)
 
 	// Init command flags for global/local mode
 	initCmd.Flags().Bool("global", false, "register project in ~/.vecgrep/ (this is the default)")
@@ -542,11 +815,16 @@ func init() {
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(indexCmd)
+	rootCmd.AddCommand(embedPendingCmd)
+	rootCmd.AddCommand(reembedCmd)
 	rootCmd.AddCommand(searchCmd)
+	rootCmd.AddCommand(batchSearchCmd)
 	rootCmd.AddCommand(studioCmd)
 	rootCmd.AddCommand(serveCmd)
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(similarCmd)
+	deleteCmd.Flags().Bool("dry-run", false, "report what would be removed without changing the index")
+	cleanCmd.Flags().Bool("dry-run", false, "report index stats without syncing the database")
 	rootCmd.AddCommand(deleteCmd)
 	rootCmd.AddCommand(cleanCmd)
 	rootCmd.AddCommand(resetCmd)
@@ -555,9 +833,28 @@ func init() {
 	rootCmd.AddCommand(branchCmd)
 	rootCmd.AddCommand(daemonCmd)
 	rootCmd.AddCommand(memoryCmd)
+	rootCmd.AddCommand(bookmarkCmd)
+	rootCmd.AddCommand(annotateCmd)
+	rootCmd.AddCommand(feedbackCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(diffIndexCmd)
+	rootCmd.AddCommand(dupesCmd)
+	rootCmd.AddCommand(topCmd)
+	rootCmd.AddCommand(deadcodeCmd)
+	rootCmd.AddCommand(calibrateCmd)
+	rootCmd.AddCommand(benchCmd)
+	rootCmd.AddCommand(symbolsCmd)
+	rootCmd.AddCommand(defCmd)
+	rootCmd.AddCommand(chunkCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(summarizeCmd)
 	rootCmd.AddCommand(benchmarkCmd)
 
 	// Daemon subcommands
+	daemonStartCmd.Flags().Bool("background", false, "detach from the terminal and return once the hub is serving")
 	daemonCmd.AddCommand(daemonStartCmd)
 	daemonCmd.AddCommand(daemonStopCmd)
 	daemonCmd.AddCommand(daemonStatusCmd)
@@ -719,7 +1016,19 @@ func runIndex(cmd *cobra.Command, args []string) error {
 	// opening a second write session (which would collide with the daemon's
 	// lock — the "database file is locked by another process" error). --dry-run
 	// is a read-only preview, so it uses a read-only session instead.
+	eventsTarget, _ := cmd.Flags().GetString("events")
+	deferEmbeddings, _ := cmd.Flags().GetBool("defer-embeddings")
+	refs, _ := cmd.Flags().GetStringSlice("ref")
 	if gdir, err := config.GetGlobalConfigDir(); err == nil && daemon.IsRunning(gdir) {
+		if eventsTarget != "" {
+			return fmt.Errorf("--events is unavailable while the daemon hub owns the index; stop it with 'vecgrep daemon stop' first")
+		}
+		if deferEmbeddings {
+			return fmt.Errorf("--defer-embeddings is unavailable while the daemon hub owns the index; stop it with 'vecgrep daemon stop' first")
+		}
+		if len(refs) > 0 {
+			return fmt.Errorf("--ref is unavailable while the daemon hub owns the index; stop it with 'vecgrep daemon stop' first")
+		}
 		return indexViaDaemon(cmd, args, gdir)
 	}
 	structuralMode, _ := cmd.Flags().GetString("structural-chunks")
@@ -743,10 +1052,25 @@ func runIndex(cmd *cobra.Command, args []string) error {
 	additionalIgnores, _ := cmd.Flags().GetStringSlice("ignore")
 	yes, _ := cmd.Flags().GetBool("yes")
 	verbose, _ := rootCmd.PersistentFlags().GetBool("verbose")
-	scopedPaths := len(args) > 0
+	rechunkStale, err := rechunkStaleFlag(cmd, args, fullReindex)
+	if err != nil {
+		return err
+	}
+	if deferEmbeddings && fullReindex {
+		return fmt.Errorf("--defer-embeddings cannot be combined with --full: a full reindex clears the index before the queue is embedded")
+	}
+	if len(refs) > 0 && (len(args) > 0 || rechunkStale) {
+		return fmt.Errorf("--ref indexes whole refs and cannot be combined with paths or --rechunk-stale")
+	}
+	// --rechunk-stale is scoped to already-indexed files, like explicit paths,
+	// and refs never touch the working-tree plan.
+	scopedPaths := len(args) > 0 || rechunkStale || len(refs) > 0
 
 	// --dry-run: preview only (no embed, no confirm).
 	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		if len(refs) > 0 {
+			return fmt.Errorf("--dry-run previews the working tree and cannot be combined with --ref")
+		}
 		preview, err := service.DryRunPreviewWithStructuralMode(cmd.Context(), structuralMode)
 		if err != nil {
 			return fmt.Errorf("dry-run failed: %w", err)
@@ -794,12 +1118,31 @@ func runIndex(cmd *cobra.Command, args []string) error {
 		FullReindex:       fullReindex,
 		AdditionalIgnores: additionalIgnores,
 		StructuralChunks:  structuralMode,
+		RechunkStale:      rechunkStale,
+		DeferEmbeddings:   deferEmbeddings,
+		Refs:              refs,
+	}
+	if eventsTarget != "" {
+		events, err := openEventsTarget(eventsTarget)
+		if err != nil {
+			return err
+		}
+		defer events.Close()
+		req.Events = index.JSONLinesFileEvents(events)
 	}
 	if fullReindex {
 		fmt.Println("  Mode: full re-index")
+	} else if rechunkStale {
+		fmt.Println("  Mode: re-chunk stale files")
 	} else {
 		fmt.Println("  Mode: incremental")
 	}
+	if deferEmbeddings {
+		fmt.Println("  Embeddings: deferred")
+	}
+	if len(refs) > 0 {
+		fmt.Printf("  Refs: %s\n", strings.Join(refs, ", "))
+	}
 
 	var result *index.IndexResult
 	if showProgress {
@@ -857,6 +1200,21 @@ func runIndex(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Files skipped (unchanged): %d\n", result.FilesSkipped)
 	fmt.Printf("  Files deleted: %d\n", result.FilesDeleted)
 	fmt.Printf("  Chunks created: %d\n", result.ChunksCreated)
+	if result.ChunksReused > 0 {
+		fmt.Printf("  Chunks reused (unchanged): %d\n", result.ChunksReused)
+	}
+	if result.ChunksTruncated > 0 {
+		fmt.Printf("  Chunks truncated to the model's input limit: %d%s\n", result.ChunksTruncated, result.EstimateNote())
+	}
+	if result.ChunksSplit > 0 {
+		fmt.Printf("  Overlong chunks split: %d%s\n", result.ChunksSplit, result.EstimateNote())
+	}
+	if result.ChunksWithSecrets > 0 {
+		fmt.Printf("  Chunks with possible secrets: %d\n", result.ChunksWithSecrets)
+	}
+	if deferEmbeddings {
+		fmt.Printf("  Chunks queued for embedding: %d\n", result.ChunksDeferred)
+	}
 	fmt.Printf("  Duration: %s\n", result.Duration.Round(100*1000000))
 
 	if len(result.Errors) > 0 {
@@ -867,6 +1225,9 @@ func runIndex(cmd *cobra.Command, args []string) error {
 			}
 		}
 	}
+	if result.ChunksDeferred > 0 {
+		fmt.Println("\nRun 'vecgrep embed-pending' once the embedding provider is reachable.")
+	}
 
 	return nil
 }
@@ -876,6 +1237,34 @@ func runIndex(cmd *cobra.Command, args []string) error {
 // never opens a second write handle that would collide with the daemon's
 // exclusive lock); --dry-run uses a read-only session for the preview. It
 // forwards --full, selected paths, structural mode, and one-run ignores.
+// openEventsTarget opens the --events destination: "fd:N" writes to a
+// descriptor the caller passed down (a pipe, typically), anything else is a
+// file path created or truncated for this run.
+func openEventsTarget(target string) (*os.File, error) {
+	if fdText, ok := strings.CutPrefix(target, "fd:"); ok {
+		fd, err := strconv.Atoi(fdText)
+		if err != nil || fd < 1 {
+			return nil, fmt.Errorf("invalid --events %q: expected fd:N with N >= 1", target)
+		}
+		return os.NewFile(uintptr(fd), "events"), nil
+	}
+	f, err := os.Create(target)
+	if err != nil {
+		return nil, fmt.Errorf("open --events file: %w", err)
+	}
+	return f, nil
+}
+
+// rechunkStaleFlag reads --rechunk-stale, which selects its own files and so
+// cannot be combined with explicit paths or --full.
+func rechunkStaleFlag(cmd *cobra.Command, args []string, fullReindex bool) (bool, error) {
+	rechunkStale, _ := cmd.Flags().GetBool("rechunk-stale")
+	if rechunkStale && (fullReindex || len(args) > 0) {
+		return false, fmt.Errorf("--rechunk-stale cannot be combined with --full or paths")
+	}
+	return rechunkStale, nil
+}
+
 func indexViaDaemon(cmd *cobra.Command, args []string, globalDataDir string) error {
 	projectRoot, err := config.GetProjectRoot()
 	if err != nil {
@@ -890,7 +1279,11 @@ func indexViaDaemon(cmd *cobra.Command, args []string, globalDataDir string) err
 	fullReindex, _ := cmd.Flags().GetBool("full")
 	additionalIgnores, _ := cmd.Flags().GetStringSlice("ignore")
 	yes, _ := cmd.Flags().GetBool("yes")
-	scopedPaths := len(args) > 0
+	rechunkStale, err := rechunkStaleFlag(cmd, args, fullReindex)
+	if err != nil {
+		return err
+	}
+	scopedPaths := len(args) > 0 || rechunkStale
 
 	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
 		session, err := app.OpenReadOnlySession(cmd.Context(), "")
@@ -932,6 +1325,8 @@ func indexViaDaemon(cmd *cobra.Command, args []string, globalDataDir string) err
 	fmt.Printf("Indexing %s (via daemon)...\n", projectRoot)
 	if fullReindex {
 		fmt.Println("  Mode: full re-index")
+	} else if rechunkStale {
+		fmt.Println("  Mode: re-chunk stale files")
 	} else {
 		fmt.Println("  Mode: incremental")
 	}
@@ -941,6 +1336,7 @@ func indexViaDaemon(cmd *cobra.Command, args []string, globalDataDir string) err
 		FullReindex:       fullReindex,
 		AdditionalIgnores: additionalIgnores,
 		StructuralChunks:  structuralMode,
+		RechunkStale:      rechunkStale,
 	})
 	if err != nil {
 		return fmt.Errorf("delegate to daemon: %w", err)
@@ -951,6 +1347,15 @@ func indexViaDaemon(cmd *cobra.Command, args []string, globalDataDir string) err
 	fmt.Printf("  Files skipped (unchanged): %d\n", result.FilesSkipped)
 	fmt.Printf("  Files deleted: %d\n", result.FilesDeleted)
 	fmt.Printf("  Chunks created: %d\n", result.ChunksCreated)
+	if result.ChunksTruncated > 0 {
+		fmt.Printf("  Chunks truncated to the model's input limit: %d%s\n", result.ChunksTruncated, result.EstimateNote())
+	}
+	if result.ChunksSplit > 0 {
+		fmt.Printf("  Overlong chunks split: %d%s\n", result.ChunksSplit, result.EstimateNote())
+	}
+	if result.ChunksWithSecrets > 0 {
+		fmt.Printf("  Chunks with possible secrets: %d\n", result.ChunksWithSecrets)
+	}
 	fmt.Printf("  Duration: %s\n", result.Duration.Round(100*1000000))
 	if len(result.Errors) > 0 {
 		fmt.Printf("\nWarnings: %d\n", len(result.Errors))
@@ -965,8 +1370,16 @@ func indexViaDaemon(cmd *cobra.Command, args []string, globalDataDir string) err
 
 func runSearch(cmd *cobra.Command, args []string) error {
 	query := strings.Join(args, " ")
+	var pathArgs []string
+	if flagQuery, _ := cmd.Flags().GetString("query"); flagQuery != "" {
+		query, pathArgs = flagQuery, args
+	}
 	limit, _ := cmd.Flags().GetInt("limit")
 	format, _ := cmd.Flags().GetString("format")
+	resultTmpl, err := resultTemplate(cmd, &format)
+	if err != nil {
+		return err
+	}
 	lang, _ := cmd.Flags().GetString("lang")
 	languages, _ := cmd.Flags().GetStringSlice("languages")
 	chunkType, _ := cmd.Flags().GetString("type")
@@ -979,6 +1392,50 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	scopeFiles, _ := cmd.Flags().GetStringSlice("scope-files")
 	symbol, _ := cmd.Flags().GetString("symbol")
 	minScore, _ := cmd.Flags().GetFloat32("min-score")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	efSearch, _ := cmd.Flags().GetInt("ef-search")
+	dedupeStr, _ := cmd.Flags().GetString("dedupe")
+	maxSnippetLines, _ := cmd.Flags().GetInt("max-snippet-lines")
+	contextBefore, contextAfter, err := searchContextLines(cmd)
+	if err != nil {
+		return err
+	}
+	outPath, _ := cmd.Flags().GetString("out")
+	groupByFile, _ := cmd.Flags().GetBool("group-by-file")
+	allProjects, _ := cmd.Flags().GetBool("all-projects")
+	ref, _ := cmd.Flags().GetString("ref")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	if timeout < 0 {
+		return fmt.Errorf("invalid --timeout %s: must not be negative", timeout)
+	}
+	expand, _ := cmd.Flags().GetBool("expand")
+	dedupe, err := search.ParseDedupeMode(dedupeStr)
+	if err != nil {
+		return err
+	}
+	if ref != "" && cmd.Flags().Changed("require-fresh") {
+		return fmt.Errorf("--require-fresh checks the working tree and cannot be combined with --ref")
+	}
+	var rerank *bool
+	if cmd.Flags().Changed("rerank") {
+		enabled, _ := cmd.Flags().GetBool("rerank")
+		rerank = &enabled
+	}
+	requireFresh := cmd.Flags().Changed("require-fresh")
+	var maxIndexAge time.Duration
+	if requireFresh {
+		value, _ := cmd.Flags().GetString("require-fresh")
+		if maxIndexAge, err = time.ParseDuration(value); err != nil || maxIndexAge < 0 {
+			return fmt.Errorf("invalid --require-fresh %q: expected a duration such as 30m or 24h", value)
+		}
+	}
+	staleAction, _ := cmd.Flags().GetString("stale-action")
+	if staleAction != "fail" && staleAction != "warn" {
+		return fmt.Errorf("invalid --stale-action %q: expected fail or warn", staleAction)
+	}
+	if efSearch < 0 {
+		return fmt.Errorf("invalid --ef-search %d: must be positive", efSearch)
+	}
 
 	// Parse line range
 	var minLine, maxLine int
@@ -986,13 +1443,57 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		minLine, maxLine = app.ParseLineRange(linesRange)
 	}
 
+	if allProjects {
+		if len(pathArgs) > 0 || len(scopeFiles) > 0 || symbol != "" || requireFresh || ref != "" {
+			return fmt.Errorf("--all-projects cannot be combined with path scopes, --scope-files, --symbol, --ref, or --require-fresh")
+		}
+		if format != "default" && format != "json" && format != "compact" && format != "template" {
+			return fmt.Errorf("--all-projects supports the default, json, compact, and template formats")
+		}
+		resp, err := app.SearchAllProjects(cmd.Context(), app.SearchRequest{
+			Query:       query,
+			Limit:       limit,
+			Language:    lang,
+			Languages:   languages,
+			ChunkType:   chunkType,
+			ChunkTypes:  chunkTypes,
+			FilePattern: filePattern,
+			Directory:   directory,
+			MinLine:     minLine,
+			MaxLine:     maxLine,
+			MinScore:    minScore,
+			Tags:        tags,
+			EfSearch:    efSearch,
+			Mode:        search.SearchMode(modeStr),
+			Dedupe:      dedupe,
+			Rerank:      rerank,
+			Timeout:     timeout,
+			Expand:      expand,
+		})
+		if err != nil {
+			return err
+		}
+		for _, w := range resp.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+		}
+		search.ExpandResults("", resp.Results, contextBefore, contextAfter)
+		search.TruncateResults(resp.Results, query, maxSnippetLines)
+		printSearchResults(resp.Results, format, resultTmpl)
+		return nil
+	}
+
 	// Try searching via the daemon socket first. If the daemon is running,
 	// this avoids opening a separate read-only session and re-initializing
 	// the embedding provider. Falls back transparently if the socket is
 	// unavailable or the request fails. The json-envelope format needs
-	// index metadata from a session, so it always takes the session path.
-	if format != "json-envelope" {
-		if ok := tryDaemonSearch(cmd.Context(), query, limit, modeStr, lang, languages, chunkTypes, chunkType, filePattern, directory, minLine, maxLine, minScore, explain, format, scopeFiles, symbol); ok {
+	// index metadata from a session, dedupe needs the service's over-fetch,
+	// markdown reports and --out need the project root for links, and
+	// --require-fresh checks the working tree against the index, --ref
+	// resolves against the session's data directory, and --expand reads the
+	// session's expander settings, so these always take the session path.
+	if format != "json-envelope" && format != "markdown" && dedupe == search.DedupeNone && outPath == "" && len(pathArgs) == 0 && !requireFresh && ref == "" && !expand {
+		if results, mode, ok := tryDaemonSearch(cmd.Context(), query, limit, modeStr, lang, languages, chunkTypes, chunkType, filePattern, directory, minLine, maxLine, minScore, tags, efSearch, explain, format, resultTmpl, scopeFiles, symbol, maxSnippetLines, contextBefore, contextAfter, rerank, timeout); ok {
+			runPostSearchHooks(cmd.Context(), nil, "", query, mode, results)
 			return nil
 		}
 	}
@@ -1020,6 +1521,15 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if requireFresh {
+		if err := service.RequireFresh(cmd.Context(), maxIndexAge); err != nil {
+			if staleAction != "warn" || !errors.Is(err, app.ErrIndexStale) {
+				return err
+			}
+			noteOut("Warning: %s\n", err)
+		}
+	}
+
 	// Resolve file scoping. When --symbol is set, use codemap impact to
 	// compute the blast radius. When --scope-files is set, use it directly.
 	var filePaths []string
@@ -1033,6 +1543,14 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		}
 		filePaths = resolvedPaths
 	}
+	pathDirs, pathFiles, err := resolveSearchPaths(session.ProjectRoot, pathArgs)
+	if err != nil {
+		return err
+	}
+	if len(pathDirs)+len(pathFiles) > 0 {
+		noteOut("Scope: %s\n", strings.Join(pathArgs, ", "))
+	}
+	filePaths = append(filePaths, pathFiles...)
 
 	resp, err := service.Search(cmd.Context(), app.SearchRequest{
 		Query:       query,
@@ -1043,12 +1561,20 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		ChunkTypes:  chunkTypes,
 		FilePattern: filePattern,
 		Directory:   directory,
+		Directories: pathDirs,
 		FilePaths:   filePaths,
 		MinLine:     minLine,
 		MaxLine:     maxLine,
 		MinScore:    minScore,
+		Tags:        tags,
+		EfSearch:    efSearch,
+		Ref:         ref,
 		Mode:        mode,
 		Explain:     explain,
+		Dedupe:      dedupe,
+		Rerank:      rerank,
+		Timeout:     timeout,
+		Expand:      expand,
 	})
 	if err != nil {
 		return fmt.Errorf("search failed: %w", err)
@@ -1060,6 +1586,9 @@ func runSearch(cmd *cobra.Command, args []string) error {
 	for _, w := range resp.Warnings {
 		noteOut("Warning: %s\n", w)
 	}
+	if resp.Translation != nil {
+		noteOut("Query translated from %s: %q\n", resp.Translation.Language, resp.Translation.Translated)
+	}
 
 	if explain && resp.Diagnostics != nil {
 		// Print explanation. Routed to stderr for machine formats so stdout
@@ -1072,11 +1601,35 @@ func runSearch(cmd *cobra.Command, args []string) error {
 		noteOut("\n")
 	}
 
+	search.ExpandResults(session.ProjectRoot, resp.Results, contextBefore, contextAfter)
+	search.TruncateResults(resp.Results, query, maxSnippetLines)
+	out := io.Writer(os.Stdout)
+	var outFile *os.File
+	if outPath != "" {
+		if outFile, err = os.Create(outPath); err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer outFile.Close()
+		out = outFile
+	}
 	if format == "json-envelope" {
-		return printSearchEnvelope(cmd.Context(), service, resp.Results)
+		if err := printSearchEnvelope(cmd.Context(), out, service, resp.Results, resp.Partial); err != nil {
+			return err
+		}
+	} else {
+		markdown := search.MarkdownOptions{GroupByFile: groupByFile}
+		if format == "markdown" {
+			markdown.LinkBase = reportLinkBase(session.ProjectRoot, outPath)
+		}
+		fmt.Fprint(out, renderQueryResults(query, resp.Results, format, resultTmpl, markdown))
 	}
-
-	printSearchResults(resp.Results, format)
+	if outFile != nil {
+		if err := outFile.Close(); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d result(s) to %s\n", len(resp.Results), outPath)
+	}
+	runPostSearchHooks(cmd.Context(), session.Config, session.ProjectRoot, query, string(resp.Mode), resp.Results)
 	return nil
 }
 
@@ -1097,22 +1650,119 @@ func isCharDevice(f *os.File) bool {
 	return err == nil && (info.Mode()&os.ModeCharDevice) != 0
 }
 
-// printSearchResults formats and prints search results.
-func printSearchResults(results []search.Result, format string) {
+// printSearchResults formats and prints search results. tmpl renders the
+// template format and may be nil otherwise.
+func printSearchResults(results []search.Result, format string, tmpl *template.Template) {
+	if format == "template" {
+		fmt.Print(renderTemplate(results, tmpl))
+		return
+	}
 	fmt.Print(render.Results(results, render.ParseOutputFormat(format)))
 }
 
-// isMachineFormat reports whether format emits machine-parseable output on
-// stdout, where any leading non-JSON text (scope notes, diagnostics) would
-// corrupt a single-document decode. json/compact/json-envelope all qualify.
+// printQueryResults prints search results, adding the formats that carry the
+// query itself.
+func printQueryResults(query string, results []search.Result, format string, tmpl *template.Template) {
+	fmt.Print(renderQueryResults(query, results, format, tmpl, search.MarkdownOptions{}))
+}
+
+// resultTemplate parses --template for the template format. Setting
+// --template alone selects that format; it is an error with any other
+// --format, as is --format template without a template.
+func resultTemplate(cmd *cobra.Command, format *string) (*template.Template, error) {
+	text, _ := cmd.Flags().GetString("template")
+	switch {
+	case text == "" && *format == "template":
+		return nil, fmt.Errorf("--format template needs --template")
+	case text == "":
+		return nil, nil
+	case !cmd.Flags().Changed("format"):
+		*format = "template"
+	case *format != "template":
+		return nil, fmt.Errorf("--template applies to --format template only")
+	}
+	return search.ParseResultTemplate(text)
+}
+
+// renderQueryResults renders search results in format. "openai" emits the
+// OpenAI vector store search response shape so RAG clients built on that API
+// can use vecgrep as their retriever; "markdown" is a shareable report;
+// "template" runs tmpl once per result.
+func renderQueryResults(query string, results []search.Result, format string, tmpl *template.Template, markdown search.MarkdownOptions) string {
+	switch format {
+	case "template":
+		return renderTemplate(results, tmpl)
+	case "openai":
+		data, err := json.MarshalIndent(search.NewOpenAISearchPage(query, results), "", "  ")
+		if err != nil {
+			return fmt.Sprintf(`{"error": %q}`+"\n", err.Error())
+		}
+		return string(data) + "\n"
+	case "markdown":
+		return search.FormatMarkdownReport(query, results, markdown)
+	default:
+		return render.Results(results, render.ParseOutputFormat(format))
+	}
+}
+
+// renderTemplate renders results with a --template, warning on stderr when
+// the template fails partway.
+func renderTemplate(results []search.Result, tmpl *template.Template) string {
+	out, err := search.FormatResultsTemplate(results, tmpl)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return out
+}
+
+// reportLinkBase returns the path from the directory a report is read in
+// (outPath's directory, or the working directory for stdout) to the project
+// root, so report links resolve to the source files.
+func reportLinkBase(projectRoot, outPath string) string {
+	from, err := os.Getwd()
+	if outPath != "" {
+		from, err = filepath.Abs(filepath.Dir(outPath))
+	}
+	if err != nil {
+		return ""
+	}
+	rel, err := filepath.Rel(from, projectRoot)
+	if err != nil {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
+
+// isMachineFormat reports whether format emits a single document on stdout,
+// where any leading text (scope notes, diagnostics) would corrupt a decode or
+// a saved report. json/compact/citations/json-envelope/openai/markdown all
+// qualify.
 func isMachineFormat(format string) bool {
 	switch format {
-	case "json", "compact", "json-envelope":
+	case "json", "compact", "citations", "json-envelope", "openai", "markdown", "template":
 		return true
 	}
 	return false
 }
 
+// searchContextLines resolves -C/-B/-A into the number of lines to show
+// before and after each result. -B and -A override -C on their side, as in
+// grep.
+func searchContextLines(cmd *cobra.Command) (before, after int, err error) {
+	both, _ := cmd.Flags().GetInt("context")
+	before, after = both, both
+	if cmd.Flags().Changed("before-context") {
+		before, _ = cmd.Flags().GetInt("before-context")
+	}
+	if cmd.Flags().Changed("after-context") {
+		after, _ = cmd.Flags().GetInt("after-context")
+	}
+	if both < 0 || before < 0 || after < 0 {
+		return 0, 0, fmt.Errorf("context line counts must not be negative")
+	}
+	return before, after, nil
+}
+
 const searchEnvelopeSchemaVersion = 1
 
 type searchEnvelope struct {
@@ -1123,13 +1773,16 @@ type searchEnvelope struct {
 		Chunks  int  `json:"chunks"`
 	} `json:"index"`
 	Hits []search.Result `json:"hits"`
+	// Partial is set when --timeout (or search.timeout) cut the search
+	// short and Hits are the best found in time.
+	Partial bool `json:"partial,omitempty"`
 }
 
 // printSearchEnvelope emits the json-envelope contract: a single JSON object
 // carrying index state alongside the hits, so a consumer can distinguish
 // "never indexed" (indexed=false) from "indexed but nothing matched"
 // (indexed=true, hits=[]). The bare-array `json` format is unchanged.
-func printSearchEnvelope(ctx context.Context, service *app.Service, results []search.Result) error {
+func printSearchEnvelope(ctx context.Context, w io.Writer, service *app.Service, results []search.Result, partial bool) error {
 	indexed, fresh, chunks, err := service.IndexMeta(ctx)
 	if err != nil {
 		return fmt.Errorf("index metadata: %w", err)
@@ -1137,6 +1790,7 @@ func printSearchEnvelope(ctx context.Context, service *app.Service, results []se
 	envelope := searchEnvelope{
 		SchemaVersion: searchEnvelopeSchemaVersion,
 		Hits:          results,
+		Partial:       partial,
 	}
 	envelope.Index.Indexed = indexed
 	envelope.Index.Fresh = fresh
@@ -1148,15 +1802,15 @@ func printSearchEnvelope(ctx context.Context, service *app.Service, results []se
 	if err != nil {
 		return fmt.Errorf("marshal envelope: %w", err)
 	}
-	fmt.Println(string(data))
+	fmt.Fprintln(w, string(data))
 	return nil
 }
 
 // tryDaemonSearch attempts to run a search through the daemon's unix socket.
-// It returns (true) if the search was performed and results were rendered,
-// or (false) if the daemon socket is unavailable, the request failed, or
-// the query uses filters the daemon protocol does not yet support (in which
-// case the caller falls back to a read-only session).
+// It returns the rendered results and ok=true if the search was performed,
+// or ok=false if the daemon socket is unavailable, the request failed, or
+// the search needs --explain or --symbol (in which case the caller falls
+// back to a read-only session).
 func tryDaemonSearch(
 	ctx context.Context,
 	query string,
@@ -1166,39 +1820,46 @@ func tryDaemonSearch(
 	chunkType, filePattern, directory string,
 	minLine, maxLine int,
 	minScore float32,
+	tags []string,
+	efSearch int,
 	explain bool,
 	format string,
+	tmpl *template.Template,
 	scopeFiles []string,
 	symbol string,
-) bool {
+	maxSnippetLines int,
+	contextBefore, contextAfter int,
+	rerank *bool,
+	timeout time.Duration,
+) (results []search.Result, mode string, ok bool) {
 	_ = ctx // reserved for future context-aware socket dial
 
-	// The daemon search protocol currently supports query, limit, mode, and
-	// language. If more complex filters are requested, fall back to the
-	// read-only session which has full filter support.
-	if len(languages) > 0 || len(chunkTypes) > 0 || chunkType != "" || filePattern != "" || directory != "" || minLine != 0 || maxLine != 0 || explain || len(scopeFiles) > 0 || symbol != "" {
-		return false
+	// The daemon applies every filter a session does, but it returns no
+	// diagnostics and cannot resolve codemap symbol scopes, so --explain and
+	// --symbol fall back to the read-only session.
+	if explain || symbol != "" {
+		return nil, "", false
 	}
 
 	// Find the project root and data dir to locate the daemon socket.
 	cwd, err := os.Getwd()
 	if err != nil {
-		return false
+		return nil, "", false
 	}
 	projectRoot, err := config.FindProjectRootFrom(cwd)
 	if err != nil {
-		return false
+		return nil, "", false
 	}
 	// The hub listens on one global socket and routes by project root.
 	globalDir, err := config.GetGlobalConfigDir()
 	if err != nil {
-		return false
+		return nil, "", false
 	}
 	socketPath := filepath.Join(globalDir, "daemon.sock")
 
 	conn, err := net.Dial("unix", socketPath)
 	if err != nil {
-		return false // daemon not running
+		return nil, "", false // daemon not running
 	}
 	defer conn.Close()
 
@@ -1206,19 +1867,43 @@ func tryDaemonSearch(
 	dec := json.NewDecoder(conn)
 
 	params := struct {
-		Project  string  `json:"project"`
-		Query    string  `json:"query"`
-		Limit    int     `json:"limit"`
-		Mode     string  `json:"mode"`
-		Language string  `json:"language,omitempty"`
-		MinScore float32 `json:"min_score,omitempty"`
+		Project     string   `json:"project"`
+		Query       string   `json:"query"`
+		Limit       int      `json:"limit"`
+		Mode        string   `json:"mode"`
+		Language    string   `json:"language,omitempty"`
+		Languages   []string `json:"languages,omitempty"`
+		ChunkTypes  []string `json:"chunk_types,omitempty"`
+		ChunkType   string   `json:"chunk_type,omitempty"`
+		FilePattern string   `json:"file_pattern,omitempty"`
+		Directory   string   `json:"directory,omitempty"`
+		MinLine     int      `json:"min_line,omitempty"`
+		MaxLine     int      `json:"max_line,omitempty"`
+		MinScore    float32  `json:"min_score,omitempty"`
+		Tags        []string `json:"tags,omitempty"`
+		EfSearch    int      `json:"ef_search,omitempty"`
+		FilePaths   []string `json:"file_paths,omitempty"`
+		Rerank      *bool    `json:"rerank,omitempty"`
+		TimeoutMS   int      `json:"timeout_ms,omitempty"`
 	}{
-		Project:  projectRoot,
-		Query:    query,
-		Limit:    limit,
-		Mode:     modeStr,
-		Language: lang,
-		MinScore: minScore,
+		Project:     projectRoot,
+		Query:       query,
+		Limit:       limit,
+		Mode:        modeStr,
+		Language:    lang,
+		Languages:   languages,
+		ChunkTypes:  chunkTypes,
+		ChunkType:   chunkType,
+		FilePattern: filePattern,
+		Directory:   directory,
+		MinLine:     minLine,
+		MaxLine:     maxLine,
+		MinScore:    minScore,
+		Tags:        tags,
+		EfSearch:    efSearch,
+		FilePaths:   scopeFiles,
+		Rerank:      rerank,
+		TimeoutMS:   int(timeout.Milliseconds()),
 	}
 	paramsJSON, _ := json.Marshal(params)
 
@@ -1233,7 +1918,7 @@ func tryDaemonSearch(
 		Method:  "daemon.search",
 		Params:  paramsJSON,
 	}); err != nil {
-		return false
+		return nil, "", false
 	}
 
 	var resp struct {
@@ -1248,10 +1933,10 @@ func tryDaemonSearch(
 		} `json:"error,omitempty"`
 	}
 	if err := dec.Decode(&resp); err != nil {
-		return false
+		return nil, "", false
 	}
 	if resp.Error != nil {
-		return false // let the fallback handle the real error
+		return nil, "", false // let the fallback handle the real error
 	}
 
 	// Surface degraded-mode diagnostics (e.g. embedder unavailable →
@@ -1266,8 +1951,10 @@ func tryDaemonSearch(
 		}
 	}
 
-	printSearchResults(resp.Result.Results, format)
-	return true
+	search.ExpandResults(projectRoot, resp.Result.Results, contextBefore, contextAfter)
+	search.TruncateResults(resp.Result.Results, query, maxSnippetLines)
+	printQueryResults(query, resp.Result.Results, format, tmpl)
+	return resp.Result.Results, resp.Result.Mode, true
 }
 
 // resolveSymbolScope uses codemap impact to compute the blast radius of a
@@ -1337,35 +2024,190 @@ func runServe(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
-	mcpServer := mcp.NewSDKServer(mcp.SDKServerConfig{ProjectRoot: projectRoot})
+	useHTTP, _ := cmd.Flags().GetBool("mcp-http")
+	host, _ := cmd.Flags().GetString("host")
+	readOnly, _ := cmd.Flags().GetBool("read-only")
+	authToken, _ := cmd.Flags().GetString("auth-token")
+	if authToken == "" {
+		authToken = os.Getenv("VECGREP_AUTH_TOKEN")
+	}
+	var resolved *config.ResolvedConfig
+	if projectRoot != "" {
+		if r, err := config.LoadResolved(projectRoot); err == nil {
+			resolved = r
+		}
+	}
+	if authToken == "" && resolved != nil {
+		authToken = resolved.Config.Server.AuthToken
+	}
+	rateLimit, _ := cmd.Flags().GetInt("rate-limit")
+	expose, _ := cmd.Flags().GetBool("expose-read-only")
+	if expose {
+		if projectRoot == "" {
+			return fmt.Errorf("--expose-read-only serves the current project; run it inside a vecgrep project")
+		}
+		useHTTP, readOnly = true, true
+		if !cmd.Flags().Changed("host") {
+			host = "0.0.0.0"
+		}
+		if !cmd.Flags().Changed("rate-limit") {
+			rateLimit = exposeReadOnlyRateLimit
+		}
+	}
+	if !useHTTP && (cmd.Flags().Changed("auth-token") || cmd.Flags().Changed("rate-limit")) {
+		return fmt.Errorf("--auth-token and --rate-limit apply to --mcp-http only")
+	}
+	if rateLimit < 0 {
+		return fmt.Errorf("--rate-limit must be 0 or positive")
+	}
+	if useHTTP && authToken == "" && (expose || !isLoopbackHost(host)) {
+		token, err := defaultAuthToken(resolved)
+		if err != nil {
+			return err
+		}
+		authToken = token
+	}
+
+	if dataDir := serveDataDir(resolved); dataDir != "" {
+		logFile, err := logging.OpenFile(filepath.Join(dataDir, serveLogFile), 0, 0)
+		if err != nil {
+			slog.Warn("serve log file unavailable, logging to stderr only", "err", err)
+		} else {
+			defer logFile.Close()
+			logging.SetOutput(io.MultiWriter(logging.Output(), logFile))
+		}
+	}
+
+	serverCfg := mcp.SDKServerConfig{ProjectRoot: projectRoot, ReadOnly: readOnly}
+	if useHTTP {
+		serverCfg.AuthToken = authToken
+		serverCfg.RateLimit = rateLimit
+	}
+	if auditPath, _ := cmd.Flags().GetString("audit-log"); auditPath != "" {
+		audit, err := mcp.OpenAuditLog(auditPath)
+		if err != nil {
+			return err
+		}
+		defer audit.Close()
+		serverCfg.AuditLog = audit
+	}
+	mcpServer := mcp.NewSDKServer(serverCfg)
+	if useHTTP {
+		port, _ := cmd.Flags().GetInt("port")
+		ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			return fmt.Errorf("listen for MCP HTTP: %w", err)
+		}
+		slog.Info("MCP server listening", "url", fmt.Sprintf("http://%s%s", ln.Addr(), mcp.HTTPPath), "sse", mcp.SSEPath)
+		if authToken != "" {
+			fmt.Fprintf(os.Stderr, "Browsers sign in once at http://%s%s?token=<token>\n", ln.Addr(), mcp.FilesPagePath)
+		}
+		return mcpServer.RunHTTP(ctx, ln)
+	}
 	return mcpServer.Run(ctx)
 }
 
+// exposeReadOnlyRateLimit is the per-client requests-a-minute cap of
+// --expose-read-only: ample for a person searching, low enough to blunt a
+// runaway script.
+const exposeReadOnlyRateLimit = 120
+
+// serveLogFile is the server's rotating log in the project data directory.
+const serveLogFile = "serve.log"
+
+// setupLogging installs the process-wide logger from --log-level and
+// --log-format.
+func setupLogging(cmd *cobra.Command) error {
+	level, _ := cmd.Flags().GetString("log-level")
+	format, _ := cmd.Flags().GetString("log-format")
+	return logging.Setup(os.Stderr, level, format)
+}
+
+// serveDataDir returns the directory serve keeps its token and log in: the
+// project's data directory, shared by its branches in global mode, or ""
+// outside a project.
+func serveDataDir(resolved *config.ResolvedConfig) string {
+	if resolved == nil {
+		return ""
+	}
+	if resolved.IsGlobalMode && resolved.ProjectName != "" {
+		if dir, err := config.GetProjectDataDir(resolved.ProjectName); err == nil {
+			return dir
+		}
+	}
+	return resolved.Config.DataDir
+}
+
+// defaultAuthToken returns the token of a server that binds beyond
+// loopback without one configured: the one kept in the project's data
+// directory (shared by its branches), generated on the first run, or a
+// token for this session alone outside a project.
+func defaultAuthToken(resolved *config.ResolvedConfig) (string, error) {
+	dataDir := serveDataDir(resolved)
+	if dataDir == "" {
+		token, err := mcp.GenerateAuthToken()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(os.Stderr, "Bearer token for this session: %s\n(set server.auth_token or VECGREP_AUTH_TOKEN to keep one across restarts)\n", token)
+		return token, nil
+	}
+	path := filepath.Join(dataDir, mcp.AuthTokenFile)
+	token, created, err := mcp.LoadOrCreateAuthToken(path)
+	if err != nil {
+		return "", err
+	}
+	if created {
+		fmt.Fprintf(os.Stderr, "Generated a bearer token, kept in %s: %s\n", path, token)
+	} else {
+		fmt.Fprintf(os.Stderr, "Bearer token: see %s\n", path)
+	}
+	return token, nil
+}
+
+// isLoopbackHost reports whether a --host value binds only the local
+// machine.
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // StatusOutput represents the JSON output for the status command
 type StatusOutput struct {
-	ProjectRoot      string                    `json:"project_root"`
-	DataDir          string                    `json:"data_dir"`
-	Database         string                    `json:"database"`
-	VectorBackend    string                    `json:"vector_backend"`
-	EmbeddingModel   string                    `json:"embedding_model"`
-	Provider         string                    `json:"provider"`
-	Dimensions       int                       `json:"dimensions"`
-	ProfilePath      string                    `json:"profile_path"`
-	ProfileStatus    string                    `json:"profile_status"`
-	ProfileMatches   bool                      `json:"profile_matches"`
-	CurrentProfile   app.EmbeddingProfile      `json:"current_profile"`
-	StoredProfile    *app.EmbeddingProfile     `json:"stored_profile,omitempty"`
-	VecLiteBytes     int64                     `json:"veclite_bytes"`
-	IndexedBytes     int64                     `json:"indexed_bytes"`
-	LatestIndexed    string                    `json:"latest_indexed_at,omitempty"`
-	IndexFresh       bool                      `json:"index_fresh"`
-	Stats            map[string]int64          `json:"stats"`
-	Languages        map[string]int64          `json:"languages,omitempty"`
-	ChunkTypes       map[string]int64          `json:"chunk_types,omitempty"`
-	PendingChanges   *PendingChanges           `json:"pending_changes,omitempty"`
-	IngestionReceipt *app.IngestionReceipt     `json:"ingestion_receipt,omitempty"`
-	ReceiptError     string                    `json:"ingestion_receipt_error,omitempty"`
-	Freshness        *app.IndexFreshnessReport `json:"freshness,omitempty"`
+	ProjectRoot       string                    `json:"project_root"`
+	DataDir           string                    `json:"data_dir"`
+	Database          string                    `json:"database"`
+	VectorBackend     string                    `json:"vector_backend"`
+	EmbeddingModel    string                    `json:"embedding_model"`
+	Provider          string                    `json:"provider"`
+	Dimensions        int                       `json:"dimensions"`
+	ProfilePath       string                    `json:"profile_path"`
+	ProfileStatus     string                    `json:"profile_status"`
+	ProfileMatches    bool                      `json:"profile_matches"`
+	CurrentProfile    app.EmbeddingProfile      `json:"current_profile"`
+	StoredProfile     *app.EmbeddingProfile     `json:"stored_profile,omitempty"`
+	VecLiteBytes      int64                     `json:"veclite_bytes"`
+	Quantization      string                    `json:"quantization"`
+	FullVectorsBytes  int64                     `json:"full_vectors_bytes,omitempty"`
+	IndexedBytes      int64                     `json:"indexed_bytes"`
+	LatestIndexed     string                    `json:"latest_indexed_at,omitempty"`
+	IndexFresh        bool                      `json:"index_fresh"`
+	Stats             map[string]int64          `json:"stats"`
+	Languages         map[string]int64          `json:"languages,omitempty"`
+	LanguageLines     map[string]int64          `json:"language_lines,omitempty"`
+	LanguageBytes     map[string]int64          `json:"language_bytes,omitempty"`
+	ChunkTypes        map[string]int64          `json:"chunk_types,omitempty"`
+	PendingChanges    *PendingChanges           `json:"pending_changes,omitempty"`
+	IngestionReceipt  *app.IngestionReceipt     `json:"ingestion_receipt,omitempty"`
+	ReceiptError      string                    `json:"ingestion_receipt_error,omitempty"`
+	Freshness         *app.IndexFreshnessReport `json:"freshness,omitempty"`
+	Provenance        *app.ProvenanceReport     `json:"provenance,omitempty"`
+	Health            *app.IndexHealth          `json:"health,omitempty"`
+	PendingEmbeddings *app.PendingEmbeddings    `json:"pending_embeddings,omitempty"`
+	Cost              *CostOutput               `json:"cost,omitempty"`
 }
 
 // PendingChanges represents pending reindex changes
@@ -1378,31 +2220,38 @@ type PendingChanges struct {
 
 func statusOutputFromResponse(status *app.StatusResponse) StatusOutput {
 	output := StatusOutput{
-		ProjectRoot:      status.ProjectRoot,
-		DataDir:          status.DataDir,
-		Database:         status.VecLitePath,
-		VectorBackend:    status.VectorBackend,
-		EmbeddingModel:   status.Model,
-		Provider:         status.Provider,
-		Dimensions:       status.Dimensions,
-		ProfilePath:      status.ProfilePath,
-		ProfileStatus:    status.ProfileStatus,
-		ProfileMatches:   status.ProfileMatches,
-		CurrentProfile:   status.CurrentProfile,
-		StoredProfile:    status.StoredProfile,
-		VecLiteBytes:     status.VecLiteSizeBytes,
-		IndexedBytes:     status.IndexedBytes,
-		IndexFresh:       status.IndexFresh,
-		Stats:            status.Stats,
-		IngestionReceipt: status.IngestionReceipt,
-		ReceiptError:     status.ReceiptError,
-		Freshness:        status.Freshness,
+		ProjectRoot:       status.ProjectRoot,
+		DataDir:           status.DataDir,
+		Database:          status.VecLitePath,
+		VectorBackend:     status.VectorBackend,
+		EmbeddingModel:    status.Model,
+		Provider:          status.Provider,
+		Dimensions:        status.Dimensions,
+		ProfilePath:       status.ProfilePath,
+		ProfileStatus:     status.ProfileStatus,
+		ProfileMatches:    status.ProfileMatches,
+		CurrentProfile:    status.CurrentProfile,
+		StoredProfile:     status.StoredProfile,
+		VecLiteBytes:      status.VecLiteSizeBytes,
+		Quantization:      status.Quantization,
+		FullVectorsBytes:  status.FullVectorBytes,
+		IndexedBytes:      status.IndexedBytes,
+		IndexFresh:        status.IndexFresh,
+		Stats:             status.Stats,
+		IngestionReceipt:  status.IngestionReceipt,
+		ReceiptError:      status.ReceiptError,
+		Freshness:         status.Freshness,
+		Provenance:        status.Provenance,
+		Health:            status.Health,
+		PendingEmbeddings: status.PendingEmbeddings,
 	}
 	if !status.LatestIndexedAt.IsZero() {
 		output.LatestIndexed = status.LatestIndexedAt.Format(time.RFC3339)
 	}
 	if status.DetailedStats != nil {
 		output.Languages = status.DetailedStats.Languages
+		output.LanguageLines = status.DetailedStats.LanguageLines
+		output.LanguageBytes = status.DetailedStats.LanguageBytes
 		output.ChunkTypes = status.DetailedStats.ChunkTypes
 	}
 	if status.PendingChanges != nil {
@@ -1418,6 +2267,7 @@ func statusOutputFromResponse(status *app.StatusResponse) StatusOutput {
 
 func runStatus(cmd *cobra.Command, args []string) error {
 	format, _ := cmd.Flags().GetString("format")
+	showCost, _ := cmd.Flags().GetBool("cost")
 
 	session, err := app.OpenReadOnlySession(cmd.Context(), "")
 	if err != nil {
@@ -1434,6 +2284,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	// JSON output
 	if format == "json" {
 		output := statusOutputFromResponse(status)
+		if showCost {
+			output.Cost = costOutputFromStatus(status, session.Config.Embedding.BudgetUSD)
+		}
 
 		jsonBytes, err := json.MarshalIndent(output, "", "  ")
 		if err != nil {
@@ -1449,6 +2302,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Printf("  Data dir:     %s\n", status.DataDir)
 	fmt.Printf("  VecLite index: %s\n", status.VecLitePath)
 	fmt.Printf("  VecLite size: %s\n", formatBytes(status.VecLiteSizeBytes))
+	if status.Quantization != "none" {
+		fmt.Printf("  Quantization: %s (full-precision vectors: %s)\n", status.Quantization, formatBytes(status.FullVectorBytes))
+	}
 	fmt.Printf("  Vector backend: %s\n", status.VectorBackend)
 	fmt.Printf("  Veclite version: %s\n", status.VecliteVersion)
 	fmt.Printf("  Embedding model: %s (%s, %d dimensions)\n", status.Model, status.Provider, status.Dimensions)
@@ -1469,6 +2325,12 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		if languages := formatCountSummary(status.DetailedStats.Languages, 5); languages != "" {
 			fmt.Printf("  Languages:  %s\n", languages)
 		}
+		if lines := formatCountSummary(status.DetailedStats.LanguageLines, 5); lines != "" {
+			fmt.Printf("  Lines:      %s\n", lines)
+		}
+		if size := formatValueSummary(status.DetailedStats.LanguageBytes, 5, formatBytes); size != "" {
+			fmt.Printf("  Code size:  %s\n", size)
+		}
 		if chunkTypes := formatCountSummary(status.DetailedStats.ChunkTypes, 5); chunkTypes != "" {
 			fmt.Printf("  Types:      %s\n", chunkTypes)
 		}
@@ -1500,6 +2362,41 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			fmt.Printf("\nFreshness could not be proven; run 'vecgrep index --full' to rebuild trusted metadata.\n")
 		}
 	}
+	if queued := status.PendingEmbeddings; queued != nil {
+		fmt.Printf("\nDeferred embeddings: %d files (%d chunks) queued; run 'vecgrep embed-pending' to embed them.\n", queued.Files, queued.Chunks)
+	}
+
+	if p := status.Provenance; p != nil && (p.Mixed || p.StaleFiles > 0) {
+		fmt.Printf("\nChunk provenance (current: %s):\n", p.CurrentParams)
+		for _, g := range p.Groups {
+			label := "unknown (indexed before provenance was recorded)"
+			if g.ChunkParams != "" || g.ChunkStrategy != "" {
+				label = fmt.Sprintf("%s %s, vecgrep %s", g.ChunkStrategy, g.ChunkParams, g.IndexerVersion)
+			}
+			state := "current"
+			if g.Stale {
+				state = "stale"
+			}
+			fmt.Printf("  %-7s %d files: %s\n", state, g.Files, label)
+		}
+		if p.StaleFiles > 0 {
+			fmt.Printf("\nRun 'vecgrep index --rechunk-stale' to re-chunk the %d stale file(s).\n", p.StaleFiles)
+		}
+	}
+
+	if h := status.Health; h != nil {
+		fmt.Printf("\nHealth: %d/100 (%s)\n", h.Score, h.Grade)
+		for _, reason := range h.Reasons {
+			fmt.Printf("  -%-3d %s\n", reason.Penalty, reason.Detail)
+			if reason.Fix != "" {
+				fmt.Printf("       fix: %s\n", reason.Fix)
+			}
+		}
+	}
+
+	if showCost {
+		writeCostText(os.Stdout, costOutputFromStatus(status, session.Config.Embedding.BudgetUSD))
+	}
 
 	return nil
 }
@@ -1510,6 +2407,12 @@ type countItem struct {
 }
 
 func formatCountSummary(counts map[string]int64, limit int) string {
+	return formatValueSummary(counts, limit, func(n int64) string { return fmt.Sprintf("%d", n) })
+}
+
+// formatValueSummary lists the largest entries of counts, rendering each
+// value with format.
+func formatValueSummary(counts map[string]int64, limit int, format func(int64) string) string {
 	if len(counts) == 0 {
 		return ""
 	}
@@ -1534,7 +2437,7 @@ func formatCountSummary(counts map[string]int64, limit int) string {
 	}
 	parts := make([]string, 0, len(items))
 	for _, item := range items {
-		parts = append(parts, fmt.Sprintf("%s %d", item.name, item.count))
+		parts = append(parts, item.name+" "+format(item.count))
 	}
 	return strings.Join(parts, ", ")
 }
@@ -1590,6 +2493,20 @@ func providerHealthLabel(health string) string {
 func runDelete(cmd *cobra.Command, args []string) error {
 	filePath := args[0]
 
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		session, err := app.OpenReadOnlySession(cmd.Context(), "")
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		chunks, err := app.NewService(session).PreviewDeleteFile(cmd.Context(), filePath)
+		if err != nil {
+			return fmt.Errorf("dry-run failed: %w", err)
+		}
+		fmt.Printf("Would delete %s (%d chunks; dry run, index unchanged)\n", filePath, chunks)
+		return nil
+	}
+
 	session, err := app.OpenSession(cmd.Context(), "")
 	if err != nil {
 		return err
@@ -1607,6 +2524,22 @@ func runDelete(cmd *cobra.Command, args []string) error {
 }
 
 func runClean(cmd *cobra.Command, args []string) error {
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		session, err := app.OpenReadOnlySession(cmd.Context(), "")
+		if err != nil {
+			return err
+		}
+		defer session.Close()
+		stats, err := app.NewService(session).PreviewClean(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("dry-run failed: %w", err)
+		}
+		fmt.Printf("Dry run (database not synced, nothing removed):\n")
+		fmt.Printf("  Records: %d\n", stats.TotalRecords)
+		fmt.Printf("  Files:   %d\n", stats.TotalFiles)
+		return nil
+	}
+
 	session, err := app.OpenSession(cmd.Context(), "")
 	if err != nil {
 		return err
@@ -1706,8 +2639,13 @@ func runReset(cmd *cobra.Command, args []string) error {
 func runSimilar(cmd *cobra.Command, args []string) error {
 	// Get flags
 	textSnippet, _ := cmd.Flags().GetString("text")
+	symbol, _ := cmd.Flags().GetString("symbol")
 	limit, _ := cmd.Flags().GetInt("limit")
 	format, _ := cmd.Flags().GetString("format")
+	resultTmpl, err := resultTemplate(cmd, &format)
+	if err != nil {
+		return err
+	}
 	lang, _ := cmd.Flags().GetString("lang")
 	languages, _ := cmd.Flags().GetStringSlice("languages")
 	chunkType, _ := cmd.Flags().GetString("type")
@@ -1717,6 +2655,7 @@ func runSimilar(cmd *cobra.Command, args []string) error {
 	linesRange, _ := cmd.Flags().GetString("lines")
 	excludeSameFile, _ := cmd.Flags().GetBool("exclude-same-file")
 	minScore, _ := cmd.Flags().GetFloat32("min-score")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
 
 	// Parse line range
 	var minLine, maxLine int
@@ -1728,7 +2667,7 @@ func runSimilar(cmd *cobra.Command, args []string) error {
 	if len(args) > 0 {
 		positional = args[0]
 	}
-	target, err := app.ParseSimilarTarget(positional, textSnippet)
+	target, err := app.ParseSimilarTarget(positional, textSnippet, symbol)
 	if err != nil {
 		return err
 	}
@@ -1752,6 +2691,7 @@ func runSimilar(cmd *cobra.Command, args []string) error {
 		MinLine:         minLine,
 		MaxLine:         maxLine,
 		MinScore:        minScore,
+		Tags:            tags,
 		ExcludeSameFile: excludeSameFile,
 	})
 	if err != nil {
@@ -1759,7 +2699,7 @@ func runSimilar(cmd *cobra.Command, args []string) error {
 	}
 
 	// Format and print results
-	printSearchResults(resp.Results, format)
+	printSearchResults(resp.Results, format, resultTmpl)
 
 	return nil
 }
@@ -1841,6 +2781,10 @@ func runConfigSet(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if strings.HasPrefix(key, "hooks.") {
+		return fmt.Errorf("%s is only read from the global config: use 'vecgrep config set --global %s ...'", key, key)
+	}
+
 	// Set in project config
 	projectRoot, err := config.GetProjectRoot()
 	if err != nil {
@@ -1965,13 +2909,13 @@ func runBenchmarkEmbeddings(cmd *cobra.Command, _ []string) error {
 		if profile.Provider != "ollama" {
 			return nil, fmt.Errorf("benchmark profile %q uses unsupported provider %q", profile.Name, profile.Provider)
 		}
-		return embed.NewOllamaProvider(embed.OllamaConfig{
+		return embed.NewNormalizedProvider(embed.NewOllamaProvider(embed.OllamaConfig{
 			URL:        resolvedConfig.Embedding.OllamaURL,
 			Model:      profile.Model,
 			Dimensions: profile.Dimensions,
 			Context:    profile.OllamaContext,
 			Options:    profile.OllamaOptions,
-		}), nil
+		})), nil
 	}
 
 	batchSize, _ := cmd.Flags().GetInt("batch-size")
@@ -2513,6 +3457,9 @@ func runDaemonStart(cmd *cobra.Command, args []string) error {
 	if daemon.IsRunning(globalDir) {
 		return fmt.Errorf("daemon hub already running")
 	}
+	if background, _ := cmd.Flags().GetBool("background"); background {
+		return startDaemonBackground(cmd, args, globalDir)
+	}
 
 	// Decide which projects to pre-open: explicit args, else the current
 	// project if cwd is inside one. Others open lazily on first request.
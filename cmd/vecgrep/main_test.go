@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
 	"github.com/spf13/cobra"
 )
 
@@ -138,3 +139,38 @@ func TestRunBenchmarkEmbeddingsRejectsUnknownPresetBeforeProviderUse(t *testing.
 		t.Fatalf("runBenchmarkEmbeddings() error = %v, want unknown preset", err)
 	}
 }
+
+func TestResultTemplateSelectsTheTemplateFormat(t *testing.T) {
+	newCmd := func(flags ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().StringP("format", "f", "default", "")
+		cmd.Flags().String("template", "", "")
+		if err := cmd.ParseFlags(flags); err != nil {
+			t.Fatal(err)
+		}
+		return cmd
+	}
+
+	cmd := newCmd("--template", "{{.RelativePath}}:{{.StartLine}}")
+	format, _ := cmd.Flags().GetString("format")
+	tmpl, err := resultTemplate(cmd, &format)
+	if err != nil || tmpl == nil || format != "template" {
+		t.Fatalf("resultTemplate() = %v, %v with format %q, want a template and the template format", tmpl, err, format)
+	}
+	got := renderQueryResults("q", []search.Result{{RelativePath: "a.go", StartLine: 4}}, format, tmpl, search.MarkdownOptions{})
+	if got != "a.go:4\n" {
+		t.Fatalf("rendered %q", got)
+	}
+
+	for _, flags := range [][]string{
+		{"--format", "template"},
+		{"--format", "json", "--template", "{{.Score}}"},
+		{"--template", "{{.Nope}}"},
+	} {
+		cmd := newCmd(flags...)
+		format, _ := cmd.Flags().GetString("format")
+		if _, err := resultTemplate(cmd, &format); err == nil {
+			t.Errorf("resultTemplate(%v) succeeded, want an error", flags)
+		}
+	}
+}
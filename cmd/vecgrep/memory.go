@@ -1,14 +1,17 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
 	"github.com/abdul-hamid-achik/vecgrep/internal/memory"
@@ -21,8 +24,15 @@ import (
 // project-scoped memories beside a symbol without calling vecgrep's MCP server.
 var memoryCmd = &cobra.Command{
 	Use:   "memory",
-	Short: "Recall and store cross-project agent memories",
-	Long: `Manage the global agent-memory store (~/.vecai/memory).
+	Short: "Recall, store, and curate cross-project agent memories",
+	Long: `Manage the agent-memory stores: the global store (~/.vecai/memory), or
+with --scope project the current project's store, kept in its vecgrep data
+directory so memories about the codebase travel with it.
+
+These are the stores the memory_* MCP tools use, so list, forget, and stats
+let you inspect and curate what an assistant has remembered. add and search
+are aliases of remember and recall. Only remember and recall need the
+embedding provider.
 
 Memories are recalled semantically and scoped by tags. For codemap-scoped
 memories, follow the G2 convention: tag with ['codemap', <project_key>] where
@@ -32,8 +42,9 @@ BOTH tags (AND) so only that project's memories match.`,
 
 // memoryRecallCmd recalls memories by semantic query, scoped by tags (AND).
 var memoryRecallCmd = &cobra.Command{
-	Use:   "recall <query>",
-	Short: "Recall memories by meaning, scoped by tags (AND)",
+	Use:     "recall <query>",
+	Aliases: []string{"search"},
+	Short:   "Recall memories by meaning, scoped by tags (AND)",
 	Long: `Recall memories semantically similar to <query>.
 
 --tags filters to memories carrying ALL the given tags (AND semantics): a
@@ -41,6 +52,11 @@ memory matches only if it carries every requested tag exactly. This is the
 scoping that prevents cross-project leakage (e.g. --tags codemap,<project_key>
 matches only that project's codemap-scoped memories).
 
+--since and --until limit recall to memories created in a time window. Each
+takes an RFC 3339 timestamp, a YYYY-MM-DD date (an --until date includes the
+whole day), or an age such as 24h, 7d, or 2w: --since 7d asks what was
+recorded this week.
+
 --format json emits a JSON array (the C5 contract) for tools to parse.`,
 	Args: cobra.MinimumNArgs(1),
 	RunE: runMemoryRecall,
@@ -48,8 +64,9 @@ matches only that project's codemap-scoped memories).
 
 // memoryRememberCmd stores a memory with tags + importance.
 var memoryRememberCmd = &cobra.Command{
-	Use:   "remember <content>",
-	Short: "Store a memory with tags and importance",
+	Use:     "remember <content>",
+	Aliases: []string{"add"},
+	Short:   "Store a memory with tags and importance",
 	Long: `Store a memory. For a codemap-scoped memory, tag it per the G2
 convention: --tags codemap,<project_key>[,extra...]. Read codemap's
 project_key from 'codemap status --json'; never re-derive it.`,
@@ -57,6 +74,48 @@ project_key from 'codemap status --json'; never re-derive it.`,
 	RunE: runMemoryRemember,
 }
 
+// memoryListCmd lists memories newest first, without a query.
+var memoryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored memories, newest first",
+	Long: `List stored memories newest first, with their ids, tags, and ages.
+
+--tags, --since, and --until filter as in recall; expired memories that have
+not been cleaned up yet are listed with --expired. --format json emits an
+array of {id,content,importance,tags,created_at,expires_at}.`,
+	Example: `  vecgrep memory list
+  vecgrep memory list --tags codemap,abc123def456 --since 7d
+  vecgrep memory list --scope project --format json`,
+	Args: cobra.NoArgs,
+	RunE: runMemoryList,
+}
+
+// memoryForgetCmd deletes memories by id, or in bulk by tags and age.
+var memoryForgetCmd = &cobra.Command{
+	Use:   "forget [id...]",
+	Short: "Delete memories by id, tags, or age",
+	Long: `Delete the memories with the given ids, or every memory matching
+--tags (all of them, exactly, as in recall) and --older-than (an RFC 3339
+time, a YYYY-MM-DD date, or an age like 30d). --expired removes memories
+past their expiration.
+
+A bulk delete by --tags or --older-than lists what it would remove and
+deletes only with --yes.`,
+	Example: `  vecgrep memory forget 42 57
+  vecgrep memory forget --tags scratch --older-than 30d
+  vecgrep memory forget --tags scratch --older-than 30d --yes
+  vecgrep memory forget --expired`,
+	RunE: runMemoryForget,
+}
+
+// memoryStatsCmd summarizes a memory store.
+var memoryStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show memory counts, age range, and tag distribution",
+	Args:  cobra.NoArgs,
+	RunE:  runMemoryStats,
+}
+
 // c5Memory is the JSON shape emitted by `memory recall --format json` (C5).
 // It mirrors the memory.Memory fields a consumer needs. ID is emitted as a
 // string to match the committed C5 contract shape exactly.
@@ -69,16 +128,32 @@ type c5Memory struct {
 }
 
 // openMemoryStore builds the memory store the same way the MCP server does:
-// the default config + an Ollama embedding provider. It pings the provider so
-// a clear error is returned when Ollama is unavailable, rather than failing
-// deep inside recall.
-func openMemoryStore(ctx context.Context) (*memory.MemoryStore, error) {
+// the default config + an Ollama embedding provider. scope "project" places
+// the store in the current project's data directory. When embeds is set it
+// pings the provider so a clear error is returned when Ollama is unavailable,
+// rather than failing deep inside recall; list, forget, and stats never embed
+// and skip the ping.
+func openMemoryStore(ctx context.Context, scope string, embeds bool) (*memory.MemoryStore, error) {
+	parsed, err := memory.ParseScope(scope)
+	if err != nil {
+		return nil, err
+	}
 	cfg := memory.DefaultConfig()
-	provider := embed.NewOllamaProvider(embed.OllamaConfig{
+	if parsed == memory.ScopeProject {
+		_, dataDir, err := resolveProjectDataDir()
+		if err != nil {
+			return nil, err
+		}
+		cfg = memory.ProjectConfig(dataDir)
+	}
+	provider := embed.NewNormalizedProvider(embed.NewOllamaProvider(embed.OllamaConfig{
 		URL:        cfg.OllamaURL,
 		Model:      cfg.EmbeddingModel,
 		Dimensions: cfg.EmbeddingDimensions,
-	})
+	}))
+	if !embeds {
+		return memory.NewMemoryStore(cfg, provider)
+	}
 	if err := provider.Ping(ctx); err != nil {
 		return nil, fmt.Errorf("embedding provider not available: %w (ensure Ollama is running with %s)", err, cfg.EmbeddingModel)
 	}
@@ -105,14 +180,23 @@ func runMemoryRecall(cmd *cobra.Command, args []string) error {
 	minImportance, _ := cmd.Flags().GetFloat64("min-importance")
 	limit, _ := cmd.Flags().GetInt("limit")
 	format, _ := cmd.Flags().GetString("format")
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	untilFlag, _ := cmd.Flags().GetString("until")
+	scope, _ := cmd.Flags().GetString("scope")
 
+	since, until, err := memory.ParseTimeWindow(sinceFlag, untilFlag, time.Now())
+	if err != nil {
+		return err
+	}
 	opts := memory.RecallOptions{
 		Limit:         limit,
 		Tags:          parseTags(tagsCSV),
 		MinImportance: minImportance,
+		Since:         since,
+		Until:         until,
 	}
 
-	store, err := openMemoryStore(cmd.Context())
+	store, err := openMemoryStore(cmd.Context(), scope, true)
 	if err != nil {
 		// Provider unreachable at open time. For the json contract, keep
 		// stdout empty and emit the degraded-signal envelope to stderr with
@@ -210,8 +294,9 @@ func runMemoryRemember(cmd *cobra.Command, args []string) error {
 	tagsCSV, _ := cmd.Flags().GetString("tags")
 	importance, _ := cmd.Flags().GetFloat64("importance")
 	ttlHours, _ := cmd.Flags().GetInt("ttl-hours")
+	scope, _ := cmd.Flags().GetString("scope")
 
-	store, err := openMemoryStore(cmd.Context())
+	store, err := openMemoryStore(cmd.Context(), scope, true)
 	if err != nil {
 		return err
 	}
@@ -228,3 +313,221 @@ func runMemoryRemember(cmd *cobra.Command, args []string) error {
 	fmt.Fprintf(cmd.OutOrStdout(), "Memory stored (id %d)\n", id)
 	return nil
 }
+
+// memoryEntry is the JSON shape of one memory in `memory list --format json`.
+type memoryEntry struct {
+	ID         string     `json:"id"`
+	Content    string     `json:"content"`
+	Importance float64    `json:"importance"`
+	Tags       []string   `json:"tags"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+func runMemoryList(cmd *cobra.Command, args []string) error {
+	tagsCSV, _ := cmd.Flags().GetString("tags")
+	sinceFlag, _ := cmd.Flags().GetString("since")
+	untilFlag, _ := cmd.Flags().GetString("until")
+	limit, _ := cmd.Flags().GetInt("limit")
+	expired, _ := cmd.Flags().GetBool("expired")
+	format, _ := cmd.Flags().GetString("format")
+	scope, _ := cmd.Flags().GetString("scope")
+
+	since, until, err := memory.ParseTimeWindow(sinceFlag, untilFlag, time.Now())
+	if err != nil {
+		return err
+	}
+	store, err := openMemoryStore(cmd.Context(), scope, false)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	memories, err := store.List(cmd.Context(), memory.ListOptions{
+		Limit:          limit,
+		Tags:           parseTags(tagsCSV),
+		Since:          since,
+		Until:          until,
+		IncludeExpired: expired,
+	})
+	if err != nil {
+		return fmt.Errorf("list failed: %w", err)
+	}
+
+	if format == "json" {
+		out := make([]memoryEntry, 0, len(memories))
+		for _, m := range memories {
+			tags := m.Tags
+			if tags == nil {
+				tags = []string{}
+			}
+			out = append(out, memoryEntry{
+				ID:         strconv.FormatUint(m.ID, 10),
+				Content:    m.Content,
+				Importance: m.Importance,
+				Tags:       tags,
+				CreatedAt:  m.CreatedAt,
+				ExpiresAt:  m.ExpiresAt,
+			})
+		}
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	w := cmd.OutOrStdout()
+	if len(memories) == 0 {
+		fmt.Fprintln(w, "No memories stored.")
+		return nil
+	}
+	writeMemoryList(w, memories)
+	return nil
+}
+
+// writeMemoryList prints one memory per line: id, creation time,
+// importance, content, and tags on the line below.
+func writeMemoryList(w io.Writer, memories []memory.Memory) {
+	for _, m := range memories {
+		fmt.Fprintf(w, "%d  %s  importance %.2f  %s\n", m.ID, m.CreatedAt.Format("2006-01-02 15:04"), m.Importance, m.Content)
+		if len(m.Tags) > 0 {
+			fmt.Fprintf(w, "    tags: %s\n", strings.Join(m.Tags, ", "))
+		}
+		if m.ExpiresAt != nil {
+			fmt.Fprintf(w, "    expires: %s\n", m.ExpiresAt.Format("2006-01-02 15:04"))
+		}
+	}
+}
+
+func runMemoryForget(cmd *cobra.Command, args []string) error {
+	tagsCSV, _ := cmd.Flags().GetString("tags")
+	olderThan, _ := cmd.Flags().GetString("older-than")
+	expired, _ := cmd.Flags().GetBool("expired")
+	yes, _ := cmd.Flags().GetBool("yes")
+	scope, _ := cmd.Flags().GetString("scope")
+
+	ids := make([]uint64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil || id == 0 {
+			return fmt.Errorf("invalid memory id %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	tags := parseTags(tagsCSV)
+	bulk := len(tags) > 0 || olderThan != ""
+	if len(ids) > 0 && bulk {
+		return fmt.Errorf("give memory ids or --tags/--older-than, not both")
+	}
+	if len(ids) == 0 && !bulk && !expired {
+		return fmt.Errorf("specify memory ids, --tags, --older-than, or --expired")
+	}
+	_, cutoff, err := memory.ParseTimeWindow("", olderThan, time.Now())
+	if err != nil {
+		return fmt.Errorf("invalid --older-than %q: %w", olderThan, err)
+	}
+
+	store, err := openMemoryStore(cmd.Context(), scope, false)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+	w := cmd.OutOrStdout()
+
+	if expired {
+		n, err := store.ForgetExpired(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("forget expired failed: %w", err)
+		}
+		fmt.Fprintf(w, "Deleted %d expired memories\n", n)
+	}
+
+	if bulk {
+		matches, err := store.List(cmd.Context(), memory.ListOptions{Tags: tags, Until: cutoff})
+		if err != nil {
+			return fmt.Errorf("list failed: %w", err)
+		}
+		if len(matches) == 0 {
+			fmt.Fprintln(w, "No memories match.")
+			return nil
+		}
+		if !yes {
+			writeMemoryList(w, matches)
+			fmt.Fprintf(w, "\n%d memories match. Run again with --yes to delete them.\n", len(matches))
+			return nil
+		}
+		for _, m := range matches {
+			ids = append(ids, m.ID)
+		}
+	}
+
+	deleted := 0
+	for _, id := range ids {
+		if _, err := store.Forget(cmd.Context(), memory.ForgetOptions{ID: id}); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: %v\n", err)
+			continue
+		}
+		deleted++
+	}
+	if len(ids) > 0 {
+		fmt.Fprintf(w, "Deleted %d memories\n", deleted)
+	}
+	if deleted < len(ids) {
+		return fmt.Errorf("%d of %d memories could not be deleted", len(ids)-deleted, len(ids))
+	}
+	return nil
+}
+
+func runMemoryStats(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	scope, _ := cmd.Flags().GetString("scope")
+
+	store, err := openMemoryStore(cmd.Context(), scope, false)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	stats, err := store.Stats(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("stats failed: %w", err)
+	}
+	if format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			TotalMemories   int64            `json:"total_memories"`
+			TotalTags       int              `json:"total_tags"`
+			ExpiredMemories int64            `json:"expired_memories"`
+			OldestMemory    *time.Time       `json:"oldest_memory,omitempty"`
+			NewestMemory    *time.Time       `json:"newest_memory,omitempty"`
+			TagCounts       map[string]int64 `json:"tag_counts"`
+		}{stats.TotalMemories, stats.TotalTags, stats.ExpiredMemories, stats.OldestMemory, stats.NewestMemory, stats.TagCounts})
+	}
+
+	w := cmd.OutOrStdout()
+	fmt.Fprintf(w, "Memories:  %d\n", stats.TotalMemories)
+	fmt.Fprintf(w, "Expired:   %d\n", stats.ExpiredMemories)
+	fmt.Fprintf(w, "Tags:      %d\n", stats.TotalTags)
+	if stats.OldestMemory != nil {
+		fmt.Fprintf(w, "Oldest:    %s\n", stats.OldestMemory.Format(time.RFC3339))
+	}
+	if stats.NewestMemory != nil {
+		fmt.Fprintf(w, "Newest:    %s\n", stats.NewestMemory.Format(time.RFC3339))
+	}
+	if len(stats.TagCounts) > 0 {
+		tags := make([]string, 0, len(stats.TagCounts))
+		for tag := range stats.TagCounts {
+			tags = append(tags, tag)
+		}
+		slices.SortFunc(tags, func(a, b string) int {
+			if c := cmp.Compare(stats.TagCounts[b], stats.TagCounts[a]); c != 0 {
+				return c
+			}
+			return strings.Compare(a, b)
+		})
+		fmt.Fprintln(w, "\nTag distribution:")
+		for _, tag := range tags {
+			fmt.Fprintf(w, "  %-24s %d\n", tag, stats.TagCounts[tag])
+		}
+	}
+	return nil
+}
@@ -96,11 +96,16 @@ func TestMemoryCommandWiring(t *testing.T) {
 	for _, c := range memoryCmd.Commands() {
 		sub[c.Name()] = true
 	}
-	for _, want := range []string{"recall", "remember"} {
+	for _, want := range []string{"recall", "remember", "list", "forget", "stats"} {
 		if !sub[want] {
 			t.Errorf("memory command missing subcommand %q", want)
 		}
 	}
+	for alias, want := range map[string]*cobra.Command{"search": memoryRecallCmd, "add": memoryRememberCmd} {
+		if got, _, err := memoryCmd.Find([]string{alias}); err != nil || got != want {
+			t.Errorf("memory %s resolves to %v (err %v), want %s", alias, got, err, want.Name())
+		}
+	}
 	for _, f := range []string{"tags", "min-importance", "limit", "format"} {
 		if memoryRecallCmd.Flags().Lookup(f) == nil {
 			t.Errorf("memory recall missing --%s flag", f)
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/daemon"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+	"github.com/spf13/cobra"
+)
+
+// reembedCmd re-embeds indexed files under the active embedding settings.
+var reembedCmd = &cobra.Command{
+	Use:   "reembed",
+	Short: "Re-embed indexed files after an embedding model change",
+	Long: `Re-embed the indexed files with the active embedding provider, model, and
+document template, keeping their chunks. Every stored vector is tagged with
+the embedding version that produced it, and --only-stale re-embeds just the
+files whose vectors carry another version than the active configuration.
+
+Use it after changing embedding.provider, embedding.model, or
+embedding.document_template without changing the vector dimensions. Until the
+run completes the index keeps its old embedding profile and search stays
+blocked; an interrupted run can be resumed with --only-stale, which skips the
+files already re-embedded. Changes to dimensions, quantization, or the vector
+backend need 'vecgrep index --full' instead.`,
+	Args:         cobra.NoArgs,
+	RunE:         runReembed,
+	SilenceUsage: true,
+}
+
+func runReembed(cmd *cobra.Command, args []string) error {
+	if gdir, err := config.GetGlobalConfigDir(); err == nil && daemon.IsRunning(gdir) {
+		return fmt.Errorf("reembed is unavailable while the daemon hub owns the index; stop it with 'vecgrep daemon stop' first")
+	}
+	onlyStale, _ := cmd.Flags().GetBool("only-stale")
+	session, err := app.OpenSession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	service := app.NewService(session)
+
+	targets, err := service.ReembedTargets(onlyStale)
+	if err != nil {
+		return err
+	}
+	if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+		for _, path := range targets {
+			fmt.Println(path)
+		}
+		fmt.Printf("\n%d files would be re-embedded.\n", len(targets))
+		return nil
+	}
+	if len(targets) == 0 {
+		fmt.Println("No files need re-embedding.")
+		return nil
+	}
+	fmt.Printf("Re-embedding %d files...\n", len(targets))
+	fmt.Printf("  Model: %s\n", session.Config.Embedding.Model)
+
+	verbose, _ := rootCmd.PersistentFlags().GetBool("verbose")
+	var progress func(index.Progress)
+	if verbose {
+		progress = func(p index.Progress) {
+			fmt.Printf("\r  %s (%d/%d files)\033[K", p.CurrentFile, p.ProcessedFiles, len(targets))
+		}
+	}
+	result, err := service.Index(cmd.Context(), app.IndexRequest{Reembed: true, ReembedOnlyStale: onlyStale}, progress)
+	if verbose {
+		fmt.Println()
+	}
+	if err != nil {
+		return fmt.Errorf("reembed failed: %w", err)
+	}
+	fmt.Printf("\nRe-embedding complete:\n")
+	fmt.Printf("  Files processed: %d\n", result.FilesProcessed)
+	fmt.Printf("  Chunks embedded: %d\n", result.ChunksCreated)
+	fmt.Printf("  Duration: %s\n", result.Duration.Round(100*1000000))
+	if len(result.Errors) > 0 {
+		fmt.Printf("\nWarnings: %d\n", len(result.Errors))
+		for _, e := range result.Errors {
+			fmt.Printf("  - %v\n", e)
+		}
+	}
+	return nil
+}
@@ -46,7 +46,10 @@ func TestIsMachineFormat(t *testing.T) {
 		"default":       false,
 		"json":          true,
 		"compact":       true,
+		"citations":     true,
 		"json-envelope": true,
+		"openai":        true,
+		"markdown":      true,
 		"yaml":          false,
 	}
 	for in, want := range cases {
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+// resolveSearchPaths maps trailing `vecgrep search -q <query> <path>...`
+// arguments to project-relative scopes: directories become directory-prefix
+// filters and files join the file allow-list. Paths are resolved from the
+// working directory like grep and must exist inside the project. The project
+// root itself scopes nothing.
+func resolveSearchPaths(projectRoot string, paths []string) (dirs, files []string, err error) {
+	for _, p := range paths {
+		abs, err := filepath.Abs(p)
+		if err != nil {
+			return nil, nil, fmt.Errorf("resolve %s: %w", p, err)
+		}
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("search path %s: %w", p, err)
+		}
+		if info.IsDir() && abs == filepath.Clean(projectRoot) {
+			return nil, nil, nil
+		}
+		rel, err := db.ProjectRelativePath(projectRoot, abs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("search path %s: %w", p, err)
+		}
+		if info.IsDir() {
+			dirs = append(dirs, rel)
+		} else {
+			files = append(files, rel)
+		}
+	}
+	return dirs, files, nil
+}
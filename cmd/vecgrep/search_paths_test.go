@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestResolveSearchPaths(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "internal", "search"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(root, "internal"))
+
+	dirs, files, err := resolveSearchPaths(root, []string{"./search", "../main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(dirs, []string{"internal/search"}) || !slices.Equal(files, []string{"main.go"}) {
+		t.Fatalf("dirs=%v files=%v", dirs, files)
+	}
+
+	dirs, files, err = resolveSearchPaths(root, []string{"search", ".."})
+	if err != nil || dirs != nil || files != nil {
+		t.Fatalf("project root should clear the scope: dirs=%v files=%v err=%v", dirs, files, err)
+	}
+
+	if _, _, err := resolveSearchPaths(root, []string{"missing"}); err == nil {
+		t.Fatal("missing path should fail")
+	}
+	if _, _, err := resolveSearchPaths(root, []string{t.TempDir()}); err == nil {
+		t.Fatal("path outside the project should fail")
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+)
+
+// CostOutput is the `status --cost` JSON section. Estimated spend fields are
+// omitted when the provider/model has no known price.
+type CostOutput struct {
+	Provider         string         `json:"provider"`
+	Model            string         `json:"model"`
+	Index            embed.Usage    `json:"index"`
+	Search           embed.Usage    `json:"search"`
+	Total            embed.Usage    `json:"total"`
+	EstimatedUSD     *float64       `json:"estimated_usd,omitempty"`
+	LastRun          *CostRunOutput `json:"last_run,omitempty"`
+	BudgetUSD        float64        `json:"budget_usd,omitempty"`
+	UsageError       string         `json:"usage_error,omitempty"`
+	PriceUnavailable bool           `json:"price_unavailable,omitempty"`
+}
+
+// CostRunOutput describes the most recent indexing run's traffic.
+type CostRunOutput struct {
+	StartedAt      string      `json:"started_at"`
+	FinishedAt     string      `json:"finished_at"`
+	Usage          embed.Usage `json:"usage"`
+	EstimatedUSD   *float64    `json:"estimated_usd,omitempty"`
+	BudgetExceeded bool        `json:"budget_exceeded,omitempty"`
+}
+
+func costOutputFromStatus(status *app.StatusResponse, budgetUSD float64) *CostOutput {
+	output := &CostOutput{
+		Provider:   status.Provider,
+		Model:      status.Model,
+		BudgetUSD:  budgetUSD,
+		UsageError: status.UsageError,
+	}
+	usage := status.EmbeddingUsage
+	if usage == nil {
+		if _, ok := embed.PricePerMillionTokens(status.Provider, status.Model); !ok {
+			output.PriceUnavailable = true
+		}
+		return output
+	}
+	output.Provider = usage.Provider
+	output.Model = usage.Model
+	output.Index = usage.Index
+	output.Search = usage.Search
+	output.Total = usage.Total()
+	if cost, ok := usage.EstimatedCostUSD(output.Total); ok {
+		output.EstimatedUSD = &cost
+	} else {
+		output.PriceUnavailable = true
+	}
+	if usage.LastRun != nil {
+		run := &CostRunOutput{
+			StartedAt:      usage.LastRun.StartedAt.Format(time.RFC3339),
+			FinishedAt:     usage.LastRun.FinishedAt.Format(time.RFC3339),
+			Usage:          usage.LastRun.Usage,
+			BudgetExceeded: usage.LastRun.BudgetExceeded,
+		}
+		if cost, ok := usage.EstimatedCostUSD(usage.LastRun.Usage); ok {
+			run.EstimatedUSD = &cost
+		}
+		output.LastRun = run
+	}
+	return output
+}
+
+func writeCostText(w io.Writer, cost *CostOutput) {
+	fmt.Fprintf(w, "\nEmbedding usage (%s, %s):\n", cost.Provider, cost.Model)
+	if cost.UsageError != "" {
+		fmt.Fprintf(w, "  Usage:      unknown (%s)\n", cost.UsageError)
+		return
+	}
+	fmt.Fprintf(w, "  Indexing:   %s\n", formatUsage(cost.Index))
+	fmt.Fprintf(w, "  Search:     %s\n", formatUsage(cost.Search))
+	fmt.Fprintf(w, "  Total:      %s\n", formatUsage(cost.Total))
+	if cost.EstimatedUSD != nil {
+		fmt.Fprintf(w, "  Est. spend: %s\n", formatUSD(*cost.EstimatedUSD))
+	} else {
+		fmt.Fprintf(w, "  Est. spend: unavailable (no known price for this model)\n")
+	}
+	if cost.LastRun != nil {
+		line := formatUsage(cost.LastRun.Usage)
+		if cost.LastRun.EstimatedUSD != nil {
+			line += ", " + formatUSD(*cost.LastRun.EstimatedUSD)
+		}
+		if cost.LastRun.BudgetExceeded {
+			line += " (aborted: budget exceeded)"
+		}
+		fmt.Fprintf(w, "  Last run:   %s at %s\n", line, cost.LastRun.FinishedAt)
+	}
+	if cost.BudgetUSD > 0 {
+		fmt.Fprintf(w, "  Budget:     %s per indexing run\n", formatUSD(cost.BudgetUSD))
+	}
+}
+
+func formatUsage(usage embed.Usage) string {
+	return fmt.Sprintf("%d tokens (est.), %d texts, %d requests", usage.Tokens, usage.Texts, usage.Requests)
+}
+
+func formatUSD(amount float64) string {
+	if amount > 0 && amount < 0.01 {
+		return fmt.Sprintf("$%.4f", amount)
+	}
+	return fmt.Sprintf("$%.2f", amount)
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
 	"github.com/abdul-hamid-achik/vecgrep/internal/index"
 )
 
@@ -24,6 +27,7 @@ func TestStatusOutputIncludesConservativeFreshnessReport(t *testing.T) {
 			ReceiptVerified:   true,
 			ManifestRequired:  true,
 		},
+		Health: &app.IndexHealth{Score: 95, Grade: app.HealthGradeHealthy},
 	}
 
 	output := statusOutputFromResponse(status)
@@ -46,4 +50,47 @@ func TestStatusOutputIncludesConservativeFreshnessReport(t *testing.T) {
 	if !ok || freshness["state"] != "unknown" || freshness["reason"] != "structural_manifest_mismatch" {
 		t.Fatalf("freshness JSON = %#v", document["freshness"])
 	}
+	if health, ok := document["health"].(map[string]any); !ok || health["score"] != float64(95) {
+		t.Fatalf("health JSON = %#v", document["health"])
+	}
+}
+
+func TestStatusCostOutputEstimatesSpend(t *testing.T) {
+	status := &app.StatusResponse{
+		Provider: "openai",
+		Model:    "text-embedding-3-small",
+		EmbeddingUsage: &app.EmbeddingUsage{
+			Provider: "openai",
+			Model:    "text-embedding-3-small",
+			Index:    embed.Usage{Requests: 4, Texts: 40, Tokens: 2_000_000},
+			Search:   embed.Usage{Requests: 1, Texts: 1, Tokens: 10},
+			LastRun: &app.EmbeddingUsageRun{
+				Usage:          embed.Usage{Requests: 4, Texts: 40, Tokens: 2_000_000},
+				BudgetExceeded: true,
+			},
+		},
+	}
+
+	cost := costOutputFromStatus(status, 1)
+	if cost.Total.Tokens != 2_000_010 || cost.EstimatedUSD == nil || cost.LastRun == nil {
+		t.Fatalf("cost output = %+v", cost)
+	}
+	if *cost.LastRun.EstimatedUSD != 0.04 || !cost.LastRun.BudgetExceeded {
+		t.Fatalf("last run = %+v", cost.LastRun)
+	}
+
+	var buf bytes.Buffer
+	writeCostText(&buf, cost)
+	for _, want := range []string{"Est. spend: $0.04", "budget exceeded", "Budget:     $1.00"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("cost text missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestStatusCostOutputWithoutKnownPrice(t *testing.T) {
+	cost := costOutputFromStatus(&app.StatusResponse{Provider: "cohere", Model: "embed-english-v3.0"}, 0)
+	if !cost.PriceUnavailable || cost.EstimatedUSD != nil {
+		t.Fatalf("cost output = %+v", cost)
+	}
 }
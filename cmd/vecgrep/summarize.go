@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// summarizeCmd stores a short description of a directory, shown by the MCP
+// overview so agents get module descriptions without reading the code.
+var summarizeCmd = &cobra.Command{
+	Use:   "summarize [dir]",
+	Short: "Describe an indexed directory and store the summary",
+	Long: `Summarize the indexed files under a directory (default: the current one).
+
+vecgrep ranks the directory's symbols by how close their embeddings are to
+the directory's mean embedding and builds a short description from the Go
+package comment, file and symbol counts, and those central symbols.
+
+With --command, that material is sent as JSON on stdin to an external command
+(for example a script calling an LLM), and its stdout becomes the summary:
+
+  {"dir": "...", "project_root": "...", "files": [...], "languages": {...},
+   "symbols": [...], "samples": [{"file", "symbol", "type", "content"}],
+   "heuristic_summary": "..."}
+
+Summaries are stored per project next to the index and replace any earlier
+summary of the same directory. Export and import carry them, and reset
+deletes them. The vecgrep_overview MCP tool lists them.`,
+	Example: `  vecgrep summarize internal/embed
+  vecgrep summarize internal/search --command "./scripts/llm-summary.sh"
+  vecgrep summarize list`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSummarize,
+}
+
+var summarizeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored directory summaries",
+	Args:  cobra.NoArgs,
+	RunE:  runSummarizeList,
+}
+
+func runSummarize(cmd *cobra.Command, args []string) error {
+	command, _ := cmd.Flags().GetString("command")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	format, _ := cmd.Flags().GetString("format")
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	target := "."
+	if len(args) == 1 {
+		target = args[0]
+	}
+	dir, err := projectRelativeDir(session.ProjectRoot, target)
+	if err != nil {
+		return err
+	}
+	summary, err := app.NewService(session).SummarizeDirectory(cmd.Context(), dir, app.SummarizeOptions{Command: command, Timeout: timeout})
+	if err != nil {
+		return fmt.Errorf("summarize failed: %w", err)
+	}
+
+	w := cmd.OutOrStdout()
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summary)
+	}
+	fmt.Fprintf(w, "%s/ (%d files, %d chunks, %s)\n%s\n", summary.Dir, summary.Files, summary.Chunks, summary.Source, summary.Summary)
+	return nil
+}
+
+func runSummarizeList(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+
+	_, dataDir, err := resolveProjectDataDir()
+	if err != nil {
+		return err
+	}
+	summaries, err := app.ListDirectorySummaries(dataDir)
+	if err != nil {
+		return fmt.Errorf("list summaries: %w", err)
+	}
+
+	w := cmd.OutOrStdout()
+	if format == "json" {
+		if summaries == nil {
+			summaries = []app.DirectorySummary{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summaries)
+	}
+	if len(summaries) == 0 {
+		fmt.Fprintln(w, "No directory summaries.")
+		return nil
+	}
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s/ — %s\n", s.Dir, s.Summary)
+	}
+	return nil
+}
+
+// projectRelativeDir resolves a directory argument (relative to the working
+// directory, or absolute) to a project-relative slash path; the project root
+// itself is ".".
+func projectRelativeDir(projectRoot, dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", dir, err)
+	}
+	rel, err := filepath.Rel(projectRoot, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the project root %s", dir, projectRoot)
+	}
+	return filepath.ToSlash(rel), nil
+}
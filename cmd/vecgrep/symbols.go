@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// symbolsCmd lists indexed symbols by name without embedding a query.
+var symbolsCmd = &cobra.Command{
+	Use:   "symbols <pattern>",
+	Short: "List indexed symbols whose names match a pattern",
+	Long: `List the functions, types, and other named chunks in the index whose symbol
+name matches a pattern. Matching ignores case and also tries the part after
+the last dot, so methods are found by their bare name. A plain pattern
+matches anywhere in the name; use glob wildcards (*, ?, [...]) to anchor it.
+No embedding provider is needed.
+
+To rank code inside matching symbols by meaning instead, put a symbol:
+filter in a search query: vecgrep search "symbol:NewSearcher retry logic".`,
+	Example: `  vecgrep symbols retry
+  vecgrep symbols 'New*' --lang go
+  vecgrep symbols Search -t function -f json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSymbols,
+}
+
+func runSymbols(cmd *cobra.Command, args []string) error {
+	lang, _ := cmd.Flags().GetString("lang")
+	chunkType, _ := cmd.Flags().GetString("type")
+	limit, _ := cmd.Flags().GetInt("limit")
+	format, _ := cmd.Flags().GetString("format")
+	if format != "default" && format != "json" {
+		return fmt.Errorf("unsupported format %q (use default or json)", format)
+	}
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	req := app.SymbolsRequest{Pattern: args[0], ChunkType: chunkType, Limit: limit}
+	if lang != "" {
+		req.Languages = []string{lang}
+	}
+	matches, err := app.NewService(session).Symbols(cmd.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(matches, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	if len(matches) == 0 {
+		fmt.Printf("No indexed symbols match %q.\n", args[0])
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, m := range matches {
+		location := fmt.Sprintf("%s:%d-%d", m.RelativePath, m.StartLine, m.EndLine)
+		if m.Root != "" {
+			location = m.Root + ":" + location
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", m.Name, m.ChunkType, location)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if len(matches) == req.Limit || (req.Limit == 0 && len(matches) == app.DefaultSymbolsLimit) {
+		fmt.Fprintf(os.Stderr, "Showing the first %d matches; raise --limit for more.\n", len(matches))
+	}
+	return nil
+}
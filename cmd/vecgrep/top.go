@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// topCmd prints an index-derived leaderboard as a quick health report.
+var topCmd = &cobra.Command{
+	Use:   "top",
+	Short: "Show leaderboards derived from the index",
+	Long: `Show a leaderboard computed from the index alone; no embedding provider is
+needed.
+
+  similarity  the most similar chunk pairs that span two different files
+  size        the largest indexed files by bytes
+  chunks      the files split into the most chunks
+
+The similarity board uses the same nearest-neighbour scan as 'vecgrep dupes'
+but has no threshold, so it shows the closest cross-file matches even when
+nothing is a duplicate.`,
+	Example: `  vecgrep top
+  vecgrep top --by chunks -n 20
+  vecgrep top --by size -f json`,
+	Args: cobra.NoArgs,
+	RunE: runTop,
+}
+
+func runTop(cmd *cobra.Command, args []string) error {
+	by, _ := cmd.Flags().GetString("by")
+	limit, _ := cmd.Flags().GetInt("limit")
+	minLines, _ := cmd.Flags().GetInt("min-lines")
+	format, _ := cmd.Flags().GetString("format")
+
+	if format != "default" && format != "json" {
+		return fmt.Errorf("unsupported format %q (use default or json)", format)
+	}
+	if !app.ValidTopBy(by) {
+		return fmt.Errorf("invalid --by %q (use %s, %s, or %s)", by, app.TopBySimilarity, app.TopBySize, app.TopByChunks)
+	}
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	report, err := app.NewService(session).Top(cmd.Context(), app.TopOptions{By: by, Limit: limit, MinLines: minLines})
+	if err != nil {
+		return fmt.Errorf("top: %w", err)
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	printTop(report)
+	return nil
+}
+
+func printTop(report *app.TopReport) {
+	switch report.By {
+	case app.TopBySimilarity:
+		if len(report.Pairs) == 0 {
+			fmt.Println("No cross-file chunk pairs found.")
+			return
+		}
+		fmt.Printf("Top %d most similar cross-file chunk pairs\n\n", len(report.Pairs))
+		for i, pair := range report.Pairs {
+			fmt.Printf("%3d. %.4f  %s:%d-%d <-> %s:%d-%d\n", i+1, pair.Score, pair.A.File, pair.A.StartLine, pair.A.EndLine, pair.B.File, pair.B.StartLine, pair.B.EndLine)
+		}
+	default:
+		if len(report.Files) == 0 {
+			fmt.Println("No indexed files.")
+			return
+		}
+		if report.By == app.TopByChunks {
+			fmt.Printf("Top %d files by chunk count\n\n", len(report.Files))
+		} else {
+			fmt.Printf("Top %d files by size\n\n", len(report.Files))
+		}
+		for i, file := range report.Files {
+			fmt.Printf("%3d. %6d chunks  %10s  %s\n", i+1, file.Chunks, formatBytes(file.Size), file.File)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd checks index health. --embeddings re-embeds a sample of stored
+// chunks and compares them with the stored vectors.
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check the index for embedding drift",
+	Long: `Check that the current embedding setup still matches the index.
+
+--embeddings samples stored chunks (one per file, spread across the project),
+re-embeds them with the current provider, and compares each fresh vector with
+the stored one. It reports a changed model or embedding profile, a dimension
+mismatch, chunks whose similarity fell below 0.90, and a change in vector
+normalization. Structural chunks were embedded with extra context, so a few
+samples slightly below 1.0 are expected.
+
+The command exits non-zero when the index is unhealthy: the profile or
+dimensions differ, or more than a quarter of the samples drifted.`,
+	Example: `  vecgrep verify --embeddings
+  vecgrep verify --embeddings --sample 50 -f json`,
+	Args: cobra.NoArgs,
+	RunE: runVerify,
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	checkEmbeddings, _ := cmd.Flags().GetBool("embeddings")
+	sampleSize, _ := cmd.Flags().GetInt("sample")
+	format, _ := cmd.Flags().GetString("format")
+	if !checkEmbeddings {
+		return fmt.Errorf("nothing to verify: pass --embeddings")
+	}
+
+	session, err := app.OpenReadOnlySession(cmd.Context(), "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	report, err := app.NewService(session).VerifyEmbeddings(cmd.Context(), sampleSize)
+	if err != nil {
+		return fmt.Errorf("verify embeddings: %w", err)
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printEmbeddingVerification(report)
+	}
+	if !report.Healthy {
+		return fmt.Errorf("embedding verification failed")
+	}
+	return nil
+}
+
+func printEmbeddingVerification(report *app.EmbeddingVerification) {
+	fmt.Println("Embedding Verification")
+	fmt.Println("======================")
+	fmt.Printf("Provider:        %s (%s)\n", report.Provider, report.Model)
+	fmt.Printf("Dimensions:      index %d, provider %d\n", report.IndexDimensions, report.ProviderDimensions)
+	if report.ProfileError != "" {
+		fmt.Printf("Profile:         %s\n", report.ProfileError)
+	}
+	if report.Sampled > 0 {
+		fmt.Printf("Sampled chunks:  %d\n", report.Sampled)
+		fmt.Printf("Similarity:      mean %.4f, min %.4f\n", report.MeanSimilarity, report.MinSimilarity)
+		fmt.Printf("Vector norm:     stored %.3f, now %.3f\n", report.StoredNorm, report.CurrentNorm)
+	}
+	for _, d := range report.Drifted {
+		fmt.Printf("  drifted: %s:%d-%d (similarity %.4f)\n", d.File, d.StartLine, d.EndLine, d.Similarity)
+	}
+	if len(report.Findings) > 0 {
+		fmt.Println("\nFindings:")
+		for _, finding := range report.Findings {
+			fmt.Printf("  - %s\n", finding)
+		}
+	}
+	if report.Healthy {
+		fmt.Println("\nResult: healthy")
+	} else {
+		fmt.Println("\nResult: drift detected; run 'vecgrep index --full' to rebuild with the current provider")
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+	"github.com/spf13/cobra"
+)
+
+// watchCmd keeps the index current as files change and, with --query, re-runs
+// a saved query and reports matches that newly cross the score threshold.
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Re-index changed files as you edit, optionally re-running a query",
+	Long: `Watch the project and incrementally re-index changed files after each
+debounced batch of changes, until interrupted. The debounce defaults to
+daemon.debounce and the configured ignore patterns apply; --debounce and
+--ignore override or extend them for this run.
+
+Changes are found through file system events, or by rescanning the tree
+every daemon.poll_interval and comparing content hashes when --poll or
+daemon.poll is set. Polling is chosen automatically on network file systems
+and when events cannot be set up, as in many containers; each scan's changes
+form one batch.
+
+With --query, the saved query is re-run after each batch. Matches scoring at
+or above --min-score that were not in the previous run are printed, or passed
+to --exec as JSON on stdin:
+
+  {"query": "...", "min_score": 0.8, "project_root": "...", "matches": [...]}
+
+Matches present when watching starts are the baseline and are not reported.
+The command holds the project's write lock while it runs, like 'vecgrep
+index'; stop the daemon for this project first.`,
+	Example: `  vecgrep watch
+  vecgrep watch --debounce 2s --ignore "testdata/**"
+  vecgrep watch --poll --poll-interval 5s
+  vecgrep watch --query "hardcoded credentials" --min-score 0.8
+  vecgrep watch -q "TODO: remove before release" --exec "./scripts/notify.sh"`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+// watchOptions are the flags of one watch run.
+type watchOptions struct {
+	Query        string
+	MinScore     float32
+	Exec         string
+	Limit        int
+	Mode         string
+	Debounce     time.Duration
+	Ignore       []string
+	Poll         bool
+	PollInterval time.Duration
+}
+
+func watchOptionsFromFlags(cmd *cobra.Command) watchOptions {
+	var opts watchOptions
+	opts.Query, _ = cmd.Flags().GetString("query")
+	opts.MinScore, _ = cmd.Flags().GetFloat32("min-score")
+	opts.Exec, _ = cmd.Flags().GetString("exec")
+	opts.Limit, _ = cmd.Flags().GetInt("limit")
+	opts.Mode, _ = cmd.Flags().GetString("mode")
+	opts.Debounce, _ = cmd.Flags().GetDuration("debounce")
+	opts.Ignore, _ = cmd.Flags().GetStringSlice("ignore")
+	opts.Poll, _ = cmd.Flags().GetBool("poll")
+	opts.PollInterval, _ = cmd.Flags().GetDuration("poll-interval")
+	return opts
+}
+
+// validate rejects flag combinations before the project is opened.
+func (opts watchOptions) validate() error {
+	if opts.MinScore < 0 || opts.MinScore > 1 {
+		return fmt.Errorf("--min-score must be between 0 and 1")
+	}
+	if opts.Debounce < 0 {
+		return fmt.Errorf("--debounce must not be negative")
+	}
+	if opts.PollInterval < 0 {
+		return fmt.Errorf("--poll-interval must not be negative")
+	}
+	if opts.Query == "" && opts.Exec != "" {
+		return fmt.Errorf("--exec requires --query")
+	}
+	return nil
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	opts := watchOptionsFromFlags(cmd)
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	session, err := app.OpenSession(ctx, "")
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	return watchProject(ctx, session, opts, os.Stdout, os.Stderr)
+}
+
+// watcherConfigFor builds the watcher settings from the daemon config and
+// the run's flags: --debounce and --poll-interval override the config, and
+// --ignore extends the configured ignore patterns.
+func watcherConfigFor(cfg *config.Config, opts watchOptions) index.WatcherConfig {
+	watcherCfg := index.DefaultWatcherConfig()
+	if cfg.Daemon.Debounce > 0 {
+		watcherCfg.Debounce = time.Duration(cfg.Daemon.Debounce) * time.Millisecond
+	}
+	if opts.Debounce > 0 {
+		watcherCfg.Debounce = opts.Debounce
+	}
+	watcherCfg.Poll = opts.Poll || cfg.Daemon.Poll
+	if cfg.Daemon.PollInterval > 0 {
+		watcherCfg.PollInterval = time.Duration(cfg.Daemon.PollInterval) * time.Millisecond
+	}
+	if opts.PollInterval > 0 {
+		watcherCfg.PollInterval = opts.PollInterval
+	}
+	indexCfg := app.BuildIndexerConfig(cfg, nil)
+	watcherCfg.IgnorePatterns = append(append([]string(nil), indexCfg.IgnorePatterns...), opts.Ignore...)
+	watcherCfg.MaxFileSize = indexCfg.MaxFileSize
+	return watcherCfg
+}
+
+// watchProject re-indexes session's project after each batch of changes
+// until ctx is done, reporting to stdout and stderr.
+func watchProject(ctx context.Context, session *app.Session, opts watchOptions, stdout, stderr io.Writer) error {
+	service := app.NewService(session)
+	cfg := session.Config
+
+	req := app.SearchRequest{
+		Query:    opts.Query,
+		Limit:    opts.Limit,
+		MinScore: opts.MinScore,
+		Mode:     app.ParseSearchMode(opts.Mode, cfg.Search.DefaultMode),
+	}
+	tracker := app.NewMatchTracker(opts.MinScore)
+	existing := 0
+	if opts.Query != "" {
+		baseline, err := service.Search(ctx, req)
+		if err != nil {
+			return fmt.Errorf("search failed: %w", err)
+		}
+		existing = len(tracker.Update(baseline.Results))
+	}
+
+	watcherCfg := watcherConfigFor(cfg, opts)
+	watcher, err := index.NewWatcher(session.ProjectRoot, watcherCfg)
+	if err != nil {
+		return fmt.Errorf("start watcher: %w", err)
+	}
+	watcher.SetCallback(func(events []index.WatchEvent) {
+		result, err := service.ApplyWatchEvents(ctx, events)
+		if err != nil {
+			fmt.Fprintf(stderr, "Warning: re-index failed: %v\n", err)
+			return
+		}
+		if opts.Query == "" {
+			printWatchReindex(stdout, stderr, result)
+			return
+		}
+		resp, err := service.Search(ctx, req)
+		if err != nil {
+			fmt.Fprintf(stderr, "Warning: search failed: %v\n", err)
+			return
+		}
+		for _, w := range resp.Warnings {
+			fmt.Fprintf(stderr, "Warning: %s\n", w)
+		}
+		fresh := tracker.Update(resp.Results)
+		if len(fresh) == 0 {
+			return
+		}
+		if opts.Exec == "" {
+			fmt.Fprintf(stdout, "[%s] %d new match(es) for %q\n", time.Now().Format("15:04:05"), len(fresh), opts.Query)
+			printSearchResults(fresh, "default", nil)
+			return
+		}
+		notification := app.WatchNotification{Query: opts.Query, MinScore: opts.MinScore, ProjectRoot: session.ProjectRoot, Matches: fresh}
+		if err := app.NotifyWatchMatches(ctx, opts.Exec, notification, stdout); err != nil {
+			fmt.Fprintf(stderr, "Warning: %v\n", err)
+		}
+	})
+	if err := watcher.Start(ctx); err != nil {
+		return fmt.Errorf("start watcher: %w", err)
+	}
+	defer watcher.Stop()
+
+	if mode := watcher.Mode(); mode != "events" {
+		fmt.Fprintf(stderr, "Detecting changes by %s.\n", mode)
+	}
+	if opts.Query == "" {
+		fmt.Fprintf(stderr, "Watching %s (debounce %s). Press Ctrl+C to stop.\n", session.ProjectRoot, watcherCfg.Debounce)
+	} else {
+		fmt.Fprintf(stderr, "Watching %s for %q (min score %.2f, %d existing match(es)). Press Ctrl+C to stop.\n", session.ProjectRoot, opts.Query, opts.MinScore, existing)
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// printWatchReindex reports one re-indexed batch when watch runs without a
+// query.
+func printWatchReindex(stdout, stderr io.Writer, result *index.IndexResult) {
+	if result == nil || result.FilesProcessed+result.FilesDeleted+len(result.Errors) == 0 {
+		return
+	}
+	fmt.Fprintf(stdout, "[%s] re-indexed %d file(s), %d chunk(s); removed %d file(s)\n",
+		time.Now().Format("15:04:05"), result.FilesProcessed, result.ChunksCreated, result.FilesDeleted)
+	for _, err := range result.Errors {
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+	}
+}
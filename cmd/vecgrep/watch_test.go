@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a bytes.Buffer safe to write from the watcher's goroutine
+// while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchOptionsValidate(t *testing.T) {
+	if err := (watchOptions{Exec: "./notify.sh"}).validate(); err == nil || !strings.Contains(err.Error(), "--exec requires --query") {
+		t.Fatalf("--exec without --query: err = %v, want an error", err)
+	}
+	if err := (watchOptions{Query: "secrets", Exec: "./notify.sh"}).validate(); err != nil {
+		t.Fatalf("--exec with --query: %v", err)
+	}
+	if err := (watchOptions{}).validate(); err != nil {
+		t.Fatalf("no query: %v", err)
+	}
+	for _, opts := range []watchOptions{{Debounce: -time.Second}, {PollInterval: -time.Second}, {Query: "q", MinScore: 1.5}} {
+		if err := opts.validate(); err == nil {
+			t.Errorf("validate(%+v) accepted invalid flags", opts)
+		}
+	}
+}
+
+func TestWatcherConfigAppliesDebounceAndIgnoreFlags(t *testing.T) {
+	_, session := createTestService(t)
+	cfg := session.Config
+	cfg.Daemon.Debounce = 900
+
+	watcherCfg := watcherConfigFor(cfg, watchOptions{})
+	if watcherCfg.Debounce != 900*time.Millisecond {
+		t.Fatalf("debounce = %s, want daemon.debounce", watcherCfg.Debounce)
+	}
+	watcherCfg = watcherConfigFor(cfg, watchOptions{Debounce: 2 * time.Second, Ignore: []string{"testdata/**"}})
+	if watcherCfg.Debounce != 2*time.Second {
+		t.Fatalf("debounce = %s, want the --debounce override", watcherCfg.Debounce)
+	}
+	if !slices.Contains(watcherCfg.IgnorePatterns, "testdata/**") || len(watcherCfg.IgnorePatterns) < 2 {
+		t.Fatalf("ignore patterns = %q, want the configured ones plus testdata/**", watcherCfg.IgnorePatterns)
+	}
+}
+
+func TestWatchProjectWithoutQueryReindexesOneDebouncedBatch(t *testing.T) {
+	_, session := createTestService(t)
+	session.Provider = watchTestProvider{dimensions: session.Config.Embedding.Dimensions, model: session.Config.Embedding.Model}
+	root := session.ProjectRoot
+	if err := os.MkdirAll(filepath.Join(root, "testdata"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stdout, stderr syncBuffer
+	done := make(chan error, 1)
+	go func() {
+		done <- watchProject(ctx, session, watchOptions{Debounce: 300 * time.Millisecond, Ignore: []string{"testdata/**"}}, &stdout, &stderr)
+	}()
+	waitFor(t, func() bool { return strings.Contains(stderr.String(), "Watching ") }, "watch to start")
+
+	// Three files written well inside one debounce window, plus one the
+	// --ignore glob drops.
+	for _, name := range []string{"a.go", "b.go", "c.go", filepath.Join("testdata", "fixture.go")} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool { return strings.Contains(stdout.String(), "re-indexed") }, "a re-index")
+	time.Sleep(600 * time.Millisecond) // two more debounce windows for a stray batch
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("watchProject: %v", err)
+	}
+
+	out := stdout.String()
+	if n := strings.Count(out, "re-indexed"); n != 1 {
+		t.Fatalf("got %d re-index reports, want one batch:\n%s", n, out)
+	}
+	if !strings.Contains(out, "re-indexed 3 file(s)") {
+		t.Fatalf("report = %q, want the three files outside testdata", out)
+	}
+	files, err := session.DB.ListFiles(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f.RelativePath, "testdata") {
+			t.Fatalf("ignored file %s was indexed", f.RelativePath)
+		}
+	}
+	if stderr := stderr.String(); strings.Contains(stderr, "Warning") {
+		t.Fatalf("stderr = %q, want no warnings", stderr)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool, what string) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// watchTestProvider embeds every text as the same unit vector, so watch
+// can index without a model.
+type watchTestProvider struct {
+	dimensions int
+	model      string
+}
+
+func (p watchTestProvider) Embed(context.Context, string) ([]float32, error) {
+	vector := make([]float32, p.dimensions)
+	vector[0] = 1
+	return vector, nil
+}
+
+func (p watchTestProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = p.Embed(ctx, text)
+	}
+	return vectors, nil
+}
+
+func (p watchTestProvider) Model() string                                 { return p.model }
+func (p watchTestProvider) Dimensions() int                               { return p.dimensions }
+func (p watchTestProvider) Ping(context.Context) error                    { return nil }
+func (p watchTestProvider) Warmup(context.Context) (time.Duration, error) { return 0, nil }
@@ -0,0 +1,299 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+const (
+	annotationsSchemaVersion = 1
+	annotationsFilename      = "annotations.json"
+
+	// AnnotationChunkType marks annotation hits in search results.
+	AnnotationChunkType = "annotation"
+
+	// maxAnnotationHits caps how many annotations ride along with one search.
+	maxAnnotationHits = 3
+	// minAnnotationScore keeps weakly related notes out of unrelated searches.
+	minAnnotationScore = 0.35
+)
+
+// Annotation is a free-text note attached to a code location. Its text is
+// embedded with the project's model so searches can surface it next to the
+// code it describes. Annotations live outside the vector index, so they
+// survive re-indexing and resets.
+type Annotation struct {
+	ID        uint64    `json:"id"`
+	Key       string    `json:"key"`
+	File      string    `json:"file"`
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Text      string    `json:"text"`
+	Model     string    `json:"model"`
+	Embedding []float32 `json:"embedding"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type annotationsFile struct {
+	SchemaVersion int          `json:"schema_version"`
+	NextID        uint64       `json:"next_id"`
+	Annotations   []Annotation `json:"annotations"`
+}
+
+// annotationsMu serializes read-modify-write cycles within one process.
+var annotationsMu sync.Mutex
+
+// AnnotationsPath returns the annotations file for a data directory.
+func AnnotationsPath(dataDir string) string {
+	return filepath.Join(dataDir, annotationsFilename)
+}
+
+// AddAnnotation embeds text and attaches it to location (file:line or
+// file:start-end).
+func AddAnnotation(ctx context.Context, dataDir string, provider embed.Provider, location, text string) (Annotation, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return Annotation{}, fmt.Errorf("annotation text is empty")
+	}
+	if provider == nil {
+		return Annotation{}, ErrProviderRequired
+	}
+	file, startLine, endLine, err := ParseChunkKey(location)
+	if err != nil {
+		return Annotation{}, err
+	}
+	vec, err := embedAnnotation(ctx, provider, file, text)
+	if err != nil {
+		return Annotation{}, fmt.Errorf("embed annotation: %w", err)
+	}
+
+	annotationsMu.Lock()
+	defer annotationsMu.Unlock()
+	stored, err := loadAnnotations(dataDir)
+	if err != nil {
+		return Annotation{}, err
+	}
+	stored.NextID++
+	a := Annotation{
+		ID:        stored.NextID,
+		Key:       ChunkKey(file, startLine, endLine),
+		File:      file,
+		StartLine: startLine,
+		EndLine:   endLine,
+		Text:      text,
+		Model:     provider.Model(),
+		Embedding: vec,
+		CreatedAt: time.Now().UTC(),
+	}
+	stored.Annotations = append(stored.Annotations, a)
+	if err := writeAnnotations(dataDir, stored); err != nil {
+		return Annotation{}, err
+	}
+	return a, nil
+}
+
+// ListAnnotations returns annotations in creation order, optionally limited
+// to one file.
+func ListAnnotations(dataDir, file string) ([]Annotation, error) {
+	annotationsMu.Lock()
+	defer annotationsMu.Unlock()
+	stored, err := loadAnnotations(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if file == "" {
+		return stored.Annotations, nil
+	}
+	file = normalizeBookmarkFile(file)
+	out := make([]Annotation, 0, len(stored.Annotations))
+	for _, a := range stored.Annotations {
+		if a.File == file {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// RemoveAnnotation deletes an annotation by ID. removed is false when no
+// annotation has that ID.
+func RemoveAnnotation(dataDir string, id uint64) (removed bool, err error) {
+	annotationsMu.Lock()
+	defer annotationsMu.Unlock()
+	stored, err := loadAnnotations(dataDir)
+	if err != nil {
+		return false, err
+	}
+	before := len(stored.Annotations)
+	stored.Annotations = slices.DeleteFunc(stored.Annotations, func(a Annotation) bool { return a.ID == id })
+	if len(stored.Annotations) == before {
+		return false, nil
+	}
+	return true, writeAnnotations(dataDir, stored)
+}
+
+// searchAnnotations ranks annotations against query and returns the best
+// matches as search results marked Annotation. It embeds the query only when
+// at least one annotation could match, so projects without annotations pay
+// nothing. Annotations embedded with another model are skipped and counted.
+func searchAnnotations(ctx context.Context, dataDir string, provider embed.Provider, query string, req SearchRequest) (hits []search.Result, skipped int, err error) {
+	if provider == nil || dataDir == "" {
+		return nil, 0, nil
+	}
+	annotations, err := ListAnnotations(dataDir, "")
+	if err != nil || len(annotations) == 0 {
+		return nil, 0, err
+	}
+	model := provider.Model()
+	candidates := make([]Annotation, 0, len(annotations))
+	for _, a := range annotations {
+		if !annotationMatchesScope(a, req) {
+			continue
+		}
+		if a.Model != model {
+			skipped++
+			continue
+		}
+		candidates = append(candidates, a)
+	}
+	if len(candidates) == 0 {
+		return nil, skipped, nil
+	}
+
+	var queryVec []float32
+	if queryProvider, ok := provider.(embed.QueryProvider); ok {
+		queryVec, err = queryProvider.EmbedQuery(ctx, query)
+	} else {
+		queryVec, err = provider.Embed(ctx, query)
+	}
+	if err != nil {
+		return nil, skipped, fmt.Errorf("embed query for annotations: %w", err)
+	}
+
+	minScore := max(float32(minAnnotationScore), req.MinScore)
+	for _, a := range candidates {
+		score := cosineSimilarity(queryVec, a.Embedding)
+		if score < minScore {
+			continue
+		}
+		hits = append(hits, search.Result{
+			RelativePath: a.File,
+			Content:      a.Text,
+			StartLine:    a.StartLine,
+			EndLine:      a.EndLine,
+			ChunkType:    AnnotationChunkType,
+			Distance:     score,
+			Score:        score,
+			Annotation:   true,
+		})
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if len(hits) > maxAnnotationHits {
+		hits = hits[:maxAnnotationHits]
+	}
+	return hits, skipped, nil
+}
+
+// annotationMatchesScope applies the location filters of a search request;
+// language and chunk-type filters do not apply to free-text notes.
+func annotationMatchesScope(a Annotation, req SearchRequest) bool {
+	if len(req.FilePaths) > 0 && !slices.Contains(req.FilePaths, a.File) {
+		return false
+	}
+	if req.Directory != "" && !strings.HasPrefix(a.File, strings.TrimSuffix(normalizeBookmarkFile(req.Directory), "/")+"/") {
+		return false
+	}
+	if len(req.Directories) > 0 && !slices.ContainsFunc(req.Directories, func(dir string) bool {
+		return strings.HasPrefix(a.File, strings.TrimSuffix(normalizeBookmarkFile(dir), "/")+"/")
+	}) {
+		return false
+	}
+	if req.FilePattern != "" {
+		full, _ := path.Match(req.FilePattern, a.File)
+		base, _ := path.Match(req.FilePattern, path.Base(a.File))
+		if !full && !base {
+			return false
+		}
+	}
+	if req.MaxLine > 0 && a.StartLine > req.MaxLine {
+		return false
+	}
+	if req.MinLine > 0 && a.EndLine < req.MinLine {
+		return false
+	}
+	return true
+}
+
+func embedAnnotation(ctx context.Context, provider embed.Provider, file, text string) ([]float32, error) {
+	// Prefix the path so a note like "retries are capped here" still carries
+	// where "here" is.
+	doc := file + "\n" + text
+	if documents, ok := provider.(embed.DocumentProvider); ok {
+		vecs, err := documents.EmbedDocuments(ctx, []string{doc})
+		if err != nil {
+			return nil, err
+		}
+		if len(vecs) != 1 {
+			return nil, fmt.Errorf("expected 1 embedding, got %d", len(vecs))
+		}
+		return vecs[0], nil
+	}
+	return provider.Embed(ctx, doc)
+}
+
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
+
+func loadAnnotations(dataDir string) (*annotationsFile, error) {
+	if strings.TrimSpace(dataDir) == "" {
+		return nil, fmt.Errorf("annotations data dir is empty")
+	}
+	data, err := os.ReadFile(AnnotationsPath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return &annotationsFile{SchemaVersion: annotationsSchemaVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read annotations: %w", err)
+	}
+	var stored annotationsFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode annotations: %w", err)
+	}
+	if stored.SchemaVersion != annotationsSchemaVersion {
+		return nil, fmt.Errorf("unsupported annotations schema version %d", stored.SchemaVersion)
+	}
+	return &stored, nil
+}
+
+func writeAnnotations(dataDir string, stored *annotationsFile) error {
+	if err := writeJSONAtomic(dataDir, AnnotationsPath(dataDir), stored); err != nil {
+		return fmt.Errorf("write annotations: %w", err)
+	}
+	return nil
+}
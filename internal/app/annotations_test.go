@@ -0,0 +1,73 @@
+package app
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAnnotationsAreSearchableAndScoped(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	provider := fakeProvider{dimensions: 4, model: "fake-model"}
+
+	if _, err := AddAnnotation(ctx, dataDir, provider, "internal/embed/throttle.go:40-60", "cache key must include the model"); err != nil {
+		t.Fatalf("AddAnnotation: %v", err)
+	}
+	if _, err := AddAnnotation(ctx, dataDir, provider, "cmd/vecgrep/main.go:12", "flags are registered in init"); err != nil {
+		t.Fatalf("AddAnnotation: %v", err)
+	}
+	if _, err := AddAnnotation(ctx, dataDir, provider, "main.go:1", "   "); err == nil {
+		t.Fatal("empty annotation text should fail")
+	}
+
+	hits, skipped, err := searchAnnotations(ctx, dataDir, provider, "cache key", SearchRequest{})
+	if err != nil || skipped != 0 || len(hits) != 2 {
+		t.Fatalf("searchAnnotations = %d hits, %d skipped, %v; want 2, 0, nil", len(hits), skipped, err)
+	}
+	for _, hit := range hits {
+		if !hit.Annotation || hit.ChunkType != AnnotationChunkType || hit.ChunkID != 0 {
+			t.Fatalf("hit not marked as annotation: %+v", hit)
+		}
+	}
+
+	hits, _, _ = searchAnnotations(ctx, dataDir, provider, "cache key", SearchRequest{Directory: "internal/embed"})
+	if len(hits) != 1 || hits[0].RelativePath != "internal/embed/throttle.go" || hits[0].StartLine != 40 {
+		t.Fatalf("directory-scoped hits = %+v", hits)
+	}
+
+	other := fakeProvider{dimensions: 4, model: "other-model"}
+	hits, skipped, err = searchAnnotations(ctx, dataDir, other, "cache key", SearchRequest{})
+	if err != nil || len(hits) != 0 || skipped != 2 {
+		t.Fatalf("model mismatch = %d hits, %d skipped, %v; want 0, 2, nil", len(hits), skipped, err)
+	}
+}
+
+func TestSearchAnnotationsWithoutAnnotationsSkipsEmbedding(t *testing.T) {
+	hits, skipped, err := searchAnnotations(context.Background(), t.TempDir(), nil, "q", SearchRequest{})
+	if err != nil || skipped != 0 || hits != nil {
+		t.Fatalf("empty store = %v, %d, %v", hits, skipped, err)
+	}
+}
+
+func TestListAndRemoveAnnotations(t *testing.T) {
+	ctx := context.Background()
+	dataDir := t.TempDir()
+	provider := fakeProvider{dimensions: 2, model: "fake-model"}
+
+	first, err := AddAnnotation(ctx, dataDir, provider, "a.go:1-2", "first")
+	if err != nil {
+		t.Fatalf("AddAnnotation: %v", err)
+	}
+	if _, err := AddAnnotation(ctx, dataDir, provider, "b.go:3", "second"); err != nil {
+		t.Fatalf("AddAnnotation: %v", err)
+	}
+	if got, _ := ListAnnotations(dataDir, "./a.go"); len(got) != 1 || got[0].ID != first.ID {
+		t.Fatalf("ListAnnotations(a.go) = %+v", got)
+	}
+	if removed, err := RemoveAnnotation(dataDir, first.ID); err != nil || !removed {
+		t.Fatalf("RemoveAnnotation = %v, %v", removed, err)
+	}
+	if got, _ := ListAnnotations(dataDir, ""); len(got) != 1 {
+		t.Fatalf("after remove = %d annotations, want 1", len(got))
+	}
+}
@@ -0,0 +1,377 @@
+package app
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/version"
+)
+
+// IndexArchiveFormat is the archive layout version written by ExportIndex.
+// ImportIndex rejects archives with a different version.
+const IndexArchiveFormat = 1
+
+const (
+	archiveManifestName = "manifest.json"
+	archiveChunksName   = "chunks.jsonl"
+	// archiveSummariesName holds the directory summaries, when there are
+	// any, after the chunks.
+	archiveSummariesName = "summaries.json"
+	// importBatchSize bounds the chunks inserted per storage batch.
+	importBatchSize = 256
+)
+
+// IndexArchiveManifest describes an exported index. It is the first entry of
+// the archive so ImportIndex can reject an incompatible archive before
+// reading any chunks.
+type IndexArchiveManifest struct {
+	Format         int              `json:"format"`
+	VecgrepVersion string           `json:"vecgrep_version"`
+	ExportedAt     time.Time        `json:"exported_at"`
+	ProjectName    string           `json:"project_name"`
+	Profile        EmbeddingProfile `json:"embedding_profile"`
+	Files          int              `json:"files"`
+	Chunks         int              `json:"chunks"`
+	Summaries      int              `json:"summaries,omitempty"`
+}
+
+// archivedChunk is one line of chunks.jsonl. Paths are relative to the
+// project root so an archive built in CI imports into any checkout.
+type archivedChunk struct {
+	RelativePath   string    `json:"relative_path"`
+	FileHash       string    `json:"file_hash"`
+	SourceHash     string    `json:"source_hash,omitempty"`
+	FileSize       int64     `json:"file_size"`
+	Language       string    `json:"language"`
+	Content        string    `json:"content"`
+	StartLine      int       `json:"start_line"`
+	EndLine        int       `json:"end_line"`
+	StartByte      int       `json:"start_byte"`
+	EndByte        int       `json:"end_byte"`
+	ChunkIndex     int       `json:"chunk_index"`
+	ParentIndex    *int      `json:"parent_index,omitempty"`
+	ChunkType      string    `json:"chunk_type"`
+	SymbolName     string    `json:"symbol_name,omitempty"`
+	IndexedAt      time.Time `json:"indexed_at"`
+	IndexerVersion string    `json:"indexer_version,omitempty"`
+	ChunkStrategy  string    `json:"chunk_strategy,omitempty"`
+	ChunkParams    string    `json:"chunk_params,omitempty"`
+	EmbedHash      string    `json:"embed_hash,omitempty"`
+	EmbedVersion   string    `json:"embed_version,omitempty"`
+	Truncated      bool      `json:"truncated,omitempty"`
+	// TruncationEstimated is set when Truncated rests on an estimated
+	// token count.
+	TruncationEstimated bool      `json:"truncation_estimated,omitempty"`
+	Secrets             []string  `json:"secrets,omitempty"`
+	Tags                []string  `json:"tags,omitempty"`
+	Vector              []float32 `json:"vector"`
+}
+
+// ExportIndex writes the project's chunks, metadata, and vectors to w as a
+// gzip-compressed tar archive.
+func (s *Service) ExportIndex(ctx context.Context, w io.Writer) (*IndexArchiveManifest, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	profile, err := LoadEmbeddingProfile(s.session.DB, s.session.Config.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("load embedding profile: %w", err)
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("index has no embedding profile; run 'vecgrep index' first")
+	}
+	files, err := s.session.DB.ListFiles(s.session.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("list indexed files: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].RelativePath < files[j].RelativePath })
+
+	// tar needs each entry's size up front, so chunks are staged in a temp
+	// file while the manifest counts are gathered.
+	staged, err := os.CreateTemp("", "vecgrep-export-*.jsonl")
+	if err != nil {
+		return nil, fmt.Errorf("create staging file: %w", err)
+	}
+	defer os.Remove(staged.Name())
+	defer staged.Close()
+
+	manifest := &IndexArchiveManifest{
+		Format:         IndexArchiveFormat,
+		VecgrepVersion: version.Version,
+		ExportedAt:     time.Now().UTC(),
+		ProjectName:    s.session.ProjectName,
+		Profile:        *profile,
+	}
+	buf := bufio.NewWriter(staged)
+	enc := json.NewEncoder(buf)
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunks, err := s.session.DB.GetChunksByFile(file.RelativePath)
+		if err != nil {
+			return nil, fmt.Errorf("read chunks for %s: %w", file.RelativePath, err)
+		}
+		sort.Slice(chunks, func(i, j int) bool { return chunks[i].ChunkIndex < chunks[j].ChunkIndex })
+		written := 0
+		for _, chunk := range chunks {
+			if chunk.ProjectRoot != s.session.ProjectRoot || len(chunk.Vector) == 0 {
+				continue
+			}
+			if err := enc.Encode(archivedChunkFrom(chunk)); err != nil {
+				return nil, fmt.Errorf("write chunk: %w", err)
+			}
+			written++
+		}
+		if written > 0 {
+			manifest.Files++
+			manifest.Chunks += written
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return nil, fmt.Errorf("write staging file: %w", err)
+	}
+	summaries, err := ListDirectorySummaries(s.session.Config.DataDir)
+	if err != nil {
+		return nil, fmt.Errorf("read summaries: %w", err)
+	}
+	manifest.Summaries = len(summaries)
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeArchiveEntry(tw, archiveManifestName, int64(len(manifestJSON)), manifest.ExportedAt, bytes.NewReader(manifestJSON)); err != nil {
+		return nil, err
+	}
+	size, err := staged.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := staged.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := writeArchiveEntry(tw, archiveChunksName, size, manifest.ExportedAt, staged); err != nil {
+		return nil, err
+	}
+	if len(summaries) > 0 {
+		summariesJSON, err := json.MarshalIndent(summariesFile{SchemaVersion: summariesSchemaVersion, Summaries: summaries}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		if err := writeArchiveEntry(tw, archiveSummariesName, int64(len(summariesJSON)), manifest.ExportedAt, bytes.NewReader(summariesJSON)); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("finish archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// ImportIndex replaces the project's index with the archive read from r. The
+// archive's embedding profile must match the active configuration, since its
+// vectors are only comparable with query embeddings from the same model. File
+// hashes are restored with the chunks, so a following 'vecgrep index' only
+// re-embeds files that differ from the exported checkout.
+func (s *Service) ImportIndex(ctx context.Context, r io.Reader) (*IndexArchiveManifest, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("open archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != archiveManifestName {
+		return nil, fmt.Errorf("open archive: %s is not the first entry", archiveManifestName)
+	}
+	var manifest IndexArchiveManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("read archive manifest: %w", err)
+	}
+	if manifest.Format != IndexArchiveFormat {
+		return nil, fmt.Errorf("unsupported archive format %d (this vecgrep reads format %d)", manifest.Format, IndexArchiveFormat)
+	}
+	current := CurrentEmbeddingProfile(s.session.Config)
+	if !manifest.Profile.Matches(current) {
+		return nil, fmt.Errorf("%w: archive %q, active %q; configure the same embedding provider and model as the exporting project",
+			ErrEmbeddingProfileMismatch, manifest.Profile.ProfileID, current.ProfileID)
+	}
+
+	header, err = tr.Next()
+	if err != nil || header.Name != archiveChunksName {
+		return nil, fmt.Errorf("open archive: missing %s", archiveChunksName)
+	}
+
+	if err := s.Reset(ctx, ResetProject); err != nil {
+		return nil, fmt.Errorf("reset project: %w", err)
+	}
+	dec := json.NewDecoder(bufio.NewReader(tr))
+	records := make([]db.ChunkRecord, 0, importBatchSize)
+	vectors := make([][]float32, 0, importBatchSize)
+	flush := func() error {
+		if len(records) == 0 {
+			return nil
+		}
+		if _, err := s.session.DB.InsertChunkBatch(records, vectors); err != nil {
+			return fmt.Errorf("insert chunks: %w", err)
+		}
+		records, vectors = records[:0], vectors[:0]
+		return nil
+	}
+	imported := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var chunk archivedChunk
+		if err := dec.Decode(&chunk); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("read chunk %d: %w", imported+1, err)
+		}
+		records = append(records, chunk.record(s.session.ProjectRoot))
+		vectors = append(vectors, chunk.Vector)
+		imported++
+		if len(records) == importBatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	if imported != manifest.Chunks {
+		return nil, fmt.Errorf("archive is truncated: read %d of %d chunks; the index is incomplete, re-run the import", imported, manifest.Chunks)
+	}
+	if manifest.Summaries > 0 {
+		if header, err = tr.Next(); err != nil || header.Name != archiveSummariesName {
+			return nil, fmt.Errorf("open archive: missing %s", archiveSummariesName)
+		}
+		var stored summariesFile
+		if err := json.NewDecoder(tr).Decode(&stored); err != nil {
+			return nil, fmt.Errorf("read archive summaries: %w", err)
+		}
+		if stored.SchemaVersion != summariesSchemaVersion {
+			return nil, fmt.Errorf("unsupported summaries schema version %d", stored.SchemaVersion)
+		}
+		if err := replaceDirectorySummaries(s.session.Config.DataDir, stored.Summaries); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.saveCurrentEmbeddingProfile(); err != nil {
+		return nil, err
+	}
+	if err := s.session.DB.Sync(); err != nil {
+		return nil, fmt.Errorf("sync index: %w", err)
+	}
+	return &manifest, nil
+}
+
+func archivedChunkFrom(chunk db.ChunkRecord) archivedChunk {
+	return archivedChunk{
+		RelativePath:        filepath.ToSlash(chunk.RelativePath),
+		FileHash:            chunk.FileHash,
+		SourceHash:          chunk.SourceHash,
+		FileSize:            chunk.FileSize,
+		Language:            chunk.Language,
+		Content:             chunk.Content,
+		StartLine:           chunk.StartLine,
+		EndLine:             chunk.EndLine,
+		StartByte:           chunk.StartByte,
+		EndByte:             chunk.EndByte,
+		ChunkIndex:          chunk.ChunkIndex,
+		ParentIndex:         archivedParent(chunk.ParentIndex),
+		ChunkType:           chunk.ChunkType,
+		SymbolName:          chunk.SymbolName,
+		IndexedAt:           chunk.IndexedAt,
+		IndexerVersion:      chunk.IndexerVersion,
+		ChunkStrategy:       chunk.ChunkStrategy,
+		ChunkParams:         chunk.ChunkParams,
+		EmbedHash:           chunk.EmbedHash,
+		EmbedVersion:        chunk.EmbedVersion,
+		Truncated:           chunk.Truncated,
+		TruncationEstimated: chunk.TruncationEstimated,
+		Secrets:             chunk.Secrets,
+		Tags:                chunk.Tags,
+		Vector:              chunk.Vector,
+	}
+}
+
+// archivedParent omits the parent of a top-level chunk, so archives from
+// before parents were recorded read the same way.
+func archivedParent(parentIndex int) *int {
+	if parentIndex < 0 {
+		return nil
+	}
+	return &parentIndex
+}
+
+// record rebuilds the stored chunk under projectRoot.
+func (c archivedChunk) record(projectRoot string) db.ChunkRecord {
+	relPath := filepath.FromSlash(c.RelativePath)
+	parentIndex := -1
+	if c.ParentIndex != nil {
+		parentIndex = *c.ParentIndex
+	}
+	return db.ChunkRecord{
+		FilePath:            filepath.Join(projectRoot, relPath),
+		RelativePath:        relPath,
+		FileHash:            c.FileHash,
+		SourceHash:          c.SourceHash,
+		FileSize:            c.FileSize,
+		Language:            c.Language,
+		Content:             c.Content,
+		StartLine:           c.StartLine,
+		EndLine:             c.EndLine,
+		StartByte:           c.StartByte,
+		EndByte:             c.EndByte,
+		ChunkIndex:          c.ChunkIndex,
+		ParentIndex:         parentIndex,
+		ChunkType:           c.ChunkType,
+		SymbolName:          c.SymbolName,
+		ProjectRoot:         projectRoot,
+		IndexedAt:           c.IndexedAt,
+		IndexerVersion:      c.IndexerVersion,
+		ChunkStrategy:       c.ChunkStrategy,
+		ChunkParams:         c.ChunkParams,
+		EmbedHash:           c.EmbedHash,
+		EmbedVersion:        c.EmbedVersion,
+		Truncated:           c.Truncated,
+		TruncationEstimated: c.TruncationEstimated,
+		Secrets:             c.Secrets,
+		Tags:                c.Tags,
+	}
+}
+
+func writeArchiveEntry(tw *tar.Writer, name string, size int64, modTime time.Time, r io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: size, ModTime: modTime}); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExportImportIndexRoundTrip(t *testing.T) {
+	src, srcService := createTestSession(t)
+	src.Provider = fakeProvider{dimensions: src.Config.Embedding.Dimensions, model: src.Config.Embedding.Model}
+	for _, name := range []string{"a.go", filepath.Join("pkg", "b.go")} {
+		path := filepath.Join(src.ProjectRoot, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := srcService.Index(context.Background(), IndexRequest{FullReindex: true}, nil); err != nil {
+		t.Fatalf("full index failed: %v", err)
+	}
+
+	summary := DirectorySummary{Dir: "pkg", Summary: "Package b.", Source: "heuristic", Files: 1}
+	if err := saveDirectorySummary(src.Config.DataDir, summary); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	exported, err := srcService.ExportIndex(context.Background(), &archive)
+	if err != nil {
+		t.Fatalf("ExportIndex: %v", err)
+	}
+	if exported.Files != 2 || exported.Chunks == 0 || exported.Summaries != 1 {
+		t.Fatalf("manifest = %+v, want 2 files and 1 summary", exported)
+	}
+
+	dst, dstService := createTestSession(t)
+	imported, err := dstService.ImportIndex(context.Background(), bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatalf("ImportIndex: %v", err)
+	}
+	if imported.Chunks != exported.Chunks {
+		t.Fatalf("imported %d chunks, want %d", imported.Chunks, exported.Chunks)
+	}
+	files, err := dst.DB.ListFiles(dst.ProjectRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("imported files = %+v, want 2", files)
+	}
+	for _, f := range files {
+		if filepath.Dir(f.Path) != filepath.Join(dst.ProjectRoot, filepath.Dir(f.RelativePath)) {
+			t.Fatalf("file path %s was not rebased onto %s", f.Path, dst.ProjectRoot)
+		}
+	}
+	if profile, err := LoadEmbeddingProfile(dst.DB, dst.Config.DataDir); err != nil || profile == nil {
+		t.Fatalf("imported profile = %+v, %v", profile, err)
+	}
+	if summaries, err := ListDirectorySummaries(dst.Config.DataDir); err != nil || len(summaries) != 1 || summaries[0].Summary != summary.Summary {
+		t.Fatalf("imported summaries = %+v, %v", summaries, err)
+	}
+
+	if err := dstService.Reset(context.Background(), ResetProject); err != nil {
+		t.Fatal(err)
+	}
+	if summaries, err := ListDirectorySummaries(dst.Config.DataDir); err != nil || len(summaries) != 0 {
+		t.Fatalf("summaries after reset = %+v, %v", summaries, err)
+	}
+
+	dst.Config.Embedding.Model = "other-model"
+	if _, err := dstService.ImportIndex(context.Background(), bytes.NewReader(archive.Bytes())); !errors.Is(err, ErrEmbeddingProfileMismatch) {
+		t.Fatalf("import with another model: err = %v, want ErrEmbeddingProfileMismatch", err)
+	}
+}
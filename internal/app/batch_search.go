@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// DefaultBatchSearchConcurrency is how many queries a batch search runs at
+// once when BatchSearchRequest.Concurrency is unset.
+const DefaultBatchSearchConcurrency = 4
+
+// BatchSearchRequest runs several queries with the same filters, as the
+// vecgrep_batch_search tool does.
+type BatchSearchRequest struct {
+	Queries []string
+	// Search holds the filters and limit applied to every query; its Query
+	// and Dedupe fields are ignored.
+	Search SearchRequest
+	// Dedupe drops results an earlier query already returned, in query
+	// order.
+	Dedupe search.DedupeMode
+	// Concurrency caps the queries searched at once.
+	Concurrency int
+}
+
+// BatchSearchResponse holds one group per query, in request order, and the
+// distinct warnings of all of them.
+type BatchSearchResponse struct {
+	Groups   []search.QueryResults
+	Warnings []string
+	// Failed counts the queries whose search returned an error; their
+	// groups carry it.
+	Failed int
+}
+
+// BatchSearch searches every query of req concurrently. A failing query
+// does not stop the others: its error is recorded in its group.
+func (s *Service) BatchSearch(ctx context.Context, req BatchSearchRequest) (*BatchSearchResponse, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	if len(req.Queries) == 0 {
+		return nil, fmt.Errorf("batch search needs at least one query")
+	}
+	concurrency := req.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchSearchConcurrency
+	}
+
+	responses := make([]*SearchResponse, len(req.Queries))
+	errs := make([]error, len(req.Queries))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, query := range req.Queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			one := req.Search
+			one.Query = query
+			one.Dedupe = search.DedupeNone
+			responses[i], errs[i] = s.Search(ctx, one)
+		}()
+	}
+	wg.Wait()
+
+	out := &BatchSearchResponse{Groups: make([]search.QueryResults, len(req.Queries))}
+	deduper := search.NewDeduper(req.Dedupe)
+	seenWarnings := make(map[string]bool)
+	for i, query := range req.Queries {
+		group := search.QueryResults{Query: query, Results: []search.Result{}}
+		if errs[i] != nil {
+			group.Error = errs[i].Error()
+			out.Failed++
+			out.Groups[i] = group
+			continue
+		}
+		if kept := deduper.Filter(responses[i].Results); kept != nil {
+			group.Results = kept
+		}
+		for _, w := range responses[i].Warnings {
+			if !seenWarnings[w] {
+				seenWarnings[w] = true
+				out.Warnings = append(out.Warnings, w)
+			}
+		}
+		out.Groups[i] = group
+	}
+	return out, nil
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+const (
+	batchTuningSchemaVersion = 1
+	batchTuningFilename      = "batch_tuning.json"
+)
+
+// BatchTuning is the persisted result of probing the embedding provider for
+// its best batch size and longest accepted input. It applies only while the
+// provider and model it was measured with stay configured.
+type BatchTuning struct {
+	SchemaVersion int       `json:"schema_version"`
+	Provider      string    `json:"provider"`
+	Model         string    `json:"model"`
+	TunedAt       time.Time `json:"tuned_at"`
+	embed.BatchTuning
+}
+
+// BatchTuningPath returns the batch tuning file for a data directory.
+func BatchTuningPath(dataDir string) string {
+	return filepath.Join(dataDir, batchTuningFilename)
+}
+
+// LoadBatchTuning reads the persisted batch tuning. A missing file is
+// (nil, nil).
+func LoadBatchTuning(dataDir string) (*BatchTuning, error) {
+	if strings.TrimSpace(dataDir) == "" {
+		return nil, fmt.Errorf("batch tuning data dir is empty")
+	}
+	data, err := os.ReadFile(BatchTuningPath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read batch tuning: %w", err)
+	}
+	var tuning BatchTuning
+	if err := json.Unmarshal(data, &tuning); err != nil {
+		return nil, fmt.Errorf("decode batch tuning: %w", err)
+	}
+	if tuning.SchemaVersion != batchTuningSchemaVersion {
+		return nil, fmt.Errorf("unsupported batch tuning schema version %d", tuning.SchemaVersion)
+	}
+	return &tuning, nil
+}
+
+// currentBatchTuning returns the persisted tuning when batch sizes are
+// auto-tuned and it was measured with the configured provider and model.
+func currentBatchTuning(cfg *config.Config) *BatchTuning {
+	if cfg.Embedding.BatchSize > 0 || cfg.DataDir == "" {
+		return nil
+	}
+	tuning, err := LoadBatchTuning(cfg.DataDir)
+	if err != nil || tuning == nil {
+		return nil
+	}
+	if tuning.Provider != cfg.Embedding.Provider || tuning.Model != cfg.Embedding.Model || tuning.BatchSize <= 0 {
+		return nil
+	}
+	return tuning
+}
+
+// tuneBatchSize probes the provider before a run that embeds, unless the
+// batch size is fixed or a tuning for this provider and model is already
+// stored. A full reindex always probes again. A failed probe only costs the
+// run its tuning: the indexer falls back to its default batch size.
+func (c *IndexCoordinator) tuneBatchSize(ctx context.Context, force bool) {
+	if c.cfg.Embedding.BatchSize > 0 || c.cfg.DataDir == "" {
+		return
+	}
+	if !force && currentBatchTuning(c.cfg) != nil {
+		return
+	}
+	measured, err := embed.TuneBatch(ctx, embed.Uncached(c.provider), embed.TuneOptions{
+		MaxInputChars: index.DefaultChunkerConfig().MaxChunkChars,
+	})
+	if err != nil {
+		slog.Warn("embedding batch size probe failed, using defaults", "err", err)
+		return
+	}
+	tuning := &BatchTuning{
+		SchemaVersion: batchTuningSchemaVersion,
+		Provider:      c.cfg.Embedding.Provider,
+		Model:         c.cfg.Embedding.Model,
+		TunedAt:       time.Now().UTC(),
+		BatchTuning:   measured,
+	}
+	if err := writeJSONAtomic(c.cfg.DataDir, BatchTuningPath(c.cfg.DataDir), tuning); err != nil {
+		slog.Warn("write batch tuning failed", "err", err)
+		return
+	}
+	slog.Info("tuned embedding batch size", "batch_size", measured.BatchSize,
+		"texts_per_second", int(measured.TextsPerSecond), "max_input_chars", measured.MaxInputChars)
+}
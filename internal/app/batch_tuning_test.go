@@ -0,0 +1,71 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+)
+
+func TestIndexTunesBatchSizeOnce(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session, service := createTestSession(t)
+	session.Provider = fakeProvider{dimensions: session.Config.Embedding.Dimensions, model: session.Config.Embedding.Model}
+	if err := os.WriteFile(filepath.Join(session.ProjectRoot, "a.go"), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := service.Index(ctx, IndexRequest{}, nil); err != nil {
+		t.Fatalf("index failed: %v", err)
+	}
+	first, err := LoadBatchTuning(session.Config.DataDir)
+	if err != nil || first == nil || first.BatchSize <= 0 || first.Model != session.Config.Embedding.Model {
+		t.Fatalf("batch tuning after first run = %+v, %v", first, err)
+	}
+	if got := BuildIndexerConfig(session.Config, nil).BatchSize; got != first.BatchSize {
+		t.Fatalf("indexer batch size = %d, want tuned %d", got, first.BatchSize)
+	}
+
+	if _, err := service.Index(ctx, IndexRequest{}, nil); err != nil {
+		t.Fatalf("second index failed: %v", err)
+	}
+	second, err := LoadBatchTuning(session.Config.DataDir)
+	if err != nil || second == nil || !second.TunedAt.Equal(first.TunedAt) {
+		t.Fatalf("incremental run re-tuned: %+v, %v", second, err)
+	}
+}
+
+func TestBuildIndexerConfigAppliesBatchTuning(t *testing.T) {
+	session, _ := createTestSession(t)
+	cfg := *session.Config
+	tuning := &BatchTuning{
+		SchemaVersion: batchTuningSchemaVersion,
+		Provider:      cfg.Embedding.Provider,
+		Model:         cfg.Embedding.Model,
+		TunedAt:       time.Now().UTC(),
+		BatchTuning:   embed.BatchTuning{BatchSize: 24, MaxInputChars: 1000},
+	}
+	if err := writeJSONAtomic(cfg.DataDir, BatchTuningPath(cfg.DataDir), tuning); err != nil {
+		t.Fatal(err)
+	}
+
+	resolved := BuildIndexerConfig(&cfg, nil)
+	if resolved.BatchSize != 24 || resolved.MaxChunkChars != 1000 {
+		t.Fatalf("tuned config = batch %d, max chunk %d; want 24, 1000", resolved.BatchSize, resolved.MaxChunkChars)
+	}
+
+	cfg.Embedding.BatchSize = 16
+	resolved = BuildIndexerConfig(&cfg, nil)
+	if resolved.BatchSize != 16 || resolved.MaxChunkChars != 0 {
+		t.Fatalf("fixed batch config = batch %d, max chunk %d; want 16, 0", resolved.BatchSize, resolved.MaxChunkChars)
+	}
+
+	cfg.Embedding.BatchSize = 0
+	cfg.Embedding.Model = "another-model"
+	if resolved = BuildIndexerConfig(&cfg, nil); resolved.BatchSize == 24 {
+		t.Fatal("tuning measured with another model was applied")
+	}
+}
@@ -0,0 +1,367 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+const (
+	// DefaultBenchQueries is how many queries 'vecgrep bench' times per
+	// search mode unless overridden.
+	DefaultBenchQueries = 50
+	// defaultBenchLimit is the results requested per timed query.
+	defaultBenchLimit = 10
+)
+
+// BenchOptions controls Service.Bench. Zero values use the project's
+// configuration.
+type BenchOptions struct {
+	// Workers and BatchSize override indexing.workers and
+	// embedding.batch_size for the scratch index.
+	Workers   int
+	BatchSize int
+	// HNSWM and EfConstruction override the vector.veclite build parameters
+	// of the scratch index; EfSearch overrides the search-time one.
+	HNSWM          int
+	EfConstruction int
+	EfSearch       int
+	// Queries caps the timed queries per mode (default DefaultBenchQueries).
+	Queries int
+	// Limit is the results requested per query (default 10).
+	Limit int
+	// Modes are the search modes to time (default keyword, semantic, and
+	// hybrid).
+	Modes []search.SearchMode
+	// SearchOnly times searches against the project's existing index
+	// instead of building a scratch one, so only EfSearch applies.
+	SearchOnly bool
+	// Progress receives indexing progress for the scratch index.
+	Progress index.ProgressCallback
+}
+
+// BenchParams are the effective tuning parameters a benchmark ran with.
+type BenchParams struct {
+	Workers        int `json:"workers,omitempty"`
+	BatchSize      int `json:"batch_size,omitempty"`
+	HNSWM          int `json:"hnsw_m,omitempty"`
+	EfConstruction int `json:"ef_construction,omitempty"`
+	EfSearch       int `json:"ef_search"`
+}
+
+// BenchReport is the result of Service.Bench.
+type BenchReport struct {
+	ProjectRoot string        `json:"project_root"`
+	Provider    string        `json:"provider"`
+	Model       string        `json:"model"`
+	Params      BenchParams   `json:"params"`
+	Index       *IndexBench   `json:"index,omitempty"`
+	Search      []SearchBench `json:"search"`
+}
+
+// IndexBench measures one full index of the project into a scratch
+// database.
+type IndexBench struct {
+	Files        int     `json:"files"`
+	Chunks       int     `json:"chunks"`
+	Errors       int     `json:"errors,omitempty"`
+	DurationMS   float64 `json:"duration_ms"`
+	FilesPerSec  float64 `json:"files_per_sec"`
+	ChunksPerSec float64 `json:"chunks_per_sec"`
+	// Embed is the latency of each embedding request the indexer made.
+	Embed LatencyStats `json:"embed"`
+}
+
+// SearchBench measures the queries of one search mode.
+type SearchBench struct {
+	Mode    search.SearchMode `json:"mode"`
+	Latency LatencyStats      `json:"latency"`
+	// MeanResults is the average number of results per query.
+	MeanResults float64 `json:"mean_results"`
+}
+
+// LatencyStats summarizes a set of timings in milliseconds. Percentiles use
+// the nearest-rank method.
+type LatencyStats struct {
+	Count  int     `json:"count"`
+	MeanMS float64 `json:"mean_ms"`
+	P50MS  float64 `json:"p50_ms"`
+	P90MS  float64 `json:"p90_ms"`
+	P99MS  float64 `json:"p99_ms"`
+	MaxMS  float64 `json:"max_ms"`
+}
+
+// NewLatencyStats summarizes durations; it is zero for none.
+func NewLatencyStats(durations []time.Duration) LatencyStats {
+	if len(durations) == 0 {
+		return LatencyStats{}
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	rank := func(p int) float64 {
+		i := (p*len(sorted)+99)/100 - 1
+		return milliseconds(sorted[max(i, 0)])
+	}
+	return LatencyStats{
+		Count:  len(sorted),
+		MeanMS: milliseconds(total / time.Duration(len(sorted))),
+		P50MS:  rank(50),
+		P90MS:  rank(90),
+		P99MS:  rank(99),
+		MaxMS:  milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// Bench measures indexing throughput and search latency on this project, to
+// compare tuning parameters. Unless opts.SearchOnly is set, it indexes the
+// project from scratch into a temporary VecLite database, which is removed
+// afterwards, and times the searches against it; the project's own index,
+// embedding cache, and remote vector stores are never written. Embeddings
+// bypass the cache, so semantic and hybrid latency include one query
+// embedding each. Queries are symbol names drawn evenly from the index.
+func (s *Service) Bench(ctx context.Context, opts BenchOptions) (*BenchReport, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	if opts.SearchOnly && (opts.Workers > 0 || opts.BatchSize > 0 || opts.HNSWM > 0 || opts.EfConstruction > 0) {
+		return nil, fmt.Errorf("search-only benchmarks use the existing index; only ef_search can be overridden")
+	}
+	if opts.Queries <= 0 {
+		opts.Queries = DefaultBenchQueries
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultBenchLimit
+	}
+	if len(opts.Modes) == 0 {
+		opts.Modes = []search.SearchMode{search.SearchModeKeyword, search.SearchModeSemantic, search.SearchModeHybrid}
+	}
+
+	cfg := *s.session.Config
+	if opts.EfSearch > 0 {
+		cfg.Vector.VecLite.EfSearch = opts.EfSearch
+	}
+	metered, err := newMeteredProvider(&cfg)
+	if err != nil {
+		return nil, fmt.Errorf("create provider: %w", err)
+	}
+	provider := &benchProvider{Provider: metered}
+	defer closeProvider(metered)
+	if err := provider.Ping(ctx); err != nil {
+		return nil, fmt.Errorf("embedding provider unavailable: %w", err)
+	}
+	// Load the model before anything is timed.
+	if _, err := provider.Warmup(ctx); err != nil {
+		return nil, fmt.Errorf("warm up provider: %w", err)
+	}
+
+	report := &BenchReport{
+		ProjectRoot: s.session.ProjectRoot,
+		Provider:    cfg.Embedding.Provider,
+		Model:       cfg.Embedding.Model,
+		Params:      BenchParams{EfSearch: db.DefaultHNSWEfSearch},
+	}
+	if cfg.Vector.VecLite.EfSearch > 0 {
+		report.Params.EfSearch = cfg.Vector.VecLite.EfSearch
+	}
+
+	var database *db.DB
+	if opts.SearchOnly {
+		if err := s.ensureEmbeddingProfileMatches(); err != nil {
+			return nil, err
+		}
+		database, err = db.OpenWithOptions(db.OpenOptions{
+			Dimensions:         cfg.Embedding.Dimensions,
+			DataDir:            cfg.DataDir,
+			HNSWM:              cfg.Vector.VecLite.M,
+			HNSWEfConstruction: cfg.Vector.VecLite.EfConstruction,
+			HNSWEfSearch:       cfg.Vector.VecLite.EfSearch,
+			Quantization:       db.Quantization(cfg.Vector.VecLite.Quantization),
+			Qdrant:             QdrantOptions(&cfg),
+			Pgvector:           PgvectorOptions(&cfg),
+			ProjectRoot:        s.session.ProjectRoot,
+			Encryption:         EncryptionOptions(&cfg),
+			ReadOnly:           true,
+			SharedRead:         true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("open database (read-only): %w", openErrorHint(err))
+		}
+	} else {
+		scratch, err := os.MkdirTemp("", "vecgrep-bench-")
+		if err != nil {
+			return nil, fmt.Errorf("create scratch directory: %w", err)
+		}
+		defer os.RemoveAll(scratch)
+
+		// Resolve a stored batch tuning against the real data directory
+		// before the copy points at the scratch one.
+		if opts.BatchSize > 0 {
+			cfg.Embedding.BatchSize = opts.BatchSize
+		} else if tuning := currentBatchTuning(&cfg); tuning != nil {
+			cfg.Embedding.BatchSize = tuning.BatchSize
+		}
+		if opts.Workers > 0 {
+			cfg.Indexing.Workers = opts.Workers
+		}
+		if opts.HNSWM > 0 {
+			cfg.Vector.VecLite.M = opts.HNSWM
+		}
+		if opts.EfConstruction > 0 {
+			cfg.Vector.VecLite.EfConstruction = opts.EfConstruction
+		}
+		cfg.DataDir = scratch
+		database, err = db.OpenWithOptions(db.OpenOptions{
+			Dimensions:         cfg.Embedding.Dimensions,
+			DataDir:            scratch,
+			HNSWM:              cfg.Vector.VecLite.M,
+			HNSWEfConstruction: cfg.Vector.VecLite.EfConstruction,
+			HNSWEfSearch:       cfg.Vector.VecLite.EfSearch,
+			Quantization:       db.Quantization(cfg.Vector.VecLite.Quantization),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("open scratch database: %w", err)
+		}
+
+		indexer, err := NewConfiguredIndexer(database, provider, &cfg, nil, "")
+		if err != nil {
+			_ = database.Close()
+			return nil, err
+		}
+		if opts.Progress != nil {
+			indexer.SetProgressCallback(opts.Progress)
+		}
+		idxCfg := BuildIndexerConfig(&cfg, nil)
+		report.Params.Workers = idxCfg.Workers
+		report.Params.BatchSize = idxCfg.BatchSize
+		report.Params.HNSWM = db.DefaultHNSWM
+		if cfg.Vector.VecLite.M > 0 {
+			report.Params.HNSWM = cfg.Vector.VecLite.M
+		}
+		report.Params.EfConstruction = db.DefaultHNSWEfConstruction
+		if cfg.Vector.VecLite.EfConstruction > 0 {
+			report.Params.EfConstruction = cfg.Vector.VecLite.EfConstruction
+		}
+
+		result, err := indexer.Index(ctx, s.session.ProjectRoot)
+		if err != nil {
+			_ = database.Close()
+			return nil, fmt.Errorf("index: %w", err)
+		}
+		report.Index = &IndexBench{
+			Files:      result.FilesProcessed,
+			Chunks:     result.ChunksCreated,
+			Errors:     len(result.Errors),
+			DurationMS: milliseconds(result.Duration),
+			Embed:      NewLatencyStats(provider.takeTimings()),
+		}
+		if seconds := result.Duration.Seconds(); seconds > 0 {
+			report.Index.FilesPerSec = float64(result.FilesProcessed) / seconds
+			report.Index.ChunksPerSec = float64(result.ChunksCreated) / seconds
+		}
+	}
+	defer database.Close()
+
+	filter := db.FilterOptions{ProjectRoot: s.session.ProjectRoot}
+	if opts.SearchOnly {
+		filter.ProjectRoots, _ = SearchRoots(s.session.ProjectRoot, &cfg)
+	}
+	records, err := database.FindSymbols("*", 0, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list symbols: %w", err)
+	}
+	queries := calibrationQueries(records, opts.Queries)
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no indexed symbols to draw queries from")
+	}
+
+	searcher := search.NewSearcher(database, provider)
+	for _, mode := range opts.Modes {
+		latencies := make([]time.Duration, 0, len(queries))
+		results := 0
+		for _, query := range queries {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			start := time.Now()
+			found, err := searcher.Search(ctx, query, search.SearchOptions{
+				Limit:        opts.Limit,
+				ProjectRoot:  s.session.ProjectRoot,
+				ProjectRoots: filter.ProjectRoots,
+				Mode:         mode,
+				VectorWeight: cfg.Search.VectorWeight,
+				TextWeight:   cfg.Search.TextWeight,
+				Fusion:       cfg.Search.Fusion,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%s search for %q: %w", mode, query, err)
+			}
+			latencies = append(latencies, time.Since(start))
+			results += len(found)
+		}
+		report.Search = append(report.Search, SearchBench{
+			Mode:        mode,
+			Latency:     NewLatencyStats(latencies),
+			MeanResults: float64(results) / float64(len(queries)),
+		})
+	}
+	return report, nil
+}
+
+// benchProvider records the latency of every batch embedding request, which
+// is how the indexer embeds chunks.
+type benchProvider struct {
+	embed.Provider
+
+	mu      sync.Mutex
+	timings []time.Duration
+}
+
+func (p *benchProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	start := time.Now()
+	vectors, err := p.Provider.EmbedBatch(ctx, texts)
+	if err == nil {
+		p.mu.Lock()
+		p.timings = append(p.timings, time.Since(start))
+		p.mu.Unlock()
+	}
+	return vectors, err
+}
+
+func (p *benchProvider) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	if query, ok := p.Provider.(embed.QueryProvider); ok {
+		return query.EmbedQuery(ctx, text)
+	}
+	return p.Provider.Embed(ctx, text)
+}
+
+// Normalization, Tokenizer, and Usage keep the wrapped chain's optional
+// interfaces visible to the indexer.
+func (p *benchProvider) Normalization() string { return embed.NormalizationOf(p.Provider) }
+
+func (p *benchProvider) Tokenizer() embed.Tokenizer { return embed.TokenizerOf(p.Provider) }
+
+func (p *benchProvider) Usage() *embed.UsageMeter { return embed.UsageOf(p.Provider) }
+
+func (p *benchProvider) takeTimings() []time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	timings := p.timings
+	p.timings = nil
+	return timings
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewLatencyStats(t *testing.T) {
+	durations := make([]time.Duration, 0, 100)
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	got := NewLatencyStats(durations)
+	want := LatencyStats{Count: 100, MeanMS: 50.5, P50MS: 50, P90MS: 90, P99MS: 99, MaxMS: 100}
+	if got != want {
+		t.Fatalf("NewLatencyStats = %+v, want %+v", got, want)
+	}
+
+	single := NewLatencyStats([]time.Duration{3 * time.Millisecond})
+	if single.P50MS != 3 || single.P99MS != 3 || single.MaxMS != 3 {
+		t.Fatalf("single sample stats = %+v", single)
+	}
+	if zero := NewLatencyStats(nil); zero != (LatencyStats{}) {
+		t.Fatalf("empty stats = %+v, want zero", zero)
+	}
+}
@@ -0,0 +1,223 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	bookmarksSchemaVersion = 1
+	bookmarksFilename      = "bookmarks.json"
+)
+
+// Bookmark pins a chunk location with a note and tags so a finding can be
+// recalled later without re-running the query that surfaced it. Bookmarks
+// are keyed by location (file:start-end) rather than chunk ID, because chunk
+// IDs are reassigned when a file is re-indexed.
+type Bookmark struct {
+	ID        uint64    `json:"id"`
+	Key       string    `json:"key"`
+	File      string    `json:"file"`
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Note      string    `json:"note,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BookmarkInput describes a bookmark to add or update.
+type BookmarkInput struct {
+	Key  string
+	Note string
+	Tags []string
+}
+
+type bookmarksFile struct {
+	SchemaVersion int        `json:"schema_version"`
+	NextID        uint64     `json:"next_id"`
+	Bookmarks     []Bookmark `json:"bookmarks"`
+}
+
+// bookmarksMu serializes read-modify-write cycles within one process.
+var bookmarksMu sync.Mutex
+
+// BookmarksPath returns the bookmarks file for a data directory.
+func BookmarksPath(dataDir string) string {
+	return filepath.Join(dataDir, bookmarksFilename)
+}
+
+// ChunkKey formats a chunk location as file:start-end.
+func ChunkKey(file string, startLine, endLine int) string {
+	return fmt.Sprintf("%s:%d-%d", normalizeBookmarkFile(file), startLine, endLine)
+}
+
+// ParseChunkKey parses file:start-end (or file:line for a single line).
+func ParseChunkKey(key string) (file string, startLine, endLine int, err error) {
+	key = strings.TrimSpace(key)
+	sep := strings.LastIndex(key, ":")
+	if sep <= 0 || sep == len(key)-1 {
+		return "", 0, 0, fmt.Errorf("invalid chunk key %q: expected file:start-end", key)
+	}
+	file = normalizeBookmarkFile(key[:sep])
+	lines := key[sep+1:]
+	startStr, endStr, isRange := strings.Cut(lines, "-")
+	if !isRange {
+		endStr = startStr
+	}
+	startLine, startErr := strconv.Atoi(startStr)
+	endLine, endErr := strconv.Atoi(endStr)
+	if startErr != nil || endErr != nil || startLine < 1 || endLine < startLine {
+		return "", 0, 0, fmt.Errorf("invalid chunk key %q: expected file:start-end with 1 <= start <= end", key)
+	}
+	return file, startLine, endLine, nil
+}
+
+func normalizeBookmarkFile(file string) string {
+	return strings.TrimPrefix(path.Clean(filepath.ToSlash(strings.TrimSpace(file))), "./")
+}
+
+// ListBookmarks returns bookmarks carrying all of tags (AND), oldest first.
+func ListBookmarks(dataDir string, tags []string) ([]Bookmark, error) {
+	bookmarksMu.Lock()
+	defer bookmarksMu.Unlock()
+	stored, err := loadBookmarks(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Bookmark, 0, len(stored.Bookmarks))
+	for _, b := range stored.Bookmarks {
+		if hasAllTags(b.Tags, tags) {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+// AddBookmark pins a chunk location. Bookmarking a location that is already
+// pinned updates its note and merges its tags instead of creating a duplicate.
+func AddBookmark(dataDir string, input BookmarkInput) (Bookmark, error) {
+	file, startLine, endLine, err := ParseChunkKey(input.Key)
+	if err != nil {
+		return Bookmark{}, err
+	}
+	key := ChunkKey(file, startLine, endLine)
+
+	bookmarksMu.Lock()
+	defer bookmarksMu.Unlock()
+	stored, err := loadBookmarks(dataDir)
+	if err != nil {
+		return Bookmark{}, err
+	}
+	now := time.Now().UTC()
+	tags := normalizeTags(input.Tags)
+
+	for i := range stored.Bookmarks {
+		b := &stored.Bookmarks[i]
+		if b.Key != key {
+			continue
+		}
+		if input.Note != "" {
+			b.Note = input.Note
+		}
+		b.Tags = normalizeTags(append(b.Tags, tags...))
+		b.UpdatedAt = now
+		if err := writeBookmarks(dataDir, stored); err != nil {
+			return Bookmark{}, err
+		}
+		return *b, nil
+	}
+
+	stored.NextID++
+	b := Bookmark{
+		ID:        stored.NextID,
+		Key:       key,
+		File:      file,
+		StartLine: startLine,
+		EndLine:   endLine,
+		Note:      input.Note,
+		Tags:      tags,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	stored.Bookmarks = append(stored.Bookmarks, b)
+	if err := writeBookmarks(dataDir, stored); err != nil {
+		return Bookmark{}, err
+	}
+	return b, nil
+}
+
+// RemoveBookmark deletes a bookmark by ID. removed is false when no bookmark
+// has that ID.
+func RemoveBookmark(dataDir string, id uint64) (removed bool, err error) {
+	bookmarksMu.Lock()
+	defer bookmarksMu.Unlock()
+	stored, err := loadBookmarks(dataDir)
+	if err != nil {
+		return false, err
+	}
+	before := len(stored.Bookmarks)
+	stored.Bookmarks = slices.DeleteFunc(stored.Bookmarks, func(b Bookmark) bool { return b.ID == id })
+	if len(stored.Bookmarks) == before {
+		return false, nil
+	}
+	return true, writeBookmarks(dataDir, stored)
+}
+
+func loadBookmarks(dataDir string) (*bookmarksFile, error) {
+	if strings.TrimSpace(dataDir) == "" {
+		return nil, fmt.Errorf("bookmarks data dir is empty")
+	}
+	data, err := os.ReadFile(BookmarksPath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return &bookmarksFile{SchemaVersion: bookmarksSchemaVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read bookmarks: %w", err)
+	}
+	var stored bookmarksFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode bookmarks: %w", err)
+	}
+	if stored.SchemaVersion != bookmarksSchemaVersion {
+		return nil, fmt.Errorf("unsupported bookmarks schema version %d", stored.SchemaVersion)
+	}
+	return &stored, nil
+}
+
+func writeBookmarks(dataDir string, stored *bookmarksFile) error {
+	if err := writeJSONAtomic(dataDir, BookmarksPath(dataDir), stored); err != nil {
+		return fmt.Errorf("write bookmarks: %w", err)
+	}
+	return nil
+}
+
+// normalizeTags trims, drops blanks, and de-duplicates tags, keeping order.
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !slices.Contains(out, tag) {
+			out = append(out, tag)
+		}
+	}
+	return out
+}
+
+func hasAllTags(have, want []string) bool {
+	for _, tag := range want {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(have, tag) {
+			return false
+		}
+	}
+	return true
+}
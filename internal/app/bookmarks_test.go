@@ -0,0 +1,67 @@
+package app
+
+import "testing"
+
+func TestParseChunkKey(t *testing.T) {
+	file, start, end, err := ParseChunkKey("./internal/app/search.go:10-24")
+	if err != nil || file != "internal/app/search.go" || start != 10 || end != 24 {
+		t.Fatalf("ParseChunkKey = %q, %d, %d, %v", file, start, end, err)
+	}
+	if _, start, end, err := ParseChunkKey("main.go:7"); err != nil || start != 7 || end != 7 {
+		t.Fatalf("single-line key = %d, %d, %v", start, end, err)
+	}
+	for _, bad := range []string{"", "main.go", "main.go:", ":1-2", "main.go:9-3", "main.go:0-1", "main.go:a-b"} {
+		if _, _, _, err := ParseChunkKey(bad); err == nil {
+			t.Errorf("ParseChunkKey(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBookmarksAddListRemove(t *testing.T) {
+	dataDir := t.TempDir()
+
+	if got, err := ListBookmarks(dataDir, nil); err != nil || len(got) != 0 {
+		t.Fatalf("empty store = %v, %v", got, err)
+	}
+	first, err := AddBookmark(dataDir, BookmarkInput{Key: "search.go:10-20", Note: "ranking", Tags: []string{"perf", " "}})
+	if err != nil {
+		t.Fatalf("AddBookmark: %v", err)
+	}
+	second, err := AddBookmark(dataDir, BookmarkInput{Key: "index.go:1-5", Tags: []string{"perf", "io"}})
+	if err != nil {
+		t.Fatalf("AddBookmark: %v", err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("IDs collide: %d", first.ID)
+	}
+
+	// Re-pinning the same location updates it in place.
+	again, err := AddBookmark(dataDir, BookmarkInput{Key: "./search.go:10-20", Tags: []string{"hot"}})
+	if err != nil {
+		t.Fatalf("re-add: %v", err)
+	}
+	if again.ID != first.ID || again.Note != "ranking" || len(again.Tags) != 2 {
+		t.Fatalf("re-add = %+v, want merged update of %d", again, first.ID)
+	}
+
+	if got, _ := ListBookmarks(dataDir, nil); len(got) != 2 {
+		t.Fatalf("list all = %d bookmarks, want 2", len(got))
+	}
+	if got, _ := ListBookmarks(dataDir, []string{"perf", "io"}); len(got) != 1 || got[0].ID != second.ID {
+		t.Fatalf("tag AND filter = %+v", got)
+	}
+
+	if removed, err := RemoveBookmark(dataDir, first.ID); err != nil || !removed {
+		t.Fatalf("RemoveBookmark = %v, %v", removed, err)
+	}
+	if removed, err := RemoveBookmark(dataDir, first.ID); err != nil || removed {
+		t.Fatalf("second RemoveBookmark = %v, %v; want false", removed, err)
+	}
+	third, err := AddBookmark(dataDir, BookmarkInput{Key: "other.go:3-4"})
+	if err != nil {
+		t.Fatalf("AddBookmark: %v", err)
+	}
+	if third.ID <= second.ID {
+		t.Fatalf("IDs must not be reused: got %d after %d", third.ID, second.ID)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
@@ -194,7 +194,7 @@ func BranchSwitch(ctx context.Context, projectRoot, projectName, targetBranch st
 					// Restore the snapshot
 					if restoreErr := f.Restore(ctx, entry.StashID, branchDir); restoreErr == nil {
 						restored = true
-						log.Printf("restored branch index from fcheap stash %s, skipping reindex", entry.StashID)
+						slog.Info("restored branch index from fcheap, skipping reindex", "stash", entry.StashID)
 					}
 				}
 			}
@@ -6,7 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -68,7 +68,7 @@ func stashEmbeddingCache(ctx context.Context, provider embed.Provider, projectPa
 
 	// Flush pending async writes so the stashed file is consistent.
 	if err := dc.FlushToDisk(); err != nil {
-		log.Printf("embedding cache stash: flush failed: %v", err)
+		slog.Warn("embedding cache stash: flush failed", "err", err)
 		return
 	}
 
@@ -91,11 +91,11 @@ func stashEmbeddingCache(ctx context.Context, provider embed.Provider, projectPa
 	name := "vecgrep-embed-cache-" + repoHash + "-" + modelName
 	result, err := f.SaveFile(ctx, cachePath, name, "vecgrep", tags)
 	if err != nil {
-		log.Printf("embedding cache stash: fcheap save failed: %v", err)
+		slog.Warn("embedding cache stash: fcheap save failed", "err", err)
 		return
 	}
 	if result != nil {
-		log.Printf("embedding cache stash: saved to fcheap stash %s", result.StashID)
+		slog.Info("embedding cache stashed in fcheap", "stash", result.StashID)
 	}
 }
 
@@ -123,7 +123,7 @@ func restoreEmbeddingCache(ctx context.Context, projectPath, modelName string) s
 
 	latest, err := f.LatestByTags(ctx, tags)
 	if err != nil {
-		log.Printf("embedding cache restore: fcheap list failed: %v", err)
+		slog.Warn("embedding cache restore: fcheap list failed", "err", err)
 		return ""
 	}
 	if latest == nil {
@@ -133,12 +133,12 @@ func restoreEmbeddingCache(ctx context.Context, projectPath, modelName string) s
 	// Restore into a temp directory, then return the restored file path.
 	tmpDir, err := os.MkdirTemp("", "vecgrep-embed-cache-restore-*")
 	if err != nil {
-		log.Printf("embedding cache restore: create temp dir failed: %v", err)
+		slog.Warn("embedding cache restore: create temp dir failed", "err", err)
 		return ""
 	}
 
 	if err := f.Restore(ctx, latest.StashID, tmpDir); err != nil {
-		log.Printf("embedding cache restore: fcheap restore failed: %v", err)
+		slog.Warn("embedding cache restore: fcheap restore failed", "err", err)
 		_ = os.RemoveAll(tmpDir)
 		return ""
 	}
@@ -150,7 +150,7 @@ func restoreEmbeddingCache(ctx context.Context, projectPath, modelName string) s
 		return ""
 	}
 	restoredPath := filepath.Join(tmpDir, entries[0].Name())
-	log.Printf("restored embedding cache from fcheap stash %s", latest.StashID)
+	slog.Info("restored embedding cache from fcheap", "stash", latest.StashID)
 	return restoredPath
 }
 
@@ -163,7 +163,7 @@ func loadRestoredCacheIntoProvider(provider embed.Provider, restoredPath string)
 	}
 	defer func() {
 		if rerr := os.RemoveAll(filepath.Dir(restoredPath)); rerr != nil {
-			log.Printf("embedding cache restore: temp cleanup failed: %v", rerr)
+			slog.Warn("embedding cache restore: temp cleanup failed", "err", rerr)
 		}
 	}()
 
@@ -173,10 +173,10 @@ func loadRestoredCacheIntoProvider(provider embed.Provider, restoredPath string)
 	}
 
 	if err := dc.MergeFromDisk(restoredPath); err != nil {
-		log.Printf("embedding cache restore: merge from %s failed: %v", restoredPath, err)
+		slog.Warn("embedding cache restore: merge failed", "path", restoredPath, "err", err)
 		return
 	}
-	log.Printf("embedding cache restore: merged %s into live cache", restoredPath)
+	slog.Info("embedding cache restore: merged into live cache", "path", restoredPath)
 }
 
 // PruneBranchIndexes runs fcheap cleanup-smart to prune branch index
@@ -299,7 +299,7 @@ func restoreBranchIndex(ctx context.Context, targetDir, repoHash, sanitizedBranc
 	if err := f.Restore(ctx, latest.StashID, targetDir); err != nil {
 		return "", fmt.Errorf("fcheap restore branch index: %w", err)
 	}
-	log.Printf("restored branch index from fcheap stash %s, skipping reindex", latest.StashID)
+	slog.Info("restored branch index from fcheap, skipping reindex", "stash", latest.StashID)
 	return latest.StashID, nil
 }
 
@@ -323,7 +323,7 @@ func (s *Service) StashEmbeddingCacheManual(ctx context.Context) bool {
 		return false
 	}
 	if err := dc.FlushToDisk(); err != nil {
-		log.Printf("embedding cache stash: flush failed: %v", err)
+		slog.Warn("embedding cache stash: flush failed", "err", err)
 		return false
 	}
 	cachePath := ResolvedCachePath(s.session.Config)
@@ -342,11 +342,11 @@ func (s *Service) StashEmbeddingCacheManual(ctx context.Context) bool {
 	name := "vecgrep-embed-cache-" + repoHash + "-" + modelName
 	result, err := f.SaveFile(ctx, cachePath, name, "vecgrep", tags)
 	if err != nil {
-		log.Printf("embedding cache stash: fcheap save failed: %v", err)
+		slog.Warn("embedding cache stash: fcheap save failed", "err", err)
 		return false
 	}
 	if result != nil {
-		log.Printf("embedding cache stash: saved to fcheap stash %s", result.StashID)
+		slog.Info("embedding cache stashed in fcheap", "stash", result.StashID)
 		return true
 	}
 	return false
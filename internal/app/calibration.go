@@ -0,0 +1,214 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+const (
+	scoreCalibrationSchemaVersion = 1
+	scoreCalibrationFilename      = "score_calibration.json"
+
+	// DefaultCalibrationQueries is how many sample queries 'vecgrep
+	// calibrate' runs per mode unless overridden.
+	DefaultCalibrationQueries = 50
+	// calibrationResultsPerQuery is how many results each sample query
+	// contributes to a mode's score distribution.
+	calibrationResultsPerQuery = 20
+)
+
+// ScoreCalibration is the persisted score calibration of an index. It
+// applies only while the provider and model it was measured with stay
+// configured, since semantic and hybrid scores depend on the model.
+type ScoreCalibration struct {
+	SchemaVersion int       `json:"schema_version"`
+	Provider      string    `json:"provider"`
+	Model         string    `json:"model"`
+	CalibratedAt  time.Time `json:"calibrated_at"`
+	Queries       int       `json:"queries"`
+	// Samples counts the scores each curve was built from.
+	Samples map[string]int `json:"samples"`
+	search.ScoreCalibration
+}
+
+// CalibrateOptions controls Service.CalibrateScores.
+type CalibrateOptions struct {
+	// Queries caps the sample queries run per mode (default
+	// DefaultCalibrationQueries).
+	Queries int
+}
+
+// ScoreCalibrationPath returns the score calibration file for a data
+// directory.
+func ScoreCalibrationPath(dataDir string) string {
+	return filepath.Join(dataDir, scoreCalibrationFilename)
+}
+
+// LoadScoreCalibration reads the persisted score calibration. A missing file
+// is (nil, nil).
+func LoadScoreCalibration(dataDir string) (*ScoreCalibration, error) {
+	if strings.TrimSpace(dataDir) == "" {
+		return nil, fmt.Errorf("score calibration data dir is empty")
+	}
+	data, err := os.ReadFile(ScoreCalibrationPath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read score calibration: %w", err)
+	}
+	var calibration ScoreCalibration
+	if err := json.Unmarshal(data, &calibration); err != nil {
+		return nil, fmt.Errorf("decode score calibration: %w", err)
+	}
+	if calibration.SchemaVersion != scoreCalibrationSchemaVersion {
+		return nil, fmt.Errorf("unsupported score calibration schema version %d", calibration.SchemaVersion)
+	}
+	return &calibration, nil
+}
+
+// ResetScoreCalibration removes the persisted score calibration so searches
+// report raw scores again. A missing file is not an error.
+func ResetScoreCalibration(dataDir string) error {
+	if err := os.Remove(ScoreCalibrationPath(dataDir)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove score calibration: %w", err)
+	}
+	return nil
+}
+
+// CurrentScoreCalibration returns the persisted calibration when it was
+// measured with the configured provider and model.
+func CurrentScoreCalibration(cfg *config.Config) *search.ScoreCalibration {
+	if cfg.DataDir == "" {
+		return nil
+	}
+	calibration, err := LoadScoreCalibration(cfg.DataDir)
+	if err != nil || calibration == nil {
+		return nil
+	}
+	if calibration.Provider != cfg.Embedding.Provider || calibration.Model != cfg.Embedding.Model {
+		return nil
+	}
+	return &calibration.ScoreCalibration
+}
+
+// CalibrateScores samples the score distribution of each search mode and
+// stores it, so later searches report calibrated scores and --min-score
+// keeps a comparable share of results in every mode. Sample queries are
+// symbol names drawn evenly from the index; keyword search always runs, and
+// semantic and hybrid search run when an embedding provider is configured.
+// Raw scores are sampled, so calibrating again replaces the curves rather
+// than compounding them.
+func (s *Service) CalibrateScores(ctx context.Context, opts CalibrateOptions) (*ScoreCalibration, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	if opts.Queries <= 0 {
+		opts.Queries = DefaultCalibrationQueries
+	}
+
+	filter := db.FilterOptions{ProjectRoot: s.session.ProjectRoot}
+	filter.ProjectRoots, _ = SearchRoots(s.session.ProjectRoot, s.session.Config)
+	records, err := s.session.DB.FindSymbols("*", 0, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list symbols: %w", err)
+	}
+	queries := calibrationQueries(records, opts.Queries)
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("no indexed symbols to sample queries from; index the project first")
+	}
+
+	modes := []search.SearchMode{search.SearchModeKeyword}
+	if s.session.Provider != nil {
+		if err := s.ensureEmbeddingProfileMatches(); err != nil {
+			return nil, err
+		}
+		modes = append(modes, search.SearchModeSemantic, search.SearchModeHybrid)
+	}
+
+	searcher := search.NewSearcher(s.session.DB, s.session.Provider)
+	fusion := s.session.Config.Search.Fusion
+	samples := make(map[string][]float32, len(modes))
+	for _, mode := range modes {
+		key := search.CalibrationKey(mode, fusion)
+		for _, query := range queries {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			results, err := searcher.Search(ctx, query, search.SearchOptions{
+				Limit:        calibrationResultsPerQuery,
+				ProjectRoot:  s.session.ProjectRoot,
+				ProjectRoots: filter.ProjectRoots,
+				Mode:         mode,
+				VectorWeight: s.session.Config.Search.VectorWeight,
+				TextWeight:   s.session.Config.Search.TextWeight,
+				Fusion:       fusion,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("%s search for %q: %w", mode, query, err)
+			}
+			for _, r := range results {
+				samples[key] = append(samples[key], r.Score)
+			}
+		}
+	}
+
+	calibration := &ScoreCalibration{
+		SchemaVersion:    scoreCalibrationSchemaVersion,
+		Provider:         s.session.Config.Embedding.Provider,
+		Model:            s.session.Config.Embedding.Model,
+		CalibratedAt:     time.Now().UTC(),
+		Queries:          len(queries),
+		Samples:          make(map[string]int, len(samples)),
+		ScoreCalibration: *search.NewScoreCalibration(samples),
+	}
+	for key, scores := range samples {
+		if calibration.Has(key) {
+			calibration.Samples[key] = len(scores)
+		}
+	}
+	if len(calibration.Curves) == 0 {
+		return nil, fmt.Errorf("too few results to calibrate (need %d scores per mode); index more code or raise --queries", search.MinCalibrationSamples)
+	}
+	dataDir := s.session.Config.DataDir
+	if err := writeJSONAtomic(dataDir, ScoreCalibrationPath(dataDir), calibration); err != nil {
+		return nil, fmt.Errorf("write score calibration: %w", err)
+	}
+	return calibration, nil
+}
+
+// calibrationQueries picks up to n distinct symbol names spread evenly over
+// the sorted names, so the sample covers the whole project rather than the
+// first package alphabetically.
+func calibrationQueries(records []db.SymbolRecord, n int) []string {
+	seen := make(map[string]bool, len(records))
+	names := make([]string, 0, len(records))
+	for _, r := range records {
+		name := strings.TrimSpace(r.Name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) <= n {
+		return names
+	}
+	picked := make([]string, n)
+	for i := range picked {
+		picked[i] = names[i*len(names)/n]
+	}
+	return picked
+}
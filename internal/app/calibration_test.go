@@ -0,0 +1,72 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+func TestCalibrationQueriesSpreadOverSymbols(t *testing.T) {
+	var records []db.SymbolRecord
+	for i := range 100 {
+		name := fmt.Sprintf("Sym%03d", i)
+		records = append(records, db.SymbolRecord{Name: name}, db.SymbolRecord{Name: name})
+	}
+	records = append(records, db.SymbolRecord{Name: "  "})
+
+	got := calibrationQueries(records, 4)
+	want := []string{"Sym000", "Sym025", "Sym050", "Sym075"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("queries = %v, want %v", got, want)
+	}
+	if got := calibrationQueries(records[:6], 10); len(got) != 3 {
+		t.Fatalf("queries = %v, want the 3 distinct names", got)
+	}
+}
+
+func TestScoreCalibrationAppliesOnlyToItsModel(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.DefaultConfig()
+	cfg.DataDir = dir
+	if CurrentScoreCalibration(cfg) != nil {
+		t.Fatal("calibration reported before one was written")
+	}
+
+	samples := make([]float32, search.MinCalibrationSamples)
+	for i := range samples {
+		samples[i] = float32(i) / float32(len(samples))
+	}
+	stored := &ScoreCalibration{
+		SchemaVersion:    scoreCalibrationSchemaVersion,
+		Provider:         cfg.Embedding.Provider,
+		Model:            cfg.Embedding.Model,
+		CalibratedAt:     time.Now().UTC(),
+		ScoreCalibration: *search.NewScoreCalibration(map[string][]float32{"keyword": samples}),
+	}
+	if err := writeJSONAtomic(dir, ScoreCalibrationPath(dir), stored); err != nil {
+		t.Fatal(err)
+	}
+	if c := CurrentScoreCalibration(cfg); !c.Has("keyword") {
+		t.Fatalf("calibration = %+v, want the keyword curve", c)
+	}
+
+	other := *cfg
+	other.Embedding.Model = "another-model"
+	if CurrentScoreCalibration(&other) != nil {
+		t.Fatal("calibration applied to a different model")
+	}
+
+	if err := ResetScoreCalibration(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := ResetScoreCalibration(dir); err != nil {
+		t.Fatalf("second reset: %v", err)
+	}
+	if CurrentScoreCalibration(cfg) != nil {
+		t.Fatal("calibration survived reset")
+	}
+}
@@ -0,0 +1,367 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+// DefaultDeadCodeLimit caps 'vecgrep deadcode' output unless overridden.
+const DefaultDeadCodeLimit = 50
+
+// DeadCodeOptions controls DeadCode.
+type DeadCodeOptions struct {
+	Languages []string
+	// IncludeTests also reports symbols defined in test files, which test
+	// runners usually call by name.
+	IncludeTests bool
+	Limit        int
+}
+
+// DeadCodeCandidate is an exported symbol that no other file appears to use.
+// Confidence is in 0-1 and drops with every sign that the symbol may be live
+// after all; Reasons lists those signs, for display.
+type DeadCodeCandidate struct {
+	SymbolMatch
+	Lines      int      `json:"lines"`
+	Confidence float64  `json:"confidence"`
+	Reasons    []string `json:"reasons"`
+}
+
+// DeadCodeReport is the result of DeadCode. Total counts every candidate
+// found; Candidates holds the best of them up to the limit.
+type DeadCodeReport struct {
+	Files      int                 `json:"files"`
+	Exported   int                 `json:"exported"`
+	Total      int                 `json:"total"`
+	Candidates []DeadCodeCandidate `json:"candidates"`
+}
+
+// deadCodeFile is one indexed file as DeadCode sees it: its source put back
+// together from its chunks and the named definitions chunked from it.
+type deadCodeFile struct {
+	path    string
+	content string
+	symbols []deadCodeSymbol
+}
+
+// deadCodeSymbol is a named definition and the first line of its chunk,
+// which carries the export keyword in languages that have one.
+type deadCodeSymbol struct {
+	SymbolMatch
+	declaration string
+}
+
+// DeadCode lists exported symbols of the project that appear in no other
+// indexed file, best candidates first. It reads only the index, so no
+// embedding provider is needed. A symbol is used when another file mentions
+// its name; for Go, the mention only counts from the symbol's own package or
+// a file importing it, using the same import parsing as related files. Go,
+// JavaScript/TypeScript, Python, and Rust symbols are checked, since those
+// are the languages whose exports can be told apart. Names reached only
+// through reflection, string lookups, or code outside the index are reported
+// too, so the result is a list of candidates to review, not to delete.
+func (s *Service) DeadCode(ctx context.Context, opts DeadCodeOptions) (*DeadCodeReport, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultDeadCodeLimit
+	}
+
+	root := s.session.ProjectRoot
+	infos, err := s.session.DB.ListFiles(root)
+	if err != nil {
+		return nil, fmt.Errorf("list indexed files: %w", err)
+	}
+	files := make([]deadCodeFile, 0, len(infos))
+	for _, info := range infos {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunks, err := s.session.DB.GetChunksByFile(info.RelativePath)
+		if err != nil {
+			return nil, fmt.Errorf("read chunks for %s: %w", info.RelativePath, err)
+		}
+		chunks = slices.DeleteFunc(chunks, func(c db.ChunkRecord) bool { return c.ProjectRoot != root })
+		files = append(files, deadCodeFileFromChunks(info.RelativePath, chunks, opts.Languages))
+	}
+
+	candidates, exported := rankDeadCode(files, opts.IncludeTests)
+	report := &DeadCodeReport{Files: len(files), Exported: exported, Total: len(candidates), Candidates: candidates}
+	if len(report.Candidates) > opts.Limit {
+		report.Candidates = report.Candidates[:opts.Limit]
+	}
+	return report, nil
+}
+
+// deadCodeFileFromChunks rebuilds a file's source from its chunks, skipping
+// the bytes that overlapping chunks (doc comments, split pieces) repeat, and
+// collects its named definitions in the requested languages. Code embedded
+// in another language, such as examples in Markdown, defines nothing.
+func deadCodeFileFromChunks(relPath string, chunks []db.ChunkRecord, languages []string) deadCodeFile {
+	sort.SliceStable(chunks, func(i, j int) bool { return chunks[i].StartByte < chunks[j].StartByte })
+	file := deadCodeFile{path: relPath}
+	fileLang := string(index.DetectLanguage(relPath))
+	var content strings.Builder
+	end := 0
+	seen := make(map[string]bool)
+	for _, chunk := range chunks {
+		if skip := end - chunk.StartByte; skip < len(chunk.Content) {
+			content.WriteString(chunk.Content[max(skip, 0):])
+			end = max(end, chunk.StartByte+len(chunk.Content))
+		}
+
+		switch chunk.ChunkType {
+		case "function", "class", "interface", "const":
+		default:
+			continue
+		}
+		if chunk.SymbolName == "" || chunk.Language != fileLang || seen[chunk.SymbolName] {
+			continue
+		}
+		if len(languages) > 0 && !slices.ContainsFunc(languages, func(l string) bool { return strings.EqualFold(l, chunk.Language) }) {
+			continue
+		}
+		seen[chunk.SymbolName] = true
+		declaration, _, _ := strings.Cut(strings.TrimSpace(chunk.Content), "\n")
+		file.symbols = append(file.symbols, deadCodeSymbol{
+			SymbolMatch: SymbolMatch{
+				Name:         chunk.SymbolName,
+				ChunkType:    chunk.ChunkType,
+				Language:     chunk.Language,
+				RelativePath: relPath,
+				StartLine:    chunk.StartLine,
+				EndLine:      chunk.EndLine,
+				ChunkID:      int64(chunk.ID),
+			},
+			declaration: declaration,
+		})
+	}
+	file.content = content.String()
+	return file
+}
+
+// rankDeadCode finds the exported symbols of files that no other file uses
+// and sorts them best first. It also returns how many exported symbols were
+// checked.
+func rankDeadCode(files []deadCodeFile, includeTests bool) ([]DeadCodeCandidate, int) {
+	idents := make([]map[string]int, len(files))
+	for i, file := range files {
+		idents[i] = identifierCounts(file.content)
+	}
+	importers := resolveImporters(files)
+
+	var candidates []DeadCodeCandidate
+	exported := 0
+	for a, file := range files {
+		if !includeTests && isTestPath(file.path) {
+			continue
+		}
+		for _, symbol := range file.symbols {
+			name := symbol.Name
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				name = name[i+1:]
+			}
+			if !isExportedSymbol(symbol.Language, name, symbol.declaration) {
+				continue
+			}
+			exported++
+
+			isGo := symbol.Language == string(index.LangGo)
+			var tests, unrelated int
+			used := false
+			for b := range files {
+				if b == a || idents[b][name] == 0 {
+					continue
+				}
+				// Go code outside the package must import it to name it.
+				if isGo && path.Dir(files[b].path) != path.Dir(file.path) && !importers[a][b] {
+					unrelated++
+					continue
+				}
+				if isTestPath(files[b].path) {
+					tests++
+					continue
+				}
+				used = true
+				break
+			}
+			if used {
+				continue
+			}
+
+			c := DeadCodeCandidate{SymbolMatch: symbol.SymbolMatch, Lines: symbol.EndLine - symbol.StartLine + 1, Confidence: 1}
+			if tests > 0 {
+				c.Confidence *= 0.5
+				c.Reasons = append(c.Reasons, fmt.Sprintf("used only by %d test file(s)", tests))
+			} else {
+				c.Reasons = append(c.Reasons, "no other file uses the name")
+			}
+			if unrelated > 0 {
+				c.Confidence *= 0.7
+				c.Reasons = append(c.Reasons, fmt.Sprintf("name appears in %d file(s) outside its package that do not import it", unrelated))
+			}
+			if idents[a][name] > 1 {
+				c.Confidence *= 0.8
+				c.Reasons = append(c.Reasons, "used within its own file; could be unexported")
+			}
+			switch {
+			case !resolvesImports(symbol.Language):
+				c.Confidence *= 0.8
+				c.Reasons = append(c.Reasons, "imports are not resolved for "+symbol.Language)
+			case len(importers[a]) == 0:
+				c.Reasons = append(c.Reasons, "no file imports it")
+			default:
+				c.Confidence *= 0.9
+				c.Reasons = append(c.Reasons, fmt.Sprintf("%d file(s) import it", len(importers[a])))
+			}
+			candidates = append(candidates, c)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Confidence != candidates[j].Confidence {
+			return candidates[i].Confidence > candidates[j].Confidence
+		}
+		if candidates[i].Lines != candidates[j].Lines {
+			return candidates[i].Lines > candidates[j].Lines
+		}
+		if candidates[i].RelativePath != candidates[j].RelativePath {
+			return candidates[i].RelativePath < candidates[j].RelativePath
+		}
+		return candidates[i].StartLine < candidates[j].StartLine
+	})
+	return candidates, exported
+}
+
+// isExportedSymbol reports whether a definition is visible outside its file
+// or package, by the rules of its language. Languages without a recognized
+// export rule report false.
+func isExportedSymbol(language, name, declaration string) bool {
+	switch index.Language(language) {
+	case index.LangGo:
+		r, _ := utf8.DecodeRuneInString(name)
+		return unicode.IsUpper(r)
+	case index.LangPython:
+		return name != "" && !strings.HasPrefix(name, "_")
+	case index.LangJavaScript, index.LangTypeScript:
+		return strings.HasPrefix(declaration, "export ") && name != "default"
+	case index.LangRust:
+		return strings.HasPrefix(declaration, "pub ") || strings.HasPrefix(declaration, "pub(")
+	}
+	return false
+}
+
+// resolvesImports reports whether resolveImporters understands the imports
+// of language.
+func resolvesImports(language string) bool {
+	switch index.Language(language) {
+	case index.LangGo, index.LangPython, index.LangJavaScript, index.LangTypeScript:
+		return true
+	}
+	return false
+}
+
+// resolveImporters maps each file to the files that import it. Go imports
+// name a package, so they reach every file in its directory; import paths
+// are matched by suffix since the module path is not known. Relative
+// JavaScript/TypeScript imports resolve against the importing file, with or
+// without an extension or an index file, and Python modules match by dotted
+// path suffix.
+func resolveImporters(files []deadCodeFile) []map[int]bool {
+	importers := make([]map[int]bool, len(files))
+	byStem := make(map[string][]int)
+	byModule := make(map[string][]int) // every path suffix of a Python module
+	byPackage := make(map[string][]int)
+	for i, file := range files {
+		importers[i] = make(map[int]bool)
+		ext := path.Ext(file.path)
+		stem := strings.TrimSuffix(file.path, ext)
+		byStem[stem] = append(byStem[stem], i)
+		switch ext {
+		case ".go":
+			byPackage[path.Dir(file.path)] = append(byPackage[path.Dir(file.path)], i)
+		case ".py":
+			for _, suffix := range pathSuffixes(strings.TrimSuffix(stem, "/__init__")) {
+				byModule[suffix] = append(byModule[suffix], i)
+			}
+		}
+	}
+	mark := func(targets []int, importer int) {
+		for _, t := range targets {
+			if t != importer {
+				importers[t][importer] = true
+			}
+		}
+	}
+
+	for b, file := range files {
+		ext := strings.ToLower(path.Ext(file.path))
+		for _, spec := range index.ParseImports(file.content, ext) {
+			switch ext {
+			case ".go":
+				for _, suffix := range pathSuffixes(spec) {
+					mark(byPackage[suffix], b)
+				}
+			case ".js", ".ts", ".jsx", ".tsx":
+				if !strings.HasPrefix(spec, ".") {
+					continue
+				}
+				target := path.Join(path.Dir(file.path), spec)
+				target = strings.TrimSuffix(target, path.Ext(target))
+				mark(byStem[target], b)
+				mark(byStem[target+"/index"], b)
+			case ".py":
+				if module := strings.ReplaceAll(strings.TrimLeft(spec, "."), ".", "/"); module != "" {
+					mark(byModule[module], b)
+				}
+			}
+		}
+	}
+	return importers
+}
+
+// pathSuffixes returns p and each shorter path formed by dropping its
+// leading segments: "a/b/c", "b/c", "c".
+func pathSuffixes(p string) []string {
+	suffixes := []string{p}
+	for {
+		_, rest, ok := strings.Cut(p, "/")
+		if !ok || rest == "" {
+			return suffixes
+		}
+		suffixes = append(suffixes, rest)
+		p = rest
+	}
+}
+
+// identifierCounts counts the identifier-like words of content.
+func identifierCounts(content string) map[string]int {
+	counts := make(map[string]int)
+	start := -1
+	for i := 0; i <= len(content); i++ {
+		if i < len(content) {
+			c := content[i]
+			if c == '_' || c == '$' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+				if start < 0 {
+					start = i
+				}
+				continue
+			}
+		}
+		if start >= 0 {
+			counts[content[start:i]]++
+			start = -1
+		}
+	}
+	return counts
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRankDeadCode(t *testing.T) {
+	symbol := func(file, name, language, declaration string, start, end int) deadCodeSymbol {
+		return deadCodeSymbol{
+			SymbolMatch: SymbolMatch{Name: name, ChunkType: "function", Language: language, RelativePath: file, StartLine: start, EndLine: end},
+			declaration: declaration,
+		}
+	}
+	files := []deadCodeFile{
+		{
+			path:    "internal/store/store.go",
+			content: "package store\n\nfunc Open() {}\n\nfunc Legacy() {}\n\nfunc Helper() {}\n\nfunc Used() { Helper() }\n\nfunc private() {}\n",
+			symbols: []deadCodeSymbol{
+				symbol("internal/store/store.go", "Open", "go", "func Open() {}", 3, 3),
+				symbol("internal/store/store.go", "Legacy", "go", "func Legacy() {}", 5, 5),
+				symbol("internal/store/store.go", "Helper", "go", "func Helper() {}", 7, 7),
+				symbol("internal/store/store.go", "Used", "go", "func Used() { Helper() }", 9, 9),
+				symbol("internal/store/store.go", "private", "go", "func private() {}", 11, 11),
+			},
+		},
+		{
+			path:    "internal/store/store_test.go",
+			content: "package store\n\nfunc TestOpen() { Open() }\n",
+		},
+		{
+			path:    "cmd/app/main.go",
+			content: "package main\n\nimport \"example.com/app/internal/store\"\n\nfunc main() { store.Used() }\n",
+		},
+		{
+			path:    "internal/other/other.go",
+			content: "package other\n\n// Legacy mirrors the old store API.\nfunc wrap() {}\n",
+		},
+		{
+			path:    "web/format.ts",
+			content: "export function formatDate() {}\nexport function unusedFormat() {}\nfunction local() {}\n",
+			symbols: []deadCodeSymbol{
+				symbol("web/format.ts", "formatDate", "typescript", "export function formatDate() {}", 1, 1),
+				symbol("web/format.ts", "unusedFormat", "typescript", "export function unusedFormat() {}", 2, 2),
+				symbol("web/format.ts", "local", "typescript", "function local() {}", 3, 3),
+			},
+		},
+		{
+			path:    "web/page.ts",
+			content: "import { formatDate } from \"./format\";\n",
+		},
+	}
+
+	candidates, exported := rankDeadCode(files, false)
+	if exported != 6 {
+		t.Errorf("exported = %d, want 6 (private and local are not exported)", exported)
+	}
+	got := make(map[string]DeadCodeCandidate)
+	var names []string
+	for _, c := range candidates {
+		got[c.Name] = c
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "unusedFormat,Helper,Legacy,Open" {
+		t.Fatalf("candidates = %v, want unusedFormat, Helper, Legacy, Open", names)
+	}
+	if c := got["unusedFormat"]; c.Confidence < 0.89 || !strings.Contains(strings.Join(c.Reasons, ";"), "1 file(s) import it") {
+		t.Errorf("unusedFormat = %+v, want a high-confidence candidate in an imported file", c)
+	}
+	if c := got["Legacy"]; !strings.Contains(strings.Join(c.Reasons, ";"), "outside its package") {
+		t.Errorf("Legacy reasons = %v, want the unrelated mention in internal/other", c.Reasons)
+	}
+	if c := got["Helper"]; !strings.Contains(strings.Join(c.Reasons, ";"), "could be unexported") {
+		t.Errorf("Helper reasons = %v, want its use inside store.go", c.Reasons)
+	}
+	if c := got["Open"]; c.Confidence > 0.5 || !strings.Contains(strings.Join(c.Reasons, ";"), "test file") {
+		t.Errorf("Open = %+v, want a low-confidence candidate used only by tests", c)
+	}
+}
+
+func TestResolveImportersPython(t *testing.T) {
+	files := []deadCodeFile{
+		{path: "app/models/user.py"},
+		{path: "app/views.py", content: "from app.models.user import User\n"},
+		{path: "scripts/seed.py", content: "import models.user\n"},
+		{path: "app/unrelated.py", content: "import os\n"},
+	}
+	importers := resolveImporters(files)
+	if len(importers[0]) != 2 || !importers[0][1] || !importers[0][2] {
+		t.Fatalf("importers of user.py = %v, want views.py and seed.py", importers[0])
+	}
+}
+
+func TestDeadCodeRequiresSession(t *testing.T) {
+	if _, err := (&Service{}).DeadCode(context.Background(), DeadCodeOptions{}); err == nil {
+		t.Error("DeadCode on an uninitialized service should fail")
+	}
+}
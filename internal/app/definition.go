@@ -0,0 +1,245 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+// DefaultDefinitionLimit caps 'vecgrep def' output unless overridden.
+const DefaultDefinitionLimit = 5
+
+// DefinitionRequest asks for the likely definition sites of a symbol.
+type DefinitionRequest struct {
+	Symbol string
+	// From is the project-relative file the symbol was seen in. Candidates
+	// closer to it in the directory tree rank higher; empty disables the
+	// proximity signal.
+	From  string
+	Limit int
+}
+
+// DefinitionMatch is one candidate definition site. Confidence is in 0-1:
+// the candidate's share of the evidence across all candidates, scaled by how
+// strong its own evidence is, so two equally likely sites get 0.5 each and a
+// lone weak match stays low.
+type DefinitionMatch struct {
+	SymbolMatch
+	Confidence float64 `json:"confidence"`
+	// Reasons lists the signals behind the score, for display.
+	Reasons []string `json:"reasons"`
+}
+
+// Definitions returns the most likely definition sites of req.Symbol, best
+// first, without a language server: candidates come from the indexed symbol
+// names and are scored by how exactly the name matches, what kind of chunk
+// carries it, and how close it is to req.From. Test files and doc comments
+// rank below real definitions. It returns ErrSymbolNotFound when no indexed
+// symbol has the name.
+func (s *Service) Definitions(ctx context.Context, req DefinitionRequest) ([]DefinitionMatch, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	name := strings.TrimSpace(req.Symbol)
+	if name == "" {
+		return nil, fmt.Errorf("symbol name cannot be empty")
+	}
+	if strings.ContainsAny(name, "*?[") {
+		return nil, fmt.Errorf("symbol %q must be an exact name; list candidates with 'vecgrep symbols %s'", name, name)
+	}
+	if req.Limit <= 0 {
+		req.Limit = DefaultDefinitionLimit
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filter := db.FilterOptions{ProjectRoot: s.session.ProjectRoot}
+	var labels map[string]string
+	filter.ProjectRoots, labels = SearchRoots(s.session.ProjectRoot, s.session.Config)
+	records, err := s.session.DB.FindSymbols(name, 0, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: %s (list similar names with 'vecgrep symbols %s')", ErrSymbolNotFound, name, name)
+	}
+
+	// Records are sorted by name, path, and line, so a definition split into
+	// several chunks is represented by its first.
+	seen := make(map[string]bool, len(records))
+	candidates := make([]SymbolMatch, 0, len(records))
+	for _, r := range records {
+		key := r.ProjectRoot + "\x00" + r.RelativePath + "\x00" + r.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		candidates = append(candidates, SymbolMatch{
+			Name:         r.Name,
+			ChunkType:    r.ChunkType,
+			Language:     r.Language,
+			RelativePath: r.RelativePath,
+			StartLine:    r.StartLine,
+			EndLine:      r.EndLine,
+			ChunkID:      r.ChunkID,
+			Root:         labels[r.ProjectRoot],
+		})
+	}
+	matches := rankDefinitions(name, strings.TrimPrefix(path.Clean("/"+req.From), "/"), candidates)
+	if len(matches) > req.Limit {
+		matches = matches[:req.Limit]
+	}
+	return matches, nil
+}
+
+// rankDefinitions scores candidates for name and sorts them best first.
+// from is a cleaned project-relative path, or empty.
+func rankDefinitions(name, from string, candidates []SymbolMatch) []DefinitionMatch {
+	hasCode := false
+	for _, c := range candidates {
+		if c.ChunkType != "comment" {
+			hasCode = true
+			break
+		}
+	}
+
+	matches := make([]DefinitionMatch, 0, len(candidates))
+	var total, best float64
+	for _, c := range candidates {
+		// A doc comment carries its symbol's name but is never the
+		// definition itself when the code is indexed too.
+		if hasCode && c.ChunkType == "comment" {
+			continue
+		}
+		m := DefinitionMatch{SymbolMatch: c}
+		raw := definitionNameScore(name, c.Name, &m.Reasons) * definitionKindScore(c, &m.Reasons)
+		if from != "" && c.Root == "" {
+			raw *= definitionProximity(from, c.RelativePath, &m.Reasons)
+		}
+		m.Confidence = raw
+		total += raw
+		best = max(best, raw)
+		matches = append(matches, m)
+	}
+	for i := range matches {
+		if total > 0 {
+			matches[i].Confidence = matches[i].Confidence / total * best
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Confidence != matches[j].Confidence {
+			return matches[i].Confidence > matches[j].Confidence
+		}
+		if matches[i].RelativePath != matches[j].RelativePath {
+			return matches[i].RelativePath < matches[j].RelativePath
+		}
+		return matches[i].StartLine < matches[j].StartLine
+	})
+	return matches
+}
+
+// definitionNameScore rates how exactly symbol matches the requested name.
+func definitionNameScore(name, symbol string, reasons *[]string) float64 {
+	switch {
+	case symbol == name:
+		*reasons = append(*reasons, "exact name")
+		return 1
+	case strings.EqualFold(symbol, name):
+		*reasons = append(*reasons, "name differs in case")
+		return 0.8
+	}
+	bare := symbol
+	if i := strings.LastIndex(symbol, "."); i >= 0 {
+		bare = symbol[i+1:]
+	}
+	if bare == name {
+		*reasons = append(*reasons, "member name")
+		return 0.9
+	}
+	*reasons = append(*reasons, "member name differs in case")
+	return 0.7
+}
+
+// definitionKindScore rates the chunk type as a definition site and demotes
+// test files, which often redefine helpers and fakes under the same name.
+func definitionKindScore(c SymbolMatch, reasons *[]string) float64 {
+	var score float64
+	switch c.ChunkType {
+	case "function", "class", "interface", "const":
+		score = 1
+		*reasons = append(*reasons, c.ChunkType)
+	case "block", "config":
+		score = 0.6
+		*reasons = append(*reasons, c.ChunkType+" chunk")
+	case "comment":
+		score = 0.3
+		*reasons = append(*reasons, "doc comment only")
+	default:
+		score = 0.5
+		*reasons = append(*reasons, "generic chunk")
+	}
+	if isTestPath(c.RelativePath) {
+		score *= 0.7
+		*reasons = append(*reasons, "test file")
+	}
+	return score
+}
+
+// definitionProximity rates how close file is to from: the same file, the
+// same directory, and then fewer directory steps apart score higher.
+func definitionProximity(from, file string, reasons *[]string) float64 {
+	if file == from {
+		*reasons = append(*reasons, "same file")
+		return 1
+	}
+	fromDir, fileDir := path.Dir(from), path.Dir(file)
+	if fromDir == fileDir {
+		*reasons = append(*reasons, "same directory")
+		return 0.95
+	}
+	steps := directorySteps(fromDir, fileDir)
+	*reasons = append(*reasons, fmt.Sprintf("%d directories away", steps))
+	return max(0.9-0.05*float64(steps-1), 0.6)
+}
+
+// directorySteps counts the moves up and down the tree between two
+// directories.
+func directorySteps(a, b string) int {
+	split := func(dir string) []string {
+		if dir == "." {
+			return nil
+		}
+		return strings.Split(dir, "/")
+	}
+	pa, pb := split(a), split(b)
+	common := 0
+	for common < len(pa) && common < len(pb) && pa[common] == pb[common] {
+		common++
+	}
+	return len(pa) - common + len(pb) - common
+}
+
+// isTestPath reports whether a project-relative path looks like a test file
+// in the common conventions of the languages vecgrep chunks.
+func isTestPath(rel string) bool {
+	base := path.Base(rel)
+	for _, suffix := range []string{"_test.go", "_test.py", "_spec.rb", "_test.rs"} {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+	if strings.HasPrefix(base, "test_") || strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") {
+		return true
+	}
+	for _, dir := range strings.Split(path.Dir(rel), "/") {
+		if dir == "test" || dir == "tests" || dir == "__tests__" || dir == "testdata" {
+			return true
+		}
+	}
+	return false
+}
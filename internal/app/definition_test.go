@@ -0,0 +1,59 @@
+package app
+
+import "testing"
+
+func TestRankDefinitions(t *testing.T) {
+	candidates := []SymbolMatch{
+		{Name: "Search", ChunkType: "comment", RelativePath: "internal/search/search.go", StartLine: 10},
+		{Name: "Search", ChunkType: "function", RelativePath: "internal/search/search.go", StartLine: 12},
+		{Name: "Searcher.Search", ChunkType: "function", RelativePath: "internal/db/db.go", StartLine: 40},
+		{Name: "Search", ChunkType: "function", RelativePath: "internal/search/search_test.go", StartLine: 5},
+	}
+
+	got := rankDefinitions("Search", "", candidates)
+	if len(got) != 3 {
+		t.Fatalf("got %d candidates, want the doc comment dropped: %+v", len(got), got)
+	}
+	if got[0].RelativePath != "internal/search/search.go" || got[0].ChunkType != "function" {
+		t.Fatalf("best = %+v, want the exact non-test function", got[0])
+	}
+	var sum float64
+	for i, m := range got {
+		sum += m.Confidence
+		if i > 0 && m.Confidence > got[i-1].Confidence {
+			t.Fatalf("candidates not sorted by confidence: %+v", got)
+		}
+	}
+	if sum > 1.0001 {
+		t.Fatalf("confidences sum to %f, want at most 1", sum)
+	}
+
+	// Proximity lifts the method defined next to the caller.
+	near := rankDefinitions("Search", "internal/db/veclite.go", candidates)
+	if near[0].Name != "Searcher.Search" {
+		t.Fatalf("with --from internal/db, best = %+v", near[0])
+	}
+
+	// A lone doc comment is still reported, with low confidence.
+	lone := rankDefinitions("Search", "", candidates[:1])
+	if len(lone) != 1 || lone[0].Confidence >= 0.5 {
+		t.Fatalf("lone comment = %+v", lone)
+	}
+}
+
+func TestDirectorySteps(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"internal/app", "internal/app", 0},
+		{"internal/app", "internal/db", 2},
+		{".", "internal/db", 2},
+		{"cmd/vecgrep", "internal/db/sub", 5},
+	}
+	for _, tc := range cases {
+		if got := directorySteps(tc.a, tc.b); got != tc.want {
+			t.Errorf("directorySteps(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+	vlsession "github.com/abdul-hamid-achik/veclite/session"
+)
+
+// Doctor check statuses.
+const (
+	DoctorOK   = "ok"
+	DoctorWarn = "warn"
+	DoctorFail = "fail"
+	// DoctorSkip marks a check that could not run because an earlier one
+	// failed (e.g. no stored vectors to compare without a database).
+	DoctorSkip = "skip"
+)
+
+// doctorProbeTimeout bounds each embedding provider request doctor makes.
+const doctorProbeTimeout = 15 * time.Second
+
+// doctorMissingFileSample is how many missing files a check names before
+// summarizing the rest as a count.
+const doctorMissingFileSample = 3
+
+// DoctorCheck is the outcome of one doctor check. Fix is a concrete next
+// step and is set whenever Status is DoctorWarn or DoctorFail.
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// DoctorReport is the result of RunDoctor. Healthy is false when any check
+// failed; warnings leave it true.
+type DoctorReport struct {
+	ProjectRoot string        `json:"project_root,omitempty"`
+	Checks      []DoctorCheck `json:"checks"`
+	Healthy     bool          `json:"healthy"`
+}
+
+func (r *DoctorReport) add(name, status, detail, fix string) {
+	r.Checks = append(r.Checks, DoctorCheck{Name: name, Status: status, Detail: detail, Fix: fix})
+	if status == DoctorFail {
+		r.Healthy = false
+	}
+}
+
+// RunDoctor diagnoses the project containing startDir and the global project
+// registry: the config, embedding provider availability, model presence,
+// dimension agreement between config, provider, and stored vectors, database
+// readability, indexed files that no longer exist on disk, orphaned data
+// directories, and stale project registrations. Unlike OpenSession it never
+// stops at the first problem and never writes: each failure becomes a check
+// with a suggested fix, and later checks run on whatever could be loaded.
+func RunDoctor(ctx context.Context, startDir string) *DoctorReport {
+	report := &DoctorReport{Healthy: true}
+	defer doctorRegistry(report)
+
+	if startDir == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			report.add("project", DoctorFail, fmt.Sprintf("get cwd: %v", err), "run vecgrep doctor from inside a project")
+			return report
+		}
+		startDir = cwd
+	}
+	absStart, err := filepath.Abs(startDir)
+	if err != nil {
+		report.add("project", DoctorFail, fmt.Sprintf("resolve start dir: %v", err), "run vecgrep doctor from inside a project")
+		return report
+	}
+	projectRoot, err := config.FindProjectRootFrom(absStart)
+	if err != nil {
+		report.add("project", DoctorFail, fmt.Sprintf("no vecgrep project at or above %s", absStart), "run 'vecgrep init' in the project root")
+		return report
+	}
+	report.ProjectRoot = projectRoot
+	report.add("project", DoctorOK, projectRoot, "")
+
+	resolved, err := config.NewConfigResolution().Resolve(projectRoot)
+	if err != nil {
+		report.add("config", DoctorFail, err.Error(), "fix the reported setting with 'vecgrep config set' or edit the config file")
+		return report
+	}
+	cfg := resolved.Config
+	report.add("config", DoctorOK, fmt.Sprintf("%s %s, %d dimensions, data in %s", cfg.Embedding.Provider, cfg.Embedding.Model, cfg.Embedding.Dimensions, cfg.DataDir), "")
+
+	providerDims := doctorProvider(ctx, report, cfg)
+	database := doctorDatabase(report, cfg)
+	if database != nil {
+		defer database.Close()
+	}
+	doctorDimensions(report, cfg, database, providerDims)
+	doctorIndexedFiles(report, cfg, database, projectRoot)
+	return report
+}
+
+// doctorProvider checks that the provider answers and has the configured
+// model, then embeds a probe text and returns the dimensions the provider
+// actually produces, or 0 when it could not be reached.
+func doctorProvider(ctx context.Context, report *DoctorReport, cfg *config.Config) int {
+	provider, err := NewProvider(cfg)
+	if err != nil {
+		report.add("provider", DoctorFail, err.Error(), "check the embedding.* settings with 'vecgrep config show'")
+		report.add("model", DoctorSkip, "provider could not be created", "")
+		return 0
+	}
+	defer func() { _ = closeProvider(provider) }()
+
+	pingCtx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+	err = provider.Ping(pingCtx)
+	cancel()
+	switch {
+	case errors.Is(err, embed.ErrModelNotFound):
+		report.add("provider", DoctorOK, fmt.Sprintf("%s is reachable", cfg.Embedding.Provider), "")
+		report.add("model", DoctorFail, fmt.Sprintf("%s does not have model %q", cfg.Embedding.Provider, cfg.Embedding.Model), modelFix(cfg))
+		return 0
+	case err != nil:
+		report.add("provider", DoctorFail, err.Error(), providerFix(cfg))
+		report.add("model", DoctorSkip, "provider is unavailable", "")
+		return 0
+	}
+	report.add("provider", DoctorOK, fmt.Sprintf("%s is reachable", cfg.Embedding.Provider), "")
+
+	embedCtx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+	defer cancel()
+	vector, err := provider.Embed(embedCtx, "vecgrep doctor")
+	if err != nil {
+		report.add("model", DoctorFail, fmt.Sprintf("%s answered but could not embed with %q: %v", cfg.Embedding.Provider, cfg.Embedding.Model, err), modelFix(cfg))
+		return 0
+	}
+	report.add("model", DoctorOK, fmt.Sprintf("%q returns %d-dimensional vectors", cfg.Embedding.Model, len(vector)), "")
+	return len(vector)
+}
+
+func providerFix(cfg *config.Config) string {
+	switch cfg.Embedding.Provider {
+	case "ollama":
+		return fmt.Sprintf("start Ollama ('ollama serve') or point embedding.ollama_url at it (now %s)", cfg.Embedding.OllamaURL)
+	case "openai", "voyage":
+		return fmt.Sprintf("check the %s API key and base URL, then retry", cfg.Embedding.Provider)
+	default:
+		return fmt.Sprintf("make sure the %s provider is running and reachable", cfg.Embedding.Provider)
+	}
+}
+
+func modelFix(cfg *config.Config) string {
+	if cfg.Embedding.Provider == "ollama" {
+		return fmt.Sprintf("run 'ollama pull %s' or set embedding.model to an installed model", cfg.Embedding.Model)
+	}
+	return "set embedding.model to a model the provider serves"
+}
+
+// doctorDatabase opens the index read-only, like OpenReadOnlySession, and
+// checks that its records can be read. It returns nil when there is no
+// usable database.
+func doctorDatabase(report *DoctorReport, cfg *config.Config) *db.DB {
+	vecPath := db.VecLitePath(cfg.DataDir)
+	legacyPath := cfg.DBPath
+	if legacyPath == "" {
+		legacyPath = filepath.Join(cfg.DataDir, config.DefaultDBFile)
+	}
+	if warning := detectMigrationWarning(legacyPath, vecPath); warning != "" {
+		report.add("database", DoctorFail, warning, "run 'vecgrep index --full' to rebuild the index in the current format")
+		return nil
+	}
+	if !fileExists(db.AtRestPath(vecPath)) {
+		report.add("database", DoctorWarn, fmt.Sprintf("no index at %s", vecPath), "run 'vecgrep index'")
+		return nil
+	}
+
+	database, err := db.OpenWithOptions(db.OpenOptions{
+		Dimensions:         cfg.Embedding.Dimensions,
+		DataDir:            cfg.DataDir,
+		HNSWM:              cfg.Vector.VecLite.M,
+		HNSWEfConstruction: cfg.Vector.VecLite.EfConstruction,
+		HNSWEfSearch:       cfg.Vector.VecLite.EfSearch,
+		Quantization:       db.Quantization(cfg.Vector.VecLite.Quantization),
+		Qdrant:             QdrantOptions(cfg),
+		Pgvector:           PgvectorOptions(cfg),
+		ProjectRoot:        report.ProjectRoot,
+		Encryption:         EncryptionOptions(cfg),
+		ReadOnly:           true,
+		SharedRead:         true,
+	})
+	if errors.Is(err, vlsession.ErrFileLocked) {
+		report.add("database", DoctorWarn, "another vecgrep process holds the index lock, so the index was not inspected", "stop it (e.g. 'vecgrep daemon stop') and rerun vecgrep doctor")
+		return nil
+	}
+	if err != nil {
+		report.add("database", DoctorFail, err.Error(), "run 'vecgrep reset --force' and then 'vecgrep index' to rebuild the index")
+		return nil
+	}
+	stats, err := database.Stats()
+	if err != nil {
+		_ = database.Close()
+		report.add("database", DoctorFail, fmt.Sprintf("read index: %v", err), "run 'vecgrep reset --force' and then 'vecgrep index' to rebuild the index")
+		return nil
+	}
+	report.add("database", DoctorOK, fmt.Sprintf("%d chunks in %d files", stats["chunks"], stats["files"]), "")
+	return database
+}
+
+// doctorDimensions compares the configured dimensions with the stored
+// embedding profile, a stored vector, and the provider's real output.
+func doctorDimensions(report *DoctorReport, cfg *config.Config, database *db.DB, providerDims int) {
+	configured := cfg.Embedding.Dimensions
+	if providerDims > 0 && providerDims != configured {
+		report.add("dimensions", DoctorFail,
+			fmt.Sprintf("embedding.dimensions is %d but %q returns %d-dimensional vectors", configured, cfg.Embedding.Model, providerDims),
+			fmt.Sprintf("run 'vecgrep config set embedding.dimensions %d' and then 'vecgrep index --full'", providerDims))
+		return
+	}
+	if database == nil {
+		report.add("dimensions", DoctorSkip, "no index to compare against", "")
+		return
+	}
+
+	stored, err := doctorStoredProfile(database, cfg.DataDir)
+	if err != nil {
+		report.add("dimensions", DoctorFail, fmt.Sprintf("read stored embedding profile: %v", err), "run 'vecgrep index --full' to rebuild the index")
+		return
+	}
+	storedDims := storedVectorDimensions(database)
+	if stored != nil && storedDims == 0 {
+		storedDims = stored.Dimensions
+	}
+	switch {
+	case storedDims == 0:
+		report.add("dimensions", DoctorOK, fmt.Sprintf("%d configured; no vectors stored yet", configured), "")
+	case storedDims != configured:
+		report.add("dimensions", DoctorFail,
+			fmt.Sprintf("the index stores %d-dimensional vectors but embedding.dimensions is %d", storedDims, configured),
+			fmt.Sprintf("restore the model the index was built with (embedding.dimensions %d), or run 'vecgrep index --full' to rebuild for the current one", storedDims))
+	case stored != nil && !stored.Matches(CurrentEmbeddingProfile(cfg)):
+		mismatch := &EmbeddingProfileMismatchError{Stored: stored, Current: CurrentEmbeddingProfile(cfg)}
+		report.add("dimensions", DoctorFail, fmt.Sprintf("dimensions agree (%d), but %s", storedDims, mismatch.Error()),
+			"restore the embedding settings the index was built with, or run 'vecgrep index --full'")
+	default:
+		report.add("dimensions", DoctorOK, fmt.Sprintf("config, provider, and index agree on %d", configured), "")
+	}
+}
+
+// doctorStoredProfile reads the embedding profile without the migration
+// LoadEmbeddingProfile performs, since doctor's database is read-only.
+func doctorStoredProfile(database *db.DB, dataDir string) (*EmbeddingProfile, error) {
+	if raw, ok := database.CollectionMetadataValue(embeddingProfileMetaKey); ok {
+		return decodeProfile(raw)
+	}
+	return loadSidecarProfile(dataDir)
+}
+
+// storedVectorDimensions returns the length of the first stored vector it
+// finds, or 0 when no file has one.
+func storedVectorDimensions(database *db.DB) int {
+	files, err := database.ListFiles("")
+	if err != nil {
+		return 0
+	}
+	for _, file := range files {
+		chunks, err := database.GetChunksByFile(file.RelativePath)
+		if err != nil {
+			continue
+		}
+		for _, chunk := range chunks {
+			if vector, err := database.GetEmbedding(int64(chunk.ID)); err == nil && len(vector) > 0 {
+				return len(vector)
+			}
+		}
+	}
+	return 0
+}
+
+// doctorIndexedFiles reports indexed files that are gone from disk and files
+// indexed outside the project and its extra roots, which a moved project
+// leaves behind in its data directory.
+func doctorIndexedFiles(report *DoctorReport, cfg *config.Config, database *db.DB, projectRoot string) {
+	if database == nil {
+		report.add("orphaned data", DoctorSkip, "no index to inspect", "")
+		return
+	}
+	files, err := database.ListFiles("")
+	if err != nil {
+		report.add("orphaned data", DoctorFail, fmt.Sprintf("list indexed files: %v", err), "run 'vecgrep reset --force' and then 'vecgrep index' to rebuild the index")
+		return
+	}
+	roots, _ := SearchRoots(projectRoot, cfg)
+	if roots == nil {
+		roots = []string{projectRoot}
+	}
+	var missing []string
+	foreign := 0
+	for _, file := range files {
+		if file.Path == "" {
+			continue
+		}
+		if !slices.ContainsFunc(roots, func(root string) bool { return pathWithin(root, file.Path) }) {
+			foreign++
+			continue
+		}
+		if _, err := os.Stat(file.Path); os.IsNotExist(err) {
+			missing = append(missing, file.RelativePath)
+		}
+	}
+	switch {
+	case len(missing) > 0:
+		detail := fmt.Sprintf("%d indexed files no longer exist: %v", len(missing), missing[:min(len(missing), doctorMissingFileSample)])
+		if len(missing) > doctorMissingFileSample {
+			detail += fmt.Sprintf(" and %d more", len(missing)-doctorMissingFileSample)
+		}
+		report.add("orphaned data", DoctorWarn, detail, "run 'vecgrep index' to prune them")
+	case foreign > 0:
+		report.add("orphaned data", DoctorWarn,
+			fmt.Sprintf("%d indexed files belong to a different project root (was the project moved?)", foreign),
+			"run 'vecgrep reset --force' and then 'vecgrep index' to rebuild for the current location")
+	case len(files) == 0:
+		report.add("orphaned data", DoctorOK, "no files indexed yet", "")
+	default:
+		report.add("orphaned data", DoctorOK, fmt.Sprintf("all %d indexed files exist", len(files)), "")
+	}
+}
+
+// doctorRegistry reports global registry entries whose project path is gone
+// and managed data directories that no entry owns.
+func doctorRegistry(report *DoctorReport) {
+	stale, err := config.PruneGlobalProjects(true, false)
+	if err != nil {
+		report.add("registry", DoctorWarn, fmt.Sprintf("read global registry: %v", err), "check ~/.vecgrep/config.yaml")
+		return
+	}
+	if len(stale) == 0 {
+		report.add("registry", DoctorOK, "every registered project path exists", "")
+	} else {
+		names := make([]string, len(stale))
+		for i, p := range stale {
+			names[i] = fmt.Sprintf("%s (%s)", p.Name, p.Path)
+		}
+		report.add("registry", DoctorWarn,
+			fmt.Sprintf("%d registered projects no longer exist: %v", len(stale), names),
+			"run 'vecgrep projects prune' (add --purge-data to delete their indexes)")
+	}
+
+	orphaned, err := config.OrphanedProjectDataDirs()
+	if err != nil || len(orphaned) == 0 {
+		return
+	}
+	var bytes int64
+	paths := make([]string, len(orphaned))
+	for i, dir := range orphaned {
+		bytes += dir.Bytes
+		paths[i] = dir.Path
+	}
+	report.add("orphaned data dirs", DoctorWarn,
+		fmt.Sprintf("%d data directories (%d bytes) belong to no registered project: %v", len(orphaned), bytes, paths),
+		"delete them if those projects are gone, or re-register with 'vecgrep projects add'")
+}
@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func doctorCheck(t *testing.T, report *DoctorReport, name string) DoctorCheck {
+	t.Helper()
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("no %q check in %+v", name, report.Checks)
+	return DoctorCheck{}
+}
+
+func TestDoctorDimensionsAndIndexedFiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session, service := createTestSession(t)
+	session.Provider = fakeProvider{dimensions: session.Config.Embedding.Dimensions, model: session.Config.Embedding.Model}
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(session.ProjectRoot, name), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := service.Index(context.Background(), IndexRequest{FullReindex: true}, nil); err != nil {
+		t.Fatalf("full index failed: %v", err)
+	}
+
+	cfg := *session.Config
+	report := &DoctorReport{Healthy: true}
+	doctorDimensions(report, &cfg, session.DB, cfg.Embedding.Dimensions)
+	doctorIndexedFiles(report, &cfg, session.DB, session.ProjectRoot)
+	if !report.Healthy || doctorCheck(t, report, "dimensions").Status != DoctorOK || doctorCheck(t, report, "orphaned data").Status != DoctorOK {
+		t.Fatalf("healthy index report = %+v", report)
+	}
+
+	// The config now names a model with different dimensions than the
+	// stored vectors: the cryptic mismatch doctor exists to explain.
+	cfg.Embedding.Dimensions *= 2
+	report = &DoctorReport{Healthy: true}
+	doctorDimensions(report, &cfg, session.DB, 0)
+	check := doctorCheck(t, report, "dimensions")
+	if report.Healthy || check.Status != DoctorFail || !strings.Contains(check.Fix, "vecgrep index --full") {
+		t.Fatalf("mismatched dimensions check = %+v", check)
+	}
+
+	report = &DoctorReport{Healthy: true}
+	doctorDimensions(report, &cfg, session.DB, session.Config.Embedding.Dimensions)
+	check = doctorCheck(t, report, "dimensions")
+	if check.Status != DoctorFail || !strings.Contains(check.Fix, "vecgrep config set embedding.dimensions") {
+		t.Fatalf("provider dimensions check = %+v", check)
+	}
+
+	if err := os.Remove(filepath.Join(session.ProjectRoot, "b.go")); err != nil {
+		t.Fatal(err)
+	}
+	report = &DoctorReport{Healthy: true}
+	doctorIndexedFiles(report, session.Config, session.DB, session.ProjectRoot)
+	check = doctorCheck(t, report, "orphaned data")
+	if !report.Healthy || check.Status != DoctorWarn || !strings.Contains(check.Detail, "b.go") {
+		t.Fatalf("missing file check = %+v", check)
+	}
+}
+
+func TestRunDoctorOutsideProject(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	report := RunDoctor(context.Background(), t.TempDir())
+	if report.Healthy || doctorCheck(t, report, "project").Status != DoctorFail {
+		t.Fatalf("report outside a project = %+v", report)
+	}
+	// The registry is checked even without a project.
+	if doctorCheck(t, report, "registry").Status != DoctorOK {
+		t.Fatalf("registry check = %+v", report.Checks)
+	}
+}
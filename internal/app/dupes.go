@@ -0,0 +1,306 @@
+package app
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+const (
+	// DefaultDuplicateThreshold is the cosine similarity at or above which two
+	// chunks are reported as duplicates.
+	DefaultDuplicateThreshold = 0.95
+	// DefaultDuplicateMinLines skips chunks too short to be meaningful
+	// duplication, such as package clauses and closing braces.
+	DefaultDuplicateMinLines = 3
+	// duplicateNeighbors is how many nearest neighbours are inspected per
+	// chunk. Larger clusters are still found through transitive pairs.
+	duplicateNeighbors = 5
+)
+
+// DuplicateOptions controls FindDuplicates.
+type DuplicateOptions struct {
+	Threshold float32
+	MinLines  int
+}
+
+// DuplicateChunk identifies one side of a duplicate pair. Key is the file path
+// plus a hash of the chunk content, so it survives line shifts elsewhere in
+// the file and can be committed in a baseline.
+type DuplicateChunk struct {
+	Key       string `json:"key"`
+	File      string `json:"file"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+// DuplicatePair is two chunks whose vectors are at least the threshold apart.
+type DuplicatePair struct {
+	Key   string         `json:"key"`
+	A     DuplicateChunk `json:"a"`
+	B     DuplicateChunk `json:"b"`
+	Score float32        `json:"score"`
+}
+
+// DuplicateCluster groups chunks connected by duplicate pairs.
+type DuplicateCluster struct {
+	Chunks   []DuplicateChunk `json:"chunks"`
+	MaxScore float32          `json:"max_score"`
+}
+
+// DuplicateReport lists duplicate pairs and the clusters they form, strongest
+// first.
+type DuplicateReport struct {
+	Threshold float32            `json:"threshold"`
+	Pairs     []DuplicatePair    `json:"pairs"`
+	Clusters  []DuplicateCluster `json:"clusters"`
+}
+
+// DuplicateBaseline is the committed record of accepted duplication. Only
+// pair keys are stored so the file stays stable while code moves around.
+type DuplicateBaseline struct {
+	Threshold float32  `json:"threshold"`
+	Pairs     []string `json:"pairs"`
+}
+
+// DuplicateComparison splits a report against a baseline.
+type DuplicateComparison struct {
+	New      []DuplicatePair `json:"new"`
+	Known    int             `json:"known"`
+	Resolved []string        `json:"resolved,omitempty"`
+}
+
+// FindDuplicates searches every indexed chunk's nearest neighbours and
+// returns the pairs scoring at least opts.Threshold, grouped into clusters.
+func (s *Service) FindDuplicates(ctx context.Context, opts DuplicateOptions) (*DuplicateReport, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultDuplicateThreshold
+	}
+	if opts.MinLines <= 0 {
+		opts.MinLines = DefaultDuplicateMinLines
+	}
+
+	pairs, err := s.neighbourPairs(ctx, opts.MinLines, func(_, _ DuplicateChunk, score float32) bool {
+		return score >= opts.Threshold
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DuplicateReport{Threshold: opts.Threshold, Pairs: make([]DuplicatePair, 0, len(pairs))}
+	for _, pair := range pairs {
+		report.Pairs = append(report.Pairs, pair)
+	}
+	sortDuplicatePairs(report.Pairs)
+	report.Clusters = clusterDuplicates(report.Pairs)
+	return report, nil
+}
+
+// neighbourPairs pairs every eligible chunk with its nearest neighbours and
+// keeps the pairs accept approves, by key with the highest score. Chunks
+// with identical content in the same file share a key and never pair.
+func (s *Service) neighbourPairs(ctx context.Context, minLines int, accept func(a, b DuplicateChunk, score float32) bool) (map[string]DuplicatePair, error) {
+	files, err := s.session.DB.ListFiles(s.session.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("list indexed files: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].RelativePath < files[j].RelativePath })
+
+	filter := db.FilterOptions{ProjectRoot: s.session.ProjectRoot}
+	pairs := make(map[string]DuplicatePair)
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunks, err := s.session.DB.GetChunksByFile(file.RelativePath)
+		if err != nil {
+			return nil, fmt.Errorf("read chunks for %s: %w", file.RelativePath, err)
+		}
+		for _, chunk := range chunks {
+			if len(chunk.Vector) == 0 || !duplicateEligible(chunk, minLines) {
+				continue
+			}
+			neighbours, err := s.session.DB.SearchWithFilter(chunk.Vector, duplicateNeighbors+1, filter)
+			if err != nil {
+				return nil, fmt.Errorf("search neighbours of %s:%d: %w", chunk.RelativePath, chunk.StartLine, err)
+			}
+			self := duplicateChunkFrom(chunk)
+			for _, n := range neighbours {
+				if n.Chunk == nil || !duplicateEligible(*n.Chunk, minLines) {
+					continue
+				}
+				other := duplicateChunkFrom(*n.Chunk)
+				if other.Key == self.Key || !accept(self, other, n.Distance) {
+					continue
+				}
+				pair := newDuplicatePair(self, other, n.Distance)
+				if existing, ok := pairs[pair.Key]; !ok || pair.Score > existing.Score {
+					pairs[pair.Key] = pair
+				}
+			}
+		}
+	}
+	return pairs, nil
+}
+
+// Baseline returns the report as a baseline that accepts every current pair.
+func (r *DuplicateReport) Baseline() DuplicateBaseline {
+	baseline := DuplicateBaseline{Threshold: r.Threshold, Pairs: make([]string, 0, len(r.Pairs))}
+	for _, pair := range r.Pairs {
+		baseline.Pairs = append(baseline.Pairs, pair.Key)
+	}
+	sort.Strings(baseline.Pairs)
+	return baseline
+}
+
+// Compare splits the report into pairs missing from the baseline and pairs
+// it already accepts, and lists baseline pairs that no longer occur. A nil
+// baseline treats every pair as new.
+func (r *DuplicateReport) Compare(baseline *DuplicateBaseline) DuplicateComparison {
+	known := make(map[string]bool)
+	if baseline != nil {
+		for _, key := range baseline.Pairs {
+			known[key] = false
+		}
+	}
+	var cmp DuplicateComparison
+	for _, pair := range r.Pairs {
+		if _, ok := known[pair.Key]; ok {
+			known[pair.Key] = true
+			cmp.Known++
+			continue
+		}
+		cmp.New = append(cmp.New, pair)
+	}
+	for key, seen := range known {
+		if !seen {
+			cmp.Resolved = append(cmp.Resolved, key)
+		}
+	}
+	sort.Strings(cmp.Resolved)
+	return cmp
+}
+
+// LoadDuplicateBaseline reads a baseline written by WriteDuplicateBaseline.
+func LoadDuplicateBaseline(path string) (*DuplicateBaseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var baseline DuplicateBaseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("parse duplicate baseline %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+// WriteDuplicateBaseline writes baseline as indented JSON so diffs stay
+// reviewable.
+func WriteDuplicateBaseline(path string, baseline DuplicateBaseline) error {
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func duplicateEligible(chunk db.ChunkRecord, minLines int) bool {
+	return chunk.EndLine-chunk.StartLine+1 >= minLines && strings.TrimSpace(chunk.Content) != ""
+}
+
+func duplicateChunkFrom(chunk db.ChunkRecord) DuplicateChunk {
+	sum := sha256.Sum256([]byte(chunk.Content))
+	return DuplicateChunk{
+		Key:       chunk.RelativePath + "#" + hex.EncodeToString(sum[:])[:12],
+		File:      chunk.RelativePath,
+		StartLine: chunk.StartLine,
+		EndLine:   chunk.EndLine,
+	}
+}
+
+func newDuplicatePair(a, b DuplicateChunk, score float32) DuplicatePair {
+	if b.Key < a.Key {
+		a, b = b, a
+	}
+	return DuplicatePair{Key: a.Key + "|" + b.Key, A: a, B: b, Score: score}
+}
+
+func sortDuplicatePairs(pairs []DuplicatePair) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Score != pairs[j].Score {
+			return pairs[i].Score > pairs[j].Score
+		}
+		return pairs[i].Key < pairs[j].Key
+	})
+}
+
+// clusterDuplicates unions pairs into connected components. Clusters are
+// ordered largest first, then by strongest pair.
+func clusterDuplicates(pairs []DuplicatePair) []DuplicateCluster {
+	parent := make(map[string]string)
+	var find func(string) string
+	find = func(key string) string {
+		if parent[key] != key {
+			parent[key] = find(parent[key])
+		}
+		return parent[key]
+	}
+	chunks := make(map[string]DuplicateChunk)
+	for _, pair := range pairs {
+		for _, c := range []DuplicateChunk{pair.A, pair.B} {
+			if _, ok := parent[c.Key]; !ok {
+				parent[c.Key] = c.Key
+				chunks[c.Key] = c
+			}
+		}
+		if ra, rb := find(pair.A.Key), find(pair.B.Key); ra != rb {
+			parent[rb] = ra
+		}
+	}
+
+	byRoot := make(map[string]*DuplicateCluster)
+	for key, chunk := range chunks {
+		root := find(key)
+		if byRoot[root] == nil {
+			byRoot[root] = &DuplicateCluster{}
+		}
+		byRoot[root].Chunks = append(byRoot[root].Chunks, chunk)
+	}
+	for _, pair := range pairs {
+		cluster := byRoot[find(pair.A.Key)]
+		if pair.Score > cluster.MaxScore {
+			cluster.MaxScore = pair.Score
+		}
+	}
+
+	clusters := make([]DuplicateCluster, 0, len(byRoot))
+	for _, cluster := range byRoot {
+		sort.Slice(cluster.Chunks, func(i, j int) bool {
+			if cluster.Chunks[i].File != cluster.Chunks[j].File {
+				return cluster.Chunks[i].File < cluster.Chunks[j].File
+			}
+			return cluster.Chunks[i].StartLine < cluster.Chunks[j].StartLine
+		})
+		clusters = append(clusters, *cluster)
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		if len(clusters[i].Chunks) != len(clusters[j].Chunks) {
+			return len(clusters[i].Chunks) > len(clusters[j].Chunks)
+		}
+		if clusters[i].MaxScore != clusters[j].MaxScore {
+			return clusters[i].MaxScore > clusters[j].MaxScore
+		}
+		return clusters[i].Chunks[0].Key < clusters[j].Chunks[0].Key
+	})
+	return clusters
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func dupeChunk(file string, line int) DuplicateChunk {
+	return DuplicateChunk{Key: file + "#" + string(rune('a'+line)), File: file, StartLine: line, EndLine: line + 5}
+}
+
+func TestClusterDuplicatesJoinsTransitivePairs(t *testing.T) {
+	a, b, c := dupeChunk("a.go", 1), dupeChunk("b.go", 2), dupeChunk("c.go", 3)
+	d, e := dupeChunk("d.go", 4), dupeChunk("e.go", 5)
+	pairs := []DuplicatePair{
+		newDuplicatePair(a, b, 0.97),
+		newDuplicatePair(b, c, 0.96),
+		newDuplicatePair(d, e, 0.99),
+	}
+	sortDuplicatePairs(pairs)
+
+	clusters := clusterDuplicates(pairs)
+	if len(clusters) != 2 {
+		t.Fatalf("clusters = %+v, want 2", clusters)
+	}
+	if len(clusters[0].Chunks) != 3 || clusters[0].MaxScore != 0.97 || clusters[0].Chunks[0].File != "a.go" {
+		t.Fatalf("first cluster = %+v, want a/b/c with max 0.97", clusters[0])
+	}
+	if len(clusters[1].Chunks) != 2 || clusters[1].MaxScore != 0.99 {
+		t.Fatalf("second cluster = %+v, want d/e with max 0.99", clusters[1])
+	}
+}
+
+func TestDuplicateReportCompareAgainstBaseline(t *testing.T) {
+	a, b, c := dupeChunk("a.go", 1), dupeChunk("b.go", 2), dupeChunk("c.go", 3)
+	old := &DuplicateReport{Threshold: 0.95, Pairs: []DuplicatePair{newDuplicatePair(a, b, 0.97), newDuplicatePair(a, c, 0.96)}}
+	baseline := old.Baseline()
+
+	path := filepath.Join(t.TempDir(), "dupes.json")
+	if err := WriteDuplicateBaseline(path, baseline); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadDuplicateBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pair keys do not depend on argument order or line numbers.
+	moved := b
+	moved.StartLine = 40
+	current := &DuplicateReport{Threshold: 0.95, Pairs: []DuplicatePair{newDuplicatePair(moved, a, 0.97), newDuplicatePair(b, c, 0.98)}}
+	cmp := current.Compare(loaded)
+	if cmp.Known != 1 || len(cmp.New) != 1 || cmp.New[0].Key != newDuplicatePair(b, c, 0).Key {
+		t.Fatalf("compare = %+v, want one known and b|c new", cmp)
+	}
+	if len(cmp.Resolved) != 1 || cmp.Resolved[0] != newDuplicatePair(a, c, 0).Key {
+		t.Fatalf("resolved = %v, want a|c", cmp.Resolved)
+	}
+
+	if got := current.Compare(nil); len(got.New) != 2 || got.Known != 0 {
+		t.Fatalf("compare without baseline = %+v, want every pair new", got)
+	}
+}
@@ -0,0 +1,324 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+const embedQueueFilename = "embed-queue.jsonl"
+
+// EmbedQueuePath returns the deferred-embedding queue for a data directory.
+// Each line is one index.PendingFile; a later line for the same file
+// supersedes earlier ones.
+func EmbedQueuePath(dataDir string) string {
+	return filepath.Join(dataDir, embedQueueFilename)
+}
+
+// embedQueue appends files chunked by a deferred-embedding run.
+type embedQueue struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func openEmbedQueue(dataDir string) (*embedQueue, error) {
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+	file, err := os.OpenFile(EmbedQueuePath(dataDir), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open embedding queue: %w", err)
+	}
+	return &embedQueue{file: file}, nil
+}
+
+// Append queues one file. It is safe for concurrent use by chunk workers.
+func (q *embedQueue) Append(file index.PendingFile) error {
+	line, err := json.Marshal(file)
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", file.RelativePath, err)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, err := q.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("write embedding queue: %w", err)
+	}
+	return nil
+}
+
+func (q *embedQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.file.Close()
+}
+
+// loadEmbedQueue reads the queue, keeping the latest entry for each file in
+// the order files were first queued. A torn final line from an interrupted
+// run is ignored; that file simply reads as modified on the next index run.
+func loadEmbedQueue(dataDir string) ([]index.PendingFile, error) {
+	file, err := os.Open(EmbedQueuePath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open embedding queue: %w", err)
+	}
+	defer file.Close()
+
+	var files []index.PendingFile
+	slots := make(map[string]int)
+	reader := bufio.NewReader(file)
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return nil, fmt.Errorf("read embedding queue: %w", readErr)
+		}
+		complete := len(line) > 0 && line[len(line)-1] == '\n'
+		if len(line) > 0 {
+			var pending index.PendingFile
+			if err := json.Unmarshal(line, &pending); err != nil {
+				if !complete {
+					break
+				}
+				return nil, fmt.Errorf("decode embedding queue line %d: %w", lineNo, err)
+			}
+			key := pending.ProjectRoot + "\x00" + pending.RelativePath
+			if slot, ok := slots[key]; ok {
+				files[slot] = pending
+			} else {
+				slots[key] = len(files)
+				files = append(files, pending)
+			}
+		}
+		if readErr != nil {
+			break
+		}
+	}
+	return files, nil
+}
+
+// writeEmbedQueue replaces the queue with files, removing it when empty.
+func writeEmbedQueue(dataDir string, files []index.PendingFile) error {
+	path := EmbedQueuePath(dataDir)
+	if len(files) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove embedding queue: %w", err)
+		}
+		return nil
+	}
+	tmp, err := os.CreateTemp(dataDir, "."+embedQueueFilename+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create embedding queue temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, file := range files {
+		if err := encoder.Encode(file); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("encode embedding queue: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write embedding queue: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close embedding queue: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replace embedding queue: %w", err)
+	}
+	return nil
+}
+
+// PendingEmbeddings summarizes the deferred-embedding queue.
+type PendingEmbeddings struct {
+	Files  int `json:"files"`
+	Chunks int `json:"chunks"`
+}
+
+// LoadPendingEmbeddings counts the files and chunks waiting in the
+// deferred-embedding queue of dataDir.
+func LoadPendingEmbeddings(dataDir string) (PendingEmbeddings, error) {
+	files, err := loadEmbedQueue(dataDir)
+	if err != nil {
+		return PendingEmbeddings{}, err
+	}
+	pending := PendingEmbeddings{Files: len(files)}
+	for _, file := range files {
+		pending.Chunks += len(file.Records)
+	}
+	return pending, nil
+}
+
+// EmbedPendingResult reports one run over the deferred-embedding queue.
+type EmbedPendingResult struct {
+	FilesEmbedded  int
+	ChunksEmbedded int
+	// FilesStale counts queued files dropped because they changed on disk
+	// or were indexed normally after they were queued.
+	FilesStale int
+	// FilesRemaining counts files left in the queue after failures.
+	FilesRemaining int
+	Duration       time.Duration
+	Errors         []error
+}
+
+// EmbedPendingProgress is reported after each queued file.
+type EmbedPendingProgress struct {
+	Done  int
+	Total int
+	File  string
+}
+
+// EmbedPending embeds the files queued by deferred-embedding index runs.
+func (s *Service) EmbedPending(ctx context.Context, progress func(EmbedPendingProgress)) (*EmbedPendingResult, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	return s.coordinator().EmbedPending(ctx, progress)
+}
+
+// EmbedPending embeds the deferred-embedding queue under the same run lock as
+// indexing. Files that fail stay queued; a fatal error such as an exhausted
+// budget stops the run and leaves the rest queued.
+func (c *IndexCoordinator) EmbedPending(ctx context.Context, progress func(EmbedPendingProgress)) (result *EmbedPendingResult, retErr error) {
+	if c == nil {
+		return nil, fmt.Errorf("index coordinator is nil")
+	}
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+	if c.cfg == nil || c.stores == nil {
+		return nil, fmt.Errorf("index coordinator is not configured")
+	}
+
+	files, err := loadEmbedQueue(c.cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	result = &EmbedPendingResult{}
+	if len(files) == 0 {
+		return result, nil
+	}
+	if err := c.prepareProvider(ctx); err != nil {
+		return nil, err
+	}
+	database, release, err := c.acquireIndexDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := release(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("release index database: %w", err))
+		}
+	}()
+	service := c.borrowedService(database)
+	if err := service.ensureEmbeddingProfileMatches(); err != nil {
+		return nil, err
+	}
+
+	indexer := index.NewIndexer(database, c.provider, BuildIndexerConfig(c.cfg, nil))
+	meter := embed.UsageOf(c.provider)
+	budgetTokens := embed.BudgetTokens(c.cfg.Embedding.Provider, c.cfg.Embedding.Model, c.cfg.Embedding.BudgetUSD)
+	startedAt := time.Now()
+	usageStart := meter.BeginRun(budgetTokens)
+
+	storedHashes := make(map[string]map[string]string)
+	var remaining []index.PendingFile
+	var fatalErr error
+	for i, file := range files {
+		if fatalErr == nil {
+			fatalErr = ctx.Err()
+		}
+		if fatalErr != nil {
+			remaining = append(remaining, file)
+			continue
+		}
+		hashes, ok := storedHashes[file.ProjectRoot]
+		if !ok {
+			// A read failure only loses the already-indexed shortcut; the
+			// on-disk check below still catches stale entries.
+			hashes, _ = database.GetFileHashes(file.ProjectRoot)
+			storedHashes[file.ProjectRoot] = hashes
+		}
+		if pendingFileStale(file, hashes) {
+			result.FilesStale++
+		} else if chunks, err := indexer.EmbedPendingFile(ctx, file); err != nil {
+			remaining = append(remaining, file)
+			result.Errors = append(result.Errors, err)
+			if index.IsFatalEmbedError(err) {
+				fatalErr = err
+			}
+		} else {
+			result.FilesEmbedded++
+			result.ChunksEmbedded += chunks
+		}
+		if progress != nil {
+			progress(EmbedPendingProgress{Done: i + 1, Total: len(files), File: file.RelativePath})
+		}
+	}
+	meter.EndRun()
+	c.recordRunUsage(meter, usageStart, budgetTokens, startedAt, fatalErr)
+	result.FilesRemaining = len(remaining)
+	result.Duration = time.Since(startedAt)
+
+	var postErr error
+	if err := flushProvider(c.provider); err != nil {
+		postErr = fmt.Errorf("flush embedding provider: %w", err)
+	}
+	if result.FilesEmbedded > 0 {
+		if err := service.saveCurrentEmbeddingProfile(); err != nil {
+			postErr = errors.Join(postErr, err)
+		}
+		if err := database.SetIndexedEmbedding(db.IndexedEmbedding{Model: c.provider.Model(), Dimensions: database.Dimensions()}); err != nil {
+			postErr = errors.Join(postErr, fmt.Errorf("record embedding model: %w", err))
+		}
+	}
+	if err := database.Sync(); err != nil {
+		postErr = errors.Join(postErr, fmt.Errorf("sync index: %w", err))
+	}
+	// Only rewrite the queue once the embedded files are durable, so a
+	// failed sync leaves them queued rather than lost.
+	if postErr == nil {
+		postErr = writeEmbedQueue(c.cfg.DataDir, remaining)
+	}
+	if err := errors.Join(fatalErr, postErr); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// pendingFileStale reports whether a queued file no longer matches the
+// source it was chunked from, or was indexed at the same content since.
+// Embedding a stale entry would overwrite newer chunks with old ones.
+func pendingFileStale(file index.PendingFile, storedHashes map[string]string) bool {
+	if len(file.Records) == 0 {
+		return true
+	}
+	if stored, ok := storedHashes[file.RelativePath]; ok && stored == file.Records[0].FileHash {
+		return true
+	}
+	content, err := os.ReadFile(file.Path)
+	if err != nil {
+		return true
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:]) != file.SourceHash()
+}
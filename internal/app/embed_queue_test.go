@@ -0,0 +1,101 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+func pendingTestFile(root, rel, content string) index.PendingFile {
+	sum := sha256.Sum256([]byte(content))
+	hash := hex.EncodeToString(sum[:])
+	record := db.NewChunkRecord(filepath.Join(root, rel), rel, hash, int64(len(content)), "go", content, 1, 1, 0, len(content), "function", "", root)
+	record.SourceHash = hash
+	return index.PendingFile{
+		Path:         filepath.Join(root, rel),
+		RelativePath: rel,
+		ProjectRoot:  root,
+		Size:         int64(len(content)),
+		Records:      []db.ChunkRecord{record},
+		Texts:        []string{content},
+	}
+}
+
+func TestEmbedQueueAppendLoadAndRewrite(t *testing.T) {
+	dataDir := t.TempDir()
+	queue, err := openEmbedQueue(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []index.PendingFile{
+		pendingTestFile("/p", "a.go", "func A() {}"),
+		pendingTestFile("/p", "b.go", "func B() {}"),
+		pendingTestFile("/p", "a.go", "func A2() {}"),
+	} {
+		if err := queue.Append(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := queue.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// A torn final line from an interrupted run is ignored.
+	f, err := os.OpenFile(EmbedQueuePath(dataDir), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"path":"/p/c.go","relative`)
+	_ = f.Close()
+
+	files, err := loadEmbedQueue(dataDir)
+	if err != nil {
+		t.Fatalf("loadEmbedQueue failed: %v", err)
+	}
+	if len(files) != 2 || files[0].RelativePath != "a.go" || files[0].Texts[0] != "func A2() {}" || files[1].RelativePath != "b.go" {
+		t.Fatalf("files = %+v, want the latest a.go then b.go", files)
+	}
+	pending, err := LoadPendingEmbeddings(dataDir)
+	if err != nil || pending.Files != 2 || pending.Chunks != 2 {
+		t.Fatalf("pending = %+v, %v; want 2 files and 2 chunks", pending, err)
+	}
+
+	if err := writeEmbedQueue(dataDir, files[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if files, err = loadEmbedQueue(dataDir); err != nil || len(files) != 1 || files[0].RelativePath != "b.go" {
+		t.Fatalf("after rewrite files = %+v, %v; want b.go", files, err)
+	}
+	if err := writeEmbedQueue(dataDir, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(EmbedQueuePath(dataDir)); !os.IsNotExist(err) {
+		t.Fatalf("empty queue left on disk: %v", err)
+	}
+}
+
+func TestPendingFileStale(t *testing.T) {
+	root := t.TempDir()
+	content := "func A() {}"
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	file := pendingTestFile(root, "a.go", content)
+
+	if pendingFileStale(file, nil) {
+		t.Fatal("unchanged, unindexed file reported stale")
+	}
+	if !pendingFileStale(file, map[string]string{"a.go": file.Records[0].FileHash}) {
+		t.Fatal("file already indexed at the queued hash was not reported stale")
+	}
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte("func A() { changed() }"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !pendingFileStale(file, nil) {
+		t.Fatal("file changed on disk was not reported stale")
+	}
+}
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/abdul-hamid-achik/vecgrep/internal/config"
 	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
 )
 
 const (
@@ -20,6 +22,9 @@ const (
 	embeddingProfileDistance      = "cosine"
 	embeddingProfileModality      = "text"
 	embeddingProfilePreprocessor  = "code-chunker-v2-lossless"
+	// embeddingProfileNormalization is the vector normalization applied to
+	// every provider's output (see embed.NewNormalizedProvider).
+	embeddingProfileNormalization = embed.NormalizationL2
 )
 
 type EmbeddingProfile struct {
@@ -31,10 +36,15 @@ type EmbeddingProfile struct {
 	Distance         string `json:"distance"`
 	Modality         string `json:"modality"`
 	Preprocessor     string `json:"preprocessor"`
+	Normalization    string `json:"normalization,omitempty"`
 	QueryTemplate    string `json:"query_template,omitempty"`
 	DocumentTemplate string `json:"document_template,omitempty"`
 	OllamaContext    int    `json:"ollama_context,omitempty"`
 	OllamaOptions    string `json:"ollama_options,omitempty"`
+	// Quantization is how the index stores vectors; empty for full precision.
+	Quantization string `json:"quantization,omitempty"`
+	// VectorBackend is where the vectors live; empty for VecLite.
+	VectorBackend string `json:"vector_backend,omitempty"`
 }
 
 type EmbeddingProfileMismatchError struct {
@@ -51,6 +61,10 @@ func (e *EmbeddingProfileMismatchError) Error() string {
 	if e.Stored == nil {
 		return fmt.Sprintf("%s; run 'vecgrep index --full' or 'vecgrep reset --force' to rebuild", reason)
 	}
+	if e.Stored.reembeddable(e.Current) {
+		return fmt.Sprintf("%s: stored %q, active %q; run 'vecgrep reembed --only-stale' or 'vecgrep index --full' to rebuild",
+			reason, e.Stored.ProfileID, e.Current.ProfileID)
+	}
 	return fmt.Sprintf("%s: stored %q, active %q; run 'vecgrep index --full' or 'vecgrep reset --force' to rebuild",
 		reason, e.Stored.ProfileID, e.Current.ProfileID)
 }
@@ -75,16 +89,11 @@ func CurrentEmbeddingProfile(cfg *config.Config) EmbeddingProfile {
 		Distance:         embeddingProfileDistance,
 		Modality:         embeddingProfileModality,
 		Preprocessor:     embeddingProfilePreprocessor,
+		Normalization:    embeddingProfileNormalization,
 		QueryTemplate:    cfg.Embedding.QueryTemplate,
 		DocumentTemplate: cfg.Embedding.DocumentTemplate,
 	}
-	profile.ProfileID = fmt.Sprintf("%s:%s:%d:%s:%s",
-		profile.Provider,
-		profile.Model,
-		profile.Dimensions,
-		profile.Distance,
-		profile.Preprocessor,
-	)
+	profile.ProfileID = profile.baseProfileID() + ":" + profile.Normalization
 	if profile.QueryTemplate != "" || profile.DocumentTemplate != "" {
 		templateHash := sha256.Sum256([]byte(profile.QueryTemplate + "\x00" + profile.DocumentTemplate))
 		profile.ProfileID += fmt.Sprintf(":templates:%x", templateHash)
@@ -103,6 +112,18 @@ func CurrentEmbeddingProfile(cfg *config.Config) EmbeddingProfile {
 			profile.ProfileID += fmt.Sprintf(":ollama:%x", requestHash)
 		}
 	}
+	// Quantized codes cannot be mixed with full-precision vectors, so the
+	// storage mode is part of the profile. An invalid value fails at open.
+	if quantization, err := db.ParseQuantization(cfg.Vector.VecLite.Quantization); err == nil && quantization != db.QuantizationNone {
+		profile.Quantization = string(quantization)
+		profile.ProfileID += ":quantization:" + profile.Quantization
+	}
+	// A VecLite file indexed for an external vector store holds only binary
+	// codes, and one indexed without it has nothing in the store.
+	if cfg.Vector.Backend == string(db.VectorBackendQdrant) || cfg.Vector.Backend == string(db.VectorBackendPgvector) {
+		profile.VectorBackend = cfg.Vector.Backend
+		profile.ProfileID += ":backend:" + profile.VectorBackend
+	}
 	return profile
 }
 
@@ -135,7 +156,7 @@ func LoadEmbeddingProfile(database *db.DB, dataDir string) (*EmbeddingProfile, e
 		return nil, fmt.Errorf("migrate embedding profile to collection metadata: %w", err)
 	}
 	if err := RemoveEmbeddingProfile(dataDir); err != nil {
-		log.Printf("embedding profile: migrated to collection metadata but failed to remove sidecar: %v", err)
+		slog.Warn("embedding profile migrated to collection metadata but the sidecar could not be removed", "err", err)
 	}
 	return sidecar, nil
 }
@@ -163,7 +184,7 @@ func SaveEmbeddingProfile(database *db.DB, dataDir string, profile EmbeddingProf
 	}
 	// Best-effort cleanup of any leftover legacy sidecar.
 	if err := RemoveEmbeddingProfile(dataDir); err != nil {
-		log.Printf("embedding profile: saved to collection metadata but failed to remove legacy sidecar: %v", err)
+		slog.Warn("embedding profile saved to collection metadata but the legacy sidecar could not be removed", "err", err)
 	}
 	return nil
 }
@@ -190,7 +211,59 @@ func RemoveEmbeddingProfileMeta(database *db.DB) error {
 	return database.DeleteCollectionMetadataValue(embeddingProfileMetaKey)
 }
 
+// baseProfileID is the leading part of the profile ID, before the
+// normalization policy and the optional suffixes.
+func (p EmbeddingProfile) baseProfileID() string {
+	return fmt.Sprintf("%s:%s:%d:%s:%s", p.Provider, p.Model, p.Dimensions, p.Distance, p.Preprocessor)
+}
+
+// withNormalization returns p as an index built before normalization was
+// recorded would be described today. Cosine distance ignores vector length,
+// so such an index ranks exactly as an L2-normalized one; it is given the l2
+// policy, and its profile ID the matching segment, instead of a rebuild.
+func (p EmbeddingProfile) withNormalization() EmbeddingProfile {
+	if p.Normalization != "" || p.Distance != embeddingProfileDistance {
+		return p
+	}
+	p.Normalization = embed.NormalizationL2
+	if base := p.baseProfileID(); strings.HasPrefix(p.ProfileID, base) {
+		p.ProfileID = base + ":" + p.Normalization + strings.TrimPrefix(p.ProfileID, base)
+	}
+	return p
+}
+
+// EmbedVersion identifies what produced a stored vector: the provider, the
+// model, and the dimensions, plus a hash of the document template and the
+// Ollama request options when any are set. Every chunk is stamped with it so
+// that vecgrep reembed --only-stale can find the vectors a configuration
+// change left behind. The query template is left out since it does not
+// touch stored vectors.
+func (p EmbeddingProfile) EmbedVersion() string {
+	version := fmt.Sprintf("%s/%s@%d", p.Provider, p.Model, p.Dimensions)
+	if p.DocumentTemplate != "" || p.OllamaContext != 0 || p.OllamaOptions != "" {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s", p.DocumentTemplate, p.OllamaContext, p.OllamaOptions)))
+		version += fmt.Sprintf("+%x", sum[:6])
+	}
+	return version
+}
+
+// reembeddable reports whether an index built with p can be brought to other
+// by re-embedding its chunks: everything but the embedding itself must agree,
+// since a different chunker, storage layout, or vector size needs a rebuild.
+func (p EmbeddingProfile) reembeddable(other EmbeddingProfile) bool {
+	p, other = p.withNormalization(), other.withNormalization()
+	return p.SchemaVersion == other.SchemaVersion &&
+		p.Dimensions == other.Dimensions &&
+		p.Distance == other.Distance &&
+		p.Modality == other.Modality &&
+		p.Preprocessor == other.Preprocessor &&
+		p.Normalization == other.Normalization &&
+		p.Quantization == other.Quantization &&
+		p.VectorBackend == other.VectorBackend
+}
+
 func (p EmbeddingProfile) Matches(other EmbeddingProfile) bool {
+	p, other = p.withNormalization(), other.withNormalization()
 	return p.SchemaVersion == other.SchemaVersion &&
 		p.ProfileID == other.ProfileID &&
 		p.Provider == other.Provider &&
@@ -199,10 +272,13 @@ func (p EmbeddingProfile) Matches(other EmbeddingProfile) bool {
 		p.Distance == other.Distance &&
 		p.Modality == other.Modality &&
 		p.Preprocessor == other.Preprocessor &&
+		p.Normalization == other.Normalization &&
 		p.QueryTemplate == other.QueryTemplate &&
 		p.DocumentTemplate == other.DocumentTemplate &&
 		p.OllamaContext == other.OllamaContext &&
-		p.OllamaOptions == other.OllamaOptions
+		p.OllamaOptions == other.OllamaOptions &&
+		p.Quantization == other.Quantization &&
+		p.VectorBackend == other.VectorBackend
 }
 
 func (s *Service) ensureEmbeddingProfileMatches() error {
@@ -233,6 +309,36 @@ func (s *Service) ensureEmbeddingProfileForIndex(fullReindex bool) error {
 	return s.ensureEmbeddingProfileMatches()
 }
 
+// ensureEmbeddingProfileForReembed allows a re-embedding run over an index
+// whose stored profile differs from the active one only in how vectors are
+// embedded. Until the run completes the old profile stays stored, so search
+// remains blocked and an interrupted run can be resumed.
+func (s *Service) ensureEmbeddingProfileForReembed() error {
+	current := CurrentEmbeddingProfile(s.session.Config)
+	stored, err := LoadEmbeddingProfile(s.session.DB, s.session.Config.DataDir)
+	if err != nil || stored == nil {
+		return err
+	}
+	if !stored.reembeddable(current) {
+		return &EmbeddingProfileMismatchError{
+			Reason:  "stored embedding profile cannot be re-embedded in place",
+			Stored:  stored,
+			Current: current,
+		}
+	}
+	return nil
+}
+
+// legacyEmbedVersion is the version assumed for chunks indexed before
+// versions were recorded: that of the stored profile, or none.
+func (s *Service) legacyEmbedVersion() (string, error) {
+	stored, err := LoadEmbeddingProfile(s.session.DB, s.session.Config.DataDir)
+	if err != nil || stored == nil {
+		return "", err
+	}
+	return stored.EmbedVersion(), nil
+}
+
 func (s *Service) saveCurrentEmbeddingProfile() error {
 	return SaveEmbeddingProfile(s.session.DB, s.session.Config.DataDir, CurrentEmbeddingProfile(s.session.Config))
 }
@@ -2,6 +2,7 @@ package app
 
 import (
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/abdul-hamid-achik/vecgrep/internal/config"
@@ -34,11 +35,101 @@ func TestEmbeddingProfileProtectsSemanticTemplates(t *testing.T) {
 func TestEmbeddingProfileIdentityTracksLosslessChunker(t *testing.T) {
 	cfg := config.DefaultConfig()
 	profile := CurrentEmbeddingProfile(cfg)
-	if profile.ProfileID != "ollama:nomic-embed-text:768:cosine:code-chunker-v2-lossless" {
+	if profile.ProfileID != "ollama:nomic-embed-text:768:cosine:code-chunker-v2-lossless:l2" {
 		t.Fatalf("ProfileID = %q, want lossless chunker identity", profile.ProfileID)
 	}
 }
 
+func TestEmbeddingProfileTracksQuantization(t *testing.T) {
+	cfg := config.DefaultConfig()
+	baseline := CurrentEmbeddingProfile(cfg)
+
+	cfg.Vector.VecLite.Quantization = "none"
+	if none := CurrentEmbeddingProfile(cfg); !baseline.Matches(none) {
+		t.Fatal("explicit none quantization must match full-precision indexes")
+	}
+
+	cfg.Vector.VecLite.Quantization = "int8"
+	int8Profile := CurrentEmbeddingProfile(cfg)
+	if baseline.Matches(int8Profile) || baseline.ProfileID == int8Profile.ProfileID {
+		t.Fatal("quantization change must require an index rebuild")
+	}
+	cfg.Vector.VecLite.Quantization = "binary"
+	if binary := CurrentEmbeddingProfile(cfg); binary.Matches(int8Profile) {
+		t.Fatal("switching quantization modes must require an index rebuild")
+	}
+}
+
+func TestEmbeddingProfileTracksVectorBackend(t *testing.T) {
+	cfg := config.DefaultConfig()
+	baseline := CurrentEmbeddingProfile(cfg)
+
+	cfg.Vector.Backend = "veclite"
+	if veclite := CurrentEmbeddingProfile(cfg); !baseline.Matches(veclite) {
+		t.Fatal("explicit veclite backend must match existing indexes")
+	}
+	cfg.Vector.Backend = "qdrant"
+	qdrant := CurrentEmbeddingProfile(cfg)
+	if baseline.Matches(qdrant) || baseline.ProfileID == qdrant.ProfileID {
+		t.Fatal("switching to qdrant must require an index rebuild")
+	}
+	cfg.Vector.Backend = "pgvector"
+	if pgvector := CurrentEmbeddingProfile(cfg); qdrant.Matches(pgvector) || baseline.Matches(pgvector) {
+		t.Fatal("switching to pgvector must require an index rebuild")
+	}
+}
+
+func TestEmbeddingProfileEmbedVersion(t *testing.T) {
+	cfg := config.DefaultConfig()
+	baseline := CurrentEmbeddingProfile(cfg)
+	if got := baseline.EmbedVersion(); got != "ollama/nomic-embed-text@768" {
+		t.Fatalf("EmbedVersion() = %q, want provider/model@dims", got)
+	}
+
+	cfg.Embedding.QueryTemplate = "query: {{text}}"
+	if got := CurrentEmbeddingProfile(cfg).EmbedVersion(); got != baseline.EmbedVersion() {
+		t.Fatalf("query template changed EmbedVersion to %q; stored vectors are unaffected", got)
+	}
+	cfg.Embedding.DocumentTemplate = "document: {{text}}"
+	templated := CurrentEmbeddingProfile(cfg)
+	if templated.EmbedVersion() == baseline.EmbedVersion() {
+		t.Fatal("document template change did not change EmbedVersion")
+	}
+	if !baseline.reembeddable(templated) {
+		t.Fatal("document template change should be reembeddable in place")
+	}
+
+	cfg.Embedding.Dimensions = 1024
+	if baseline.reembeddable(CurrentEmbeddingProfile(cfg)) {
+		t.Fatal("dimension change must require an index rebuild")
+	}
+}
+
+func TestEmbeddingProfileRecordsNormalization(t *testing.T) {
+	current := CurrentEmbeddingProfile(config.DefaultConfig())
+	if current.Normalization != "l2" {
+		t.Fatalf("Normalization = %q, want l2", current.Normalization)
+	}
+
+	// An index built before the policy was recorded used cosine distance,
+	// which ignores vector length, so it still matches.
+	legacyCfg := config.DefaultConfig()
+	legacyCfg.Embedding.QueryTemplate = "query: {text}"
+	active := CurrentEmbeddingProfile(legacyCfg)
+	legacy := active
+	legacy.Normalization = ""
+	legacy.ProfileID = strings.Replace(active.ProfileID, ":l2", "", 1)
+	if !legacy.Matches(active) || !active.Matches(legacy) || !legacy.reembeddable(active) {
+		t.Fatalf("legacy profile %q should match %q", legacy.ProfileID, active.ProfileID)
+	}
+
+	other := current
+	other.Normalization = "none"
+	if other.Matches(current) {
+		t.Fatal("profile with another normalization policy should not match the active profile")
+	}
+}
+
 func TestEmbeddingProfileCanonicalizesOllamaOptions(t *testing.T) {
 	first := config.DefaultConfig()
 	first.Embedding.OllamaOptions = map[string]any{
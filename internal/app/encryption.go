@@ -0,0 +1,107 @@
+package app
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+// EncryptionKeyEnv holds the index encryption key, base64 or hex encoded.
+const EncryptionKeyEnv = "VECGREP_ENCRYPTION_KEY"
+
+// Keychain entry that holds the encryption key when EncryptionKeyEnv is
+// unset.
+const (
+	keychainService = "vecgrep"
+	keychainAccount = "encryption-key"
+)
+
+var (
+	encryptionKeyOnce sync.Once
+	encryptionKey     []byte
+	encryptionKeyErr  error
+)
+
+// EncryptionOptions returns the at-rest encryption for db.OpenOptions, or
+// nil when security.encrypt is off. The key is looked up once per process.
+func EncryptionOptions(cfg *config.Config) *db.EncryptionConfig {
+	if cfg == nil || !cfg.Security.Encrypt {
+		return nil
+	}
+	return &db.EncryptionConfig{Key: func() ([]byte, error) {
+		encryptionKeyOnce.Do(func() {
+			encryptionKey, encryptionKeyErr = loadEncryptionKey()
+		})
+		return encryptionKey, encryptionKeyErr
+	}}
+}
+
+// loadEncryptionKey reads the key from EncryptionKeyEnv, or else from the OS
+// keychain: the login keychain on macOS, the Secret Service (secret-tool) on
+// Linux.
+func loadEncryptionKey() ([]byte, error) {
+	if value := strings.TrimSpace(os.Getenv(EncryptionKeyEnv)); value != "" {
+		key, err := decodeEncryptionKey(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", EncryptionKeyEnv, err)
+		}
+		return key, nil
+	}
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("security", "find-generic-password", "-s", keychainService, "-a", keychainAccount, "-w")
+	case "linux", "freebsd", "openbsd":
+		cmd = exec.Command("secret-tool", "lookup", "service", keychainService, "account", keychainAccount)
+	default:
+		return nil, fmt.Errorf("security.encrypt is set but %s is not; no keychain is supported on %s", EncryptionKeyEnv, runtime.GOOS)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	value := strings.TrimSpace(string(out))
+	if err != nil || value == "" {
+		if errors.Is(err, exec.ErrNotFound) {
+			err = fmt.Errorf("%s not found", cmd.Path)
+		} else if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			err = errors.New(msg)
+		} else if err == nil {
+			err = errors.New("no key stored")
+		}
+		return nil, fmt.Errorf("security.encrypt is set but %s is not and the keychain lookup (service %q, account %q) failed: %w",
+			EncryptionKeyEnv, keychainService, keychainAccount, err)
+	}
+	key, err := decodeEncryptionKey(value)
+	if err != nil {
+		return nil, fmt.Errorf("keychain encryption key: %w", err)
+	}
+	return key, nil
+}
+
+// decodeEncryptionKey accepts a 32-byte key as base64 (standard or URL
+// alphabet, padded or not) or hex.
+func decodeEncryptionKey(value string) ([]byte, error) {
+	if key, err := hex.DecodeString(value); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if key, err := enc.DecodeString(value); err == nil {
+			if len(key) != 32 {
+				return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+			}
+			return key, nil
+		}
+	}
+	return nil, errors.New("key must be 32 bytes encoded as base64 or hex (e.g. 'openssl rand -base64 32')")
+}
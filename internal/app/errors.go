@@ -7,4 +7,6 @@ var (
 	ErrMigrationRequired        = errors.New("legacy vecgrep database found without a veclite index")
 	ErrProviderRequired         = errors.New("embedding provider required")
 	ErrEmbeddingProfileMismatch = errors.New("embedding profile mismatch")
+	ErrIndexStale               = errors.New("index is not fresh")
+	ErrSymbolNotFound           = errors.New("symbol not found in the index")
 )
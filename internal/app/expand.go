@@ -0,0 +1,24 @@
+package app
+
+import (
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// NewSearchExpander builds the query expander for --expand searches: the
+// Ollama model in search.expander_model, or nil for the built-in synonym
+// templates. The expander URL defaults to the embedding provider's Ollama
+// URL.
+func NewSearchExpander(cfg *config.Config) search.QueryExpander {
+	if cfg == nil {
+		return nil
+	}
+	url := cfg.Search.ExpanderURL
+	if url == "" {
+		url = cfg.Embedding.OllamaURL
+	}
+	if expander := search.NewOllamaExpander(url, cfg.Search.ExpanderModel, 0); expander != nil {
+		return expander
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	embeddingbench "github.com/abdul-hamid-achik/vecgrep/internal/benchmark"
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+const (
+	feedbackSchemaVersion = 1
+	feedbackFilename      = "feedback.json"
+
+	// feedbackBoost is added to the score of a result judged relevant for
+	// the query being searched again; scores stay capped at 1.
+	feedbackBoost = 0.2
+	// feedbackDemotion scales the score of a result judged irrelevant.
+	feedbackDemotion = 0.5
+
+	// Result.Feedback values.
+	FeedbackRelevant   = "relevant"
+	FeedbackIrrelevant = "irrelevant"
+)
+
+// Judgment records whether a chunk answered a query. Judgments are keyed by
+// the normalized query and the chunk's location (file:start-end), like
+// bookmarks, because chunk IDs are reassigned when a file is re-indexed.
+// Content keeps the chunk text as judged so the judgments can be exported as
+// an eval dataset after the code has moved on.
+type Judgment struct {
+	ID        uint64    `json:"id"`
+	Query     string    `json:"query"`
+	Key       string    `json:"key"`
+	File      string    `json:"file"`
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Relevant  bool      `json:"relevant"`
+	Language  string    `json:"language,omitempty"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JudgmentInput describes a judgment to record.
+type JudgmentInput struct {
+	Query    string
+	Key      string
+	Relevant bool
+	Language string
+	Content  string
+}
+
+type feedbackFile struct {
+	SchemaVersion int        `json:"schema_version"`
+	NextID        uint64     `json:"next_id"`
+	Judgments     []Judgment `json:"judgments"`
+}
+
+// feedbackMu serializes read-modify-write cycles within one process.
+var feedbackMu sync.Mutex
+
+// FeedbackPath returns the search feedback file for a data directory.
+func FeedbackPath(dataDir string) string {
+	return filepath.Join(dataDir, feedbackFilename)
+}
+
+// NormalizeFeedbackQuery reduces a query to the form judgments are keyed
+// by: inline filters stripped, lowercased, and whitespace collapsed, so
+// "Retry  Backoff lang:go" and "retry backoff" share judgments.
+func NormalizeFeedbackQuery(query string) string {
+	query, _ = search.ApplyInlineQuery(query, search.SearchOptions{})
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// FeedbackChunk loads the chunk a judgment is about from the index. target
+// is a chunk ID as printed by `search -f json`, or a file:start-end location
+// that must match an indexed chunk exactly.
+func FeedbackChunk(database *db.DB, projectRoot, target string) (*db.ChunkRecord, error) {
+	if database == nil {
+		return nil, fmt.Errorf("index not open")
+	}
+	if id, err := strconv.ParseInt(strings.TrimSpace(target), 10, 64); err == nil {
+		chunk, err := database.Backend().GetChunkByID(id)
+		if err != nil || chunk == nil || chunk.ProjectRoot != projectRoot || chunk.Ref != "" {
+			return nil, fmt.Errorf("chunk %d is not in this project's index; search again for current chunk IDs", id)
+		}
+		return chunk, nil
+	}
+	file, startLine, endLine, err := ParseChunkKey(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chunk %q: expected a chunk ID or file:start-end", target)
+	}
+	chunks, err := database.GetChunksByFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read chunks of %s: %w", file, err)
+	}
+	for i := range chunks {
+		chunk := &chunks[i]
+		if chunk.ProjectRoot == projectRoot && chunk.Ref == "" && chunk.StartLine == startLine && chunk.EndLine == endLine {
+			return chunk, nil
+		}
+	}
+	return nil, fmt.Errorf("no indexed chunk spans %s", ChunkKey(file, startLine, endLine))
+}
+
+// RecordFeedback stores a relevance judgment. Judging a chunk again for the
+// same query replaces the earlier judgment.
+func RecordFeedback(dataDir string, input JudgmentInput) (Judgment, error) {
+	query := NormalizeFeedbackQuery(input.Query)
+	if query == "" {
+		return Judgment{}, fmt.Errorf("feedback needs the query the chunk was judged for")
+	}
+	file, startLine, endLine, err := ParseChunkKey(input.Key)
+	if err != nil {
+		return Judgment{}, err
+	}
+	key := ChunkKey(file, startLine, endLine)
+
+	feedbackMu.Lock()
+	defer feedbackMu.Unlock()
+	stored, err := loadFeedback(dataDir)
+	if err != nil {
+		return Judgment{}, err
+	}
+	now := time.Now().UTC()
+	for i := range stored.Judgments {
+		j := &stored.Judgments[i]
+		if j.Query != query || j.Key != key {
+			continue
+		}
+		j.Relevant = input.Relevant
+		j.Language = input.Language
+		j.Content = input.Content
+		j.UpdatedAt = now
+		if err := writeFeedback(dataDir, stored); err != nil {
+			return Judgment{}, err
+		}
+		return *j, nil
+	}
+
+	stored.NextID++
+	j := Judgment{
+		ID:        stored.NextID,
+		Query:     query,
+		Key:       key,
+		File:      file,
+		StartLine: startLine,
+		EndLine:   endLine,
+		Relevant:  input.Relevant,
+		Language:  input.Language,
+		Content:   input.Content,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	stored.Judgments = append(stored.Judgments, j)
+	if err := writeFeedback(dataDir, stored); err != nil {
+		return Judgment{}, err
+	}
+	return j, nil
+}
+
+// ListFeedback returns the judgments for query, or all judgments when query
+// is empty, oldest first.
+func ListFeedback(dataDir, query string) ([]Judgment, error) {
+	feedbackMu.Lock()
+	defer feedbackMu.Unlock()
+	stored, err := loadFeedback(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	query = NormalizeFeedbackQuery(query)
+	out := make([]Judgment, 0, len(stored.Judgments))
+	for _, j := range stored.Judgments {
+		if query == "" || j.Query == query {
+			out = append(out, j)
+		}
+	}
+	return out, nil
+}
+
+// ApplySearchFeedback re-scores results of a query that has judgments:
+// chunks judged relevant gain feedbackBoost, chunks judged irrelevant keep
+// feedbackDemotion of their score, and the results are re-sorted. Adjusted
+// results are marked with Result.Feedback. Feedback never fails a search; a
+// store that cannot be read returns the results unchanged with a warning.
+func ApplySearchFeedback(dataDir, query string, results []search.Result) ([]search.Result, string) {
+	if len(results) == 0 || strings.TrimSpace(dataDir) == "" {
+		return results, ""
+	}
+	judgments, err := ListFeedback(dataDir, query)
+	if err != nil {
+		return results, fmt.Sprintf("search feedback ignored: %v", err)
+	}
+	if len(judgments) == 0 || NormalizeFeedbackQuery(query) == "" {
+		return results, ""
+	}
+	relevant := make(map[string]bool, len(judgments))
+	for _, j := range judgments {
+		relevant[j.Key] = j.Relevant
+	}
+	adjusted := false
+	for i := range results {
+		r := &results[i]
+		if r.Annotation || r.Root != "" || r.Project != "" || r.Ref != "" {
+			continue
+		}
+		isRelevant, judged := relevant[ChunkKey(r.RelativePath, r.StartLine, r.EndLine)]
+		if !judged {
+			continue
+		}
+		adjusted = true
+		if isRelevant {
+			r.Score = min(r.Score+feedbackBoost, 1)
+			r.Feedback = FeedbackRelevant
+		} else {
+			r.Score *= feedbackDemotion
+			r.Feedback = FeedbackIrrelevant
+		}
+	}
+	if adjusted {
+		sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	}
+	return results, ""
+}
+
+// FeedbackDataset exports the judgments as an embedding benchmark dataset
+// (`vecgrep benchmark embeddings --dataset`): every judged chunk becomes a
+// document, and every query with at least one relevant chunk becomes a
+// query labelled with those chunks. Chunks judged only irrelevant stay in as
+// distractors.
+func FeedbackDataset(dataDir string) (embeddingbench.Dataset, error) {
+	judgments, err := ListFeedback(dataDir, "")
+	if err != nil {
+		return embeddingbench.Dataset{}, err
+	}
+	dataset := embeddingbench.Dataset{Version: embeddingbench.DatasetVersion}
+	documents := make(map[string]int)
+	queries := make(map[string]int)
+	for _, j := range judgments {
+		language := j.Language
+		if language == "" {
+			language = "unknown"
+		}
+		if i, ok := documents[j.Key]; ok {
+			// The latest judgment has the chunk text closest to the code.
+			dataset.Documents[i].Text, dataset.Documents[i].Language = j.Content, language
+		} else {
+			documents[j.Key] = len(dataset.Documents)
+			dataset.Documents = append(dataset.Documents, embeddingbench.Document{ID: j.Key, Language: language, Text: j.Content})
+		}
+		if !j.Relevant {
+			continue
+		}
+		i, ok := queries[j.Query]
+		if !ok {
+			i = len(dataset.Queries)
+			queries[j.Query] = i
+			dataset.Queries = append(dataset.Queries, embeddingbench.Query{ID: fmt.Sprintf("feedback-%d", i+1), Text: j.Query})
+		}
+		dataset.Queries[i].Relevant = append(dataset.Queries[i].Relevant, j.Key)
+	}
+	if len(dataset.Queries) == 0 {
+		return embeddingbench.Dataset{}, fmt.Errorf("no chunks have been judged relevant yet; record some with 'vecgrep feedback <chunk> --query ... --relevant'")
+	}
+	return dataset, nil
+}
+
+func loadFeedback(dataDir string) (*feedbackFile, error) {
+	if strings.TrimSpace(dataDir) == "" {
+		return nil, fmt.Errorf("feedback data dir is empty")
+	}
+	data, err := os.ReadFile(FeedbackPath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return &feedbackFile{SchemaVersion: feedbackSchemaVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read feedback: %w", err)
+	}
+	var stored feedbackFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode feedback: %w", err)
+	}
+	if stored.SchemaVersion != feedbackSchemaVersion {
+		return nil, fmt.Errorf("unsupported feedback schema version %d", stored.SchemaVersion)
+	}
+	return &stored, nil
+}
+
+func writeFeedback(dataDir string, stored *feedbackFile) error {
+	if err := writeJSONAtomic(dataDir, FeedbackPath(dataDir), stored); err != nil {
+		return fmt.Errorf("write feedback: %w", err)
+	}
+	return nil
+}
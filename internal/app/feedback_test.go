@@ -0,0 +1,105 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	embeddingbench "github.com/abdul-hamid-achik/vecgrep/internal/benchmark"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+func TestNormalizeFeedbackQuery(t *testing.T) {
+	if got := NormalizeFeedbackQuery("  Retry   Backoff lang:go "); got != "retry backoff" {
+		t.Fatalf("NormalizeFeedbackQuery = %q", got)
+	}
+}
+
+func TestFeedbackRecordListAndApply(t *testing.T) {
+	dataDir := t.TempDir()
+
+	if _, err := RecordFeedback(dataDir, JudgmentInput{Key: "a.go:1-5", Relevant: true}); err == nil {
+		t.Fatal("RecordFeedback without a query should fail")
+	}
+	first, err := RecordFeedback(dataDir, JudgmentInput{Query: "rate limiting", Key: "./limit.go:10-20", Relevant: false, Content: "func limit() {}", Language: "go"})
+	if err != nil {
+		t.Fatalf("RecordFeedback: %v", err)
+	}
+	if first.Key != "limit.go:10-20" {
+		t.Fatalf("key = %q, want normalized location", first.Key)
+	}
+	// Judging again for the same query replaces the earlier judgment.
+	again, err := RecordFeedback(dataDir, JudgmentInput{Query: "Rate  Limiting", Key: "limit.go:10-20", Relevant: true, Content: "func limit() {}", Language: "go"})
+	if err != nil {
+		t.Fatalf("re-record: %v", err)
+	}
+	if again.ID != first.ID || !again.Relevant {
+		t.Fatalf("re-record = %+v, want relevant update of %d", again, first.ID)
+	}
+	if _, err := RecordFeedback(dataDir, JudgmentInput{Query: "rate limiting", Key: "noise.go:1-3", Relevant: false, Content: "var noise = 1"}); err != nil {
+		t.Fatalf("RecordFeedback: %v", err)
+	}
+	if _, err := RecordFeedback(dataDir, JudgmentInput{Query: "other", Key: "noise.go:1-3", Relevant: true, Content: "var noise = 1"}); err != nil {
+		t.Fatalf("RecordFeedback: %v", err)
+	}
+
+	if got, _ := ListFeedback(dataDir, ""); len(got) != 3 {
+		t.Fatalf("list all = %d judgments, want 3", len(got))
+	}
+	if got, _ := ListFeedback(dataDir, "rate limiting path:internal/**"); len(got) != 2 {
+		t.Fatalf("list for query = %d judgments, want 2", len(got))
+	}
+
+	results := []search.Result{
+		{RelativePath: "noise.go", StartLine: 1, EndLine: 3, Score: 0.9},
+		{RelativePath: "other.go", StartLine: 1, EndLine: 9, Score: 0.7},
+		{RelativePath: "limit.go", StartLine: 10, EndLine: 20, Score: 0.6},
+	}
+	results, warning := ApplySearchFeedback(dataDir, "rate limiting", results)
+	if warning != "" {
+		t.Fatalf("warning = %q", warning)
+	}
+	order := []string{results[0].RelativePath, results[1].RelativePath, results[2].RelativePath}
+	if order[0] != "limit.go" || order[1] != "other.go" || order[2] != "noise.go" {
+		t.Fatalf("order after feedback = %v", order)
+	}
+	if results[0].Feedback != FeedbackRelevant || results[2].Feedback != FeedbackIrrelevant || results[1].Feedback != "" {
+		t.Fatalf("feedback markers = %q, %q, %q", results[0].Feedback, results[1].Feedback, results[2].Feedback)
+	}
+
+	// Unjudged queries leave results alone.
+	untouched := []search.Result{{RelativePath: "noise.go", StartLine: 1, EndLine: 3, Score: 0.9}}
+	if got, _ := ApplySearchFeedback(dataDir, "unrelated", untouched); got[0].Score != 0.9 || got[0].Feedback != "" {
+		t.Fatalf("unjudged query = %+v", got[0])
+	}
+}
+
+func TestFeedbackDatasetLoadsAsBenchmark(t *testing.T) {
+	dataDir := t.TempDir()
+	if _, err := FeedbackDataset(dataDir); err == nil {
+		t.Fatal("exporting an empty store should fail")
+	}
+	for _, in := range []JudgmentInput{
+		{Query: "rate limiting", Key: "limit.go:10-20", Relevant: true, Content: "func limit() {}", Language: "go"},
+		{Query: "rate limiting", Key: "noise.go:1-3", Relevant: false, Content: "var noise = 1"},
+	} {
+		if _, err := RecordFeedback(dataDir, in); err != nil {
+			t.Fatalf("RecordFeedback: %v", err)
+		}
+	}
+
+	dataset, err := FeedbackDataset(dataDir)
+	if err != nil {
+		t.Fatalf("FeedbackDataset: %v", err)
+	}
+	if len(dataset.Documents) != 2 || len(dataset.Queries) != 1 || len(dataset.Queries[0].Relevant) != 1 {
+		t.Fatalf("dataset = %+v", dataset)
+	}
+	data, err := json.Marshal(dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := embeddingbench.LoadDataset(bytes.NewReader(data), t.TempDir()); err != nil {
+		t.Fatalf("exported dataset does not load: %v", err)
+	}
+}
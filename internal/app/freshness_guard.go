@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RequireFresh returns an error wrapping ErrIndexStale unless the index
+// reflects the working tree: no files are new, modified, or deleted since they
+// were indexed and, when maxAge is positive, the last successful index run is
+// no older than maxAge. It backs search --require-fresh.
+//
+// Pending changes are proven from raw source hashes, so an index written
+// before they were recorded cannot be shown fresh until it is rebuilt.
+func (s *Service) RequireFresh(ctx context.Context, maxAge time.Duration) error {
+	if s == nil || s.session == nil || s.session.DB == nil || s.session.Config == nil {
+		return fmt.Errorf("service not initialized")
+	}
+	pending, complete, err := s.rawPendingChanges(ctx)
+	if err != nil {
+		return fmt.Errorf("check pending changes: %w", err)
+	}
+	if !complete {
+		return fmt.Errorf("%w: the index has no raw source hashes to compare; run 'vecgrep index --full'", ErrIndexStale)
+	}
+	if pending != nil && pending.TotalPending > 0 {
+		return fmt.Errorf("%w: %d pending change(s) (%d new, %d modified, %d deleted); run 'vecgrep index'",
+			ErrIndexStale, pending.TotalPending, pending.NewFiles, pending.ModifiedFiles, pending.DeletedFiles)
+	}
+	if maxAge <= 0 {
+		return nil
+	}
+
+	lastRun, err := s.lastIndexRun()
+	if err != nil {
+		return err
+	}
+	if lastRun.IsZero() {
+		return fmt.Errorf("%w: the project has never been indexed; run 'vecgrep index'", ErrIndexStale)
+	}
+	if age := time.Since(lastRun); age > maxAge {
+		return fmt.Errorf("%w: last index run was %s ago (at %s), older than %s; run 'vecgrep index'",
+			ErrIndexStale, age.Round(time.Second), lastRun.Local().Format(time.RFC3339), maxAge)
+	}
+	return nil
+}
+
+// lastIndexRun returns when the project was last indexed successfully: the
+// ingestion receipt when one was written, otherwise the newest per-file
+// indexed_at. It is zero for an empty index.
+func (s *Service) lastIndexRun() (time.Time, error) {
+	receipt, err := LoadIngestionReceipt(s.session.Config.DataDir, s.session.ProjectRoot)
+	if err == nil && receipt != nil && receipt.LastSuccess != nil {
+		return *receipt.LastSuccess, nil
+	}
+	files, err := s.session.DB.ListFiles(s.session.ProjectRoot)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("list indexed files: %w", err)
+	}
+	var latest time.Time
+	for _, file := range files {
+		if file.IndexedAt.After(latest) {
+			latest = file.IndexedAt
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,35 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequireFresh(t *testing.T) {
+	session, service := createTestSession(t)
+	seedFreshnessIndex(t, session, false)
+	ctx := context.Background()
+
+	if err := service.RequireFresh(ctx, 0); err != nil {
+		t.Fatalf("RequireFresh(0) on a fresh index: %v", err)
+	}
+	if err := service.RequireFresh(ctx, time.Hour); err != nil {
+		t.Fatalf("RequireFresh(1h) right after indexing: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := service.RequireFresh(ctx, time.Millisecond); !errors.Is(err, ErrIndexStale) || !strings.Contains(err.Error(), "last index run") {
+		t.Fatalf("RequireFresh(1ms) error = %v, want stale by age", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(session.ProjectRoot, "extra.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.RequireFresh(ctx, 0); !errors.Is(err, ErrIndexStale) || !strings.Contains(err.Error(), "1 new") {
+		t.Fatalf("RequireFresh(0) with a new file error = %v, want pending change", err)
+	}
+}
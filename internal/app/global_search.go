@@ -0,0 +1,102 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// GlobalSearchResponse is the merged result of one query run against every
+// project registered in ~/.vecgrep/config.yaml.
+type GlobalSearchResponse struct {
+	// Results are ranked by score across projects; each carries Project.
+	Results []search.Result
+	// Searched counts projects that answered the query.
+	Searched int
+	// Warnings reports projects that were skipped (missing path, no index,
+	// locked database, profile mismatch) and per-project degraded modes.
+	Warnings []string
+}
+
+// SearchAllProjects runs req against every registered project and merges the
+// results by score. Each project is searched through its own read-only session
+// with its own provider and config, so a failing project is reported as a
+// warning instead of failing the whole search. Scores are only comparable
+// across projects that share a model; keyword-mode scores are normalized per
+// project.
+func SearchAllProjects(ctx context.Context, req SearchRequest) (*GlobalSearchResponse, error) {
+	projects, err := config.ListGlobalProjects()
+	if err != nil {
+		return nil, fmt.Errorf("list projects: %w", err)
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no projects registered in the global config; run 'vecgrep init' in each project")
+	}
+	names := make([]string, 0, len(projects))
+	for name := range projects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resp := &GlobalSearchResponse{}
+	perProject := make(map[string][]search.Result, len(names))
+	for _, name := range names {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		results, warnings, err := searchRegisteredProject(ctx, config.ExpandPath(projects[name].Path), req)
+		if err != nil {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		resp.Searched++
+		for _, w := range warnings {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf("%s: %s", name, w))
+		}
+		perProject[name] = results
+	}
+	resp.Results = mergeProjectResults(perProject, req.Limit)
+	return resp, nil
+}
+
+func searchRegisteredProject(ctx context.Context, projectPath string, req SearchRequest) ([]search.Result, []string, error) {
+	session, err := OpenReadOnlySession(ctx, projectPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer session.Close()
+	projectReq := req
+	projectReq.ProjectRoot = ""
+	projectReq.Mode = ParseSearchMode(string(req.Mode), session.Config.Search.DefaultMode)
+	resp, err := NewService(session).Search(ctx, projectReq)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Results, resp.Warnings, nil
+}
+
+// mergeProjectResults tags each result with its project and returns the top
+// limit results across projects, best score first. Ties keep project name
+// order so output is stable.
+func mergeProjectResults(perProject map[string][]search.Result, limit int) []search.Result {
+	names := make([]string, 0, len(perProject))
+	for name := range perProject {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var merged []search.Result
+	for _, name := range names {
+		for _, r := range perProject[name] {
+			r.Project = name
+			merged = append(merged, r)
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Score > merged[j].Score })
+	if limit > 0 && len(merged) > limit {
+		merged = merged[:limit]
+	}
+	return merged
+}
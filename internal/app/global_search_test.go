@@ -0,0 +1,28 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+func TestMergeProjectResultsRanksAcrossProjects(t *testing.T) {
+	merged := mergeProjectResults(map[string][]search.Result{
+		"billing": {{RelativePath: "pay.go", Score: 0.6}, {RelativePath: "refund.go", Score: 0.3}},
+		"auth":    {{RelativePath: "token.go", Score: 0.8}, {RelativePath: "session.go", Score: 0.6}},
+	}, 3)
+
+	if len(merged) != 3 {
+		t.Fatalf("merged = %d results, want 3", len(merged))
+	}
+	want := []struct{ project, path string }{
+		{"auth", "token.go"},
+		{"auth", "session.go"},
+		{"billing", "pay.go"},
+	}
+	for i, w := range want {
+		if merged[i].Project != w.project || merged[i].RelativePath != w.path {
+			t.Fatalf("merged[%d] = %s/%s, want %s/%s", i, merged[i].Project, merged[i].RelativePath, w.project, w.path)
+		}
+	}
+}
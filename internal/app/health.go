@@ -0,0 +1,204 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+// Health factors, each with the most it can take off the score. Together
+// they weigh 100, so an index failing every one of them completely scores 0.
+const (
+	HealthFactorModelMismatch = "model_mismatch"
+	HealthFactorStaleFiles    = "stale_files"
+	HealthFactorOrphaned      = "orphaned_records"
+	HealthFactorFragmentation = "fragmentation"
+	HealthFactorTruncated     = "truncated_chunks"
+
+	healthWeightModelMismatch = 40
+	healthWeightStaleFiles    = 25
+	healthWeightOrphaned      = 15
+	healthWeightFragmentation = 12
+	healthWeightTruncated     = 8
+)
+
+// Health grades, from the score: healthy from 90 up, fair from 70 up.
+const (
+	HealthGradeHealthy = "healthy"
+	HealthGradeFair    = "fair"
+	HealthGradePoor    = "needs maintenance"
+)
+
+// IndexHealth is a composite 0-100 score saying how much maintenance an index
+// needs, with one reason per factor that took points off, worst first.
+type IndexHealth struct {
+	Score   int            `json:"score"`
+	Grade   string         `json:"grade"`
+	Reasons []HealthReason `json:"reasons,omitempty"`
+}
+
+// HealthReason is one factor's deduction from an IndexHealth score and the
+// command that clears it.
+type HealthReason struct {
+	Factor  string `json:"factor"`
+	Penalty int    `json:"penalty"`
+	Detail  string `json:"detail"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// healthInputs are the index measurements an IndexHealth is scored from.
+type healthInputs struct {
+	files  int
+	chunks int64
+	// profileStatus is the embedding profile check's verdict; anything but
+	// "ok" or "not written yet" means the stored vectors may not match the
+	// configured model.
+	profileStatus  string
+	pending        *index.PendingChanges
+	orphanedChunks int64
+	truncated      int64
+	// estimatedTruncated counts the truncated chunks cut by estimated
+	// token counts.
+	estimatedTruncated int64
+	// rechunkFiles counts files chunked under other chunker settings, and
+	// reembedFiles those embedded under another embedding version.
+	rechunkFiles int
+	reembedFiles int
+}
+
+// Health scores the project's index; see IndexHealth. pending is the index's
+// pending changes, as IndexFreshness reports them, or nil when unknown.
+func (s *Service) Health(ctx context.Context, pending *index.PendingChanges) (*IndexHealth, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	detailed, err := s.session.DB.GetDetailedStats(s.session.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("get detailed stats: %w", err)
+	}
+	files, err := s.session.DB.ListFiles(s.session.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("list indexed files: %w", err)
+	}
+	current := CurrentEmbeddingProfile(s.session.Config)
+	stored, profileErr := LoadEmbeddingProfile(s.session.DB, s.session.Config.DataDir)
+	profileStatus, _ := embeddingProfileState(current, stored, profileErr, detailed.TotalChunks > 0)
+	return s.indexHealth(detailed, files, current, stored, profileStatus, pending), nil
+}
+
+// indexHealth gathers the health inputs Status and Health share.
+func (s *Service) indexHealth(detailed *db.Stats, files []db.FileInfo, current EmbeddingProfile, stored *EmbeddingProfile, profileStatus string, pending *index.PendingChanges) *IndexHealth {
+	in := healthInputs{
+		files:         len(files),
+		profileStatus: profileStatus,
+		pending:       pending,
+		rechunkFiles:  buildProvenanceReport(s.session.Config, files).StaleFiles,
+	}
+	if detailed != nil {
+		in.chunks = detailed.TotalChunks
+		in.orphanedChunks = detailed.OrphanedChunks
+		in.truncated = detailed.TruncatedChunks
+		in.estimatedTruncated = detailed.EstimatedTruncatedChunks
+	}
+	// A profile mismatch already marks every vector as suspect; only count
+	// files left behind under an older embedding version when it matches.
+	if profileStatus == "ok" && stored != nil {
+		version, legacy := current.EmbedVersion(), stored.EmbedVersion()
+		for _, file := range files {
+			if index.EmbeddingStale(file, version, legacy) {
+				in.reembedFiles++
+			}
+		}
+	}
+	return scoreIndexHealth(in)
+}
+
+// scoreIndexHealth deducts each factor's weight scaled by how much of the
+// index it affects, at least one point whenever it affects anything.
+func scoreIndexHealth(in healthInputs) *IndexHealth {
+	health := &IndexHealth{Score: 100}
+	deduct := func(factor string, weight int, share float64, detail, fix string) {
+		if share <= 0 {
+			return
+		}
+		penalty := max(1, int(math.Round(float64(weight)*min(share, 1))))
+		health.Reasons = append(health.Reasons, HealthReason{Factor: factor, Penalty: penalty, Detail: detail, Fix: fix})
+		health.Score -= penalty
+	}
+
+	if in.profileStatus != "" && in.profileStatus != "ok" && in.profileStatus != "not written yet" && in.chunks > 0 {
+		deduct(HealthFactorModelMismatch, healthWeightModelMismatch, 1,
+			fmt.Sprintf("embedding profile %s: stored vectors may not match the configured model", in.profileStatus),
+			"vecgrep reembed, or vecgrep index --full")
+	}
+	if p := in.pending; p != nil {
+		if changed := p.NewFiles + p.ModifiedFiles; changed > 0 {
+			deduct(HealthFactorStaleFiles, healthWeightStaleFiles, healthRatio(int64(changed), int64(max(in.files, 1))),
+				fmt.Sprintf("%d new and %d modified files are not indexed", p.NewFiles, p.ModifiedFiles),
+				"vecgrep index")
+		}
+	}
+	deleted := 0
+	if in.pending != nil {
+		deleted = in.pending.DeletedFiles
+	}
+	if deleted > 0 || in.orphanedChunks > 0 {
+		// An incremental index prunes deleted files, but chunks naming no
+		// file are only dropped by a rebuild.
+		fix := "vecgrep index"
+		if in.orphanedChunks > 0 {
+			fix = "vecgrep index --full"
+		}
+		deduct(HealthFactorOrphaned, healthWeightOrphaned,
+			max(healthRatio(int64(deleted), int64(in.files)), healthRatio(in.orphanedChunks, in.chunks)),
+			fmt.Sprintf("%d indexed files no longer exist and %d chunks name no file", deleted, in.orphanedChunks),
+			fix)
+	}
+	if in.rechunkFiles > 0 || in.reembedFiles > 0 {
+		fix := "vecgrep index --rechunk-stale"
+		switch {
+		case in.rechunkFiles == 0:
+			fix = "vecgrep reembed --only-stale"
+		case in.reembedFiles > 0:
+			fix += ", then vecgrep reembed --only-stale"
+		}
+		deduct(HealthFactorFragmentation, healthWeightFragmentation,
+			healthRatio(int64(max(in.rechunkFiles, in.reembedFiles)), int64(in.files)),
+			fmt.Sprintf("%d files chunked under other chunker settings and %d embedded under another embedding version", in.rechunkFiles, in.reembedFiles),
+			fix)
+	}
+	if in.truncated > 0 {
+		detail := fmt.Sprintf("%d of %d chunks were cut to fit the model's input limit", in.truncated, in.chunks)
+		if in.estimatedTruncated > 0 {
+			detail += fmt.Sprintf(" (%d by estimated token counts)", in.estimatedTruncated)
+		}
+		deduct(HealthFactorTruncated, healthWeightTruncated, healthRatio(in.truncated, in.chunks), detail,
+			"lower the chunk size, or use a model with a longer input limit")
+	}
+
+	health.Score = max(health.Score, 0)
+	switch {
+	case health.Score >= 90:
+		health.Grade = HealthGradeHealthy
+	case health.Score >= 70:
+		health.Grade = HealthGradeFair
+	default:
+		health.Grade = HealthGradePoor
+	}
+	sort.SliceStable(health.Reasons, func(i, j int) bool {
+		return health.Reasons[i].Penalty > health.Reasons[j].Penalty
+	})
+	return health
+}
+
+// healthRatio is n/total, or 0 when total is not positive.
+func healthRatio(n, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+func TestScoreIndexHealth(t *testing.T) {
+	healthy := scoreIndexHealth(healthInputs{files: 10, chunks: 40, profileStatus: "ok", pending: &index.PendingChanges{}})
+	if healthy.Score != 100 || healthy.Grade != HealthGradeHealthy || len(healthy.Reasons) != 0 {
+		t.Fatalf("clean index health = %+v", healthy)
+	}
+
+	health := scoreIndexHealth(healthInputs{
+		files:         10,
+		chunks:        40,
+		profileStatus: "ok",
+		pending:       &index.PendingChanges{ModifiedFiles: 2, DeletedFiles: 1, TotalPending: 3},
+		truncated:     1,
+		rechunkFiles:  5,
+	})
+	// stale 25*0.2=5, orphaned 15*0.1=2 (rounded), fragmentation 12*0.5=6,
+	// truncated at least 1.
+	if health.Score != 100-5-2-6-1 || health.Grade != HealthGradeFair {
+		t.Fatalf("health = %+v", health)
+	}
+	factors := make([]string, len(health.Reasons))
+	for i, reason := range health.Reasons {
+		factors[i] = reason.Factor
+	}
+	want := []string{HealthFactorFragmentation, HealthFactorStaleFiles, HealthFactorOrphaned, HealthFactorTruncated}
+	if len(factors) != len(want) {
+		t.Fatalf("reasons = %+v", health.Reasons)
+	}
+	for i := range want {
+		if factors[i] != want[i] {
+			t.Fatalf("reasons order = %v, want %v", factors, want)
+		}
+	}
+	if health.Reasons[0].Fix != "vecgrep index --rechunk-stale" {
+		t.Errorf("fragmentation fix = %q", health.Reasons[0].Fix)
+	}
+
+	mismatch := scoreIndexHealth(healthInputs{files: 10, chunks: 40, profileStatus: "mismatch", pending: &index.PendingChanges{NewFiles: 20}})
+	if mismatch.Score != 100-40-25 || mismatch.Grade != HealthGradePoor || mismatch.Reasons[0].Factor != HealthFactorModelMismatch {
+		t.Fatalf("mismatched index health = %+v", mismatch)
+	}
+}
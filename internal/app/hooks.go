@@ -0,0 +1,83 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// DefaultHookTimeout bounds one hook command when hooks.timeout is unset.
+const DefaultHookTimeout = 10 * time.Second
+
+// PostSearchEvent is the JSON document post_search hooks read on stdin.
+type PostSearchEvent struct {
+	Query       string          `json:"query"`
+	Mode        string          `json:"mode"`
+	ProjectRoot string          `json:"project_root"`
+	Results     []search.Result `json:"results"`
+}
+
+// RunPostSearchHooks runs each hooks.post_search command in the project root
+// with event on stdin and VECGREP_HOOK=post_search in the environment. Hook
+// stdout and stderr go to output (the CLI passes stderr, so hook chatter never
+// mixes with results on stdout). A failing hook does not stop later ones;
+// the failures are returned for the caller to report.
+func RunPostSearchHooks(ctx context.Context, hooks config.HooksConfig, event PostSearchEvent, output io.Writer) []error {
+	if len(hooks.PostSearch) == 0 {
+		return nil
+	}
+	if event.Results == nil {
+		event.Results = []search.Result{}
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return []error{fmt.Errorf("marshal post_search event: %w", err)}
+	}
+	timeout := hooks.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+
+	var errs []error
+	for _, hook := range hooks.PostSearch {
+		if err := runHook(ctx, hook, "post_search", event.ProjectRoot, payload, timeout, output, output); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// runHook runs command without a shell in dir with payload on stdin and
+// VECGREP_HOOK=name in the environment, killing it after timeout.
+func runHook(ctx context.Context, command, name, dir string, payload []byte, timeout time.Duration, stdout, stderr io.Writer) error {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Env = append(os.Environ(), "VECGREP_HOOK="+name, "VECGREP_PROJECT_ROOT="+dir)
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%s hook %q timed out after %s", name, args[0], timeout)
+		}
+		return fmt.Errorf("%s hook %q: %w", name, args[0], err)
+	}
+	return nil
+}
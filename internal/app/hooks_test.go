@@ -0,0 +1,62 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+func TestRunPostSearchHooksPassesEventOnStdin(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	projectRoot := t.TempDir()
+	hookScript := filepath.Join(projectRoot, "hook.sh")
+	if err := os.WriteFile(hookScript, []byte("#!/bin/sh\ncat > event.json\necho \"hook=$VECGREP_HOOK\"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var output bytes.Buffer
+	hooks := config.HooksConfig{PostSearch: []string{"sh " + hookScript, "sh -c false", hookScript}}
+	event := PostSearchEvent{
+		Query:       "retry backoff",
+		Mode:        "hybrid",
+		ProjectRoot: projectRoot,
+		Results:     []search.Result{{RelativePath: "net/retry.go", StartLine: 10}},
+	}
+	errs := RunPostSearchHooks(context.Background(), hooks, event, &output)
+
+	// The failing middle hook is reported without stopping the last one.
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `post_search hook "sh"`) {
+		t.Fatalf("errs = %v, want one failure from the middle hook", errs)
+	}
+	if strings.Count(output.String(), "hook=post_search") != 2 {
+		t.Fatalf("hook output = %q", output.String())
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectRoot, "event.json"))
+	if err != nil {
+		t.Fatalf("hook did not run in the project root: %v", err)
+	}
+	var got PostSearchEvent
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("decode event: %v", err)
+	}
+	if got.Query != "retry backoff" || got.Mode != "hybrid" || len(got.Results) != 1 || got.Results[0].RelativePath != "net/retry.go" {
+		t.Fatalf("event = %+v", got)
+	}
+}
+
+func TestRunPostSearchHooksWithoutHooksIsNoop(t *testing.T) {
+	if errs := RunPostSearchHooks(context.Background(), config.HooksConfig{}, PostSearchEvent{}, nil); errs != nil {
+		t.Fatalf("errs = %v", errs)
+	}
+}
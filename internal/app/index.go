@@ -3,6 +3,7 @@ package app
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"time"
 
 	"github.com/abdul-hamid-achik/vecgrep/internal/db"
@@ -16,6 +17,39 @@ type IndexRequest struct {
 	// StructuralChunks overrides codemap.structural_chunks for this run when
 	// non-empty (auto, off, or required).
 	StructuralChunks string
+	// RechunkStale replaces Paths with the indexed files whose chunk
+	// provenance differs from the current chunker settings and re-indexes
+	// them even though their content is unchanged.
+	RechunkStale bool
+	// Reembed replaces Paths with every indexed file and re-embeds them
+	// under the active embedding configuration, which may differ from the
+	// stored profile in provider, model, or templates. ReembedOnlyStale
+	// narrows it to files whose vectors carry another embedding version.
+	Reembed          bool
+	ReembedOnlyStale bool
+	// Events, when set, receives structured per-file indexing events.
+	Events index.FileEventCallback
+	// DeferEmbeddings chunks changed files into the embedding queue instead
+	// of embedding them, so the run needs no provider. EmbedPending embeds
+	// the queue later.
+	DeferEmbeddings bool
+	// Refs indexes these git refs instead of the working tree, each under
+	// its own project root; see IndexedRef.
+	Refs []string
+}
+
+// ReembedTargets returns the relative paths of the indexed files a Reembed
+// request would re-embed, without changing anything.
+func (s *Service) ReembedTargets(onlyStale bool) ([]string, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	absRoot, err := filepath.Abs(s.session.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("resolve project root for reembed: %w", err)
+	}
+	indexer := index.NewIndexer(s.session.DB, nil, BuildIndexerConfig(s.session.Config, nil))
+	return reembedTargets(s, indexer, absRoot, onlyStale)
 }
 
 type ResetScope string
@@ -86,6 +120,15 @@ func (s *Service) DeleteFile(ctx context.Context, path string) (int64, error) {
 	return s.session.DB.DeleteProjectFile(ctx, s.session.ProjectRoot, path)
 }
 
+// PreviewDeleteFile returns how many chunks DeleteFile would remove for path
+// without modifying the index.
+func (s *Service) PreviewDeleteFile(ctx context.Context, path string) (int64, error) {
+	if s == nil || s.session == nil {
+		return 0, fmt.Errorf("service not initialized")
+	}
+	return s.session.DB.CountProjectFile(ctx, s.session.ProjectRoot, path)
+}
+
 // DryRunPreview returns counts of files needing reindexing and an estimated
 // chunk count without calling the embedding provider. It is used by the
 // --dry-run flag on the index command to preview what would change.
@@ -113,6 +156,15 @@ func (s *Service) Clean(ctx context.Context) (*db.CleanStats, error) {
 	return s.session.DB.Clean(ctx)
 }
 
+// PreviewClean reports the index statistics Clean would print without
+// flushing the database.
+func (s *Service) PreviewClean(ctx context.Context) (*db.CleanStats, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	return s.session.DB.CleanPreview(ctx)
+}
+
 func (s *Service) Reset(ctx context.Context, scope ResetScope) error {
 	if s == nil || s.session == nil {
 		return fmt.Errorf("service not initialized")
@@ -127,9 +179,16 @@ func (s *Service) Reset(ctx context.Context, scope ResetScope) error {
 			return err
 		}
 	}
+	if err := s.resetIndexedRefs(ctx); err != nil {
+		return err
+	}
 	if err := RemoveEmbeddingProfileMeta(s.session.DB); err != nil {
 		return fmt.Errorf("remove embedding profile metadata: %w", err)
 	}
+	// Directory summaries describe the index they were built from.
+	if err := replaceDirectorySummaries(s.session.Config.DataDir, nil); err != nil {
+		return err
+	}
 	return RemoveEmbeddingProfile(s.session.Config.DataDir)
 }
 
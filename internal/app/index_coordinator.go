@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"path/filepath"
 	"slices"
 	"sync"
@@ -84,25 +84,20 @@ func (c *IndexCoordinator) indexLocked(ctx context.Context, req IndexRequest, pr
 	if c.cfg == nil || c.stores == nil {
 		return nil, fmt.Errorf("index coordinator is not configured")
 	}
-	if c.provider == nil {
-		return nil, ErrProviderRequired
-	}
-	if err := c.provider.Ping(ctx); err != nil {
-		return nil, fmt.Errorf("embedding provider unavailable: %w", err)
-	}
-
-	// Cache restoration is best-effort and only useful once per long-lived
-	// runtime. Warmup is retried per run because a transient model unload should
-	// not permanently disable it for a daemon.
-	c.restoreOnce.Do(func() {
-		borrowed := c.borrowedService(nil)
-		borrowed.maybeRestoreEmbeddingCache(ctx)
-	})
-	log.Printf("warming up embedding model")
-	if loadDur, err := c.provider.Warmup(ctx); err != nil {
-		log.Printf("model warmup skipped: %v", err)
+	if req.DeferEmbeddings {
+		// A full reindex resets the project first, which would leave nothing
+		// searchable until the queue is embedded.
+		if req.FullReindex {
+			return nil, fmt.Errorf("deferred embeddings cannot be combined with a full reindex")
+		}
 	} else {
-		log.Printf("model warmup complete (load_duration: %dms)", loadDur.Milliseconds())
+		if err := c.prepareProvider(ctx); err != nil {
+			return nil, err
+		}
+		c.tuneBatchSize(ctx, req.FullReindex)
+	}
+	if len(req.Refs) > 0 {
+		return c.indexRefsLocked(ctx, req, progress)
 	}
 
 	database, release, err := c.acquireIndexDB(ctx)
@@ -124,6 +119,52 @@ func (c *IndexCoordinator) indexLocked(ctx context.Context, req IndexRequest, pr
 	if err != nil {
 		return nil, err
 	}
+	// Rechunking targets only files whose provenance is stale, so the request
+	// narrows to those paths and forces them past the unchanged-hash skip.
+	if req.RechunkStale && !req.FullReindex {
+		absRoot, absErr := filepath.Abs(c.projectRoot)
+		if absErr != nil {
+			return nil, fmt.Errorf("resolve project root for rechunk: %w", absErr)
+		}
+		stale, staleErr := indexer.StaleProvenanceFiles(absRoot)
+		if staleErr != nil {
+			return nil, staleErr
+		}
+		// Deleted files are left for the next incremental run to prune; a
+		// path-scoped walk would fail on them.
+		req.Paths = nil
+		for _, rel := range stale {
+			if path := filepath.Join(absRoot, rel); fileExists(path) {
+				req.Paths = append(req.Paths, path)
+			}
+		}
+		if len(req.Paths) == 0 {
+			return &index.IndexResult{}, nil
+		}
+		indexer.ForceReindex(stale)
+	}
+	// Re-embedding works the same way, over every indexed file or only
+	// those whose vectors carry another embedding version.
+	if req.Reembed && !req.FullReindex {
+		absRoot, absErr := filepath.Abs(c.projectRoot)
+		if absErr != nil {
+			return nil, fmt.Errorf("resolve project root for reembed: %w", absErr)
+		}
+		targets, targetErr := reembedTargets(service, indexer, absRoot, req.ReembedOnlyStale)
+		if targetErr != nil {
+			return nil, targetErr
+		}
+		req.Paths = nil
+		for _, rel := range targets {
+			if path := filepath.Join(absRoot, rel); fileExists(path) {
+				req.Paths = append(req.Paths, path)
+			}
+		}
+		if len(req.Paths) == 0 {
+			return &index.IndexResult{}, nil
+		}
+		indexer.ForceReindex(targets)
+	}
 	// A project_dirty tombstone is durable evidence of an interrupted
 	// multi-collection mutation. Do not let an incremental run appear to repair
 	// it: only ReindexAll resets the project and can clear the marker. Requiring
@@ -143,6 +184,24 @@ func (c *IndexCoordinator) indexLocked(ctx context.Context, req IndexRequest, pr
 		return nil, err
 	}
 	scopeComplete := req.FullReindex || len(req.Paths) == 0
+	// Path-scoped runs (watch, explicit paths, rechunking) stay inside the
+	// project root; only whole-project runs walk the extra roots.
+	var extraRoots []ExtraRoot
+	if scopeComplete {
+		if extraRoots, err = ExtraRoots(c.projectRoot, c.cfg); err != nil {
+			return nil, err
+		}
+	}
+	var deferred index.DeferredEmbeddingSink
+	if req.DeferEmbeddings {
+		queue, err := openEmbedQueue(c.cfg.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		defer queue.Close()
+		deferred = queue.Append
+		indexer.SetDeferredEmbeddingSink(deferred)
+	}
 	// Poison the previous success before ensureEmbeddingProfileForIndex or the
 	// indexer can mutate collection metadata/chunks. Failure here is a hard
 	// preflight error and leaves the old searchable index untouched.
@@ -150,13 +209,25 @@ func (c *IndexCoordinator) indexLocked(ctx context.Context, req IndexRequest, pr
 		return nil, fmt.Errorf("begin ingestion receipt: %w", err)
 	}
 	indexer.SetIndexRunAttemptID(attemptID)
-	if err := service.ensureEmbeddingProfileForIndex(req.FullReindex); err != nil {
-		finalizeErr := finalizeIngestionReceiptAttempt(c.cfg.DataDir, c.projectRoot, attemptID, err)
-		return nil, errors.Join(err, finalizeErr)
+	profileErr := service.ensureEmbeddingProfileForIndex(req.FullReindex)
+	if req.Reembed && !req.FullReindex {
+		profileErr = service.ensureEmbeddingProfileForReembed()
+	}
+	if profileErr != nil {
+		finalizeErr := finalizeIngestionReceiptAttempt(c.cfg.DataDir, c.projectRoot, attemptID, profileErr)
+		return nil, errors.Join(profileErr, finalizeErr)
 	}
 	if progress != nil {
 		indexer.SetProgressCallback(progress)
 	}
+	if req.Events != nil {
+		indexer.SetFileEventCallback(req.Events)
+	}
+
+	meter := embed.UsageOf(c.provider)
+	budgetTokens := embed.BudgetTokens(c.cfg.Embedding.Provider, c.cfg.Embedding.Model, c.cfg.Embedding.BudgetUSD)
+	runStartedAt := time.Now()
+	usageStart := meter.BeginRun(budgetTokens)
 
 	var indexErr error
 	if req.FullReindex {
@@ -164,6 +235,11 @@ func (c *IndexCoordinator) indexLocked(ctx context.Context, req IndexRequest, pr
 	} else {
 		result, indexErr = indexer.Index(ctx, c.projectRoot, req.Paths...)
 	}
+	if indexErr == nil && len(extraRoots) > 0 {
+		indexErr = c.indexExtraRoots(ctx, database, extraRoots, req, progress, deferred, result)
+	}
+	meter.EndRun()
+	c.recordRunUsage(meter, usageStart, budgetTokens, runStartedAt, indexErr)
 	flushErr := flushProvider(c.provider)
 	if indexErr != nil {
 		if flushErr != nil {
@@ -219,10 +295,95 @@ func (c *IndexCoordinator) indexLocked(ctx context.Context, req IndexRequest, pr
 		return nil, errors.Join(finalizeErr, releaseErr, invalidateErr)
 	}
 
+	if result != nil {
+		if err := recordIndexDiff(c.cfg, c.projectRoot, req.FullReindex, result.ChunkDiff); err != nil {
+			slog.Warn("record index diff failed", "err", err)
+		}
+	}
 	service.maybeStashEmbeddingCache(ctx)
 	return result, nil
 }
 
+// indexExtraRoots indexes each extra root under its own project root and adds
+// its totals to result. Structural chunks and the ingestion receipt describe
+// the project root alone, so extra roots use a plain indexer.
+func (c *IndexCoordinator) indexExtraRoots(ctx context.Context, database *db.DB, roots []ExtraRoot, req IndexRequest, progress func(index.Progress), deferred index.DeferredEmbeddingSink, result *index.IndexResult) error {
+	for _, root := range roots {
+		indexer := index.NewIndexer(database, c.provider, BuildIndexerConfig(c.cfg, req.AdditionalIgnores))
+		if progress != nil {
+			indexer.SetProgressCallback(progress)
+		}
+		if req.Events != nil {
+			indexer.SetFileEventCallback(req.Events)
+		}
+		if deferred != nil {
+			indexer.SetDeferredEmbeddingSink(deferred)
+		}
+		var (
+			rootResult *index.IndexResult
+			err        error
+		)
+		if req.FullReindex {
+			rootResult, err = indexer.ReindexAll(ctx, root.Path)
+		} else {
+			rootResult, err = indexer.Index(ctx, root.Path)
+		}
+		if err != nil {
+			return fmt.Errorf("index extra root %s: %w", root.Name, err)
+		}
+		if result == nil || rootResult == nil {
+			continue
+		}
+		result.FilesProcessed += rootResult.FilesProcessed
+		result.FilesSkipped += rootResult.FilesSkipped
+		result.FilesDeleted += rootResult.FilesDeleted
+		result.ChunksCreated += rootResult.ChunksCreated
+		result.ChunksReused += rootResult.ChunksReused
+		result.ChunksTruncated += rootResult.ChunksTruncated
+		result.ChunksSplit += rootResult.ChunksSplit
+		result.TokensEstimated = result.TokensEstimated || rootResult.TokensEstimated
+		result.ChunksWithSecrets += rootResult.ChunksWithSecrets
+		result.ChunksDeferred += rootResult.ChunksDeferred
+		result.Duration += rootResult.Duration
+		if rootResult.ChunkDiff != nil {
+			if result.ChunkDiff == nil {
+				result.ChunkDiff = &index.ChunkDiff{Files: []index.FileChunkDiff{}}
+			}
+			result.ChunkDiff.Merge(rootResult.ChunkDiff, root.Name)
+		}
+		for _, rootErr := range rootResult.Errors {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", root.Name, rootErr))
+		}
+	}
+	return nil
+}
+
+// prepareProvider checks that the embedding provider is reachable and warms
+// it up before a run that embeds.
+func (c *IndexCoordinator) prepareProvider(ctx context.Context) error {
+	if c.provider == nil {
+		return ErrProviderRequired
+	}
+	if err := c.provider.Ping(ctx); err != nil {
+		return fmt.Errorf("embedding provider unavailable: %w", err)
+	}
+
+	// Cache restoration is best-effort and only useful once per long-lived
+	// runtime. Warmup is retried per run because a transient model unload should
+	// not permanently disable it for a daemon.
+	c.restoreOnce.Do(func() {
+		borrowed := c.borrowedService(nil)
+		borrowed.maybeRestoreEmbeddingCache(ctx)
+	})
+	slog.Info("warming up embedding model")
+	if loadDur, err := c.provider.Warmup(ctx); err != nil {
+		slog.Info("model warmup skipped", "err", err)
+	} else {
+		slog.Info("model warmup complete", "load_duration_ms", loadDur.Milliseconds())
+	}
+	return nil
+}
+
 func (c *IndexCoordinator) acquireIndexDB(ctx context.Context) (*db.DB, func() error, error) {
 	if c == nil || c.stores == nil {
 		return nil, nil, fmt.Errorf("index coordinator is not configured")
@@ -251,6 +412,26 @@ func (c *IndexCoordinator) acquireIndexDB(ctx context.Context) (*db.DB, func() e
 	return lease.DB, release, nil
 }
 
+// recordRunUsage persists the embedding traffic of one indexing run. Usage is
+// an estimate for status reporting, so a write failure is logged rather than
+// failing the run.
+func (c *IndexCoordinator) recordRunUsage(meter *embed.UsageMeter, start embed.Usage, budgetTokens int64, startedAt time.Time, runErr error) {
+	if meter == nil {
+		return
+	}
+	delta := meter.Snapshot().Sub(start)
+	run := &EmbeddingUsageRun{
+		StartedAt:      startedAt.UTC(),
+		FinishedAt:     time.Now().UTC(),
+		Usage:          delta,
+		BudgetTokens:   budgetTokens,
+		BudgetExceeded: errors.Is(runErr, embed.ErrBudgetExceeded),
+	}
+	if err := recordEmbeddingUsage(c.cfg, delta, embed.Usage{}, run); err != nil {
+		slog.Warn("record embedding usage failed", "err", err)
+	}
+}
+
 func flushProvider(provider embed.Provider) error {
 	if flusher, ok := provider.(interface{ Flush() error }); ok {
 		return flusher.Flush()
@@ -355,7 +536,10 @@ func (c *IndexCoordinator) deletePathsLocked(ctx context.Context, paths []string
 			}
 		}
 		rel = filepath.Clean(rel)
-		if _, err := database.DeleteProjectFile(ctx, c.projectRoot, rel); err != nil {
+		if _, err := database.DeleteProjectFile(ctx, c.projectRoot, rel); errors.Is(err, db.ErrFileNotIndexed) {
+			// The watcher also reports files that were never indexed.
+			continue
+		} else if err != nil {
 			errs = append(errs, fmt.Errorf("delete watched file %s: %w", rel, err))
 			continue
 		}
@@ -375,3 +559,25 @@ func sortedSet(values map[string]struct{}) []string {
 	slices.Sort(result)
 	return result
 }
+
+// reembedTargets returns the relative paths of the indexed files under
+// absRoot that a reembed run covers: all of them, or with onlyStale those
+// whose vectors carry another embedding version than the active one.
+func reembedTargets(service *Service, indexer *index.Indexer, absRoot string, onlyStale bool) ([]string, error) {
+	if onlyStale {
+		legacy, err := service.legacyEmbedVersion()
+		if err != nil {
+			return nil, err
+		}
+		return indexer.StaleEmbeddingFiles(absRoot, legacy)
+	}
+	files, err := service.session.DB.ListFiles(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("list indexed files: %w", err)
+	}
+	targets := make([]string, 0, len(files))
+	for _, file := range files {
+		targets = append(targets, file.RelativePath)
+	}
+	return targets, nil
+}
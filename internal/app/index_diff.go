@@ -0,0 +1,79 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+const (
+	indexDiffSchemaVersion = 1
+	indexDiffFilename      = "index_diff.json"
+)
+
+// IndexDiff is the persisted chunk diff of the most recent index run that
+// touched any file. Runs that found nothing to do leave it in place, so
+// `vecgrep diff-index` keeps showing the last run worth inspecting.
+type IndexDiff struct {
+	SchemaVersion int       `json:"schema_version"`
+	ProjectRoot   string    `json:"project_root"`
+	IndexedAt     time.Time `json:"indexed_at"`
+	FullReindex   bool      `json:"full_reindex"`
+	index.ChunkDiff
+}
+
+// IndexDiffPath returns the index diff file for a data directory.
+func IndexDiffPath(dataDir string) string {
+	return filepath.Join(dataDir, indexDiffFilename)
+}
+
+// LoadIndexDiff reads the last persisted index diff. A missing file is
+// (nil, nil).
+func LoadIndexDiff(dataDir string) (*IndexDiff, error) {
+	if strings.TrimSpace(dataDir) == "" {
+		return nil, fmt.Errorf("index diff data dir is empty")
+	}
+	data, err := os.ReadFile(IndexDiffPath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read index diff: %w", err)
+	}
+	var diff IndexDiff
+	if err := json.Unmarshal(data, &diff); err != nil {
+		return nil, fmt.Errorf("decode index diff: %w", err)
+	}
+	if diff.SchemaVersion != indexDiffSchemaVersion {
+		return nil, fmt.Errorf("unsupported index diff schema version %d", diff.SchemaVersion)
+	}
+	return &diff, nil
+}
+
+// recordIndexDiff persists a run's chunk diff unless the run touched no file.
+func recordIndexDiff(cfg *config.Config, projectRoot string, fullReindex bool, diff *index.ChunkDiff) error {
+	if cfg == nil || cfg.DataDir == "" || diff == nil {
+		return nil
+	}
+	if len(diff.Files) == 0 && diff.Unchanged == 0 {
+		return nil
+	}
+	record := &IndexDiff{
+		SchemaVersion: indexDiffSchemaVersion,
+		ProjectRoot:   projectRoot,
+		IndexedAt:     time.Now().UTC(),
+		FullReindex:   fullReindex,
+		ChunkDiff:     *diff,
+	}
+	if err := writeJSONAtomic(cfg.DataDir, IndexDiffPath(cfg.DataDir), record); err != nil {
+		return fmt.Errorf("write index diff: %w", err)
+	}
+	return nil
+}
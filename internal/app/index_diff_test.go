@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+func TestIndexRecordsChunkDiff(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session, service := createTestSession(t)
+	session.Provider = fakeProvider{dimensions: session.Config.Embedding.Dimensions, model: session.Config.Embedding.Model}
+	path := filepath.Join(session.ProjectRoot, "a.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := service.Index(ctx, IndexRequest{FullReindex: true}, nil); err != nil {
+		t.Fatalf("full index failed: %v", err)
+	}
+	first, err := LoadIndexDiff(session.Config.DataDir)
+	if err != nil || first == nil || !first.FullReindex || first.Added == 0 {
+		t.Fatalf("first diff = %+v, %v", first, err)
+	}
+
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {\n\tprintln(1)\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Index(ctx, IndexRequest{}, nil); err != nil {
+		t.Fatalf("incremental index failed: %v", err)
+	}
+	second, err := LoadIndexDiff(session.Config.DataDir)
+	if err != nil || second == nil || second.FullReindex || second.Modified == 0 {
+		t.Fatalf("second diff = %+v, %v", second, err)
+	}
+	if len(second.Files) != 1 || second.Files[0].Path != "a.go" || second.Files[0].Changes[0].Kind != index.ChunkModified {
+		t.Fatalf("second diff files = %+v", second.Files)
+	}
+
+	// A run with nothing to do keeps the last diff worth inspecting.
+	if _, err := service.Index(ctx, IndexRequest{}, nil); err != nil {
+		t.Fatalf("no-op index failed: %v", err)
+	}
+	kept, err := LoadIndexDiff(session.Config.DataDir)
+	if err != nil || kept == nil || !kept.IndexedAt.Equal(second.IndexedAt) {
+		t.Fatalf("diff after no-op run = %+v, %v", kept, err)
+	}
+}
+
+func TestLoadIndexDiffMissing(t *testing.T) {
+	diff, err := LoadIndexDiff(t.TempDir())
+	if diff != nil || err != nil {
+		t.Fatalf("LoadIndexDiff on empty dir = %+v, %v", diff, err)
+	}
+}
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"strings"
+
 	"github.com/abdul-hamid-achik/vecgrep/internal/config"
 	"github.com/abdul-hamid-achik/vecgrep/internal/db"
 	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
@@ -28,17 +30,55 @@ func BuildIndexerConfig(cfg *config.Config, additionalIgnores []string) index.In
 	if cfg.Indexing.MaxFileSize > 0 {
 		resolved.MaxFileSize = cfg.Indexing.MaxFileSize
 	}
+	if cfg.Indexing.MaxChunksPerFile > 0 {
+		resolved.MaxChunksPerFile = cfg.Indexing.MaxChunksPerFile
+	}
 	if cfg.Indexing.SourceBufferBytes > 0 {
 		resolved.SourceBufferBytes = cfg.Indexing.SourceBufferBytes
 	}
+	if cfg.Indexing.Workers > 0 {
+		resolved.Workers = cfg.Indexing.Workers
+	}
 	if cfg.Indexing.SyncInterval > 0 {
 		resolved.SyncInterval = cfg.Indexing.SyncInterval
 	}
 	if cfg.Indexing.SyncIntervalDuration > 0 {
 		resolved.SyncIntervalDuration = cfg.Indexing.SyncIntervalDuration
 	}
+	if cfg.Embedding.BatchSize > 0 {
+		resolved.BatchSize = cfg.Embedding.BatchSize
+	} else if tuning := currentBatchTuning(cfg); tuning != nil {
+		resolved.BatchSize = tuning.BatchSize
+		if tuning.MaxInputChars < index.DefaultChunkerConfig().MaxChunkChars {
+			resolved.MaxChunkChars = tuning.MaxInputChars
+		}
+	}
+	resolved.MaxInputTokens = cfg.Embedding.MaxInputTokens
+	if resolved.MaxInputTokens <= 0 && (cfg.Embedding.Provider == "" || cfg.Embedding.Provider == "ollama") {
+		// Ollama truncates at the context it was given.
+		resolved.MaxInputTokens = cfg.Embedding.OllamaContext
+	}
+	resolved.OverlongInput = cfg.Embedding.OverlongInput
+	resolved.SecretsPolicy = cfg.Indexing.SecretsPolicy
+	for _, rule := range cfg.Indexing.Tags {
+		resolved.TagRules = append(resolved.TagRules, index.TagRule{Tags: rule.Tags, Path: rule.Path, Content: rule.Content})
+	}
+	resolved.EnabledLanguages = cfg.Indexing.Languages.Enabled
+	resolved.DisabledLanguages = cfg.Indexing.Languages.Disabled
+	for ext, chunker := range cfg.Chunkers {
+		command := strings.Fields(chunker.Cmd)
+		ext = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+		if len(command) == 0 || ext == "" {
+			continue
+		}
+		if resolved.ExternalChunkers == nil {
+			resolved.ExternalChunkers = make(map[string]index.ExternalChunkerSpec)
+		}
+		resolved.ExternalChunkers[ext] = index.ExternalChunkerSpec{Command: command, Timeout: chunker.Timeout}
+	}
 	resolved.IgnorePatterns = append(resolved.IgnorePatterns, cfg.Indexing.IgnorePatterns...)
 	resolved.IgnorePatterns = append(resolved.IgnorePatterns, additionalIgnores...)
+	resolved.EmbedVersion = CurrentEmbeddingProfile(cfg).EmbedVersion()
 	return resolved
 }
 
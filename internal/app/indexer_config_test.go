@@ -15,8 +15,10 @@ func TestBuildIndexerConfigCarriesAllResolvedSettings(t *testing.T) {
 	cfg.Indexing.ChunkOverlap = 44
 	cfg.Indexing.MaxFileSize = 234567
 	cfg.Indexing.SourceBufferBytes = 345678
+	cfg.Indexing.MaxChunksPerFile = 42
 	cfg.Indexing.SyncInterval = 17
 	cfg.Indexing.SyncIntervalDuration = 9 * time.Second
+	cfg.Indexing.Workers = 6
 	cfg.Indexing.IgnorePatterns = []string{"generated/**"}
 
 	got := BuildIndexerConfig(cfg, []string{"scratch/**"})
@@ -26,9 +28,15 @@ func TestBuildIndexerConfigCarriesAllResolvedSettings(t *testing.T) {
 	if got.MaxFileSize != cfg.Indexing.MaxFileSize || got.SourceBufferBytes != cfg.Indexing.SourceBufferBytes {
 		t.Fatalf("size settings = (%d, %d)", got.MaxFileSize, got.SourceBufferBytes)
 	}
+	if got.MaxChunksPerFile != 42 {
+		t.Fatalf("MaxChunksPerFile = %d, want 42", got.MaxChunksPerFile)
+	}
 	if got.SyncInterval != 17 || got.SyncIntervalDuration != 9*time.Second {
 		t.Fatalf("sync settings = (%d, %s)", got.SyncInterval, got.SyncIntervalDuration)
 	}
+	if got.Workers != 6 {
+		t.Fatalf("Workers = %d, want 6", got.Workers)
+	}
 	for _, pattern := range []string{".vecgrep/**", "generated/**", "scratch/**"} {
 		if !slices.Contains(got.IgnorePatterns, pattern) {
 			t.Fatalf("ignore patterns %v omit %q", got.IgnorePatterns, pattern)
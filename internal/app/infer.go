@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// InferredScope is a query's inferred filters resolved against the index.
+type InferredScope struct {
+	// Query is the text left to search for.
+	Query string
+	// Filters are the inferred filters that match indexed files; the others
+	// are dropped so a wrong guess never empties the results.
+	Filters search.InferredFilters
+	// FilePaths are the files the tests and directory filters allow, or nil
+	// when neither applies.
+	FilePaths []string
+}
+
+// InferScope reads filters from the wording of query (see
+// search.InferFilters) and keeps the ones that match files indexed under
+// roots: languages that are indexed, tests when there are test files,
+// and the directory when a directory name contains the query's word. When
+// tests and the directory match no file together, the directory is dropped.
+func InferScope(database *db.DB, roots []string, query string) (InferredScope, error) {
+	_, inferred := search.InferFilters(query)
+	if inferred.IsZero() {
+		return InferredScope{Query: query}, nil
+	}
+	var files []db.FileInfo
+	for _, root := range roots {
+		rootFiles, err := database.ListFiles(root)
+		if err != nil {
+			return InferredScope{Query: query}, fmt.Errorf("list files: %w", err)
+		}
+		files = append(files, rootFiles...)
+	}
+
+	var kept search.InferredFilters
+	for _, lang := range inferred.Languages {
+		if slices.ContainsFunc(files, func(f db.FileInfo) bool { return f.Language == lang }) {
+			kept.Languages = append(kept.Languages, lang)
+		}
+	}
+	inScope := func(tests bool, dir string) []string {
+		var paths []string
+		for _, f := range files {
+			if len(kept.Languages) > 0 && !slices.Contains(kept.Languages, f.Language) {
+				continue
+			}
+			if (tests && !isTestPath(f.RelativePath)) || (dir != "" && !inDirectoryLike(f.RelativePath, dir)) {
+				continue
+			}
+			paths = append(paths, f.RelativePath)
+		}
+		return paths
+	}
+
+	var paths []string
+	if inferred.TestsOnly || inferred.Directory != "" {
+		kept.TestsOnly, kept.Directory = inferred.TestsOnly, inferred.Directory
+		if kept.TestsOnly && len(inScope(true, "")) == 0 {
+			kept.TestsOnly = false
+		}
+		if kept.Directory != "" && len(inScope(kept.TestsOnly, kept.Directory)) == 0 {
+			kept.Directory = ""
+		}
+		if kept.TestsOnly || kept.Directory != "" {
+			paths = inScope(kept.TestsOnly, kept.Directory)
+		}
+	}
+	if kept.IsZero() {
+		return InferredScope{Query: query}, nil
+	}
+	return InferredScope{Query: kept.Strip(query), Filters: kept, FilePaths: paths}, nil
+}
+
+// inDirectoryLike reports whether a project-relative path is under a
+// directory whose name contains word, ignoring case.
+func inDirectoryLike(rel, word string) bool {
+	dir := path.Dir(rel)
+	if dir == "." {
+		return false
+	}
+	for _, name := range strings.Split(dir, "/") {
+		if strings.Contains(strings.ToLower(name), word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+func TestInferScopeKeepsFiltersThatMatchTheIndex(t *testing.T) {
+	session, _ := createTestSession(t)
+	for _, file := range []struct{ rel, lang string }{
+		{"internal/auth/login.py", "python"},
+		{"internal/auth/login_test.py", "python"},
+		{"internal/billing/test_invoice.py", "python"},
+		{"internal/auth/session.go", "go"},
+	} {
+		chunk := db.NewChunkRecord(filepath.Join(session.ProjectRoot, file.rel), file.rel, "hash-"+file.rel, 16,
+			file.lang, "content", 1, 1, 0, 7, "generic", "", session.ProjectRoot)
+		if _, err := session.DB.InsertChunk(chunk, make([]float32, session.Config.Embedding.Dimensions)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	roots := []string{session.ProjectRoot}
+
+	scope, err := InferScope(session.DB, roots, "python tests for the auth flow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := search.InferredFilters{Languages: []string{"python"}, TestsOnly: true, Directory: "auth"}
+	if scope.Query != "for the auth flow" || !reflect.DeepEqual(scope.Filters, want) {
+		t.Fatalf("scope = %+v, want %+v", scope, want)
+	}
+	if !reflect.DeepEqual(scope.FilePaths, []string{"internal/auth/login_test.py"}) {
+		t.Errorf("file paths = %v", scope.FilePaths)
+	}
+
+	// Rust is not indexed and nothing is under a payments directory, so
+	// only the request for tests survives.
+	scope, err = InferScope(session.DB, roots, "rust tests for the payments flow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(scope.Filters, search.InferredFilters{TestsOnly: true}) || scope.Query != "rust for the payments flow" {
+		t.Fatalf("scope = %+v, want tests only", scope)
+	}
+	if len(scope.FilePaths) != 2 {
+		t.Errorf("file paths = %v, want both test files", scope.FilePaths)
+	}
+
+	scope, err = InferScope(session.DB, roots, "rust parser")
+	if err != nil || !scope.Filters.IsZero() || scope.Query != "rust parser" || scope.FilePaths != nil {
+		t.Fatalf("scope = %+v, %v, want the query unchanged", scope, err)
+	}
+}
@@ -0,0 +1,71 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+// ProvenanceGroup counts the indexed files sharing one chunk provenance.
+// Empty fields mean the files were indexed before provenance was recorded.
+type ProvenanceGroup struct {
+	IndexerVersion string `json:"indexer_version"`
+	ChunkStrategy  string `json:"chunk_strategy"`
+	ChunkParams    string `json:"chunk_params"`
+	Files          int    `json:"files"`
+	Stale          bool   `json:"stale"`
+}
+
+// ProvenanceReport summarizes how the indexed files were chunked. Mixed is
+// set when more than one provenance is present; StaleFiles counts the files
+// `vecgrep index --rechunk-stale` would re-chunk.
+type ProvenanceReport struct {
+	CurrentParams string            `json:"current_params"`
+	Groups        []ProvenanceGroup `json:"groups"`
+	Mixed         bool              `json:"mixed"`
+	StaleFiles    int               `json:"stale_files"`
+	UnknownFiles  int               `json:"unknown_files"`
+}
+
+// buildProvenanceReport groups files by provenance and marks the groups whose
+// chunk parameters differ from the current config.
+func buildProvenanceReport(cfg *config.Config, files []db.FileInfo) *ProvenanceReport {
+	report := &ProvenanceReport{CurrentParams: index.ChunkParams(BuildIndexerConfig(cfg, nil))}
+	groups := make(map[ProvenanceGroup]int)
+	for _, file := range files {
+		key := ProvenanceGroup{
+			IndexerVersion: file.IndexerVersion,
+			ChunkStrategy:  file.ChunkStrategy,
+			ChunkParams:    file.ChunkParams,
+			Stale:          index.ProvenanceStale(file, report.CurrentParams),
+		}
+		groups[key]++
+		if key.Stale {
+			report.StaleFiles++
+		}
+		if file.ChunkParams == "" {
+			report.UnknownFiles++
+		}
+	}
+	for key, count := range groups {
+		key.Files = count
+		report.Groups = append(report.Groups, key)
+	}
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if a.Files != b.Files {
+			return a.Files > b.Files
+		}
+		if a.IndexerVersion != b.IndexerVersion {
+			return a.IndexerVersion < b.IndexerVersion
+		}
+		if a.ChunkStrategy != b.ChunkStrategy {
+			return a.ChunkStrategy < b.ChunkStrategy
+		}
+		return a.ChunkParams < b.ChunkParams
+	})
+	report.Mixed = len(report.Groups) > 1
+	return report
+}
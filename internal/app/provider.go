@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/abdul-hamid-achik/vecgrep/internal/config"
 	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
@@ -27,7 +28,7 @@ func ResolvedCachePath(cfg *config.Config) string {
 }
 
 func NewProvider(cfg *config.Config) (embed.Provider, error) {
-	inner, err := newInnerProvider(cfg)
+	inner, err := newMeteredProvider(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +62,7 @@ func NewProvider(cfg *config.Config) (embed.Provider, error) {
 // independent queues. The daemon-specific limits belong here, beside the raw
 // provider factory, while ownership and Close remain with app.Session.
 func NewDaemonProvider(cfg *config.Config) (embed.Provider, error) {
-	inner, err := newInnerProvider(cfg)
+	inner, err := newMeteredProvider(cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +83,17 @@ func NewDaemonProvider(cfg *config.Config) (embed.Provider, error) {
 	return embed.NewThrottledProvider(inner, throttleCfg), nil
 }
 
+// newMeteredProvider wraps the raw provider with L2 normalization and usage
+// metering. Both sit beneath the throttle's cache so only upstream requests
+// are counted and only normalized vectors are cached.
+func newMeteredProvider(cfg *config.Config) (embed.Provider, error) {
+	inner, err := newInnerProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return embed.NewMeteredProvider(embed.NewNormalizedProvider(inner), embed.NewUsageMeter()), nil
+}
+
 // newInnerProvider constructs the raw embedding provider based on the
 // configured provider type, without any throttle/cache wrapper.
 func newInnerProvider(cfg *config.Config) (embed.Provider, error) {
@@ -107,6 +119,31 @@ func newInnerProvider(cfg *config.Config) (embed.Provider, error) {
 			Model:      cfg.Embedding.Model,
 			Dimensions: cfg.Embedding.Dimensions,
 		}), nil
+	case "tei":
+		return embed.NewTEIProvider(embed.TEIConfig{
+			URL:          cfg.Embedding.TEIURL,
+			APIKey:       cfg.Embedding.TEIAPIKey,
+			Model:        cfg.Embedding.Model,
+			Dimensions:   cfg.Embedding.Dimensions,
+			MaxBatchSize: cfg.Embedding.MaxBatchSize,
+			Truncation:   cfg.Embedding.TEITruncation,
+		})
+	case "exec":
+		return embed.NewExecProvider(embed.ExecConfig{
+			Command:      strings.Fields(cfg.Embedding.ExecCommand),
+			Model:        cfg.Embedding.Model,
+			Dimensions:   cfg.Embedding.Dimensions,
+			MaxBatchSize: cfg.Embedding.MaxBatchSize,
+			Timeout:      cfg.Embedding.ExecTimeout,
+		})
+	case "onnx":
+		return embed.NewONNXProvider(embed.ONNXConfig{
+			ModelDir:     cfg.Embedding.ONNXModelDir,
+			LibraryPath:  cfg.Embedding.ONNXLibraryPath,
+			Model:        cfg.Embedding.Model,
+			Dimensions:   cfg.Embedding.Dimensions,
+			MaxBatchSize: cfg.Embedding.MaxBatchSize,
+		})
 	case "ollama", "":
 		return embed.NewOllamaProvider(embed.OllamaConfig{
 			URL:              cfg.Embedding.OllamaURL,
@@ -134,6 +134,10 @@ func initProjectDatabase(projectRoot, projectName, dataDir string, global bool)
 		HNSWM:              cfg.Vector.VecLite.M,
 		HNSWEfConstruction: cfg.Vector.VecLite.EfConstruction,
 		HNSWEfSearch:       cfg.Vector.VecLite.EfSearch,
+		Quantization:       db.Quantization(cfg.Vector.VecLite.Quantization),
+		Qdrant:             QdrantOptions(cfg),
+		Pgvector:           PgvectorOptions(cfg),
+		ProjectRoot:        projectRoot,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("initialize veclite index: %w", err)
@@ -194,6 +198,14 @@ func ResetIndexFiles(ctx context.Context, startDir string) (*ResetIndexFilesResu
 	if err := os.RemoveAll(vecPath); err != nil {
 		return nil, fmt.Errorf("remove veclite index: %w", err)
 	}
+	if err := os.RemoveAll(db.FullVectorsPath(cfg.DataDir)); err != nil {
+		return nil, fmt.Errorf("remove full-precision vectors: %w", err)
+	}
+	for _, path := range []string{db.SealedPath(vecPath), db.SealedPath(db.FullVectorsPath(cfg.DataDir))} {
+		if err := os.RemoveAll(path); err != nil {
+			return nil, fmt.Errorf("remove encrypted index: %w", err)
+		}
+	}
 	// Also remove the stale lock file left by another process holding the DB.
 	if err := os.RemoveAll(vecPath + ".lock"); err != nil {
 		return nil, fmt.Errorf("remove veclite lock file: %w", err)
@@ -211,6 +223,11 @@ func ResetIndexFiles(ctx context.Context, startDir string) (*ResetIndexFilesResu
 		HNSWM:              cfg.Vector.VecLite.M,
 		HNSWEfConstruction: cfg.Vector.VecLite.EfConstruction,
 		HNSWEfSearch:       cfg.Vector.VecLite.EfSearch,
+		Quantization:       db.Quantization(cfg.Vector.VecLite.Quantization),
+		Qdrant:             QdrantOptions(cfg),
+		Pgvector:           PgvectorOptions(cfg),
+		ProjectRoot:        projectRoot,
+		Encryption:         EncryptionOptions(cfg),
 	})
 	if err != nil {
 		return &ResetIndexFilesResult{
@@ -0,0 +1,250 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+	"github.com/abdul-hamid-achik/vecgrep/internal/git"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+const (
+	refsSchemaVersion = 1
+	refsFilename      = "refs.json"
+	refsDirname       = "refs"
+)
+
+// IndexedRef is a git ref indexed with `vecgrep index --ref`. Its tree is
+// exported to Root, which doubles as the project root its chunks are stored
+// under, so refs never collide with the working tree or each other.
+type IndexedRef struct {
+	Ref       string    `json:"ref"`
+	Commit    string    `json:"commit"`
+	Root      string    `json:"root"`
+	IndexedAt time.Time `json:"indexed_at"`
+}
+
+type refsFile struct {
+	SchemaVersion int          `json:"schema_version"`
+	Refs          []IndexedRef `json:"refs"`
+}
+
+// RefsPath returns the indexed-refs file for a data directory.
+func RefsPath(dataDir string) string {
+	return filepath.Join(dataDir, refsFilename)
+}
+
+// RefRoot returns the directory a ref's tree is exported to.
+func RefRoot(dataDir, ref string) string {
+	return filepath.Join(dataDir, refsDirname, git.SanitizeBranch(ref))
+}
+
+// ListIndexedRefs returns the refs indexed for a data directory, by name.
+func ListIndexedRefs(dataDir string) ([]IndexedRef, error) {
+	data, err := os.ReadFile(RefsPath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read indexed refs: %w", err)
+	}
+	var stored refsFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode indexed refs: %w", err)
+	}
+	if stored.SchemaVersion != refsSchemaVersion {
+		return nil, fmt.Errorf("unsupported indexed refs schema version %d", stored.SchemaVersion)
+	}
+	return stored.Refs, nil
+}
+
+// IndexedRefRoot returns the project root the chunks of an indexed ref are
+// stored under.
+func IndexedRefRoot(dataDir, ref string) (string, error) {
+	refs, err := ListIndexedRefs(dataDir)
+	if err != nil {
+		return "", err
+	}
+	for _, indexed := range refs {
+		if indexed.Ref == ref {
+			return indexed.Root, nil
+		}
+	}
+	return "", fmt.Errorf("ref %q is not indexed; run 'vecgrep index --ref %s' first", ref, ref)
+}
+
+func recordIndexedRef(dataDir string, ref IndexedRef) error {
+	refs, err := ListIndexedRefs(dataDir)
+	if err != nil {
+		return err
+	}
+	refs = slices.DeleteFunc(refs, func(r IndexedRef) bool { return r.Ref == ref.Ref })
+	refs = append(refs, ref)
+	slices.SortFunc(refs, func(a, b IndexedRef) int { return strings.Compare(a.Ref, b.Ref) })
+	if err := writeJSONAtomic(dataDir, RefsPath(dataDir), refsFile{SchemaVersion: refsSchemaVersion, Refs: refs}); err != nil {
+		return fmt.Errorf("write indexed refs: %w", err)
+	}
+	return nil
+}
+
+// resetIndexedRefs deletes the chunks and exported trees of every indexed
+// ref, along with the record of them.
+func (s *Service) resetIndexedRefs(ctx context.Context) error {
+	dataDir := s.session.Config.DataDir
+	refs, err := ListIndexedRefs(dataDir)
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if err := s.session.DB.Reset(ctx, ref.Root); err != nil {
+			return fmt.Errorf("reset ref %s: %w", ref.Ref, err)
+		}
+	}
+	if err := os.RemoveAll(filepath.Join(dataDir, refsDirname)); err != nil {
+		return fmt.Errorf("remove exported refs: %w", err)
+	}
+	if err := os.Remove(RefsPath(dataDir)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove indexed refs: %w", err)
+	}
+	return nil
+}
+
+// indexRefsLocked indexes each requested git ref under its own project root.
+// A ref's tree is exported with git archive, so the working tree, index, and
+// HEAD are never touched. Structural chunks and the ingestion receipt
+// describe the working tree alone, so refs use a plain indexer.
+func (c *IndexCoordinator) indexRefsLocked(ctx context.Context, req IndexRequest, progress func(index.Progress)) (result *index.IndexResult, retErr error) {
+	if len(req.Paths) > 0 || req.RechunkStale || req.Reembed {
+		return nil, fmt.Errorf("a ref is indexed whole; it cannot be combined with paths, rechunking, or re-embedding")
+	}
+	existing, err := ListIndexedRefs(c.cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]string, len(req.Refs))
+	for i, ref := range req.Refs {
+		if commits[i], err = git.ResolveRef(ctx, c.projectRoot, ref); err != nil {
+			return nil, err
+		}
+		root := RefRoot(c.cfg.DataDir, ref)
+		for _, other := range existing {
+			if other.Root == root && other.Ref != ref {
+				return nil, fmt.Errorf("ref %q would share the directory of indexed ref %q", ref, other.Ref)
+			}
+		}
+	}
+
+	database, release, err := c.acquireIndexDB(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := release(); err != nil {
+			retErr = errors.Join(retErr, fmt.Errorf("release index database: %w", err))
+		}
+	}()
+	service := c.borrowedService(database)
+	// Refs share the collection with the working tree, so a full reindex of
+	// a ref still has to match the stored embedding profile.
+	if err := service.ensureEmbeddingProfileMatches(); err != nil {
+		return nil, err
+	}
+	var deferred index.DeferredEmbeddingSink
+	if req.DeferEmbeddings {
+		queue, err := openEmbedQueue(c.cfg.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		defer queue.Close()
+		deferred = queue.Append
+	}
+
+	meter := embed.UsageOf(c.provider)
+	budgetTokens := embed.BudgetTokens(c.cfg.Embedding.Provider, c.cfg.Embedding.Model, c.cfg.Embedding.BudgetUSD)
+	runStartedAt := time.Now()
+	usageStart := meter.BeginRun(budgetTokens)
+	result, indexErr := c.indexRefs(ctx, database, req, commits, progress, deferred)
+	meter.EndRun()
+	c.recordRunUsage(meter, usageStart, budgetTokens, runStartedAt, indexErr)
+
+	postErr := indexErr
+	if err := flushProvider(c.provider); err != nil {
+		postErr = errors.Join(postErr, fmt.Errorf("flush embedding provider: %w", err))
+	}
+	if indexErr == nil {
+		if err := service.saveCurrentEmbeddingProfile(); err != nil {
+			postErr = errors.Join(postErr, err)
+		}
+	}
+	if err := database.Sync(); err != nil {
+		postErr = errors.Join(postErr, fmt.Errorf("sync index: %w", err))
+	}
+	if postErr != nil {
+		return nil, postErr
+	}
+	return result, nil
+}
+
+// indexRefs exports and indexes each ref in turn, recording it once its
+// chunks are written, and returns the combined totals.
+func (c *IndexCoordinator) indexRefs(ctx context.Context, database *db.DB, req IndexRequest, commits []string, progress func(index.Progress), deferred index.DeferredEmbeddingSink) (*index.IndexResult, error) {
+	result := &index.IndexResult{}
+	for i, ref := range req.Refs {
+		root := RefRoot(c.cfg.DataDir, ref)
+		if err := git.ExportRef(ctx, c.projectRoot, commits[i], root); err != nil {
+			return nil, fmt.Errorf("export ref %s: %w", ref, err)
+		}
+		cfg := BuildIndexerConfig(c.cfg, req.AdditionalIgnores)
+		cfg.Ref = ref
+		indexer := index.NewIndexer(database, c.provider, cfg)
+		if progress != nil {
+			indexer.SetProgressCallback(progress)
+		}
+		if req.Events != nil {
+			indexer.SetFileEventCallback(req.Events)
+		}
+		if deferred != nil {
+			indexer.SetDeferredEmbeddingSink(deferred)
+		}
+		var (
+			refResult *index.IndexResult
+			err       error
+		)
+		if req.FullReindex {
+			refResult, err = indexer.ReindexAll(ctx, root)
+		} else {
+			refResult, err = indexer.Index(ctx, root)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("index ref %s: %w", ref, err)
+		}
+		result.FilesProcessed += refResult.FilesProcessed
+		result.FilesSkipped += refResult.FilesSkipped
+		result.FilesDeleted += refResult.FilesDeleted
+		result.ChunksCreated += refResult.ChunksCreated
+		result.ChunksReused += refResult.ChunksReused
+		result.ChunksTruncated += refResult.ChunksTruncated
+		result.ChunksSplit += refResult.ChunksSplit
+		result.TokensEstimated = result.TokensEstimated || refResult.TokensEstimated
+		result.ChunksWithSecrets += refResult.ChunksWithSecrets
+		result.ChunksDeferred += refResult.ChunksDeferred
+		result.Duration += refResult.Duration
+		for _, refErr := range refResult.Errors {
+			result.Errors = append(result.Errors, fmt.Errorf("%s: %w", ref, refErr))
+		}
+		indexed := IndexedRef{Ref: ref, Commit: commits[i], Root: root, IndexedAt: time.Now().UTC()}
+		if err := recordIndexedRef(c.cfg.DataDir, indexed); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
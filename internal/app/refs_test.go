@@ -0,0 +1,49 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndexedRefsRecordAndResolve(t *testing.T) {
+	dataDir := t.TempDir()
+
+	if refs, err := ListIndexedRefs(dataDir); err != nil || len(refs) != 0 {
+		t.Fatalf("ListIndexedRefs on a fresh data dir = %v, %v", refs, err)
+	}
+	if _, err := IndexedRefRoot(dataDir, "main"); err == nil || !strings.Contains(err.Error(), "vecgrep index --ref main") {
+		t.Fatalf("IndexedRefRoot for an unindexed ref = %v, want an index hint", err)
+	}
+
+	feature := IndexedRef{Ref: "feature/x", Commit: "bbb", Root: RefRoot(dataDir, "feature/x"), IndexedAt: time.Now().UTC()}
+	mainRef := IndexedRef{Ref: "main", Commit: "aaa", Root: RefRoot(dataDir, "main"), IndexedAt: time.Now().UTC()}
+	for _, ref := range []IndexedRef{feature, mainRef, {Ref: "main", Commit: "ccc", Root: mainRef.Root}} {
+		if err := recordIndexedRef(dataDir, ref); err != nil {
+			t.Fatalf("recordIndexedRef(%s) failed: %v", ref.Ref, err)
+		}
+	}
+
+	refs, err := ListIndexedRefs(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != 2 || refs[0].Ref != "feature/x" || refs[1].Ref != "main" || refs[1].Commit != "ccc" {
+		t.Fatalf("refs = %+v, want feature/x then the re-indexed main", refs)
+	}
+	if feature.Root == mainRef.Root || filepath.Dir(feature.Root) != filepath.Join(dataDir, refsDirname) {
+		t.Fatalf("ref roots %q and %q are not distinct directories under the data dir", feature.Root, mainRef.Root)
+	}
+	if root, err := IndexedRefRoot(dataDir, "feature/x"); err != nil || root != feature.Root {
+		t.Fatalf("IndexedRefRoot(feature/x) = %q, %v; want %q", root, err, feature.Root)
+	}
+
+	if err := os.WriteFile(RefsPath(dataDir), []byte(`{"schema_version":99,"refs":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ListIndexedRefs(dataDir); err == nil {
+		t.Fatal("ListIndexedRefs accepted an unknown schema version")
+	}
+}
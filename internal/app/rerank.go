@@ -0,0 +1,51 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// NewSearchReranker builds the reranking stage configured in cfg.Search, or
+// nil when none is configured. The ollama reranker defaults to the
+// embedding provider's Ollama URL.
+func NewSearchReranker(cfg *config.Config) (search.Reranker, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	rc := search.RerankerConfig{
+		Kind:    cfg.Search.Reranker,
+		URL:     cfg.Search.RerankerURL,
+		Model:   cfg.Search.RerankerModel,
+		APIKey:  cfg.Search.RerankerAPIKey,
+		Timeout: cfg.Search.RerankerTimeout,
+		TopN:    cfg.Search.RerankTopN,
+	}
+	if rc.Kind == search.RerankerOllama && rc.URL == "" {
+		rc.URL = cfg.Embedding.OllamaURL
+	}
+	return search.NewReranker(rc)
+}
+
+// RerankSearchResults applies the configured reranker to results. override,
+// when non-nil, turns reranking on or off for one request; otherwise any
+// configured reranker runs. Failures keep the original order and come back
+// as a warning for the caller to surface.
+func RerankSearchResults(ctx context.Context, cfg *config.Config, override *bool, query string, results []search.Result) ([]search.Result, string) {
+	if override != nil && !*override {
+		return results, ""
+	}
+	reranker, err := NewSearchReranker(cfg)
+	if err != nil {
+		return results, fmt.Sprintf("reranker misconfigured, kept original order: %v", err)
+	}
+	if reranker == nil {
+		if override != nil {
+			return results, "rerank requested but no reranker is configured; set search.reranker"
+		}
+		return results, ""
+	}
+	return search.RerankResults(ctx, reranker, query, results)
+}
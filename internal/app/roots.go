@@ -0,0 +1,123 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+)
+
+// ExtraRoot is one configured directory indexed alongside the project root.
+// Its chunks are stored under Path as their project root, so relative paths
+// stay relative to the directory they were read from.
+type ExtraRoot struct {
+	Name string
+	Path string
+}
+
+// ExtraRoots resolves indexing.extra_roots for projectRoot and validates that
+// each root is an existing directory with a unique name that neither contains
+// nor lies inside the project root or another extra root, since overlapping
+// roots would index the same files twice.
+func ExtraRoots(projectRoot string, cfg *config.Config) ([]ExtraRoot, error) {
+	if cfg == nil || len(cfg.Indexing.ExtraRoots) == 0 {
+		return nil, nil
+	}
+	absProject, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("resolve project root: %w", err)
+	}
+	roots := configuredExtraRoots(absProject, cfg)
+	names := make(map[string]string, len(roots))
+	for i, root := range roots {
+		if root.Path == "" {
+			return nil, fmt.Errorf("indexing.extra_roots[%d]: path is required", i)
+		}
+		info, err := os.Stat(root.Path)
+		if err != nil {
+			return nil, fmt.Errorf("extra root %s: %w", root.Name, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("extra root %s: %s is not a directory", root.Name, root.Path)
+		}
+		if other, ok := names[root.Name]; ok {
+			return nil, fmt.Errorf("extra roots %s and %s share the name %q; set name: on one of them", other, root.Path, root.Name)
+		}
+		names[root.Name] = root.Path
+		if pathsOverlap(absProject, root.Path) {
+			return nil, fmt.Errorf("extra root %s (%s) overlaps the project root %s", root.Name, root.Path, absProject)
+		}
+		for _, prev := range roots[:i] {
+			if pathsOverlap(prev.Path, root.Path) {
+				return nil, fmt.Errorf("extra roots %s and %s overlap", prev.Name, root.Name)
+			}
+		}
+	}
+	return roots, nil
+}
+
+// configuredExtraRoots returns the configured roots as absolute, cleaned
+// paths with default names, without touching the filesystem. Search uses it
+// directly so a missing checkout narrows results instead of failing queries.
+func configuredExtraRoots(absProject string, cfg *config.Config) []ExtraRoot {
+	if cfg == nil {
+		return nil
+	}
+	roots := make([]ExtraRoot, 0, len(cfg.Indexing.ExtraRoots))
+	for _, configured := range cfg.Indexing.ExtraRoots {
+		path := config.ExpandPath(strings.TrimSpace(configured.Path))
+		if path != "" {
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(absProject, path)
+			}
+			path = filepath.Clean(path)
+		}
+		name := strings.TrimSpace(configured.Name)
+		if name == "" && path != "" {
+			name = filepath.Base(path)
+		}
+		roots = append(roots, ExtraRoot{Name: name, Path: path})
+	}
+	return roots
+}
+
+// pathsOverlap reports whether a and b are the same directory or one contains
+// the other.
+func pathsOverlap(a, b string) bool {
+	return pathWithin(a, b) || pathWithin(b, a)
+}
+
+func pathWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// SearchRoots returns the project roots a search of projectRoot covers: the
+// project itself followed by its extra roots, and the labels for results
+// from the extra roots keyed by root path. It returns nil slices for a
+// single-root project so callers keep the plain project_root filter.
+func SearchRoots(projectRoot string, cfg *config.Config) ([]string, map[string]string) {
+	if cfg == nil || len(cfg.Indexing.ExtraRoots) == 0 {
+		return nil, nil
+	}
+	absProject, err := filepath.Abs(projectRoot)
+	if err != nil {
+		return nil, nil
+	}
+	extras := configuredExtraRoots(absProject, cfg)
+	paths := []string{projectRoot}
+	labels := make(map[string]string, len(extras))
+	for _, root := range extras {
+		if root.Path == "" {
+			continue
+		}
+		paths = append(paths, root.Path)
+		labels[root.Path] = root.Name
+	}
+	return paths, labels
+}
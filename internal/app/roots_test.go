@@ -0,0 +1,70 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+)
+
+func TestExtraRoots(t *testing.T) {
+	base := t.TempDir()
+	project := filepath.Join(base, "app")
+	shared := filepath.Join(base, "shared-lib")
+	for _, dir := range []string{project, shared, filepath.Join(project, "vendor")} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	withRoots := func(roots ...config.ExtraRootConfig) *config.Config {
+		cfg := config.DefaultConfig()
+		cfg.Indexing.ExtraRoots = roots
+		return cfg
+	}
+
+	roots, err := ExtraRoots(project, withRoots(config.ExtraRootConfig{Path: "../shared-lib"}))
+	if err != nil {
+		t.Fatalf("ExtraRoots: %v", err)
+	}
+	if len(roots) != 1 || roots[0].Path != shared || roots[0].Name != "shared-lib" {
+		t.Fatalf("roots = %+v, want shared-lib at %s", roots, shared)
+	}
+
+	tests := []struct {
+		name  string
+		roots []config.ExtraRootConfig
+		want  string
+	}{
+		{"missing", []config.ExtraRootConfig{{Path: filepath.Join(base, "gone")}}, "gone"},
+		{"inside project", []config.ExtraRootConfig{{Path: "vendor"}}, "overlaps the project root"},
+		{"contains project", []config.ExtraRootConfig{{Path: base}}, "overlaps the project root"},
+		{"duplicate name", []config.ExtraRootConfig{{Path: shared, Name: "lib"}, {Path: filepath.Join(project, "vendor"), Name: "lib"}}, "share the name"},
+		{"empty path", []config.ExtraRootConfig{{Name: "lib"}}, "path is required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExtraRoots(project, withRoots(tt.roots...))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("ExtraRoots error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchRoots(t *testing.T) {
+	if paths, labels := SearchRoots("/work/app", config.DefaultConfig()); paths != nil || labels != nil {
+		t.Fatalf("single-root project should keep the plain filter, got %v %v", paths, labels)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Indexing.ExtraRoots = []config.ExtraRootConfig{{Path: "/work/shared", Name: "lib"}}
+	paths, labels := SearchRoots("/work/app", cfg)
+	if len(paths) != 2 || paths[0] != "/work/app" || paths[1] != "/work/shared" {
+		t.Fatalf("paths = %v", paths)
+	}
+	if labels["/work/shared"] != "lib" {
+		t.Fatalf("labels = %v", labels)
+	}
+}
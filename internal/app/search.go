@@ -2,11 +2,13 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
 	"github.com/abdul-hamid-achik/vecgrep/internal/search"
 )
 
@@ -20,14 +22,38 @@ type SearchRequest struct {
 	ChunkType   string
 	FilePattern string
 	Directory   string
+	Directories []string // Any of several directory prefixes (OR)
 	FilePaths   []string // Allow-list of relative paths (blast-radius scoping)
 	MinLine     int
 	MaxLine     int
-	MinScore    float32 // Drop hits below this score (0-1); 0 keeps all
+	MinScore    float32  // Drop hits below this score (0-1); 0 keeps all
+	Tags        []string // indexing.tags labels (OR)
+	EfSearch    int      // HNSW candidate list size; 0 uses vector.veclite.ef_search
 	ProjectRoot string
-	Explain     bool
+	// Ref searches a git ref indexed with `vecgrep index --ref` instead of
+	// the working tree; it takes precedence over ProjectRoot.
+	Ref     string
+	Explain bool
+	// Dedupe collapses repeated hits; DedupeFile over-fetches so the limit
+	// still counts distinct files.
+	Dedupe search.DedupeMode
+	// Rerank forces the configured reranking stage on or off; nil runs it
+	// whenever search.reranker (or reranker_url) is configured.
+	Rerank *bool
+	// Timeout bounds the whole search. When it passes, the results found so
+	// far come back with Partial set instead of an error: keyword-only hits
+	// if the query was not embedded yet, the original order if reranking had
+	// not finished. Zero uses search.timeout.
+	Timeout time.Duration
+	// Expand also searches rewrites of the query (search.expander_model, or
+	// synonym templates) and fuses the rankings. Explain searches ignore it.
+	Expand bool
 }
 
+// dedupeOverfetch widens the candidate pool when file-level dedupe will drop
+// sibling chunks, so the caller still gets up to Limit distinct files.
+const dedupeOverfetch = 3
+
 type SearchResponse struct {
 	Results     []search.Result
 	Diagnostics *search.SearchExplanation
@@ -37,6 +63,12 @@ type SearchResponse struct {
 	// failed at query time and results are keyword-only). Renderers must
 	// surface these so a fallback is never silent.
 	Warnings []string
+	// Translation is set when a non-English query was rewritten into English
+	// by the configured search.translator_url before searching.
+	Translation *search.QueryTranslation
+	// Partial reports that the search deadline cut a stage short; Warnings
+	// say which.
+	Partial bool
 }
 
 type SimilarTargetKind string
@@ -45,6 +77,7 @@ const (
 	SimilarTargetID       SimilarTargetKind = "id"
 	SimilarTargetLocation SimilarTargetKind = "location"
 	SimilarTargetText     SimilarTargetKind = "text"
+	SimilarTargetSymbol   SimilarTargetKind = "symbol"
 )
 
 type SimilarTarget struct {
@@ -53,6 +86,7 @@ type SimilarTarget struct {
 	FilePath string
 	Line     int
 	Text     string
+	Symbol   string
 }
 type SimilarRequest struct {
 	Target          SimilarTarget
@@ -66,7 +100,8 @@ type SimilarRequest struct {
 	FilePaths       []string // Allow-list of relative paths (blast-radius scoping)
 	MinLine         int
 	MaxLine         int
-	MinScore        float32 // Drop hits below this score (0-1); 0 keeps all
+	MinScore        float32  // Drop hits below this score (0-1); 0 keeps all
+	Tags            []string // indexing.tags labels (OR)
 	ExcludeSameFile bool
 }
 
@@ -97,27 +132,76 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (*SearchRespons
 		ChunkTypes:   req.ChunkTypes,
 		FilePattern:  req.FilePattern,
 		Directory:    req.Directory,
+		Directories:  req.Directories,
 		FilePaths:    req.FilePaths,
 		MinLine:      req.MinLine,
 		MaxLine:      req.MaxLine,
 		MinScore:     req.MinScore,
+		Tags:         req.Tags,
+		EfSearch:     req.EfSearch,
 		ProjectRoot:  req.ProjectRoot,
 		Mode:         mode,
 		VectorWeight: s.session.Config.Search.VectorWeight,
 		TextWeight:   s.session.Config.Search.TextWeight,
+		Fusion:       s.session.Config.Search.Fusion,
 		Explain:      req.Explain,
+		Expand:       req.Expand,
+		Calibration:  CurrentScoreCalibration(s.session.Config),
+	}
+	if req.Expand {
+		opts.Expander = NewSearchExpander(s.session.Config)
+	}
+	if req.Ref != "" {
+		root, err := IndexedRefRoot(s.session.Config.DataDir, req.Ref)
+		if err != nil {
+			return nil, err
+		}
+		opts.ProjectRoot = root
 	}
+	var rootLabels map[string]string
 	if opts.ProjectRoot == "" {
 		opts.ProjectRoot = s.session.ProjectRoot
+		opts.ProjectRoots, rootLabels = SearchRoots(s.session.ProjectRoot, s.session.Config)
+	}
+	// Strip inline filters ("lang:go path:internal/**") here rather than
+	// leaving it to the searcher, so the reranker and annotations see the
+	// plain query and the same scope.
+	req.Query, opts = search.ApplyInlineQuery(req.Query, opts)
+	req.Directory, req.FilePattern = opts.Directory, opts.FilePattern
+	req.MinLine, req.MaxLine, req.MinScore = opts.MinLine, opts.MaxLine, opts.MinScore
+	timeout := req.Timeout
+	if timeout == 0 {
+		timeout = s.session.Config.Search.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		opts.AllowPartial = true
+	}
+	var (
+		translation        *search.QueryTranslation
+		translationWarning string
+	)
+	if mode != search.SearchModeKeyword {
+		translator := search.NewHTTPTranslator(s.session.Config.Search.TranslatorURL, s.session.Config.Search.TranslatorTimeout)
+		req.Query, translation, translationWarning = search.TranslateQuery(ctx, translator, req.Query)
+	}
+	if req.Dedupe == search.DedupeFile && opts.Limit > 0 {
+		opts.Limit *= dedupeOverfetch
 	}
 
 	searcher := search.NewSearcher(s.session.DB, s.session.Provider)
 	start := time.Now()
+	meter := embed.UsageOf(s.session.Provider)
+	usageStart := meter.Snapshot()
+	defer s.recordSearchUsage(meter, usageStart)
 
 	var (
 		results  []search.Result
 		diag     *search.SearchExplanation
 		warnings []string
+		partial  bool
 		err      error
 	)
 	if req.Explain && mode != search.SearchModeKeyword {
@@ -129,6 +213,7 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (*SearchRespons
 			results = outcome.Results
 			warnings = outcome.Warnings
 			mode = outcome.Mode
+			partial = outcome.Partial
 		}
 		if req.Explain {
 			diag = &search.SearchExplanation{Mode: mode, Duration: time.Since(start)}
@@ -137,6 +222,47 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (*SearchRespons
 	if err != nil {
 		return nil, err
 	}
+	search.LabelRoots(results, rootLabels)
+	results = search.NewDeduper(req.Dedupe).Filter(results)
+	results, rerankWarning := RerankSearchResults(ctx, s.session.Config, req.Rerank, req.Query, results)
+	if rerankWarning != "" {
+		warnings = append(warnings, rerankWarning)
+		partial = partial || searchTimedOut(ctx, timeout)
+	}
+	results, feedbackWarning := ApplySearchFeedback(s.session.Config.DataDir, req.Query, results)
+	if req.Limit > 0 && len(results) > req.Limit {
+		results = results[:req.Limit]
+	}
+
+	// Annotations ride along after the code hits (outside the limit) for
+	// embedding-backed searches. A degraded search already failed to reach the
+	// provider, so it skips them rather than retrying. Annotations describe
+	// the working tree, so a ref search leaves them out.
+	if mode != search.SearchModeKeyword && len(warnings) == 0 && req.Ref == "" {
+		notes, skipped, annErr := searchAnnotations(ctx, s.session.Config.DataDir, s.session.Provider, req.Query, req)
+		switch {
+		case annErr != nil && searchTimedOut(ctx, timeout):
+			partial = true
+			warnings = append(warnings, "search deadline reached before annotations were searched; they were left out")
+		case annErr != nil:
+			warnings = append(warnings, fmt.Sprintf("annotations unavailable: %v", annErr))
+		case skipped > 0:
+			warnings = append(warnings, fmt.Sprintf("%d annotation(s) were embedded with a different model and were skipped; re-add them to make them searchable", skipped))
+		}
+		results = append(results, notes...)
+	}
+	// Added after the annotation check: a failed translation or feedback
+	// read still searched with a working provider, so annotations are not
+	// skipped for it.
+	if feedbackWarning != "" {
+		warnings = append(warnings, feedbackWarning)
+	}
+	if translationWarning != "" {
+		warnings = append(warnings, translationWarning)
+	}
+	if req.Expand && req.Explain && diag != nil && mode != search.SearchModeKeyword {
+		warnings = append(warnings, "expansion is skipped when explaining a search; only the original query was explained")
+	}
 
 	return &SearchResponse{
 		Results:     results,
@@ -144,9 +270,17 @@ func (s *Service) Search(ctx context.Context, req SearchRequest) (*SearchRespons
 		Mode:        mode,
 		Duration:    time.Since(start),
 		Warnings:    warnings,
+		Translation: translation,
+		Partial:     partial,
 	}, nil
 }
 
+// searchTimedOut reports whether a search bounded by timeout ran past its
+// deadline, as opposed to failing or being cancelled for another reason.
+func searchTimedOut(ctx context.Context, timeout time.Duration) bool {
+	return timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
 func (s *Service) Similar(ctx context.Context, req SimilarRequest) (*SearchResponse, error) {
 	if s == nil || s.session == nil {
 		return nil, fmt.Errorf("service not initialized")
@@ -168,11 +302,14 @@ func (s *Service) Similar(ctx context.Context, req SimilarRequest) (*SearchRespo
 			MinLine:     req.MinLine,
 			MaxLine:     req.MaxLine,
 			MinScore:    req.MinScore,
+			Tags:        req.Tags,
 			ProjectRoot: s.session.ProjectRoot,
 		},
 		ExcludeSameFile: req.ExcludeSameFile,
 		ExcludeSourceID: true,
 	}
+	var rootLabels map[string]string
+	opts.ProjectRoots, rootLabels = SearchRoots(s.session.ProjectRoot, s.session.Config)
 
 	searcher := search.NewSearcher(s.session.DB, s.session.Provider)
 	start := time.Now()
@@ -184,6 +321,11 @@ func (s *Service) Similar(ctx context.Context, req SimilarRequest) (*SearchRespo
 	switch req.Target.Kind {
 	case SimilarTargetID:
 		results, err = searcher.SearchSimilarByID(ctx, req.Target.ChunkID, opts)
+	case SimilarTargetSymbol:
+		var match SymbolMatch
+		if match, err = s.ResolveSymbol(ctx, req.Target.Symbol); err == nil {
+			results, err = searcher.SearchSimilarByID(ctx, match.ChunkID, opts)
+		}
 	case SimilarTargetLocation:
 		results, err = searcher.SearchSimilarByLocation(ctx, req.Target.FilePath, req.Target.Line, opts)
 	case SimilarTargetText:
@@ -197,6 +339,7 @@ func (s *Service) Similar(ctx context.Context, req SimilarRequest) (*SearchRespo
 	if err != nil {
 		return nil, err
 	}
+	search.LabelRoots(results, rootLabels)
 
 	return &SearchResponse{
 		Results:  results,
@@ -205,15 +348,21 @@ func (s *Service) Similar(ctx context.Context, req SimilarRequest) (*SearchRespo
 	}, nil
 }
 
-func ParseSimilarTarget(positional, text string) (SimilarTarget, error) {
-	if text != "" && positional != "" {
-		return SimilarTarget{}, fmt.Errorf("cannot specify both --text and a positional target")
+func ParseSimilarTarget(positional, text, symbol string) (SimilarTarget, error) {
+	if text != "" && symbol != "" {
+		return SimilarTarget{}, fmt.Errorf("cannot specify both --text and --symbol")
+	}
+	if (text != "" || symbol != "") && positional != "" {
+		return SimilarTarget{}, fmt.Errorf("cannot specify both --text or --symbol and a positional target")
 	}
 	if text != "" {
 		return SimilarTarget{Kind: SimilarTargetText, Text: text}, nil
 	}
+	if symbol != "" {
+		return SimilarTarget{Kind: SimilarTargetSymbol, Symbol: symbol}, nil
+	}
 	if positional == "" {
-		return SimilarTarget{}, fmt.Errorf("target required: provide a chunk ID, file:line location, or use --text")
+		return SimilarTarget{}, fmt.Errorf("target required: provide a chunk ID, file:line location, or use --text or --symbol")
 	}
 
 	if chunkID, err := strconv.ParseInt(positional, 10, 64); err == nil {
@@ -257,3 +406,17 @@ func ParseLineRange(rangeStr string) (int, int) {
 	max, _ := strconv.Atoi(parts[1])
 	return min, max
 }
+
+// recordSearchUsage persists query-embedding traffic. Only cache misses reach
+// the metered provider, so repeated queries add nothing. Failures are ignored:
+// usage is a status estimate and must never fail a search.
+func (s *Service) recordSearchUsage(meter *embed.UsageMeter, start embed.Usage) {
+	if meter == nil {
+		return
+	}
+	delta := meter.Snapshot().Sub(start)
+	if delta.IsZero() {
+		return
+	}
+	_ = recordEmbeddingUsage(s.session.Config, embed.Usage{}, delta, nil)
+}
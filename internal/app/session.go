@@ -79,6 +79,13 @@ func OpenSession(ctx context.Context, startDir string) (*Session, error) {
 		HNSWM:              cfg.Vector.VecLite.M,
 		HNSWEfConstruction: cfg.Vector.VecLite.EfConstruction,
 		HNSWEfSearch:       cfg.Vector.VecLite.EfSearch,
+		Quantization:       db.Quantization(cfg.Vector.VecLite.Quantization),
+		HotWindow:          cfg.Vector.VecLite.HotTierWindow(),
+		HotMaxChunks:       cfg.Vector.VecLite.HotMaxChunks,
+		Qdrant:             QdrantOptions(cfg),
+		Pgvector:           PgvectorOptions(cfg),
+		ProjectRoot:        projectRoot,
+		Encryption:         EncryptionOptions(cfg),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", openErrorHint(err))
@@ -193,6 +200,13 @@ func OpenReadOnlySession(ctx context.Context, startDir string) (*Session, error)
 		HNSWM:              cfg.Vector.VecLite.M,
 		HNSWEfConstruction: cfg.Vector.VecLite.EfConstruction,
 		HNSWEfSearch:       cfg.Vector.VecLite.EfSearch,
+		Quantization:       db.Quantization(cfg.Vector.VecLite.Quantization),
+		HotWindow:          cfg.Vector.VecLite.HotTierWindow(),
+		HotMaxChunks:       cfg.Vector.VecLite.HotMaxChunks,
+		Qdrant:             QdrantOptions(cfg),
+		Pgvector:           PgvectorOptions(cfg),
+		ProjectRoot:        projectRoot,
+		Encryption:         EncryptionOptions(cfg),
 		ReadOnly:           true,
 		SharedRead:         true,
 	})
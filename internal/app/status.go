@@ -30,6 +30,10 @@ type StatusResponse struct {
 	ProfileStatus    string
 	ProfileMatches   bool
 	VecLiteSizeBytes int64
+	// Quantization is how vectors are stored; a quantized index keeps its
+	// full-precision vectors in a sidecar of FullVectorBytes.
+	Quantization     string
+	FullVectorBytes  int64
 	IndexedBytes     int64
 	LatestIndexedAt  time.Time
 	IndexFresh       bool
@@ -41,6 +45,18 @@ type StatusResponse struct {
 	IngestionReceipt *IngestionReceipt
 	ReceiptError     string
 	Freshness        *IndexFreshnessReport
+	// Provenance groups indexed files by the vecgrep version and chunker
+	// settings that produced their chunks.
+	Provenance *ProvenanceReport
+	// Health scores how much maintenance the index needs.
+	Health *IndexHealth
+	// EmbeddingUsage is the persisted embedding traffic account (nil when
+	// nothing has been metered yet); UsageError reports an unreadable file.
+	EmbeddingUsage *EmbeddingUsage
+	UsageError     string
+	// PendingEmbeddings counts chunks queued by deferred-embedding index runs
+	// (nil when the queue is empty or unreadable).
+	PendingEmbeddings *PendingEmbeddings
 
 	// HNSWConfig reports the resolved HNSW index/search parameters actually
 	// applied to the veclite collection (M, EfConstruction, EfSearch). These
@@ -99,25 +115,14 @@ func (s *Service) Status(ctx context.Context) (*StatusResponse, error) {
 	}
 
 	vecVersion, _ := s.session.DB.VecVersion()
-	vecLiteSize := fileSize(s.session.VecLitePath)
+	vecLiteSize := fileSize(db.AtRestPath(s.session.VecLitePath))
+	var fullVectorsSize int64
+	if s.session.DB.Quantization() != db.QuantizationNone {
+		fullVectorsSize = fileSize(db.AtRestPath(db.FullVectorsPath(s.session.Config.DataDir)))
+	}
 	currentProfile := CurrentEmbeddingProfile(s.session.Config)
 	storedProfile, profileErr := LoadEmbeddingProfile(s.session.DB, s.session.Config.DataDir)
-	profileStatus := "ok"
-	profileMatches := true
-	if profileErr != nil {
-		profileStatus = profileErr.Error()
-		profileMatches = false
-	} else if storedProfile == nil {
-		if stats["chunks"] > 0 {
-			profileStatus = "missing"
-			profileMatches = false
-		} else {
-			profileStatus = "not written yet"
-		}
-	} else if !storedProfile.Matches(currentProfile) {
-		profileStatus = "mismatch"
-		profileMatches = false
-	}
+	profileStatus, profileMatches := embeddingProfileState(currentProfile, storedProfile, profileErr, stats["chunks"] > 0)
 
 	// Status reads raw source hashes plus codemap's bounded structural manifest;
 	// it never loads the paginated structural export. Receipt corruption and
@@ -128,7 +133,16 @@ func (s *Service) Status(ctx context.Context) (*StatusResponse, error) {
 		receiptError = receiptErr.Error()
 	}
 	freshness, pending, _ := s.indexFreshness(ctx, ingestionReceipt, receiptErr)
+	embeddingUsage, usageErr := LoadEmbeddingUsage(s.session.Config.DataDir)
+	usageError := ""
+	if usageErr != nil {
+		usageError = usageErr.Error()
+	}
 	indexFresh := freshness.IsFresh()
+	var pendingEmbeddings *PendingEmbeddings
+	if queued, err := LoadPendingEmbeddings(s.session.Config.DataDir); err == nil && queued.Files > 0 {
+		pendingEmbeddings = &queued
+	}
 
 	// Resolve the effective HNSW parameters. The config layer defaults M to 0
 	// (meaning "use veclite's default"), so surface the veclite default when
@@ -170,32 +184,39 @@ func (s *Service) Status(ctx context.Context) (*StatusResponse, error) {
 	}
 
 	return &StatusResponse{
-		ProjectRoot:      s.session.ProjectRoot,
-		ProjectName:      s.session.ProjectName,
-		DataDir:          s.session.Config.DataDir,
-		DBPath:           s.session.Config.DBPath,
-		VecLitePath:      s.session.VecLitePath,
-		VectorBackend:    vecVersion,
-		Provider:         s.session.Config.Embedding.Provider,
-		Model:            s.session.Config.Embedding.Model,
-		Dimensions:       s.session.Config.Embedding.Dimensions,
-		ProfilePath:      EmbeddingProfilePath(s.session.Config.DataDir),
-		CurrentProfile:   currentProfile,
-		StoredProfile:    storedProfile,
-		ProfileStatus:    profileStatus,
-		ProfileMatches:   profileMatches,
-		VecLiteSizeBytes: vecLiteSize,
-		IndexedBytes:     indexedBytes,
-		LatestIndexedAt:  latestIndexedAt,
-		IndexFresh:       indexFresh,
-		Stats:            stats,
-		DetailedStats:    detailed,
-		PendingChanges:   pending,
-		ConfigSources:    s.session.ConfigSources,
-		MigrationWarning: s.session.MigrationWarning,
-		IngestionReceipt: ingestionReceipt,
-		ReceiptError:     receiptError,
-		Freshness:        freshness,
+		ProjectRoot:       s.session.ProjectRoot,
+		ProjectName:       s.session.ProjectName,
+		DataDir:           s.session.Config.DataDir,
+		DBPath:            s.session.Config.DBPath,
+		VecLitePath:       s.session.VecLitePath,
+		VectorBackend:     vecVersion,
+		Provider:          s.session.Config.Embedding.Provider,
+		Model:             s.session.Config.Embedding.Model,
+		Dimensions:        s.session.Config.Embedding.Dimensions,
+		ProfilePath:       EmbeddingProfilePath(s.session.Config.DataDir),
+		CurrentProfile:    currentProfile,
+		StoredProfile:     storedProfile,
+		ProfileStatus:     profileStatus,
+		ProfileMatches:    profileMatches,
+		VecLiteSizeBytes:  vecLiteSize,
+		IndexedBytes:      indexedBytes,
+		LatestIndexedAt:   latestIndexedAt,
+		IndexFresh:        indexFresh,
+		Stats:             stats,
+		DetailedStats:     detailed,
+		PendingChanges:    pending,
+		ConfigSources:     s.session.ConfigSources,
+		MigrationWarning:  s.session.MigrationWarning,
+		IngestionReceipt:  ingestionReceipt,
+		ReceiptError:      receiptError,
+		Freshness:         freshness,
+		Provenance:        buildProvenanceReport(s.session.Config, files),
+		Health:            s.indexHealth(detailed, files, currentProfile, storedProfile, profileStatus, pending),
+		EmbeddingUsage:    embeddingUsage,
+		UsageError:        usageError,
+		PendingEmbeddings: pendingEmbeddings,
+		Quantization:      s.session.DB.Quantization().String(),
+		FullVectorBytes:   fullVectorsSize,
 		// Surface the resolved HNSW parameters so users can confirm their
 		// config tuning is actually applied (Phase 1 wiring). Defaults are
 		// resolved above so a 0 in config shows as veclite's default, not 0.
@@ -208,6 +229,23 @@ func (s *Service) Status(ctx context.Context) (*StatusResponse, error) {
 	}, nil
 }
 
+// embeddingProfileState is the status line for the stored embedding profile
+// against the current one, and whether the index can be searched with it.
+// An index without chunks needs no stored profile yet.
+func embeddingProfileState(current EmbeddingProfile, stored *EmbeddingProfile, loadErr error, hasChunks bool) (string, bool) {
+	switch {
+	case loadErr != nil:
+		return loadErr.Error(), false
+	case stored == nil && hasChunks:
+		return "missing", false
+	case stored == nil:
+		return "not written yet", true
+	case !stored.Matches(current):
+		return "mismatch", false
+	}
+	return "ok", true
+}
+
 // IndexMeta reports a lightweight index-state summary for machine consumers
 // (the `search --format json-envelope` contract): whether the project has been
 // indexed at all, whether the index is fresh (no pending changes), and how many
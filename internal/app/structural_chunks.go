@@ -783,7 +783,11 @@ func structuralChunkType(kind string) index.ChunkType {
 	switch strings.ToLower(kind) {
 	case "function", "method", "test", "constructor":
 		return index.ChunkTypeFunction
-	case "class", "type", "struct", "interface", "enum":
+	case "interface", "trait", "protocol":
+		return index.ChunkTypeInterface
+	case "enum", "constant", "const":
+		return index.ChunkTypeConst
+	case "class", "type", "struct":
 		return index.ChunkTypeClass
 	default:
 		return index.ChunkTypeBlock
@@ -0,0 +1,388 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	summariesSchemaVersion = 1
+	summariesFilename      = "summaries.json"
+
+	// DefaultSummaryCommandTimeout bounds a `summarize --command` run; LLM
+	// calls are slower than hooks.
+	DefaultSummaryCommandTimeout = 2 * time.Minute
+
+	// maxSummarySymbols is how many representative symbols a summary keeps.
+	maxSummarySymbols = 8
+	// maxSummarySamples is how many symbol excerpts a summary command reads.
+	maxSummarySamples = 12
+	// summarySampleLines caps each excerpt handed to a summary command.
+	summarySampleLines = 20
+)
+
+// DirectorySummary is a short description of one indexed directory. It is
+// stored per project next to the index, outside the vector store, so the
+// read-only sessions that write it never take the index's write lock. It is
+// carried by export and import, cleared by reset, and shown by overview
+// output.
+type DirectorySummary struct {
+	Dir       string         `json:"dir"`
+	Summary   string         `json:"summary"`
+	Source    string         `json:"source"` // "heuristic" or "command"
+	Files     int            `json:"files"`
+	Chunks    int            `json:"chunks"`
+	Languages map[string]int `json:"languages,omitempty"`
+	Symbols   []string       `json:"symbols,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// SummarizeOptions configures SummarizeDirectory.
+type SummarizeOptions struct {
+	// Command optionally produces the summary text: it reads a
+	// SummaryRequest as JSON on stdin and writes the summary to stdout.
+	Command string
+	Timeout time.Duration
+}
+
+// SummarySample is one representative symbol excerpt sent to a summary
+// command.
+type SummarySample struct {
+	File    string `json:"file"`
+	Symbol  string `json:"symbol"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// SummaryRequest is the JSON document a summary command reads on stdin.
+type SummaryRequest struct {
+	Dir              string          `json:"dir"`
+	ProjectRoot      string          `json:"project_root"`
+	Files            []string        `json:"files"`
+	Languages        map[string]int  `json:"languages"`
+	Symbols          []string        `json:"symbols"`
+	Samples          []SummarySample `json:"samples"`
+	HeuristicSummary string          `json:"heuristic_summary"`
+}
+
+type summariesFile struct {
+	SchemaVersion int                `json:"schema_version"`
+	Summaries     []DirectorySummary `json:"summaries"`
+}
+
+// summariesMu serializes read-modify-write cycles within one process.
+var summariesMu sync.Mutex
+
+// SummariesPath returns the directory summaries file for a data directory.
+func SummariesPath(dataDir string) string {
+	return filepath.Join(dataDir, summariesFilename)
+}
+
+// SummarizeDirectory describes the indexed files under dir ("." for the whole
+// project) and stores the result, replacing any earlier summary of dir.
+//
+// Symbols are ranked by how close their stored embeddings are to the
+// directory's mean embedding, so the summary names what the directory is
+// mostly about rather than whatever sorts first. Without a command the
+// summary is assembled from a Go package comment, file and symbol counts, and
+// those symbols.
+func (s *Service) SummarizeDirectory(ctx context.Context, dir string, opts SummarizeOptions) (DirectorySummary, error) {
+	if s == nil || s.session == nil {
+		return DirectorySummary{}, fmt.Errorf("service not initialized")
+	}
+	dir = normalizeBookmarkFile(dir)
+	if dir == "" {
+		dir = "."
+	}
+	files, err := s.session.DB.ListFiles(s.session.ProjectRoot)
+	if err != nil {
+		return DirectorySummary{}, fmt.Errorf("list indexed files: %w", err)
+	}
+
+	type candidate struct {
+		file, symbol, chunkType, content string
+		vector                           []float32
+	}
+	summary := DirectorySummary{Dir: dir, Source: "heuristic", Languages: map[string]int{}}
+	var (
+		paths      []string
+		candidates []candidate
+		centroid   []float32
+		typeCounts = map[string]int{}
+		packageDoc string
+	)
+	for _, file := range files {
+		if dir != "." && !strings.HasPrefix(file.RelativePath, dir+"/") {
+			continue
+		}
+		chunks, err := s.session.DB.GetChunksByFile(file.RelativePath)
+		if err != nil {
+			return DirectorySummary{}, fmt.Errorf("read chunks for %s: %w", file.RelativePath, err)
+		}
+		paths = append(paths, file.RelativePath)
+		for _, chunk := range chunks {
+			summary.Chunks++
+			if chunk.Language != "" {
+				summary.Languages[chunk.Language]++
+			}
+			if packageDoc == "" {
+				packageDoc = goPackageDoc(chunk.Content)
+			}
+			if len(chunk.Vector) > 0 {
+				if centroid == nil {
+					centroid = make([]float32, len(chunk.Vector))
+				}
+				if len(chunk.Vector) == len(centroid) {
+					norm := float32(vectorNorm(chunk.Vector))
+					for i, x := range chunk.Vector {
+						if norm > 0 {
+							centroid[i] += x / norm
+						}
+					}
+				}
+			}
+			if chunk.SymbolName == "" {
+				continue
+			}
+			typeCounts[chunk.ChunkType]++
+			candidates = append(candidates, candidate{
+				file:      chunk.RelativePath,
+				symbol:    chunk.SymbolName,
+				chunkType: chunk.ChunkType,
+				content:   chunk.Content,
+				vector:    chunk.Vector,
+			})
+		}
+	}
+	if len(paths) == 0 {
+		return DirectorySummary{}, fmt.Errorf("no indexed files under %s", dir)
+	}
+	summary.Files = len(paths)
+
+	scores := make([]float32, len(candidates))
+	for i, c := range candidates {
+		scores[i] = cosineSimilarity(centroid, c.vector)
+	}
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return scores[order[a]] > scores[order[b]] })
+	var samples []SummarySample
+	for _, i := range order {
+		c := candidates[i]
+		if slices.Contains(summary.Symbols, c.symbol) {
+			continue
+		}
+		if len(summary.Symbols) < maxSummarySymbols {
+			summary.Symbols = append(summary.Symbols, c.symbol)
+		}
+		if len(samples) < maxSummarySamples {
+			samples = append(samples, SummarySample{File: c.file, Symbol: c.symbol, Type: c.chunkType, Content: firstLines(c.content, summarySampleLines)})
+		}
+	}
+
+	summary.Summary = heuristicDirectorySummary(summary, typeCounts, packageDoc)
+	if opts.Command != "" {
+		text, err := runSummaryCommand(ctx, opts, s.session.ProjectRoot, SummaryRequest{
+			Dir:              dir,
+			ProjectRoot:      s.session.ProjectRoot,
+			Files:            paths,
+			Languages:        summary.Languages,
+			Symbols:          summary.Symbols,
+			Samples:          samples,
+			HeuristicSummary: summary.Summary,
+		})
+		if err != nil {
+			return DirectorySummary{}, err
+		}
+		summary.Summary = text
+		summary.Source = "command"
+	}
+	summary.CreatedAt = time.Now().UTC()
+
+	if err := saveDirectorySummary(s.session.Config.DataDir, summary); err != nil {
+		return DirectorySummary{}, err
+	}
+	return summary, nil
+}
+
+// ListDirectorySummaries returns stored summaries sorted by directory.
+func ListDirectorySummaries(dataDir string) ([]DirectorySummary, error) {
+	summariesMu.Lock()
+	defer summariesMu.Unlock()
+	stored, err := loadSummaries(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return stored.Summaries, nil
+}
+
+func saveDirectorySummary(dataDir string, summary DirectorySummary) error {
+	summariesMu.Lock()
+	defer summariesMu.Unlock()
+	stored, err := loadSummaries(dataDir)
+	if err != nil {
+		return err
+	}
+	stored.Summaries = slices.DeleteFunc(stored.Summaries, func(s DirectorySummary) bool { return s.Dir == summary.Dir })
+	stored.Summaries = append(stored.Summaries, summary)
+	sort.Slice(stored.Summaries, func(i, j int) bool { return stored.Summaries[i].Dir < stored.Summaries[j].Dir })
+	if err := writeJSONAtomic(dataDir, SummariesPath(dataDir), stored); err != nil {
+		return fmt.Errorf("write summaries: %w", err)
+	}
+	return nil
+}
+
+// replaceDirectorySummaries stores summaries in place of every earlier one;
+// none removes the file.
+func replaceDirectorySummaries(dataDir string, summaries []DirectorySummary) error {
+	summariesMu.Lock()
+	defer summariesMu.Unlock()
+	if len(summaries) == 0 {
+		if err := os.Remove(SummariesPath(dataDir)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove summaries: %w", err)
+		}
+		return nil
+	}
+	stored := &summariesFile{SchemaVersion: summariesSchemaVersion, Summaries: slices.Clone(summaries)}
+	sort.Slice(stored.Summaries, func(i, j int) bool { return stored.Summaries[i].Dir < stored.Summaries[j].Dir })
+	if err := writeJSONAtomic(dataDir, SummariesPath(dataDir), stored); err != nil {
+		return fmt.Errorf("write summaries: %w", err)
+	}
+	return nil
+}
+
+func loadSummaries(dataDir string) (*summariesFile, error) {
+	if strings.TrimSpace(dataDir) == "" {
+		return nil, fmt.Errorf("summaries data dir is empty")
+	}
+	data, err := os.ReadFile(SummariesPath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return &summariesFile{SchemaVersion: summariesSchemaVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read summaries: %w", err)
+	}
+	var stored summariesFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("decode summaries: %w", err)
+	}
+	if stored.SchemaVersion != summariesSchemaVersion {
+		return nil, fmt.Errorf("unsupported summaries schema version %d", stored.SchemaVersion)
+	}
+	return &stored, nil
+}
+
+// runSummaryCommand sends req to the summary command and returns its trimmed
+// stdout. Stderr is kept for the error message.
+func runSummaryCommand(ctx context.Context, opts SummarizeOptions, projectRoot string, req SummaryRequest) (string, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return "", fmt.Errorf("marshal summary request: %w", err)
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultSummaryCommandTimeout
+	}
+	var stdout, stderr bytes.Buffer
+	if err := runHook(ctx, opts.Command, "summarize", projectRoot, payload, timeout, &stdout, &stderr); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	text := strings.TrimSpace(stdout.String())
+	if text == "" {
+		return "", fmt.Errorf("summarize command %q printed no summary", strings.Fields(opts.Command)[0])
+	}
+	return text, nil
+}
+
+// heuristicDirectorySummary builds a one-paragraph description from the
+// package comment, file and symbol counts, and the representative symbols.
+func heuristicDirectorySummary(summary DirectorySummary, typeCounts map[string]int, packageDoc string) string {
+	var parts []string
+	if packageDoc != "" {
+		parts = append(parts, packageDoc)
+	}
+
+	languages := make([]string, 0, len(summary.Languages))
+	for language := range summary.Languages {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if summary.Languages[languages[i]] != summary.Languages[languages[j]] {
+			return summary.Languages[languages[i]] > summary.Languages[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+	counts := fmt.Sprintf("%d %s", summary.Files, plural(summary.Files, "file", "files"))
+	if len(languages) > 0 {
+		counts += " (" + strings.Join(languages, ", ") + ")"
+	}
+	var kinds []string
+	for _, kind := range []struct{ chunkType, one, many string }{
+		{"interface", "interface", "interfaces"},
+		{"class", "type", "types"},
+		{"const", "constant group", "constant groups"},
+		{"function", "function", "functions"},
+	} {
+		if n := typeCounts[kind.chunkType]; n > 0 {
+			kinds = append(kinds, fmt.Sprintf("%d %s", n, plural(n, kind.one, kind.many)))
+		}
+	}
+	if len(kinds) > 0 {
+		counts += " defining " + strings.Join(kinds, ", ")
+	}
+	parts = append(parts, counts+".")
+	if len(summary.Symbols) > 0 {
+		parts = append(parts, "Central symbols: "+strings.Join(summary.Symbols, ", ")+".")
+	}
+	return strings.Join(parts, " ")
+}
+
+// goPackageDoc returns the first sentence of a "// Package name ..." comment
+// in content, or "".
+func goPackageDoc(content string) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "// Package ") {
+			continue
+		}
+		doc := strings.TrimPrefix(line, "// ")
+		if end := strings.Index(doc, ". "); end >= 0 {
+			doc = doc[:end+1]
+		}
+		if !strings.HasSuffix(doc, ".") {
+			doc += "."
+		}
+		return doc
+	}
+	return ""
+}
+
+func firstLines(content string, n int) string {
+	lines := strings.SplitN(content, "\n", n+1)
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
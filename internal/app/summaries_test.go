@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSummaryFixture(t *testing.T, root string) {
+	t.Helper()
+	files := map[string]string{
+		"payment/provider.go": "// Package payment charges cards. It wraps providers.\npackage payment\n\ntype Provider interface {\n\tCharge() error\n}\n\nfunc NewProvider() Provider {\n\treturn nil\n}\n",
+		"cmd/main.go":         "package main\n\nfunc main() {}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestSummarizeDirectoryHeuristic(t *testing.T) {
+	session, service := createTestSession(t)
+	session.Provider = fakeProvider{dimensions: session.Config.Embedding.Dimensions, model: session.Config.Embedding.Model}
+	writeSummaryFixture(t, session.ProjectRoot)
+	if _, err := service.Index(context.Background(), IndexRequest{FullReindex: true}, nil); err != nil {
+		t.Fatalf("full index failed: %v", err)
+	}
+
+	summary, err := service.SummarizeDirectory(context.Background(), "./payment/", SummarizeOptions{})
+	if err != nil {
+		t.Fatalf("SummarizeDirectory: %v", err)
+	}
+	if summary.Dir != "payment" || summary.Files != 1 || summary.Source != "heuristic" {
+		t.Fatalf("summary = %+v", summary)
+	}
+	for _, want := range []string{"Package payment charges cards.", "1 file (go)", "Provider"} {
+		if !strings.Contains(summary.Summary, want) {
+			t.Errorf("summary %q missing %q", summary.Summary, want)
+		}
+	}
+	if strings.Contains(summary.Summary, "main") {
+		t.Errorf("summary %q includes symbols outside the directory", summary.Summary)
+	}
+
+	if _, err := service.SummarizeDirectory(context.Background(), "missing", SummarizeOptions{}); err == nil {
+		t.Fatal("expected an error for a directory without indexed files")
+	}
+
+	stored, err := ListDirectorySummaries(session.Config.DataDir)
+	if err != nil {
+		t.Fatalf("ListDirectorySummaries: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Summary != summary.Summary {
+		t.Fatalf("stored summaries = %+v", stored)
+	}
+}
+
+func TestSummarizeDirectoryCommandReplacesSummary(t *testing.T) {
+	session, service := createTestSession(t)
+	session.Provider = fakeProvider{dimensions: session.Config.Embedding.Dimensions, model: session.Config.Embedding.Model}
+	writeSummaryFixture(t, session.ProjectRoot)
+	if _, err := service.Index(context.Background(), IndexRequest{FullReindex: true}, nil); err != nil {
+		t.Fatalf("full index failed: %v", err)
+	}
+
+	script := filepath.Join(t.TempDir(), "summarize.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\ngrep -q '\"dir\":\"payment\"' && echo 'Card payment providers.'\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.SummarizeDirectory(context.Background(), "payment", SummarizeOptions{}); err != nil {
+		t.Fatalf("SummarizeDirectory: %v", err)
+	}
+	summary, err := service.SummarizeDirectory(context.Background(), "payment", SummarizeOptions{Command: script})
+	if err != nil {
+		t.Fatalf("SummarizeDirectory with command: %v", err)
+	}
+	if summary.Summary != "Card payment providers." || summary.Source != "command" {
+		t.Fatalf("summary = %+v", summary)
+	}
+	stored, err := ListDirectorySummaries(session.Config.DataDir)
+	if err != nil {
+		t.Fatalf("ListDirectorySummaries: %v", err)
+	}
+	if len(stored) != 1 || stored[0].Source != "command" {
+		t.Fatalf("stored summaries = %+v, want the command summary to replace the heuristic one", stored)
+	}
+
+	if _, err := service.SummarizeDirectory(context.Background(), "payment", SummarizeOptions{Command: "true"}); err == nil {
+		t.Fatal("expected an error when the command prints nothing")
+	}
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+// DefaultSymbolsLimit caps 'vecgrep symbols' output unless overridden.
+const DefaultSymbolsLimit = 100
+
+// SymbolsRequest selects indexed symbols by name.
+type SymbolsRequest struct {
+	// Pattern is matched case-insensitively against the symbol name and the
+	// part after its last dot. Without glob characters it matches anywhere in
+	// the name, so "retry" finds "RetryPolicy" and "withRetry".
+	Pattern   string
+	Languages []string
+	ChunkType string
+	Limit     int
+}
+
+// SymbolMatch is one indexed symbol and where its chunk lives.
+type SymbolMatch struct {
+	Name         string `json:"symbol"`
+	ChunkType    string `json:"chunk_type"`
+	Language     string `json:"language"`
+	RelativePath string `json:"relative_path"`
+	StartLine    int    `json:"start_line"`
+	EndLine      int    `json:"end_line"`
+	ChunkID      int64  `json:"chunk_id"`
+	// Root names the extra project root the symbol was indexed from; empty
+	// for the project root itself.
+	Root string `json:"root,omitempty"`
+}
+
+// Symbols lists the project's indexed symbols whose names match req.Pattern.
+// It reads only the index, so no embedding provider is needed.
+func (s *Service) Symbols(ctx context.Context, req SymbolsRequest) ([]SymbolMatch, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	pattern := strings.TrimSpace(req.Pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("symbol pattern cannot be empty")
+	}
+	if !strings.ContainsAny(pattern, "*?[") {
+		pattern = "*" + pattern + "*"
+	}
+	if req.Limit <= 0 {
+		req.Limit = DefaultSymbolsLimit
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	filter := db.FilterOptions{
+		ProjectRoot: s.session.ProjectRoot,
+		Languages:   req.Languages,
+		ChunkType:   req.ChunkType,
+	}
+	var labels map[string]string
+	filter.ProjectRoots, labels = SearchRoots(s.session.ProjectRoot, s.session.Config)
+	records, err := s.session.DB.FindSymbols(pattern, req.Limit, filter)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]SymbolMatch, 0, len(records))
+	for _, r := range records {
+		matches = append(matches, SymbolMatch{
+			Name:         r.Name,
+			ChunkType:    r.ChunkType,
+			Language:     r.Language,
+			RelativePath: r.RelativePath,
+			StartLine:    r.StartLine,
+			EndLine:      r.EndLine,
+			ChunkID:      r.ChunkID,
+			Root:         labels[r.ProjectRoot],
+		})
+	}
+	return matches, nil
+}
+
+// AmbiguousSymbolError reports a symbol name defined in more than one place.
+// Candidates lists one chunk per definition so the caller can pick by ID.
+type AmbiguousSymbolError struct {
+	Symbol     string
+	Candidates []SymbolMatch
+}
+
+func (e *AmbiguousSymbolError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "symbol %q matches %d definitions; pass a chunk ID instead:\n", e.Symbol, len(e.Candidates))
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	for _, c := range e.Candidates {
+		location := fmt.Sprintf("%s:%d-%d", c.RelativePath, c.StartLine, c.EndLine)
+		if c.Root != "" {
+			location = c.Root + ":" + location
+		}
+		fmt.Fprintf(w, "  %d\t%s\t%s\n", c.ChunkID, c.Name, location)
+	}
+	_ = w.Flush()
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// ResolveSymbol returns the first chunk of the definition named name. Exact
+// matches on the full name win over matches on the part after the last dot,
+// so "Search" prefers a function named Search over the method
+// Searcher.Search. A long definition split into several chunks counts once.
+// It returns ErrSymbolNotFound when nothing matches and an
+// *AmbiguousSymbolError when several definitions do.
+func (s *Service) ResolveSymbol(ctx context.Context, name string) (SymbolMatch, error) {
+	if s == nil || s.session == nil {
+		return SymbolMatch{}, fmt.Errorf("service not initialized")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return SymbolMatch{}, fmt.Errorf("symbol name cannot be empty")
+	}
+	if strings.ContainsAny(name, "*?[") {
+		return SymbolMatch{}, fmt.Errorf("symbol %q must be an exact name; list candidates with 'vecgrep symbols %s'", name, name)
+	}
+	if err := ctx.Err(); err != nil {
+		return SymbolMatch{}, err
+	}
+
+	filter := db.FilterOptions{ProjectRoot: s.session.ProjectRoot}
+	var labels map[string]string
+	filter.ProjectRoots, labels = SearchRoots(s.session.ProjectRoot, s.session.Config)
+	records, err := s.session.DB.FindSymbols(name, 0, filter)
+	if err != nil {
+		return SymbolMatch{}, err
+	}
+	if len(records) == 0 {
+		return SymbolMatch{}, fmt.Errorf("%w: %s (list similar names with 'vecgrep symbols %s')", ErrSymbolNotFound, name, name)
+	}
+	exact := records[:0:0]
+	for _, r := range records {
+		if strings.EqualFold(r.Name, name) {
+			exact = append(exact, r)
+		}
+	}
+	if len(exact) > 0 {
+		records = exact
+	}
+
+	// Records are sorted by name, path, and line, so the first chunk of each
+	// definition comes first.
+	seen := make(map[string]bool, len(records))
+	var definitions []SymbolMatch
+	for _, r := range records {
+		key := r.ProjectRoot + "\x00" + r.RelativePath + "\x00" + r.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		definitions = append(definitions, SymbolMatch{
+			Name:         r.Name,
+			ChunkType:    r.ChunkType,
+			Language:     r.Language,
+			RelativePath: r.RelativePath,
+			StartLine:    r.StartLine,
+			EndLine:      r.EndLine,
+			ChunkID:      r.ChunkID,
+			Root:         labels[r.ProjectRoot],
+		})
+	}
+	if len(definitions) > 1 {
+		return SymbolMatch{}, &AmbiguousSymbolError{Symbol: name, Candidates: definitions}
+	}
+	return definitions[0], nil
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+func insertSymbolChunk(t *testing.T, session *Session, rel, symbol string, start, end int) int64 {
+	t.Helper()
+	chunk := db.NewChunkRecord(
+		filepath.Join(session.ProjectRoot, rel),
+		rel,
+		"hash",
+		64,
+		"go",
+		"func "+symbol+"() {}",
+		start, end, 0, 20,
+		"function",
+		symbol,
+		session.ProjectRoot,
+	)
+	id, err := session.DB.InsertChunk(chunk, make([]float32, session.Config.Embedding.Dimensions))
+	if err != nil {
+		t.Fatalf("InsertChunk failed: %v", err)
+	}
+	return int64(id)
+}
+
+func TestResolveSymbol(t *testing.T) {
+	session, service := createTestSession(t)
+	ctx := context.Background()
+
+	newSearcher := insertSymbolChunk(t, session, "search.go", "NewSearcher", 10, 40)
+	insertSymbolChunk(t, session, "search.go", "NewSearcher", 41, 70)
+	insertSymbolChunk(t, session, "search.go", "Searcher.Search", 80, 120)
+	insertSymbolChunk(t, session, "a/load.go", "Load", 1, 10)
+	insertSymbolChunk(t, session, "b/load.go", "Load", 1, 12)
+
+	match, err := service.ResolveSymbol(ctx, "newsearcher")
+	if err != nil {
+		t.Fatalf("ResolveSymbol(newsearcher) failed: %v", err)
+	}
+	if match.ChunkID != newSearcher || match.StartLine != 10 {
+		t.Fatalf("match = %+v, want first chunk %d of NewSearcher", match, newSearcher)
+	}
+
+	match, err = service.ResolveSymbol(ctx, "Search")
+	if err != nil {
+		t.Fatalf("ResolveSymbol(Search) failed: %v", err)
+	}
+	if match.Name != "Searcher.Search" {
+		t.Fatalf("match = %+v, want the Searcher.Search method", match)
+	}
+
+	_, err = service.ResolveSymbol(ctx, "Load")
+	var ambiguous *AmbiguousSymbolError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("ResolveSymbol(Load) err = %v, want AmbiguousSymbolError", err)
+	}
+	if len(ambiguous.Candidates) != 2 || ambiguous.Candidates[0].RelativePath != "a/load.go" {
+		t.Fatalf("candidates = %+v, want a/load.go and b/load.go", ambiguous.Candidates)
+	}
+
+	if _, err := service.ResolveSymbol(ctx, "Missing"); !errors.Is(err, ErrSymbolNotFound) {
+		t.Fatalf("ResolveSymbol(Missing) err = %v, want ErrSymbolNotFound", err)
+	}
+	if _, err := service.ResolveSymbol(ctx, "New*"); err == nil {
+		t.Fatal("ResolveSymbol accepted a glob pattern")
+	}
+}
+
+func TestParseSimilarTargetSymbol(t *testing.T) {
+	target, err := ParseSimilarTarget("", "", "NewSearcher")
+	if err != nil {
+		t.Fatalf("ParseSimilarTarget failed: %v", err)
+	}
+	if target.Kind != SimilarTargetSymbol || target.Symbol != "NewSearcher" {
+		t.Fatalf("target = %+v, want symbol NewSearcher", target)
+	}
+	if _, err := ParseSimilarTarget("main.go:10", "", "NewSearcher"); err == nil {
+		t.Fatal("expected an error for a positional target with --symbol")
+	}
+	if _, err := ParseSimilarTarget("", "some code", "NewSearcher"); err == nil {
+		t.Fatal("expected an error for --text with --symbol")
+	}
+}
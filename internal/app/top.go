@@ -0,0 +1,132 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Leaderboards reported by Top.
+const (
+	// TopBySimilarity ranks the most similar chunk pairs in different files.
+	TopBySimilarity = "similarity"
+	// TopBySize ranks the largest indexed files by bytes.
+	TopBySize = "size"
+	// TopByChunks ranks files by how many chunks they were split into.
+	TopByChunks = "chunks"
+
+	// DefaultTopLimit is how many entries a leaderboard lists by default.
+	DefaultTopLimit = 10
+)
+
+// TopOptions controls Top.
+type TopOptions struct {
+	By    string
+	Limit int
+	// MinLines skips chunks shorter than this in the similarity board
+	// (default DefaultDuplicateMinLines).
+	MinLines int
+}
+
+// TopFile is one entry of the size and chunks leaderboards.
+type TopFile struct {
+	File     string `json:"file"`
+	Language string `json:"language,omitempty"`
+	Size     int64  `json:"size"`
+	Chunks   int    `json:"chunks"`
+}
+
+// TopReport is one leaderboard. Pairs is set for TopBySimilarity and Files
+// for the others.
+type TopReport struct {
+	By    string          `json:"by"`
+	Pairs []DuplicatePair `json:"pairs,omitempty"`
+	Files []TopFile       `json:"files,omitempty"`
+}
+
+// ValidTopBy reports whether by names a leaderboard.
+func ValidTopBy(by string) bool {
+	switch by {
+	case TopBySimilarity, TopBySize, TopByChunks:
+		return true
+	}
+	return false
+}
+
+// Top builds a leaderboard from the index alone; no provider calls are made.
+// The similarity board reuses the stored vectors the way FindDuplicates
+// does, but keeps only pairs that span two files and has no threshold.
+func (s *Service) Top(ctx context.Context, opts TopOptions) (*TopReport, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	if !ValidTopBy(opts.By) {
+		return nil, fmt.Errorf("unknown leaderboard %q (use %s, %s, or %s)", opts.By, TopBySimilarity, TopBySize, TopByChunks)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultTopLimit
+	}
+	if opts.MinLines <= 0 {
+		opts.MinLines = DefaultDuplicateMinLines
+	}
+
+	report := &TopReport{By: opts.By}
+	if opts.By == TopBySimilarity {
+		pairs, err := s.neighbourPairs(ctx, opts.MinLines, func(a, b DuplicateChunk, _ float32) bool {
+			return a.File != b.File
+		})
+		if err != nil {
+			return nil, err
+		}
+		report.Pairs = make([]DuplicatePair, 0, len(pairs))
+		for _, pair := range pairs {
+			report.Pairs = append(report.Pairs, pair)
+		}
+		sortDuplicatePairs(report.Pairs)
+		if len(report.Pairs) > opts.Limit {
+			report.Pairs = report.Pairs[:opts.Limit]
+		}
+		return report, nil
+	}
+
+	files, err := s.session.DB.ListFiles(s.session.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("list indexed files: %w", err)
+	}
+	entries := make([]TopFile, 0, len(files))
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		chunks, err := s.session.DB.GetChunksByFile(file.RelativePath)
+		if err != nil {
+			return nil, fmt.Errorf("read chunks for %s: %w", file.RelativePath, err)
+		}
+		entries = append(entries, TopFile{File: file.RelativePath, Language: file.Language, Size: file.Size, Chunks: len(chunks)})
+	}
+	report.Files = rankTopFiles(entries, opts.By, opts.Limit)
+	return report, nil
+}
+
+// rankTopFiles sorts files for the size or chunks board, ties broken by the
+// other measure and then by path, and keeps the first limit.
+func rankTopFiles(files []TopFile, by string, limit int) []TopFile {
+	sort.Slice(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		primaryA, primaryB, secondaryA, secondaryB := a.Size, b.Size, int64(a.Chunks), int64(b.Chunks)
+		if by == TopByChunks {
+			primaryA, primaryB, secondaryA, secondaryB = secondaryA, secondaryB, primaryA, primaryB
+		}
+		if primaryA != primaryB {
+			return primaryA > primaryB
+		}
+		if secondaryA != secondaryB {
+			return secondaryA > secondaryB
+		}
+		return a.File < b.File
+	})
+	if len(files) > limit {
+		files = files[:limit]
+	}
+	return files
+}
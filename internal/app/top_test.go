@@ -0,0 +1,40 @@
+package app
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRankTopFiles(t *testing.T) {
+	files := func() []TopFile {
+		return []TopFile{
+			{File: "small.go", Size: 100, Chunks: 9},
+			{File: "big.go", Size: 900, Chunks: 2},
+			{File: "mid.go", Size: 500, Chunks: 9},
+			{File: "also-mid.go", Size: 500, Chunks: 4},
+		}
+	}
+
+	bySize := rankTopFiles(files(), TopBySize, 3)
+	if len(bySize) != 3 || bySize[0].File != "big.go" || bySize[1].File != "mid.go" || bySize[2].File != "also-mid.go" {
+		t.Fatalf("by size = %+v", bySize)
+	}
+	byChunks := rankTopFiles(files(), TopByChunks, 10)
+	if len(byChunks) != 4 || byChunks[0].File != "mid.go" || byChunks[1].File != "small.go" || byChunks[3].File != "big.go" {
+		t.Fatalf("by chunks = %+v", byChunks)
+	}
+}
+
+func TestValidTopBy(t *testing.T) {
+	for _, by := range []string{TopBySimilarity, TopBySize, TopByChunks} {
+		if !ValidTopBy(by) {
+			t.Errorf("ValidTopBy(%q) = false", by)
+		}
+	}
+	if ValidTopBy("lines") {
+		t.Error("ValidTopBy accepted an unknown board")
+	}
+	if _, err := (&Service{}).Top(context.Background(), TopOptions{By: TopBySize}); err == nil {
+		t.Error("Top on an uninitialized service should fail")
+	}
+}
@@ -0,0 +1,162 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+)
+
+const (
+	embeddingUsageSchemaVersion = 1
+	embeddingUsageFilename      = "usage.json"
+)
+
+// EmbeddingUsageRun is the metered traffic of the most recent indexing run.
+type EmbeddingUsageRun struct {
+	StartedAt      time.Time   `json:"started_at"`
+	FinishedAt     time.Time   `json:"finished_at"`
+	Usage          embed.Usage `json:"usage"`
+	BudgetTokens   int64       `json:"budget_tokens,omitempty"`
+	BudgetExceeded bool        `json:"budget_exceeded,omitempty"`
+}
+
+// EmbeddingUsage is the cumulative, persisted account of embedding traffic
+// for one data directory. Counters are scoped to the provider/model pair:
+// switching models requires a rebuild, so earlier traffic is not carried into
+// the new model's cost estimate.
+type EmbeddingUsage struct {
+	SchemaVersion int                `json:"schema_version"`
+	Provider      string             `json:"provider"`
+	Model         string             `json:"model"`
+	Index         embed.Usage        `json:"index"`
+	Search        embed.Usage        `json:"search"`
+	LastRun       *EmbeddingUsageRun `json:"last_run,omitempty"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// Total returns indexing plus search traffic.
+func (u *EmbeddingUsage) Total() embed.Usage {
+	if u == nil {
+		return embed.Usage{}
+	}
+	return u.Index.Add(u.Search)
+}
+
+// EstimatedCostUSD returns the estimated spend for a usage snapshot under the
+// recorded provider/model. ok is false when the price is unknown.
+func (u *EmbeddingUsage) EstimatedCostUSD(usage embed.Usage) (float64, bool) {
+	if u == nil {
+		return 0, false
+	}
+	return embed.EstimateCostUSD(u.Provider, u.Model, usage.Tokens)
+}
+
+// embeddingUsageMu serializes read-modify-write cycles within one process.
+// Concurrent processes may race; the file is an estimate, not a ledger.
+var embeddingUsageMu sync.Mutex
+
+// EmbeddingUsagePath returns the usage file for a data directory.
+func EmbeddingUsagePath(dataDir string) string {
+	return filepath.Join(dataDir, embeddingUsageFilename)
+}
+
+// LoadEmbeddingUsage reads the persisted usage counters. A missing file is
+// (nil, nil).
+func LoadEmbeddingUsage(dataDir string) (*EmbeddingUsage, error) {
+	if strings.TrimSpace(dataDir) == "" {
+		return nil, fmt.Errorf("embedding usage data dir is empty")
+	}
+	data, err := os.ReadFile(EmbeddingUsagePath(dataDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read embedding usage: %w", err)
+	}
+	var usage EmbeddingUsage
+	if err := json.Unmarshal(data, &usage); err != nil {
+		return nil, fmt.Errorf("decode embedding usage: %w", err)
+	}
+	if usage.SchemaVersion != embeddingUsageSchemaVersion {
+		return nil, fmt.Errorf("unsupported embedding usage schema version %d", usage.SchemaVersion)
+	}
+	return &usage, nil
+}
+
+// recordEmbeddingUsage adds index and search deltas to the persisted counters
+// and, when run is non-nil, replaces the last-run record.
+func recordEmbeddingUsage(cfg *config.Config, indexDelta, searchDelta embed.Usage, run *EmbeddingUsageRun) error {
+	if cfg == nil || cfg.DataDir == "" {
+		return nil
+	}
+	if indexDelta.IsZero() && searchDelta.IsZero() && run == nil {
+		return nil
+	}
+	embeddingUsageMu.Lock()
+	defer embeddingUsageMu.Unlock()
+
+	usage, err := LoadEmbeddingUsage(cfg.DataDir)
+	if err != nil {
+		// A corrupt file only loses an estimate; start over rather than
+		// failing the operation that produced the traffic.
+		usage = nil
+	}
+	if usage == nil || usage.Provider != cfg.Embedding.Provider || usage.Model != cfg.Embedding.Model {
+		usage = &EmbeddingUsage{
+			SchemaVersion: embeddingUsageSchemaVersion,
+			Provider:      cfg.Embedding.Provider,
+			Model:         cfg.Embedding.Model,
+		}
+	}
+	usage.Index = usage.Index.Add(indexDelta)
+	usage.Search = usage.Search.Add(searchDelta)
+	if run != nil {
+		usage.LastRun = run
+	}
+	usage.UpdatedAt = time.Now().UTC()
+	return writeEmbeddingUsage(cfg.DataDir, usage)
+}
+
+func writeEmbeddingUsage(dataDir string, usage *EmbeddingUsage) error {
+	if err := writeJSONAtomic(dataDir, EmbeddingUsagePath(dataDir), usage); err != nil {
+		return fmt.Errorf("write embedding usage: %w", err)
+	}
+	return nil
+}
+
+// writeJSONAtomic encodes v as indented JSON and renames it over path, so a
+// crash never leaves a half-written file behind.
+func writeJSONAtomic(dataDir, path string, v any) error {
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return fmt.Errorf("create data dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(dataDir, "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+	encoder := json.NewEncoder(tmp)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("encode: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("replace: %w", err)
+	}
+	return nil
+}
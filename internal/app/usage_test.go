@@ -0,0 +1,49 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+)
+
+func TestRecordEmbeddingUsageAccumulatesAndResetsOnModelChange(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.DataDir = t.TempDir()
+	cfg.Embedding.Provider = "openai"
+	cfg.Embedding.Model = "text-embedding-3-small"
+
+	if usage, err := LoadEmbeddingUsage(cfg.DataDir); err != nil || usage != nil {
+		t.Fatalf("missing usage = %+v, %v; want nil, nil", usage, err)
+	}
+	run := &EmbeddingUsageRun{Usage: embed.Usage{Requests: 1, Texts: 2, Tokens: 500_000}}
+	if err := recordEmbeddingUsage(cfg, run.Usage, embed.Usage{}, run); err != nil {
+		t.Fatalf("record index usage: %v", err)
+	}
+	if err := recordEmbeddingUsage(cfg, embed.Usage{}, embed.Usage{Requests: 1, Texts: 1, Tokens: 500_000}, nil); err != nil {
+		t.Fatalf("record search usage: %v", err)
+	}
+
+	usage, err := LoadEmbeddingUsage(cfg.DataDir)
+	if err != nil || usage == nil {
+		t.Fatalf("LoadEmbeddingUsage = %+v, %v", usage, err)
+	}
+	if usage.Total().Tokens != 1_000_000 || usage.LastRun == nil || usage.LastRun.Usage.Tokens != 500_000 {
+		t.Fatalf("usage = %+v", usage)
+	}
+	if cost, ok := usage.EstimatedCostUSD(usage.Total()); !ok || cost != 0.02 {
+		t.Fatalf("estimated cost = %v, %v; want 0.02", cost, ok)
+	}
+
+	cfg.Embedding.Model = "text-embedding-3-large"
+	if err := recordEmbeddingUsage(cfg, embed.Usage{Requests: 1, Texts: 1, Tokens: 10}, embed.Usage{}, nil); err != nil {
+		t.Fatalf("record after model change: %v", err)
+	}
+	usage, err = LoadEmbeddingUsage(cfg.DataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Model != "text-embedding-3-large" || usage.Total().Tokens != 10 || usage.LastRun != nil {
+		t.Fatalf("usage after model change = %+v", usage)
+	}
+}
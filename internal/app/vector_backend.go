@@ -0,0 +1,58 @@
+package app
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+// QdrantOptions returns the Qdrant connection for db.OpenOptions, or nil
+// when the config keeps vectors in VecLite. Without a configured collection
+// name, each data directory gets its own collection so projects sharing a
+// Qdrant instance never see each other's points.
+func QdrantOptions(cfg *config.Config) *db.QdrantConfig {
+	if cfg == nil || cfg.Vector.Backend != string(db.VectorBackendQdrant) {
+		return nil
+	}
+	qdrant := &db.QdrantConfig{
+		URL:        cfg.Vector.Qdrant.URL,
+		APIKey:     cfg.Vector.Qdrant.APIKey,
+		Collection: cfg.Vector.Qdrant.Collection,
+	}
+	if qdrant.URL == "" {
+		qdrant.URL = config.DefaultQdrantURL
+	}
+	if qdrant.Collection == "" {
+		qdrant.Collection = defaultVectorStoreName(cfg.DataDir)
+	}
+	return qdrant
+}
+
+// PgvectorOptions returns the Postgres connection for db.OpenOptions, or nil
+// when the config does not use the pgvector backend. Without a configured
+// table name, each data directory gets its own table.
+func PgvectorOptions(cfg *config.Config) *db.PgvectorConfig {
+	if cfg == nil || cfg.Vector.Backend != string(db.VectorBackendPgvector) {
+		return nil
+	}
+	pgvector := &db.PgvectorConfig{
+		URL:   cfg.Vector.Pgvector.URL,
+		Table: cfg.Vector.Pgvector.Table,
+	}
+	if pgvector.Table == "" {
+		pgvector.Table = defaultVectorStoreName(cfg.DataDir)
+	}
+	return pgvector
+}
+
+// defaultVectorStoreName names the collection or table of an index kept in
+// an external vector store after its data directory.
+func defaultVectorStoreName(dataDir string) string {
+	if abs, err := filepath.Abs(dataDir); err == nil {
+		dataDir = abs
+	}
+	return fmt.Sprintf("vecgrep_%x", sha256.Sum256([]byte(dataDir)))[:len("vecgrep_")+16]
+}
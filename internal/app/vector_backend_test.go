@@ -0,0 +1,50 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+)
+
+func TestQdrantOptions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	if QdrantOptions(cfg) != nil {
+		t.Fatal("default config must not use qdrant")
+	}
+
+	cfg.Vector.Backend = "qdrant"
+	cfg.DataDir = "/work/a/.vecgrep"
+	first := QdrantOptions(cfg)
+	if first == nil || first.URL != config.DefaultQdrantURL || !strings.HasPrefix(first.Collection, "vecgrep_") {
+		t.Fatalf("QdrantOptions() = %+v, want the default URL and a derived collection", first)
+	}
+	cfg.DataDir = "/work/b/.vecgrep"
+	if second := QdrantOptions(cfg); second.Collection == first.Collection {
+		t.Fatal("indexes in different data directories must not share a collection")
+	}
+	cfg.Vector.Qdrant.Collection = "shared"
+	if got := QdrantOptions(cfg).Collection; got != "shared" {
+		t.Fatalf("Collection = %q, want the configured name", got)
+	}
+}
+
+func TestPgvectorOptions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Vector.Backend = "qdrant"
+	if PgvectorOptions(cfg) != nil {
+		t.Fatal("qdrant config must not use pgvector")
+	}
+
+	cfg.Vector.Backend = "pgvector"
+	cfg.Vector.Pgvector.URL = "postgres://db/team"
+	cfg.DataDir = "/work/a/.vecgrep"
+	derived := PgvectorOptions(cfg)
+	if derived == nil || derived.URL != "postgres://db/team" || !strings.HasPrefix(derived.Table, "vecgrep_") {
+		t.Fatalf("PgvectorOptions() = %+v, want the URL and a derived table", derived)
+	}
+	cfg.Vector.Pgvector.Table = "team_vectors"
+	if got := PgvectorOptions(cfg).Table; got != "team_vectors" {
+		t.Fatalf("Table = %q, want the configured name", got)
+	}
+}
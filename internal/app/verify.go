@@ -0,0 +1,197 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+)
+
+const (
+	// DefaultEmbeddingVerifySample is how many chunks verify re-embeds when
+	// no sample size is given.
+	DefaultEmbeddingVerifySample = 20
+
+	// embeddingDriftThreshold is the cosine similarity between a stored
+	// vector and a fresh embedding of the same chunk below which the sample
+	// counts as drifted. Structural chunks are embedded with extra context
+	// that is not stored, so they legitimately land a little below 1.
+	embeddingDriftThreshold = 0.90
+	// embeddingDriftTolerance is the fraction of drifted samples tolerated
+	// before the index is reported unhealthy.
+	embeddingDriftTolerance = 0.25
+	// embeddingNormTolerance is the relative difference in mean vector norm
+	// reported as a normalization change.
+	embeddingNormTolerance = 0.05
+)
+
+// EmbeddingDriftSample is one re-embedded chunk whose fresh vector differs
+// from the stored one.
+type EmbeddingDriftSample struct {
+	File       string  `json:"file"`
+	StartLine  int     `json:"start_line"`
+	EndLine    int     `json:"end_line"`
+	Similarity float32 `json:"similarity"`
+}
+
+// EmbeddingVerification reports how well the current provider reproduces the
+// vectors stored in the index.
+type EmbeddingVerification struct {
+	Provider           string                 `json:"provider"`
+	Model              string                 `json:"model"`
+	IndexDimensions    int                    `json:"index_dimensions"`
+	ProviderDimensions int                    `json:"provider_dimensions"`
+	ProfileError       string                 `json:"profile_error,omitempty"`
+	Sampled            int                    `json:"sampled"`
+	MeanSimilarity     float32                `json:"mean_similarity"`
+	MinSimilarity      float32                `json:"min_similarity"`
+	StoredNorm         float64                `json:"stored_norm"`
+	CurrentNorm        float64                `json:"current_norm"`
+	Drifted            []EmbeddingDriftSample `json:"drifted,omitempty"`
+	Findings           []string               `json:"findings,omitempty"`
+	Healthy            bool                   `json:"healthy"`
+}
+
+// VerifyEmbeddings samples up to sampleSize indexed chunks (one per file,
+// spread evenly across the sorted file list), re-embeds them with the current
+// provider, and compares the result with the stored vectors. It reports a
+// changed model or profile, a dimension mismatch, per-chunk drift, and a
+// change in vector normalization. Provider failures are returned as errors.
+func (s *Service) VerifyEmbeddings(ctx context.Context, sampleSize int) (*EmbeddingVerification, error) {
+	if s == nil || s.session == nil {
+		return nil, fmt.Errorf("service not initialized")
+	}
+	if s.session.Provider == nil {
+		return nil, ErrProviderRequired
+	}
+	if sampleSize <= 0 {
+		sampleSize = DefaultEmbeddingVerifySample
+	}
+	cfg := s.session.Config
+	report := &EmbeddingVerification{
+		Provider:           cfg.Embedding.Provider,
+		Model:              s.session.Provider.Model(),
+		IndexDimensions:    s.session.DB.Dimensions(),
+		ProviderDimensions: s.session.Provider.Dimensions(),
+		Healthy:            true,
+	}
+
+	if err := s.ensureEmbeddingProfileMatches(); err != nil {
+		var mismatch *EmbeddingProfileMismatchError
+		if !errors.As(err, &mismatch) {
+			return nil, err
+		}
+		report.ProfileError = err.Error()
+		report.Findings = append(report.Findings, "embedding configuration differs from the one the index was built with")
+		report.Healthy = false
+	}
+	if report.IndexDimensions != report.ProviderDimensions {
+		report.Findings = append(report.Findings, fmt.Sprintf("provider returns %d dimensions but the index stores %d", report.ProviderDimensions, report.IndexDimensions))
+		report.Healthy = false
+		return report, nil
+	}
+
+	files, err := s.session.DB.ListFiles(s.session.ProjectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("list indexed files: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].RelativePath < files[j].RelativePath })
+
+	type sample struct {
+		file               string
+		startLine, endLine int
+		content            string
+		stored             []float32
+	}
+	var samples []sample
+	for _, i := range evenlySpaced(len(files), sampleSize) {
+		chunks, err := s.session.DB.GetChunksByFile(files[i].RelativePath)
+		if err != nil || len(chunks) == 0 {
+			continue
+		}
+		chunk := chunks[len(chunks)/2]
+		if chunk.Content == "" {
+			continue
+		}
+		stored, err := s.session.DB.GetEmbedding(int64(chunk.ID))
+		if err != nil || len(stored) == 0 {
+			continue
+		}
+		samples = append(samples, sample{file: chunk.RelativePath, startLine: chunk.StartLine, endLine: chunk.EndLine, content: chunk.Content, stored: stored})
+	}
+	if len(samples) == 0 {
+		report.Findings = append(report.Findings, "no indexed chunks to sample; run 'vecgrep index' first")
+		return report, nil
+	}
+
+	texts := make([]string, len(samples))
+	for i, smp := range samples {
+		texts[i] = smp.content
+	}
+	var fresh [][]float32
+	if documents, ok := s.session.Provider.(embed.DocumentProvider); ok {
+		fresh, err = documents.EmbedDocuments(ctx, texts)
+	} else {
+		fresh, err = s.session.Provider.EmbedBatch(ctx, texts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("re-embed sampled chunks: %w", err)
+	}
+	if len(fresh) != len(samples) {
+		return nil, fmt.Errorf("re-embed sampled chunks: provider returned %d embeddings for %d texts", len(fresh), len(samples))
+	}
+
+	report.Sampled = len(samples)
+	report.MinSimilarity = 1
+	var simSum float32
+	for i, smp := range samples {
+		sim := cosineSimilarity(smp.stored, fresh[i])
+		simSum += sim
+		report.MinSimilarity = min(report.MinSimilarity, sim)
+		report.StoredNorm += vectorNorm(smp.stored)
+		report.CurrentNorm += vectorNorm(fresh[i])
+		if sim < embeddingDriftThreshold {
+			report.Drifted = append(report.Drifted, EmbeddingDriftSample{File: smp.file, StartLine: smp.startLine, EndLine: smp.endLine, Similarity: sim})
+		}
+	}
+	report.MeanSimilarity = simSum / float32(len(samples))
+	report.StoredNorm /= float64(len(samples))
+	report.CurrentNorm /= float64(len(samples))
+
+	if drifted := len(report.Drifted); drifted > 0 {
+		report.Findings = append(report.Findings, fmt.Sprintf("%d of %d sampled chunks re-embed with similarity below %.2f", drifted, len(samples), embeddingDriftThreshold))
+		if float64(drifted) > embeddingDriftTolerance*float64(len(samples)) {
+			report.Healthy = false
+		}
+	}
+	if report.StoredNorm > 0 && math.Abs(report.CurrentNorm-report.StoredNorm)/report.StoredNorm > embeddingNormTolerance {
+		// Cosine ranking ignores scale, so this is informational unless it
+		// comes with drift. Fresh vectors are always L2-normalized, so it
+		// means the stored vectors predate normalization enforcement.
+		report.Findings = append(report.Findings, fmt.Sprintf("mean vector norm changed from %.3f to %.3f (stored vectors are not unit length)", report.StoredNorm, report.CurrentNorm))
+	}
+	return report, nil
+}
+
+// evenlySpaced returns up to k indexes spread evenly over [0, n).
+func evenlySpaced(n, k int) []int {
+	if k >= n {
+		k = n
+	}
+	indexes := make([]int, 0, k)
+	for i := 0; i < k; i++ {
+		indexes = append(indexes, i*n/k)
+	}
+	return indexes
+}
+
+func vectorNorm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
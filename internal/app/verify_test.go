@@ -0,0 +1,61 @@
+package app
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// shiftedProvider embeds every text onto a different axis than fakeProvider,
+// standing in for a provider whose output changed under the same model name.
+type shiftedProvider struct{ fakeProvider }
+
+func (p shiftedProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i := range texts {
+		embeddings[i] = make([]float32, p.dimensions)
+		embeddings[i][1] = 1
+	}
+	return embeddings, nil
+}
+
+func TestVerifyEmbeddingsDetectsDrift(t *testing.T) {
+	session, service := createTestSession(t)
+	provider := fakeProvider{dimensions: session.Config.Embedding.Dimensions, model: session.Config.Embedding.Model}
+	session.Provider = provider
+	for _, name := range []string{"a.go", "b.go"} {
+		if err := os.WriteFile(filepath.Join(session.ProjectRoot, name), []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := service.Index(context.Background(), IndexRequest{FullReindex: true}, nil); err != nil {
+		t.Fatalf("full index failed: %v", err)
+	}
+
+	report, err := service.VerifyEmbeddings(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("VerifyEmbeddings: %v", err)
+	}
+	if !report.Healthy || report.Sampled != 2 || report.MinSimilarity < 0.99 || len(report.Findings) != 0 {
+		t.Fatalf("same provider report = %+v", report)
+	}
+
+	session.Provider = shiftedProvider{provider}
+	report, err = service.VerifyEmbeddings(context.Background(), 10)
+	if err != nil {
+		t.Fatalf("VerifyEmbeddings: %v", err)
+	}
+	if report.Healthy || len(report.Drifted) != 2 {
+		t.Fatalf("shifted provider report = %+v, want 2 drifted and unhealthy", report)
+	}
+}
+
+func TestEvenlySpaced(t *testing.T) {
+	if got := evenlySpaced(10, 3); len(got) != 3 || got[0] != 0 || got[1] != 3 || got[2] != 6 {
+		t.Fatalf("evenlySpaced(10, 3) = %v", got)
+	}
+	if got := evenlySpaced(2, 5); len(got) != 2 {
+		t.Fatalf("evenlySpaced(2, 5) = %v", got)
+	}
+}
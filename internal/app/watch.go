@@ -0,0 +1,68 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// MatchTracker remembers which results of a watched query were above the
+// score threshold on the previous run, so a re-run after file changes reports
+// only matches that newly appeared. A match that drops out and later comes
+// back is reported again.
+type MatchTracker struct {
+	minScore float32
+	previous map[string]bool
+}
+
+// NewMatchTracker returns a tracker that ignores results scoring below
+// minScore.
+func NewMatchTracker(minScore float32) *MatchTracker {
+	return &MatchTracker{minScore: minScore, previous: map[string]bool{}}
+}
+
+// Update records this run's qualifying results and returns those that were
+// not present on the previous run. Annotations are not code matches and are
+// ignored.
+func (t *MatchTracker) Update(results []search.Result) []search.Result {
+	current := make(map[string]bool, len(results))
+	var fresh []search.Result
+	for _, r := range results {
+		if r.Annotation || r.Score < t.minScore {
+			continue
+		}
+		key := ChunkKey(r.RelativePath, r.StartLine, r.EndLine)
+		if current[key] {
+			continue
+		}
+		current[key] = true
+		if !t.previous[key] {
+			fresh = append(fresh, r)
+		}
+	}
+	t.previous = current
+	return fresh
+}
+
+// WatchNotification is the JSON document a `vecgrep watch --exec` command
+// reads on stdin.
+type WatchNotification struct {
+	Query       string          `json:"query"`
+	MinScore    float32         `json:"min_score"`
+	ProjectRoot string          `json:"project_root"`
+	Matches     []search.Result `json:"matches"`
+}
+
+// NotifyWatchMatches runs command in the project root with n on stdin and
+// VECGREP_HOOK=watch in the environment, using the same runner as
+// hooks.post_search.
+func NotifyWatchMatches(ctx context.Context, command string, n WatchNotification, output io.Writer) error {
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("marshal watch notification: %w", err)
+	}
+	return runHook(ctx, command, "watch", n.ProjectRoot, payload, DefaultHookTimeout, output, output)
+}
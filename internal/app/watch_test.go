@@ -0,0 +1,29 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+func TestMatchTrackerReportsOnlyNewMatchesAboveThreshold(t *testing.T) {
+	tracker := NewMatchTracker(0.8)
+	a := search.Result{RelativePath: "a.go", StartLine: 1, EndLine: 5, Score: 0.9}
+	b := search.Result{RelativePath: "b.go", StartLine: 1, EndLine: 5, Score: 0.85}
+	low := search.Result{RelativePath: "c.go", StartLine: 1, EndLine: 5, Score: 0.5}
+	note := search.Result{RelativePath: "d.go", StartLine: 1, EndLine: 1, Score: 0.95, Annotation: true}
+
+	if fresh := tracker.Update([]search.Result{a, low, note}); len(fresh) != 1 || fresh[0].RelativePath != "a.go" {
+		t.Fatalf("baseline = %+v, want a.go only", fresh)
+	}
+	if fresh := tracker.Update([]search.Result{a, b, low}); len(fresh) != 1 || fresh[0].RelativePath != "b.go" {
+		t.Fatalf("second run = %+v, want b.go only", fresh)
+	}
+	// a.go drops out, then returns: it is reported again.
+	if fresh := tracker.Update([]search.Result{b}); len(fresh) != 0 {
+		t.Fatalf("third run = %+v, want none", fresh)
+	}
+	if fresh := tracker.Update([]search.Result{a, b}); len(fresh) != 1 || fresh[0].RelativePath != "a.go" {
+		t.Fatalf("fourth run = %+v, want a.go again", fresh)
+	}
+}
@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 )
 
 // boolPtr returns a pointer to the given bool value.
@@ -53,9 +55,29 @@ type Config struct {
 	// snapshot/restore integration.
 	Cache CacheConfig `mapstructure:"cache" yaml:"cache,omitempty"`
 
+	// Chunkers maps file extensions (without the dot) to external chunker
+	// commands that replace the built-in chunker for those files.
+	Chunkers map[string]ExternalChunkerConfig `mapstructure:"chunkers" yaml:"chunkers,omitempty"`
+
+	// Hooks configures commands run after vecgrep operations.
+	Hooks HooksConfig `mapstructure:"hooks" yaml:"hooks,omitempty"`
+
+	// Security configures at-rest protection of the index.
+	Security SecurityConfig `mapstructure:"security" yaml:"security,omitempty"`
+
 	present map[string]bool `mapstructure:"-" yaml:"-"`
 }
 
+// HooksConfig holds user commands run after CLI operations. Each command is
+// split on whitespace and run without a shell.
+type HooksConfig struct {
+	// PostSearch commands receive each CLI search's query and results as JSON
+	// on stdin once the results are printed.
+	PostSearch []string `mapstructure:"post_search" yaml:"post_search,omitempty"`
+	// Timeout bounds each hook command. Zero uses the default (10s).
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout,omitempty"`
+}
+
 // CacheConfig holds settings for the embedding disk cache and its fcheap
 // snapshot/restore integration. When FcheapStash is enabled (default), the
 // embedding cache is auto-stashed to fcheap after indexing and restored
@@ -86,6 +108,27 @@ func (c *CacheConfig) FcheapStashEnabled() bool {
 	return *c.FcheapStash
 }
 
+// ExternalChunkerConfig declares an external chunker process. The command is
+// split on whitespace and run without a shell; it reads the file on stdin and
+// writes chunk JSON to stdout (see index.ExternalChunkerSpec). Like
+// codemap.bin, it executes whatever the project config names, so only index
+// repositories whose config you trust.
+type ExternalChunkerConfig struct {
+	Cmd     string        `mapstructure:"cmd" yaml:"cmd"`
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout,omitempty"`
+}
+
+// UnmarshalYAML also accepts the scalar shorthand `proto: "protoc-chunker"`
+// (or `"cmd: protoc-chunker"`) for a command with the default timeout.
+func (c *ExternalChunkerConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		c.Cmd = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(node.Value), "cmd:"))
+		return nil
+	}
+	type plain ExternalChunkerConfig
+	return node.Decode((*plain)(c))
+}
+
 // SearchConfig holds search-related settings
 type SearchConfig struct {
 	// DefaultMode is the default search mode: "semantic", "keyword", or "hybrid"
@@ -94,12 +137,93 @@ type SearchConfig struct {
 	VectorWeight float32 `mapstructure:"vector_weight" yaml:"vector_weight,omitempty"`
 	// TextWeight is the weight for text matching in hybrid search (0-1)
 	TextWeight float32 `mapstructure:"text_weight" yaml:"text_weight,omitempty"`
+	// Fusion selects how hybrid search combines the vector and keyword
+	// rankings: "weighted" (default) adds weighted scores, "rrf" uses
+	// reciprocal rank fusion rescaled to 0-1.
+	Fusion string `mapstructure:"fusion" yaml:"fusion,omitempty"`
+	// RerankerURL is an HTTP service that reorders results after retrieval.
+	// Empty disables external reranking.
+	RerankerURL string `mapstructure:"reranker_url" yaml:"reranker_url,omitempty"`
+	// RerankerTimeout bounds one reranker request. Zero uses the default (5s,
+	// or 30s for model rerankers).
+	RerankerTimeout time.Duration `mapstructure:"reranker_timeout" yaml:"reranker_timeout,omitempty"`
+	// Reranker selects the reranking stage: "http" (reranker_url protocol),
+	// "ollama" or "openai" (model-scored cross-encoder pass), or "none".
+	// Empty means "http" when reranker_url is set.
+	Reranker string `mapstructure:"reranker" yaml:"reranker,omitempty"`
+	// RerankerModel is the model used by the "ollama" and "openai" rerankers.
+	RerankerModel string `mapstructure:"reranker_model" yaml:"reranker_model,omitempty"`
+	// RerankerAPIKey is an optional bearer token for the "openai" reranker.
+	RerankerAPIKey string `mapstructure:"reranker_api_key" yaml:"reranker_api_key,omitempty"`
+	// RerankTopN is how many leading results a model reranker scores.
+	// Zero uses the default (20).
+	RerankTopN int `mapstructure:"rerank_top_n" yaml:"rerank_top_n,omitempty"`
+	// TranslatorURL is a LibreTranslate-compatible service that rewrites
+	// non-English queries into English before embedding. Empty disables
+	// query translation.
+	TranslatorURL string `mapstructure:"translator_url" yaml:"translator_url,omitempty"`
+	// TranslatorTimeout bounds one translation request. Zero uses the default (5s).
+	TranslatorTimeout time.Duration `mapstructure:"translator_timeout" yaml:"translator_timeout,omitempty"`
+	// ExpanderModel is an Ollama model that paraphrases queries for
+	// --expand searches. Empty uses the built-in synonym templates.
+	ExpanderModel string `mapstructure:"expander_model" yaml:"expander_model,omitempty"`
+	// ExpanderURL is the Ollama server for ExpanderModel. Empty uses
+	// embedding.ollama_url.
+	ExpanderURL string `mapstructure:"expander_url" yaml:"expander_url,omitempty"`
+	// Timeout is the default deadline for a whole search. When it passes,
+	// the results found so far are returned and marked partial instead of
+	// failing. Zero means no deadline.
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout,omitempty"`
+	// InferFilters controls whether the vecgrep_search MCP tool reads
+	// filters from the wording of a query ("python tests for the auth
+	// flow") before embedding it. Nil means enabled.
+	InferFilters *bool `mapstructure:"infer_filters" yaml:"infer_filters,omitempty"`
+}
+
+// InferFiltersEnabled reports whether filters are inferred from query
+// wording. Defaults to true when InferFilters is nil.
+func (c *SearchConfig) InferFiltersEnabled() bool {
+	if c == nil || c.InferFilters == nil {
+		return true
+	}
+	return *c.InferFilters
 }
 
 // VectorConfig holds vector backend settings
 type VectorConfig struct {
+	// Backend selects where chunks are stored for vector search:
+	// "veclite" (default), "qdrant", or "pgvector". Keyword search stays
+	// in VecLite either way.
+	Backend string `mapstructure:"backend" yaml:"backend,omitempty"`
 	// VecLite holds VecLite-specific configuration (HNSW parameters)
 	VecLite VecLiteConfig `mapstructure:"veclite" yaml:"veclite,omitempty"`
+	// Qdrant holds the Qdrant connection used when Backend is "qdrant"
+	Qdrant QdrantConfig `mapstructure:"qdrant" yaml:"qdrant,omitempty"`
+	// Pgvector holds the Postgres connection used when Backend is "pgvector"
+	Pgvector PgvectorConfig `mapstructure:"pgvector" yaml:"pgvector,omitempty"`
+}
+
+// QdrantConfig holds Qdrant backend settings
+type QdrantConfig struct {
+	// URL is the Qdrant REST endpoint (default: DefaultQdrantURL)
+	URL string `mapstructure:"url" yaml:"url,omitempty"`
+	// APIKey is sent as the api-key header for secured or cloud instances
+	APIKey string `mapstructure:"api_key" yaml:"api_key,omitempty"`
+	// Collection names the Qdrant collection (default: derived from the
+	// data directory; set the same name on every client sharing the index)
+	Collection string `mapstructure:"collection" yaml:"collection,omitempty"`
+}
+
+// DefaultQdrantURL is the REST endpoint of a local Qdrant instance.
+const DefaultQdrantURL = "http://localhost:6333"
+
+// PgvectorConfig holds pgvector backend settings
+type PgvectorConfig struct {
+	// URL is the postgres:// connection URL, including credentials
+	URL string `mapstructure:"url" yaml:"url,omitempty"`
+	// Table names the table holding the chunks (default: derived from the
+	// data directory; set the same name on every client sharing the index)
+	Table string `mapstructure:"table" yaml:"table,omitempty"`
 }
 
 // VecLiteConfig holds VecLite backend settings
@@ -110,6 +234,30 @@ type VecLiteConfig struct {
 	EfConstruction int `mapstructure:"ef_construction" yaml:"ef_construction,omitempty"`
 	// EfSearch is the HNSW search quality parameter (default: DefaultVecLiteEfSearch = 100)
 	EfSearch int `mapstructure:"ef_search" yaml:"ef_search,omitempty"`
+	// Quantization stores vectors as "int8" or "binary" codes and re-scores
+	// the top candidates from a full-precision sidecar (default: none)
+	Quantization string `mapstructure:"quantization" yaml:"quantization,omitempty"`
+	// Hot controls the hot tier: the chunks of files indexed within
+	// HotWindow, searched exhaustively and merged with the HNSW results
+	// (default: true)
+	Hot *bool `mapstructure:"hot" yaml:"hot,omitempty"`
+	// HotWindow is how recently a file must have been indexed to be hot
+	// (default: DefaultVecLiteHotWindow = 24h)
+	HotWindow time.Duration `mapstructure:"hot_window" yaml:"hot_window,omitempty"`
+	// HotMaxChunks caps the hot tier, newest chunks first (default: DefaultVecLiteHotMaxChunks = 2000)
+	HotMaxChunks int `mapstructure:"hot_max_chunks" yaml:"hot_max_chunks,omitempty"`
+}
+
+// HotTierWindow returns the hot tier window to open the index with, or 0
+// when the tier is turned off.
+func (c *VecLiteConfig) HotTierWindow() time.Duration {
+	if c == nil || (c.Hot != nil && !*c.Hot) {
+		return 0
+	}
+	if c.HotWindow > 0 {
+		return c.HotWindow
+	}
+	return DefaultVecLiteHotWindow
 }
 
 // Default HNSW parameters for VecLite. Exposed so callers (status views,
@@ -120,6 +268,12 @@ const (
 	DefaultVecLiteEfSearch       = 100
 )
 
+// Default hot tier settings for VecLite.
+const (
+	DefaultVecLiteHotWindow    = 24 * time.Hour
+	DefaultVecLiteHotMaxChunks = 2000
+)
+
 // ThrottleConfig configures the ThrottledProvider that wraps the raw
 // embedding provider. The same struct is reused for both the CLI path
 // (Config.Embedding.Throttle) and the daemon path (Config.Daemon). When
@@ -143,7 +297,7 @@ type ThrottleConfig struct {
 
 // EmbeddingConfig holds embedding provider settings
 type EmbeddingConfig struct {
-	// Provider is the embedding provider: "ollama", "openai", "cohere", or "voyage"
+	// Provider is the embedding provider: "ollama", "openai", "cohere", "voyage", "tei", "exec", or "onnx"
 	Provider string `mapstructure:"provider" yaml:"provider,omitempty"`
 	// Model is the embedding model name
 	Model string `mapstructure:"model" yaml:"model,omitempty"`
@@ -163,16 +317,55 @@ type EmbeddingConfig struct {
 	VoyageAPIKey string `mapstructure:"voyage_api_key" yaml:"voyage_api_key,omitempty"`
 	// VoyageBaseURL is the base URL for Voyage AI API (can also be set via VOYAGE_BASE_URL or VECGREP_VOYAGE_BASE_URL env)
 	VoyageBaseURL string `mapstructure:"voyage_base_url" yaml:"voyage_base_url,omitempty"`
+	// TEIURL is the base URL of a text-embeddings-inference server (can also
+	// be set via VECGREP_EMBEDDING_TEI_URL env)
+	TEIURL string `mapstructure:"tei_url" yaml:"tei_url,omitempty"`
+	// TEIAPIKey is an optional bearer token for TEI behind a gateway (can also
+	// be set via VECGREP_EMBEDDING_TEI_API_KEY env)
+	TEIAPIKey string `mapstructure:"tei_api_key" yaml:"tei_api_key,omitempty"`
+	// TEITruncation controls how TEI handles inputs longer than the model's
+	// sequence limit: "right" (default), "left", or "none" to reject them.
+	TEITruncation string `mapstructure:"tei_truncation" yaml:"tei_truncation,omitempty"`
+	// ExecCommand is the command run by the "exec" provider, split on
+	// whitespace and run without a shell. It reads a JSON batch request on
+	// stdin and writes embeddings to stdout (see embed.ExecProvider).
+	ExecCommand string `mapstructure:"exec_command" yaml:"exec_command,omitempty"`
+	// ExecTimeout bounds one exec provider batch. Zero uses the default (2m).
+	ExecTimeout time.Duration `mapstructure:"exec_timeout" yaml:"exec_timeout,omitempty"`
+	// ONNXModelDir is the directory holding model.onnx and tokenizer.json
+	// for the "onnx" provider, which runs the model in-process.
+	ONNXModelDir string `mapstructure:"onnx_model_dir" yaml:"onnx_model_dir,omitempty"`
+	// ONNXLibraryPath is the ONNX Runtime shared library used by the "onnx"
+	// provider. Empty checks ONNXRUNTIME_LIB and common install locations.
+	ONNXLibraryPath string `mapstructure:"onnx_library_path" yaml:"onnx_library_path,omitempty"`
 	// MaxBatchSize is the maximum number of texts sent in a single embedding
 	// request to the provider (Ollama /api/embed). Default 64. Only used by
 	// providers that support native batch embedding.
 	MaxBatchSize int `mapstructure:"max_batch_size" yaml:"max_batch_size,omitempty"`
+	// BatchSize is the number of chunks the indexer groups into one
+	// embedding batch. Zero (the default) probes the provider once per
+	// provider/model for the fastest batch size and the longest input it
+	// accepts; a positive value fixes the batch size and skips the probe.
+	BatchSize int `mapstructure:"batch_size" yaml:"batch_size,omitempty"`
 	// KeepAlive controls how long the provider keeps the model loaded in
 	// memory after a request. Only used by Ollama. If empty, sensible defaults
 	// are applied: "5m" for single embeds, "30m" for batch indexing.
 	KeepAlive string `mapstructure:"keep_alive" yaml:"keep_alive,omitempty"`
+	// BudgetUSD is an optional hard spend limit per indexing run, estimated
+	// from metered tokens and the provider's published price. Indexing
+	// aborts when the estimate would exceed it. Zero disables the budget;
+	// providers without a known price are never limited.
+	BudgetUSD float64 `mapstructure:"budget_usd" yaml:"budget_usd,omitempty"`
 	// OllamaContext sets Ollama's num_ctx option when positive.
 	OllamaContext int `mapstructure:"ollama_context" yaml:"ollama_context,omitempty"`
+	// MaxInputTokens is the longest chunk, in tokens, the model embeds
+	// without truncating it. Zero uses the limit the provider reports (TEI)
+	// or, for Ollama, OllamaContext; with neither, inputs are not checked.
+	MaxInputTokens int `mapstructure:"max_input_tokens" yaml:"max_input_tokens,omitempty"`
+	// OverlongInput is what indexing does with a chunk over MaxInputTokens:
+	// "truncate" (default) embeds the part that fits and flags the chunk,
+	// "split" divides it into chunks that fit, and "error" fails the file.
+	OverlongInput string `mapstructure:"overlong_input" yaml:"overlong_input,omitempty"`
 	// OllamaOptions are passed through to Ollama's /api/embed options object.
 	OllamaOptions map[string]any `mapstructure:"ollama_options" yaml:"ollama_options,omitempty"`
 	// QueryTemplate and DocumentTemplate explicitly preprocess inputs for
@@ -200,12 +393,78 @@ type IndexingConfig struct {
 	IgnorePatterns []string `mapstructure:"ignore_patterns" yaml:"ignore_patterns,omitempty"`
 	// MaxFileSize is the maximum file size to index in bytes
 	MaxFileSize int64 `mapstructure:"max_file_size" yaml:"max_file_size,omitempty"`
+	// MaxChunksPerFile caps how many chunks a single file may contribute.
+	// Pathological files (generated JSON, concatenated bundles) are truncated
+	// to the cap and reported as an indexing warning.
+	MaxChunksPerFile int `mapstructure:"max_chunks_per_file" yaml:"max_chunks_per_file,omitempty"`
 	// SourceBufferBytes bounds queued source bytes before chunking.
 	SourceBufferBytes int64 `mapstructure:"source_buffer_bytes" yaml:"source_buffer_bytes,omitempty"`
+	// Workers is the number of files chunked and embedded concurrently.
+	// Zero uses the indexer default.
+	Workers int `mapstructure:"workers" yaml:"workers,omitempty"`
 	// SyncInterval syncs storage after this many indexed files.
 	SyncInterval int `mapstructure:"sync_interval" yaml:"sync_interval,omitempty"`
 	// SyncIntervalDuration syncs storage after this much elapsed time.
 	SyncIntervalDuration time.Duration `mapstructure:"sync_interval_duration" yaml:"sync_interval_duration,omitempty"`
+	// Languages skips whole languages without crafting glob patterns.
+	Languages LanguageFilterConfig `mapstructure:"languages" yaml:"languages,omitempty"`
+	// ExtraRoots are directories outside the project root (a shared library
+	// in another checkout, for example) indexed and searched as part of this
+	// project.
+	ExtraRoots []ExtraRootConfig `mapstructure:"extra_roots" yaml:"extra_roots,omitempty"`
+	// SecretsPolicy is what indexing does with chunks that look like they
+	// hold credentials: "redact" (default) masks the values before they are
+	// stored or embedded, "skip" leaves the chunk out, "flag" stores it
+	// unchanged but marked, and "off" disables the scan.
+	SecretsPolicy string `mapstructure:"secrets_policy" yaml:"secrets_policy,omitempty"`
+	// Tags label files by path or content so searches can filter with
+	// --tag; see TagRuleConfig.
+	Tags []TagRuleConfig `mapstructure:"tags" yaml:"tags,omitempty"`
+}
+
+// TagRuleConfig gives Tags to every file whose relative path matches the Path
+// regular expression and whose content matches Content. Either pattern may be
+// omitted, but not both.
+type TagRuleConfig struct {
+	Tags    []string `mapstructure:"tags" yaml:"tags"`
+	Path    string   `mapstructure:"path" yaml:"path,omitempty"`
+	Content string   `mapstructure:"content" yaml:"content,omitempty"`
+}
+
+// ExtraRootConfig declares one additional project root. Relative paths are
+// resolved against the project root; Name labels search results from the
+// root and defaults to the directory's base name.
+type ExtraRootConfig struct {
+	Path string `mapstructure:"path" yaml:"path"`
+	Name string `mapstructure:"name" yaml:"name,omitempty"`
+}
+
+// UnmarshalYAML also accepts a bare path, so `extra_roots: [../shared]`
+// works without naming the root.
+func (r *ExtraRootConfig) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		r.Path = strings.TrimSpace(node.Value)
+		return nil
+	}
+	type plain ExtraRootConfig
+	return node.Decode((*plain)(r))
+}
+
+// LanguageFilterConfig selects which detected languages are indexed. Names
+// match the language column of 'vecgrep status' (go, json, yaml, unknown, ...).
+type LanguageFilterConfig struct {
+	// Enabled, when non-empty, indexes only the listed languages.
+	Enabled []string `mapstructure:"enabled" yaml:"enabled,omitempty"`
+	// Disabled skips the listed languages and wins over Enabled.
+	Disabled []string `mapstructure:"disabled" yaml:"disabled,omitempty"`
+}
+
+// SecurityConfig holds at-rest protection settings.
+type SecurityConfig struct {
+	// Encrypt keeps the index files encrypted with AES-256-GCM while no
+	// vecgrep process has them open. The key comes from
+	// VECGREP_ENCRYPTION_KEY or the OS keychain.
+	Encrypt bool `mapstructure:"encrypt" yaml:"encrypt,omitempty"`
 }
 
 // ServerConfig holds MCP server settings.
@@ -219,6 +478,19 @@ type ServerConfig struct {
 	//   server:
 	//     mcp_reload_interval: "10s"
 	MCPReloadInterval string `mapstructure:"mcp_reload_interval" yaml:"mcp_reload_interval,omitempty"`
+	// MaxResponseBytes caps the text returned by search-type MCP tools.
+	// Lowest-scored results are omitted first and the response says so.
+	// Zero uses the default (256 KiB).
+	MaxResponseBytes int `mapstructure:"max_response_bytes" yaml:"max_response_bytes,omitempty"`
+	// Editor is the editor the web server's open endpoint links search
+	// results to: vscode (the default), vscode-insiders, cursor, zed, idea,
+	// goland, sublime, or a URI template with {path}, {line}, and {column}
+	// whose scheme is an editor's (txmt, mvim, pycharm, ...).
+	Editor string `mapstructure:"editor" yaml:"editor,omitempty"`
+	// AuthToken is the bearer token `vecgrep serve --mcp-http` requires on
+	// every request. When it is unset and the server binds beyond loopback,
+	// a token is generated on the first run and kept in the data directory.
+	AuthToken string `mapstructure:"auth_token" yaml:"auth_token,omitempty"`
 }
 
 // CodemapConfig holds settings for the codemap graph integration. When
@@ -269,6 +541,14 @@ type DaemonConfig struct {
 	EmbedMaxInFlight int `mapstructure:"embed_max_in_flight" yaml:"embed_max_in_flight,omitempty"`
 	// Debounce is the watcher debounce duration in milliseconds (default 500).
 	Debounce int `mapstructure:"debounce" yaml:"debounce,omitempty"`
+	// Poll makes the watcher rescan the tree for changes instead of relying
+	// on file system events, for network file systems and containers where
+	// events are lost. The watcher also polls on its own when it detects a
+	// network file system or cannot set up events.
+	Poll bool `mapstructure:"poll" yaml:"poll,omitempty"`
+	// PollInterval is the time between rescans of a polling watcher in
+	// milliseconds (default 2000).
+	PollInterval int `mapstructure:"poll_interval" yaml:"poll_interval,omitempty"`
 	// SweepInterval is the interval between automatic fcheap vault
 	// cleanup sweeps. When non-zero, the daemon starts a ticker that
 	// runs fcheap vacuum every SweepInterval to remove orphaned stash
@@ -292,6 +572,7 @@ const (
 	DefaultIndexSourceBufferBytes    = 8 * 1024 * 1024
 	DefaultIndexSyncInterval         = 50
 	DefaultIndexSyncIntervalDuration = 30 * time.Second
+	DefaultIndexMaxChunksPerFile     = 500
 )
 
 const (
@@ -299,6 +580,7 @@ const (
 	DefaultDaemonEmbedWorkers     = 4
 	DefaultDaemonEmbedMaxInFlight = 8
 	DefaultDaemonDebounceMs       = 500
+	DefaultDaemonPollIntervalMs   = 2000
 	DefaultDaemonSweepInterval    = "24h"
 	DefaultDaemonLogOffloadInt    = "1h"
 	DefaultDaemonLogOffloadTTL    = "30d"
@@ -333,6 +615,7 @@ func DefaultConfig() *Config {
 				"yarn.lock",
 			},
 			MaxFileSize:          1024 * 1024, // 1MB
+			MaxChunksPerFile:     DefaultIndexMaxChunksPerFile,
 			SourceBufferBytes:    DefaultIndexSourceBufferBytes,
 			SyncInterval:         DefaultIndexSyncInterval,
 			SyncIntervalDuration: DefaultIndexSyncIntervalDuration,
@@ -361,6 +644,7 @@ func DefaultConfig() *Config {
 			EmbedWorkers:     DefaultDaemonEmbedWorkers,
 			EmbedMaxInFlight: DefaultDaemonEmbedMaxInFlight,
 			Debounce:         DefaultDaemonDebounceMs,
+			PollInterval:     DefaultDaemonPollIntervalMs,
 			SweepInterval:    DefaultDaemonSweepInterval,
 			// LogOffload defaults to off; interval/TTL apply only once enabled.
 			LogOffloadInterval: DefaultDaemonLogOffloadInt,
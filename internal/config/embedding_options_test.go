@@ -97,3 +97,33 @@ func TestEmbeddingAndIndexingEnvironmentOverrides(t *testing.T) {
 		t.Fatalf("indexing environment config = %+v", cfg.Indexing)
 	}
 }
+
+func TestChunkersConfigYAMLAndMerge(t *testing.T) {
+	var cfg Config
+	err := yaml.Unmarshal([]byte(`chunkers:
+  proto: "cmd: protoc-chunker --json"
+  thrift: thrift-chunker
+  cue:
+    cmd: cue-chunker
+    timeout: 5s
+`), &cfg)
+	if err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	if got := cfg.Chunkers["proto"].Cmd; got != "protoc-chunker --json" {
+		t.Errorf("proto cmd = %q", got)
+	}
+	if got := cfg.Chunkers["thrift"].Cmd; got != "thrift-chunker" {
+		t.Errorf("thrift cmd = %q", got)
+	}
+	if got := cfg.Chunkers["cue"]; got.Cmd != "cue-chunker" || got.Timeout != 5*time.Second {
+		t.Errorf("cue = %+v", got)
+	}
+
+	dst := DefaultConfig()
+	dst.Chunkers = map[string]ExternalChunkerConfig{"proto": {Cmd: "global-proto"}, "sol": {Cmd: "sol-chunker"}}
+	NewConfigResolution().mergeConfig(dst, &cfg)
+	if dst.Chunkers["proto"].Cmd != "protoc-chunker --json" || dst.Chunkers["sol"].Cmd != "sol-chunker" || len(dst.Chunkers) != 4 {
+		t.Errorf("merged chunkers = %+v", dst.Chunkers)
+	}
+}
@@ -374,6 +374,56 @@ func PruneGlobalProjects(dryRun, purgeData bool) ([]PrunedProject, error) {
 	return pruned, SaveGlobalConfig(globalCfg)
 }
 
+// OrphanedDataDir is a directory under ~/.vecgrep/projects that no registry
+// entry uses, found by OrphanedProjectDataDirs.
+type OrphanedDataDir struct {
+	Path  string
+	Bytes int64
+}
+
+// OrphanedProjectDataDirs returns the directories directly under
+// ~/.vecgrep/projects that hold no registered project's data, typically left
+// behind when a registry entry was removed by hand. A registered project owns
+// the top-level directory containing its data_dir (branch data lives beneath
+// it) or, without a managed data_dir, the directory named after it. Nothing
+// is deleted.
+func OrphanedProjectDataDirs() ([]OrphanedDataDir, error) {
+	projectsDir, err := GetGlobalProjectsDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(projectsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	globalCfg, err := LoadGlobalConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[string]bool, len(globalCfg.Projects))
+	for name, entry := range globalCfg.Projects {
+		owned[name] = true
+		if dataDir, ok := managedDataDir(entry.DataDir); ok {
+			rel, _ := filepath.Rel(projectsDir, dataDir)
+			owned[strings.Split(rel, string(filepath.Separator))[0]] = true
+		}
+	}
+
+	var orphaned []OrphanedDataDir
+	for _, entry := range entries {
+		if !entry.IsDir() || owned[entry.Name()] {
+			continue
+		}
+		dir := filepath.Join(projectsDir, entry.Name())
+		orphaned = append(orphaned, OrphanedDataDir{Path: dir, Bytes: dirSize(dir)})
+	}
+	return orphaned, nil
+}
+
 // managedDataDir reports whether dir resolves to a location strictly inside
 // ~/.vecgrep/projects and returns the expanded path if so.
 func managedDataDir(dir string) (string, bool) {
@@ -144,3 +144,32 @@ func TestPruneRefusesUnmanagedDataDir(t *testing.T) {
 		t.Fatalf("prune deleted data outside ~/.vecgrep/projects: %v", err)
 	}
 }
+
+func TestOrphanedProjectDataDirs(t *testing.T) {
+	setupPruneFixture(t)
+
+	projectsDir, err := GetGlobalProjectsDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orphan := filepath.Join(projectsDir, "removed-long-ago")
+	if err := os.MkdirAll(filepath.Join(orphan, "branches"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(orphan, "vectors.veclite"), make([]byte, 512), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	orphaned, err := OrphanedProjectDataDirs()
+	if err != nil {
+		t.Fatalf("orphaned data dirs: %v", err)
+	}
+	if len(orphaned) != 1 || orphaned[0].Path != orphan || orphaned[0].Bytes != 512 {
+		t.Fatalf("orphaned = %+v, want only %s with 512 bytes", orphaned, orphan)
+	}
+	// The stale registry entry still owns its data dir; that is for
+	// PruneGlobalProjects to report, not this check.
+	if _, err := os.Stat(orphan); err != nil {
+		t.Fatalf("orphan detection modified the data dir: %v", err)
+	}
+}
@@ -3,8 +3,10 @@ package config
 import (
 	"bytes"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
@@ -13,6 +15,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// pgvectorTablePattern accepts the plain identifiers the pgvector backend
+// splices into SQL.
+var pgvectorTablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,47}$`)
+
 // ParseConfigValue validates and converts a supported config key/value pair.
 func ParseConfigValue(key, value string) (any, error) {
 	key = publicConfigKey(key)
@@ -24,36 +30,69 @@ func ParseConfigValue(key, value string) (any, error) {
 		"embedding.query_template", "embedding.document_template",
 		"embedding.openai_api_key", "embedding.openai_base_url",
 		"embedding.cohere_api_key", "embedding.cohere_base_url",
-		"embedding.voyage_api_key", "embedding.voyage_base_url":
+		"embedding.voyage_api_key", "embedding.voyage_base_url",
+		"embedding.tei_url", "embedding.tei_api_key",
+		"embedding.exec_command",
+		"embedding.onnx_model_dir", "embedding.onnx_library_path",
+		"server.editor", "server.auth_token":
 		return value, nil
 	case "embedding.provider":
 		switch value {
-		case "ollama", "openai", "cohere", "voyage":
+		case "ollama", "openai", "cohere", "voyage", "tei", "exec", "onnx":
+			return value, nil
+		default:
+			return nil, fmt.Errorf("invalid embedding.provider value %q: expected ollama, openai, cohere, voyage, tei, exec, or onnx", value)
+		}
+	case "embedding.tei_truncation":
+		switch value {
+		case "right", "left", "none":
 			return value, nil
 		default:
-			return nil, fmt.Errorf("invalid embedding.provider value %q: expected ollama, openai, cohere, or voyage", value)
+			return nil, fmt.Errorf("invalid embedding.tei_truncation value %q: expected right, left, or none", value)
 		}
 	case "embedding.dimensions":
 		return parsePositiveInt(key, value)
-	case "embedding.ollama_context":
+	case "embedding.ollama_context", "embedding.max_input_tokens":
 		return parseNonNegativeInt(key, value)
+	case "embedding.overlong_input":
+		switch value {
+		case "truncate", "split", "error":
+			return value, nil
+		default:
+			return nil, fmt.Errorf("invalid embedding.overlong_input value %q: expected truncate, split, or error", value)
+		}
+	case "indexing.secrets_policy":
+		switch value {
+		case "redact", "skip", "flag", "off":
+			return value, nil
+		default:
+			return nil, fmt.Errorf("invalid indexing.secrets_policy value %q: expected redact, skip, flag, or off", value)
+		}
 	case "embedding.ollama_options":
 		options := make(map[string]any)
 		if err := yaml.Unmarshal([]byte(value), &options); err != nil {
 			return nil, fmt.Errorf("invalid embedding.ollama_options value %q: %w", value, err)
 		}
 		return options, nil
-	case "indexing.chunk_size", "indexing.chunk_overlap", "indexing.sync_interval":
+	case "indexing.chunk_size", "indexing.chunk_overlap", "indexing.sync_interval", "indexing.workers":
 		return parseNonNegativeInt(key, value)
+	case "indexing.max_chunks_per_file", "server.max_response_bytes":
+		return parsePositiveInt(key, value)
 	case "indexing.max_file_size", "indexing.source_buffer_bytes":
 		return parsePositiveInt64(key, value)
+	case "embedding.exec_timeout":
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid embedding.exec_timeout value %q", value)
+		}
+		return duration, nil
 	case "indexing.sync_interval_duration":
 		duration, err := time.ParseDuration(value)
 		if err != nil || duration < 0 {
 			return nil, fmt.Errorf("invalid indexing.sync_interval_duration value %q", value)
 		}
 		return duration, nil
-	case "indexing.ignore_patterns":
+	case "indexing.ignore_patterns", "indexing.languages.enabled", "indexing.languages.disabled":
 		return parseStringList(value)
 	case "search.default_mode":
 		switch value {
@@ -64,14 +103,100 @@ func ParseConfigValue(key, value string) (any, error) {
 		}
 	case "search.vector_weight", "search.text_weight":
 		return parseUnitFloat32(key, value)
+	case "search.fusion":
+		switch value {
+		case "weighted", "rrf":
+			return value, nil
+		default:
+			return nil, fmt.Errorf("invalid search.fusion value %q: expected weighted or rrf", value)
+		}
+	case "search.reranker":
+		switch value {
+		case "", "http", "ollama", "openai", "none":
+			return value, nil
+		default:
+			return nil, fmt.Errorf("invalid search.reranker value %q: expected http, ollama, openai, or none", value)
+		}
+	case "search.reranker_model", "search.reranker_api_key", "search.expander_model":
+		return value, nil
+	case "search.rerank_top_n":
+		return parsePositiveInt(key, value)
+	case "search.reranker_url", "search.translator_url", "search.expander_url":
+		if value == "" {
+			return value, nil
+		}
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid %s value %q: expected an http(s) URL", key, value)
+		}
+		return value, nil
+	case "search.reranker_timeout", "search.translator_timeout", "search.timeout":
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid %s value %q", key, value)
+		}
+		return duration, nil
+	case "hooks.post_search":
+		return parseStringList(value)
+	case "hooks.timeout":
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return nil, fmt.Errorf("invalid hooks.timeout value %q", value)
+		}
+		return duration, nil
 	case "server.mcp_enabled":
 		parsed, err := strconv.ParseBool(value)
 		if err != nil {
 			return nil, fmt.Errorf("invalid server.mcp_enabled value %q: %w", value, err)
 		}
 		return parsed, nil
-	case "vector.veclite.m", "vector.veclite.ef_construction", "vector.veclite.ef_search":
+	case "vector.veclite.m", "vector.veclite.ef_construction", "vector.veclite.ef_search", "vector.veclite.hot_max_chunks":
 		return parsePositiveInt(key, value)
+	case "vector.veclite.hot":
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector.veclite.hot value %q: %w", value, err)
+		}
+		return parsed, nil
+	case "vector.veclite.hot_window":
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid vector.veclite.hot_window value %q: expected a positive duration", value)
+		}
+		return duration, nil
+	case "vector.backend":
+		switch value {
+		case "veclite", "qdrant", "pgvector":
+			return value, nil
+		default:
+			return nil, fmt.Errorf("invalid vector.backend value %q: want veclite, qdrant, or pgvector", value)
+		}
+	case "vector.qdrant.url":
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid %s value %q: expected an http(s) URL", key, value)
+		}
+		return value, nil
+	case "vector.qdrant.api_key", "vector.qdrant.collection":
+		return value, nil
+	case "vector.pgvector.url":
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "postgres" && parsed.Scheme != "postgresql") {
+			return nil, fmt.Errorf("invalid %s value %q: expected a postgres:// URL", key, value)
+		}
+		return value, nil
+	case "vector.pgvector.table":
+		if !pgvectorTablePattern.MatchString(value) {
+			return nil, fmt.Errorf("invalid %s value %q: expected a table name of letters, digits, and underscores", key, value)
+		}
+		return value, nil
+	case "vector.veclite.quantization":
+		switch value {
+		case "none", "int8", "binary":
+			return value, nil
+		default:
+			return nil, fmt.Errorf("invalid vector.veclite.quantization value %q: want none, int8, or binary", value)
+		}
 	case "codemap.enabled":
 		parsed, err := strconv.ParseBool(value)
 		if err != nil {
@@ -93,13 +218,13 @@ func ParseConfigValue(key, value string) (any, error) {
 			return nil, fmt.Errorf("invalid codemap.structural_weight value %q: %w", value, err)
 		}
 		return float32(w), nil
-	case "daemon.autostart":
+	case "daemon.autostart", "daemon.poll", "security.encrypt":
 		parsed, err := strconv.ParseBool(value)
 		if err != nil {
-			return nil, fmt.Errorf("invalid daemon.autostart value %q: %w", value, err)
+			return nil, fmt.Errorf("invalid %s value %q: %w", key, value, err)
 		}
 		return parsed, nil
-	case "daemon.idle_timeout", "daemon.embed_workers", "daemon.embed_max_in_flight", "daemon.debounce":
+	case "daemon.idle_timeout", "daemon.embed_workers", "daemon.embed_max_in_flight", "daemon.debounce", "daemon.poll_interval":
 		return parseNonNegativeInt(key, value)
 	case "daemon.embed_rps":
 		r, err := strconv.ParseFloat(value, 64)
@@ -127,16 +252,31 @@ func ParseConfigValue(key, value string) (any, error) {
 			return nil, fmt.Errorf("invalid embedding.throttle.rate_limit value %q: must be zero or greater", value)
 		}
 		return r, nil
-	case "embedding.max_batch_size":
+	case "embedding.max_batch_size", "embedding.batch_size":
 		return parseNonNegativeInt(key, value)
 	case "embedding.keep_alive":
 		return value, nil
+	case "embedding.budget_usd":
+		budget, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid embedding.budget_usd value %q: %w", value, err)
+		}
+		if budget < 0 {
+			return nil, fmt.Errorf("invalid embedding.budget_usd value %q: must be zero or greater", value)
+		}
+		return budget, nil
 	case "cache.fcheap_stash":
 		parsed, err := strconv.ParseBool(value)
 		if err != nil {
 			return nil, fmt.Errorf("invalid cache.fcheap_stash value %q: %w", value, err)
 		}
 		return parsed, nil
+	case "search.infer_filters":
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search.infer_filters value %q: %w", value, err)
+		}
+		return parsed, nil
 	case "cache.fcheap_ttl", "cache.path":
 		return value, nil
 	case "daemon.sweep_interval":
@@ -177,10 +317,28 @@ func ApplyConfigValue(cfg *Config, key, value string) error {
 		cfg.Embedding.VoyageAPIKey = parsed.(string)
 	case "embedding.voyage_base_url":
 		cfg.Embedding.VoyageBaseURL = parsed.(string)
+	case "embedding.tei_url":
+		cfg.Embedding.TEIURL = parsed.(string)
+	case "embedding.tei_api_key":
+		cfg.Embedding.TEIAPIKey = parsed.(string)
+	case "embedding.tei_truncation":
+		cfg.Embedding.TEITruncation = parsed.(string)
+	case "embedding.exec_command":
+		cfg.Embedding.ExecCommand = parsed.(string)
+	case "embedding.exec_timeout":
+		cfg.Embedding.ExecTimeout = parsed.(time.Duration)
+	case "embedding.onnx_model_dir":
+		cfg.Embedding.ONNXModelDir = parsed.(string)
+	case "embedding.onnx_library_path":
+		cfg.Embedding.ONNXLibraryPath = parsed.(string)
 	case "embedding.dimensions":
 		cfg.Embedding.Dimensions = parsed.(int)
 	case "embedding.ollama_context":
 		cfg.Embedding.OllamaContext = parsed.(int)
+	case "embedding.max_input_tokens":
+		cfg.Embedding.MaxInputTokens = parsed.(int)
+	case "embedding.overlong_input":
+		cfg.Embedding.OverlongInput = parsed.(string)
 	case "embedding.ollama_options":
 		cfg.Embedding.OllamaOptions = parsed.(map[string]any)
 	case "embedding.query_template":
@@ -200,28 +358,96 @@ func ApplyConfigValue(cfg *Config, key, value string) error {
 		cfg.Indexing.ChunkOverlap = parsed.(int)
 	case "indexing.max_file_size":
 		cfg.Indexing.MaxFileSize = parsed.(int64)
+	case "indexing.max_chunks_per_file":
+		cfg.Indexing.MaxChunksPerFile = parsed.(int)
 	case "indexing.source_buffer_bytes":
 		cfg.Indexing.SourceBufferBytes = parsed.(int64)
 	case "indexing.sync_interval":
 		cfg.Indexing.SyncInterval = parsed.(int)
+	case "indexing.workers":
+		cfg.Indexing.Workers = parsed.(int)
 	case "indexing.sync_interval_duration":
 		cfg.Indexing.SyncIntervalDuration = parsed.(time.Duration)
+	case "indexing.secrets_policy":
+		cfg.Indexing.SecretsPolicy = parsed.(string)
 	case "indexing.ignore_patterns":
 		cfg.Indexing.IgnorePatterns = parsed.([]string)
+	case "indexing.languages.enabled":
+		cfg.Indexing.Languages.Enabled = parsed.([]string)
+	case "indexing.languages.disabled":
+		cfg.Indexing.Languages.Disabled = parsed.([]string)
 	case "search.default_mode":
 		cfg.Search.DefaultMode = parsed.(string)
 	case "search.vector_weight":
 		cfg.Search.VectorWeight = parsed.(float32)
 	case "search.text_weight":
 		cfg.Search.TextWeight = parsed.(float32)
+	case "search.fusion":
+		cfg.Search.Fusion = parsed.(string)
+	case "search.reranker_url":
+		cfg.Search.RerankerURL = parsed.(string)
+	case "search.reranker_timeout":
+		cfg.Search.RerankerTimeout = parsed.(time.Duration)
+	case "search.reranker":
+		cfg.Search.Reranker = parsed.(string)
+	case "search.reranker_model":
+		cfg.Search.RerankerModel = parsed.(string)
+	case "search.reranker_api_key":
+		cfg.Search.RerankerAPIKey = parsed.(string)
+	case "search.rerank_top_n":
+		cfg.Search.RerankTopN = parsed.(int)
+	case "search.translator_url":
+		cfg.Search.TranslatorURL = parsed.(string)
+	case "search.translator_timeout":
+		cfg.Search.TranslatorTimeout = parsed.(time.Duration)
+	case "search.expander_model":
+		cfg.Search.ExpanderModel = parsed.(string)
+	case "search.expander_url":
+		cfg.Search.ExpanderURL = parsed.(string)
+	case "search.infer_filters":
+		b := parsed.(bool)
+		cfg.Search.InferFilters = &b
+	case "search.timeout":
+		cfg.Search.Timeout = parsed.(time.Duration)
+	case "hooks.post_search":
+		cfg.Hooks.PostSearch = parsed.([]string)
+	case "hooks.timeout":
+		cfg.Hooks.Timeout = parsed.(time.Duration)
 	case "server.mcp_enabled":
 		cfg.Server.MCPEnabled = parsed.(bool)
+	case "server.editor":
+		cfg.Server.Editor = parsed.(string)
+	case "server.auth_token":
+		cfg.Server.AuthToken = parsed.(string)
+	case "server.max_response_bytes":
+		cfg.Server.MaxResponseBytes = parsed.(int)
 	case "vector.veclite.m":
 		cfg.Vector.VecLite.M = parsed.(int)
 	case "vector.veclite.ef_construction":
 		cfg.Vector.VecLite.EfConstruction = parsed.(int)
 	case "vector.veclite.ef_search":
 		cfg.Vector.VecLite.EfSearch = parsed.(int)
+	case "vector.veclite.quantization":
+		cfg.Vector.VecLite.Quantization = parsed.(string)
+	case "vector.veclite.hot":
+		b := parsed.(bool)
+		cfg.Vector.VecLite.Hot = &b
+	case "vector.veclite.hot_window":
+		cfg.Vector.VecLite.HotWindow = parsed.(time.Duration)
+	case "vector.veclite.hot_max_chunks":
+		cfg.Vector.VecLite.HotMaxChunks = parsed.(int)
+	case "vector.backend":
+		cfg.Vector.Backend = parsed.(string)
+	case "vector.qdrant.url":
+		cfg.Vector.Qdrant.URL = parsed.(string)
+	case "vector.qdrant.api_key":
+		cfg.Vector.Qdrant.APIKey = parsed.(string)
+	case "vector.qdrant.collection":
+		cfg.Vector.Qdrant.Collection = parsed.(string)
+	case "vector.pgvector.url":
+		cfg.Vector.Pgvector.URL = parsed.(string)
+	case "vector.pgvector.table":
+		cfg.Vector.Pgvector.Table = parsed.(string)
 	case "codemap.enabled":
 		cfg.Codemap.Enabled = parsed.(bool)
 	case "codemap.bin":
@@ -234,6 +460,8 @@ func ApplyConfigValue(cfg *Config, key, value string) error {
 		cfg.Codemap.StructuralChunks = parsed.(string)
 	case "daemon.autostart":
 		cfg.Daemon.Autostart = parsed.(bool)
+	case "security.encrypt":
+		cfg.Security.Encrypt = parsed.(bool)
 	case "daemon.idle_timeout":
 		cfg.Daemon.IdleTimeout = parsed.(int)
 	case "daemon.embed_workers":
@@ -244,12 +472,20 @@ func ApplyConfigValue(cfg *Config, key, value string) error {
 		cfg.Daemon.EmbedMaxInFlight = parsed.(int)
 	case "daemon.debounce":
 		cfg.Daemon.Debounce = parsed.(int)
+	case "daemon.poll":
+		cfg.Daemon.Poll = parsed.(bool)
+	case "daemon.poll_interval":
+		cfg.Daemon.PollInterval = parsed.(int)
 	case "daemon.sweep_interval":
 		cfg.Daemon.SweepInterval = parsed.(string)
 	case "embedding.max_batch_size":
 		cfg.Embedding.MaxBatchSize = parsed.(int)
+	case "embedding.batch_size":
+		cfg.Embedding.BatchSize = parsed.(int)
 	case "embedding.keep_alive":
 		cfg.Embedding.KeepAlive = parsed.(string)
+	case "embedding.budget_usd":
+		cfg.Embedding.BudgetUSD = parsed.(float64)
 	case "cache.fcheap_stash":
 		b := parsed.(bool)
 		cfg.Cache.FcheapStash = &b
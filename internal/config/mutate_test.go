@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -74,15 +75,34 @@ func TestSetConfigValueInFileTypedValuesResolve(t *testing.T) {
 		"embedding.voyage_base_url":      "https://example.test/voyage",
 		"embedding.cohere_api_key":       "cohere-key",
 		"embedding.cohere_base_url":      "https://example.test/cohere",
+		"embedding.onnx_model_dir":       "/models/minilm",
+		"embedding.tei_url":              "http://gpu-box:8080",
+		"embedding.tei_truncation":       "left",
 		"indexing.ignore_patterns":       ".git/**, dist/**",
 		"indexing.max_file_size":         "2048",
+		"indexing.languages.disabled":    "json, yaml",
 		"search.default_mode":            "keyword",
 		"search.vector_weight":           "0",
 		"search.text_weight":             "1",
+		"search.reranker_url":            "http://localhost:9100/rerank",
+		"search.reranker_timeout":        "2s",
+		"search.reranker":                "openai",
+		"search.reranker_model":          "bge-reranker-v2-m3",
+		"search.rerank_top_n":            "30",
+		"search.timeout":                 "500ms",
+		"search.translator_url":          "http://localhost:5000/translate",
+		"search.translator_timeout":      "3s",
+		"search.expander_model":          "llama3.2",
+		"search.expander_url":            "http://gpu-box:11434",
 		"server.mcp_enabled":             "false",
 		"vector.veclite.m":               "32",
 		"vector.veclite.ef_construction": "320",
 		"vector.veclite.ef_search":       "64",
+		"vector.backend":                 "qdrant",
+		"vector.qdrant.url":              "http://qdrant:6333",
+		"vector.qdrant.collection":       "monorepo",
+		"vector.pgvector.url":            "postgres://vecgrep@db:5432/team",
+		"vector.pgvector.table":          "monorepo_vectors",
 	}
 
 	for key, value := range settings {
@@ -118,9 +138,18 @@ func TestSetConfigValueInFileTypedValuesResolve(t *testing.T) {
 	if cfg.Embedding.CohereBaseURL != "https://example.test/cohere" {
 		t.Fatalf("embedding.cohere_base_url = %q, want https://example.test/cohere", cfg.Embedding.CohereBaseURL)
 	}
+	if cfg.Embedding.TEIURL != "http://gpu-box:8080" || cfg.Embedding.TEITruncation != "left" {
+		t.Fatalf("tei settings = %q/%q, want http://gpu-box:8080/left", cfg.Embedding.TEIURL, cfg.Embedding.TEITruncation)
+	}
+	if cfg.Embedding.ONNXModelDir != "/models/minilm" {
+		t.Fatalf("embedding.onnx_model_dir = %q, want /models/minilm", cfg.Embedding.ONNXModelDir)
+	}
 	if got := cfg.Indexing.IgnorePatterns; len(got) != 2 || got[0] != ".git/**" || got[1] != "dist/**" {
 		t.Fatalf("ignore_patterns = %v, want [.git/** dist/**]", got)
 	}
+	if got := cfg.Indexing.Languages.Disabled; len(got) != 2 || got[0] != "json" || got[1] != "yaml" {
+		t.Fatalf("languages.disabled = %v, want [json yaml]", got)
+	}
 	if cfg.Indexing.MaxFileSize != 2048 {
 		t.Fatalf("max_file_size = %d, want 2048", cfg.Indexing.MaxFileSize)
 	}
@@ -133,6 +162,21 @@ func TestSetConfigValueInFileTypedValuesResolve(t *testing.T) {
 	if cfg.Search.TextWeight != 1 {
 		t.Fatalf("text_weight = %f, want 1", cfg.Search.TextWeight)
 	}
+	if cfg.Search.RerankerURL != "http://localhost:9100/rerank" || cfg.Search.RerankerTimeout != 2*time.Second {
+		t.Fatalf("reranker = %q, %s", cfg.Search.RerankerURL, cfg.Search.RerankerTimeout)
+	}
+	if cfg.Search.Reranker != "openai" || cfg.Search.RerankerModel != "bge-reranker-v2-m3" || cfg.Search.RerankTopN != 30 {
+		t.Fatalf("model reranker = %q, %q, %d", cfg.Search.Reranker, cfg.Search.RerankerModel, cfg.Search.RerankTopN)
+	}
+	if cfg.Search.Timeout != 500*time.Millisecond {
+		t.Fatalf("search timeout = %s", cfg.Search.Timeout)
+	}
+	if cfg.Search.TranslatorURL != "http://localhost:5000/translate" || cfg.Search.TranslatorTimeout != 3*time.Second {
+		t.Fatalf("translator = %q, %s", cfg.Search.TranslatorURL, cfg.Search.TranslatorTimeout)
+	}
+	if cfg.Search.ExpanderModel != "llama3.2" || cfg.Search.ExpanderURL != "http://gpu-box:11434" {
+		t.Fatalf("expander = %q, %q", cfg.Search.ExpanderModel, cfg.Search.ExpanderURL)
+	}
 	if cfg.Server.MCPEnabled {
 		t.Fatal("mcp_enabled = true, want false")
 	}
@@ -145,6 +189,12 @@ func TestSetConfigValueInFileTypedValuesResolve(t *testing.T) {
 	if cfg.Vector.VecLite.EfSearch != 64 {
 		t.Fatalf("veclite.ef_search = %d, want 64", cfg.Vector.VecLite.EfSearch)
 	}
+	if cfg.Vector.Backend != "qdrant" || cfg.Vector.Qdrant.URL != "http://qdrant:6333" || cfg.Vector.Qdrant.Collection != "monorepo" {
+		t.Fatalf("qdrant settings = %q/%q/%q, want qdrant/http://qdrant:6333/monorepo", cfg.Vector.Backend, cfg.Vector.Qdrant.URL, cfg.Vector.Qdrant.Collection)
+	}
+	if cfg.Vector.Pgvector.URL != "postgres://vecgrep@db:5432/team" || cfg.Vector.Pgvector.Table != "monorepo_vectors" {
+		t.Fatalf("pgvector settings = %q/%q", cfg.Vector.Pgvector.URL, cfg.Vector.Pgvector.Table)
+	}
 }
 
 func TestSetGlobalConfigValueInFilePreservesProjects(t *testing.T) {
@@ -300,6 +350,23 @@ func TestParseConfigValueRejectsUnknownProvider(t *testing.T) {
 	}
 }
 
+func TestParseConfigValueRejectsNonHTTPRerankerURL(t *testing.T) {
+	for _, value := range []string{"localhost:9100", "file:///tmp/rerank", "http://"} {
+		if _, err := ParseConfigValue("search.reranker_url", value); err == nil {
+			t.Fatalf("ParseConfigValue succeeded for reranker_url %q", value)
+		}
+	}
+}
+
+func TestParseConfigValueRejectsInvalidPgvectorSettings(t *testing.T) {
+	if _, err := ParseConfigValue("vector.pgvector.url", "http://db:5432/team"); err == nil {
+		t.Fatal("ParseConfigValue accepted a non-postgres pgvector URL")
+	}
+	if _, err := ParseConfigValue("vector.pgvector.table", "vectors; DROP TABLE x"); err == nil {
+		t.Fatal("ParseConfigValue accepted a pgvector table name that is not an identifier")
+	}
+}
+
 func TestSetConfigValuesInFileAppliesBatchAndPreservesOtherValues(t *testing.T) {
 	path := filepath.Join(t.TempDir(), "vecgrep.yaml")
 	initial := []byte("embedding:\n  model: old\n  ollama_options:\n    stale: true\ncustom:\n  keep: true\n")
@@ -386,3 +453,33 @@ func isolateConfigTestEnv(t *testing.T) string {
 func containsString(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestHooksResolveOnlyFromGlobalConfig(t *testing.T) {
+	isolateConfigTestEnv(t)
+	projectRoot := t.TempDir()
+	if err := SetConfigValueInFile(filepath.Join(projectRoot, "vecgrep.yaml"), "hooks.post_search", "./scripts/exfiltrate.sh"); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := LoadResolved(projectRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hooks := resolved.Config.Hooks.PostSearch; len(hooks) != 0 {
+		t.Fatalf("project hooks.post_search = %q, want it ignored", hooks)
+	}
+
+	globalPath, err := GetGlobalConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetConfigValueInFile(globalPath, "defaults.hooks.post_search", "./log-query.sh"); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err = LoadResolved(projectRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hooks := resolved.Config.Hooks.PostSearch; len(hooks) != 1 || hooks[0] != "./log-query.sh" {
+		t.Fatalf("global hooks.post_search = %q, want [./log-query.sh]", hooks)
+	}
+}
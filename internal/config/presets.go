@@ -23,6 +23,16 @@ var embeddingPresets = []EmbeddingPreset{
 			OllamaContext: 2048,
 		},
 	},
+	{
+		Name:        "multilingual",
+		Description: "Multilingual local embeddings for queries and comments outside English",
+		Embedding: EmbeddingConfig{
+			Provider:      "ollama",
+			Model:         "bge-m3",
+			Dimensions:    1024,
+			OllamaContext: 8192,
+		},
+	},
 	{
 		Name:        "quality-code",
 		Description: "Higher-quality local embeddings tuned for code retrieval",
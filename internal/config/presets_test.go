@@ -12,10 +12,10 @@ import (
 
 func TestEmbeddingPresetsExactValuesAndStableListing(t *testing.T) {
 	got := ListEmbeddingPresets()
-	if len(got) != 2 {
-		t.Fatalf("preset count = %d, want 2", len(got))
+	if len(got) != 3 {
+		t.Fatalf("preset count = %d, want 3", len(got))
 	}
-	if names := []string{got[0].Name, got[1].Name}; !reflect.DeepEqual(names, []string{"fast-local", "quality-code"}) {
+	if names := []string{got[0].Name, got[1].Name, got[2].Name}; !reflect.DeepEqual(names, []string{"fast-local", "multilingual", "quality-code"}) {
 		t.Fatalf("preset names = %v", names)
 	}
 
@@ -27,7 +27,15 @@ func TestEmbeddingPresetsExactValuesAndStableListing(t *testing.T) {
 		t.Fatalf("fast-local embedding = %+v", e)
 	}
 
-	quality := got[1]
+	multilingual := got[1]
+	if multilingual.Description == "" {
+		t.Fatal("multilingual description is empty")
+	}
+	if e := multilingual.Embedding; e.Provider != "ollama" || e.Model != "bge-m3" || e.Dimensions != 1024 || e.OllamaContext != 8192 || len(e.OllamaOptions) != 0 || e.QueryTemplate != "" || e.DocumentTemplate != "" {
+		t.Fatalf("multilingual embedding = %+v", e)
+	}
+
+	quality := got[2]
 	if quality.Description == "" {
 		t.Fatal("quality-code description is empty")
 	}
@@ -3,9 +3,11 @@ package config
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -181,6 +183,7 @@ func (r *ConfigResolution) Resolve(projectDir string) (*ResolvedConfig, error) {
 	globalCfg, err := LoadGlobalConfig()
 	if err == nil && globalCfg != nil {
 		r.mergeConfig(result.Config, &globalCfg.Defaults)
+		mergeHooksConfig(result.Config, &globalCfg.Defaults)
 	}
 
 	// Step 3: Check if this project is in global projects list
@@ -216,6 +219,7 @@ func (r *ConfigResolution) Resolve(projectDir string) (*ResolvedConfig, error) {
 		legacyPath := filepath.Join(projectDir, DefaultDataDir, DefaultConfigFile)
 		if cfg, err := r.loadYAMLConfig(legacyPath); err == nil {
 			r.mergeConfig(result.Config, cfg)
+			warnProjectHooks(cfg, legacyPath)
 			r.foundConfigFiles = append(r.foundConfigFiles, legacyPath)
 		}
 
@@ -223,6 +227,7 @@ func (r *ConfigResolution) Resolve(projectDir string) (*ResolvedConfig, error) {
 		xdgPath := filepath.Join(projectDir, ".config", "vecgrep.yaml")
 		if cfg, err := r.loadYAMLConfig(xdgPath); err == nil {
 			r.mergeConfig(result.Config, cfg)
+			warnProjectHooks(cfg, xdgPath)
 			r.foundConfigFiles = append(r.foundConfigFiles, xdgPath)
 		}
 
@@ -241,11 +246,13 @@ func (r *ConfigResolution) Resolve(projectDir string) (*ResolvedConfig, error) {
 		if yamlExists {
 			if cfg, err := r.loadYAMLConfig(yamlPath); err == nil {
 				r.mergeConfig(result.Config, cfg)
+				warnProjectHooks(cfg, yamlPath)
 				r.foundConfigFiles = append(r.foundConfigFiles, yamlPath)
 			}
 		} else if ymlExists {
 			if cfg, err := r.loadYAMLConfig(ymlPath); err == nil {
 				r.mergeConfig(result.Config, cfg)
+				warnProjectHooks(cfg, ymlPath)
 				r.foundConfigFiles = append(r.foundConfigFiles, ymlPath)
 			}
 		}
@@ -289,11 +296,29 @@ func (r *ConfigResolution) Resolve(projectDir string) (*ResolvedConfig, error) {
 		if !filepath.IsAbs(result.Config.DBPath) {
 			result.Config.DBPath = filepath.Join(projectDir, result.Config.DBPath)
 		}
+		result.Config.Indexing.ExtraRoots = resolveExtraRoots(projectDir, result.Config.Indexing.ExtraRoots)
 	}
 
 	return result, nil
 }
 
+// resolveExtraRoots returns a copy of roots with ~ expanded and relative
+// paths joined to projectDir, so every consumer sees the same absolute roots.
+func resolveExtraRoots(projectDir string, roots []ExtraRootConfig) []ExtraRootConfig {
+	if len(roots) == 0 {
+		return roots
+	}
+	resolved := make([]ExtraRootConfig, len(roots))
+	for i, root := range roots {
+		root.Path = ExpandPath(strings.TrimSpace(root.Path))
+		if root.Path != "" && !filepath.IsAbs(root.Path) {
+			root.Path = filepath.Join(projectDir, root.Path)
+		}
+		resolved[i] = root
+	}
+	return resolved
+}
+
 // resolveCtx returns a context for git operations during config resolution.
 // Uses a short timeout so git detection never blocks the caller for long.
 func (r *ConfigResolution) resolveCtx() context.Context {
@@ -335,6 +360,50 @@ func (r *ConfigResolution) mergeConfig(dst, src *Config) {
 	mergeCodemapConfig(dst, src)
 	mergeDaemonConfig(dst, src)
 	mergeCacheConfig(dst, src)
+	mergeChunkersConfig(dst, src)
+	mergeSecurityConfig(dst, src)
+}
+
+func mergeSecurityConfig(dst, src *Config) {
+	if src.Security.Encrypt || src.has("security.encrypt") {
+		dst.Security.Encrypt = src.Security.Encrypt
+	}
+}
+
+// mergeHooksConfig applies src's hooks. Only the global config may set
+// them: project config files travel with cloned repositories, and a plain
+// search must not run commands a repository's author chose.
+func mergeHooksConfig(dst, src *Config) {
+	if len(src.Hooks.PostSearch) > 0 || src.has("hooks.post_search") {
+		dst.Hooks.PostSearch = src.Hooks.PostSearch
+	}
+	if src.Hooks.Timeout != 0 || src.has("hooks.timeout") {
+		dst.Hooks.Timeout = src.Hooks.Timeout
+	}
+}
+
+// warnProjectHooks reports hooks set in a project config file, which are
+// ignored (see mergeHooksConfig).
+func warnProjectHooks(cfg *Config, path string) {
+	if len(cfg.Hooks.PostSearch) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring hooks.post_search in %s; hooks only run from the global config (vecgrep config set --global hooks.post_search ...)\n", path)
+	}
+}
+
+// mergeChunkersConfig overlays src's external chunkers per extension, so a
+// project can add or replace one chunker without repeating the global set.
+func mergeChunkersConfig(dst, src *Config) {
+	if len(src.Chunkers) == 0 {
+		return
+	}
+	merged := make(map[string]ExternalChunkerConfig, len(dst.Chunkers)+len(src.Chunkers))
+	for ext, chunker := range dst.Chunkers {
+		merged[ext] = chunker
+	}
+	for ext, chunker := range src.Chunkers {
+		merged[ext] = chunker
+	}
+	dst.Chunkers = merged
 }
 
 func mergeEmbeddingConfig(dst, src *EmbeddingConfig) {
@@ -353,6 +422,12 @@ func mergeEmbeddingConfig(dst, src *EmbeddingConfig) {
 	if src.OllamaContext != 0 {
 		dst.OllamaContext = src.OllamaContext
 	}
+	if src.MaxInputTokens != 0 {
+		dst.MaxInputTokens = src.MaxInputTokens
+	}
+	if src.OverlongInput != "" {
+		dst.OverlongInput = src.OverlongInput
+	}
 	if len(src.OllamaOptions) > 0 {
 		dst.OllamaOptions = src.OllamaOptions
 	}
@@ -385,9 +460,36 @@ func mergeEmbeddingConfig(dst, src *EmbeddingConfig) {
 	if src.MaxBatchSize != 0 {
 		dst.MaxBatchSize = src.MaxBatchSize
 	}
+	if src.BatchSize != 0 {
+		dst.BatchSize = src.BatchSize
+	}
 	if src.KeepAlive != "" {
 		dst.KeepAlive = src.KeepAlive
 	}
+	if src.BudgetUSD > 0 {
+		dst.BudgetUSD = src.BudgetUSD
+	}
+	if src.TEIURL != "" {
+		dst.TEIURL = src.TEIURL
+	}
+	if src.TEIAPIKey != "" {
+		dst.TEIAPIKey = src.TEIAPIKey
+	}
+	if src.TEITruncation != "" {
+		dst.TEITruncation = src.TEITruncation
+	}
+	if src.ExecCommand != "" {
+		dst.ExecCommand = src.ExecCommand
+	}
+	if src.ExecTimeout > 0 {
+		dst.ExecTimeout = src.ExecTimeout
+	}
+	if src.ONNXModelDir != "" {
+		dst.ONNXModelDir = src.ONNXModelDir
+	}
+	if src.ONNXLibraryPath != "" {
+		dst.ONNXLibraryPath = src.ONNXLibraryPath
+	}
 }
 
 // mergeThrottleConfig merges non-zero throttle settings from src into dst.
@@ -418,6 +520,12 @@ func mergeIndexingConfig(dst, src *IndexingConfig) {
 	if src.MaxFileSize != 0 {
 		dst.MaxFileSize = src.MaxFileSize
 	}
+	if src.MaxChunksPerFile != 0 {
+		dst.MaxChunksPerFile = src.MaxChunksPerFile
+	}
+	if src.Workers != 0 {
+		dst.Workers = src.Workers
+	}
 	if src.SourceBufferBytes != 0 {
 		dst.SourceBufferBytes = src.SourceBufferBytes
 	}
@@ -427,18 +535,48 @@ func mergeIndexingConfig(dst, src *IndexingConfig) {
 	if src.SyncIntervalDuration != 0 {
 		dst.SyncIntervalDuration = src.SyncIntervalDuration
 	}
+	if len(src.Languages.Enabled) > 0 {
+		dst.Languages.Enabled = src.Languages.Enabled
+	}
+	if len(src.Languages.Disabled) > 0 {
+		dst.Languages.Disabled = src.Languages.Disabled
+	}
+	if len(src.ExtraRoots) > 0 {
+		dst.ExtraRoots = src.ExtraRoots
+	}
+	if src.SecretsPolicy != "" {
+		dst.SecretsPolicy = src.SecretsPolicy
+	}
+	if len(src.Tags) > 0 {
+		dst.Tags = src.Tags
+	}
 }
 
 func mergeServerConfig(dst, src *ServerConfig) {
 	if src.MCPEnabled {
 		dst.MCPEnabled = true
 	}
+	if src.MaxResponseBytes > 0 {
+		dst.MaxResponseBytes = src.MaxResponseBytes
+	}
+	if src.Editor != "" {
+		dst.Editor = src.Editor
+	}
 }
 
 func mergeServerConfigWithPresence(dst, src *Config) {
 	if src.Server.MCPEnabled || src.has("server.mcp_enabled") {
 		dst.Server.MCPEnabled = src.Server.MCPEnabled
 	}
+	if src.Server.MaxResponseBytes != 0 || src.has("server.max_response_bytes") {
+		dst.Server.MaxResponseBytes = src.Server.MaxResponseBytes
+	}
+	if src.Server.Editor != "" || src.has("server.editor") {
+		dst.Server.Editor = src.Server.Editor
+	}
+	if src.Server.AuthToken != "" || src.has("server.auth_token") {
+		dst.Server.AuthToken = src.Server.AuthToken
+	}
 }
 
 func mergeSearchConfig(dst, src *Config) {
@@ -451,6 +589,45 @@ func mergeSearchConfig(dst, src *Config) {
 	if src.Search.TextWeight != 0 || src.has("search.text_weight") {
 		dst.Search.TextWeight = src.Search.TextWeight
 	}
+	if src.Search.Fusion != "" || src.has("search.fusion") {
+		dst.Search.Fusion = src.Search.Fusion
+	}
+	if src.Search.RerankerURL != "" || src.has("search.reranker_url") {
+		dst.Search.RerankerURL = src.Search.RerankerURL
+	}
+	if src.Search.RerankerTimeout != 0 || src.has("search.reranker_timeout") {
+		dst.Search.RerankerTimeout = src.Search.RerankerTimeout
+	}
+	if src.Search.Reranker != "" || src.has("search.reranker") {
+		dst.Search.Reranker = src.Search.Reranker
+	}
+	if src.Search.RerankerModel != "" || src.has("search.reranker_model") {
+		dst.Search.RerankerModel = src.Search.RerankerModel
+	}
+	if src.Search.RerankerAPIKey != "" || src.has("search.reranker_api_key") {
+		dst.Search.RerankerAPIKey = src.Search.RerankerAPIKey
+	}
+	if src.Search.RerankTopN != 0 || src.has("search.rerank_top_n") {
+		dst.Search.RerankTopN = src.Search.RerankTopN
+	}
+	if src.Search.TranslatorURL != "" || src.has("search.translator_url") {
+		dst.Search.TranslatorURL = src.Search.TranslatorURL
+	}
+	if src.Search.TranslatorTimeout != 0 || src.has("search.translator_timeout") {
+		dst.Search.TranslatorTimeout = src.Search.TranslatorTimeout
+	}
+	if src.Search.ExpanderModel != "" || src.has("search.expander_model") {
+		dst.Search.ExpanderModel = src.Search.ExpanderModel
+	}
+	if src.Search.ExpanderURL != "" || src.has("search.expander_url") {
+		dst.Search.ExpanderURL = src.Search.ExpanderURL
+	}
+	if src.Search.Timeout != 0 || src.has("search.timeout") {
+		dst.Search.Timeout = src.Search.Timeout
+	}
+	if src.Search.InferFilters != nil || src.has("search.infer_filters") {
+		dst.Search.InferFilters = src.Search.InferFilters
+	}
 }
 
 func mergeVectorConfig(dst, src *Config) {
@@ -463,6 +640,36 @@ func mergeVectorConfig(dst, src *Config) {
 	if src.Vector.VecLite.EfSearch != 0 || src.has("vector.veclite.ef_search") {
 		dst.Vector.VecLite.EfSearch = src.Vector.VecLite.EfSearch
 	}
+	if src.Vector.VecLite.Quantization != "" || src.has("vector.veclite.quantization") {
+		dst.Vector.VecLite.Quantization = src.Vector.VecLite.Quantization
+	}
+	if src.Vector.VecLite.Hot != nil || src.has("vector.veclite.hot") {
+		dst.Vector.VecLite.Hot = src.Vector.VecLite.Hot
+	}
+	if src.Vector.VecLite.HotWindow != 0 || src.has("vector.veclite.hot_window") {
+		dst.Vector.VecLite.HotWindow = src.Vector.VecLite.HotWindow
+	}
+	if src.Vector.VecLite.HotMaxChunks != 0 || src.has("vector.veclite.hot_max_chunks") {
+		dst.Vector.VecLite.HotMaxChunks = src.Vector.VecLite.HotMaxChunks
+	}
+	if src.Vector.Backend != "" || src.has("vector.backend") {
+		dst.Vector.Backend = src.Vector.Backend
+	}
+	if src.Vector.Qdrant.URL != "" || src.has("vector.qdrant.url") {
+		dst.Vector.Qdrant.URL = src.Vector.Qdrant.URL
+	}
+	if src.Vector.Qdrant.APIKey != "" || src.has("vector.qdrant.api_key") {
+		dst.Vector.Qdrant.APIKey = src.Vector.Qdrant.APIKey
+	}
+	if src.Vector.Qdrant.Collection != "" || src.has("vector.qdrant.collection") {
+		dst.Vector.Qdrant.Collection = src.Vector.Qdrant.Collection
+	}
+	if src.Vector.Pgvector.URL != "" || src.has("vector.pgvector.url") {
+		dst.Vector.Pgvector.URL = src.Vector.Pgvector.URL
+	}
+	if src.Vector.Pgvector.Table != "" || src.has("vector.pgvector.table") {
+		dst.Vector.Pgvector.Table = src.Vector.Pgvector.Table
+	}
 }
 
 func mergeCodemapConfig(dst, src *Config) {
@@ -517,6 +724,12 @@ func mergeDaemonConfig(dst, src *Config) {
 	if src.Daemon.Debounce != 0 || src.has("daemon.debounce") {
 		dst.Daemon.Debounce = src.Daemon.Debounce
 	}
+	if src.Daemon.Poll || src.has("daemon.poll") {
+		dst.Daemon.Poll = src.Daemon.Poll
+	}
+	if src.Daemon.PollInterval != 0 || src.has("daemon.poll_interval") {
+		dst.Daemon.PollInterval = src.Daemon.PollInterval
+	}
 	if src.Daemon.SweepInterval != "" || src.has("daemon.sweep_interval") {
 		dst.Daemon.SweepInterval = src.Daemon.SweepInterval
 	}
@@ -558,6 +771,14 @@ func (r *ConfigResolution) applyEnvironment(cfg *Config) {
 			cfg.Embedding.OllamaContext = contextSize
 		}
 	}
+	if val := os.Getenv("VECGREP_EMBEDDING_MAX_INPUT_TOKENS"); val != "" {
+		if tokens, err := strconv.Atoi(val); err == nil && tokens >= 0 {
+			cfg.Embedding.MaxInputTokens = tokens
+		}
+	}
+	if val := os.Getenv("VECGREP_EMBEDDING_OVERLONG_INPUT"); val != "" {
+		cfg.Embedding.OverlongInput = val
+	}
 	if val := os.Getenv("VECGREP_OLLAMA_OPTIONS"); val != "" {
 		var options map[string]any
 		if yaml.Unmarshal([]byte(val), &options) == nil {
@@ -576,6 +797,16 @@ func (r *ConfigResolution) applyEnvironment(cfg *Config) {
 			cfg.Indexing.SourceBufferBytes = size
 		}
 	}
+	if val := os.Getenv("VECGREP_INDEXING_MAX_CHUNKS_PER_FILE"); val != "" {
+		if limit, err := strconv.Atoi(val); err == nil && limit > 0 {
+			cfg.Indexing.MaxChunksPerFile = limit
+		}
+	}
+	if val := os.Getenv("VECGREP_INDEXING_WORKERS"); val != "" {
+		if workers, err := strconv.Atoi(val); err == nil && workers > 0 {
+			cfg.Indexing.Workers = workers
+		}
+	}
 	if val := os.Getenv("VECGREP_INDEXING_SYNC_INTERVAL"); val != "" {
 		if interval, err := strconv.Atoi(val); err == nil && interval >= 0 {
 			cfg.Indexing.SyncInterval = interval
@@ -586,6 +817,9 @@ func (r *ConfigResolution) applyEnvironment(cfg *Config) {
 			cfg.Indexing.SyncIntervalDuration = interval
 		}
 	}
+	if val := os.Getenv("VECGREP_INDEXING_SECRETS_POLICY"); val != "" {
+		cfg.Indexing.SecretsPolicy = val
+	}
 
 	// OpenAI settings - check both VECGREP_ and standard OPENAI_ prefixes
 	if val := os.Getenv("VECGREP_OPENAI_API_KEY"); val != "" {
@@ -623,6 +857,35 @@ func (r *ConfigResolution) applyEnvironment(cfg *Config) {
 		cfg.Embedding.VoyageBaseURL = val
 	}
 
+	// TEI provider settings
+	if val := os.Getenv("VECGREP_EMBEDDING_TEI_URL"); val != "" {
+		cfg.Embedding.TEIURL = val
+	}
+	if val := os.Getenv("VECGREP_EMBEDDING_TEI_API_KEY"); val != "" {
+		cfg.Embedding.TEIAPIKey = val
+	}
+	if val := os.Getenv("VECGREP_EMBEDDING_TEI_TRUNCATION"); val != "" {
+		cfg.Embedding.TEITruncation = val
+	}
+
+	// Exec provider settings
+	if val := os.Getenv("VECGREP_EMBEDDING_EXEC_COMMAND"); val != "" {
+		cfg.Embedding.ExecCommand = val
+	}
+	if val := os.Getenv("VECGREP_EMBEDDING_EXEC_TIMEOUT"); val != "" {
+		if d, err := time.ParseDuration(val); err == nil && d >= 0 {
+			cfg.Embedding.ExecTimeout = d
+		}
+	}
+
+	// ONNX provider settings
+	if val := os.Getenv("VECGREP_EMBEDDING_ONNX_MODEL_DIR"); val != "" {
+		cfg.Embedding.ONNXModelDir = val
+	}
+	if val := os.Getenv("VECGREP_EMBEDDING_ONNX_LIBRARY_PATH"); val != "" {
+		cfg.Embedding.ONNXLibraryPath = val
+	}
+
 	// Embedding throttle settings
 	if val := os.Getenv("VECGREP_EMBEDDING_THROTTLE_ENABLED"); val != "" {
 		if enabled, err := strconv.ParseBool(val); err == nil {
@@ -646,9 +909,19 @@ func (r *ConfigResolution) applyEnvironment(cfg *Config) {
 			cfg.Embedding.MaxBatchSize = n
 		}
 	}
+	if val := os.Getenv("VECGREP_EMBEDDING_BATCH_SIZE"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n >= 0 {
+			cfg.Embedding.BatchSize = n
+		}
+	}
 	if val := os.Getenv("VECGREP_EMBEDDING_KEEP_ALIVE"); val != "" {
 		cfg.Embedding.KeepAlive = val
 	}
+	if val := os.Getenv("VECGREP_EMBEDDING_BUDGET_USD"); val != "" {
+		if budget, err := strconv.ParseFloat(val, 64); err == nil && budget >= 0 {
+			cfg.Embedding.BudgetUSD = budget
+		}
+	}
 
 	// Data directory
 	if val := os.Getenv("VECGREP_DATA_DIR"); val != "" {
@@ -675,6 +948,29 @@ func (r *ConfigResolution) applyEnvironment(cfg *Config) {
 			cfg.Vector.VecLite.EfSearch = ef
 		}
 	}
+	if val := os.Getenv("VECGREP_VECTOR_VECLITE_QUANTIZATION"); val != "" {
+		cfg.Vector.VecLite.Quantization = val
+	}
+	if val := os.Getenv("VECGREP_VECTOR_BACKEND"); val != "" {
+		cfg.Vector.Backend = val
+	}
+	if val := os.Getenv("VECGREP_VECTOR_QDRANT_URL"); val != "" {
+		cfg.Vector.Qdrant.URL = val
+	}
+	if val := os.Getenv("VECGREP_VECTOR_QDRANT_API_KEY"); val != "" {
+		cfg.Vector.Qdrant.APIKey = val
+	} else if val := os.Getenv("QDRANT_API_KEY"); val != "" {
+		cfg.Vector.Qdrant.APIKey = val
+	}
+	if val := os.Getenv("VECGREP_VECTOR_QDRANT_COLLECTION"); val != "" {
+		cfg.Vector.Qdrant.Collection = val
+	}
+	if val := os.Getenv("VECGREP_VECTOR_PGVECTOR_URL"); val != "" {
+		cfg.Vector.Pgvector.URL = val
+	}
+	if val := os.Getenv("VECGREP_VECTOR_PGVECTOR_TABLE"); val != "" {
+		cfg.Vector.Pgvector.Table = val
+	}
 
 	// Codemap integration settings
 	if val := os.Getenv("VECGREP_CODEMAP_ENABLED"); val != "" {
@@ -697,6 +993,12 @@ func (r *ConfigResolution) applyEnvironment(cfg *Config) {
 		cfg.Codemap.StructuralChunks = val
 	}
 
+	if val := os.Getenv("VECGREP_SECURITY_ENCRYPT"); val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			cfg.Security.Encrypt = enabled
+		}
+	}
+
 	// Daemon settings
 	if val := os.Getenv("VECGREP_DAEMON_AUTOSTART"); val != "" {
 		if enabled, err := strconv.ParseBool(val); err == nil {
@@ -728,6 +1030,16 @@ func (r *ConfigResolution) applyEnvironment(cfg *Config) {
 			cfg.Daemon.Debounce = n
 		}
 	}
+	if val := os.Getenv("VECGREP_DAEMON_POLL"); val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			cfg.Daemon.Poll = enabled
+		}
+	}
+	if val := os.Getenv("VECGREP_DAEMON_POLL_INTERVAL"); val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n > 0 {
+			cfg.Daemon.PollInterval = n
+		}
+	}
 	if val := os.Getenv("VECGREP_DAEMON_SWEEP_INTERVAL"); val != "" {
 		cfg.Daemon.SweepInterval = val
 	}
@@ -843,6 +1155,12 @@ func ShowResolvedConfig(cfg *Config, sources []string) string {
 	fmt.Fprintf(&sb, "  provider: %s\n", cfg.Embedding.Provider)
 	fmt.Fprintf(&sb, "  model: %s\n", cfg.Embedding.Model)
 	fmt.Fprintf(&sb, "  dimensions: %d\n", cfg.Embedding.Dimensions)
+	if cfg.Embedding.MaxInputTokens > 0 {
+		fmt.Fprintf(&sb, "  max_input_tokens: %d\n", cfg.Embedding.MaxInputTokens)
+	}
+	if cfg.Embedding.OverlongInput != "" {
+		fmt.Fprintf(&sb, "  overlong_input: %s\n", cfg.Embedding.OverlongInput)
+	}
 	if cfg.Embedding.Provider == "ollama" {
 		fmt.Fprintf(&sb, "  ollama_url: %s\n", cfg.Embedding.OllamaURL)
 	}
@@ -876,6 +1194,27 @@ func ShowResolvedConfig(cfg *Config, sources []string) string {
 			fmt.Fprintf(&sb, "  voyage_base_url: %s\n", cfg.Embedding.VoyageBaseURL)
 		}
 	}
+	if cfg.Embedding.Provider == "tei" {
+		fmt.Fprintf(&sb, "  tei_url: %s\n", cfg.Embedding.TEIURL)
+		if cfg.Embedding.TEIAPIKey != "" {
+			sb.WriteString("  tei_api_key: [set]\n")
+		}
+		if cfg.Embedding.TEITruncation != "" {
+			fmt.Fprintf(&sb, "  tei_truncation: %s\n", cfg.Embedding.TEITruncation)
+		}
+	}
+	if cfg.Embedding.Provider == "exec" {
+		fmt.Fprintf(&sb, "  exec_command: %s\n", cfg.Embedding.ExecCommand)
+		if cfg.Embedding.ExecTimeout > 0 {
+			fmt.Fprintf(&sb, "  exec_timeout: %s\n", cfg.Embedding.ExecTimeout)
+		}
+	}
+	if cfg.Embedding.Provider == "onnx" {
+		fmt.Fprintf(&sb, "  onnx_model_dir: %s\n", cfg.Embedding.ONNXModelDir)
+		if cfg.Embedding.ONNXLibraryPath != "" {
+			fmt.Fprintf(&sb, "  onnx_library_path: %s\n", cfg.Embedding.ONNXLibraryPath)
+		}
+	}
 
 	// Embedding throttle settings
 	sb.WriteString("\nEmbedding throttle:\n")
@@ -891,9 +1230,17 @@ func ShowResolvedConfig(cfg *Config, sources []string) string {
 	if cfg.Embedding.MaxBatchSize > 0 {
 		fmt.Fprintf(&sb, "  max_batch_size: %d\n", cfg.Embedding.MaxBatchSize)
 	}
+	if cfg.Embedding.BatchSize > 0 {
+		fmt.Fprintf(&sb, "  batch_size: %d\n", cfg.Embedding.BatchSize)
+	} else {
+		sb.WriteString("  batch_size: auto\n")
+	}
 	if cfg.Embedding.KeepAlive != "" {
 		fmt.Fprintf(&sb, "  keep_alive: %s\n", cfg.Embedding.KeepAlive)
 	}
+	if cfg.Embedding.BudgetUSD > 0 {
+		fmt.Fprintf(&sb, "  budget_usd: %.2f\n", cfg.Embedding.BudgetUSD)
+	}
 
 	// Cache settings
 	sb.WriteString("\nCache:\n")
@@ -914,23 +1261,130 @@ func ShowResolvedConfig(cfg *Config, sources []string) string {
 	fmt.Fprintf(&sb, "  chunk_size: %d\n", cfg.Indexing.ChunkSize)
 	fmt.Fprintf(&sb, "  chunk_overlap: %d\n", cfg.Indexing.ChunkOverlap)
 	fmt.Fprintf(&sb, "  max_file_size: %d\n", cfg.Indexing.MaxFileSize)
+	fmt.Fprintf(&sb, "  max_chunks_per_file: %d\n", cfg.Indexing.MaxChunksPerFile)
+	if cfg.Indexing.Workers > 0 {
+		fmt.Fprintf(&sb, "  workers: %d\n", cfg.Indexing.Workers)
+	}
 	fmt.Fprintf(&sb, "  ignore_patterns: %v\n", cfg.Indexing.IgnorePatterns)
+	if cfg.Indexing.SecretsPolicy != "" {
+		fmt.Fprintf(&sb, "  secrets_policy: %s\n", cfg.Indexing.SecretsPolicy)
+	}
+	if len(cfg.Indexing.Languages.Enabled) > 0 {
+		fmt.Fprintf(&sb, "  languages.enabled: %v\n", cfg.Indexing.Languages.Enabled)
+	}
+	if len(cfg.Indexing.Languages.Disabled) > 0 {
+		fmt.Fprintf(&sb, "  languages.disabled: %v\n", cfg.Indexing.Languages.Disabled)
+	}
+	for _, root := range cfg.Indexing.ExtraRoots {
+		if root.Name != "" {
+			fmt.Fprintf(&sb, "  extra_root: %s (%s)\n", root.Path, root.Name)
+		} else {
+			fmt.Fprintf(&sb, "  extra_root: %s\n", root.Path)
+		}
+	}
+	for _, rule := range cfg.Indexing.Tags {
+		var match []string
+		if rule.Path != "" {
+			match = append(match, "path="+rule.Path)
+		}
+		if rule.Content != "" {
+			match = append(match, "content="+rule.Content)
+		}
+		fmt.Fprintf(&sb, "  tag: %s (%s)\n", strings.Join(rule.Tags, ","), strings.Join(match, " "))
+	}
 
 	// Search settings
 	sb.WriteString("\nSearch:\n")
 	fmt.Fprintf(&sb, "  default_mode: %s\n", cfg.Search.DefaultMode)
 	fmt.Fprintf(&sb, "  vector_weight: %.2f\n", cfg.Search.VectorWeight)
 	fmt.Fprintf(&sb, "  text_weight: %.2f\n", cfg.Search.TextWeight)
+	if cfg.Search.Fusion != "" {
+		fmt.Fprintf(&sb, "  fusion: %s\n", cfg.Search.Fusion)
+	}
+	if cfg.Search.Reranker != "" {
+		fmt.Fprintf(&sb, "  reranker: %s\n", cfg.Search.Reranker)
+	}
+	if cfg.Search.RerankerModel != "" {
+		fmt.Fprintf(&sb, "  reranker_model: %s\n", cfg.Search.RerankerModel)
+	}
+	if cfg.Search.RerankerURL != "" {
+		fmt.Fprintf(&sb, "  reranker_url: %s\n", cfg.Search.RerankerURL)
+		fmt.Fprintf(&sb, "  reranker_timeout: %s\n", cfg.Search.RerankerTimeout)
+	}
+	if cfg.Search.RerankerAPIKey != "" {
+		sb.WriteString("  reranker_api_key: [set]\n")
+	}
+	if cfg.Search.RerankTopN > 0 {
+		fmt.Fprintf(&sb, "  rerank_top_n: %d\n", cfg.Search.RerankTopN)
+	}
+	if cfg.Search.TranslatorURL != "" {
+		fmt.Fprintf(&sb, "  translator_url: %s\n", cfg.Search.TranslatorURL)
+		fmt.Fprintf(&sb, "  translator_timeout: %s\n", cfg.Search.TranslatorTimeout)
+	}
+	if cfg.Search.ExpanderModel != "" {
+		fmt.Fprintf(&sb, "  expander_model: %s\n", cfg.Search.ExpanderModel)
+	}
+	if cfg.Search.ExpanderURL != "" {
+		fmt.Fprintf(&sb, "  expander_url: %s\n", cfg.Search.ExpanderURL)
+	}
+	if cfg.Search.Timeout > 0 {
+		fmt.Fprintf(&sb, "  timeout: %s\n", cfg.Search.Timeout)
+	}
+	if cfg.Search.InferFilters != nil {
+		fmt.Fprintf(&sb, "  infer_filters: %t\n", *cfg.Search.InferFilters)
+	}
 
 	// Server settings
 	sb.WriteString("\nServer:\n")
 	fmt.Fprintf(&sb, "  mcp_enabled: %t\n", cfg.Server.MCPEnabled)
+	if cfg.Server.MaxResponseBytes > 0 {
+		fmt.Fprintf(&sb, "  max_response_bytes: %d\n", cfg.Server.MaxResponseBytes)
+	}
+	if cfg.Server.Editor != "" {
+		fmt.Fprintf(&sb, "  editor: %s\n", cfg.Server.Editor)
+	}
+	if cfg.Server.AuthToken != "" {
+		sb.WriteString("  auth_token: [set]\n")
+	}
 
 	// Vector settings
 	sb.WriteString("\nVector:\n")
 	fmt.Fprintf(&sb, "  veclite.m: %d\n", cfg.Vector.VecLite.M)
 	fmt.Fprintf(&sb, "  veclite.ef_construction: %d\n", cfg.Vector.VecLite.EfConstruction)
 	fmt.Fprintf(&sb, "  veclite.ef_search: %d\n", cfg.Vector.VecLite.EfSearch)
+	if cfg.Vector.VecLite.Quantization != "" {
+		fmt.Fprintf(&sb, "  veclite.quantization: %s\n", cfg.Vector.VecLite.Quantization)
+	}
+	if window := cfg.Vector.VecLite.HotTierWindow(); window > 0 {
+		fmt.Fprintf(&sb, "  veclite.hot_window: %s\n", window)
+	} else {
+		sb.WriteString("  veclite.hot: false\n")
+	}
+	if cfg.Vector.Backend == "qdrant" {
+		qdrantURL := cfg.Vector.Qdrant.URL
+		if qdrantURL == "" {
+			qdrantURL = DefaultQdrantURL
+		}
+		sb.WriteString("  backend: qdrant\n")
+		fmt.Fprintf(&sb, "  qdrant.url: %s\n", qdrantURL)
+		if cfg.Vector.Qdrant.Collection != "" {
+			fmt.Fprintf(&sb, "  qdrant.collection: %s\n", cfg.Vector.Qdrant.Collection)
+		}
+		if cfg.Vector.Qdrant.APIKey != "" {
+			sb.WriteString("  qdrant.api_key: [set]\n")
+		}
+	}
+	if cfg.Vector.Backend == "pgvector" {
+		sb.WriteString("  backend: pgvector\n")
+		pgURL := cfg.Vector.Pgvector.URL
+		if parsed, err := url.Parse(pgURL); err == nil {
+			pgURL = parsed.Redacted()
+		}
+		fmt.Fprintf(&sb, "  pgvector.url: %s\n", pgURL)
+		if cfg.Vector.Pgvector.Table != "" {
+			fmt.Fprintf(&sb, "  pgvector.table: %s\n", cfg.Vector.Pgvector.Table)
+		}
+	}
 
 	// Codemap settings
 	sb.WriteString("\nCodemap:\n")
@@ -952,10 +1406,41 @@ func ShowResolvedConfig(cfg *Config, sources []string) string {
 	fmt.Fprintf(&sb, "  daemon.embed_rps: %.1f\n", cfg.Daemon.EmbedRPS)
 	fmt.Fprintf(&sb, "  daemon.embed_max_in_flight: %d\n", cfg.Daemon.EmbedMaxInFlight)
 	fmt.Fprintf(&sb, "  daemon.debounce: %d\n", cfg.Daemon.Debounce)
+	fmt.Fprintf(&sb, "  daemon.poll: %t\n", cfg.Daemon.Poll)
+	fmt.Fprintf(&sb, "  daemon.poll_interval: %d\n", cfg.Daemon.PollInterval)
 	if cfg.Daemon.SweepInterval != "" {
 		fmt.Fprintf(&sb, "  daemon.sweep_interval: %s\n", cfg.Daemon.SweepInterval)
 	}
 
+	if cfg.Security.Encrypt {
+		sb.WriteString("\nSecurity:\n")
+		sb.WriteString("  security.encrypt: true\n")
+	}
+
+	// External chunkers
+	if len(cfg.Chunkers) > 0 {
+		sb.WriteString("\nChunkers:\n")
+		exts := make([]string, 0, len(cfg.Chunkers))
+		for ext := range cfg.Chunkers {
+			exts = append(exts, ext)
+		}
+		sort.Strings(exts)
+		for _, ext := range exts {
+			fmt.Fprintf(&sb, "  %s: %s\n", ext, cfg.Chunkers[ext].Cmd)
+		}
+	}
+
+	// Hooks
+	if len(cfg.Hooks.PostSearch) > 0 {
+		sb.WriteString("\nHooks:\n")
+		for _, hook := range cfg.Hooks.PostSearch {
+			fmt.Fprintf(&sb, "  post_search: %s\n", hook)
+		}
+		if cfg.Hooks.Timeout > 0 {
+			fmt.Fprintf(&sb, "  timeout: %s\n", cfg.Hooks.Timeout)
+		}
+	}
+
 	// Sources
 	if len(sources) > 0 {
 		sb.WriteString("\nConfig sources (in order of loading):\n")
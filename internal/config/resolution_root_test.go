@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 // TestFindProjectRootFrom_GlobalDirNotAProject is a regression test for the bug
@@ -63,3 +65,32 @@ func TestFindProjectRootFrom_RealProjectStillDetected(t *testing.T) {
 		t.Fatalf("expected root %q, got %q", proj, root)
 	}
 }
+
+func TestExtraRootsYAMLAndResolution(t *testing.T) {
+	data := []byte(`indexing:
+  extra_roots:
+    - ../shared
+    - path: /opt/vendor-lib
+      name: vendor
+`)
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	roots := resolveExtraRoots("/work/app", cfg.Indexing.ExtraRoots)
+	want := []ExtraRootConfig{
+		{Path: "/work/shared"},
+		{Path: "/opt/vendor-lib", Name: "vendor"},
+	}
+	if len(roots) != len(want) {
+		t.Fatalf("roots = %+v, want %+v", roots, want)
+	}
+	for i := range want {
+		if roots[i] != want[i] {
+			t.Errorf("roots[%d] = %+v, want %+v", i, roots[i], want[i])
+		}
+	}
+	if cfg.Indexing.ExtraRoots[0].Path != "../shared" {
+		t.Error("resolveExtraRoots must not rewrite the loaded config in place")
+	}
+}
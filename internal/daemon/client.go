@@ -28,6 +28,15 @@ type reindexSyncResult struct {
 	ChunksCreated  int           `json:"chunks_created"`
 	Duration       time.Duration `json:"duration"`
 	Errors         []string      `json:"errors"`
+
+	// Chunks fitted to the model's input limit or matched by the secret
+	// scanner; omitted when zero.
+	ChunksTruncated   int `json:"chunks_truncated,omitempty"`
+	ChunksSplit       int `json:"chunks_split,omitempty"`
+	ChunksWithSecrets int `json:"chunks_with_secrets,omitempty"`
+	// TokensEstimated marks truncation and split counts based on
+	// estimated token counts.
+	TokensEstimated bool `json:"tokens_estimated,omitempty"`
 }
 
 const reindexSyncReadTimeout = 30 * time.Minute
@@ -85,6 +94,9 @@ func ReindexSync(ctx context.Context, globalDataDir, projectRoot string, req app
 	if len(req.AdditionalIgnores) > 0 {
 		paramsMap["additional_ignores"] = req.AdditionalIgnores
 	}
+	if req.RechunkStale {
+		paramsMap["rechunk_stale"] = true
+	}
 	params, err := json.Marshal(paramsMap)
 	if err != nil {
 		return nil, fmt.Errorf("marshal params: %w", err)
@@ -112,6 +124,11 @@ func ReindexSync(ctx context.Context, globalDataDir, projectRoot string, req app
 		FilesDeleted:   wire.FilesDeleted,
 		ChunksCreated:  wire.ChunksCreated,
 		Duration:       wire.Duration,
+
+		ChunksTruncated:   wire.ChunksTruncated,
+		ChunksSplit:       wire.ChunksSplit,
+		TokensEstimated:   wire.TokensEstimated,
+		ChunksWithSecrets: wire.ChunksWithSecrets,
 	}
 	for _, msg := range wire.Errors {
 		res.Errors = append(res.Errors, errors.New(msg))
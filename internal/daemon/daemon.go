@@ -13,7 +13,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
@@ -22,6 +22,7 @@ import (
 
 	"github.com/abdul-hamid-achik/vecgrep/internal/app"
 	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/logging"
 	"github.com/abdul-hamid-achik/vecgrep/internal/snapshot"
 )
 
@@ -70,8 +71,10 @@ type Daemon struct {
 	sweepDoneCh chan struct{}
 
 	// logSink, when non-nil, is the managed log file the hub writes to and
-	// periodically offloads to fcheap. logOffloadDoneCh signals that loop to exit.
+	// periodically offloads to fcheap; logOut is where the hub logged before
+	// it, restored on shutdown. logOffloadDoneCh signals that loop to exit.
 	logSink          *rotatingSink
+	logOut           io.Writer
 	logOffloadDoneCh chan struct{}
 }
 
@@ -141,9 +144,9 @@ func (d *Daemon) Start(ctx context.Context, preopen ...string) error {
 	// Pre-open requested projects. Best-effort: a bad project is logged, not fatal.
 	for _, root := range preopen {
 		if _, err := d.getOrOpenWorker(ctx, root); err != nil {
-			log.Printf("daemon: pre-open %s failed: %v", root, err)
+			slog.Warn("daemon pre-open failed", "root", root, "err", err)
 		} else {
-			log.Printf("daemon: opened project %s", root)
+			slog.Info("daemon opened project", "root", root)
 		}
 	}
 
@@ -154,7 +157,7 @@ func (d *Daemon) Start(ctx context.Context, preopen ...string) error {
 		_ = d.cleanup()
 		return fmt.Errorf("listen on socket: %w", err)
 	}
-	log.Printf("daemon hub listening on %s (%d project(s) open)", d.socketPath, len(d.listWorkers()))
+	slog.Info("daemon hub listening", "socket", d.socketPath, "projects", len(d.listWorkers()))
 
 	go d.acceptLoop(ctx)
 
@@ -176,7 +179,7 @@ func (d *Daemon) Start(ctx context.Context, preopen ...string) error {
 	// restore plain stderr logging and close the managed log file.
 	if d.logSink != nil {
 		d.offloadLog(context.Background(), snapshot.NewFcheap(), time.Now())
-		log.SetOutput(os.Stderr)
+		logging.SetOutput(d.logOut)
 		_ = d.logSink.Close()
 	}
 
@@ -298,7 +301,7 @@ func (d *Daemon) acceptLoop(ctx context.Context) {
 			case <-d.stopCh:
 				return
 			default:
-				log.Printf("daemon accept error: %v", err)
+				slog.Error("daemon accept failed", "err", err)
 				return
 			}
 		}
@@ -392,14 +395,10 @@ func (d *Daemon) handleSearch(ctx context.Context, req *jsonRPCRequest) jsonRPCR
 		return jsonRPCResponse{ID: req.ID, Error: rpcErr}
 	}
 	w.touchActivity()
-	results, mode, warnings, err := w.search(ctx, params)
+	result, err := w.search(ctx, params)
 	if err != nil {
 		return errResp(req, -32603, fmt.Sprintf("search failed: %v", err))
 	}
-	result := map[string]any{"results": results, "mode": mode}
-	if len(warnings) > 0 {
-		result["warnings"] = warnings
-	}
 	return jsonRPCResponse{ID: req.ID, Result: result}
 }
 
@@ -432,6 +431,7 @@ type reindexSyncParams struct {
 	StructuralChunks  string   `json:"structural_chunks,omitempty"`
 	Paths             []string `json:"paths,omitempty"`
 	AdditionalIgnores []string `json:"additional_ignores,omitempty"`
+	RechunkStale      bool     `json:"rechunk_stale,omitempty"`
 }
 
 // handleReindexSync runs an incremental (or full) reindex synchronously and
@@ -453,6 +453,7 @@ func (d *Daemon) handleReindexSync(ctx context.Context, req *jsonRPCRequest) jso
 		FullReindex:       p.Full,
 		AdditionalIgnores: p.AdditionalIgnores,
 		StructuralChunks:  p.StructuralChunks,
+		RechunkStale:      p.RechunkStale,
 	})
 	if err != nil {
 		return jsonRPCResponse{ID: req.ID, Error: &jsonRPCError{Code: -32000, Message: err.Error()}}
@@ -463,6 +464,11 @@ func (d *Daemon) handleReindexSync(ctx context.Context, req *jsonRPCRequest) jso
 		FilesDeleted:   result.FilesDeleted,
 		ChunksCreated:  result.ChunksCreated,
 		Duration:       result.Duration,
+
+		ChunksTruncated:   result.ChunksTruncated,
+		ChunksSplit:       result.ChunksSplit,
+		TokensEstimated:   result.TokensEstimated,
+		ChunksWithSecrets: result.ChunksWithSecrets,
 	}
 	for _, e := range result.Errors {
 		wire.Errors = append(wire.Errors, e.Error())
@@ -657,9 +663,15 @@ type searchParams struct {
 	MinLine     int      `json:"min_line,omitempty"`
 	MaxLine     int      `json:"max_line,omitempty"`
 	MinScore    float32  `json:"min_score,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	EfSearch    int      `json:"ef_search,omitempty"`
 	Explain     bool     `json:"explain,omitempty"`
 	FilePaths   []string `json:"file_paths,omitempty"`
 	Symbol      string   `json:"symbol,omitempty"`
+	Rerank      *bool    `json:"rerank,omitempty"`
+	// TimeoutMS bounds the search; on expiry the results found so far come
+	// back marked partial. Zero uses search.timeout.
+	TimeoutMS int `json:"timeout_ms,omitempty"`
 }
 
 // --- periodic background loops (hub-level) ---
@@ -672,7 +684,7 @@ func (d *Daemon) sweepLoop(ctx context.Context, interval time.Duration) {
 
 	f := snapshot.NewFcheap()
 	if !f.Available() {
-		log.Printf("periodic fcheap sweep: fcheap not available, skipping")
+		slog.Info("periodic fcheap sweep skipped: fcheap not available")
 		return
 	}
 
@@ -683,13 +695,13 @@ func (d *Daemon) sweepLoop(ctx context.Context, interval time.Duration) {
 		case <-d.sweepDoneCh:
 			return
 		case <-ticker.C:
-			log.Printf("periodic fcheap sweep started")
+			slog.Info("periodic fcheap sweep started")
 			result, err := f.Sweep(ctx)
 			if err != nil {
-				log.Printf("periodic fcheap sweep failed: %v", err)
+				slog.Warn("periodic fcheap sweep failed", "err", err)
 				continue
 			}
-			log.Printf("periodic fcheap sweep complete: swept %d stashes", result.Swept)
+			slog.Info("periodic fcheap sweep complete", "swept", result.Swept)
 		}
 	}
 }
@@ -699,19 +711,18 @@ func (d *Daemon) sweepLoop(ctx context.Context, interval time.Duration) {
 func (d *Daemon) startLogOffload(ctx context.Context) {
 	interval := parseSweepInterval(d.cfg.Daemon.LogOffloadInterval)
 	if interval <= 0 {
-		log.Printf("daemon: log offload enabled but interval %q is invalid; disabling",
-			d.cfg.Daemon.LogOffloadInterval)
+		slog.Warn("daemon log offload disabled: invalid interval", "interval", d.cfg.Daemon.LogOffloadInterval)
 		return
 	}
 	sink, err := newRotatingSink(d.logPath)
 	if err != nil {
-		log.Printf("daemon: log offload disabled: %v", err)
+		slog.Warn("daemon log offload disabled", "err", err)
 		return
 	}
 	d.logSink = sink
-	log.SetOutput(io.MultiWriter(os.Stderr, sink))
-	log.Printf("daemon: log offload enabled (every %s, ttl %q) → %s",
-		interval, d.cfg.Daemon.LogOffloadTTL, d.logPath)
+	d.logOut = logging.Output()
+	logging.SetOutput(io.MultiWriter(d.logOut, sink))
+	slog.Info("daemon log offload enabled", "every", interval, "ttl", d.cfg.Daemon.LogOffloadTTL, "path", d.logPath)
 
 	go d.logOffloadLoop(ctx, interval)
 }
@@ -722,7 +733,7 @@ func (d *Daemon) logOffloadLoop(ctx context.Context, interval time.Duration) {
 
 	f := snapshot.NewFcheap()
 	if !f.Available() {
-		log.Printf("daemon: log offload: fcheap not available, rotating logs locally only")
+		slog.Info("daemon log offload: fcheap not available, rotating logs locally only")
 	}
 
 	for {
@@ -745,7 +756,7 @@ func (d *Daemon) offloadLog(ctx context.Context, f *snapshot.Fcheap, now time.Ti
 	}
 	rotated, err := d.logSink.Rotate(now)
 	if err != nil {
-		log.Printf("daemon: log rotate failed: %v", err)
+		slog.Warn("daemon log rotate failed", "err", err)
 		return
 	}
 	if rotated == "" {
@@ -757,11 +768,11 @@ func (d *Daemon) offloadLog(ctx context.Context, f *snapshot.Fcheap, now time.Ti
 	name := fmt.Sprintf("vecgrep-hub-log-%s", filepath.Base(rotated))
 	tags := []string{"daemon-log", "hub"}
 	if _, err := f.SaveWithTTL(ctx, rotated, name, "vecgrep-daemon", d.cfg.Daemon.LogOffloadTTL, tags); err != nil {
-		log.Printf("daemon: log offload to fcheap failed (kept %s): %v", rotated, err)
+		slog.Warn("daemon log offload to fcheap failed", "kept", rotated, "err", err)
 		return
 	}
 	if err := os.Remove(rotated); err != nil {
-		log.Printf("daemon: removing offloaded log %s: %v", rotated, err)
+		slog.Warn("daemon could not remove offloaded log", "path", rotated, "err", err)
 	}
 }
 
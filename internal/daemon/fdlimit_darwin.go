@@ -3,7 +3,7 @@
 package daemon
 
 import (
-	"log"
+	"log/slog"
 	"syscall"
 )
 
@@ -14,7 +14,7 @@ import (
 func systemFDCeiling() uint64 {
 	n, err := syscall.SysctlUint32("kern.maxfilesperproc")
 	if err != nil {
-		log.Printf("daemon: read kern.maxfilesperproc: %v", err)
+		slog.Warn("daemon could not read kern.maxfilesperproc", "err", err)
 		return 0
 	}
 	return uint64(n)
@@ -3,7 +3,7 @@
 package daemon
 
 import (
-	"log"
+	"log/slog"
 	"syscall"
 )
 
@@ -22,7 +22,7 @@ const desiredFDLimit = 65536
 func raiseFDLimit() {
 	var rl syscall.Rlimit
 	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
-		log.Printf("daemon: read open-file limit: %v", err)
+		slog.Warn("daemon could not read open-file limit", "err", err)
 		return
 	}
 
@@ -34,10 +34,10 @@ func raiseFDLimit() {
 	prev := rl.Cur
 	rl.Cur = target
 	if err := syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rl); err != nil {
-		log.Printf("daemon: could not raise open-file limit from %d to %d: %v", prev, target, err)
+		slog.Warn("daemon could not raise open-file limit", "from", prev, "to", target, "err", err)
 		return
 	}
-	log.Printf("daemon: raised open-file limit %d → %d", prev, target)
+	slog.Info("daemon raised open-file limit", "from", prev, "to", target)
 }
 
 // fdLimitTarget computes the soft limit to request: desiredFDLimit, clamped down
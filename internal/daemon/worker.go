@@ -3,8 +3,9 @@ package daemon
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
@@ -75,6 +76,10 @@ func newProjectWorker(ctx context.Context, root string) (*projectWorker, error)
 		indexCfg := app.BuildIndexerConfig(cfg, nil)
 		watcherCfg.IgnorePatterns = append([]string(nil), indexCfg.IgnorePatterns...)
 		watcherCfg.MaxFileSize = indexCfg.MaxFileSize
+		watcherCfg.Poll = cfg.Daemon.Poll
+		if cfg.Daemon.PollInterval > 0 {
+			watcherCfg.PollInterval = time.Duration(cfg.Daemon.PollInterval) * time.Millisecond
+		}
 		watcher, werr := index.NewWatcher(session.ProjectRoot, watcherCfg)
 		if werr != nil {
 			_ = session.Close()
@@ -86,7 +91,7 @@ func newProjectWorker(ctx context.Context, root string) (*projectWorker, error)
 			}
 			defer w.endOperation()
 			if _, err := w.service.ApplyWatchEvents(ctx, events); err != nil {
-				log.Printf("daemon: auto-reindex %s failed: %v", w.root(), err)
+				slog.Warn("daemon auto-reindex failed", "root", w.root(), "err", err)
 				return
 			}
 			w.markReindexed()
@@ -95,6 +100,9 @@ func newProjectWorker(ctx context.Context, root string) (*projectWorker, error)
 			_ = session.Close()
 			return nil, fmt.Errorf("start watcher for %s: %w", root, werr)
 		}
+		if mode := watcher.Mode(); mode != "events" {
+			slog.Info("daemon watching project", "root", root, "mode", mode)
+		}
 		w.watcher = watcher
 	}
 
@@ -156,7 +164,7 @@ func (w *projectWorker) reindex(ctx context.Context) {
 	defer w.endOperation()
 	_, err := w.service.Index(ctx, app.IndexRequest{}, nil)
 	if err != nil {
-		log.Printf("daemon: reindex %s failed: %v", w.root(), err)
+		slog.Warn("daemon reindex failed", "root", w.root(), "err", err)
 		return
 	}
 	w.markReindexed()
@@ -182,15 +190,18 @@ func (w *projectWorker) reindexSync(ctx context.Context, req app.IndexRequest) (
 }
 
 // search runs a search against the worker's warm session and returns the
-// results, the resolved mode string, and any degraded-mode warnings.
-func (w *projectWorker) search(ctx context.Context, params searchParams) (any, string, []string, error) {
+// response result: the results, the resolved mode string, any degraded-mode
+// warnings, and whether the search deadline made the results partial.
+func (w *projectWorker) search(ctx context.Context, params searchParams) (map[string]any, error) {
 	if !w.beginOperation() {
-		return nil, "", nil, errWorkerClosing
+		return nil, errWorkerClosing
 	}
 	defer w.endOperation()
 	mode := app.ParseSearchMode(params.Mode, w.cfg.Search.DefaultMode)
 	searcher := search.NewSearcher(w.session.DB, w.session.Provider)
-	outcome, err := searcher.SearchWithOutcome(ctx, params.Query, search.SearchOptions{
+	// Inline filters are stripped before translation so "lang:go" and paths
+	// never reach the translator.
+	query, opts := search.ApplyInlineQuery(params.Query, search.SearchOptions{
 		Limit:        params.Limit,
 		Language:     params.Language,
 		Languages:    params.Languages,
@@ -201,16 +212,57 @@ func (w *projectWorker) search(ctx context.Context, params searchParams) (any, s
 		MinLine:      params.MinLine,
 		MaxLine:      params.MaxLine,
 		MinScore:     params.MinScore,
+		Tags:         params.Tags,
+		EfSearch:     params.EfSearch,
 		FilePaths:    params.FilePaths,
 		ProjectRoot:  w.session.ProjectRoot,
 		Mode:         mode,
 		VectorWeight: w.cfg.Search.VectorWeight,
 		TextWeight:   w.cfg.Search.TextWeight,
+		Fusion:       w.cfg.Search.Fusion,
+		Calibration:  app.CurrentScoreCalibration(w.cfg),
 	})
+	timeout := time.Duration(params.TimeoutMS) * time.Millisecond
+	if timeout == 0 {
+		timeout = w.cfg.Search.Timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		opts.AllowPartial = true
+	}
+	var translationWarning string
+	if mode != search.SearchModeKeyword {
+		translator := search.NewHTTPTranslator(w.cfg.Search.TranslatorURL, w.cfg.Search.TranslatorTimeout)
+		query, _, translationWarning = search.TranslateQuery(ctx, translator, query)
+	}
+	outcome, err := searcher.SearchWithOutcome(ctx, query, opts)
 	if err != nil {
-		return nil, "", nil, err
+		return nil, err
 	}
-	return outcome.Results, string(outcome.Mode), outcome.Warnings, nil
+	results, rerankWarning := app.RerankSearchResults(ctx, w.cfg, params.Rerank, query, outcome.Results)
+	results, feedbackWarning := app.ApplySearchFeedback(w.cfg.DataDir, params.Query, results)
+	warnings := outcome.Warnings
+	partial := outcome.Partial
+	if feedbackWarning != "" {
+		warnings = append(warnings, feedbackWarning)
+	}
+	if translationWarning != "" {
+		warnings = append(warnings, translationWarning)
+	}
+	if rerankWarning != "" {
+		warnings = append(warnings, rerankWarning)
+		partial = partial || (timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded))
+	}
+	result := map[string]any{"results": results, "mode": string(outcome.Mode)}
+	if len(warnings) > 0 {
+		result["warnings"] = warnings
+	}
+	if partial {
+		result["partial"] = true
+	}
+	return result, nil
 }
 
 // stats returns index statistics for the worker's project.
@@ -2,10 +2,17 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path"
+	"strings"
 	"time"
 )
 
+// ErrFileNotIndexed is returned by DeleteProjectFile when no indexed chunk
+// or file record matched the path.
+var ErrFileNotIndexed = errors.New("file is not indexed")
+
 // DB wraps the veclite database with vecgrep-specific functionality.
 // All data is stored in veclite - no SQLite needed.
 type DB struct {
@@ -31,6 +38,38 @@ type OpenOptions struct {
 	// SharedRead allows multiple processes to open the same database file
 	// simultaneously for read-only access. Requires ReadOnly to be true.
 	SharedRead bool
+
+	// Quantization stores chunk vectors as int8 or binary codes, keeping the
+	// full-precision vectors in FullVectorsPath to re-score the top
+	// candidates. It must match how the existing vectors were written.
+	Quantization Quantization
+
+	// Qdrant, when set, keeps chunks (vectors and payloads) in a Qdrant
+	// collection and serves vector search from it, so clients sharing the
+	// collection search the same index. VecLite still keeps the keyword
+	// index and the file hashes of incremental indexing. It cannot be
+	// combined with Quantization.
+	Qdrant *QdrantConfig
+
+	// Pgvector, when set, keeps chunks in a Postgres table with the pgvector
+	// extension instead, with the same split and restriction.
+	Pgvector *PgvectorConfig
+
+	// ProjectRoot is the project root that paths in a Qdrant or pgvector
+	// store are relative to, so clients with the project checked out in
+	// different directories share its chunks.
+	ProjectRoot string
+
+	// Encryption, when set, keeps the index files sealed with AES-256-GCM
+	// in DataDir and works on an unsealed copy while the database is open.
+	Encryption *EncryptionConfig
+
+	// HotWindow, when positive, keeps the chunks indexed within it in a hot
+	// tier that vector search scores exhaustively and merges with the
+	// approximate index, at most HotMaxChunks of them (default
+	// DefaultHotMaxChunks), newest first.
+	HotWindow    time.Duration
+	HotMaxChunks int
 }
 
 // Default HNSW parameters used when config does not override them.
@@ -66,8 +105,47 @@ func OpenWithOptions(opts OpenOptions) (*DB, error) {
 		opts.HNSWEfSearch = DefaultHNSWEfSearch
 	}
 
+	quantization, err := ParseQuantization(string(opts.Quantization))
+	if err != nil {
+		return nil, err
+	}
+
 	// Create veclite backend
 	backend := NewVecLiteBackend(VecLitePath(opts.DataDir))
+	backend.quantization = quantization
+	backend.hot = newHotTier(opts.HotWindow, opts.HotMaxChunks)
+	if opts.Encryption != nil {
+		key, err := opts.Encryption.Key()
+		if err != nil {
+			return nil, fmt.Errorf("encryption key: %w", err)
+		}
+		sealed, err := openSealedFiles(opts.DataDir, key, opts.ReadOnly)
+		if err != nil {
+			return nil, err
+		}
+		if sealed != nil {
+			backend.sealed = sealed
+			backend.dbPath = sealed.dbPath()
+		}
+	} else if fileExists(SealedPath(VecLitePath(opts.DataDir))) && !fileExists(VecLitePath(opts.DataDir)) {
+		return nil, ErrIndexEncrypted
+	}
+	if opts.Qdrant != nil {
+		if quantization != QuantizationNone {
+			return nil, fmt.Errorf("quantization %s cannot be combined with the qdrant vector backend", quantization)
+		}
+		backend.vectors = NewQdrantBackend(*opts.Qdrant)
+	}
+	if opts.Pgvector != nil {
+		if quantization != QuantizationNone {
+			return nil, fmt.Errorf("quantization %s cannot be combined with the pgvector vector backend", quantization)
+		}
+		if opts.Qdrant != nil {
+			return nil, fmt.Errorf("the qdrant and pgvector vector backends are mutually exclusive")
+		}
+		backend.vectors = NewPgvectorBackend(*opts.Pgvector)
+	}
+	backend.root = opts.ProjectRoot
 
 	// Initialize backend with HNSW config and access mode
 	if err := backend.InitWithOptions(opts.Dimensions, HNSWConfig{
@@ -75,6 +153,9 @@ func OpenWithOptions(opts OpenOptions) (*DB, error) {
 		EfConstruction: opts.HNSWEfConstruction,
 		EfSearch:       opts.HNSWEfSearch,
 	}, opts.ReadOnly, opts.SharedRead); err != nil {
+		if backend.sealed != nil {
+			_ = backend.Close()
+		}
 		return nil, fmt.Errorf("failed to initialize veclite: %w", err)
 	}
 
@@ -107,6 +188,49 @@ func (db *DB) DeleteCollectionMetadataValue(key string) error {
 	return db.backend.DeleteMetadataValue(key)
 }
 
+// Collection metadata keys naming the model that embedded the stored vectors.
+const (
+	indexedModelMetaKey      = "embedding_model"
+	indexedDimensionsMetaKey = "embedding_dimensions"
+)
+
+// IndexedEmbedding names the model that embedded a database's vectors and
+// their dimensions, so a search can refuse query vectors from another model.
+type IndexedEmbedding struct {
+	Model      string
+	Dimensions int
+}
+
+// SetIndexedEmbedding records the model and dimensions of the vectors just
+// stored. It is persisted by the next Sync.
+func (db *DB) SetIndexedEmbedding(embedding IndexedEmbedding) error {
+	if err := db.backend.SetMetadataValue(indexedModelMetaKey, embedding.Model); err != nil {
+		return err
+	}
+	return db.backend.SetMetadataValue(indexedDimensionsMetaKey, embedding.Dimensions)
+}
+
+// IndexedEmbedding returns the model and dimensions recorded at index time.
+// ok is false for an empty database or one indexed before they were recorded.
+func (db *DB) IndexedEmbedding() (embedding IndexedEmbedding, ok bool) {
+	model, _ := db.backend.MetadataValue(indexedModelMetaKey)
+	dimensions, _ := db.backend.MetadataValue(indexedDimensionsMetaKey)
+	embedding.Model, _ = model.(string)
+	// Metadata round-trips through gob, so accept any integer width.
+	switch d := dimensions.(type) {
+	case int:
+		embedding.Dimensions = d
+	case int64:
+		embedding.Dimensions = int(d)
+	case float64:
+		embedding.Dimensions = int(d)
+	}
+	if embedding.Model == "" || embedding.Dimensions <= 0 {
+		return IndexedEmbedding{}, false
+	}
+	return embedding, true
+}
+
 // InsertChunk inserts a chunk with all its metadata and embedding.
 func (db *DB) InsertChunk(chunk ChunkRecord, embedding []float32) (uint64, error) {
 	return db.backend.InsertChunk(chunk, embedding)
@@ -124,6 +248,13 @@ func (db *DB) UpsertChunk(chunk ChunkRecord, embedding []float32) (uint64, bool,
 	return db.backend.UpsertChunk(chunk, embedding)
 }
 
+// UpsertChunkBatch writes chunks keyed by their stable chunk key in one
+// backend call, inserting new keys and updating existing ones in place. With
+// replaceFiles set, each touched file's chunks outside the batch are removed.
+func (db *DB) UpsertChunkBatch(chunks []ChunkRecord, embeddings [][]float32, replaceFiles bool) (UpsertBatchResult, error) {
+	return db.backend.UpsertChunkBatch(chunks, embeddings, replaceFiles)
+}
+
 // InsertEmbedding inserts an embedding (legacy compatibility).
 // Deprecated: Use InsertChunk for full metadata storage.
 func (db *DB) InsertEmbedding(chunkID int64, embedding []float32) error {
@@ -163,8 +294,17 @@ func (db *DB) HybridSearch(queryEmbedding []float32, textQuery string, limit int
 	return db.backend.HybridSearch(queryEmbedding, textQuery, limit, opts, vectorWeight, textWeight)
 }
 
+// HybridSearchWithFusion performs hybrid search with an explicit fusion
+// strategy (FusionWeighted or FusionRRF).
+func (db *DB) HybridSearchWithFusion(queryEmbedding []float32, textQuery string, limit int, opts FilterOptions, vectorWeight, textWeight float32, fusion string) ([]SearchResult, error) {
+	return db.backend.HybridSearchWithFusion(queryEmbedding, textQuery, limit, opts, vectorWeight, textWeight, fusion)
+}
+
 // VecVersion returns the vector backend version info.
 func (db *DB) VecVersion() (string, error) {
+	if location := db.backend.VectorLocation(); location != "" {
+		return fmt.Sprintf("%s (%s)", db.backend.Type(), location), nil
+	}
 	return db.backend.Type(), nil
 }
 
@@ -178,6 +318,17 @@ func (db *DB) GetChunkByLocation(filePath string, line int) (*ChunkRecord, error
 	return db.backend.GetChunkByLocation(filePath, line)
 }
 
+// GetChunkParent returns the chunk enclosing chunkID, or nil for a
+// top-level chunk.
+func (db *DB) GetChunkParent(chunkID int64) (*ChunkRecord, error) {
+	return db.backend.GetChunkParent(chunkID)
+}
+
+// GetChunkChildren returns the chunks directly enclosed by chunkID.
+func (db *DB) GetChunkChildren(chunkID int64) ([]ChunkRecord, error) {
+	return db.backend.GetChunkChildren(chunkID)
+}
+
 // GetChunksByFile returns all chunks for a specific file.
 func (db *DB) GetChunksByFile(filePath string) ([]ChunkRecord, error) {
 	return db.backend.GetChunksByFile(filePath)
@@ -191,8 +342,75 @@ func (db *DB) DeleteFile(ctx context.Context, filePath string) (int64, error) {
 // DeleteProjectFile removes one file only from the named project's index.
 // Use this for every project-aware surface; DeleteFile remains solely for
 // compatibility with legacy callers that do not carry project identity.
+// filePath may be project-relative ("./internal/x.go"), absolute, or use
+// Windows separators; see ProjectRelativePath. It returns ErrFileNotIndexed
+// when nothing matched.
 func (db *DB) DeleteProjectFile(ctx context.Context, projectRoot, filePath string) (int64, error) {
-	return db.backend.DeleteByProjectFile(projectRoot, filePath)
+	relPath, err := ProjectRelativePath(projectRoot, filePath)
+	if err != nil {
+		return 0, err
+	}
+	deleted, matched, err := db.backend.DeleteByProjectFile(projectRoot, relPath)
+	if err != nil {
+		return deleted, err
+	}
+	if !matched {
+		return 0, fmt.Errorf("%w: %s", ErrFileNotIndexed, relPath)
+	}
+	return deleted, nil
+}
+
+// CountProjectFile returns the number of chunks DeleteProjectFile would
+// remove for filePath, without modifying the index. Like DeleteProjectFile it
+// returns ErrFileNotIndexed when nothing matched.
+func (db *DB) CountProjectFile(ctx context.Context, projectRoot, filePath string) (int64, error) {
+	relPath, err := ProjectRelativePath(projectRoot, filePath)
+	if err != nil {
+		return 0, err
+	}
+	chunks, matched, err := db.backend.CountByProjectFile(projectRoot, relPath)
+	if err != nil {
+		return 0, err
+	}
+	if !matched {
+		return 0, fmt.Errorf("%w: %s", ErrFileNotIndexed, relPath)
+	}
+	return chunks, nil
+}
+
+// ProjectRelativePath normalizes a user-supplied file path to the canonical
+// project-relative slash path stored in relative_path: backslashes become
+// slashes, "./" and ".." elements are cleaned, and an absolute path under
+// projectRoot is made relative to it. Paths outside the project are rejected.
+func ProjectRelativePath(projectRoot, filePath string) (string, error) {
+	p := strings.ReplaceAll(strings.TrimSpace(filePath), `\`, "/")
+	if p == "" {
+		return "", fmt.Errorf("file path is required")
+	}
+	if path.IsAbs(p) || hasDriveLetter(p) {
+		root := path.Clean(strings.ReplaceAll(projectRoot, `\`, "/"))
+		cleaned := path.Clean(p)
+		rel, ok := strings.CutPrefix(cleaned, root+"/")
+		if !ok && hasDriveLetter(p) && len(cleaned) > len(root)+1 && strings.EqualFold(cleaned[:len(root)+1], root+"/") {
+			// Windows paths are case-insensitive.
+			rel, ok = cleaned[len(root)+1:], true
+		}
+		if !ok || rel == "" {
+			return "", fmt.Errorf("%s is outside the project root %s", filePath, projectRoot)
+		}
+		return rel, nil
+	}
+	rel := path.Clean(p)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%s is outside the project root %s", filePath, projectRoot)
+	}
+	return rel, nil
+}
+
+// hasDriveLetter reports whether p starts with a Windows drive such as "C:/".
+func hasDriveLetter(p string) bool {
+	return len(p) >= 3 && p[1] == ':' && p[2] == '/' &&
+		(('a' <= p[0] && p[0] <= 'z') || ('A' <= p[0] && p[0] <= 'Z'))
 }
 
 // GetFileHashes returns file hashes for incremental indexing.
@@ -221,6 +439,12 @@ func (db *DB) ListFiles(projectRoot string) ([]FileInfo, error) {
 	return db.backend.ListFiles(projectRoot)
 }
 
+// FindSymbols lists indexed chunks whose symbol name matches pattern, which is
+// case-insensitive and may use glob wildcards.
+func (db *DB) FindSymbols(pattern string, limit int, opts FilterOptions) ([]SymbolRecord, error) {
+	return db.backend.FindSymbols(pattern, limit, opts)
+}
+
 // CleanStats contains statistics from a clean operation.
 //
 // With pure veclite storage there is no separate orphan/embedding table to
@@ -271,6 +495,20 @@ func (db *DB) Clean(ctx context.Context) (*CleanStats, error) {
 	}, nil
 }
 
+// CleanPreview reports what Clean would do without flushing: the current
+// record and file counts, with Synced false. Clean removes nothing with
+// veclite-only storage, so the preview's legacy orphan fields are zero too.
+func (db *DB) CleanPreview(ctx context.Context) (*CleanStats, error) {
+	stats, err := db.Stats()
+	if err != nil {
+		return nil, fmt.Errorf("stats: %w", err)
+	}
+	return &CleanStats{
+		TotalRecords: stats["embeddings"],
+		TotalFiles:   stats["files"],
+	}, nil
+}
+
 // Reset clears all data for a project.
 func (db *DB) Reset(ctx context.Context, projectRoot string) error {
 	if projectRoot == "" {
@@ -348,6 +586,11 @@ func (db *DB) Dimensions() int {
 	return db.dimensions
 }
 
+// Quantization returns how the database stores chunk vectors.
+func (db *DB) Quantization() Quantization {
+	return db.backend.Quantization()
+}
+
 // DataDir returns the data directory path.
 func (db *DB) DataDir() string {
 	return db.dataDir
@@ -377,6 +620,7 @@ func NewChunkRecord(
 		ChunkType:    chunkType,
 		SymbolName:   symbolName,
 		ProjectRoot:  projectRoot,
+		ParentIndex:  -1,
 		IndexedAt:    time.Now(),
 	}
 }
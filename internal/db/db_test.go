@@ -1,6 +1,10 @@
 package db
 
 import (
+	"errors"
+	"slices"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -269,6 +273,45 @@ func TestStats(t *testing.T) {
 	}
 }
 
+func TestGetDetailedStatsLanguageVolume(t *testing.T) {
+	tmpDir := t.TempDir()
+	dimensions := 8
+
+	db, err := Open(tmpDir+"/test.db", dimensions, tmpDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	embedding := make([]float32, dimensions)
+	chunks := []ChunkRecord{
+		// Two chunks of one Go file count the file once, up to its last line.
+		NewChunkRecord("/tmp/test/main.go", "main.go", "h1", 1200, "go", "func a() {}", 1, 40, 0, 600, "function", "a", "/tmp/test"),
+		NewChunkRecord("/tmp/test/main.go", "main.go", "h1", 1200, "go", "func b() {}", 30, 80, 500, 1200, "function", "b", "/tmp/test"),
+		NewChunkRecord("/tmp/test/util.go", "util.go", "h2", 300, "go", "func c() {}", 1, 20, 0, 300, "function", "c", "/tmp/test"),
+		NewChunkRecord("/tmp/test/README.md", "README.md", "h3", 5000, "markdown", "# Title", 1, 150, 0, 5000, "section", "Title", "/tmp/test"),
+	}
+	for _, chunk := range chunks {
+		if _, err := db.InsertChunk(chunk, embedding); err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+	}
+
+	stats, err := db.GetDetailedStats("/tmp/test")
+	if err != nil {
+		t.Fatalf("GetDetailedStats failed: %v", err)
+	}
+	if stats.Languages["go"] != 3 || stats.Languages["markdown"] != 1 {
+		t.Errorf("chunk counts = %v, want go 3, markdown 1", stats.Languages)
+	}
+	if stats.LanguageLines["go"] != 100 || stats.LanguageLines["markdown"] != 150 {
+		t.Errorf("line totals = %v, want go 100, markdown 150", stats.LanguageLines)
+	}
+	if stats.LanguageBytes["go"] != 1500 || stats.LanguageBytes["markdown"] != 5000 {
+		t.Errorf("byte totals = %v, want go 1500, markdown 5000", stats.LanguageBytes)
+	}
+}
+
 func TestGetFileHashes(t *testing.T) {
 	tmpDir := t.TempDir()
 	dimensions := 768
@@ -486,6 +529,160 @@ func TestSearchWithFilePathsFilter(t *testing.T) {
 	}
 }
 
+func TestFindSymbolsAndSymbolFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	dimensions := 768
+
+	db, err := Open(tmpDir+"/test.db", dimensions, tmpDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	embedding := make([]float32, dimensions)
+	symbols := []struct{ rel, symbol, root string }{
+		{"search.go", "NewSearcher", "/tmp/test"},
+		{"search.go", "Searcher.Search", "/tmp/test"},
+		{"retry.go", "withRetry", "/tmp/test"},
+		{"main.go", "", "/tmp/test"},
+		{"search.go", "NewSearcher", "/tmp/other"},
+	}
+	for i, s := range symbols {
+		chunk := NewChunkRecord(
+			s.root+"/"+s.rel,
+			s.rel,
+			"hash",
+			100,
+			"go",
+			"content",
+			i*10+1, i*10+10, 0, 20,
+			"function",
+			s.symbol,
+			s.root,
+		)
+		if _, err := db.InsertChunk(chunk, embedding); err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+	}
+
+	names := func(records []SymbolRecord) []string {
+		var out []string
+		for _, r := range records {
+			out = append(out, r.Name)
+		}
+		return out
+	}
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"*search*", []string{"NewSearcher", "Searcher.Search"}},
+		{"search", []string{"Searcher.Search"}},
+		{"new*", []string{"NewSearcher"}},
+		{"*RETRY", []string{"withRetry"}},
+	}
+	for _, tt := range tests {
+		got, err := db.FindSymbols(tt.pattern, 0, FilterOptions{ProjectRoot: "/tmp/test"})
+		if err != nil {
+			t.Fatalf("FindSymbols(%q): %v", tt.pattern, err)
+		}
+		if !slices.Equal(names(got), tt.want) {
+			t.Errorf("FindSymbols(%q) = %v, want %v", tt.pattern, names(got), tt.want)
+		}
+	}
+
+	results, err := db.SearchWithFilter(embedding, 10, FilterOptions{ProjectRoot: "/tmp/test", Symbol: "newsearcher"})
+	if err != nil {
+		t.Fatalf("SearchWithFilter failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Chunk.SymbolName != "NewSearcher" || results[0].Chunk.ProjectRoot != "/tmp/test" {
+		t.Fatalf("symbol filter returned %d results, want NewSearcher in /tmp/test", len(results))
+	}
+}
+
+func TestTagFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	dimensions := 8
+
+	db, err := Open(tmpDir+"/test.db", dimensions, tmpDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	embedding := make([]float32, dimensions)
+	embedding[0] = 1
+	files := []struct {
+		rel  string
+		tags []string
+	}{
+		{"internal/api/handler.go", []string{"api"}},
+		{"deploy/main.tf", []string{"api", "infra"}},
+		{"web/app.tsx", []string{"frontend"}},
+		{"README.md", nil},
+	}
+	for i, f := range files {
+		chunk := NewChunkRecord("/tmp/test/"+f.rel, f.rel, "hash", 100, "go", "content", i+1, i+1, 0, 7, "block", "", "/tmp/test")
+		chunk.Tags = f.tags
+		if _, err := db.InsertChunk(chunk, embedding); err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+	}
+
+	paths := func(tags ...string) []string {
+		results, err := db.SearchWithFilter(embedding, 10, FilterOptions{ProjectRoot: "/tmp/test", Tags: tags})
+		if err != nil {
+			t.Fatalf("SearchWithFilter(%v): %v", tags, err)
+		}
+		var out []string
+		for _, r := range results {
+			out = append(out, r.Chunk.RelativePath)
+		}
+		slices.Sort(out)
+		return out
+	}
+	if got, want := paths("API"), []string{"deploy/main.tf", "internal/api/handler.go"}; !slices.Equal(got, want) {
+		t.Errorf("tag api = %v, want %v", got, want)
+	}
+	if got, want := paths("infra", "frontend"), []string{"deploy/main.tf", "web/app.tsx"}; !slices.Equal(got, want) {
+		t.Errorf("tags infra,frontend = %v, want %v", got, want)
+	}
+	if got := paths("backend"); len(got) != 0 {
+		t.Errorf("unknown tag = %v, want no results", got)
+	}
+}
+
+func TestExcludeFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	dimensions := 8
+
+	db, err := Open(tmpDir+"/test.db", dimensions, tmpDir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer db.Close()
+
+	embedding := make([]float32, dimensions)
+	embedding[0] = 1
+	var ids []int64
+	for i, rel := range []string{"a.go", "a.go", "b.go", "c.go"} {
+		chunk := NewChunkRecord("/tmp/test/"+rel, rel, "hash", 100, "go", "content", i+1, i+1, 0, 7, "block", "", "/tmp/test")
+		id, err := db.InsertChunk(chunk, embedding)
+		if err != nil {
+			t.Fatalf("InsertChunk failed: %v", err)
+		}
+		ids = append(ids, int64(id))
+	}
+
+	results, err := db.SearchWithFilter(embedding, 2, FilterOptions{ProjectRoot: "/tmp/test", ExcludeIDs: []int64{ids[2]}, ExcludePaths: []string{"a.go"}})
+	if err != nil {
+		t.Fatalf("SearchWithFilter: %v", err)
+	}
+	if len(results) != 1 || results[0].ChunkID != ids[3] {
+		t.Fatalf("results = %+v, want only chunk %d (c.go)", results, ids[3])
+	}
+}
+
 func TestNewChunkRecord(t *testing.T) {
 	before := time.Now()
 	chunk := NewChunkRecord(
@@ -511,6 +708,62 @@ func TestNewChunkRecord(t *testing.T) {
 	if chunk.IndexedAt.Before(before) || chunk.IndexedAt.After(after) {
 		t.Errorf("IndexedAt should be between test start and end")
 	}
+	if chunk.ParentIndex != -1 {
+		t.Errorf("ParentIndex = %d, want -1 (top-level)", chunk.ParentIndex)
+	}
+}
+
+func TestGetChunkParentAndChildren(t *testing.T) {
+	const dimensions = 32
+	const projectRoot = "/tmp/tree-project"
+	database, err := Open("", dimensions, t.TempDir())
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer database.Close()
+
+	// impl Server (0) > fn start (1), fn stop (2); fn stop's second piece (3).
+	spans := []struct {
+		start, end, parent int
+		chunkType, symbol  string
+	}{
+		{1, 20, -1, "class", "Server"},
+		{2, 8, 0, "function", "start"},
+		{9, 15, 0, "function", "stop"},
+		{15, 19, 2, "function", "stop"},
+	}
+	ids := make([]int64, len(spans))
+	for i, span := range spans {
+		chunk := NewChunkRecord(
+			projectRoot+"/server.rs", "server.rs", "hash", 100, "rust", "content",
+			span.start, span.end, span.start*10, span.end*10, span.chunkType, span.symbol, projectRoot,
+		)
+		chunk.ChunkIndex = i
+		chunk.ParentIndex = span.parent
+		id, err := database.InsertChunk(chunk, make([]float32, dimensions))
+		if err != nil {
+			t.Fatalf("InsertChunk(%d) failed: %v", i, err)
+		}
+		ids[i] = int64(id)
+	}
+
+	if parent, err := database.GetChunkParent(ids[0]); err != nil || parent != nil {
+		t.Fatalf("GetChunkParent(top-level) = %+v, %v; want nil", parent, err)
+	}
+	parent, err := database.GetChunkParent(ids[3])
+	if err != nil || parent == nil || parent.ChunkIndex != 2 {
+		t.Fatalf("GetChunkParent(split piece) = %+v, %v; want chunk 2", parent, err)
+	}
+	children, err := database.GetChunkChildren(ids[0])
+	if err != nil {
+		t.Fatalf("GetChunkChildren failed: %v", err)
+	}
+	if len(children) != 2 || children[0].SymbolName != "start" || children[1].ChunkIndex != 2 {
+		t.Fatalf("GetChunkChildren(impl) = %+v, want start and stop", children)
+	}
+	if leaves, err := database.GetChunkChildren(ids[1]); err != nil || len(leaves) != 0 {
+		t.Fatalf("GetChunkChildren(leaf) = %+v, %v; want none", leaves, err)
+	}
 }
 
 func TestFileHashesRemainCorrectAfterDeleteAndReopen(t *testing.T) {
@@ -689,6 +942,121 @@ func TestDeleteProjectFileDoesNotCrossProjectBoundary(t *testing.T) {
 	}
 }
 
+func TestDeleteProjectFileNormalizesPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	const dimensions = 16
+	const root = "/projects/alpha"
+	database, err := Open("", dimensions, tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	paths := []string{"internal/search/search.go", "internal/db/db.go", "cmd/main.go"}
+	for _, path := range paths {
+		chunk := NewChunkRecord(root+"/"+path, path, "hash-"+path, 10, "go", "package p", 1, 1, 0, 9, "generic", "", root)
+		if _, err := database.InsertChunk(chunk, make([]float32, dimensions)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, input := range []string{"./internal/search/search.go", `internal\db\db.go`, root + "/cmd/main.go"} {
+		if deleted, err := database.DeleteProjectFile(t.Context(), root, input); err != nil {
+			t.Fatalf("DeleteProjectFile(%q): %v", input, err)
+		} else if deleted != 1 {
+			t.Fatalf("DeleteProjectFile(%q) deleted %d chunks, want 1", input, deleted)
+		}
+	}
+	if hashes, _ := database.GetFileHashes(root); len(hashes) != 0 {
+		t.Fatalf("hashes remain after delete: %v", hashes)
+	}
+
+	// Legacy records carry only the absolute file_path.
+	if _, err := database.backend.collection().Insert(make([]float32, dimensions), map[string]any{
+		"file_path":    root + "/legacy.go",
+		"file_hash":    "legacy-hash",
+		"project_root": root,
+	}); err != nil {
+		t.Fatalf("insert legacy record failed: %v", err)
+	}
+	if deleted, err := database.DeleteProjectFile(t.Context(), root, "./legacy.go"); err != nil || deleted != 1 {
+		t.Fatalf("legacy DeleteProjectFile = %d, %v; want 1", deleted, err)
+	}
+
+	if _, err := database.DeleteProjectFile(t.Context(), root, "internal/search/search.go"); !errors.Is(err, ErrFileNotIndexed) {
+		t.Fatalf("deleting a missing file: err = %v, want ErrFileNotIndexed", err)
+	}
+	if _, err := database.DeleteProjectFile(t.Context(), root, "../beta/main.go"); err == nil || !strings.Contains(err.Error(), "outside the project root") {
+		t.Fatalf("deleting outside the project: err = %v", err)
+	}
+}
+
+func TestCountProjectFileLeavesIndexUnchanged(t *testing.T) {
+	tmpDir := t.TempDir()
+	const dimensions = 16
+	const root = "/projects/alpha"
+	database, err := Open("", dimensions, tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	for i := range 2 {
+		chunk := NewChunkRecord(root+"/main.go", "main.go", "hash-main", 10, "go", "package p", 1, 1, i*5, i*5+4, "generic", "", root)
+		if _, err := database.InsertChunk(chunk, make([]float32, dimensions)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := database.backend.collection().Insert(make([]float32, dimensions), map[string]any{
+		"file_path":    root + "/legacy.go",
+		"file_hash":    "legacy-hash",
+		"project_root": root,
+	}); err != nil {
+		t.Fatalf("insert legacy record failed: %v", err)
+	}
+
+	if chunks, err := database.CountProjectFile(t.Context(), root, "./main.go"); err != nil || chunks != 2 {
+		t.Fatalf("CountProjectFile(main.go) = %d, %v; want 2", chunks, err)
+	}
+	if chunks, err := database.CountProjectFile(t.Context(), root, "legacy.go"); err != nil || chunks != 1 {
+		t.Fatalf("CountProjectFile(legacy.go) = %d, %v; want 1", chunks, err)
+	}
+	if _, err := database.CountProjectFile(t.Context(), "/projects/beta", "main.go"); !errors.Is(err, ErrFileNotIndexed) {
+		t.Fatalf("counting another project's file: err = %v, want ErrFileNotIndexed", err)
+	}
+	if chunks, _ := database.GetChunksByFile("main.go"); len(chunks) != 2 {
+		t.Fatalf("chunks after count = %d, want 2", len(chunks))
+	}
+	if deleted, err := database.DeleteProjectFile(t.Context(), root, "main.go"); err != nil || deleted != 2 {
+		t.Fatalf("DeleteProjectFile after count = %d, %v; want 2", deleted, err)
+	}
+}
+
+func TestProjectRelativePath(t *testing.T) {
+	tests := []struct {
+		root, input, want string
+		wantErr           bool
+	}{
+		{"/repo", "main.go", "main.go", false},
+		{"/repo", "./internal/search/search.go", "internal/search/search.go", false},
+		{"/repo", `internal\search\search.go`, "internal/search/search.go", false},
+		{"/repo", "/repo/internal/x.go", "internal/x.go", false},
+		{"/repo", "internal/../cmd/main.go", "cmd/main.go", false},
+		{`C:\work\repo`, `C:\work\repo\pkg\a.go`, "pkg/a.go", false},
+		{`C:\work\repo`, `c:\Work\Repo\pkg\a.go`, "pkg/a.go", false},
+		{"/repo", "/other/main.go", "", true},
+		{"/repo", "../main.go", "", true},
+		{"/repo", "/repo", "", true},
+		{"/repo", " ", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ProjectRelativePath(tt.root, tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ProjectRelativePath(%q, %q) = %q, %v; want %q, err=%v", tt.root, tt.input, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
 func TestChunkIdentitySeparatesPartsAndProjects(t *testing.T) {
 	tmpDir := t.TempDir()
 	const dimensions = 8
@@ -734,3 +1102,98 @@ func TestChunkIdentitySeparatesPartsAndProjects(t *testing.T) {
 		t.Fatalf("chunk count after upsert = %d, want 3", got)
 	}
 }
+
+func TestUpsertChunkBatchDedupesAndReplacesFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	const dimensions = 4
+	database, err := Open("", dimensions, tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	chunk := func(index int, content string) ChunkRecord {
+		record := NewChunkRecord("/repo/a.go", "a.go", "v1", 10, "go", content, index+1, index+1, index*10, index*10+9, "function", "", "/repo")
+		record.ChunkIndex = index
+		return record
+	}
+	vec := func(x float32) []float32 { return []float32{x, 0, 0, 0} }
+
+	first, err := database.UpsertChunkBatch(
+		[]ChunkRecord{chunk(0, "a"), chunk(1, "b"), chunk(2, "c"), chunk(1, "b2")},
+		[][]float32{vec(1), vec(2), vec(3), vec(4)},
+		true,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Inserted != 3 || len(first.IDs) != 4 || first.IDs[1] != first.IDs[3] {
+		t.Fatalf("first upsert = %+v, want 3 inserts with duplicate keys sharing an ID", first)
+	}
+	if got, _ := database.backend.collection().Get(first.IDs[1]); getStringPayload(got.Payload, "content") != "b2" {
+		t.Fatalf("duplicate key kept %q, want the last occurrence", getStringPayload(got.Payload, "content"))
+	}
+
+	// Re-index the file: chunk 0 is unchanged, chunk 1 has a new vector, chunk
+	// 2 is gone, and chunk 3 is new.
+	updated := []ChunkRecord{chunk(0, "a"), chunk(1, "b3"), chunk(3, "d")}
+	for i := range updated {
+		updated[i].FileHash = "v2"
+	}
+	second, err := database.UpsertChunkBatch(updated, [][]float32{vec(1), vec(5), vec(6)}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Unchanged != 1 || second.Updated != 1 || second.Inserted != 1 || second.Pruned != 1 {
+		t.Fatalf("second upsert = %+v, want 1 unchanged, 1 updated, 1 inserted, 1 pruned", second)
+	}
+	if second.IDs[0] != first.IDs[0] || second.IDs[1] != first.IDs[1] {
+		t.Fatalf("existing keys changed IDs: first=%v second=%v", first.IDs, second.IDs)
+	}
+	if got := database.backend.collection().Count(); got != 3 {
+		t.Fatalf("chunk count = %d, want 3", got)
+	}
+	hashes, err := database.GetFileHashes("/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashes["a.go"] != "v2" {
+		t.Fatalf("file hash = %q, want v2", hashes["a.go"])
+	}
+}
+
+func TestSearchWithFilterDirectoriesMatchesAnyPrefix(t *testing.T) {
+	tmpDir := t.TempDir()
+	const dimensions = 4
+	database, err := Open("", dimensions, tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	var chunks []ChunkRecord
+	var vectors [][]float32
+	for _, rel := range []string{"internal/search/a.go", "internal/embed/b.go", "cmd/c.go", "internal/searcher/d.go"} {
+		chunks = append(chunks, NewChunkRecord("/repo/"+rel, rel, "h", 10, "go", rel, 1, 1, 0, 9, "function", "", "/repo"))
+		vectors = append(vectors, []float32{1, 0, 0, 0})
+	}
+	if _, err := database.InsertChunkBatch(chunks, vectors); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := database.SearchWithFilter([]float32{1, 0, 0, 0}, 10, FilterOptions{
+		ProjectRoot: "/repo",
+		Directories: []string{"internal/search", "cmd/"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Chunk.RelativePath)
+	}
+	sort.Strings(got)
+	if strings.Join(got, ",") != "cmd/c.go,internal/search/a.go" {
+		t.Fatalf("results = %v, want only files under the listed directories", got)
+	}
+}
@@ -0,0 +1,396 @@
+package db
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync/atomic"
+)
+
+// EncryptionConfig keeps the index files encrypted at rest. VecLite reads and
+// writes plain files, so an encrypted index is sealed in the data directory
+// and unsealed into a private runtime directory (tmpfs where available)
+// while it is open. Writers seal it again on every Sync and on Close.
+type EncryptionConfig struct {
+	// Key returns the 32-byte AES-256 key. It is called once per open.
+	Key func() ([]byte, error)
+}
+
+// ErrIndexEncrypted is returned when an encrypted index is opened without
+// an encryption key.
+var ErrIndexEncrypted = errors.New("the index is encrypted; set security.encrypt: true and provide its key")
+
+const (
+	sealedSuffix = ".sealed"
+	sealMagic    = "VGSEAL1\n"
+	// sealSegment is the plaintext size of one sealed segment.
+	sealSegment = 1 << 20
+)
+
+// readerSeq numbers the private working copies of read-only opens within
+// one process.
+var readerSeq atomic.Uint64
+
+// SealedPath returns where an encrypted index keeps the sealed copy of an
+// index file.
+func SealedPath(path string) string {
+	return path + sealedSuffix
+}
+
+// AtRestPath returns the file that holds an index file at rest: its sealed
+// copy when the index is encrypted, otherwise path itself. Use it to check
+// whether an index file exists, how large it is, and when it changed.
+func AtRestPath(path string) string {
+	if sealed := SealedPath(path); fileExists(sealed) {
+		return sealed
+	}
+	return path
+}
+
+// sealedFiles maps an encrypted index's sealed copies in the data directory
+// to plaintext working copies. Writers share one working directory per data
+// directory, so VecLite's lock still keeps them exclusive and a crashed
+// writer's write-ahead log is replayed by the next one. Readers each get a
+// private copy, refreshed by unseal.
+type sealedFiles struct {
+	aead     cipher.AEAD
+	dataDir  string
+	workDir  string
+	readOnly bool
+}
+
+// sealedFileNames are the index files sealed at rest.
+var sealedFileNames = []string{filepath.Base(VecLitePath("")), filepath.Base(FullVectorsPath(""))}
+
+// openSealedFiles prepares the working copies of an encrypted index. A
+// writer adopts an unencrypted index left in the data directory and seals it
+// immediately. A reader of an index that has not been sealed yet gets nil
+// and reads the plain files until the next write seals them.
+func openSealedFiles(dataDir string, key []byte, readOnly bool) (*sealedFiles, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	sealedExists := fileExists(SealedPath(VecLitePath(dataDir)))
+	if readOnly && !sealedExists {
+		return nil, nil
+	}
+
+	base, err := encryptedWorkBase()
+	if err != nil {
+		return nil, err
+	}
+	abs, err := filepath.Abs(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256([]byte(abs))
+	workDir := filepath.Join(base, hex.EncodeToString(sum[:8]), "writer")
+	if readOnly {
+		workDir = filepath.Join(filepath.Dir(workDir), fmt.Sprintf("read-%d-%d", os.Getpid(), readerSeq.Add(1)))
+	}
+	if err := os.MkdirAll(workDir, 0o700); err != nil {
+		return nil, fmt.Errorf("create encrypted index working directory: %w", err)
+	}
+	s := &sealedFiles{aead: aead, dataDir: dataDir, workDir: workDir, readOnly: readOnly}
+
+	switch {
+	case !readOnly && !sealedExists:
+		if err := s.adoptPlain(); err != nil {
+			return nil, err
+		}
+	case !readOnly && fileExists(VecLitePath(workDir)):
+		// A writer that did not close cleanly left its working copy, which
+		// is at least as new as the sealed one.
+	default:
+		if err := s.unseal(); err != nil {
+			_ = os.RemoveAll(workDir)
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// encryptedWorkBase returns the per-user directory holding unsealed working
+// copies, preferring memory-backed storage so plaintext never reaches disk.
+func encryptedWorkBase() (string, error) {
+	base := filepath.Join(os.TempDir(), fmt.Sprintf("vecgrep-%d", os.Getuid()))
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		base = filepath.Join(dir, "vecgrep")
+	} else if runtime.GOOS == "linux" && dirExists("/dev/shm") {
+		base = filepath.Join("/dev/shm", fmt.Sprintf("vecgrep-%d", os.Getuid()))
+	}
+	if err := os.MkdirAll(base, 0o700); err != nil {
+		return "", fmt.Errorf("create encrypted index working directory: %w", err)
+	}
+	// Refuse a directory another user created or made readable first.
+	info, err := os.Lstat(base)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("encrypted index working directory %s is not a directory", base)
+	}
+	if info.Mode().Perm() != 0o700 {
+		if err := os.Chmod(base, 0o700); err != nil {
+			return "", fmt.Errorf("secure encrypted index working directory %s: %w", base, err)
+		}
+	}
+	return base, nil
+}
+
+// dbPath is the working VecLite file to open.
+func (s *sealedFiles) dbPath() string {
+	return VecLitePath(s.workDir)
+}
+
+// adoptPlain moves an unencrypted index from the data directory into the
+// working directory and seals it. A working copy left from an index whose
+// sealed copy was since removed, e.g. by vecgrep reset, is discarded.
+func (s *sealedFiles) adoptPlain() error {
+	plain := VecLitePath(s.dataDir)
+	names := append(sealedFileNames, filepath.Base(plain)+".wal")
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(s.workDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove stale working copy: %w", err)
+		}
+	}
+	if !fileExists(plain) {
+		return nil
+	}
+	for _, name := range names {
+		from := filepath.Join(s.dataDir, name)
+		if !fileExists(from) {
+			continue
+		}
+		if err := copyFile(from, filepath.Join(s.workDir, name)); err != nil {
+			return fmt.Errorf("adopt unencrypted index: %w", err)
+		}
+	}
+	if err := s.seal(); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(s.dataDir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove unencrypted index: %w", err)
+		}
+	}
+	return nil
+}
+
+// seal encrypts the working copies into the data directory, replacing each
+// sealed copy atomically.
+func (s *sealedFiles) seal() error {
+	for _, name := range sealedFileNames {
+		plain := filepath.Join(s.workDir, name)
+		if !fileExists(plain) {
+			continue
+		}
+		if err := s.sealFile(plain, SealedPath(filepath.Join(s.dataDir, name))); err != nil {
+			return fmt.Errorf("seal %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// unseal decrypts the sealed copies into the working directory.
+func (s *sealedFiles) unseal() error {
+	for _, name := range sealedFileNames {
+		sealed := SealedPath(filepath.Join(s.dataDir, name))
+		if !fileExists(sealed) {
+			continue
+		}
+		if err := s.unsealFile(sealed, filepath.Join(s.workDir, name)); err != nil {
+			return fmt.Errorf("unseal %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// close seals a writer's working copies one last time and removes them.
+// It runs after VecLite has closed the files.
+func (s *sealedFiles) close() error {
+	var err error
+	if !s.readOnly {
+		err = s.seal()
+		if err != nil {
+			// Keep the working copy so the next writer recovers it.
+			return err
+		}
+	}
+	return errors.Join(err, os.RemoveAll(s.workDir))
+}
+
+// sealFile writes plainPath to sealedPath as AES-256-GCM segments. The file
+// starts with sealMagic and a random 8-byte nonce prefix; each segment is a
+// final-flag byte, a 4-byte ciphertext length, and the ciphertext. A
+// segment's nonce is the prefix plus its index, and the flag is
+// authenticated, so reordered, dropped, or truncated segments fail to open.
+func (s *sealedFiles) sealFile(plainPath, sealedPath string) error {
+	in, err := os.Open(plainPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return writeFileAtomic(sealedPath, func(w io.Writer) error {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := rand.Read(nonce[:8]); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, sealMagic); err != nil {
+			return err
+		}
+		if _, err := w.Write(nonce[:8]); err != nil {
+			return err
+		}
+		reader := bufio.NewReaderSize(in, sealSegment)
+		buf := make([]byte, sealSegment)
+		var header [5]byte
+		for index := uint32(0); ; index++ {
+			n, readErr := io.ReadFull(reader, buf)
+			if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+				return readErr
+			}
+			final := readErr != nil
+			if !final {
+				// A full segment is final only when nothing follows it.
+				if _, peekErr := reader.Peek(1); peekErr == io.EOF {
+					final = true
+				}
+			}
+			header[0] = 0
+			if final {
+				header[0] = 1
+			}
+			binary.BigEndian.PutUint32(nonce[8:], index)
+			ciphertext := s.aead.Seal(nil, nonce, buf[:n], header[:1])
+			binary.BigEndian.PutUint32(header[1:], uint32(len(ciphertext)))
+			if _, err := w.Write(header[:]); err != nil {
+				return err
+			}
+			if _, err := w.Write(ciphertext); err != nil {
+				return err
+			}
+			if final {
+				return nil
+			}
+		}
+	})
+}
+
+// unsealFile decrypts a file written by sealFile into plainPath.
+func (s *sealedFiles) unsealFile(sealedPath, plainPath string) error {
+	in, err := os.Open(sealedPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	reader := bufio.NewReader(in)
+
+	return writeFileAtomic(plainPath, func(w io.Writer) error {
+		prefix := make([]byte, len(sealMagic)+8)
+		if _, err := io.ReadFull(reader, prefix); err != nil || string(prefix[:len(sealMagic)]) != sealMagic {
+			return fmt.Errorf("%s is not a sealed vecgrep index", sealedPath)
+		}
+		nonce := make([]byte, s.aead.NonceSize())
+		copy(nonce, prefix[len(sealMagic):])
+		maxSegment := uint32(sealSegment + s.aead.Overhead())
+		var header [5]byte
+		for index := uint32(0); ; index++ {
+			if _, err := io.ReadFull(reader, header[:]); err != nil {
+				return fmt.Errorf("%s is truncated", sealedPath)
+			}
+			size := binary.BigEndian.Uint32(header[1:])
+			if size > maxSegment {
+				return fmt.Errorf("%s is corrupted", sealedPath)
+			}
+			ciphertext := make([]byte, size)
+			if _, err := io.ReadFull(reader, ciphertext); err != nil {
+				return fmt.Errorf("%s is truncated", sealedPath)
+			}
+			binary.BigEndian.PutUint32(nonce[8:], index)
+			plaintext, err := s.aead.Open(ciphertext[:0], nonce, ciphertext, header[:1])
+			if err != nil {
+				return fmt.Errorf("decrypt %s: wrong encryption key or corrupted file", sealedPath)
+			}
+			if _, err := w.Write(plaintext); err != nil {
+				return err
+			}
+			if header[0] == 1 {
+				return nil
+			}
+		}
+	})
+}
+
+// writeFileAtomic writes path through a temporary file in the same
+// directory, readable only by the owner, and renames it into place.
+func writeFileAtomic(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	if err := tmp.Chmod(0o600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	buffered := bufio.NewWriterSize(tmp, sealSegment)
+	if err := write(buffered); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+func copyFile(from, to string) error {
+	in, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeFileAtomic(to, func(w io.Writer) error {
+		_, err := io.Copy(w, in)
+		return err
+	})
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func testSealedFiles(t *testing.T, key []byte) *sealedFiles {
+	t.Helper()
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	s, err := openSealedFiles(t.TempDir(), key, false)
+	if err != nil {
+		t.Fatalf("openSealedFiles: %v", err)
+	}
+	return s
+}
+
+func TestSealFileRoundTrip(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	s := testSealedFiles(t, key)
+	dir := t.TempDir()
+	for _, size := range []int{0, 1, sealSegment, 2*sealSegment + 17} {
+		plain := make([]byte, size)
+		if _, err := rand.Read(plain); err != nil {
+			t.Fatal(err)
+		}
+		plainPath := filepath.Join(dir, "plain")
+		sealedPath := filepath.Join(dir, "sealed")
+		outPath := filepath.Join(dir, "out")
+		if err := os.WriteFile(plainPath, plain, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.sealFile(plainPath, sealedPath); err != nil {
+			t.Fatalf("seal %d bytes: %v", size, err)
+		}
+		sealed, err := os.ReadFile(sealedPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size > 0 && bytes.Contains(sealed, plain[:min(size, 64)]) {
+			t.Fatalf("sealed %d bytes contain the plaintext", size)
+		}
+		if err := s.unsealFile(sealedPath, outPath); err != nil {
+			t.Fatalf("unseal %d bytes: %v", size, err)
+		}
+		got, err := os.ReadFile(outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Fatalf("round trip of %d bytes returned %d different bytes", size, len(got))
+		}
+	}
+}
+
+func TestUnsealFileRejectsWrongKeyAndTruncation(t *testing.T) {
+	s := testSealedFiles(t, bytes.Repeat([]byte{7}, 32))
+	dir := t.TempDir()
+	plainPath := filepath.Join(dir, "plain")
+	sealedPath := filepath.Join(dir, "sealed")
+	if err := os.WriteFile(plainPath, bytes.Repeat([]byte("vecgrep "), sealSegment/4), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.sealFile(plainPath, sealedPath); err != nil {
+		t.Fatal(err)
+	}
+
+	other := testSealedFiles(t, bytes.Repeat([]byte{8}, 32))
+	if err := other.unsealFile(sealedPath, filepath.Join(dir, "out")); err == nil {
+		t.Fatal("unsealed with the wrong key")
+	}
+
+	sealed, err := os.ReadFile(sealedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Drop the final segment: the remaining one is whole but not final.
+	truncatedPath := filepath.Join(dir, "truncated")
+	if err := os.WriteFile(truncatedPath, sealed[:len(sealMagic)+8+5+sealSegment+s.aead.Overhead()], 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.unsealFile(truncatedPath, filepath.Join(dir, "out")); err == nil {
+		t.Fatal("unsealed a file missing its final segment")
+	}
+}
+
+func TestEncryptedDatabaseKeepsNoPlaintextInDataDir(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	dataDir := t.TempDir()
+	key := bytes.Repeat([]byte{7}, 32)
+	opts := OpenOptions{
+		Dimensions: 8,
+		DataDir:    dataDir,
+		Encryption: &EncryptionConfig{Key: func() ([]byte, error) { return key, nil }},
+	}
+
+	database, err := OpenWithOptions(opts)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	emb := make([]float32, 8)
+	for i := range emb {
+		emb[i] = 0.1
+	}
+	if _, err := database.InsertChunk(ChunkRecord{RelativePath: "secret.go", Content: "func proprietary() {}", StartLine: 1, IndexedAt: time.Now()}, emb); err != nil {
+		t.Fatalf("InsertChunk: %v", err)
+	}
+	if err := database.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if fileExists(VecLitePath(dataDir)) {
+		t.Fatal("plaintext index left in the data directory")
+	}
+	sealed, err := os.ReadFile(SealedPath(VecLitePath(dataDir)))
+	if err != nil {
+		t.Fatalf("read sealed index: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("proprietary")) {
+		t.Fatal("sealed index contains chunk content")
+	}
+
+	if _, err := OpenWithOptions(OpenOptions{Dimensions: 8, DataDir: dataDir}); !errors.Is(err, ErrIndexEncrypted) {
+		t.Fatalf("open without a key: err = %v, want ErrIndexEncrypted", err)
+	}
+
+	opts.ReadOnly = true
+	reader, err := OpenWithOptions(opts)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer reader.Close()
+	if count, err := reader.Backend().Count(); err != nil || count != 1 {
+		t.Fatalf("reopened count = %d, %v; want 1", count, err)
+	}
+}
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/veclite"
+)
+
+// DefaultHotMaxChunks caps the hot tier when OpenOptions.HotMaxChunks is
+// unset.
+const DefaultHotMaxChunks = 2000
+
+// hotTier holds the chunks of recently modified files: those indexed within
+// window, newest first, at most maxChunks of them. Vector search scores them
+// exhaustively next to the approximate index and merges the two, so the
+// files under active development are found at their exact similarity however
+// the HNSW graph (or an external store) routes the query.
+//
+// Membership comes from each chunk's indexed_at payload, so every process
+// sharing the index agrees on it. The tier is read from the collection the
+// first time it serves a collection (after Init, Reload, or DeleteAll swap
+// the pointer) and extended by the backend's own writes after that; chunks
+// deleted since are skipped when searched.
+type hotTier struct {
+	window    time.Duration
+	maxChunks int
+
+	mu      sync.Mutex
+	coll    *veclite.Collection
+	indexed map[uint64]time.Time
+}
+
+// newHotTier returns a hot tier over window, or nil when window is not
+// positive and the tier is off.
+func newHotTier(window time.Duration, maxChunks int) *hotTier {
+	if window <= 0 {
+		return nil
+	}
+	if maxChunks <= 0 {
+		maxChunks = DefaultHotMaxChunks
+	}
+	return &hotTier{window: window, maxChunks: maxChunks}
+}
+
+// ids returns the IDs of coll's hot chunks as of now, newest first.
+func (h *hotTier) ids(coll *veclite.Collection, now time.Time) []uint64 {
+	if h == nil || coll == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cutoff := now.Add(-h.window)
+	if h.coll != coll {
+		h.coll = coll
+		h.indexed = make(map[uint64]time.Time)
+		// Find clones the records it returns; the filter collects what it
+		// needs itself and matches nothing, so no record is copied.
+		_, _ = coll.Find(veclite.FilterFunc(func(r *veclite.Record) bool {
+			if at, ok := recordIndexedAt(r); ok && at.After(cutoff) {
+				h.indexed[r.ID] = at
+			}
+			return false
+		}))
+	}
+
+	ids := make([]uint64, 0, len(h.indexed))
+	for id, at := range h.indexed {
+		if !at.After(cutoff) {
+			delete(h.indexed, id)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ai, aj := h.indexed[ids[i]], h.indexed[ids[j]]; !ai.Equal(aj) {
+			return ai.After(aj)
+		}
+		return ids[i] > ids[j]
+	})
+	if len(ids) > h.maxChunks {
+		for _, id := range ids[h.maxChunks:] {
+			delete(h.indexed, id)
+		}
+		ids = ids[:h.maxChunks]
+	}
+	return ids
+}
+
+// noteWritten records chunks the backend just wrote to coll under ids. A
+// tier that has not read coll yet will find them when it does.
+func (h *hotTier) noteWritten(coll *veclite.Collection, ids []uint64, chunks []ChunkRecord) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.coll != coll {
+		return
+	}
+	for i, id := range ids {
+		if i < len(chunks) && id != 0 {
+			// Stored timestamps have second precision; match them so a
+			// reread ranks the chunk the same.
+			h.indexed[id] = chunks[i].IndexedAt.Truncate(time.Second)
+		}
+	}
+}
+
+// recordIndexedAt reads a chunk record's indexed_at payload.
+func recordIndexedAt(r *veclite.Record) (time.Time, bool) {
+	ts := getStringPayload(r.Payload, "indexed_at")
+	if ts == "" {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(time.RFC3339, ts)
+	return at, err == nil
+}
+
+// searchHot scores the hot chunks that pass filters exactly, by the cosine
+// similarity of their full-precision vectors, and returns the best limit.
+func (b *VecLiteBackend) searchHot(query []float32, limit int, filters []veclite.Filter) ([]veclite.Result, error) {
+	coll := b.collection()
+	ids := b.hot.ids(coll, time.Now())
+	if len(ids) == 0 || limit <= 0 {
+		return nil, nil
+	}
+	records := make([]*veclite.Record, 0, len(ids))
+records:
+	for _, id := range ids {
+		record, err := coll.Get(id)
+		if err != nil || record == nil {
+			continue
+		}
+		for _, f := range filters {
+			if !f.Match(record) {
+				continue records
+			}
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	var external map[uint64]vectorHit
+	if b.vectors != nil {
+		pointIDs := make([]uint64, len(records))
+		for i, record := range records {
+			pointIDs[i] = b.pointID(record.ID, record.Payload)
+		}
+		var err error
+		if external, err = b.vectors.points(pointIDs); err != nil {
+			return nil, err
+		}
+	}
+	results := make([]veclite.Result, 0, len(records))
+	for _, record := range records {
+		vector := record.Vector
+		switch {
+		case b.vectors != nil:
+			vector = external[b.pointID(record.ID, record.Payload)].Vector
+		case b.quantization != QuantizationNone:
+			vector, _ = b.full.get(record.ID)
+		}
+		if len(vector) != len(query) {
+			continue
+		}
+		results = append(results, veclite.Result{Record: record, Score: cosineSimilarity(query, vector)})
+	}
+	return topResults(results, limit), nil
+}
+
+// mergeHot merges hot tier results into the approximate index's, keeping
+// each chunk once at its exact hot score, and returns the best limit.
+func mergeHot(results, hot []veclite.Result, limit int) []veclite.Result {
+	if len(hot) == 0 {
+		return results
+	}
+	merged := slices.Clone(hot)
+	seen := make(map[uint64]bool, len(hot))
+	for _, r := range hot {
+		seen[r.Record.ID] = true
+	}
+	for _, r := range results {
+		if !seen[r.Record.ID] {
+			merged = append(merged, r)
+		}
+	}
+	return topResults(merged, limit)
+}
+
+// topResults sorts results by descending score, breaking ties by ID, and
+// keeps the first limit.
+func topResults(results []veclite.Result, limit int) []veclite.Result {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Record.ID < results[j].Record.ID
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
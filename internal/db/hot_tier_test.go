@@ -0,0 +1,152 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/veclite"
+)
+
+func TestHotTierTracksRecentlyIndexedChunks(t *testing.T) {
+	const dims = 16
+	dataDir := t.TempDir()
+	database, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: dataDir, HotWindow: time.Hour, HotMaxChunks: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	chunk := func(rel string, indexedAt time.Time) ChunkRecord {
+		c := NewChunkRecord("/repo/"+rel, rel, "h-"+rel, 10, "go", "func "+rel+"() {}", 1, 1, 0, 10, "function", "", "/repo")
+		c.IndexedAt = indexedAt
+		return c
+	}
+	vectors := randomUnitVectors(6, dims)
+	chunks := []ChunkRecord{
+		chunk("old1.go", now.Add(-48*time.Hour)),
+		chunk("old2.go", now.Add(-2*time.Hour)),
+		chunk("new1.go", now.Add(-10*time.Minute)),
+		chunk("new2.go", now.Add(-5*time.Minute)),
+	}
+	ids, err := database.InsertChunkBatch(chunks, vectors[:4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	hot := database.Backend().hot
+	coll := database.Backend().collection()
+	if got := hot.ids(coll, now); !slices.Equal(got, []uint64{ids[3], ids[2]}) {
+		t.Fatalf("hot ids = %v, want the two new chunks %v newest first", got, []uint64{ids[3], ids[2]})
+	}
+
+	// Re-indexing a file makes it hot; the cap keeps the newest chunks.
+	refreshed := chunk("old2.go", now.Add(-time.Minute))
+	if _, err := database.UpsertChunkBatch([]ChunkRecord{refreshed}, vectors[4:5], true); err != nil {
+		t.Fatal(err)
+	}
+	newest, err := database.InsertChunk(chunk("new3.go", now), vectors[5])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint64{newest, ids[1], ids[3]}
+	if got := hot.ids(coll, now); !slices.Equal(got, want) {
+		t.Fatalf("hot ids = %v, want %v", got, want)
+	}
+	if err := database.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another process reads the same membership from the stored chunks.
+	reopened, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: dataDir, ReadOnly: true, HotWindow: time.Hour, HotMaxChunks: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if got := reopened.Backend().hot.ids(reopened.Backend().collection(), now); !slices.Equal(got, want) {
+		t.Fatalf("reopened hot ids = %v, want %v", got, want)
+	}
+	if got := reopened.Backend().hot.ids(reopened.Backend().collection(), now.Add(2*time.Hour)); len(got) != 0 {
+		t.Fatalf("hot ids after the window = %v, want none", got)
+	}
+}
+
+func TestSearchHotScoresExactly(t *testing.T) {
+	const dims = 32
+	vectors := randomUnitVectors(60, dims)
+	chunks := make([]ChunkRecord, len(vectors))
+	for i := range chunks {
+		rel := fmt.Sprintf("f%02d.go", i)
+		chunks[i] = NewChunkRecord("/repo/"+rel, rel, "h", 10, []string{"go", "python"}[i%2], "func f() {}", 1, 1, 0, 10, "function", "", "/repo")
+	}
+
+	for _, mode := range []Quantization{QuantizationNone, QuantizationBinary} {
+		t.Run(mode.String(), func(t *testing.T) {
+			database, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: t.TempDir(), Quantization: mode, HotWindow: time.Hour})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer database.Close()
+			result, err := database.UpsertChunkBatch(chunks, vectors, true)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			query := randomUnitVectors(61, dims)[60]
+			opts := FilterOptions{Language: "python"}
+			backend := database.Backend()
+			hot, err := backend.searchHot(query, 5, backend.buildNativeFilters(opts))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			type scored struct {
+				id    uint64
+				score float32
+			}
+			var exact []scored
+			for i, v := range vectors {
+				if chunks[i].Language == "python" {
+					exact = append(exact, scored{result.IDs[i], cosineSimilarity(query, v)})
+				}
+			}
+			sort.Slice(exact, func(i, j int) bool { return exact[i].score > exact[j].score })
+			if len(hot) != 5 {
+				t.Fatalf("got %d hot results, want 5", len(hot))
+			}
+			for i, r := range hot {
+				if r.Record.ID != exact[i].id || r.Score != exact[i].score {
+					t.Fatalf("hot result %d = %d (%.4f), want %d (%.4f)", i, r.Record.ID, r.Score, exact[i].id, exact[i].score)
+				}
+			}
+
+			// The merged search returns the same best chunks.
+			results, err := database.SearchWithFilter(query, 5, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, r := range results {
+				if uint64(r.ChunkID) != exact[i].id {
+					t.Fatalf("search result %d = %d, want %d", i, r.ChunkID, exact[i].id)
+				}
+			}
+		})
+	}
+}
+
+func TestMergeHotKeepsEachChunkOnceAtItsExactScore(t *testing.T) {
+	record := func(id uint64) *veclite.Record { return &veclite.Record{ID: id} }
+	index := []veclite.Result{{Record: record(1), Score: 0.9}, {Record: record(2), Score: 0.8}, {Record: record(3), Score: 0.5}}
+	hot := []veclite.Result{{Record: record(2), Score: 0.85}, {Record: record(4), Score: 0.7}}
+	merged := mergeHot(index, hot, 3)
+	var got []string
+	for _, r := range merged {
+		got = append(got, fmt.Sprintf("%d:%.2f", r.Record.ID, r.Score))
+	}
+	if want := []string{"1:0.90", "2:0.85", "4:0.70"}; !slices.Equal(got, want) {
+		t.Fatalf("merged = %v, want %v", got, want)
+	}
+	if got := mergeHot(index, nil, 3); len(got) != 3 || got[0].Record.ID != 1 {
+		t.Fatalf("merge without hot results = %v", got)
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/abdul-hamid-achik/veclite"
@@ -338,3 +339,96 @@ func TestHybridSearchNormalizesWeightSum(t *testing.T) {
 		}
 	}
 }
+
+func TestKeywordTermsSplitsCodeIdentifiers(t *testing.T) {
+	got := keywordTerms("x := db.Search(ctx, parseHTTPRequest_v2) plain")
+	for _, want := range []string{"db", "search", "ctx", "parse", "http", "request", "v2"} {
+		if !strings.Contains(" "+got+" ", " "+want+" ") {
+			t.Fatalf("keywordTerms = %q, missing %q", got, want)
+		}
+	}
+	if strings.Contains(" "+got+" ", " plain ") || strings.Contains(" "+got+" ", " x ") {
+		t.Fatalf("keywordTerms = %q, want plain words left to the BM25 tokenizer", got)
+	}
+	if got := expandKeywordQuery("handleSearch"); got != "handleSearch handle search" {
+		t.Fatalf("expandKeywordQuery = %q", got)
+	}
+}
+
+// TestTextSearchMatchesIdentifierParts covers identifiers glued to
+// punctuation, which veclite's whitespace tokenizer indexes as one token.
+func TestTextSearchMatchesIdentifierParts(t *testing.T) {
+	database, _ := hybridTestFixture(t)
+
+	results, err := database.TextSearch("safeParse", 10, FilterOptions{})
+	if err != nil {
+		t.Fatalf("TextSearch failed: %v", err)
+	}
+	if len(results) == 0 || results[0].Chunk.RelativePath != "storage.ts" {
+		t.Fatalf("results = %+v, want storage.ts for an identifier inside a call expression", results)
+	}
+}
+
+func TestHybridSearchRRFFusionScoresAreCalibrated(t *testing.T) {
+	database, queryEmbedding := hybridTestFixture(t)
+
+	results, err := database.HybridSearchWithFusion(queryEmbedding, "sessionStorage zod schema validation", 10, FilterOptions{}, 0.7, 0.3, FusionRRF)
+	if err != nil {
+		t.Fatalf("HybridSearchWithFusion failed: %v", err)
+	}
+	if len(results) == 0 || results[0].Chunk.RelativePath != "storage.ts" {
+		t.Fatalf("results = %+v, want storage.ts first", results)
+	}
+	if results[0].Distance < 0.9 || results[0].Distance > 1.0001 {
+		t.Fatalf("top RRF score = %f, want close to 1 for a record ranked first by both", results[0].Distance)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Distance > results[i-1].Distance {
+			t.Fatalf("results not sorted by score: %+v", results)
+		}
+	}
+
+	if _, err := database.HybridSearchWithFusion(queryEmbedding, "zod", 10, FilterOptions{}, 0.7, 0.3, "borda"); err == nil {
+		t.Fatal("expected an error for an unknown fusion strategy")
+	}
+}
+
+// TestHybridSearchConcurrentCallsAgree runs hybrid searches in parallel; each
+// one runs its vector and keyword passes concurrently, so this exercises the
+// collection's read path under contention (run with -race).
+func TestHybridSearchConcurrentCallsAgree(t *testing.T) {
+	database, queryEmbedding := hybridTestFixture(t)
+	want, err := database.HybridSearch(queryEmbedding, "sessionStorage zod", 10, FilterOptions{}, 0.7, 0.3)
+	if err != nil {
+		t.Fatalf("HybridSearch failed: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := database.HybridSearch(queryEmbedding, "sessionStorage zod", 10, FilterOptions{}, 0.7, 0.3)
+			if err != nil {
+				errs <- err.Error()
+				return
+			}
+			if len(got) != len(want) {
+				errs <- "result count differs"
+				return
+			}
+			for j := range got {
+				if got[j].ChunkID != want[j].ChunkID || got[j].Distance != want[j].Distance {
+					errs <- "results differ from the sequential call"
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for msg := range errs {
+		t.Error(msg)
+	}
+}
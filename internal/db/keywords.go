@@ -0,0 +1,97 @@
+package db
+
+import (
+	"strings"
+	"unicode"
+)
+
+// keywordTrimChars mirrors the punctuation veclite's BM25 tokenizer strips
+// from the edges of whitespace-separated tokens.
+const keywordTrimChars = ".,;:!?\"'()[]{}#@$%^&*+=<>/\\|`~"
+
+// keywordTerms returns the extra BM25 terms for code text. veclite tokenizes
+// on whitespace only, so `db.Search(ctx,` is indexed as the single token
+// "db.search(ctx" and a query for "search" never reaches it. For every token
+// the whitespace tokenizer would not already produce as a plain word, this
+// emits its identifiers (runs of letters, digits, and underscores) and the
+// camelCase and snake_case parts of compound identifiers, lower-cased and
+// space-separated. Indexing and queries both pass through it, so the two
+// sides agree on term boundaries.
+func keywordTerms(text string) string {
+	var sb strings.Builder
+	seen := make(map[string]bool)
+	emit := func(term string) {
+		if term == "" || seen[term] {
+			return
+		}
+		seen[term] = true
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(term)
+	}
+
+	for _, field := range strings.Fields(text) {
+		token := strings.Trim(field, keywordTrimChars)
+		if token == "" {
+			continue
+		}
+		identifiers := splitIdentifiers(token)
+		if len(identifiers) == 1 && identifiers[0] == token && len(identifierParts(token)) <= 1 {
+			// Already a plain word to the BM25 tokenizer.
+			continue
+		}
+		for _, ident := range identifiers {
+			parts := identifierParts(ident)
+			if len(identifiers) > 1 || ident != token {
+				emit(strings.ToLower(ident))
+			}
+			if len(parts) > 1 {
+				for _, part := range parts {
+					emit(strings.ToLower(part))
+				}
+			}
+		}
+	}
+	return sb.String()
+}
+
+// expandKeywordQuery appends the keyword terms of query to it so a query for
+// `handleSearch` also matches chunks where the identifier was indexed in
+// parts.
+func expandKeywordQuery(query string) string {
+	if terms := keywordTerms(query); terms != "" {
+		return query + " " + terms
+	}
+	return query
+}
+
+// splitIdentifiers returns the runs of letters, digits, and underscores in s.
+func splitIdentifiers(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+}
+
+// identifierParts splits an identifier on underscores and camelCase
+// boundaries: "parseHTTPRequest_v2" -> parse, HTTP, Request, v2.
+func identifierParts(ident string) []string {
+	var parts []string
+	for _, word := range strings.Split(ident, "_") {
+		runes := []rune(word)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			lowerToUpper := (unicode.IsLower(prev) || unicode.IsDigit(prev)) && unicode.IsUpper(cur)
+			acronymEnd := unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				parts = append(parts, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, string(runes[start:]))
+		}
+	}
+	return parts
+}
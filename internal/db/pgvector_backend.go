@@ -0,0 +1,687 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VectorBackendPgvector keeps chunk vectors in a Postgres table with the
+// pgvector extension.
+const VectorBackendPgvector VectorBackendType = "pgvector"
+
+// DefaultPgvectorTimeout bounds each statement sent to Postgres.
+const DefaultPgvectorTimeout = 30 * time.Second
+
+// pgvectorDriver is the database/sql driver the backend connects with. The
+// pgx stdlib package registers it in builds with the pgvector tag.
+const pgvectorDriver = "pgx"
+
+// pgvectorUpsertBatch is the number of rows written per INSERT statement.
+const pgvectorUpsertBatch = 256
+
+// pgvectorColumns are the chunk payload fields copied into columns next to
+// the payload so Postgres can apply the filters it understands before
+// ranking, with their SQL types.
+var pgvectorColumns = []struct{ name, sqlType string }{
+	{"project_root", "text"},
+	{"relative_path", "text"},
+	{"language", "text"},
+	{"chunk_type", "text"},
+	{"start_line", "integer"},
+}
+
+// pgvectorTablePattern restricts table names to plain identifiers, since
+// they are spliced into SQL.
+var pgvectorTablePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,47}$`)
+
+// PgvectorConfig locates the Postgres table that holds an index's vectors.
+type PgvectorConfig struct {
+	URL     string // postgres:// connection URL
+	Table   string
+	Timeout time.Duration // zero means DefaultPgvectorTimeout
+}
+
+// PgvectorBackend stores chunks as rows of a Postgres table with a pgvector
+// column, an HNSW index, and the chunk payload as jsonb. Rows are keyed by
+// the chunk's portable key, so re-indexing updates them in place, and search
+// results come from Postgres alone: any client with the connection URL and
+// table name searches the same index. The table's metadata (embedding model
+// and profile) is kept in a companion <table>_meta table.
+type PgvectorBackend struct {
+	cfg        PgvectorConfig
+	db         *sql.DB
+	dimensions int
+	hnsw       HNSWConfig
+	readOnly   bool
+	// missing is set when a read-only handle finds no table; every read
+	// then sees an empty index instead of an error.
+	missing bool
+}
+
+// NewPgvectorBackend creates a backend for the configured table. Nothing is
+// sent to the server until Init.
+func NewPgvectorBackend(cfg PgvectorConfig) *PgvectorBackend {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultPgvectorTimeout
+	}
+	return &PgvectorBackend{cfg: cfg}
+}
+
+// Init creates the table if it does not exist and checks its vector size
+// otherwise.
+func (p *PgvectorBackend) Init(dimensions int, hnsw HNSWConfig) error {
+	return p.open(dimensions, hnsw, false)
+}
+
+// open connects and prepares the table, creating it when it does not exist.
+func (p *PgvectorBackend) open(dimensions int, hnsw HNSWConfig, readOnly bool) error {
+	if p.cfg.URL == "" {
+		return fmt.Errorf("pgvector URL is required")
+	}
+	if !pgvectorTablePattern.MatchString(p.cfg.Table) {
+		return fmt.Errorf("invalid pgvector table name %q", p.cfg.Table)
+	}
+	p.dimensions = dimensions
+	p.hnsw = hnsw
+	p.readOnly = readOnly
+	if p.db == nil {
+		if !slices.Contains(sql.Drivers(), pgvectorDriver) {
+			return fmt.Errorf("vecgrep was built without pgvector support; rebuild with 'go build -tags pgvector'")
+		}
+		conn, err := sql.Open(pgvectorDriver, p.cfg.URL)
+		if err != nil {
+			return fmt.Errorf("connect to postgres: %w", err)
+		}
+		p.db = conn
+	}
+
+	ctx, cancel := p.context()
+	defer cancel()
+	var size sql.NullInt64
+	err := p.db.QueryRowContext(ctx,
+		`SELECT atttypmod FROM pg_attribute WHERE attrelid = to_regclass($1) AND attname = 'embedding'`,
+		p.cfg.Table).Scan(&size)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if readOnly {
+			p.missing = true
+			return nil
+		}
+		return p.createTable()
+	case err != nil:
+		return fmt.Errorf("inspect pgvector table: %w", err)
+	}
+	if int(size.Int64) != dimensions {
+		return fmt.Errorf("pgvector table %q holds %d-dimensional vectors, expected %d; drop it or choose another vector.pgvector.table",
+			p.cfg.Table, size.Int64, dimensions)
+	}
+	var keyed bool
+	if err := p.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_attribute WHERE attrelid = to_regclass($1) AND attname = 'chunk_key')`,
+		p.cfg.Table).Scan(&keyed); err != nil {
+		return fmt.Errorf("inspect pgvector table: %w", err)
+	}
+	if !keyed {
+		return fmt.Errorf("pgvector table %q was written by an older vecgrep without chunk payloads; drop it or choose another vector.pgvector.table", p.cfg.Table)
+	}
+	p.missing = false
+	return nil
+}
+
+func (p *PgvectorBackend) createTable() error {
+	ctx, cancel := p.context()
+	defer cancel()
+	columns := make([]string, 0, len(pgvectorColumns))
+	for _, c := range pgvectorColumns {
+		columns = append(columns, c.name+" "+c.sqlType)
+	}
+	table := p.table()
+	statements := []string{
+		`CREATE EXTENSION IF NOT EXISTS vector`,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (chunk_key text PRIMARY KEY, id bigint NOT NULL UNIQUE, embedding vector(%d) NOT NULL, %s, payload jsonb)`,
+			table, p.dimensions, strings.Join(columns, ", ")),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (key text PRIMARY KEY, value jsonb NOT NULL)`, p.metaTable()),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s USING hnsw (embedding vector_cosine_ops) WITH (m = %d, ef_construction = %d)`,
+			quoteIdentifier(p.cfg.Table+"_embedding_idx"), table, p.hnsw.M, p.hnsw.EfConstruction),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS %s ON %s (project_root, relative_path)`,
+			quoteIdentifier(p.cfg.Table+"_path_idx"), table),
+	}
+	for _, statement := range statements {
+		if _, err := p.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("create pgvector table: %w", err)
+		}
+	}
+	p.missing = false
+	return nil
+}
+
+// upsert writes one row per ID with its payload, replacing the row of the
+// same chunk key. payloads may be nil or hold nil entries; rows without a
+// chunk_key in their payload are keyed by ID.
+func (p *PgvectorBackend) upsert(ids []uint64, vectors [][]float32, payloads []map[string]any) error {
+	names := []string{"chunk_key", "id", "embedding", "payload"}
+	updates := []string{"id = EXCLUDED.id", "embedding = EXCLUDED.embedding", "payload = EXCLUDED.payload"}
+	for _, c := range pgvectorColumns {
+		names = append(names, c.name)
+		updates = append(updates, c.name+" = EXCLUDED."+c.name)
+	}
+	ctx, cancel := p.context()
+	defer cancel()
+	for start := 0; start < len(ids); start += pgvectorUpsertBatch {
+		end := min(start+pgvectorUpsertBatch, len(ids))
+		rows := make([]string, 0, end-start)
+		args := make([]any, 0, (end-start)*len(names))
+		for i := start; i < end; i++ {
+			placeholders := make([]string, len(names))
+			for j := range placeholders {
+				placeholders[j] = "$" + strconv.Itoa(len(args)+j+1)
+			}
+			placeholders[2] += "::vector"
+			placeholders[3] += "::jsonb"
+			rows = append(rows, "("+strings.Join(placeholders, ", ")+")")
+			var payload map[string]any
+			if i < len(payloads) {
+				payload = payloads[i]
+			}
+			var document any
+			if payload != nil {
+				data, err := json.Marshal(payload)
+				if err != nil {
+					return fmt.Errorf("encode pgvector payload: %w", err)
+				}
+				document = string(data)
+			}
+			key := getStringPayload(payload, "chunk_key")
+			if key == "" {
+				key = "id:" + strconv.FormatUint(ids[i], 10)
+			}
+			args = append(args, key, int64(ids[i]), pgvectorLiteral(vectors[i]), document)
+			for _, c := range pgvectorColumns {
+				args = append(args, payload[c.name])
+			}
+		}
+		statement := fmt.Sprintf(`INSERT INTO %s (%s) VALUES %s ON CONFLICT (chunk_key) DO UPDATE SET %s`,
+			p.table(), strings.Join(names, ", "), strings.Join(rows, ", "), strings.Join(updates, ", "))
+		if _, err := p.db.ExecContext(ctx, statement, args...); err != nil {
+			return fmt.Errorf("upsert pgvector rows: %w", err)
+		}
+	}
+	return nil
+}
+
+// deletePoints removes the rows with the given IDs.
+func (p *PgvectorBackend) deletePoints(ids []uint64) error {
+	if len(ids) == 0 || p.missing {
+		return nil
+	}
+	ctx, cancel := p.context()
+	defer cancel()
+	if _, err := p.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ANY($1::bigint[])`, p.table()), pgIDArray(ids)); err != nil {
+		return fmt.Errorf("delete pgvector rows: %w", err)
+	}
+	return nil
+}
+
+// deleteProject removes the rows of one project root.
+func (p *PgvectorBackend) deleteProject(root string) error {
+	if p.missing {
+		return nil
+	}
+	ctx, cancel := p.context()
+	defer cancel()
+	if _, err := p.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE project_root = $1`, p.table()), root); err != nil {
+		return fmt.Errorf("delete pgvector rows: %w", err)
+	}
+	return nil
+}
+
+// countProject returns the number of rows of one project root.
+func (p *PgvectorBackend) countProject(root string) (int64, error) {
+	if p.missing {
+		return 0, nil
+	}
+	ctx, cancel := p.context()
+	defer cancel()
+	var count int64
+	if err := p.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM %s WHERE project_root = $1`, p.table()), root).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count pgvector rows: %w", err)
+	}
+	return count, nil
+}
+
+// metadata reads the metadata table.
+func (p *PgvectorBackend) metadata() (map[string]any, error) {
+	if p.missing {
+		return nil, nil
+	}
+	ctx, cancel := p.context()
+	defer cancel()
+	rows, err := p.db.QueryContext(ctx, fmt.Sprintf(`SELECT key, value::text FROM %s`, p.metaTable()))
+	if err != nil {
+		return nil, fmt.Errorf("read pgvector metadata: %w", err)
+	}
+	defer rows.Close()
+	meta := map[string]any{}
+	for rows.Next() {
+		var key, text string
+		if err := rows.Scan(&key, &text); err != nil {
+			return nil, fmt.Errorf("read pgvector metadata: %w", err)
+		}
+		var value any
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return nil, fmt.Errorf("invalid pgvector metadata %s: %w", key, err)
+		}
+		meta[key] = value
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read pgvector metadata: %w", err)
+	}
+	return meta, nil
+}
+
+// setMetadata writes one metadata row, or deletes it when value is nil.
+func (p *PgvectorBackend) setMetadata(key string, value any) error {
+	ctx, cancel := p.context()
+	defer cancel()
+	if value == nil {
+		if _, err := p.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE key = $1`, p.metaTable()), key); err != nil {
+			return fmt.Errorf("delete pgvector metadata: %w", err)
+		}
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode pgvector metadata %s: %w", key, err)
+	}
+	statement := fmt.Sprintf(`INSERT INTO %s (key, value) VALUES ($1, $2::jsonb) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value`, p.metaTable())
+	if _, err := p.db.ExecContext(ctx, statement, key, string(data)); err != nil {
+		return fmt.Errorf("write pgvector metadata: %w", err)
+	}
+	return nil
+}
+
+// isMissing reports that a read-only handle found no table.
+func (p *PgvectorBackend) isMissing() bool {
+	return p.missing
+}
+
+// search ranks rows by cosine similarity with the filters Postgres can
+// evaluate in the WHERE clause and returns them with their payloads.
+func (p *PgvectorBackend) search(query []float32, limit int, opts FilterOptions) ([]vectorHit, error) {
+	if p.missing || limit <= 0 {
+		return nil, nil
+	}
+	args := []any{pgvectorLiteral(query)}
+	where, args, _ := pgvectorFilter(opts, args)
+	statement := fmt.Sprintf(`SELECT id, 1 - (embedding <=> $1::vector), payload::text FROM %s%s ORDER BY embedding <=> $1::vector LIMIT %d`,
+		p.table(), where, limit)
+
+	ctx, cancel := p.context()
+	defer cancel()
+	// hnsw.ef_search is per session, so it is set inside a transaction
+	// that pins one pooled connection.
+	tx, err := p.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("pgvector search: %w", err)
+	}
+	defer tx.Rollback()
+	if ef := p.hnsw.efSearchFor(opts); ef > 0 {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`SET LOCAL hnsw.ef_search = %d`, ef)); err != nil {
+			return nil, fmt.Errorf("pgvector search: %w", err)
+		}
+	}
+	rows, err := tx.QueryContext(ctx, statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgvector search: %w", err)
+	}
+	defer rows.Close()
+	var hits []vectorHit
+	for rows.Next() {
+		var id int64
+		var score float64
+		var payload sql.NullString
+		if err := rows.Scan(&id, &score, &payload); err != nil {
+			return nil, fmt.Errorf("pgvector search: %w", err)
+		}
+		hit := vectorHit{ID: uint64(id), Score: float32(score)}
+		if hit.Payload, err = parsePgvectorPayload(payload); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("pgvector search: %w", err)
+	}
+	return hits, nil
+}
+
+// exactFilter reports whether pgvectorFilter covers every filter in opts.
+func (p *PgvectorBackend) exactFilter(opts FilterOptions) bool {
+	_, _, exact := pgvectorFilter(opts, nil)
+	return exact
+}
+
+// points fetches the given rows with their vectors and payloads. Missing
+// rows are absent from the map.
+func (p *PgvectorBackend) points(ids []uint64) (map[uint64]vectorHit, error) {
+	if len(ids) == 0 || p.missing {
+		return nil, nil
+	}
+	ctx, cancel := p.context()
+	defer cancel()
+	rows, err := p.db.QueryContext(ctx, fmt.Sprintf(`SELECT id, embedding::text, payload::text FROM %s WHERE id = ANY($1::bigint[])`, p.table()), pgIDArray(ids))
+	if err != nil {
+		return nil, fmt.Errorf("read pgvector rows: %w", err)
+	}
+	defer rows.Close()
+	points := make(map[uint64]vectorHit, len(ids))
+	for rows.Next() {
+		var id int64
+		var text string
+		var payload sql.NullString
+		if err := rows.Scan(&id, &text, &payload); err != nil {
+			return nil, fmt.Errorf("read pgvector rows: %w", err)
+		}
+		point := vectorHit{ID: uint64(id)}
+		if point.Vector, err = parsePgvector(text); err != nil {
+			return nil, err
+		}
+		if point.Payload, err = parsePgvectorPayload(payload); err != nil {
+			return nil, err
+		}
+		points[point.ID] = point
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read pgvector rows: %w", err)
+	}
+	return points, nil
+}
+
+// InsertEmbedding stores an embedding under the chunk ID.
+func (p *PgvectorBackend) InsertEmbedding(chunkID int64, embedding []float32) error {
+	if len(embedding) != p.dimensions {
+		return fmt.Errorf("embedding dimension mismatch: got %d, expected %d", len(embedding), p.dimensions)
+	}
+	return p.upsert([]uint64{uint64(chunkID)}, [][]float32{embedding}, nil)
+}
+
+// DeleteEmbedding removes the embedding stored under the chunk ID.
+func (p *PgvectorBackend) DeleteEmbedding(chunkID int64) error {
+	return p.deletePoints([]uint64{uint64(chunkID)})
+}
+
+// SearchEmbeddings performs a vector similarity search. Results carry row
+// IDs only; use the VecLite backend for chunks.
+func (p *PgvectorBackend) SearchEmbeddings(queryEmbedding []float32, limit int) ([]SearchResult, error) {
+	if len(queryEmbedding) != p.dimensions {
+		return nil, fmt.Errorf("query embedding dimension mismatch: got %d, expected %d", len(queryEmbedding), p.dimensions)
+	}
+	hits, err := p.search(queryEmbedding, limit, FilterOptions{})
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = SearchResult{ChunkID: int64(hit.ID), Distance: hit.Score}
+	}
+	return results, nil
+}
+
+// GetEmbedding retrieves the embedding stored under the chunk ID.
+func (p *PgvectorBackend) GetEmbedding(chunkID int64) ([]float32, error) {
+	points, err := p.points([]uint64{uint64(chunkID)})
+	if err != nil {
+		return nil, err
+	}
+	row, ok := points[uint64(chunkID)]
+	if !ok {
+		return nil, fmt.Errorf("embedding not found for chunk %d", chunkID)
+	}
+	return row.Vector, nil
+}
+
+// Count returns the number of rows in the table.
+func (p *PgvectorBackend) Count() (int64, error) {
+	if p.missing {
+		return 0, nil
+	}
+	ctx, cancel := p.context()
+	defer cancel()
+	var count int64
+	if err := p.db.QueryRowContext(ctx, fmt.Sprintf(`SELECT count(*) FROM %s`, p.table())).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count pgvector rows: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteAll drops and recreates the table and its metadata.
+func (p *PgvectorBackend) DeleteAll() error {
+	if p.readOnly {
+		return fmt.Errorf("pgvector backend is read-only")
+	}
+	ctx, cancel := p.context()
+	defer cancel()
+	if _, err := p.db.ExecContext(ctx, fmt.Sprintf(`DROP TABLE IF EXISTS %s, %s`, p.table(), p.metaTable())); err != nil {
+		return fmt.Errorf("drop pgvector table: %w", err)
+	}
+	return p.createTable()
+}
+
+// DeleteOrphaned removes rows whose IDs are not in chunkIDs.
+func (p *PgvectorBackend) DeleteOrphaned(chunkIDs []int64) (int64, error) {
+	if p.missing {
+		return 0, nil
+	}
+	valid := make(map[uint64]bool, len(chunkIDs))
+	for _, id := range chunkIDs {
+		valid[uint64(id)] = true
+	}
+	ctx, cancel := p.context()
+	defer cancel()
+	rows, err := p.db.QueryContext(ctx, fmt.Sprintf(`SELECT id FROM %s`, p.table()))
+	if err != nil {
+		return 0, fmt.Errorf("list pgvector rows: %w", err)
+	}
+	var orphans []uint64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("list pgvector rows: %w", err)
+		}
+		if !valid[uint64(id)] {
+			orphans = append(orphans, uint64(id))
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("list pgvector rows: %w", err)
+	}
+	if err := p.deletePoints(orphans); err != nil {
+		return 0, err
+	}
+	return int64(len(orphans)), nil
+}
+
+// Sync is a no-op: every statement commits before it returns.
+func (p *PgvectorBackend) Sync() error {
+	return nil
+}
+
+// Close closes the connection pool.
+func (p *PgvectorBackend) Close() error {
+	if p.db == nil {
+		return nil
+	}
+	err := p.db.Close()
+	p.db = nil
+	return err
+}
+
+// Type returns the backend type name.
+func (p *PgvectorBackend) Type() string {
+	return string(VectorBackendPgvector)
+}
+
+// Location describes the table as the connection URL, without its
+// password, and the table name.
+func (p *PgvectorBackend) Location() string {
+	location := p.cfg.URL
+	if u, err := url.Parse(p.cfg.URL); err == nil {
+		location = u.Redacted()
+	}
+	return location + " table " + p.cfg.Table
+}
+
+// table returns the quoted table name.
+func (p *PgvectorBackend) table() string {
+	return quoteIdentifier(p.cfg.Table)
+}
+
+// metaTable returns the quoted name of the metadata table.
+func (p *PgvectorBackend) metaTable() string {
+	return quoteIdentifier(p.cfg.Table + "_meta")
+}
+
+func (p *PgvectorBackend) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), p.cfg.Timeout)
+}
+
+// quoteIdentifier quotes a Postgres identifier.
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// pgvectorLiteral formats v in pgvector's text form, [x,y,...].
+func pgvectorLiteral(v []float32) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// parsePgvector parses pgvector's text form.
+func parsePgvector(text string) ([]float32, error) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "[") || !strings.HasSuffix(text, "]") {
+		return nil, fmt.Errorf("invalid pgvector value %q", text)
+	}
+	body := text[1 : len(text)-1]
+	if body == "" {
+		return []float32{}, nil
+	}
+	fields := strings.Split(body, ",")
+	v := make([]float32, len(fields))
+	for i, field := range fields {
+		x, err := strconv.ParseFloat(strings.TrimSpace(field), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pgvector value: %w", err)
+		}
+		v[i] = float32(x)
+	}
+	return v, nil
+}
+
+// parsePgvectorPayload decodes a payload column read as text; NULL is a
+// nil payload.
+func parsePgvectorPayload(text sql.NullString) (map[string]any, error) {
+	if !text.Valid {
+		return nil, nil
+	}
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(text.String), &payload); err != nil {
+		return nil, fmt.Errorf("invalid pgvector payload: %w", err)
+	}
+	return payload, nil
+}
+
+// pgIDArray formats ids as a Postgres array literal, which every driver can
+// pass as text.
+func pgIDArray(ids []uint64) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.FormatUint(id, 10)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// pgvectorFilter translates the filters Postgres can evaluate into a WHERE
+// clause whose placeholders continue after args. exact is false when some
+// filter (file glob, directory prefix, symbol, tags, exclusions) is left for
+// the caller to apply to the hits.
+func pgvectorFilter(opts FilterOptions, args []any) (where string, _ []any, exact bool) {
+	var conds []string
+	match := func(column string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		placeholders := make([]string, len(values))
+		for i, v := range values {
+			args = append(args, v)
+			placeholders[i] = "$" + strconv.Itoa(len(args))
+		}
+		if len(values) == 1 {
+			conds = append(conds, column+" = "+placeholders[0])
+			return
+		}
+		conds = append(conds, column+" IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	lower := func(values []string) []string {
+		out := make([]string, len(values))
+		for i, v := range values {
+			out[i] = strings.ToLower(v)
+		}
+		return out
+	}
+
+	if len(opts.ProjectRoots) > 0 {
+		match("project_root", opts.ProjectRoots)
+	} else if opts.ProjectRoot != "" {
+		match("project_root", []string{opts.ProjectRoot})
+	}
+	if opts.Language != "" {
+		match("language", lower([]string{opts.Language}))
+	} else {
+		match("language", lower(opts.Languages))
+	}
+	if opts.ChunkType != "" {
+		match("chunk_type", lower([]string{opts.ChunkType}))
+	} else {
+		match("chunk_type", lower(opts.ChunkTypes))
+	}
+	match("relative_path", opts.FilePaths)
+	if opts.MinLine > 0 {
+		args = append(args, opts.MinLine)
+		conds = append(conds, "start_line >= $"+strconv.Itoa(len(args)))
+	}
+	if opts.MaxLine > 0 {
+		args = append(args, opts.MaxLine)
+		conds = append(conds, "start_line <= $"+strconv.Itoa(len(args)))
+	}
+
+	exact = opts.FilePattern == "" && opts.Directory == "" && len(opts.Directories) == 0 && opts.Symbol == "" &&
+		len(opts.Tags) == 0 && len(opts.ExcludeIDs) == 0 && len(opts.ExcludePaths) == 0
+	if len(conds) == 0 {
+		return "", args, exact
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args, exact
+}
+
+// Ensure PgvectorBackend implements VectorBackend and can hold a VecLite
+// collection's chunks.
+var _ vectorStore = (*PgvectorBackend)(nil)
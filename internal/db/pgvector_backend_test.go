@@ -0,0 +1,150 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPgvectorFilter(t *testing.T) {
+	where, args, exact := pgvectorFilter(FilterOptions{
+		ProjectRoots: []string{"/a", "/b"},
+		Language:     "Go",
+		ChunkTypes:   []string{"function"},
+		MinLine:      10,
+	}, []any{"[1]"})
+	want := " WHERE project_root IN ($2, $3) AND language = $4 AND chunk_type = $5 AND start_line >= $6"
+	if where != want {
+		t.Fatalf("where = %q, want %q", where, want)
+	}
+	if !reflect.DeepEqual(args, []any{"[1]", "/a", "/b", "go", "function", 10}) {
+		t.Fatalf("args = %v", args)
+	}
+	if !exact {
+		t.Fatal("filters Postgres evaluates must be reported exact")
+	}
+
+	where, args, exact = pgvectorFilter(FilterOptions{Directory: "pkg"}, nil)
+	if where != "" || len(args) != 0 || exact {
+		t.Fatalf("directory filter = %q %v exact=%v; want it left to the caller", where, args, exact)
+	}
+}
+
+func TestPgvectorLiteralRoundTrip(t *testing.T) {
+	v := []float32{0.25, -1, 3.5e-7}
+	text := pgvectorLiteral(v)
+	if text != "[0.25,-1,3.5e-07]" {
+		t.Fatalf("pgvectorLiteral() = %q", text)
+	}
+	got, err := parsePgvector(text)
+	if err != nil || !reflect.DeepEqual(got, v) {
+		t.Fatalf("parsePgvector(%q) = %v, %v", text, got, err)
+	}
+	if _, err := parsePgvector("1,2"); err == nil {
+		t.Fatal("parsePgvector accepted a value without brackets")
+	}
+	if got := pgIDArray([]uint64{3, 14}); got != "{3,14}" {
+		t.Fatalf("pgIDArray() = %q", got)
+	}
+}
+
+func TestPgvectorBackendRequiresDriver(t *testing.T) {
+	if slices.Contains(sql.Drivers(), pgvectorDriver) {
+		t.Skip("built with the pgvector tag")
+	}
+	_, err := OpenWithOptions(OpenOptions{
+		Dimensions: 8,
+		DataDir:    t.TempDir(),
+		Pgvector:   &PgvectorConfig{URL: "postgres://localhost/vecgrep", Table: "chunks"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "-tags pgvector") {
+		t.Fatalf("OpenWithOptions() error = %v, want a rebuild hint", err)
+	}
+}
+
+// TestPgvectorBackendSharesIndexAcrossClients runs against a real Postgres
+// with the pgvector extension, named by VECGREP_TEST_PGVECTOR_URL, in builds
+// with the pgvector tag.
+func TestPgvectorBackendSharesIndexAcrossClients(t *testing.T) {
+	url := os.Getenv("VECGREP_TEST_PGVECTOR_URL")
+	if url == "" {
+		t.Skip("VECGREP_TEST_PGVECTOR_URL is not set")
+	}
+	if !slices.Contains(sql.Drivers(), pgvectorDriver) {
+		t.Skip("built without the pgvector tag")
+	}
+	const dims = 8
+	pg := &PgvectorConfig{URL: url, Table: fmt.Sprintf("vecgrep_test_%d", time.Now().UnixNano())}
+	t.Cleanup(func() {
+		conn, err := sql.Open(pgvectorDriver, url)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s, %s`, quoteIdentifier(pg.Table), quoteIdentifier(pg.Table+"_meta")))
+	})
+
+	vectors := randomUnitVectors(3, dims)
+	var chunks []ChunkRecord
+	for i := range vectors {
+		rel := fmt.Sprintf("pkg/f%d.go", i)
+		chunks = append(chunks, NewChunkRecord("/home/ann/repo/"+rel, rel, "h", 10, "go", fmt.Sprintf("func F%d() {}", i), 1, 1, 0, 10, "function", fmt.Sprintf("F%d", i), "/home/ann/repo"))
+	}
+	writer, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: t.TempDir(), Pgvector: pg, ProjectRoot: "/home/ann/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	if _, err := writer.UpsertChunkBatch(chunks, vectors, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.SetIndexedEmbedding(IndexedEmbedding{Model: "m", Dimensions: dims}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reader has only the connection settings: its data directory is
+	// fresh, as after vecgrep init, and its checkout lives elsewhere.
+	reader, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: t.TempDir(), Pgvector: pg, ProjectRoot: "/home/bob/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if embedding, ok := reader.IndexedEmbedding(); !ok || embedding.Model != "m" {
+		t.Fatalf("reader IndexedEmbedding() = %+v, %v; want the writer's", embedding, ok)
+	}
+	if stats, err := reader.StatsForProject("/home/bob/repo"); err != nil || stats["chunks"] != 3 {
+		t.Fatalf("reader StatsForProject() = %v, %v; want 3 chunks", stats, err)
+	}
+	results, err := reader.SearchWithFilter(vectors[1], 1, FilterOptions{ProjectRoot: "/home/bob/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("reader got %d results, want 1", len(results))
+	}
+	top := results[0].Chunk
+	if top.FilePath != "/home/bob/repo/pkg/f1.go" || top.StartLine != 1 || top.Content != "func F1() {}" || top.ChunkType != "function" || top.SymbolName != "F1" || top.FileHash != "h" {
+		t.Fatalf("reader chunk = %+v, want pkg/f1.go under the reader's root", top)
+	}
+
+	// Re-indexing a changed chunk updates its row in place.
+	chunks[1].Content, chunks[1].FileHash = "func F1() { return }", "h2"
+	if _, err := writer.UpsertChunkBatch(chunks[1:2], vectors[1:2], false); err != nil {
+		t.Fatal(err)
+	}
+	if stats, err := reader.StatsForProject("/home/bob/repo"); err != nil || stats["chunks"] != 3 {
+		t.Fatalf("after re-index StatsForProject() = %v, %v; want 3 chunks", stats, err)
+	}
+	results, err = reader.SearchWithFilter(vectors[1], 1, FilterOptions{ProjectRoot: "/home/bob/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Chunk.Content != "func F1() { return }" || results[0].Chunk.FileHash != "h2" {
+		t.Fatalf("after re-index reader results = %+v, want the updated chunk", results)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build pgvector
+
+package db
+
+// The pgx stdlib package registers the "pgx" database/sql driver the
+// pgvector backend connects with. Builds without the pgvector tag leave it
+// out, and opening a pgvector backend reports how to rebuild.
+import _ "github.com/jackc/pgx/v5/stdlib"
@@ -0,0 +1,549 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VectorBackendQdrant keeps chunk vectors in a Qdrant collection.
+const VectorBackendQdrant VectorBackendType = "qdrant"
+
+// DefaultQdrantTimeout bounds each request to the Qdrant server.
+const DefaultQdrantTimeout = 30 * time.Second
+
+// qdrantUpsertBatch is the number of points sent per upsert request.
+const qdrantUpsertBatch = 256
+
+// qdrantPayloadFields are the chunk payload fields Qdrant indexes so it can
+// apply the filters it understands before ranking.
+var qdrantPayloadFields = map[string]string{
+	"project_root":  "keyword",
+	"relative_path": "keyword",
+	"language":      "keyword",
+	"chunk_type":    "keyword",
+	"start_line":    "integer",
+}
+
+// qdrantMetadataPoint is the ID of the point holding the collection's
+// metadata under qdrantMetadataField. storePointID never returns it, and its
+// payload carries no chunk, so searches skip it.
+const (
+	qdrantMetadataPoint = 0
+	qdrantMetadataField = "vecgrep_metadata"
+)
+
+// QdrantConfig locates the Qdrant collection that holds an index's vectors.
+type QdrantConfig struct {
+	URL        string // e.g. http://localhost:6333
+	APIKey     string // sent as the api-key header when set
+	Collection string
+	Timeout    time.Duration // zero means DefaultQdrantTimeout
+}
+
+// QdrantBackend stores chunks as points in a Qdrant collection through its
+// REST API. Each point holds a chunk's vector and full payload under an ID
+// derived from the chunk's key, so search results come from Qdrant alone and
+// every client pointed at the collection searches the same index.
+type QdrantBackend struct {
+	cfg        QdrantConfig
+	client     *http.Client
+	dimensions int
+	hnsw       HNSWConfig
+	readOnly   bool
+	// missing is set when a read-only handle finds no collection; every
+	// read then sees an empty index instead of an error.
+	missing bool
+}
+
+// NewQdrantBackend creates a backend for the configured collection. Nothing
+// is sent to the server until Init.
+func NewQdrantBackend(cfg QdrantConfig) *QdrantBackend {
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultQdrantTimeout
+	}
+	return &QdrantBackend{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Init creates the collection if it does not exist and checks its vector
+// size otherwise.
+func (q *QdrantBackend) Init(dimensions int, hnsw HNSWConfig) error {
+	return q.open(dimensions, hnsw, false)
+}
+
+// open prepares the collection, creating it when it does not exist.
+func (q *QdrantBackend) open(dimensions int, hnsw HNSWConfig, readOnly bool) error {
+	if q.cfg.URL == "" {
+		return fmt.Errorf("qdrant URL is required")
+	}
+	if q.cfg.Collection == "" {
+		return fmt.Errorf("qdrant collection is required")
+	}
+	q.dimensions = dimensions
+	q.hnsw = hnsw
+	q.readOnly = readOnly
+
+	var info struct {
+		Result struct {
+			Config struct {
+				Params struct {
+					Vectors struct {
+						Size int `json:"size"`
+					} `json:"vectors"`
+				} `json:"params"`
+			} `json:"config"`
+		} `json:"result"`
+	}
+	err := q.do(http.MethodGet, "", nil, &info)
+	var status *qdrantStatusError
+	switch {
+	case errors.As(err, &status) && status.code == http.StatusNotFound:
+		if readOnly {
+			q.missing = true
+			return nil
+		}
+		return q.createCollection()
+	case err != nil:
+		return err
+	}
+	if size := info.Result.Config.Params.Vectors.Size; size != dimensions {
+		return fmt.Errorf("qdrant collection %q holds %d-dimensional vectors, expected %d; delete it or choose another vector.qdrant.collection",
+			q.cfg.Collection, size, dimensions)
+	}
+	return nil
+}
+
+func (q *QdrantBackend) createCollection() error {
+	body := map[string]any{
+		"vectors": map[string]any{"size": q.dimensions, "distance": "Cosine"},
+		"hnsw_config": map[string]any{
+			"m":            q.hnsw.M,
+			"ef_construct": q.hnsw.EfConstruction,
+		},
+	}
+	if err := q.do(http.MethodPut, "", body, nil); err != nil {
+		return fmt.Errorf("create qdrant collection: %w", err)
+	}
+	for field, schema := range qdrantPayloadFields {
+		index := map[string]any{"field_name": field, "field_schema": schema}
+		if err := q.do(http.MethodPut, "/index?wait=true", index, nil); err != nil {
+			return fmt.Errorf("create qdrant payload index %s: %w", field, err)
+		}
+	}
+	q.missing = false
+	return nil
+}
+
+// upsert writes one point per ID with its payload. payloads may be nil or
+// hold nil entries.
+func (q *QdrantBackend) upsert(ids []uint64, vectors [][]float32, payloads []map[string]any) error {
+	for start := 0; start < len(ids); start += qdrantUpsertBatch {
+		end := min(start+qdrantUpsertBatch, len(ids))
+		points := make([]map[string]any, 0, end-start)
+		for i := start; i < end; i++ {
+			point := map[string]any{"id": ids[i], "vector": vectors[i]}
+			if i < len(payloads) && payloads[i] != nil {
+				point["payload"] = payloads[i]
+			}
+			points = append(points, point)
+		}
+		if err := q.do(http.MethodPut, "/points?wait=true", map[string]any{"points": points}, nil); err != nil {
+			return fmt.Errorf("upsert qdrant points: %w", err)
+		}
+	}
+	return nil
+}
+
+// deletePoints removes the points with the given IDs.
+func (q *QdrantBackend) deletePoints(ids []uint64) error {
+	if len(ids) == 0 || q.missing {
+		return nil
+	}
+	if err := q.do(http.MethodPost, "/points/delete?wait=true", map[string]any{"points": ids}, nil); err != nil {
+		return fmt.Errorf("delete qdrant points: %w", err)
+	}
+	return nil
+}
+
+// deleteProject removes the points of one project root.
+func (q *QdrantBackend) deleteProject(root string) error {
+	if q.missing {
+		return nil
+	}
+	filter := map[string]any{"must": []map[string]any{{"key": "project_root", "match": map[string]any{"value": root}}}}
+	if err := q.do(http.MethodPost, "/points/delete?wait=true", map[string]any{"filter": filter}, nil); err != nil {
+		return fmt.Errorf("delete qdrant points: %w", err)
+	}
+	return nil
+}
+
+// countProject returns the number of points of one project root.
+func (q *QdrantBackend) countProject(root string) (int64, error) {
+	if q.missing {
+		return 0, nil
+	}
+	filter := map[string]any{"must": []map[string]any{{"key": "project_root", "match": map[string]any{"value": root}}}}
+	var resp struct {
+		Result struct {
+			Count int64 `json:"count"`
+		} `json:"result"`
+	}
+	if err := q.do(http.MethodPost, "/points/count", map[string]any{"exact": true, "filter": filter}, &resp); err != nil {
+		return 0, fmt.Errorf("count qdrant points: %w", err)
+	}
+	return resp.Result.Count, nil
+}
+
+// metadata reads the metadata point.
+func (q *QdrantBackend) metadata() (map[string]any, error) {
+	points, err := q.points([]uint64{qdrantMetadataPoint})
+	if err != nil {
+		return nil, err
+	}
+	meta, _ := points[qdrantMetadataPoint].Payload[qdrantMetadataField].(map[string]any)
+	return meta, nil
+}
+
+// setMetadata rewrites the metadata point with one value changed, or
+// removed when value is nil. The point's vector is an arbitrary unit vector.
+func (q *QdrantBackend) setMetadata(key string, value any) error {
+	meta, err := q.metadata()
+	if err != nil {
+		return err
+	}
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	if value == nil {
+		delete(meta, key)
+	} else {
+		meta[key] = value
+	}
+	vector := make([]float32, q.dimensions)
+	vector[0] = 1
+	return q.upsert([]uint64{qdrantMetadataPoint}, [][]float32{vector}, []map[string]any{{qdrantMetadataField: meta}})
+}
+
+// isMissing reports that a read-only handle found no collection.
+func (q *QdrantBackend) isMissing() bool {
+	return q.missing
+}
+
+// search ranks points by cosine similarity with the filters Qdrant can
+// evaluate pushed down.
+func (q *QdrantBackend) search(query []float32, limit int, opts FilterOptions) ([]vectorHit, error) {
+	filter, _ := qdrantFilter(opts)
+	return q.searchPoints(query, limit, q.hnsw.efSearchFor(opts), filter)
+}
+
+// exactFilter reports whether qdrantFilter covers every filter in opts.
+func (q *QdrantBackend) exactFilter(opts FilterOptions) bool {
+	_, exact := qdrantFilter(opts)
+	return exact
+}
+
+// searchPoints returns up to limit points with their payloads, ranked by
+// cosine similarity and restricted by a Qdrant filter (nil for none).
+func (q *QdrantBackend) searchPoints(query []float32, limit, efSearch int, filter map[string]any) ([]vectorHit, error) {
+	if q.missing || limit <= 0 {
+		return nil, nil
+	}
+	body := map[string]any{"vector": query, "limit": limit, "with_payload": true}
+	if efSearch > 0 {
+		body["params"] = map[string]any{"hnsw_ef": efSearch}
+	}
+	if filter != nil {
+		body["filter"] = filter
+	}
+	var resp struct {
+		Result []vectorHit `json:"result"`
+	}
+	if err := q.do(http.MethodPost, "/points/search", body, &resp); err != nil {
+		return nil, fmt.Errorf("qdrant search: %w", err)
+	}
+	return resp.Result, nil
+}
+
+// points fetches the given points with their vectors and payloads. Missing
+// points are absent from the map.
+func (q *QdrantBackend) points(ids []uint64) (map[uint64]vectorHit, error) {
+	if len(ids) == 0 || q.missing {
+		return nil, nil
+	}
+	body := map[string]any{"ids": ids, "with_vector": true, "with_payload": true}
+	var resp struct {
+		Result []vectorHit `json:"result"`
+	}
+	if err := q.do(http.MethodPost, "/points", body, &resp); err != nil {
+		return nil, fmt.Errorf("retrieve qdrant points: %w", err)
+	}
+	points := make(map[uint64]vectorHit, len(resp.Result))
+	for _, p := range resp.Result {
+		points[p.ID] = p
+	}
+	return points, nil
+}
+
+// InsertEmbedding stores an embedding under the chunk ID.
+func (q *QdrantBackend) InsertEmbedding(chunkID int64, embedding []float32) error {
+	if len(embedding) != q.dimensions {
+		return fmt.Errorf("embedding dimension mismatch: got %d, expected %d", len(embedding), q.dimensions)
+	}
+	return q.upsert([]uint64{uint64(chunkID)}, [][]float32{embedding}, nil)
+}
+
+// DeleteEmbedding removes the embedding stored under the chunk ID.
+func (q *QdrantBackend) DeleteEmbedding(chunkID int64) error {
+	return q.deletePoints([]uint64{uint64(chunkID)})
+}
+
+// SearchEmbeddings performs a vector similarity search. Results carry point
+// IDs only; use the VecLite backend for chunks.
+func (q *QdrantBackend) SearchEmbeddings(queryEmbedding []float32, limit int) ([]SearchResult, error) {
+	if len(queryEmbedding) != q.dimensions {
+		return nil, fmt.Errorf("query embedding dimension mismatch: got %d, expected %d", len(queryEmbedding), q.dimensions)
+	}
+	hits, err := q.searchPoints(queryEmbedding, limit, q.hnsw.EfSearch, nil)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]SearchResult, len(hits))
+	for i, hit := range hits {
+		results[i] = SearchResult{ChunkID: int64(hit.ID), Distance: hit.Score}
+	}
+	return results, nil
+}
+
+// GetEmbedding retrieves the embedding stored under the chunk ID.
+func (q *QdrantBackend) GetEmbedding(chunkID int64) ([]float32, error) {
+	points, err := q.points([]uint64{uint64(chunkID)})
+	if err != nil {
+		return nil, err
+	}
+	p, ok := points[uint64(chunkID)]
+	if !ok {
+		return nil, fmt.Errorf("embedding not found for chunk %d", chunkID)
+	}
+	return p.Vector, nil
+}
+
+// Count returns the number of points in the collection.
+func (q *QdrantBackend) Count() (int64, error) {
+	if q.missing {
+		return 0, nil
+	}
+	var resp struct {
+		Result struct {
+			Count int64 `json:"count"`
+		} `json:"result"`
+	}
+	if err := q.do(http.MethodPost, "/points/count", map[string]any{"exact": true}, &resp); err != nil {
+		return 0, fmt.Errorf("count qdrant points: %w", err)
+	}
+	return resp.Result.Count, nil
+}
+
+// DeleteAll drops and recreates the collection.
+func (q *QdrantBackend) DeleteAll() error {
+	if q.readOnly {
+		return fmt.Errorf("qdrant backend is read-only")
+	}
+	var status *qdrantStatusError
+	if err := q.do(http.MethodDelete, "", nil, nil); err != nil && (!errors.As(err, &status) || status.code != http.StatusNotFound) {
+		return fmt.Errorf("drop qdrant collection: %w", err)
+	}
+	return q.createCollection()
+}
+
+// DeleteOrphaned removes points whose IDs are not in chunkIDs.
+func (q *QdrantBackend) DeleteOrphaned(chunkIDs []int64) (int64, error) {
+	if q.missing {
+		return 0, nil
+	}
+	valid := make(map[uint64]bool, len(chunkIDs))
+	for _, id := range chunkIDs {
+		valid[uint64(id)] = true
+	}
+	var orphans []uint64
+	var offset any
+	for {
+		body := map[string]any{"limit": 1000, "with_payload": false, "with_vector": false}
+		if offset != nil {
+			body["offset"] = offset
+		}
+		var resp struct {
+			Result struct {
+				Points []struct {
+					ID uint64 `json:"id"`
+				} `json:"points"`
+				NextPageOffset any `json:"next_page_offset"`
+			} `json:"result"`
+		}
+		if err := q.do(http.MethodPost, "/points/scroll", body, &resp); err != nil {
+			return 0, fmt.Errorf("scroll qdrant points: %w", err)
+		}
+		for _, p := range resp.Result.Points {
+			if !valid[p.ID] {
+				orphans = append(orphans, p.ID)
+			}
+		}
+		if resp.Result.NextPageOffset == nil {
+			break
+		}
+		offset = resp.Result.NextPageOffset
+	}
+	if err := q.deletePoints(orphans); err != nil {
+		return 0, err
+	}
+	return int64(len(orphans)), nil
+}
+
+// Sync is a no-op: every write waits for Qdrant to apply it.
+func (q *QdrantBackend) Sync() error {
+	return nil
+}
+
+// Close releases idle connections to the server.
+func (q *QdrantBackend) Close() error {
+	q.client.CloseIdleConnections()
+	return nil
+}
+
+// Type returns the backend type name.
+func (q *QdrantBackend) Type() string {
+	return string(VectorBackendQdrant)
+}
+
+// Location describes the collection as URL/collection for status output.
+func (q *QdrantBackend) Location() string {
+	return q.cfg.URL + "/collections/" + q.cfg.Collection
+}
+
+// qdrantStatusError is a non-2xx response from the Qdrant server.
+type qdrantStatusError struct {
+	code    int
+	message string
+}
+
+func (e *qdrantStatusError) Error() string {
+	if e.message == "" {
+		return fmt.Sprintf("qdrant returned %d", e.code)
+	}
+	return fmt.Sprintf("qdrant returned %d: %s", e.code, e.message)
+}
+
+// do sends a request for the collection path plus suffix and decodes the
+// JSON response into out when it is non-nil.
+func (q *QdrantBackend) do(method, suffix string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, q.cfg.URL+"/collections/"+url.PathEscape(q.cfg.Collection)+suffix, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if q.cfg.APIKey != "" {
+		req.Header.Set("api-key", q.cfg.APIKey)
+	}
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("qdrant request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read qdrant response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var failure struct {
+			Status struct {
+				Error string `json:"error"`
+			} `json:"status"`
+		}
+		_ = json.Unmarshal(data, &failure)
+		return &qdrantStatusError{code: resp.StatusCode, message: failure.Status.Error}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decode qdrant response: %w", err)
+	}
+	return nil
+}
+
+// qdrantFilter translates the filters Qdrant can evaluate into a Qdrant
+// filter. exact is false when some filter (file glob, directory prefix,
+// symbol, tags, exclusions) is left for the caller to apply to the hits.
+func qdrantFilter(opts FilterOptions) (filter map[string]any, exact bool) {
+	var must []map[string]any
+	match := func(field string, values []string) {
+		switch len(values) {
+		case 0:
+		case 1:
+			must = append(must, map[string]any{"key": field, "match": map[string]any{"value": values[0]}})
+		default:
+			must = append(must, map[string]any{"key": field, "match": map[string]any{"any": values}})
+		}
+	}
+	lower := func(values []string) []string {
+		out := make([]string, len(values))
+		for i, v := range values {
+			out[i] = strings.ToLower(v)
+		}
+		return out
+	}
+
+	if len(opts.ProjectRoots) > 0 {
+		match("project_root", opts.ProjectRoots)
+	} else if opts.ProjectRoot != "" {
+		match("project_root", []string{opts.ProjectRoot})
+	}
+	if opts.Language != "" {
+		match("language", lower([]string{opts.Language}))
+	} else {
+		match("language", lower(opts.Languages))
+	}
+	if opts.ChunkType != "" {
+		match("chunk_type", lower([]string{opts.ChunkType}))
+	} else {
+		match("chunk_type", lower(opts.ChunkTypes))
+	}
+	match("relative_path", opts.FilePaths)
+	if opts.MinLine > 0 || opts.MaxLine > 0 {
+		lines := map[string]any{}
+		if opts.MinLine > 0 {
+			lines["gte"] = opts.MinLine
+		}
+		if opts.MaxLine > 0 {
+			lines["lte"] = opts.MaxLine
+		}
+		must = append(must, map[string]any{"key": "start_line", "range": lines})
+	}
+
+	exact = opts.FilePattern == "" && opts.Directory == "" && len(opts.Directories) == 0 && opts.Symbol == "" &&
+		len(opts.Tags) == 0 && len(opts.ExcludeIDs) == 0 && len(opts.ExcludePaths) == 0
+	if len(must) == 0 {
+		return nil, exact
+	}
+	return map[string]any{"must": must}, exact
+}
+
+// Ensure QdrantBackend implements VectorBackend and can hold a VecLite
+// collection's chunks.
+var _ vectorStore = (*QdrantBackend)(nil)
@@ -0,0 +1,420 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeQdrant serves the subset of the Qdrant REST API QdrantBackend uses,
+// with exact cosine search over one collection.
+type fakeQdrant struct {
+	mu       sync.Mutex
+	exists   bool
+	size     int
+	points   map[uint64]fakePoint
+	searches []map[string]any // request bodies of /points/search
+}
+
+type fakePoint struct {
+	vector  []float32
+	payload map[string]any
+}
+
+func newFakeQdrant(t *testing.T) (*fakeQdrant, *QdrantConfig) {
+	t.Helper()
+	fake := &fakeQdrant{points: map[uint64]fakePoint{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, &QdrantConfig{URL: server.URL, APIKey: "secret", Collection: "chunks"}
+}
+
+func (f *fakeQdrant) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if r.Header.Get("api-key") != "secret" {
+		http.Error(w, `{"status":{"error":"unauthorized"}}`, http.StatusUnauthorized)
+		return
+	}
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	route := r.Method + " " + strings.TrimPrefix(r.URL.Path, "/collections/chunks")
+	if !f.exists && route != "PUT " {
+		http.Error(w, `{"status":{"error":"Not found: Collection doesn't exist"}}`, http.StatusNotFound)
+		return
+	}
+
+	var result any = true
+	switch route {
+	case "GET ":
+		result = map[string]any{"config": map[string]any{"params": map[string]any{"vectors": map[string]any{"size": f.size}}}}
+	case "PUT ":
+		f.exists = true
+		f.size = int(body["vectors"].(map[string]any)["size"].(float64))
+		f.points = map[uint64]fakePoint{}
+	case "DELETE ":
+		f.exists = false
+	case "PUT /index":
+	case "PUT /points":
+		for _, raw := range body["points"].([]any) {
+			p := raw.(map[string]any)
+			payload, _ := p["payload"].(map[string]any)
+			f.points[uint64(p["id"].(float64))] = fakePoint{vector: floats(p["vector"]), payload: payload}
+		}
+	case "POST /points/delete":
+		if filter, ok := body["filter"].(map[string]any); ok {
+			for id, p := range f.points {
+				if fakeMatches(p.payload, filter) {
+					delete(f.points, id)
+				}
+			}
+			break
+		}
+		for _, id := range body["points"].([]any) {
+			delete(f.points, uint64(id.(float64)))
+		}
+	case "POST /points/search":
+		f.searches = append(f.searches, body)
+		query := floats(body["vector"])
+		var filter map[string]any
+		if raw, ok := body["filter"].(map[string]any); ok {
+			filter = raw
+		}
+		var hits []map[string]any
+		for id, p := range f.points {
+			if fakeMatches(p.payload, filter) {
+				hits = append(hits, map[string]any{"id": id, "score": cosineSimilarity(query, p.vector), "payload": p.payload})
+			}
+		}
+		sort.Slice(hits, func(i, j int) bool { return hits[i]["score"].(float32) > hits[j]["score"].(float32) })
+		if limit := int(body["limit"].(float64)); len(hits) > limit {
+			hits = hits[:limit]
+		}
+		result = hits
+	case "POST /points":
+		points := []map[string]any{}
+		for _, raw := range body["ids"].([]any) {
+			id := uint64(raw.(float64))
+			if p, ok := f.points[id]; ok {
+				points = append(points, map[string]any{"id": id, "vector": p.vector, "payload": p.payload})
+			}
+		}
+		result = points
+	case "POST /points/count":
+		filter, _ := body["filter"].(map[string]any)
+		count := 0
+		for _, p := range f.points {
+			if fakeMatches(p.payload, filter) {
+				count++
+			}
+		}
+		result = map[string]any{"count": count}
+	case "POST /points/scroll":
+		points := []map[string]any{}
+		for id := range f.points {
+			points = append(points, map[string]any{"id": id})
+		}
+		result = map[string]any{"points": points, "next_page_offset": nil}
+	default:
+		http.Error(w, fmt.Sprintf(`{"status":{"error":"unexpected %s"}}`, route), http.StatusBadRequest)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": result, "status": "ok"})
+}
+
+func floats(raw any) []float32 {
+	values := raw.([]any)
+	v := make([]float32, len(values))
+	for i, x := range values {
+		v[i] = float32(x.(float64))
+	}
+	return v
+}
+
+// fakeMatches evaluates the must conditions qdrantFilter produces.
+func fakeMatches(payload, filter map[string]any) bool {
+	if filter == nil {
+		return true
+	}
+	for _, raw := range filter["must"].([]any) {
+		cond := raw.(map[string]any)
+		value := payload[cond["key"].(string)]
+		if match, ok := cond["match"].(map[string]any); ok {
+			if want, ok := match["value"]; ok && value != want {
+				return false
+			}
+			if anyOf, ok := match["any"].([]any); ok {
+				found := false
+				for _, want := range anyOf {
+					found = found || value == want
+				}
+				if !found {
+					return false
+				}
+			}
+		}
+		if lines, ok := cond["range"].(map[string]any); ok {
+			line, _ := value.(float64)
+			if gte, ok := lines["gte"].(float64); ok && line < gte {
+				return false
+			}
+			if lte, ok := lines["lte"].(float64); ok && line > lte {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestQdrantBackendStoresAndSearchesVectors(t *testing.T) {
+	const dims = 16
+	fake, qdrant := newFakeQdrant(t)
+	vectors := randomUnitVectors(12, dims)
+	chunks := make([]ChunkRecord, len(vectors))
+	for i := range chunks {
+		rel, lang := fmt.Sprintf("pkg/f%02d.go", i), "go"
+		if i%2 == 1 {
+			rel, lang = fmt.Sprintf("web/f%02d.ts", i), "typescript"
+		}
+		chunks[i] = NewChunkRecord("/repo/"+rel, rel, "h", 10, lang, "body", 1, 1, 0, 10, "function", "", "/repo")
+	}
+
+	database, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: t.TempDir(), Qdrant: qdrant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err := database.UpsertChunkBatch(chunks, vectors, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(fake.points); got != len(chunks) {
+		t.Fatalf("qdrant holds %d points, want %d", got, len(chunks))
+	}
+	if version, _ := database.VecVersion(); !strings.HasPrefix(version, "qdrant (") {
+		t.Fatalf("VecVersion() = %q, want the qdrant location", version)
+	}
+
+	results, err := database.SearchWithFilter(vectors[3], 3, FilterOptions{Language: "TypeScript"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) == 0 || results[0].Chunk.RelativePath != "web/f03.ts" {
+		t.Fatalf("top result = %+v, want web/f03.ts", results)
+	}
+	for _, r := range results {
+		if r.Chunk.Language != "typescript" || r.Chunk.Vector != nil {
+			t.Fatalf("result %s: language %q, vector %v; want typescript without vector", r.Chunk.RelativePath, r.Chunk.Language, r.Chunk.Vector)
+		}
+	}
+	if filter := fake.searches[len(fake.searches)-1]["filter"]; filter == nil {
+		t.Fatal("language filter was not sent to qdrant")
+	}
+
+	// A directory prefix is applied to the hits, not by Qdrant.
+	results, err = database.SearchWithFilter(vectors[3], 2, FilterOptions{Directory: "pkg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || !strings.HasPrefix(results[0].Chunk.RelativePath, "pkg/") || !strings.HasPrefix(results[1].Chunk.RelativePath, "pkg/") {
+		t.Fatalf("directory-filtered results = %+v", results)
+	}
+
+	stored, err := database.GetChunksByFile("pkg/f00.go")
+	if err != nil || len(stored) != 1 {
+		t.Fatalf("GetChunksByFile() = %v, %v", stored, err)
+	}
+	if cosineSimilarity(stored[0].Vector, vectors[0]) < 0.9999 {
+		t.Fatal("GetChunksByFile did not return the full vector from qdrant")
+	}
+
+	if _, _, err := database.Backend().DeleteByProjectFile("/repo", "pkg/f00.go"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fake.points[database.Backend().pointID(stored[0].ID, chunkPayload(stored[0]))]; ok {
+		t.Fatal("deleting a file left its qdrant point behind")
+	}
+	if err := database.Backend().DeleteAll(); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.points) != 0 {
+		t.Fatalf("DeleteAll left %d qdrant points", len(fake.points))
+	}
+}
+
+func TestQdrantBackendServesSearchFromPointPayloads(t *testing.T) {
+	const dims = 8
+	fake, qdrant := newFakeQdrant(t)
+	vectors := randomUnitVectors(3, dims)
+	var chunks []ChunkRecord
+	for i := range vectors {
+		rel := fmt.Sprintf("pkg/f%d.go", i)
+		chunks = append(chunks, NewChunkRecord("/home/ann/repo/"+rel, rel, "h", 10, "go", fmt.Sprintf("func F%d() {}", i), 1, 1, 0, 10, "function", fmt.Sprintf("F%d", i), "/home/ann/repo"))
+	}
+	writer, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: t.TempDir(), Qdrant: qdrant, ProjectRoot: "/home/ann/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer writer.Close()
+	if _, err := writer.UpsertChunkBatch(chunks, vectors, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.SetIndexedEmbedding(IndexedEmbedding{Model: "m", Dimensions: dims}); err != nil {
+		t.Fatal(err)
+	}
+	for id, p := range fake.points {
+		if id == qdrantMetadataPoint {
+			continue
+		}
+		if p.payload["content"] == nil || p.payload["project_root"] != "." || p.payload["file_path"] != nil {
+			t.Fatalf("point payload = %v, want the chunk with a portable root", p.payload)
+		}
+	}
+
+	// Another client with the project checked out elsewhere and an empty
+	// local index searches the same collection.
+	reader, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: t.TempDir(), Qdrant: qdrant, ProjectRoot: "/home/bob/repo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	results, err := reader.SearchWithFilter(vectors[1], 2, FilterOptions{ProjectRoot: "/home/bob/repo", Language: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Chunk.RelativePath != "pkg/f1.go" {
+		t.Fatalf("reader results = %+v, want pkg/f1.go first", results)
+	}
+	top := results[0].Chunk
+	if top.FilePath != "/home/bob/repo/pkg/f1.go" || top.ProjectRoot != "/home/bob/repo" || top.Content != "func F1() {}" || top.SymbolName != "F1" {
+		t.Fatalf("reader chunk = %+v, want it under the reader's root", top)
+	}
+	chunk, err := reader.Backend().GetChunkByID(results[0].ChunkID)
+	if err != nil || chunk.Content != top.Content {
+		t.Fatalf("GetChunkByID(%d) = %+v, %v", results[0].ChunkID, chunk, err)
+	}
+	if embedding, ok := reader.IndexedEmbedding(); !ok || embedding.Model != "m" {
+		t.Fatalf("reader IndexedEmbedding() = %+v, %v; want the writer's", embedding, ok)
+	}
+	if stats, err := reader.StatsForProject("/home/bob/repo"); err != nil || stats["chunks"] != 3 {
+		t.Fatalf("reader StatsForProject() = %v, %v; want the 3 shared chunks", stats, err)
+	}
+
+	// Resetting the project clears its points, including ones this client
+	// did not write.
+	if err := reader.Reset(context.Background(), "/home/bob/repo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fake.points[qdrantMetadataPoint]; !ok || len(fake.points) != 1 {
+		t.Fatalf("project reset left %d qdrant points, want only the metadata point", len(fake.points)-1)
+	}
+}
+
+func TestQdrantBackendGrowsSearchForSelectiveFilters(t *testing.T) {
+	const dims = 8
+	fake, qdrant := newFakeQdrant(t)
+	query := randomUnitVectors(1, dims)[0]
+	opposite := make([]float32, dims)
+	for i, x := range query {
+		opposite[i] = -x
+	}
+	// Only the three least similar chunks pass the directory filter, which
+	// Qdrant cannot evaluate.
+	var chunks []ChunkRecord
+	var vectors [][]float32
+	for i := 0; i < 30; i++ {
+		rel, vector := fmt.Sprintf("pkg/f%02d.go", i), query
+		if i < 3 {
+			rel, vector = fmt.Sprintf("rare/f%02d.go", i), opposite
+		}
+		chunks = append(chunks, NewChunkRecord("/repo/"+rel, rel, "h", 10, "go", "body", 1, 1, 0, 10, "function", "", "/repo"))
+		vectors = append(vectors, vector)
+	}
+
+	database, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: t.TempDir(), Qdrant: qdrant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err := database.UpsertChunkBatch(chunks, vectors, true); err != nil {
+		t.Fatal(err)
+	}
+
+	fake.searches = nil
+	results, err := database.SearchWithFilter(query, 3, FilterOptions{Directory: "rare"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	var limits []float64
+	for _, search := range fake.searches {
+		limits = append(limits, search["limit"].(float64))
+	}
+	if len(limits) != 2 || limits[0] != 12 || limits[1] != 48 {
+		t.Fatalf("qdrant search limits = %v, want [12 48]", limits)
+	}
+}
+
+func TestQdrantBackendSendsPerSearchEfSearch(t *testing.T) {
+	const dims = 8
+	fake, qdrant := newFakeQdrant(t)
+	vectors := randomUnitVectors(2, dims)
+	chunks := []ChunkRecord{
+		NewChunkRecord("/repo/a.go", "a.go", "h", 10, "go", "body", 1, 1, 0, 10, "function", "", "/repo"),
+		NewChunkRecord("/repo/b.go", "b.go", "h", 10, "go", "body", 1, 1, 0, 10, "function", "", "/repo"),
+	}
+	database, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: t.TempDir(), Qdrant: qdrant, HNSWEfSearch: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err := database.UpsertChunkBatch(chunks, vectors, true); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct{ override, want int }{{0, 50}, {200, 200}} {
+		if _, err := database.SearchWithFilter(vectors[0], 1, FilterOptions{EfSearch: tt.override}); err != nil {
+			t.Fatal(err)
+		}
+		params, _ := fake.searches[len(fake.searches)-1]["params"].(map[string]any)
+		if got := params["hnsw_ef"]; got != float64(tt.want) {
+			t.Errorf("EfSearch %d: hnsw_ef = %v, want %d", tt.override, got, tt.want)
+		}
+	}
+}
+
+func TestQdrantBackendRejectsDimensionMismatch(t *testing.T) {
+	fake, qdrant := newFakeQdrant(t)
+	dataDir := t.TempDir()
+	database, err := OpenWithOptions(OpenOptions{Dimensions: 8, DataDir: dataDir, Qdrant: qdrant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunk := NewChunkRecord("/repo/a.go", "a.go", "h", 10, "go", "body", 1, 1, 0, 10, "function", "", "/repo")
+	if _, err := database.InsertChunk(chunk, randomUnitVectors(1, 8)[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Close(); err != nil {
+		t.Fatal(err)
+	}
+	fake.size = 16
+
+	if _, err := OpenWithOptions(OpenOptions{Dimensions: 8, DataDir: dataDir, Qdrant: qdrant}); err == nil || !strings.Contains(err.Error(), "16-dimensional") {
+		t.Fatalf("OpenWithOptions() error = %v, want a dimension mismatch", err)
+	}
+}
+
+func TestOpenRejectsQdrantWithQuantization(t *testing.T) {
+	_, qdrant := newFakeQdrant(t)
+	_, err := OpenWithOptions(OpenOptions{Dimensions: 8, DataDir: t.TempDir(), Qdrant: qdrant, Quantization: QuantizationInt8})
+	if err == nil {
+		t.Fatal("OpenWithOptions accepted quantization with the qdrant backend")
+	}
+}
@@ -0,0 +1,351 @@
+package db
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/abdul-hamid-achik/veclite"
+)
+
+// Quantization selects how chunk vectors are stored in the VecLite file.
+type Quantization string
+
+const (
+	// QuantizationNone stores full-precision float32 vectors.
+	QuantizationNone Quantization = ""
+	// QuantizationInt8 stores each vector as integer codes in [-127, 127],
+	// scaled by the vector's largest component. Cosine similarity ignores
+	// the scale, so the codes rank almost exactly like the original.
+	QuantizationInt8 Quantization = "int8"
+	// QuantizationBinary stores only the sign of each component. It is the
+	// smallest and coarsest mode, so it over-fetches more candidates.
+	QuantizationBinary Quantization = "binary"
+)
+
+// ParseQuantization parses a configured quantization mode. Empty and "none"
+// mean full precision.
+func ParseQuantization(value string) (Quantization, error) {
+	switch value {
+	case "", "none":
+		return QuantizationNone, nil
+	case string(QuantizationInt8), string(QuantizationBinary):
+		return Quantization(value), nil
+	default:
+		return QuantizationNone, fmt.Errorf("invalid quantization %q: want none, int8, or binary", value)
+	}
+}
+
+// String returns the configured name of the mode.
+func (q Quantization) String() string {
+	if q == QuantizationNone {
+		return "none"
+	}
+	return string(q)
+}
+
+// rescoreMultiplier is how many quantized candidates are fetched per wanted
+// result before re-scoring them at full precision.
+func (q Quantization) rescoreMultiplier() int {
+	switch q {
+	case QuantizationInt8:
+		return 4
+	case QuantizationBinary:
+		return 10
+	default:
+		return 1
+	}
+}
+
+// quantize returns the vector stored in the collection for v. The codes are
+// whole numbers, which VecLite's gob snapshot encodes in a few bytes instead
+// of the full float32 mantissa.
+func (q Quantization) quantize(v []float32) []float32 {
+	switch q {
+	case QuantizationInt8:
+		var maxAbs float64
+		for _, x := range v {
+			maxAbs = math.Max(maxAbs, math.Abs(float64(x)))
+		}
+		codes := make([]float32, len(v))
+		if maxAbs == 0 {
+			return codes
+		}
+		for i, x := range v {
+			codes[i] = float32(math.Round(float64(x) / maxAbs * 127))
+		}
+		return codes
+	case QuantizationBinary:
+		codes := make([]float32, len(v))
+		for i, x := range v {
+			if x < 0 {
+				codes[i] = -1
+			} else {
+				codes[i] = 1
+			}
+		}
+		return codes
+	default:
+		return v
+	}
+}
+
+// FullVectorsPath returns the file that keeps full-precision vectors next to
+// a quantized VecLite database.
+func FullVectorsPath(dataDir string) string {
+	return filepath.Join(dataDir, "vectors.full")
+}
+
+// fullVectorStore keeps full-precision vectors in fixed-width rows addressed
+// by VecLite record ID, so re-scoring reads only the rows of its candidates.
+// Record IDs are never reused while the collection lives, and the file is
+// truncated whenever the collection is emptied or recreated. A missing or
+// all-zero row reads as absent; those records keep their quantized score.
+type fullVectorStore struct {
+	file       *os.File
+	dimensions int
+}
+
+func openFullVectorStore(path string, dimensions int, readOnly bool) (*fullVectorStore, error) {
+	var (
+		file *os.File
+		err  error
+	)
+	if readOnly {
+		file, err = os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			// Nothing was written yet; every record reads as absent.
+			return &fullVectorStore{dimensions: dimensions}, nil
+		}
+	} else {
+		file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open full-precision vectors: %w", err)
+	}
+	return &fullVectorStore{file: file, dimensions: dimensions}, nil
+}
+
+func (s *fullVectorStore) rowSize() int64 {
+	return int64(s.dimensions) * 4
+}
+
+func (s *fullVectorStore) offset(id uint64) int64 {
+	return int64(id-1) * s.rowSize()
+}
+
+func (s *fullVectorStore) put(id uint64, v []float32) error {
+	if s == nil || s.file == nil || id == 0 {
+		return nil
+	}
+	if len(v) != s.dimensions {
+		return fmt.Errorf("full-precision vector dimension mismatch: got %d, expected %d", len(v), s.dimensions)
+	}
+	row := make([]byte, s.rowSize())
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(row[i*4:], math.Float32bits(x))
+	}
+	if _, err := s.file.WriteAt(row, s.offset(id)); err != nil {
+		return fmt.Errorf("write full-precision vector: %w", err)
+	}
+	return nil
+}
+
+func (s *fullVectorStore) get(id uint64) ([]float32, bool) {
+	if s == nil || s.file == nil || id == 0 {
+		return nil, false
+	}
+	row := make([]byte, s.rowSize())
+	// Rows past the end of the file (io.EOF) were never written.
+	if _, err := s.file.ReadAt(row, s.offset(id)); err != nil {
+		return nil, false
+	}
+	v := make([]float32, s.dimensions)
+	present := false
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(row[i*4:]))
+		present = present || v[i] != 0
+	}
+	return v, present
+}
+
+func (s *fullVectorStore) truncate() error {
+	if s == nil || s.file == nil {
+		return nil
+	}
+	if err := s.file.Truncate(0); err != nil {
+		return fmt.Errorf("truncate full-precision vectors: %w", err)
+	}
+	return nil
+}
+
+func (s *fullVectorStore) sync() error {
+	if s == nil || s.file == nil {
+		return nil
+	}
+	return s.file.Sync()
+}
+
+func (s *fullVectorStore) close() error {
+	if s == nil || s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// storedVector returns what the collection stores for embedding v. With an
+// external store holding the vectors, the collection only needs the smallest
+// codes VecLite accepts.
+func (b *VecLiteBackend) storedVector(v []float32) []float32 {
+	if b.vectors != nil {
+		return QuantizationBinary.quantize(v)
+	}
+	return b.quantization.quantize(v)
+}
+
+// putFullVectors records the full-precision vectors of records whose
+// collection entry holds codes: in the external vector store along with
+// their payloads, or in the sidecar of a quantized collection.
+func (b *VecLiteBackend) putFullVectors(ids []uint64, vectors [][]float32, payloads []map[string]any) error {
+	if b.vectors != nil {
+		points := make([]uint64, len(ids))
+		stored := make([]map[string]any, len(ids))
+		for i, id := range ids {
+			var payload map[string]any
+			if i < len(payloads) {
+				payload = payloads[i]
+			}
+			points[i] = b.pointID(id, payload)
+			stored[i] = b.storePayload(id, payload)
+		}
+		return b.vectors.upsert(points, vectors, stored)
+	}
+	if b.quantization == QuantizationNone {
+		return nil
+	}
+	for i, id := range ids {
+		if err := b.full.put(id, vectors[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fullVector returns the full-precision vector of a record whose collection
+// entry holds codes.
+func (b *VecLiteBackend) fullVector(r *veclite.Record) ([]float32, bool) {
+	if b.vectors != nil {
+		id := b.pointID(r.ID, r.Payload)
+		points, err := b.vectors.points([]uint64{id})
+		point, ok := points[id]
+		return point.Vector, err == nil && ok
+	}
+	if b.quantization == QuantizationNone {
+		return nil, false
+	}
+	return b.full.get(r.ID)
+}
+
+// dropVectors removes the external points of deleted records. Quantized
+// sidecar rows are left in place; their IDs are never reused.
+func (b *VecLiteBackend) dropVectors(records []*veclite.Record) error {
+	if b.vectors == nil || len(records) == 0 {
+		return nil
+	}
+	ids := make([]uint64, len(records))
+	for i, r := range records {
+		ids[i] = b.pointID(r.ID, r.Payload)
+	}
+	return b.vectors.deletePoints(ids)
+}
+
+// deleteChunksWhere deletes the chunk records matching filters, along with
+// their external points.
+func (b *VecLiteBackend) deleteChunksWhere(filters ...veclite.Filter) (int, error) {
+	if b.vectors == nil {
+		return b.collection().DeleteWhere(filters...)
+	}
+	records, err := b.collection().Find(filters...)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := b.collection().DeleteWhere(filters...)
+	if err != nil {
+		return deleted, err
+	}
+	return deleted, b.dropVectors(records)
+}
+
+// chunkFromRecord converts a record like recordToChunk, restoring the
+// full-precision vector when the collection stores quantized codes. Chunks
+// of a collection with an external vector store come back without a vector; callers that
+// need vectors fetch them in bulk (see hydrateVectors).
+func (b *VecLiteBackend) chunkFromRecord(r *veclite.Record) ChunkRecord {
+	chunk := recordToChunk(r)
+	if b.vectors != nil {
+		chunk.Vector = nil
+		return chunk
+	}
+	if v, ok := b.fullVector(r); ok {
+		chunk.Vector = v
+	}
+	return chunk
+}
+
+// hydrateVectors fills in the vectors of chunks read from records with an
+// external vector store in one request.
+func (b *VecLiteBackend) hydrateVectors(chunks []ChunkRecord, records []*veclite.Record) error {
+	if b.vectors == nil || len(chunks) == 0 {
+		return nil
+	}
+	ids := make([]uint64, len(records))
+	for i, r := range records {
+		ids[i] = b.pointID(r.ID, r.Payload)
+	}
+	points, err := b.vectors.points(ids)
+	if err != nil {
+		return err
+	}
+	for i := range chunks {
+		chunks[i].Vector = points[ids[i]].Vector
+	}
+	return nil
+}
+
+// candidateLimit widens a vector search so re-scoring has candidates to
+// promote past quantization error.
+func (b *VecLiteBackend) candidateLimit(limit int) int {
+	return limit * b.quantization.rescoreMultiplier()
+}
+
+// rescore replaces each candidate's quantized similarity with the cosine
+// similarity of its full-precision vector and keeps the best limit.
+func (b *VecLiteBackend) rescore(query []float32, results []veclite.Result, limit int) []veclite.Result {
+	if b.quantization == QuantizationNone {
+		return results
+	}
+	for i := range results {
+		if v, ok := b.fullVector(results[i].Record); ok {
+			results[i].Score = cosineSimilarity(query, v)
+		}
+	}
+	return topResults(results, limit)
+}
+
+func cosineSimilarity(a, b []float32) float32 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
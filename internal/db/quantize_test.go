@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"slices"
+	"testing"
+)
+
+func randomUnitVectors(n, dims int) [][]float32 {
+	rng := rand.New(rand.NewSource(7))
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		v := make([]float32, dims)
+		var norm float64
+		for j := range v {
+			v[j] = float32(rng.NormFloat64())
+			norm += float64(v[j]) * float64(v[j])
+		}
+		for j := range v {
+			v[j] = float32(float64(v[j]) / math.Sqrt(norm))
+		}
+		vectors[i] = v
+	}
+	return vectors
+}
+
+func TestParseQuantization(t *testing.T) {
+	for value, want := range map[string]Quantization{"": QuantizationNone, "none": QuantizationNone, "int8": QuantizationInt8, "binary": QuantizationBinary} {
+		if got, err := ParseQuantization(value); err != nil || got != want {
+			t.Fatalf("ParseQuantization(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseQuantization("fp16"); err == nil {
+		t.Fatal("ParseQuantization accepted an unknown mode")
+	}
+}
+
+func TestQuantizedStorageRescoresFromFullPrecision(t *testing.T) {
+	const dims = 64
+	vectors := randomUnitVectors(40, dims)
+	chunks := make([]ChunkRecord, len(vectors))
+	for i := range chunks {
+		rel := fmt.Sprintf("f%02d.go", i)
+		chunks[i] = NewChunkRecord("/repo/"+rel, rel, "h", 10, "go", "func f() {}", 1, 1, 0, 10, "function", "", "/repo")
+	}
+
+	sizes := map[Quantization]int64{}
+	for _, mode := range []Quantization{QuantizationNone, QuantizationInt8, QuantizationBinary} {
+		t.Run(mode.String(), func(t *testing.T) {
+			dataDir := t.TempDir()
+			database, err := OpenWithOptions(OpenOptions{Dimensions: dims, DataDir: dataDir, Quantization: mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer database.Close()
+			if _, err := database.UpsertChunkBatch(chunks, vectors, true); err != nil {
+				t.Fatalf("UpsertChunkBatch failed: %v", err)
+			}
+			if err := database.Sync(); err != nil {
+				t.Fatal(err)
+			}
+
+			// The exact vector is the top hit with its full-precision score,
+			// even in binary mode where many codes tie.
+			results, err := database.SearchWithFilter(vectors[17], 3, FilterOptions{ProjectRoot: "/repo"})
+			if err != nil || len(results) != 3 {
+				t.Fatalf("SearchWithFilter = %d results, %v", len(results), err)
+			}
+			if results[0].Chunk.RelativePath != "f17.go" || math.Abs(float64(results[0].Distance)-1) > 1e-5 {
+				t.Fatalf("top hit = %s score %f, want f17.go scored 1", results[0].Chunk.RelativePath, results[0].Distance)
+			}
+
+			// Chunks and embeddings read back at full precision.
+			stored, err := database.GetChunksByFile("f05.go")
+			if err != nil || len(stored) != 1 || !slices.Equal(stored[0].Vector, vectors[5]) {
+				t.Fatalf("GetChunksByFile vector differs from the original: %v", err)
+			}
+			embedding, err := database.GetEmbedding(int64(stored[0].ID))
+			if err != nil || !slices.Equal(embedding, vectors[5]) {
+				t.Fatalf("GetEmbedding differs from the original: %v", err)
+			}
+
+			info, err := os.Stat(VecLitePath(dataDir))
+			if err != nil {
+				t.Fatal(err)
+			}
+			sizes[mode] = info.Size()
+
+			if mode == QuantizationNone {
+				if _, err := os.Stat(FullVectorsPath(dataDir)); !os.IsNotExist(err) {
+					t.Fatalf("full-precision file written without quantization: %v", err)
+				}
+				return
+			}
+			// Emptying the collection drops the full-precision rows with it.
+			if err := database.Reset(context.Background(), "/repo"); err != nil {
+				t.Fatal(err)
+			}
+			if info, err := os.Stat(FullVectorsPath(dataDir)); err != nil || info.Size() != 0 {
+				t.Fatalf("full-precision file after reset: %v, %v", info, err)
+			}
+		})
+	}
+	if sizes[QuantizationInt8] >= sizes[QuantizationNone] || sizes[QuantizationBinary] >= sizes[QuantizationNone] {
+		t.Fatalf("quantized files are not smaller: %v", sizes)
+	}
+}
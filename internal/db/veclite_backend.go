@@ -3,13 +3,16 @@ package db
 import (
 	"errors"
 	"fmt"
+	"path"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/abdul-hamid-achik/veclite"
+	"golang.org/x/sync/errgroup"
 )
 
 // SearchMode defines how search is performed.
@@ -47,11 +50,46 @@ type ChunkRecord struct {
 	StartByte    int
 	EndByte      int
 	ChunkIndex   int
-	ChunkType    string
-	SymbolName   string
-	ProjectRoot  string
-	IndexedAt    time.Time
-	Vector       []float32
+	// ParentIndex is the ChunkIndex of the chunk enclosing this one in the
+	// same file (the impl block around a method, the first piece of a split
+	// function, the block a doc comment documents), or -1 for a top-level
+	// chunk. Chunks indexed before parents were recorded read as -1.
+	ParentIndex int
+	ChunkType   string
+	SymbolName  string
+	ProjectRoot string
+	// Ref is the git ref a chunk was indexed from by `vecgrep index --ref`;
+	// empty for chunks read from the working tree.
+	Ref       string
+	IndexedAt time.Time
+	Vector    []float32
+	// Provenance records how the chunk was produced: the vecgrep version, the
+	// chunk strategy (builtin, external, or structural), and the chunker
+	// parameters. Chunks written before provenance was recorded leave these
+	// empty.
+	IndexerVersion string
+	ChunkStrategy  string
+	ChunkParams    string
+	// EmbedHash is a hash of the exact text that was embedded for the chunk,
+	// so a re-index can reuse the stored vector of an unchanged chunk. Empty
+	// for chunks indexed before it was recorded.
+	EmbedHash string
+	// EmbedVersion names the embedding provider, model, dimensions, and
+	// document preprocessing that produced the chunk's vector, so vectors
+	// from an earlier configuration can be found and re-embedded. Empty for
+	// chunks indexed before it was recorded.
+	EmbedVersion string
+	// Truncated marks a chunk that was longer than the model's input limit,
+	// so only its beginning was embedded. TruncationEstimated marks the
+	// length as estimated from text length, for providers with no
+	// tokenizer.
+	Truncated           bool
+	TruncationEstimated bool
+	// Secrets names the secret-scanner rules the chunk matched, sorted.
+	// With the redact policy the matched values are already masked.
+	Secrets []string
+	// Tags are the labels indexing.tags rules gave the chunk's file, sorted.
+	Tags []string
 }
 
 func stableChunkKey(chunk ChunkRecord) string {
@@ -65,6 +103,59 @@ func stableChunkKey(chunk ChunkRecord) string {
 	)
 }
 
+// chunkPayload builds the stored payload for a chunk record, including the
+// stable chunk_key used for keyed upserts.
+func chunkPayload(chunk ChunkRecord) map[string]any {
+	payload := map[string]any{
+		"file_path":     chunk.FilePath,
+		"relative_path": chunk.RelativePath,
+		"file_hash":     chunk.FileHash,
+		"source_hash":   chunk.SourceHash,
+		"file_size":     chunk.FileSize,
+		"language":      chunk.Language,
+		"content":       chunk.Content,
+		"start_line":    chunk.StartLine,
+		"end_line":      chunk.EndLine,
+		"start_byte":    chunk.StartByte,
+		"end_byte":      chunk.EndByte,
+		"chunk_index":   chunk.ChunkIndex,
+		"chunk_type":    chunk.ChunkType,
+		"symbol_name":   chunk.SymbolName,
+		"search_terms":  keywordTerms(chunk.Content + " " + chunk.SymbolName + " " + chunk.RelativePath),
+		"chunk_key":     stableChunkKey(chunk),
+		"project_root":  chunk.ProjectRoot,
+		"ref":           chunk.Ref,
+		"indexed_at":    chunk.IndexedAt.Format(time.RFC3339),
+		// Provenance is stored per chunk so ListFiles can report it without
+		// another collection; every chunk of a file shares the same values.
+		"indexer_version": chunk.IndexerVersion,
+		"chunk_strategy":  chunk.ChunkStrategy,
+		"chunk_params":    chunk.ChunkParams,
+	}
+	if chunk.ParentIndex >= 0 && chunk.ParentIndex != chunk.ChunkIndex {
+		payload["parent_index"] = chunk.ParentIndex
+	}
+	if chunk.EmbedHash != "" {
+		payload["embed_hash"] = chunk.EmbedHash
+	}
+	if chunk.EmbedVersion != "" {
+		payload["embed_version"] = chunk.EmbedVersion
+	}
+	if chunk.Truncated {
+		payload["truncated"] = true
+	}
+	if chunk.TruncationEstimated {
+		payload["truncation_estimated"] = true
+	}
+	if len(chunk.Secrets) > 0 {
+		payload["secrets"] = strings.Join(chunk.Secrets, ",")
+	}
+	if len(chunk.Tags) > 0 {
+		payload["tags"] = strings.Join(chunk.Tags, ",")
+	}
+	return payload
+}
+
 // FileInfo represents file information stored in veclite.
 type FileInfo struct {
 	Path         string
@@ -74,6 +165,16 @@ type FileInfo struct {
 	Size         int64
 	Language     string
 	IndexedAt    time.Time
+	// IndexerVersion, ChunkStrategy, and ChunkParams are the provenance of
+	// the file's chunks; all three are empty for files indexed before
+	// provenance was recorded.
+	IndexerVersion string
+	ChunkStrategy  string
+	ChunkParams    string
+	// EmbedVersion is the embedding version of the file's vectors (see
+	// ChunkRecord.EmbedVersion); empty for files indexed before it was
+	// recorded.
+	EmbedVersion string
 }
 
 // Stats contains database statistics.
@@ -83,6 +184,18 @@ type Stats struct {
 	TotalProjects int64
 	Languages     map[string]int64
 	ChunkTypes    map[string]int64
+	// LanguageLines and LanguageBytes total the indexed files per language
+	// (lines up to each file's last chunk, and source file size), so the
+	// breakdown reflects code volume rather than chunk counts.
+	LanguageLines map[string]int64
+	LanguageBytes map[string]int64
+	// TruncatedChunks counts chunks cut to fit the model's input limit, of
+	// which EstimatedTruncatedChunks were cut by estimated token counts, and
+	// OrphanedChunks those naming no source file, which no reindex can
+	// replace or prune.
+	TruncatedChunks          int64
+	EstimatedTruncatedChunks int64
+	OrphanedChunks           int64
 }
 
 // HNSWConfig holds HNSW index parameters. It mirrors veclite's HNSWConfig
@@ -94,6 +207,15 @@ type HNSWConfig struct {
 	EfSearch       int
 }
 
+// efSearchFor returns the HNSW candidate list size for one search: the
+// override in opts when set, otherwise the configured value.
+func (h HNSWConfig) efSearchFor(opts FilterOptions) int {
+	if opts.EfSearch > 0 {
+		return opts.EfSearch
+	}
+	return h.EfSearch
+}
+
 // VecLiteBackend implements the database layer using VecLite with HNSW indexing.
 // All metadata is stored in vector payload - no SQLite needed.
 //
@@ -121,6 +243,25 @@ type VecLiteBackend struct {
 	hnsw       HNSWConfig
 	readOnly   bool
 	testHooks  *vecLiteBackendTestHooks
+	// quantization is set before Init; full holds the full-precision vectors
+	// of a quantized collection.
+	quantization Quantization
+	full         *fullVectorStore
+	// vectors, when set before Init, holds the chunks in an external store
+	// (Qdrant or pgvector) that serves vector search. The collection then
+	// keeps binary codes without an HNSW index, for keyword search and
+	// incremental indexing. root is the project root that store paths are
+	// relative to (see storeRoot), and storeMeta caches the store's
+	// metadata under collMu.
+	vectors   vectorStore
+	root      string
+	storeMeta map[string]any
+	// sealed, when set before Init, maps an encrypted index to its working
+	// copy; dbPath then points into the working directory.
+	sealed *sealedFiles
+	// hot, when set before Init, is searched exhaustively next to the
+	// vector index; nil turns the hot tier off.
+	hot *hotTier
 }
 
 // Lock order is storageMu, then collMu, then any VecLite DB/Collection lock.
@@ -217,17 +358,7 @@ func (b *VecLiteBackend) InitWithOptions(dimensions int, hnsw HNSWConfig, readOn
 
 	// Create or get collection with HNSW index.
 	// Use cosine distance which is standard for normalized embeddings.
-	coll, err := db.CreateCollection("chunks",
-		veclite.WithDimension(dimensions),
-		veclite.WithDistanceType(veclite.DistanceCosine),
-		veclite.WithHNSWConfig(veclite.HNSWConfig{
-			M:              hnsw.M,
-			EfConstruction: hnsw.EfConstruction,
-			EfSearch:       hnsw.EfSearch,
-			UseHeuristic:   true,
-		}),
-		veclite.WithTextIndex("content", "symbol_name", "relative_path", "language", "chunk_type"),
-	)
+	coll, err := db.CreateCollection("chunks", b.collectionOptions()...)
 	if err != nil {
 		// Collection might already exist, try to get it
 		coll, err = db.GetCollection("chunks")
@@ -254,15 +385,36 @@ func (b *VecLiteBackend) InitWithOptions(dimensions int, hnsw HNSWConfig, readOn
 	}
 	b.setCollections(coll, fileHashes)
 
+	if b.quantization != QuantizationNone {
+		full, err := openFullVectorStore(FullVectorsPath(filepath.Dir(b.dbPath)), dimensions, readOnly)
+		if err != nil {
+			return err
+		}
+		b.full = full
+	}
+	if b.vectors != nil {
+		if err := b.vectors.open(dimensions, hnsw, readOnly); err != nil {
+			return fmt.Errorf("open %s vector store: %w", b.vectors.Type(), err)
+		}
+		if err := b.loadStoreMetadata(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// Quantization returns how the collection stores vectors.
+func (b *VecLiteBackend) Quantization() Quantization {
+	return b.quantization
+}
+
 // HNSWConfig returns the active HNSW configuration.
 func (b *VecLiteBackend) HNSWConfig() HNSWConfig {
 	return b.hnsw
 }
 
-// SetMetadataValue stores a single metadata value on the chunks collection.
+// SetMetadataValue stores a single metadata value on the chunks collection,
+// and in the external vector store when there is one.
 func (b *VecLiteBackend) SetMetadataValue(key string, value any) error {
 	b.storageMu.Lock()
 	defer b.storageMu.Unlock()
@@ -270,21 +422,29 @@ func (b *VecLiteBackend) SetMetadataValue(key string, value any) error {
 	if coll == nil {
 		return fmt.Errorf("backend not initialized")
 	}
-	return coll.SetMetadataValue(key, value)
+	if err := coll.SetMetadataValue(key, value); err != nil {
+		return err
+	}
+	return b.putStoreMetadata(key, value)
 }
 
-// MetadataValue retrieves a single metadata value from the chunks collection.
-// It returns (nil, false) when the key is absent or the backend is unopened.
+// MetadataValue retrieves a single metadata value from the chunks collection,
+// falling back to the external vector store's for indexes another client
+// wrote. It returns (nil, false) when the key is absent or the backend is
+// unopened.
 func (b *VecLiteBackend) MetadataValue(key string) (any, bool) {
 	coll := b.collection()
 	if coll == nil {
 		return nil, false
 	}
-	v, ok := coll.Metadata()[key]
-	return v, ok
+	if v, ok := coll.Metadata()[key]; ok {
+		return v, true
+	}
+	return b.storeMetadataValue(key)
 }
 
-// DeleteMetadataValue removes a single metadata value from the chunks collection.
+// DeleteMetadataValue removes a single metadata value from the chunks
+// collection and the external vector store.
 func (b *VecLiteBackend) DeleteMetadataValue(key string) error {
 	b.storageMu.Lock()
 	defer b.storageMu.Unlock()
@@ -292,31 +452,39 @@ func (b *VecLiteBackend) DeleteMetadataValue(key string) error {
 	if coll == nil {
 		return fmt.Errorf("backend not initialized")
 	}
-	return coll.DeleteMetadataValue(key)
+	if err := coll.DeleteMetadataValue(key); err != nil {
+		return err
+	}
+	return b.putStoreMetadata(key, nil)
 }
 
-// collectionHNSWOptions returns the veclite collection options used by this backend.
+// collectionOptions returns the veclite collection options used by this backend.
 // Used by DeleteAll to recreate the collection with the same HNSW parameters.
+// Collections with an external vector store skip the HNSW index; the store
+// ranks their vectors.
 func (b *VecLiteBackend) collectionOptions() []veclite.CollectionOption {
-	return []veclite.CollectionOption{
+	opts := []veclite.CollectionOption{
 		veclite.WithDimension(b.dimensions),
 		veclite.WithDistanceType(veclite.DistanceCosine),
-		veclite.WithHNSWConfig(veclite.HNSWConfig{
+		veclite.WithTextIndex("content", "symbol_name", "relative_path", "language", "chunk_type", "search_terms"),
+	}
+	if b.vectors == nil {
+		opts = append(opts, veclite.WithHNSWConfig(veclite.HNSWConfig{
 			M:              b.hnsw.M,
 			EfConstruction: b.hnsw.EfConstruction,
 			EfSearch:       b.hnsw.EfSearch,
 			UseHeuristic:   true,
-		}),
-		veclite.WithTextIndex("content", "symbol_name", "relative_path", "language", "chunk_type"),
+		}))
 	}
+	return opts
 }
 
 // searchOptions builds the base search options (TopK + EfSearch) used by every
 // search call. Additional options (filters, weights) can be appended by callers.
-func (b *VecLiteBackend) searchOptions(limit int) []veclite.SearchOption {
+func (b *VecLiteBackend) searchOptions(limit int, filter FilterOptions) []veclite.SearchOption {
 	opts := []veclite.SearchOption{veclite.TopK(limit)}
-	if b.hnsw.EfSearch > 0 {
-		opts = append(opts, veclite.WithEfSearch(b.hnsw.EfSearch))
+	if ef := b.hnsw.efSearchFor(filter); ef > 0 {
+		opts = append(opts, veclite.WithEfSearch(ef))
 	}
 	return opts
 }
@@ -480,48 +648,25 @@ func (b *VecLiteBackend) InsertChunk(chunk ChunkRecord, embedding []float32) (ui
 		return 0, fmt.Errorf("embedding dimension mismatch: got %d, expected %d", len(embedding), b.dimensions)
 	}
 
-	// Generate unique chunk key for upsert operations
-	chunkKey := stableChunkKey(chunk)
-
-	payload := map[string]any{
-		// File info (denormalized)
-		"file_path":     chunk.FilePath,
-		"relative_path": chunk.RelativePath,
-		"file_hash":     chunk.FileHash,
-		"source_hash":   chunk.SourceHash,
-		"file_size":     chunk.FileSize,
-		"language":      chunk.Language,
-
-		// Chunk info
-		"content":     chunk.Content,
-		"start_line":  chunk.StartLine,
-		"end_line":    chunk.EndLine,
-		"start_byte":  chunk.StartByte,
-		"end_byte":    chunk.EndByte,
-		"chunk_index": chunk.ChunkIndex,
-		"chunk_type":  chunk.ChunkType,
-		"symbol_name": chunk.SymbolName,
-
-		// Unique key for upsert
-		"chunk_key": chunkKey,
-
-		// Project info
-		"project_root": chunk.ProjectRoot,
-		"indexed_at":   chunk.IndexedAt.Format(time.RFC3339),
-	}
+	payload := chunkPayload(chunk)
 
-	id, err := b.collection().Insert(embedding, payload)
+	id, err := b.collection().Insert(b.storedVector(embedding), payload)
 	if err != nil {
 		return 0, err
 	}
 	if b.testHooks != nil && b.testHooks.afterChunkInsert != nil {
 		b.testHooks.afterChunkInsert()
 	}
+	if err := b.putFullVectors([]uint64{id}, [][]float32{embedding}, []map[string]any{payload}); err != nil {
+		_ = b.collection().Delete(id)
+		return 0, err
+	}
 	if err := b.upsertFileHash(chunk); err != nil {
 		_ = b.collection().Delete(id)
 		b.invalidateFileHashes(chunk.ProjectRoot)
 		return 0, fmt.Errorf("store file hash: %w", err)
 	}
+	b.hot.noteWritten(b.collection(), []uint64{id}, []ChunkRecord{chunk})
 
 	return id, nil
 }
@@ -548,28 +693,9 @@ func (b *VecLiteBackend) InsertChunkBatch(chunks []ChunkRecord, embeddings [][]f
 			return nil, fmt.Errorf("embedding %d dimension mismatch: got %d, expected %d", i, len(embeddings[i]), b.dimensions)
 		}
 
-		vectors[i] = embeddings[i]
-
-		chunkKey := stableChunkKey(chunk)
-		payloads[i] = map[string]any{
-			"file_path":     chunk.FilePath,
-			"relative_path": chunk.RelativePath,
-			"file_hash":     chunk.FileHash,
-			"source_hash":   chunk.SourceHash,
-			"file_size":     chunk.FileSize,
-			"language":      chunk.Language,
-			"content":       chunk.Content,
-			"start_line":    chunk.StartLine,
-			"end_line":      chunk.EndLine,
-			"start_byte":    chunk.StartByte,
-			"end_byte":      chunk.EndByte,
-			"chunk_index":   chunk.ChunkIndex,
-			"chunk_type":    chunk.ChunkType,
-			"symbol_name":   chunk.SymbolName,
-			"chunk_key":     chunkKey,
-			"project_root":  chunk.ProjectRoot,
-			"indexed_at":    chunk.IndexedAt.Format(time.RFC3339),
-		}
+		vectors[i] = b.storedVector(embeddings[i])
+
+		payloads[i] = chunkPayload(chunk)
 		fileChunks[fileHashKey(chunk.ProjectRoot, chunk.RelativePath)] = chunk
 	}
 
@@ -578,6 +704,12 @@ func (b *VecLiteBackend) InsertChunkBatch(chunks []ChunkRecord, embeddings [][]f
 	if err != nil {
 		return nil, fmt.Errorf("batch insert failed: %w", err)
 	}
+	if err := b.putFullVectors(ids, embeddings, payloads); err != nil {
+		for _, id := range ids {
+			_ = b.collection().Delete(id)
+		}
+		return nil, err
+	}
 	for _, chunk := range fileChunks {
 		if err := b.upsertFileHash(chunk); err != nil {
 			for _, id := range ids {
@@ -587,6 +719,7 @@ func (b *VecLiteBackend) InsertChunkBatch(chunks []ChunkRecord, embeddings [][]f
 			return nil, fmt.Errorf("store file hash: %w", err)
 		}
 	}
+	b.hot.noteWritten(b.collection(), ids, chunks)
 
 	return ids, nil
 }
@@ -601,40 +734,194 @@ func (b *VecLiteBackend) UpsertChunk(chunk ChunkRecord, embedding []float32) (ui
 		return 0, false, fmt.Errorf("embedding dimension mismatch: got %d, expected %d", len(embedding), b.dimensions)
 	}
 
-	chunkKey := stableChunkKey(chunk)
-
-	payload := map[string]any{
-		"file_path":     chunk.FilePath,
-		"relative_path": chunk.RelativePath,
-		"file_hash":     chunk.FileHash,
-		"source_hash":   chunk.SourceHash,
-		"file_size":     chunk.FileSize,
-		"language":      chunk.Language,
-		"content":       chunk.Content,
-		"start_line":    chunk.StartLine,
-		"end_line":      chunk.EndLine,
-		"start_byte":    chunk.StartByte,
-		"end_byte":      chunk.EndByte,
-		"chunk_index":   chunk.ChunkIndex,
-		"chunk_type":    chunk.ChunkType,
-		"symbol_name":   chunk.SymbolName,
-		"chunk_key":     chunkKey,
-		"project_root":  chunk.ProjectRoot,
-		"indexed_at":    chunk.IndexedAt.Format(time.RFC3339),
-	}
+	payload := chunkPayload(chunk)
 
-	id, isNew, err := b.collection().UpsertByKey("chunk_key", chunkKey, embedding, payload)
+	id, isNew, err := b.collection().UpsertByKey("chunk_key", payload["chunk_key"], b.storedVector(embedding), payload)
 	if err != nil {
 		return 0, false, fmt.Errorf("upsert failed: %w", err)
 	}
+	if err := b.putFullVectors([]uint64{id}, [][]float32{embedding}, []map[string]any{payload}); err != nil {
+		return 0, false, err
+	}
 	if err := b.upsertFileHash(chunk); err != nil {
 		b.invalidateFileHashes(chunk.ProjectRoot)
 		return 0, false, fmt.Errorf("store file hash: %w", err)
 	}
+	b.hot.noteWritten(b.collection(), []uint64{id}, []ChunkRecord{chunk})
 
 	return id, isNew, nil
 }
 
+// UpsertBatchResult reports how UpsertChunkBatch applied a batch.
+type UpsertBatchResult struct {
+	// IDs holds the record ID for each input chunk, in input order. Chunks
+	// sharing a key share an ID.
+	IDs []uint64
+	// Inserted counts new records.
+	Inserted int
+	// Updated counts existing records whose vector changed.
+	Updated int
+	// Unchanged counts existing records whose vector matched; only their
+	// payload was refreshed, so their index entries were left alone.
+	Unchanged int
+	// Pruned counts stale records removed from replaced files.
+	Pruned int
+}
+
+// UpsertChunkBatch writes chunks keyed by their stable chunk key in a single
+// backend call. Duplicate keys within the batch collapse to the last
+// occurrence. Existing records are found with one lookup: an unchanged vector
+// only refreshes the payload, a changed vector is updated in place, and new
+// keys are written with one batch insert. When replaceFiles is set, the batch
+// is the complete chunk set of every file it touches and their other chunks
+// are removed, so a re-indexed file needs no delete beforehand.
+//
+// File hashes are written last. A failure part-way leaves the previous hash in
+// place, so the file still reads as modified and is retried by the next run.
+func (b *VecLiteBackend) UpsertChunkBatch(chunks []ChunkRecord, embeddings [][]float32, replaceFiles bool) (UpsertBatchResult, error) {
+	b.storageMu.Lock()
+	defer b.storageMu.Unlock()
+	if len(chunks) != len(embeddings) {
+		return UpsertBatchResult{}, fmt.Errorf("chunks and embeddings length mismatch: %d vs %d", len(chunks), len(embeddings))
+	}
+	if len(chunks) == 0 {
+		return UpsertBatchResult{}, nil
+	}
+
+	// Dedupe by key, keeping the last occurrence's data at the first
+	// occurrence's position so write order follows input order.
+	keys := make([]string, len(chunks))
+	slots := make(map[string]int, len(chunks))
+	var order []int
+	fileChunks := make(map[string]ChunkRecord)
+	roots := make(map[string]bool)
+	var paths []any
+	for i, chunk := range chunks {
+		if len(embeddings[i]) != b.dimensions {
+			return UpsertBatchResult{}, fmt.Errorf("embedding %d dimension mismatch: got %d, expected %d", i, len(embeddings[i]), b.dimensions)
+		}
+		keys[i] = stableChunkKey(chunk)
+		if _, seen := slots[keys[i]]; !seen {
+			order = append(order, i)
+		}
+		slots[keys[i]] = i
+		fileKey := fileHashKey(chunk.ProjectRoot, chunk.RelativePath)
+		if _, seen := fileChunks[fileKey]; !seen {
+			paths = append(paths, chunk.RelativePath)
+		}
+		fileChunks[fileKey] = chunk
+		roots[chunk.ProjectRoot] = true
+	}
+
+	// One lookup covers both key matching and, for replaced files, finding
+	// their stale chunks.
+	records, err := b.collection().Find(veclite.In("relative_path", paths...))
+	if err != nil {
+		return UpsertBatchResult{}, fmt.Errorf("find existing chunks: %w", err)
+	}
+	existing := make(map[string]*veclite.Record)
+	var stale []*veclite.Record
+	for _, record := range records {
+		root, _ := record.Payload["project_root"].(string)
+		relPath, _ := record.Payload["relative_path"].(string)
+		if _, ok := fileChunks[fileHashKey(root, relPath)]; !ok {
+			continue
+		}
+		key, _ := record.Payload["chunk_key"].(string)
+		if _, ok := slots[key]; ok && existing[key] == nil {
+			existing[key] = record
+			continue
+		}
+		if replaceFiles {
+			stale = append(stale, record)
+		}
+	}
+
+	result := UpsertBatchResult{IDs: make([]uint64, len(chunks))}
+	ids := make(map[string]uint64, len(slots))
+	var newVectors, newEmbeddings [][]float32
+	var newPayloads []map[string]any
+	var newKeys []string
+	for _, first := range order {
+		i := slots[keys[first]]
+		record := existing[keys[i]]
+		if record == nil {
+			newVectors = append(newVectors, b.storedVector(embeddings[i]))
+			newEmbeddings = append(newEmbeddings, embeddings[i])
+			newPayloads = append(newPayloads, chunkPayload(chunks[i]))
+			newKeys = append(newKeys, keys[i])
+		}
+	}
+	if len(newVectors) > 0 {
+		newIDs, err := b.collection().InsertBatch(newVectors, newPayloads)
+		if err != nil {
+			return UpsertBatchResult{}, fmt.Errorf("batch insert failed: %w", err)
+		}
+		if err := b.putFullVectors(newIDs, newEmbeddings, newPayloads); err != nil {
+			return UpsertBatchResult{}, err
+		}
+		for j, id := range newIDs {
+			ids[newKeys[j]] = id
+		}
+		result.Inserted = len(newIDs)
+	}
+
+	var keptIDs []uint64
+	var keptEmbeddings [][]float32
+	var keptPayloads []map[string]any
+	for _, first := range order {
+		i := slots[keys[first]]
+		record := existing[keys[i]]
+		if record == nil {
+			continue
+		}
+		payload := chunkPayload(chunks[i])
+		if vector := b.storedVector(embeddings[i]); !slices.Equal(record.Vector, vector) {
+			if err := b.collection().UpdateVector(record.ID, vector); err != nil {
+				return result, fmt.Errorf("update chunk vector: %w", err)
+			}
+			result.Updated++
+		} else {
+			result.Unchanged++
+		}
+		// Codes can match while the full-precision vector moved.
+		keptIDs = append(keptIDs, record.ID)
+		keptEmbeddings = append(keptEmbeddings, embeddings[i])
+		keptPayloads = append(keptPayloads, payload)
+		if err := b.collection().Update(record.ID, payload); err != nil {
+			return result, fmt.Errorf("update chunk payload: %w", err)
+		}
+		ids[keys[i]] = record.ID
+	}
+	if err := b.putFullVectors(keptIDs, keptEmbeddings, keptPayloads); err != nil {
+		return result, err
+	}
+	for i, key := range keys {
+		result.IDs[i] = ids[key]
+	}
+	b.hot.noteWritten(b.collection(), result.IDs, chunks)
+
+	for _, record := range stale {
+		if err := b.collection().Delete(record.ID); err != nil {
+			return result, fmt.Errorf("prune stale chunk: %w", err)
+		}
+		result.Pruned++
+	}
+	if err := b.dropVectors(stale); err != nil {
+		return result, err
+	}
+
+	for _, chunk := range fileChunks {
+		if err := b.upsertFileHash(chunk); err != nil {
+			for root := range roots {
+				b.invalidateFileHashes(root)
+			}
+			return result, fmt.Errorf("store file hash: %w", err)
+		}
+	}
+	return result, nil
+}
+
 // InsertEmbedding inserts an embedding for a chunk (legacy compatibility).
 // Deprecated: Use InsertChunk instead for full metadata storage.
 func (b *VecLiteBackend) InsertEmbedding(chunkID int64, embedding []float32) error {
@@ -645,15 +932,18 @@ func (b *VecLiteBackend) InsertEmbedding(chunkID int64, embedding []float32) err
 	}
 
 	// Legacy mode: store with minimal payload
-	_, err := b.collection().Insert(embedding, map[string]any{"chunk_id": chunkID})
-	return err
+	id, err := b.collection().Insert(b.storedVector(embedding), map[string]any{"chunk_id": chunkID})
+	if err != nil {
+		return err
+	}
+	return b.putFullVectors([]uint64{id}, [][]float32{embedding}, nil)
 }
 
 // DeleteEmbedding removes an embedding for a chunk (legacy compatibility).
 func (b *VecLiteBackend) DeleteEmbedding(chunkID int64) error {
 	b.storageMu.Lock()
 	defer b.storageMu.Unlock()
-	_, err := b.collection().DeleteWhere(veclite.Equal("chunk_id", chunkID))
+	_, err := b.deleteChunksWhere(veclite.Equal("chunk_id", chunkID))
 	return err
 }
 
@@ -664,7 +954,7 @@ func (b *VecLiteBackend) DeleteByFilePath(filePath string) (int64, error) {
 
 	// Modern records are keyed by canonical project-relative paths. Only scan
 	// the legacy absolute-path field when no canonical record matched.
-	deleted, err := b.collection().DeleteWhere(veclite.Equal("relative_path", filePath))
+	deleted, err := b.deleteChunksWhere(veclite.Equal("relative_path", filePath))
 	if err != nil {
 		return int64(deleted), err
 	}
@@ -677,7 +967,7 @@ func (b *VecLiteBackend) DeleteByFilePath(filePath string) (int64, error) {
 		return int64(deleted), nil
 	}
 
-	deleted, err = b.collection().DeleteWhere(veclite.Equal("file_path", filePath))
+	deleted, err = b.deleteChunksWhere(veclite.Equal("file_path", filePath))
 	if err != nil {
 		return int64(deleted), err
 	}
@@ -697,20 +987,21 @@ func (b *VecLiteBackend) DeleteByFilePath(filePath string) (int64, error) {
 // makes GetSourceHashes report incomplete instead of trusting stale metadata.
 // A clean operation removes only its own tombstone after both deletes succeed;
 // a tombstone that predated the operation is never cleared by a file-scoped
-// delete and remains authoritative until a full project reset/reindex.
-func (b *VecLiteBackend) DeleteByProjectFile(projectRoot, filePath string) (int64, error) {
+// delete and remains authoritative until a full project reset/reindex. The
+// returned bool reports whether any chunk or file hash record matched.
+func (b *VecLiteBackend) DeleteByProjectFile(projectRoot, filePath string) (int64, bool, error) {
 	if projectRoot == "" {
-		return 0, fmt.Errorf("project root is required")
+		return 0, false, fmt.Errorf("project root is required")
 	}
 	if filePath == "" {
-		return 0, fmt.Errorf("file path is required")
+		return 0, false, fmt.Errorf("file path is required")
 	}
 
 	b.storageMu.Lock()
 	defer b.storageMu.Unlock()
 	wasDirty := b.fileHashesDirty(projectRoot)
 	if err := b.markFileHashesDirty(projectRoot); err != nil {
-		return 0, fmt.Errorf("mark project file hashes dirty: %w", err)
+		return 0, false, fmt.Errorf("mark project file hashes dirty: %w", err)
 	}
 
 	chunkFilters := []veclite.Filter{
@@ -722,51 +1013,108 @@ func (b *VecLiteBackend) DeleteByProjectFile(projectRoot, filePath string) (int6
 		veclite.Equal("project_root", projectRoot),
 		veclite.Equal("relative_path", filePath),
 	}
-	deleted, err := b.collection().DeleteWhere(chunkFilters...)
+	deleted, err := b.deleteChunksWhere(chunkFilters...)
 	if err != nil {
-		return int64(deleted), fmt.Errorf("delete project file chunks: %w", err)
+		return int64(deleted), deleted > 0, fmt.Errorf("delete project file chunks: %w", err)
 	}
 	if b.testHooks != nil && b.testHooks.beforeProjectHashDelete != nil {
 		if err := b.testHooks.beforeProjectHashDelete(); err != nil {
-			return int64(deleted), err
+			return int64(deleted), deleted > 0, err
 		}
 	}
 	hashDeleted := 0
 	if coll := b.fileHashCollection(); coll != nil {
 		hashDeleted, err = coll.DeleteWhere(hashFilters...)
 		if err != nil {
-			return int64(deleted), fmt.Errorf("delete project file hash: %w", err)
+			return int64(deleted), deleted > 0, fmt.Errorf("delete project file hash: %w", err)
 		}
 	}
 	if deleted > 0 || hashDeleted > 0 {
 		if err := b.finishProjectFileDelete(projectRoot, wasDirty); err != nil {
-			return int64(deleted), err
+			return int64(deleted), true, err
 		}
-		return int64(deleted), nil
+		return int64(deleted), true, nil
 	}
 
-	// Legacy records may only carry their absolute file_path. Keep the project
-	// predicate so the compatibility lookup cannot cross project boundaries.
-	deleted, err = b.collection().DeleteWhere(
+	// Legacy records may only carry their absolute file_path, so match both
+	// the path as given and its absolute form. Keep the project predicate so
+	// the compatibility lookup cannot cross project boundaries.
+	legacyPaths := []any{filePath}
+	if !filepath.IsAbs(filePath) {
+		legacyPaths = append(legacyPaths, filepath.Join(projectRoot, filepath.FromSlash(filePath)))
+	}
+	deleted, err = b.deleteChunksWhere(
 		veclite.Equal("project_root", projectRoot),
-		veclite.Equal("file_path", filePath),
+		veclite.In("file_path", legacyPaths...),
 	)
 	if err != nil {
-		return int64(deleted), fmt.Errorf("delete legacy project file chunks: %w", err)
+		return int64(deleted), deleted > 0, fmt.Errorf("delete legacy project file chunks: %w", err)
 	}
+	hashDeleted = 0
 	if coll := b.fileHashCollection(); coll != nil {
-		if _, err := coll.DeleteWhere(
+		hashDeleted, err = coll.DeleteWhere(
 			veclite.Equal(fileHashRecordField, fileHashRecordType),
 			veclite.Equal("project_root", projectRoot),
-			veclite.Equal("file_path", filePath),
-		); err != nil {
-			return int64(deleted), fmt.Errorf("delete legacy project file hash: %w", err)
+			veclite.In("file_path", legacyPaths...),
+		)
+		if err != nil {
+			return int64(deleted), deleted > 0, fmt.Errorf("delete legacy project file hash: %w", err)
 		}
 	}
+	matched := deleted > 0 || hashDeleted > 0
 	if err := b.finishProjectFileDelete(projectRoot, wasDirty); err != nil {
-		return int64(deleted), err
+		return int64(deleted), matched, err
 	}
-	return int64(deleted), nil
+	return int64(deleted), matched, nil
+}
+
+// CountByProjectFile reports what DeleteByProjectFile would remove without
+// changing either collection: the number of matching chunks and whether any
+// chunk or file hash record matched. It applies the same legacy absolute-path
+// fallback.
+func (b *VecLiteBackend) CountByProjectFile(projectRoot, filePath string) (int64, bool, error) {
+	if projectRoot == "" {
+		return 0, false, fmt.Errorf("project root is required")
+	}
+	if filePath == "" {
+		return 0, false, fmt.Errorf("file path is required")
+	}
+
+	count := func(pathField string, paths ...any) (int64, bool, error) {
+		chunks, err := b.collection().Find(
+			veclite.Equal("project_root", projectRoot),
+			veclite.In(pathField, paths...),
+		)
+		if err != nil {
+			return 0, false, fmt.Errorf("find project file chunks: %w", err)
+		}
+		if len(chunks) > 0 {
+			return int64(len(chunks)), true, nil
+		}
+		coll := b.fileHashCollection()
+		if coll == nil {
+			return 0, false, nil
+		}
+		hashes, err := coll.Find(
+			veclite.Equal(fileHashRecordField, fileHashRecordType),
+			veclite.Equal("project_root", projectRoot),
+			veclite.In(pathField, paths...),
+		)
+		if err != nil {
+			return 0, false, fmt.Errorf("find project file hash: %w", err)
+		}
+		return 0, len(hashes) > 0, nil
+	}
+
+	chunks, matched, err := count("relative_path", filePath)
+	if err != nil || matched {
+		return chunks, matched, err
+	}
+	legacyPaths := []any{filePath}
+	if !filepath.IsAbs(filePath) {
+		legacyPaths = append(legacyPaths, filepath.Join(projectRoot, filepath.FromSlash(filePath)))
+	}
+	return count("file_path", legacyPaths...)
 }
 
 // DeleteByProjectRoot removes all chunks for a project.
@@ -781,10 +1129,16 @@ func (b *VecLiteBackend) DeleteByProjectRoot(projectRoot string) (int64, error)
 		return 0, fmt.Errorf("mark project file hashes dirty: %w", err)
 	}
 
-	deleted, err := b.collection().DeleteWhere(veclite.Equal("project_root", projectRoot))
+	deleted, err := b.deleteChunksWhere(veclite.Equal("project_root", projectRoot))
 	if err != nil {
 		return int64(deleted), fmt.Errorf("delete project chunks: %w", err)
 	}
+	// Other clients may have written points this collection never held.
+	if b.vectors != nil {
+		if err := b.vectors.deleteProject(b.storeRoot(projectRoot)); err != nil {
+			return int64(deleted), fmt.Errorf("delete project chunks: %w", err)
+		}
+	}
 	if b.testHooks != nil && b.testHooks.beforeProjectHashDelete != nil {
 		if err := b.testHooks.beforeProjectHashDelete(); err != nil {
 			return int64(deleted), err
@@ -1012,9 +1366,72 @@ func (b *VecLiteBackend) GetChunksByFile(filePath string) ([]ChunkRecord, error)
 
 	chunks := make([]ChunkRecord, 0, len(records))
 	for _, r := range records {
-		chunks = append(chunks, recordToChunk(r))
+		chunks = append(chunks, b.chunkFromRecord(r))
+	}
+	if err := b.hydrateVectors(chunks, records); err != nil {
+		return nil, err
+	}
+
+	return chunks, nil
+}
+
+// GetChunkParent returns the chunk enclosing chunkID in its file, or nil
+// when the chunk is top-level (its parent is the file itself).
+func (b *VecLiteBackend) GetChunkParent(chunkID int64) (*ChunkRecord, error) {
+	chunk, err := b.GetChunkByID(chunkID)
+	if err != nil {
+		return nil, err
+	}
+	if chunk.ParentIndex < 0 {
+		return nil, nil
+	}
+	siblings, err := b.fileChunks(chunk)
+	if err != nil {
+		return nil, err
+	}
+	for i := range siblings {
+		if siblings[i].ChunkIndex == chunk.ParentIndex {
+			return &siblings[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// GetChunkChildren returns the chunks directly enclosed by chunkID, in file
+// order. Pass the result's IDs back in to walk further down the tree.
+func (b *VecLiteBackend) GetChunkChildren(chunkID int64) ([]ChunkRecord, error) {
+	chunk, err := b.GetChunkByID(chunkID)
+	if err != nil {
+		return nil, err
+	}
+	siblings, err := b.fileChunks(chunk)
+	if err != nil {
+		return nil, err
 	}
+	var children []ChunkRecord
+	for _, sibling := range siblings {
+		if sibling.ID != chunk.ID && sibling.ParentIndex == chunk.ChunkIndex {
+			children = append(children, sibling)
+		}
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].ChunkIndex < children[j].ChunkIndex })
+	return children, nil
+}
 
+// fileChunks returns the chunks stored for the same file, project, and ref
+// as chunk, which are the only chunks its parent links can point to.
+func (b *VecLiteBackend) fileChunks(chunk *ChunkRecord) ([]ChunkRecord, error) {
+	records, err := b.collection().Find(veclite.Equal("file_path", chunk.FilePath))
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]ChunkRecord, 0, len(records))
+	for _, r := range records {
+		sibling := b.chunkFromRecord(r)
+		if sibling.ProjectRoot == chunk.ProjectRoot && sibling.Ref == chunk.Ref {
+			chunks = append(chunks, sibling)
+		}
+	}
 	return chunks, nil
 }
 
@@ -1061,14 +1478,14 @@ func (b *VecLiteBackend) SearchEmbeddings(queryEmbedding []float32, limit int) (
 		return nil, fmt.Errorf("query embedding dimension mismatch: got %d, expected %d", len(queryEmbedding), b.dimensions)
 	}
 
-	results, err := b.collection().Search(queryEmbedding, b.searchOptions(limit)...)
+	results, err := b.vectorSearch(queryEmbedding, limit, FilterOptions{}, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	searchResults := make([]SearchResult, 0, len(results))
 	for _, r := range results {
-		chunk := recordToChunk(r.Record)
+		chunk := b.chunkFromRecord(r.Record)
 		sr := SearchResult{
 			ChunkID:  int64(r.Record.ID),
 			Distance: r.Score,
@@ -1090,13 +1507,19 @@ func (b *VecLiteBackend) SearchEmbeddings(queryEmbedding []float32, limit int) (
 // GetChunkByID retrieves a full chunk record by its vector ID.
 func (b *VecLiteBackend) GetChunkByID(chunkID int64) (*ChunkRecord, error) {
 	record, err := b.collection().Get(uint64(chunkID))
+	if err != nil || record == nil {
+		// Chunks written by other clients of a shared vector store.
+		if stored := b.storeRecordByID(uint64(chunkID)); stored != nil {
+			record, err = stored, nil
+		}
+	}
 	if err != nil {
 		return nil, fmt.Errorf("chunk not found for ID %d: %w", chunkID, err)
 	}
 	if record == nil {
 		return nil, fmt.Errorf("chunk not found for ID %d", chunkID)
 	}
-	chunk := recordToChunk(record)
+	chunk := b.chunkFromRecord(record)
 	return &chunk, nil
 }
 
@@ -1105,6 +1528,9 @@ func (b *VecLiteBackend) GetEmbedding(chunkID int64) ([]float32, error) {
 	// First try by record ID
 	record, err := b.collection().Get(uint64(chunkID))
 	if err == nil && record != nil {
+		if v, ok := b.fullVector(record); ok {
+			return v, nil
+		}
 		return record.Vector, nil
 	}
 
@@ -1115,9 +1541,18 @@ func (b *VecLiteBackend) GetEmbedding(chunkID int64) ([]float32, error) {
 	}
 
 	if len(records) == 0 {
+		// Chunks written by other clients of a shared vector store.
+		if record := b.storeRecordByID(uint64(chunkID)); record != nil {
+			if v, ok := b.fullVector(record); ok {
+				return v, nil
+			}
+		}
 		return nil, fmt.Errorf("embedding not found for chunk %d", chunkID)
 	}
 
+	if v, ok := b.fullVector(records[0]); ok {
+		return v, nil
+	}
 	return records[0].Vector, nil
 }
 
@@ -1129,11 +1564,20 @@ func (b *VecLiteBackend) Count() (int64, error) {
 // GetStats returns comprehensive statistics about the index.
 func (b *VecLiteBackend) GetStats(projectRoot string) (*Stats, error) {
 	stats := &Stats{
-		Languages:  make(map[string]int64),
-		ChunkTypes: make(map[string]int64),
+		Languages:     make(map[string]int64),
+		ChunkTypes:    make(map[string]int64),
+		LanguageLines: make(map[string]int64),
+		LanguageBytes: make(map[string]int64),
 	}
 
-	filesSet := make(map[string]bool)
+	// Chunks overlap and repeat per-file metadata, so volume is tracked once
+	// per file: its language, size, and the last line any chunk covers.
+	type fileVolume struct {
+		language string
+		bytes    int64
+		lines    int64
+	}
+	filesSet := make(map[string]*fileVolume)
 	projectsSet := make(map[string]bool)
 
 	// Push the project_root filter down to veclite when a specific project is
@@ -1154,16 +1598,25 @@ func (b *VecLiteBackend) GetStats(projectRoot string) (*Stats, error) {
 
 		stats.TotalChunks++
 
+		lang := getStringPayload(r.Payload, "language")
 		relPath := getStringPayload(r.Payload, "relative_path")
-		if relPath != "" {
-			filesSet[root+":"+relPath] = true
+		if relPath == "" {
+			stats.OrphanedChunks++
+		} else {
+			key := root + ":" + relPath
+			file := filesSet[key]
+			if file == nil {
+				file = &fileVolume{language: lang}
+				filesSet[key] = file
+			}
+			file.bytes = max(file.bytes, getInt64Payload(r.Payload, "file_size"))
+			file.lines = max(file.lines, getInt64Payload(r.Payload, "end_line"))
 		}
 
 		if root != "" {
 			projectsSet[root] = true
 		}
 
-		lang := getStringPayload(r.Payload, "language")
 		if lang != "" {
 			stats.Languages[lang]++
 		}
@@ -1172,20 +1625,57 @@ func (b *VecLiteBackend) GetStats(projectRoot string) (*Stats, error) {
 		if chunkType != "" {
 			stats.ChunkTypes[chunkType]++
 		}
+		if getBoolPayload(r.Payload, "truncated") {
+			stats.TruncatedChunks++
+			if getBoolPayload(r.Payload, "truncation_estimated") {
+				stats.EstimatedTruncatedChunks++
+			}
+		}
+	}
+
+	for _, file := range filesSet {
+		if file.language == "" {
+			continue
+		}
+		stats.LanguageLines[file.language] += file.lines
+		stats.LanguageBytes[file.language] += file.bytes
 	}
 
 	stats.TotalFiles = int64(len(filesSet))
 	stats.TotalProjects = int64(len(projectsSet))
 
+	// A client that did not index the project still searches the chunks
+	// other clients wrote to a shared vector store.
+	if projectRoot != "" && stats.TotalChunks == 0 && b.vectors != nil {
+		shared, err := b.vectors.countProject(b.storeRoot(projectRoot))
+		if err != nil {
+			return nil, err
+		}
+		if shared > 0 {
+			stats.TotalChunks = shared
+			stats.TotalProjects = 1
+		}
+	}
+
 	return stats, nil
 }
 
 // DeleteAll removes all embeddings by recreating the collection.
-// This ensures the HNSW index is properly reset.
+// This ensures the HNSW index is properly reset. An external vector store is
+// emptied as well.
 func (b *VecLiteBackend) DeleteAll() error {
 	b.storageMu.Lock()
 	defer b.storageMu.Unlock()
-	return b.recreateCollections()
+	if err := b.recreateCollections(); err != nil {
+		return err
+	}
+	if b.vectors != nil {
+		b.collMu.Lock()
+		b.storeMeta = nil
+		b.collMu.Unlock()
+		return b.vectors.DeleteAll()
+	}
+	return nil
 }
 
 func (b *VecLiteBackend) recreateCollections() error {
@@ -1208,7 +1698,9 @@ func (b *VecLiteBackend) recreateCollections() error {
 		return fmt.Errorf("initialize file hashes collection: %w", err)
 	}
 	b.setCollections(coll, fileHashes)
-	return nil
+	// The new collection numbers records from 1 again. External stores key
+	// points by chunk, so they need no reset.
+	return b.full.truncate()
 }
 
 // DeleteOrphaned removes embeddings that don't have corresponding chunks.
@@ -1229,7 +1721,7 @@ func (b *VecLiteBackend) DeleteOrphaned(validChunkIDs []int64) (int64, error) {
 		return 0, fmt.Errorf("find legacy records for orphan cleanup: %w", err)
 	}
 
-	var deleted int64
+	var removed []*veclite.Record
 	for _, r := range legacyRecords {
 		chunkID := getInt64Payload(r.Payload, "chunk_id")
 		if chunkID == 0 {
@@ -1238,12 +1730,12 @@ func (b *VecLiteBackend) DeleteOrphaned(validChunkIDs []int64) (int64, error) {
 
 		if !validMap[chunkID] {
 			if err := b.collection().Delete(r.ID); err == nil {
-				deleted++
+				removed = append(removed, r)
 			}
 		}
 	}
 
-	return deleted, nil
+	return int64(len(removed)), b.dropVectors(removed)
 }
 
 // Sync persists any pending changes.
@@ -1253,17 +1745,35 @@ func (b *VecLiteBackend) Sync() error {
 	if b.testHooks != nil && b.testHooks.syncLocked != nil {
 		b.testHooks.syncLocked()
 	}
-	return b.db.Sync()
+	// Full-precision rows go first so a synced record has its row.
+	if err := b.full.sync(); err != nil {
+		return fmt.Errorf("sync full-precision vectors: %w", err)
+	}
+	if err := b.db.Sync(); err != nil {
+		return err
+	}
+	if b.sealed != nil {
+		return b.sealed.seal()
+	}
+	return nil
 }
 
 // Close closes the VecLite database.
 func (b *VecLiteBackend) Close() error {
 	b.storageMu.Lock()
 	defer b.storageMu.Unlock()
+	fullErr := b.full.close()
+	if b.vectors != nil {
+		fullErr = errors.Join(fullErr, b.vectors.Close())
+	}
 	if b.db != nil {
-		return b.db.Close()
+		fullErr = errors.Join(b.db.Close(), fullErr)
 	}
-	return nil
+	if b.sealed != nil {
+		fullErr = errors.Join(fullErr, b.sealed.close())
+		b.sealed = nil
+	}
+	return fullErr
 }
 
 // Reload re-reads the database from disk, rebuilding all in-memory state
@@ -1284,6 +1794,11 @@ func (b *VecLiteBackend) Reload() error {
 	if b.db == nil {
 		return fmt.Errorf("backend not initialized")
 	}
+	if b.sealed != nil {
+		if err := b.sealed.unseal(); err != nil {
+			return err
+		}
+	}
 	if err := b.db.Reload(); err != nil {
 		return err
 	}
@@ -1296,14 +1811,38 @@ func (b *VecLiteBackend) Reload() error {
 		fileHashes = nil
 	}
 	b.setCollections(coll, fileHashes)
+	if b.vectors != nil && b.vectors.isMissing() {
+		// The writer may have created the vector store since.
+		if err := b.vectors.open(b.dimensions, b.hnsw, b.readOnly); err != nil {
+			return fmt.Errorf("reload: open %s vector store: %w", b.vectors.Type(), err)
+		}
+	}
+	if b.vectors != nil {
+		if err := b.loadStoreMetadata(); err != nil {
+			return fmt.Errorf("reload: %w", err)
+		}
+	}
 	return nil
 }
 
-// Type returns "veclite".
+// Type returns "veclite", or the type of the external store holding the
+// vectors ("qdrant" or "pgvector").
 func (b *VecLiteBackend) Type() string {
+	if b.vectors != nil {
+		return b.vectors.Type()
+	}
 	return string(VectorBackendVecLite)
 }
 
+// VectorLocation describes the external store holding the vectors, or
+// returns "" when they live in the VecLite file.
+func (b *VecLiteBackend) VectorLocation() string {
+	if b.vectors == nil {
+		return ""
+	}
+	return b.vectors.Location()
+}
+
 // Dimensions returns the embedding dimensions.
 func (b *VecLiteBackend) Dimensions() int {
 	return b.dimensions
@@ -1325,6 +1864,19 @@ func getStringPayload(payload map[string]any, key string) string {
 	return ""
 }
 
+// splitListPayload reads a comma-joined list payload.
+func splitListPayload(payload map[string]any, key string) []string {
+	if s := getStringPayload(payload, key); s != "" {
+		return strings.Split(s, ",")
+	}
+	return nil
+}
+
+func getBoolPayload(payload map[string]any, key string) bool {
+	b, _ := payload[key].(bool)
+	return b
+}
+
 func getInt64Payload(payload map[string]any, key string) int64 {
 	if v, ok := payload[key]; ok {
 		switch n := v.(type) {
@@ -1343,6 +1895,15 @@ func getIntPayload(payload map[string]any, key string) int {
 	return int(getInt64Payload(payload, key))
 }
 
+// parentIndexPayload reads a chunk's parent_index, which is absent for
+// top-level chunks and for chunks indexed before parents were recorded.
+func parentIndexPayload(payload map[string]any) int {
+	if _, ok := payload["parent_index"]; !ok {
+		return -1
+	}
+	return getIntPayload(payload, "parent_index")
+}
+
 func recordToChunk(r *veclite.Record) ChunkRecord {
 	indexedAt := time.Now()
 	if ts := getStringPayload(r.Payload, "indexed_at"); ts != "" {
@@ -1365,12 +1926,75 @@ func recordToChunk(r *veclite.Record) ChunkRecord {
 		StartByte:    getIntPayload(r.Payload, "start_byte"),
 		EndByte:      getIntPayload(r.Payload, "end_byte"),
 		ChunkIndex:   getIntPayload(r.Payload, "chunk_index"),
+		ParentIndex:  parentIndexPayload(r.Payload),
 		ChunkType:    getStringPayload(r.Payload, "chunk_type"),
 		SymbolName:   getStringPayload(r.Payload, "symbol_name"),
 		ProjectRoot:  getStringPayload(r.Payload, "project_root"),
+		Ref:          getStringPayload(r.Payload, "ref"),
 		IndexedAt:    indexedAt,
 		Vector:       r.Vector,
+
+		IndexerVersion:      getStringPayload(r.Payload, "indexer_version"),
+		ChunkStrategy:       getStringPayload(r.Payload, "chunk_strategy"),
+		ChunkParams:         getStringPayload(r.Payload, "chunk_params"),
+		EmbedHash:           getStringPayload(r.Payload, "embed_hash"),
+		EmbedVersion:        getStringPayload(r.Payload, "embed_version"),
+		Truncated:           getBoolPayload(r.Payload, "truncated"),
+		Secrets:             splitListPayload(r.Payload, "secrets"),
+		TruncationEstimated: getBoolPayload(r.Payload, "truncation_estimated"),
+		Tags:                splitListPayload(r.Payload, "tags"),
+	}
+}
+
+// SymbolRecord is one named chunk found by FindSymbols.
+type SymbolRecord struct {
+	ChunkID      int64
+	Name         string
+	ChunkType    string
+	Language     string
+	RelativePath string
+	StartLine    int
+	EndLine      int
+	ProjectRoot  string
+}
+
+// FindSymbols returns chunks whose symbol name matches pattern (see
+// symbolFilter) and the remaining filters in opts, ordered by name, path, and
+// line. A positive limit caps the result.
+func (b *VecLiteBackend) FindSymbols(pattern string, limit int, opts FilterOptions) ([]SymbolRecord, error) {
+	opts.Symbol = pattern
+	records, err := b.collection().Find(b.buildNativeFilters(opts)...)
+	if err != nil {
+		return nil, fmt.Errorf("find symbols: %w", err)
 	}
+	symbols := make([]SymbolRecord, 0, len(records))
+	for _, r := range records {
+		chunk := recordToChunk(r)
+		symbols = append(symbols, SymbolRecord{
+			ChunkID:      int64(chunk.ID),
+			Name:         chunk.SymbolName,
+			ChunkType:    chunk.ChunkType,
+			Language:     chunk.Language,
+			RelativePath: chunk.RelativePath,
+			StartLine:    chunk.StartLine,
+			EndLine:      chunk.EndLine,
+			ProjectRoot:  chunk.ProjectRoot,
+		})
+	}
+	sort.Slice(symbols, func(i, j int) bool {
+		a, c := symbols[i], symbols[j]
+		if a.Name != c.Name {
+			return a.Name < c.Name
+		}
+		if a.RelativePath != c.RelativePath {
+			return a.RelativePath < c.RelativePath
+		}
+		return a.StartLine < c.StartLine
+	})
+	if limit > 0 && len(symbols) > limit {
+		symbols = symbols[:limit]
+	}
+	return symbols, nil
 }
 
 // ListFiles returns all unique files in the index for a project.
@@ -1414,6 +2038,11 @@ func (b *VecLiteBackend) ListFiles(projectRoot string) ([]FileInfo, error) {
 				Size:         getInt64Payload(r.Payload, "file_size"),
 				Language:     getStringPayload(r.Payload, "language"),
 				IndexedAt:    indexedAt,
+
+				IndexerVersion: getStringPayload(r.Payload, "indexer_version"),
+				ChunkStrategy:  getStringPayload(r.Payload, "chunk_strategy"),
+				ChunkParams:    getStringPayload(r.Payload, "chunk_params"),
+				EmbedVersion:   getStringPayload(r.Payload, "embed_version"),
 			}
 		}
 	}
@@ -1449,10 +2078,79 @@ type FilterOptions struct {
 	ChunkTypes  []string // Filter by multiple chunk types (OR)
 	FilePattern string   // Filter by file pattern (glob)
 	Directory   string   // Filter by directory prefix
+	Directories []string // Filter by any of several directory prefixes (OR)
 	FilePaths   []string // Filter by an allow-list of relative paths (OR). Used for blast-radius scoping.
 	MinLine     int      // Filter by minimum start line (0 = no filter)
 	MaxLine     int      // Filter by maximum start line (0 = no filter)
 	ProjectRoot string   // Filter by project root
+	// ProjectRoots filters by any of several project roots and takes
+	// precedence over ProjectRoot.
+	ProjectRoots []string
+	Symbol       string // Filter by symbol name (case-insensitive; glob or exact, see symbolFilter)
+	// Tags keeps chunks carrying any of these indexing.tags labels (OR,
+	// case-insensitive).
+	Tags []string
+	// ExcludeIDs and ExcludePaths leave out chunks by ID or by relative
+	// path, so a search that skips its own source still fills its limit.
+	ExcludeIDs   []int64
+	ExcludePaths []string
+	// EfSearch overrides the HNSW candidate list size for this search only
+	// (0 = the index's ef_search). Larger values trade latency for recall.
+	EfSearch int
+}
+
+// symbolFilter matches chunks whose symbol_name matches pattern, ignoring
+// case. A pattern with glob characters must match the whole name or the part
+// after its last dot, so "Search*" finds both "Searcher" and
+// "Searcher.Search"; a plain pattern must equal one of those exactly.
+func symbolFilter(pattern string) veclite.Filter {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	isGlob := strings.ContainsAny(pattern, "*?[")
+	return veclite.FilterFunc(func(r *veclite.Record) bool {
+		name := strings.ToLower(getStringPayload(r.Payload, "symbol_name"))
+		if name == "" {
+			return false
+		}
+		short := name[strings.LastIndex(name, ".")+1:]
+		if !isGlob {
+			return name == pattern || short == pattern
+		}
+		for _, candidate := range []string{name, short} {
+			if matched, _ := path.Match(pattern, candidate); matched {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// tagFilter matches chunks whose comma-joined tags payload holds any of
+// tags, ignoring case.
+func tagFilter(tags []string) veclite.Filter {
+	want := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		want[strings.ToLower(strings.TrimSpace(tag))] = true
+	}
+	return veclite.FilterFunc(func(r *veclite.Record) bool {
+		for _, tag := range splitListPayload(r.Payload, "tags") {
+			if want[strings.ToLower(tag)] {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// excludeFilter rejects chunks whose ID (or legacy chunk_id) is in ids or
+// whose relative path is in paths.
+func excludeFilter(ids []int64, paths []string) veclite.Filter {
+	return veclite.FilterFunc(func(r *veclite.Record) bool {
+		id := getInt64Payload(r.Payload, "chunk_id")
+		if id == 0 {
+			id = int64(r.ID)
+		}
+		return !slices.Contains(ids, id) && !slices.Contains(paths, getStringPayload(r.Payload, "relative_path"))
+	})
 }
 
 // buildNativeFilters converts FilterOptions to veclite native filters.
@@ -1460,7 +2158,13 @@ func (b *VecLiteBackend) buildNativeFilters(opts FilterOptions) []veclite.Filter
 	var filters []veclite.Filter
 
 	// Project filter
-	if opts.ProjectRoot != "" {
+	if len(opts.ProjectRoots) > 0 {
+		roots := make([]any, len(opts.ProjectRoots))
+		for i, root := range opts.ProjectRoots {
+			roots[i] = root
+		}
+		filters = append(filters, veclite.In("project_root", roots...))
+	} else if opts.ProjectRoot != "" {
 		filters = append(filters, veclite.Equal("project_root", opts.ProjectRoot))
 	}
 
@@ -1500,6 +2204,16 @@ func (b *VecLiteBackend) buildNativeFilters(opts FilterOptions) []veclite.Filter
 		}
 		filters = append(filters, veclite.Prefix("relative_path", dir))
 	}
+	if len(opts.Directories) > 0 {
+		prefixes := make([]veclite.Filter, len(opts.Directories))
+		for i, dir := range opts.Directories {
+			if !strings.HasSuffix(dir, "/") {
+				dir += "/"
+			}
+			prefixes[i] = veclite.Prefix("relative_path", dir)
+		}
+		filters = append(filters, veclite.Or(prefixes...))
+	}
 
 	// File allow-list filter (blast-radius scoping). When FilePaths is
 	// non-empty, restrict results to chunks whose relative_path is in the
@@ -1513,6 +2227,18 @@ func (b *VecLiteBackend) buildNativeFilters(opts FilterOptions) []veclite.Filter
 		filters = append(filters, veclite.In("relative_path", paths...))
 	}
 
+	if opts.Symbol != "" {
+		filters = append(filters, symbolFilter(opts.Symbol))
+	}
+
+	if len(opts.Tags) > 0 {
+		filters = append(filters, tagFilter(opts.Tags))
+	}
+
+	if len(opts.ExcludeIDs) > 0 || len(opts.ExcludePaths) > 0 {
+		filters = append(filters, excludeFilter(opts.ExcludeIDs, opts.ExcludePaths))
+	}
+
 	// Line range filter
 	if opts.MinLine > 0 && opts.MaxLine > 0 {
 		filters = append(filters, veclite.Between("start_line", float64(opts.MinLine), float64(opts.MaxLine)))
@@ -1531,24 +2257,15 @@ func (b *VecLiteBackend) SearchWithFilter(queryEmbedding []float32, limit int, o
 		return nil, fmt.Errorf("query embedding dimension mismatch: got %d, expected %d", len(queryEmbedding), b.dimensions)
 	}
 
-	// Build native filters
-	filters := b.buildNativeFilters(opts)
-
-	// Build search options (TopK + EfSearch + filters)
-	searchOpts := b.searchOptions(limit)
-	if len(filters) > 0 {
-		searchOpts = append(searchOpts, veclite.WithFilters(filters...))
-	}
-
 	// Perform search with native filtering
-	results, err := b.collection().Search(queryEmbedding, searchOpts...)
+	results, err := b.vectorSearch(queryEmbedding, limit, opts, b.buildNativeFilters(opts))
 	if err != nil {
 		return nil, err
 	}
 
 	searchResults := make([]SearchResult, 0, len(results))
 	for _, r := range results {
-		chunk := recordToChunk(r.Record)
+		chunk := b.chunkFromRecord(r.Record)
 		sr := SearchResult{
 			ChunkID:  int64(r.Record.ID),
 			Distance: r.Score,
@@ -1567,6 +2284,90 @@ func (b *VecLiteBackend) SearchWithFilter(queryEmbedding []float32, limit int, o
 	return searchResults, nil
 }
 
+const (
+	// postFilterMultiplier widens an external vector store search when some
+	// filters can only be applied to its hits, and again each time too few
+	// hits pass them.
+	postFilterMultiplier = 4
+	// postFilterMaxMultiplier and postFilterMinCap bound that growth: one
+	// search requests at most limit*postFilterMaxMultiplier hits, or
+	// postFilterMinCap when that is larger.
+	postFilterMaxMultiplier = 64
+	postFilterMinCap        = 1000
+)
+
+// vectorSearch runs the vector pass of a search and returns up to limit
+// results: the hot tier's exact matches merged with the vector index's (see
+// hotTier). filters are the native filters built from opts.
+func (b *VecLiteBackend) vectorSearch(query []float32, limit int, opts FilterOptions, filters []veclite.Filter) ([]veclite.Result, error) {
+	hot, err := b.searchHot(query, limit, filters)
+	if err != nil {
+		return nil, err
+	}
+	results, err := b.indexSearch(query, limit, opts, filters)
+	if err != nil {
+		return nil, err
+	}
+	return mergeHot(results, hot, limit), nil
+}
+
+// indexSearch searches the vector index and returns up to limit results.
+// filters are the native filters built from opts. VecLite grows its
+// own candidate pool when filters reject candidates and falls back to an
+// exact scan. Collections with an external vector store send the query there
+// with the filters it can evaluate, resolve each hit from its payload (see
+// storeRecord), and check the rest. When too few hits survive, the search is
+// repeated with a larger pool until limit is met, the store runs out of
+// hits, or the cap is reached.
+func (b *VecLiteBackend) indexSearch(query []float32, limit int, opts FilterOptions, filters []veclite.Filter) ([]veclite.Result, error) {
+	coll := b.collection()
+	if b.vectors == nil {
+		searchOpts := b.searchOptions(b.candidateLimit(limit), opts)
+		if len(filters) > 0 {
+			searchOpts = append(searchOpts, veclite.WithFilters(filters...))
+		}
+		results, err := coll.Search(query, searchOpts...)
+		if err != nil {
+			return nil, err
+		}
+		return b.rescore(query, results, limit), nil
+	}
+
+	fetch := limit
+	if !b.vectors.exactFilter(opts) {
+		fetch = limit * postFilterMultiplier
+	}
+	maxFetch := max(limit*postFilterMaxMultiplier, postFilterMinCap)
+	storeOpts := b.storeFilter(opts)
+	for {
+		hits, err := b.vectors.search(query, fetch, storeOpts)
+		if err != nil {
+			return nil, err
+		}
+		results := make([]veclite.Result, 0, min(limit, len(hits)))
+	hits:
+		for _, hit := range hits {
+			record := b.storeRecord(coll, hit)
+			if record == nil {
+				continue
+			}
+			for _, f := range filters {
+				if !f.Match(record) {
+					continue hits
+				}
+			}
+			results = append(results, veclite.Result{Record: record, Score: hit.Score})
+			if len(results) == limit {
+				break
+			}
+		}
+		if len(results) == limit || len(hits) < fetch || fetch >= maxFetch {
+			return results, nil
+		}
+		fetch = min(fetch*postFilterMultiplier, maxFetch)
+	}
+}
+
 // SearchWithExplain performs a search and returns diagnostic information.
 func (b *VecLiteBackend) SearchWithExplain(queryEmbedding []float32, limit int, opts FilterOptions) ([]SearchResult, *SearchExplanation, error) {
 	if len(queryEmbedding) != b.dimensions {
@@ -1576,25 +2377,45 @@ func (b *VecLiteBackend) SearchWithExplain(queryEmbedding []float32, limit int,
 	// Build native filters
 	filters := b.buildNativeFilters(opts)
 
-	// Build search options (TopK + EfSearch + filters)
-	searchOpts := b.searchOptions(limit)
-	if len(filters) > 0 {
-		searchOpts = append(searchOpts, veclite.WithFilters(filters...))
-	}
+	var explanation *veclite.SearchExplanation
+	var results []veclite.Result
+	if b.vectors != nil {
+		// External stores report no traversal statistics; time the round trip.
+		start := time.Now()
+		var err error
+		results, err = b.vectorSearch(queryEmbedding, limit, opts, filters)
+		if err != nil {
+			return nil, nil, err
+		}
+		explanation = &veclite.SearchExplanation{
+			IndexType: b.vectors.Type(),
+			Duration:  time.Since(start),
+		}
+	} else {
+		// Build search options (TopK + EfSearch + filters)
+		searchOpts := b.searchOptions(b.candidateLimit(limit), opts)
+		if len(filters) > 0 {
+			searchOpts = append(searchOpts, veclite.WithFilters(filters...))
+		}
 
-	// Use SearchExplain for diagnostics. veclite's SearchExplanation carries
-	// the actual Results alongside the diagnostics, so we no longer need to
-	// run a second Search() call — halving the work for every --explain.
-	explanation, err := b.collection().SearchExplain(queryEmbedding, searchOpts...)
-	if err != nil {
-		return nil, nil, err
+		// Use SearchExplain for diagnostics. veclite's SearchExplanation carries
+		// the actual Results alongside the diagnostics, so we no longer need to
+		// run a second Search() call — halving the work for every --explain.
+		var err error
+		explanation, err = b.collection().SearchExplain(queryEmbedding, searchOpts...)
+		if err != nil {
+			return nil, nil, err
+		}
+		hot, err := b.searchHot(queryEmbedding, limit, filters)
+		if err != nil {
+			return nil, nil, err
+		}
+		results = mergeHot(b.rescore(queryEmbedding, explanation.Results, limit), hot, limit)
 	}
 
-	results := explanation.Results
-
 	searchResults := make([]SearchResult, 0, len(results))
 	for _, r := range results {
-		chunk := recordToChunk(r.Record)
+		chunk := b.chunkFromRecord(r.Record)
 		sr := SearchResult{
 			ChunkID:  int64(r.Record.ID),
 			Distance: r.Score,
@@ -1624,12 +2445,12 @@ func (b *VecLiteBackend) SearchWithExplain(queryEmbedding []float32, limit int,
 func (b *VecLiteBackend) TextSearch(query string, limit int, opts FilterOptions) ([]SearchResult, error) {
 	filters := b.buildNativeFilters(opts)
 
-	searchOpts := b.searchOptions(limit)
+	searchOpts := b.searchOptions(limit, opts)
 	if len(filters) > 0 {
 		searchOpts = append(searchOpts, veclite.WithFilters(filters...))
 	}
 
-	results, err := b.collection().TextSearch(query, searchOpts...)
+	results, err := b.collection().TextSearch(expandKeywordQuery(query), searchOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1642,8 +2463,16 @@ func (b *VecLiteBackend) TextSearch(query string, limit int, opts FilterOptions)
 // here instead of delegating to veclite's HybridSearch because veclite fuses
 // with Reciprocal Rank Fusion, whose scores are bounded by 1/(k+1) (~0.016
 // with k=60) — meaningless when surfaced to users as a similarity, and
-// rank-only fusion discards how close a vector match actually was.
+// rank-only fusion discards how close a vector match actually was. RRF stays
+// available as an opt-in (FusionRRF) with its scores rescaled to 0-1.
 const (
+	// FusionWeighted fuses hybrid results by weighted score (the default).
+	FusionWeighted = "weighted"
+	// FusionRRF fuses hybrid results by weighted reciprocal rank.
+	FusionRRF = "rrf"
+	// hybridRRFK is the Reciprocal Rank Fusion constant.
+	hybridRRFK = 60
+
 	// hybridFetchMultiplier over-fetches from each modality so fusion sees
 	// candidates that only one ranker surfaced.
 	hybridFetchMultiplier = 3
@@ -1675,6 +2504,17 @@ const (
 // capped by the text weight, and a vector-only match is capped by the vector
 // weight.
 func (b *VecLiteBackend) HybridSearch(queryEmbedding []float32, textQuery string, limit int, opts FilterOptions, vectorWeight, textWeight float32) ([]SearchResult, error) {
+	return b.HybridSearchWithFusion(queryEmbedding, textQuery, limit, opts, vectorWeight, textWeight, FusionWeighted)
+}
+
+// HybridSearchWithFusion is HybridSearch with an explicit fusion strategy:
+// FusionWeighted (or "") fuses scores as described on HybridSearch, FusionRRF
+// ranks by weighted reciprocal rank and rescales the result so a record that
+// tops both rankers scores 1.0.
+func (b *VecLiteBackend) HybridSearchWithFusion(queryEmbedding []float32, textQuery string, limit int, opts FilterOptions, vectorWeight, textWeight float32, fusion string) ([]SearchResult, error) {
+	if fusion != "" && fusion != FusionWeighted && fusion != FusionRRF {
+		return nil, fmt.Errorf("unknown hybrid fusion %q: expected %s or %s", fusion, FusionWeighted, FusionRRF)
+	}
 	if len(queryEmbedding) != b.dimensions {
 		return nil, fmt.Errorf("query embedding dimension mismatch: got %d, expected %d", len(queryEmbedding), b.dimensions)
 	}
@@ -1699,25 +2539,37 @@ func (b *VecLiteBackend) HybridSearch(queryEmbedding []float32, textQuery string
 		fetchK = hybridMinFetch
 	}
 
-	vectorOpts := b.searchOptions(fetchK)
-	if len(filters) > 0 {
-		vectorOpts = append(vectorOpts, veclite.WithFilters(filters...))
-	}
-	vectorResults, err := b.collection().Search(queryEmbedding, vectorOpts...)
-	if err != nil {
-		return nil, err
-	}
-
-	textOpts := b.searchOptions(fetchK)
+	textOpts := b.searchOptions(fetchK, opts)
 	if len(filters) > 0 {
 		textOpts = append(textOpts, veclite.WithFilters(filters...))
 	}
-	textResults, err := b.collection().TextSearch(textQuery, textOpts...)
-	if err != nil {
+
+	// The two passes are independent and each only holds the collection's
+	// read lock, so they run concurrently: latency is the slower pass rather
+	// than the sum, which matters most for large limits where both over-fetch.
+	coll := b.collection()
+	var vectorResults, textResults []veclite.Result
+	var g errgroup.Group
+	g.Go(func() error {
+		var err error
+		vectorResults, err = b.vectorSearch(queryEmbedding, fetchK, opts, filters)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		textResults, err = coll.TextSearch(expandKeywordQuery(textQuery), textOpts...)
+		return err
+	})
+	if err := g.Wait(); err != nil {
 		return nil, err
 	}
 
-	fused := fuseWeightedScores(vectorResults, textResults, float64(vectorWeight), float64(textWeight))
+	var fused []veclite.Result
+	if fusion == FusionRRF {
+		fused = fuseReciprocalRanks(vectorResults, textResults, float64(vectorWeight), float64(textWeight))
+	} else {
+		fused = fuseWeightedScores(vectorResults, textResults, float64(vectorWeight), float64(textWeight))
+	}
 	if len(fused) > limit {
 		fused = fused[:limit]
 	}
@@ -1783,6 +2635,27 @@ func fuseWeightedScores(vectorResults, textResults []veclite.Result, vectorWeigh
 	return fused
 }
 
+// fuseReciprocalRanks merges the two rankings with weighted Reciprocal Rank
+// Fusion: each list contributes weight/(k+rank). The sum is multiplied by
+// k+1 so, with weights summing to 1, a record ranked first by both scores
+// 1.0 and scores stay comparable with --min-score. Ties break on record ID.
+func fuseReciprocalRanks(vectorResults, textResults []veclite.Result, vectorWeight, textWeight float64) []veclite.Result {
+	fused := veclite.FuseRRF([][]veclite.Result{vectorResults, textResults},
+		veclite.WithRRFK(hybridRRFK),
+		veclite.WithFusionWeights(vectorWeight, textWeight),
+	)
+	for i := range fused {
+		fused[i].Score *= hybridRRFK + 1
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		if fused[i].Score != fused[j].Score {
+			return fused[i].Score > fused[j].Score
+		}
+		return fused[i].Record.ID < fused[j].Record.ID
+	})
+	return fused
+}
+
 // chunkSubstanceFactor scales the keyword contribution of a chunk by how
 // substantive its content is: 1.0 at substantiveChunkChars or more, ramping
 // linearly down to minSubstanceFactor for empty content. This keeps 1-line
@@ -1803,7 +2676,7 @@ func chunkSubstanceFactor(record *veclite.Record) float64 {
 func (b *VecLiteBackend) resultsToSearchResults(results []veclite.Result) []SearchResult {
 	searchResults := make([]SearchResult, 0, len(results))
 	for _, r := range results {
-		chunk := recordToChunk(r.Record)
+		chunk := b.chunkFromRecord(r.Record)
 		sr := SearchResult{
 			ChunkID:  int64(r.Record.ID),
 			Distance: r.Score,
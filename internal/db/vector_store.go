@@ -0,0 +1,245 @@
+package db
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/veclite"
+)
+
+// vectorStore keeps a VecLite collection's chunks outside the VecLite file:
+// each point holds a chunk's vector and its payload, keyed by storePointID of
+// the chunk's portable key. Any client configured with the same store can
+// search it, whether or not its own collection holds the chunks.
+type vectorStore interface {
+	VectorBackend
+	// open prepares the store without emptying it; the store is shared, so
+	// an empty local collection says nothing about its contents.
+	open(dimensions int, hnsw HNSWConfig, readOnly bool) error
+	// isMissing reports that a read-only open found nothing to read yet.
+	isMissing() bool
+	// upsert writes one point per ID; payloads may be nil or hold nil
+	// entries.
+	upsert(ids []uint64, vectors [][]float32, payloads []map[string]any) error
+	deletePoints(ids []uint64) error
+	// deleteProject removes every point whose project_root is root.
+	deleteProject(root string) error
+	// search returns up to limit hits with their payloads, ranked by cosine
+	// similarity and restricted by the filters in opts the store can
+	// evaluate.
+	search(query []float32, limit int, opts FilterOptions) ([]vectorHit, error)
+	// exactFilter reports whether search applies every filter in opts; when
+	// it does not, the caller checks the rest against each hit's record.
+	exactFilter(opts FilterOptions) bool
+	// points fetches stored points with their vectors and payloads; missing
+	// IDs are absent from the map.
+	points(ids []uint64) (map[uint64]vectorHit, error)
+	// countProject returns the number of points whose project_root is root.
+	countProject(root string) (int64, error)
+	// metadata returns the collection metadata kept with the store (the
+	// embedding model and profile), so clients that did not write the index
+	// can check it; setMetadata stores one value, and nil removes it.
+	metadata() (map[string]any, error)
+	setMetadata(key string, value any) error
+	// Location describes where the vectors live, for status output.
+	Location() string
+}
+
+// vectorHit is one point returned by a vectorStore.
+type vectorHit struct {
+	ID      uint64         `json:"id"`
+	Score   float32        `json:"score"`
+	Vector  []float32      `json:"vector,omitempty"`
+	Payload map[string]any `json:"payload,omitempty"`
+}
+
+// storeKeySeparator replaces the NUL separators of chunk keys in the store,
+// since Postgres text and jsonb values cannot hold NUL.
+const storeKeySeparator = "\x1f"
+
+// storeRecordIDField is the store payload field holding the writer's VecLite
+// record ID, so the writer can resolve a hit to its own record cheaply.
+const storeRecordIDField = "record_id"
+
+// storePointID derives a point ID from a chunk's portable key. It stays below
+// 2^53 so the ID survives JSON clients that read numbers as doubles.
+func storePointID(key string) uint64 {
+	sum := sha256.Sum256([]byte(key))
+	id := binary.BigEndian.Uint64(sum[:8]) >> 11
+	if id == 0 {
+		id = 1
+	}
+	return id
+}
+
+// storeRoot maps a project root to the form kept in the store: relative to
+// the root the backend was opened for ("." for the root itself), so clients
+// with the project checked out elsewhere find the same points. Roots outside
+// it are kept absolute.
+func (b *VecLiteBackend) storeRoot(root string) string {
+	if b.root == "" || root == "" {
+		return root
+	}
+	rel, err := filepath.Rel(b.root, root)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return root
+	}
+	return filepath.ToSlash(rel)
+}
+
+// localRoot reverses storeRoot.
+func (b *VecLiteBackend) localRoot(root string) string {
+	if b.root == "" || root == "" || filepath.IsAbs(root) {
+		return root
+	}
+	return filepath.Join(b.root, filepath.FromSlash(root))
+}
+
+// storeKey returns the portable key of the chunk stored under record id with
+// payload: its chunk_key with the project root mapped by storeRoot and the
+// separators replaced by storeKeySeparator. Legacy records without a chunk
+// key are keyed by record ID.
+func (b *VecLiteBackend) storeKey(id uint64, payload map[string]any) string {
+	key := getStringPayload(payload, "chunk_key")
+	if key == "" {
+		return "record:" + strconv.FormatUint(id, 10)
+	}
+	root := getStringPayload(payload, "project_root")
+	if rest, ok := strings.CutPrefix(key, root+"\x00"); ok {
+		key = b.storeRoot(root) + "\x00" + rest
+	}
+	return strings.ReplaceAll(key, "\x00", storeKeySeparator)
+}
+
+// pointID returns the store point ID of the chunk stored under record id
+// with payload.
+func (b *VecLiteBackend) pointID(id uint64, payload map[string]any) uint64 {
+	return storePointID(b.storeKey(id, payload))
+}
+
+// storePayload converts a chunk payload into the one kept in the store:
+// without the keyword terms and absolute file path, which only the local
+// collection uses, with portable project_root and chunk_key, and with the
+// writer's record ID.
+func (b *VecLiteBackend) storePayload(id uint64, payload map[string]any) map[string]any {
+	out := make(map[string]any, len(payload)+1)
+	maps.Copy(out, payload)
+	delete(out, "search_terms")
+	delete(out, "file_path")
+	if _, ok := payload["chunk_key"]; ok {
+		out["chunk_key"] = b.storeKey(id, payload)
+		out["project_root"] = b.storeRoot(getStringPayload(payload, "project_root"))
+	}
+	out[storeRecordIDField] = id
+	return out
+}
+
+// localPayload reverses storePayload for this client's project root.
+func (b *VecLiteBackend) localPayload(payload map[string]any) map[string]any {
+	out := make(map[string]any, len(payload))
+	maps.Copy(out, payload)
+	delete(out, storeRecordIDField)
+	root := b.localRoot(getStringPayload(payload, "project_root"))
+	if key := getStringPayload(payload, "chunk_key"); key != "" {
+		key = strings.ReplaceAll(key, storeKeySeparator, "\x00")
+		if rest, ok := strings.CutPrefix(key, getStringPayload(payload, "project_root")+"\x00"); ok {
+			key = root + "\x00" + rest
+		}
+		out["chunk_key"] = key
+		out["project_root"] = root
+		out["file_path"] = filepath.Join(root, filepath.FromSlash(getStringPayload(payload, "relative_path")))
+	}
+	return out
+}
+
+// storeRecord resolves a store point to a record: the local record it was
+// written from when this client wrote it and still holds it, otherwise a
+// record built from the point's payload under the point ID. It returns nil
+// for points without a usable payload.
+func (b *VecLiteBackend) storeRecord(coll *veclite.Collection, hit vectorHit) *veclite.Record {
+	if id := uint64(getInt64Payload(hit.Payload, storeRecordIDField)); id != 0 {
+		if record, err := coll.Get(id); err == nil && record != nil && b.pointID(record.ID, record.Payload) == hit.ID {
+			return record
+		}
+	}
+	if getStringPayload(hit.Payload, "chunk_key") == "" {
+		return nil
+	}
+	return &veclite.Record{ID: hit.ID, Vector: hit.Vector, Payload: b.localPayload(hit.Payload)}
+}
+
+// storeRecordByID reads the point stored under id as a record, or returns
+// nil when there is no store or no such point.
+func (b *VecLiteBackend) storeRecordByID(id uint64) *veclite.Record {
+	if b.vectors == nil {
+		return nil
+	}
+	points, err := b.vectors.points([]uint64{id})
+	point, ok := points[id]
+	if err != nil || !ok {
+		return nil
+	}
+	return b.storeRecord(b.collection(), point)
+}
+
+// storeFilter maps the project roots in opts to their store form.
+func (b *VecLiteBackend) storeFilter(opts FilterOptions) FilterOptions {
+	if opts.ProjectRoot != "" {
+		opts.ProjectRoot = b.storeRoot(opts.ProjectRoot)
+	}
+	if len(opts.ProjectRoots) > 0 {
+		roots := make([]string, len(opts.ProjectRoots))
+		for i, root := range opts.ProjectRoots {
+			roots[i] = b.storeRoot(root)
+		}
+		opts.ProjectRoots = roots
+	}
+	return opts
+}
+
+// loadStoreMetadata caches the external vector store's metadata.
+func (b *VecLiteBackend) loadStoreMetadata() error {
+	meta, err := b.vectors.metadata()
+	if err != nil {
+		return fmt.Errorf("read %s vector store metadata: %w", b.vectors.Type(), err)
+	}
+	b.collMu.Lock()
+	b.storeMeta = meta
+	b.collMu.Unlock()
+	return nil
+}
+
+// storeMetadataValue returns a cached value of the store's metadata.
+func (b *VecLiteBackend) storeMetadataValue(key string) (any, bool) {
+	b.collMu.RLock()
+	defer b.collMu.RUnlock()
+	v, ok := b.storeMeta[key]
+	return v, ok
+}
+
+// putStoreMetadata writes one value of the store's metadata, or removes it
+// when value is nil. It is a no-op without an external store.
+func (b *VecLiteBackend) putStoreMetadata(key string, value any) error {
+	if b.vectors == nil {
+		return nil
+	}
+	if err := b.vectors.setMetadata(key, value); err != nil {
+		return err
+	}
+	b.collMu.Lock()
+	defer b.collMu.Unlock()
+	if value == nil {
+		delete(b.storeMeta, key)
+		return nil
+	}
+	if b.storeMeta == nil {
+		b.storeMeta = map[string]any{}
+	}
+	b.storeMeta[key] = value
+	return nil
+}
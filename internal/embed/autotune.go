@@ -0,0 +1,193 @@
+package embed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Batch tuning bounds. The probe doubles the batch size from
+// minTuneBatchSize and stops once throughput gains less than
+// tunePlateauGain, a batch fails, or one batch takes longer than
+// maxTuneLatency.
+const (
+	minTuneBatchSize  = 8
+	maxTuneBatchSize  = 256
+	tunePlateauGain   = 1.1
+	maxTuneLatency    = 15 * time.Second
+	tuneProbeChars    = 1024
+	minTuneInputChars = 256
+	// tuneInputStep is how close the input-length search gets to the
+	// provider's real limit before it stops.
+	tuneInputStep = 256
+)
+
+// BatchTuning is what a probe learned about a provider: the batch size with
+// the best throughput and the longest input it accepts, up to the limit the
+// probe was asked to check.
+type BatchTuning struct {
+	BatchSize      int     `json:"batch_size"`
+	MaxInputChars  int     `json:"max_input_chars"`
+	TextsPerSecond float64 `json:"texts_per_second"`
+}
+
+// TuneOptions bounds a batch tuning probe.
+type TuneOptions struct {
+	// MaxInputChars is the longest input worth checking, normally the
+	// chunker's cap. Longer inputs are never sent, so it is also the
+	// largest MaxInputChars a probe can report.
+	MaxInputChars int
+	// MaxBatchSize caps the probed batch size. Zero uses 256.
+	MaxBatchSize int
+
+	now func() time.Time
+}
+
+// TuneBatch probes provider for the longest input it accepts, by binary
+// search on errors, and then for the batch size with the best throughput, by
+// doubling until the gain levels off and then, if a batch fails, searching
+// back down to the largest size that succeeds. Each probe text is distinct,
+// so pass an uncached provider (see Uncached) to measure the model itself.
+func TuneBatch(ctx context.Context, provider Provider, opts TuneOptions) (BatchTuning, error) {
+	if opts.MaxInputChars < minTuneInputChars {
+		opts.MaxInputChars = minTuneInputChars
+	}
+	if opts.MaxBatchSize <= 0 {
+		opts.MaxBatchSize = maxTuneBatchSize
+	}
+	if opts.now == nil {
+		opts.now = time.Now
+	}
+	probe := &batchProbe{provider: provider, now: opts.now}
+
+	maxInput, err := probe.maxInputChars(ctx, opts.MaxInputChars)
+	if err != nil {
+		return BatchTuning{}, err
+	}
+	tuning, err := probe.batchSize(ctx, min(tuneProbeChars, maxInput), opts.MaxBatchSize)
+	if err != nil {
+		return BatchTuning{}, err
+	}
+	tuning.MaxInputChars = maxInput
+	return tuning, nil
+}
+
+type batchProbe struct {
+	provider Provider
+	now      func() time.Time
+	seq      int
+}
+
+// maxInputChars returns limit if the provider embeds an input that long, or
+// else the longest accepted length found by binary search, to within
+// tuneInputStep.
+func (p *batchProbe) maxInputChars(ctx context.Context, limit int) (int, error) {
+	if _, err := p.embed(ctx, 1, limit); err == nil {
+		return limit, nil
+	} else if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	if _, err := p.embed(ctx, 1, minTuneInputChars); err != nil {
+		return 0, fmt.Errorf("provider rejects a %d-character input: %w", minTuneInputChars, err)
+	}
+	lo, hi := minTuneInputChars, limit
+	for hi-lo > tuneInputStep {
+		mid := (lo + hi) / 2
+		if _, err := p.embed(ctx, 1, mid); err == nil {
+			lo = mid
+		} else if ctx.Err() != nil {
+			return 0, ctx.Err()
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// batchSize measures throughput at doubling batch sizes and returns the
+// fastest one.
+func (p *batchProbe) batchSize(ctx context.Context, chars, maxBatch int) (BatchTuning, error) {
+	var best BatchTuning
+	measure := func(size int) (time.Duration, error) {
+		elapsed, err := p.embed(ctx, size, chars)
+		if err != nil {
+			return 0, err
+		}
+		if rate := float64(size) / max(elapsed, time.Microsecond).Seconds(); rate > best.TextsPerSecond {
+			best = BatchTuning{BatchSize: size, TextsPerSecond: rate}
+		}
+		return elapsed, nil
+	}
+
+	lastGood := 0
+	for size := min(minTuneBatchSize, maxBatch); size <= maxBatch; size *= 2 {
+		previousBest := best.TextsPerSecond
+		elapsed, err := measure(size)
+		if err != nil {
+			if ctx.Err() != nil {
+				return BatchTuning{}, ctx.Err()
+			}
+			if lastGood == 0 {
+				return BatchTuning{}, fmt.Errorf("provider rejects a batch of %d: %w", size, err)
+			}
+			// Find the largest size that still succeeds, to within a
+			// quarter of the last good size.
+			lo, hi := lastGood, size
+			for hi-lo > max(1, lo/4) {
+				mid := (lo + hi) / 2
+				if _, err := measure(mid); err == nil {
+					lo = mid
+				} else if ctx.Err() != nil {
+					return BatchTuning{}, ctx.Err()
+				} else {
+					hi = mid
+				}
+			}
+			break
+		}
+		lastGood = size
+		if previousBest > 0 && best.TextsPerSecond < previousBest*tunePlateauGain {
+			// The larger batch was not clearly faster, so keep the
+			// smaller one it measured against.
+			if best.BatchSize == size {
+				best = BatchTuning{BatchSize: size / 2, TextsPerSecond: previousBest}
+			}
+			break
+		}
+		if elapsed > maxTuneLatency {
+			break
+		}
+	}
+	return best, nil
+}
+
+// embed sends one batch of count distinct texts, each chars long, and
+// returns how long it took.
+func (p *batchProbe) embed(ctx context.Context, count, chars int) (time.Duration, error) {
+	texts := make([]string, count)
+	for i := range texts {
+		p.seq++
+		texts[i] = probeText(p.seq, chars)
+	}
+	start := p.now()
+	var err error
+	if count == 1 {
+		_, err = p.provider.Embed(ctx, texts[0])
+	} else {
+		_, err = p.provider.EmbedBatch(ctx, texts)
+	}
+	return p.now().Sub(start), err
+}
+
+// probeText returns a code-like text of exactly chars bytes that differs for
+// every seq.
+func probeText(seq, chars int) string {
+	var b strings.Builder
+	b.Grow(chars + 64)
+	fmt.Fprintf(&b, "// probe %d\n", seq)
+	for b.Len() < chars {
+		fmt.Fprintf(&b, "func value%d(a, b int) int { return a*%d + b }\n", b.Len(), seq)
+	}
+	return b.String()[:chars]
+}
@@ -0,0 +1,116 @@
+package embed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tuneProvider models a provider whose batches cost a fixed overhead plus a
+// per-text cost on a fake clock, and which rejects oversized inputs and
+// batches.
+type tuneProvider struct {
+	clock      time.Time
+	overhead   time.Duration
+	perText    time.Duration
+	maxChars   int
+	maxBatch   int
+	seen       map[string]bool
+	duplicates int
+}
+
+func (p *tuneProvider) now() time.Time { return p.clock }
+
+func (p *tuneProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	vecs, err := p.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vecs[0], nil
+}
+
+func (p *tuneProvider) EmbedBatch(_ context.Context, texts []string) ([][]float32, error) {
+	p.clock = p.clock.Add(p.overhead + time.Duration(len(texts))*p.perText)
+	if p.maxBatch > 0 && len(texts) > p.maxBatch {
+		return nil, fmt.Errorf("batch of %d exceeds %d", len(texts), p.maxBatch)
+	}
+	vecs := make([][]float32, len(texts))
+	for i, text := range texts {
+		if p.maxChars > 0 && len(text) > p.maxChars {
+			return nil, errors.New("input exceeds context length")
+		}
+		if p.seen[text] {
+			p.duplicates++
+		}
+		p.seen[text] = true
+		vecs[i] = []float32{1}
+	}
+	return vecs, nil
+}
+
+func (p *tuneProvider) Model() string                                 { return "tune" }
+func (p *tuneProvider) Dimensions() int                               { return 1 }
+func (p *tuneProvider) Ping(context.Context) error                    { return nil }
+func (p *tuneProvider) Warmup(context.Context) (time.Duration, error) { return 0, nil }
+
+func TestTuneBatch(t *testing.T) {
+	provider := &tuneProvider{
+		overhead: 100 * time.Millisecond,
+		perText:  time.Millisecond,
+		maxChars: 1500,
+		maxBatch: 100,
+		seen:     map[string]bool{},
+	}
+	tuning, err := TuneBatch(context.Background(), provider, TuneOptions{MaxInputChars: 4096, now: provider.now})
+	if err != nil {
+		t.Fatalf("TuneBatch() error = %v", err)
+	}
+	// Throughput keeps climbing with batch size, so the probe settles on the
+	// largest batch it found accepted below the provider's limit of 100.
+	if tuning.BatchSize < 75 || tuning.BatchSize > 100 {
+		t.Errorf("BatchSize = %d, want the largest accepted batch near 100", tuning.BatchSize)
+	}
+	if tuning.MaxInputChars > 1500 || tuning.MaxInputChars <= 1500-tuneInputStep {
+		t.Errorf("MaxInputChars = %d, want within %d below 1500", tuning.MaxInputChars, tuneInputStep)
+	}
+	if tuning.TextsPerSecond <= 0 {
+		t.Errorf("TextsPerSecond = %v, want positive", tuning.TextsPerSecond)
+	}
+	if provider.duplicates != 0 {
+		t.Errorf("probe repeated %d texts; every probe text must be distinct", provider.duplicates)
+	}
+}
+
+func TestTuneBatchStopsAtThroughputPlateau(t *testing.T) {
+	// Without per-request overhead, larger batches are no faster.
+	provider := &tuneProvider{perText: time.Millisecond, seen: map[string]bool{}}
+	tuning, err := TuneBatch(context.Background(), provider, TuneOptions{MaxInputChars: 2048, now: provider.now})
+	if err != nil {
+		t.Fatalf("TuneBatch() error = %v", err)
+	}
+	if tuning.BatchSize != minTuneBatchSize || tuning.MaxInputChars != 2048 {
+		t.Fatalf("tuning = %+v, want batch %d and the full 2048-char input", tuning, minTuneBatchSize)
+	}
+}
+
+func TestTuneBatchFailsWhenProviderRejectsSmallInputs(t *testing.T) {
+	provider := &tuneProvider{maxChars: 100, seen: map[string]bool{}}
+	_, err := TuneBatch(context.Background(), provider, TuneOptions{MaxInputChars: 2048, now: provider.now})
+	if err == nil || !strings.Contains(err.Error(), "input") {
+		t.Fatalf("TuneBatch() error = %v, want an input length error", err)
+	}
+}
+
+func TestUncachedUnwrapsThrottle(t *testing.T) {
+	inner := &tuneProvider{seen: map[string]bool{}}
+	throttled := NewThrottledProvider(inner, ThrottleConfig{MaxInFlight: 1, CacheSize: 10})
+	if got := Uncached(throttled); got != Provider(inner) {
+		t.Fatalf("Uncached(throttled) = %T, want the inner provider", got)
+	}
+	if got := Uncached(inner); got != Provider(inner) {
+		t.Fatalf("Uncached(inner) = %T, want it unchanged", got)
+	}
+}
@@ -0,0 +1,188 @@
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	defaultExecModel        = "exec"
+	defaultExecTimeout      = 120 * time.Second
+	defaultExecMaxBatchSize = 64
+	execInputQuery          = "query"
+	execInputDocument       = "document"
+)
+
+// ExecConfig holds configuration for the exec embedding provider.
+type ExecConfig struct {
+	// Command is the program and arguments to run, without a shell.
+	Command      []string
+	Model        string
+	Dimensions   int
+	MaxBatchSize int
+	Timeout      time.Duration
+}
+
+// ExecProvider implements Provider by running an external command once per
+// batch. The command reads a JSON request on stdin:
+//
+//	{"model": "my-model", "input_type": "document", "texts": ["...", "..."]}
+//
+// and must exit 0 after writing one embedding per text, in order:
+//
+//	{"embeddings": [[0.1, 0.2, ...], [0.3, 0.4, ...]]}
+//
+// input_type is "query" for search queries and "document" for indexed
+// content, so retrieval models that embed the two differently can. A non-zero
+// exit fails the batch; stderr is included in the error.
+type ExecProvider struct {
+	config ExecConfig
+}
+
+type execEmbeddingRequest struct {
+	Model     string   `json:"model"`
+	InputType string   `json:"input_type"`
+	Texts     []string `json:"texts"`
+}
+
+type execEmbeddingResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+// NewExecProvider returns an exec provider. The command and dimensions are
+// required: vecgrep sizes the index before the first embedding is produced.
+func NewExecProvider(cfg ExecConfig) (*ExecProvider, error) {
+	if len(cfg.Command) == 0 {
+		return nil, NewProviderError("exec", "init", fmt.Errorf("embedding.exec_command is not configured"))
+	}
+	if cfg.Dimensions <= 0 {
+		return nil, NewProviderError("exec", "init", fmt.Errorf("embedding.dimensions must be set for the exec provider"))
+	}
+	if cfg.Model == "" {
+		cfg.Model = defaultExecModel
+	}
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = defaultExecMaxBatchSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultExecTimeout
+	}
+	return &ExecProvider{config: cfg}, nil
+}
+
+func (p *ExecProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	return p.EmbedQuery(ctx, text)
+}
+
+func (p *ExecProvider) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+	embeddings, err := p.embedBatchByInputType(ctx, []string{text}, execInputQuery)
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (p *ExecProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	return p.embedBatchByInputType(ctx, texts, execInputQuery)
+}
+
+func (p *ExecProvider) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return p.embedBatchByInputType(ctx, texts, execInputDocument)
+}
+
+func (p *ExecProvider) embedBatchByInputType(ctx context.Context, texts []string, inputType string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	for i, text := range texts {
+		if text == "" {
+			return nil, NewProviderError("exec", "embedBatch", fmt.Errorf("text %d: %w", i, ErrEmptyText))
+		}
+	}
+	results := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += p.config.MaxBatchSize {
+		end := min(start+p.config.MaxBatchSize, len(texts))
+		embeddings, err := p.run(ctx, texts[start:end], inputType)
+		if err != nil {
+			return nil, NewProviderError("exec", "embed", err)
+		}
+		results = append(results, embeddings...)
+	}
+	return results, nil
+}
+
+func (p *ExecProvider) run(ctx context.Context, texts []string, inputType string) ([][]float32, error) {
+	body, err := json.Marshal(execEmbeddingRequest{Model: p.config.Model, InputType: inputType, Texts: texts})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+	cmd := exec.CommandContext(runCtx, p.config.Command[0], p.config.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(body)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrContextCanceled
+		}
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("command timed out after %s", p.config.Timeout)
+		}
+		var execErr *exec.Error
+		if errors.As(err, &execErr) {
+			return nil, fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("command failed: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("command failed: %w", err)
+	}
+
+	var resp execEmbeddingResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return nil, fmt.Errorf("decode command output: %w", err)
+	}
+	if len(resp.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("command returned %d embeddings for %d texts", len(resp.Embeddings), len(texts))
+	}
+	for _, embedding := range resp.Embeddings {
+		if err := validateEmbeddingDimensions("exec", embedding, p.config.Dimensions); err != nil {
+			return nil, err
+		}
+	}
+	return resp.Embeddings, nil
+}
+
+func (p *ExecProvider) Model() string {
+	return p.config.Model
+}
+
+func (p *ExecProvider) Dimensions() int {
+	return p.config.Dimensions
+}
+
+// Ping runs the command on a probe text, so a missing binary, a crashing
+// script, or a dimension mismatch is reported before indexing starts.
+func (p *ExecProvider) Ping(ctx context.Context) error {
+	if _, err := p.EmbedQuery(ctx, "test"); err != nil {
+		return NewProviderError("exec", "ping", err)
+	}
+	return nil
+}
+
+// Warmup is a no-op for the exec provider; each batch starts a fresh process.
+func (p *ExecProvider) Warmup(ctx context.Context) (time.Duration, error) {
+	return 0, nil
+}
@@ -0,0 +1,85 @@
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func requireShell(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+}
+
+func TestExecProviderBatchContract(t *testing.T) {
+	requireShell(t)
+	requestPath := filepath.Join(t.TempDir(), "request.json")
+	script := `cat > "$0"; printf '{"embeddings":[[1,0],[0,1]]}'`
+	provider, err := NewExecProvider(ExecConfig{
+		Command:    []string{"sh", "-c", script, requestPath},
+		Model:      "local-st",
+		Dimensions: 2,
+	})
+	if err != nil {
+		t.Fatalf("NewExecProvider: %v", err)
+	}
+
+	vecs, err := provider.EmbedDocuments(context.Background(), []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("EmbedDocuments: %v", err)
+	}
+	if len(vecs) != 2 || vecs[1][1] != 1 {
+		t.Fatalf("embeddings = %v", vecs)
+	}
+
+	data, err := os.ReadFile(requestPath)
+	if err != nil {
+		t.Fatalf("read request: %v", err)
+	}
+	var req execEmbeddingRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+	if req.Model != "local-st" || req.InputType != execInputDocument || strings.Join(req.Texts, ",") != "a,b" {
+		t.Fatalf("request = %+v", req)
+	}
+
+	// The script always returns two vectors, so a single query is a
+	// count mismatch rather than a silently misaligned result.
+	if _, err := provider.EmbedQuery(context.Background(), "q"); err == nil || !strings.Contains(err.Error(), "2 embeddings for 1 texts") {
+		t.Fatalf("EmbedQuery err = %v, want count mismatch", err)
+	}
+}
+
+func TestExecProviderErrors(t *testing.T) {
+	requireShell(t)
+
+	if _, err := NewExecProvider(ExecConfig{Dimensions: 2}); err == nil {
+		t.Fatal("missing command should fail")
+	}
+	if _, err := NewExecProvider(ExecConfig{Command: []string{"x"}}); err == nil {
+		t.Fatal("missing dimensions should fail")
+	}
+
+	failing, _ := NewExecProvider(ExecConfig{Command: []string{"sh", "-c", "echo model missing >&2; exit 3"}, Dimensions: 2})
+	if err := failing.Ping(context.Background()); err == nil || !strings.Contains(err.Error(), "model missing") {
+		t.Fatalf("Ping err = %v, want stderr surfaced", err)
+	}
+
+	wrongDims, _ := NewExecProvider(ExecConfig{Command: []string{"sh", "-c", `cat >/dev/null; printf '{"embeddings":[[1,2,3]]}'`}, Dimensions: 2})
+	if _, err := wrongDims.Embed(context.Background(), "q"); !errors.Is(err, ErrDimensionMismatch) {
+		t.Fatalf("err = %v, want ErrDimensionMismatch", err)
+	}
+
+	missing, _ := NewExecProvider(ExecConfig{Command: []string{"vecgrep-no-such-embedder"}, Dimensions: 2})
+	if _, err := missing.Embed(context.Background(), "q"); !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("err = %v, want ErrProviderUnavailable", err)
+	}
+}
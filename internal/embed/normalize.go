@@ -0,0 +1,131 @@
+package embed
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// NormalizationL2 is the normalization policy applied by NormalizedProvider:
+// every vector is scaled to unit Euclidean length.
+const NormalizationL2 = "l2"
+
+// NormalizeL2 scales v in place to unit length and returns it. Zero vectors
+// and vectors containing NaN or Inf are left unchanged.
+func NormalizeL2(v []float32) []float32 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	norm := math.Sqrt(sum)
+	if norm == 0 || math.IsNaN(norm) || math.IsInf(norm, 0) {
+		return v
+	}
+	for i, x := range v {
+		v[i] = float32(float64(x) / norm)
+	}
+	return v
+}
+
+// NormalizationReporter is implemented by providers that know the
+// normalization policy applied to their vectors.
+type NormalizationReporter interface {
+	Normalization() string
+}
+
+// NormalizationOf returns the normalization policy of a provider chain, or ""
+// when vectors are returned as the upstream provider produced them.
+func NormalizationOf(provider Provider) string {
+	if reporter, ok := provider.(NormalizationReporter); ok {
+		return reporter.Normalization()
+	}
+	return ""
+}
+
+// NormalizedProvider L2-normalizes every vector the inner provider returns.
+// Some providers (exec scripts, self-hosted OpenAI-compatible servers, some
+// Ollama models) return unnormalized vectors; normalizing them here keeps
+// cosine scores and stored vectors independent of provider behaviour. It
+// belongs directly above the raw provider so caches only hold normalized
+// vectors.
+type NormalizedProvider struct {
+	inner Provider
+}
+
+// normalizedDocumentProvider adds EmbedDocuments only when the inner provider
+// supports it, matching meteredDocumentProvider.
+type normalizedDocumentProvider struct {
+	*NormalizedProvider
+	documents DocumentProvider
+}
+
+// NewNormalizedProvider wraps inner so every returned vector has unit length.
+func NewNormalizedProvider(inner Provider) Provider {
+	normalized := &NormalizedProvider{inner: inner}
+	if documents, ok := inner.(DocumentProvider); ok {
+		return &normalizedDocumentProvider{NormalizedProvider: normalized, documents: documents}
+	}
+	return normalized
+}
+
+// Embed generates a normalized embedding for one text.
+func (p *NormalizedProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	vec, err := p.inner.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	return NormalizeL2(vec), nil
+}
+
+// EmbedBatch generates normalized embeddings for several texts.
+func (p *NormalizedProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	return normalizeAll(p.inner.EmbedBatch(ctx, texts))
+}
+
+// EmbedQuery uses the inner provider's query embedding when available.
+func (p *NormalizedProvider) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	queryProvider, ok := p.inner.(QueryProvider)
+	if !ok {
+		return p.Embed(ctx, text)
+	}
+	vec, err := queryProvider.EmbedQuery(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	return NormalizeL2(vec), nil
+}
+
+// EmbedDocuments delegates to the inner DocumentProvider.
+func (p *normalizedDocumentProvider) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return normalizeAll(p.documents.EmbedDocuments(ctx, texts))
+}
+
+// Model returns the inner provider's model.
+func (p *NormalizedProvider) Model() string { return p.inner.Model() }
+
+// Dimensions returns the inner provider's dimensions.
+func (p *NormalizedProvider) Dimensions() int { return p.inner.Dimensions() }
+
+// Ping checks the inner provider.
+func (p *NormalizedProvider) Ping(ctx context.Context) error { return p.inner.Ping(ctx) }
+
+// Warmup delegates to the inner provider.
+func (p *NormalizedProvider) Warmup(ctx context.Context) (time.Duration, error) {
+	return p.inner.Warmup(ctx)
+}
+
+// Normalization reports the L2 policy.
+func (p *NormalizedProvider) Normalization() string { return NormalizationL2 }
+
+// Tokenizer returns the inner provider's tokenizer, or nil.
+func (p *NormalizedProvider) Tokenizer() Tokenizer { return TokenizerOf(p.inner) }
+
+func normalizeAll(vecs [][]float32, err error) ([][]float32, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, vec := range vecs {
+		NormalizeL2(vec)
+	}
+	return vecs, nil
+}
@@ -0,0 +1,100 @@
+package embed
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func assertUnitLength(t *testing.T, v []float32) {
+	t.Helper()
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	if math.Abs(math.Sqrt(sum)-1) > 1e-6 {
+		t.Fatalf("norm of %v = %f, want 1", v, math.Sqrt(sum))
+	}
+}
+
+func TestNormalizeL2(t *testing.T) {
+	v := NormalizeL2([]float32{3, 4})
+	if v[0] != 0.6 || v[1] != 0.8 {
+		t.Fatalf("NormalizeL2([3 4]) = %v, want [0.6 0.8]", v)
+	}
+	zero := NormalizeL2([]float32{0, 0, 0})
+	for _, x := range zero {
+		if x != 0 {
+			t.Fatalf("zero vector changed to %v", zero)
+		}
+	}
+	nan := NormalizeL2([]float32{float32(math.NaN()), 1})
+	if nan[1] != 1 {
+		t.Fatalf("vector with NaN was rescaled: %v", nan)
+	}
+}
+
+func TestNormalizedProviderNormalizesEveryPath(t *testing.T) {
+	inner := &mockProvider{}
+	provider := NewNormalizedProvider(inner)
+	ctx := context.Background()
+
+	vec, err := provider.Embed(ctx, "a")
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	assertUnitLength(t, vec)
+
+	vecs, err := provider.EmbedBatch(ctx, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("EmbedBatch: %v", err)
+	}
+	for _, v := range vecs {
+		assertUnitLength(t, v)
+	}
+
+	docs, ok := provider.(DocumentProvider)
+	if !ok {
+		t.Fatal("normalized provider should expose EmbedDocuments when the inner provider does")
+	}
+	vecs, err = docs.EmbedDocuments(ctx, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("EmbedDocuments: %v", err)
+	}
+	for _, v := range vecs {
+		assertUnitLength(t, v)
+	}
+
+	query, ok := provider.(QueryProvider)
+	if !ok {
+		t.Fatal("normalized provider should implement EmbedQuery")
+	}
+	vec, err = query.EmbedQuery(ctx, "a")
+	if err != nil {
+		t.Fatalf("EmbedQuery: %v", err)
+	}
+	assertUnitLength(t, vec)
+}
+
+func TestNormalizedProviderPreservesMissingDocumentCapability(t *testing.T) {
+	provider := NewNormalizedProvider(batchOnlyProvider{Provider: &mockProvider{}})
+	if _, ok := provider.(DocumentProvider); ok {
+		t.Fatal("normalized provider should not invent EmbedDocuments")
+	}
+}
+
+func TestNormalizationReportedThroughProviderChain(t *testing.T) {
+	if got := NormalizationOf(&mockProvider{}); got != "" {
+		t.Fatalf("raw provider normalization = %q, want empty", got)
+	}
+	metered := NewMeteredProvider(NewNormalizedProvider(&mockProvider{}), nil)
+	throttled := NewThrottledProvider(metered, ThrottleConfig{CacheSize: 10, CachePath: filepath.Join(t.TempDir(), "cache.db")})
+	defer throttled.Close()
+	if got := NormalizationOf(throttled); got != NormalizationL2 {
+		t.Fatalf("throttled normalization = %q, want %q", got, NormalizationL2)
+	}
+	if got := throttled.diskCache.diskKey("x"); got != throttled.diskCache.mem.Key("x")+":test-model:l2" {
+		t.Fatalf("disk cache key = %q, want an l2 namespace", got)
+	}
+}
@@ -0,0 +1,163 @@
+//go:build onnx
+
+package embed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/veclite/embed/onnx"
+	"github.com/daulet/tokenizers"
+)
+
+// ONNXProvider implements Provider with an in-process ONNX Runtime session,
+// so indexing and search need no embedding service at all. Embeddings are
+// mean-pooled and L2-normalized. It is only compiled with the onnx build tag,
+// since ONNX Runtime requires cgo and a shared library at run time.
+type ONNXProvider struct {
+	config   ONNXConfig
+	embedder *onnx.Embedder
+	// tokenizer is a second handle on the model's tokenizer.json, which
+	// the embedder keeps private, for counting tokens exactly. mu
+	// serializes its use.
+	mu        sync.Mutex
+	tokenizer *tokenizers.Tokenizer
+}
+
+// NewONNXProvider loads the model in cfg.ModelDir.
+func NewONNXProvider(cfg ONNXConfig) (*ONNXProvider, error) {
+	if cfg.ModelDir == "" {
+		return nil, NewProviderError("onnx", "init", fmt.Errorf("embedding.onnx_model_dir is not configured"))
+	}
+	if cfg.Model == "" {
+		cfg.Model = defaultONNXModel
+	}
+	if cfg.Dimensions <= 0 {
+		cfg.Dimensions = defaultONNXDimensions
+	}
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = defaultONNXMaxBatchSize
+	}
+	modelPath := filepath.Join(cfg.ModelDir, "model.onnx")
+	tokenizerPath := filepath.Join(cfg.ModelDir, "tokenizer.json")
+	for _, path := range []string{modelPath, tokenizerPath} {
+		if _, err := os.Stat(path); err != nil {
+			return nil, NewProviderError("onnx", "init", fmt.Errorf("%w: %v", ErrModelNotFound, err))
+		}
+	}
+	if cfg.LibraryPath != "" {
+		onnx.SetLibraryPath(cfg.LibraryPath)
+	}
+	embedder, err := onnx.NewEmbedder(modelPath, tokenizerPath, onnx.WithDimension(cfg.Dimensions))
+	if err != nil {
+		return nil, NewProviderError("onnx", "init", fmt.Errorf("%w: %v", ErrProviderUnavailable, err))
+	}
+	tokenizer, err := tokenizers.FromFile(tokenizerPath)
+	if err != nil {
+		_ = embedder.Close()
+		return nil, NewProviderError("onnx", "init", fmt.Errorf("load tokenizer: %w", err))
+	}
+	return &ONNXProvider{config: cfg, embedder: embedder, tokenizer: tokenizer}, nil
+}
+
+func (p *ONNXProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+	embeddings, err := p.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// EmbedBatch runs inference in MaxBatchSize slices. ONNX Runtime calls do not
+// take a context, so cancellation is checked between slices.
+func (p *ONNXProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	for i, text := range texts {
+		if text == "" {
+			return nil, NewProviderError("onnx", "embedBatch", fmt.Errorf("text %d: %w", i, ErrEmptyText))
+		}
+	}
+	results := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += p.config.MaxBatchSize {
+		if ctx.Err() != nil {
+			return nil, ErrContextCanceled
+		}
+		end := min(start+p.config.MaxBatchSize, len(texts))
+		embeddings, err := p.embedder.EmbedBatch(texts[start:end])
+		if err != nil {
+			return nil, NewProviderError("onnx", "embed", err)
+		}
+		if len(embeddings) != end-start {
+			return nil, NewProviderError("onnx", "embed", fmt.Errorf("model returned %d embeddings for %d texts", len(embeddings), end-start))
+		}
+		for _, embedding := range embeddings {
+			if err := validateEmbeddingDimensions("onnx", embedding, p.config.Dimensions); err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, embeddings...)
+	}
+	return results, nil
+}
+
+func (p *ONNXProvider) Model() string {
+	return p.config.Model
+}
+
+func (p *ONNXProvider) Dimensions() int {
+	return p.config.Dimensions
+}
+
+// Ping embeds a probe text, so a model whose output size differs from
+// embedding.dimensions is reported before indexing starts.
+func (p *ONNXProvider) Ping(ctx context.Context) error {
+	if _, err := p.Embed(ctx, "test"); err != nil {
+		return NewProviderError("onnx", "ping", err)
+	}
+	return nil
+}
+
+// Warmup is a no-op for the onnx provider; the model is loaded by
+// NewONNXProvider.
+func (p *ONNXProvider) Warmup(ctx context.Context) (time.Duration, error) {
+	return 0, nil
+}
+
+// CountTokens tokenizes texts with the model's tokenizer.json, counting the
+// special tokens the model adds.
+func (p *ONNXProvider) CountTokens(ctx context.Context, texts []string) ([]int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	counts := make([]int, len(texts))
+	for i, text := range texts {
+		if ctx.Err() != nil {
+			return nil, ErrContextCanceled
+		}
+		ids, _ := p.tokenizer.Encode(text, true)
+		counts[i] = len(ids)
+	}
+	return counts, nil
+}
+
+// MaxInputTokens returns the sequence length the embedder cuts inputs to.
+func (p *ONNXProvider) MaxInputTokens(context.Context) (int, error) {
+	return onnx.DefaultMaxLength, nil
+}
+
+// Close releases the ONNX Runtime session and tokenizers.
+func (p *ONNXProvider) Close() error {
+	p.mu.Lock()
+	tokenErr := p.tokenizer.Close()
+	p.mu.Unlock()
+	return errors.Join(p.embedder.Close(), tokenErr)
+}
@@ -0,0 +1,20 @@
+package embed
+
+const (
+	defaultONNXModel        = "all-MiniLM-L6-v2"
+	defaultONNXDimensions   = 384
+	defaultONNXMaxBatchSize = 32
+)
+
+// ONNXConfig holds configuration for the onnx embedding provider.
+type ONNXConfig struct {
+	// ModelDir contains model.onnx and tokenizer.json for a
+	// sentence-transformers model exported to ONNX.
+	ModelDir string
+	// LibraryPath is the ONNX Runtime shared library. Empty uses the
+	// ONNXRUNTIME_LIB environment variable or the usual install locations.
+	LibraryPath  string
+	Model        string
+	Dimensions   int
+	MaxBatchSize int
+}
@@ -0,0 +1,37 @@
+//go:build !onnx
+
+package embed
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ONNXProvider is unavailable in builds without the onnx tag.
+type ONNXProvider struct{}
+
+// NewONNXProvider reports that this binary was built without ONNX Runtime.
+func NewONNXProvider(cfg ONNXConfig) (*ONNXProvider, error) {
+	return nil, NewProviderError("onnx", "init", fmt.Errorf("%w: vecgrep was built without ONNX support; rebuild with 'go build -tags onnx'", ErrProviderUnavailable))
+}
+
+func (p *ONNXProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	return nil, ErrProviderUnavailable
+}
+
+func (p *ONNXProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, ErrProviderUnavailable
+}
+
+func (p *ONNXProvider) Model() string { return "" }
+
+func (p *ONNXProvider) Dimensions() int { return 0 }
+
+func (p *ONNXProvider) Ping(ctx context.Context) error { return ErrProviderUnavailable }
+
+func (p *ONNXProvider) Warmup(ctx context.Context) (time.Duration, error) {
+	return 0, ErrProviderUnavailable
+}
+
+func (p *ONNXProvider) Close() error { return nil }
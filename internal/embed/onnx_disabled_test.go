@@ -0,0 +1,19 @@
+//go:build !onnx
+
+package embed
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewONNXProviderWithoutBuildTag(t *testing.T) {
+	_, err := NewONNXProvider(ONNXConfig{ModelDir: t.TempDir()})
+	if !errors.Is(err, ErrProviderUnavailable) {
+		t.Fatalf("NewONNXProvider() error = %v, want ErrProviderUnavailable", err)
+	}
+	if !strings.Contains(err.Error(), "-tags onnx") {
+		t.Fatalf("error %q should explain how to enable ONNX", err)
+	}
+}
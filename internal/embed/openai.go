@@ -64,6 +64,9 @@ func DefaultOpenAIConfig() OpenAIConfig {
 type OpenAIProvider struct {
 	config OpenAIConfig
 	client *http.Client
+	// tokenizer counts tokens with the model's tiktoken encoding; nil when
+	// tiktoken does not know the model.
+	tokenizer *tiktokenTokenizer
 }
 
 // openaiEmbeddingRequest is the request body for OpenAI's embedding endpoint.
@@ -147,6 +150,7 @@ func NewOpenAIProvider(cfg OpenAIConfig) *OpenAIProvider {
 				IdleConnTimeout:     90 * time.Second,
 			},
 		},
+		tokenizer: newTiktokenTokenizer(cfg.Model),
 	}
 }
 
@@ -374,3 +378,13 @@ func (p *OpenAIProvider) Ping(ctx context.Context) error {
 func (p *OpenAIProvider) Warmup(ctx context.Context) (time.Duration, error) {
 	return 0, nil
 }
+
+// Tokenizer returns the model's tiktoken tokenizer, or nil for models
+// tiktoken does not know, such as those of other servers speaking the
+// OpenAI API, whose token counts are then estimated.
+func (p *OpenAIProvider) Tokenizer() Tokenizer {
+	if p.tokenizer == nil {
+		return nil
+	}
+	return p.tokenizer
+}
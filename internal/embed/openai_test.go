@@ -280,6 +280,31 @@ func TestOpenAIProvider_Dimensions(t *testing.T) {
 	}
 }
 
+func TestOpenAIProvider_CountsTokensWithTiktoken(t *testing.T) {
+	ctx := context.Background()
+	for _, model := range []string{"text-embedding-3-small", "gpt-4o"} {
+		tokenizer := TokenizerOf(NewMeteredProvider(NewOpenAIProvider(OpenAIConfig{APIKey: "test-key", Model: model}), NewUsageMeter()))
+		if tokenizer == nil {
+			t.Fatalf("%s: no tokenizer", model)
+		}
+		counts, err := tokenizer.CountTokens(ctx, []string{"hello world", "func main() {}"})
+		if err != nil {
+			t.Fatalf("%s: CountTokens: %v", model, err)
+		}
+		if len(counts) != 2 || counts[0] != 2 || counts[1] != 4 {
+			t.Errorf("%s: counts = %v, want [2 4]", model, counts)
+		}
+	}
+
+	limit, err := NewOpenAIProvider(OpenAIConfig{APIKey: "test-key", Model: "text-embedding-3-large"}).Tokenizer().MaxInputTokens(ctx)
+	if err != nil || limit != 8191 {
+		t.Errorf("MaxInputTokens = %d, %v; want 8191", limit, err)
+	}
+	if tokenizer := TokenizerOf(NewOpenAIProvider(OpenAIConfig{APIKey: "test-key", Model: "nomic-embed-text"})); tokenizer != nil {
+		t.Errorf("nomic-embed-text has a tokenizer, want its counts estimated")
+	}
+}
+
 func TestDefaultOpenAIConfig(t *testing.T) {
 	cfg := DefaultOpenAIConfig()
 
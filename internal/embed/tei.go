@@ -0,0 +1,349 @@
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTEIURL          = "http://localhost:8080"
+	defaultTEITimeout      = 60 * time.Second
+	defaultTEIMaxRetries   = 3
+	defaultTEIRetryDelay   = 1 * time.Second
+	defaultTEIMaxBatchSize = 32
+
+	// TEI truncation settings. TEITruncateNone sends inputs untruncated, so
+	// TEI rejects any input longer than the model's maximum sequence length.
+	TEITruncateRight = "right"
+	TEITruncateLeft  = "left"
+	TEITruncateNone  = "none"
+)
+
+// errTEIRetryable marks responses worth retrying: TEI answers 429 when its
+// request queue is full and 503 while the model is still loading.
+var errTEIRetryable = errors.New("tei server busy")
+
+// TEIConfig holds configuration for the text-embeddings-inference provider.
+type TEIConfig struct {
+	URL string
+	// APIKey is sent as a bearer token when set, for TEI behind a gateway or
+	// a Hugging Face Inference Endpoint.
+	APIKey     string
+	Model      string
+	Dimensions int
+	// MaxBatchSize caps the inputs per /embed request. It must not exceed the
+	// server's --max-client-batch-size (32 by default).
+	MaxBatchSize int
+	// Truncation is TEITruncateRight (default), TEITruncateLeft, or
+	// TEITruncateNone.
+	Truncation    string
+	Timeout       time.Duration
+	MaxRetries    int
+	RetryInterval time.Duration
+}
+
+// TEIProvider implements Provider using a Hugging Face
+// text-embeddings-inference server. TEI serves a single model chosen when
+// the server starts, so Model is only recorded in the index profile.
+type TEIProvider struct {
+	config TEIConfig
+	client *http.Client
+}
+
+type teiEmbedRequest struct {
+	Inputs              []string `json:"inputs"`
+	Normalize           bool     `json:"normalize"`
+	Truncate            bool     `json:"truncate"`
+	TruncationDirection string   `json:"truncation_direction,omitempty"`
+}
+
+type teiErrorResponse struct {
+	Error     string `json:"error"`
+	ErrorType string `json:"error_type"`
+}
+
+// NewTEIProvider returns a TEI provider. Dimensions are required because the
+// served model is not known until the server is queried.
+func NewTEIProvider(cfg TEIConfig) (*TEIProvider, error) {
+	if cfg.Dimensions <= 0 {
+		return nil, NewProviderError("tei", "init", fmt.Errorf("embedding.dimensions must be set for the tei provider"))
+	}
+	switch cfg.Truncation {
+	case "":
+		cfg.Truncation = TEITruncateRight
+	case TEITruncateRight, TEITruncateLeft, TEITruncateNone:
+	default:
+		return nil, NewProviderError("tei", "init", fmt.Errorf("invalid embedding.tei_truncation %q: expected right, left, or none", cfg.Truncation))
+	}
+	if cfg.URL == "" {
+		cfg.URL = defaultTEIURL
+	}
+	if cfg.MaxBatchSize <= 0 {
+		cfg.MaxBatchSize = defaultTEIMaxBatchSize
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTEITimeout
+	}
+	if cfg.MaxRetries == 0 {
+		cfg.MaxRetries = defaultTEIMaxRetries
+	}
+	if cfg.RetryInterval == 0 {
+		cfg.RetryInterval = defaultTEIRetryDelay
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+
+	return &TEIProvider{
+		config: cfg,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+			Transport: &http.Transport{
+				MaxIdleConns:        100,
+				MaxIdleConnsPerHost: 100,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}, nil
+}
+
+func (p *TEIProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+	embeddings, err := p.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+func (p *TEIProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	for i, text := range texts {
+		if text == "" {
+			return nil, NewProviderError("tei", "embedBatch", fmt.Errorf("text %d: %w", i, ErrEmptyText))
+		}
+	}
+	results := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += p.config.MaxBatchSize {
+		end := min(start+p.config.MaxBatchSize, len(texts))
+		embeddings, err := p.embedWithRetry(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, embeddings...)
+	}
+	return results, nil
+}
+
+func (p *TEIProvider) embedWithRetry(ctx context.Context, texts []string) ([][]float32, error) {
+	var lastErr error
+	for attempt := 0; attempt < p.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, NewProviderError("tei", "embed", ErrContextCanceled)
+			case <-time.After(p.config.RetryInterval * time.Duration(1<<uint(attempt-1))):
+			}
+		}
+
+		embeddings, err := p.doEmbed(ctx, texts)
+		if err == nil {
+			return embeddings, nil
+		}
+		lastErr = err
+		if !errors.Is(err, errTEIRetryable) {
+			return nil, NewProviderError("tei", "embed", err)
+		}
+	}
+	return nil, NewProviderError("tei", "embed", lastErr)
+}
+
+func (p *TEIProvider) doEmbed(ctx context.Context, texts []string) ([][]float32, error) {
+	reqBody := teiEmbedRequest{
+		Inputs:    texts,
+		Normalize: true,
+		Truncate:  p.config.Truncation != TEITruncateNone,
+	}
+	if reqBody.Truncate {
+		// TEI spells the direction "Right" or "Left".
+		reqBody.TruncationDirection = strings.ToUpper(p.config.Truncation[:1]) + p.config.Truncation[1:]
+	}
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", p.config.URL+"/embed", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ErrContextCanceled
+		}
+		return nil, fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		message := teiErrorMessage(body)
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return nil, fmt.Errorf("%w: status %d: %s", errTEIRetryable, resp.StatusCode, message)
+		case http.StatusRequestEntityTooLarge:
+			return nil, fmt.Errorf("batch of %d inputs rejected (lower embedding.max_batch_size to the server's --max-client-batch-size): %s", len(texts), message)
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return nil, fmt.Errorf("unauthorized (check embedding.tei_api_key): %s", message)
+		}
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, message)
+	}
+
+	var embeddings [][]float32
+	if err := json.Unmarshal(body, &embeddings); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("server returned %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+	for _, embedding := range embeddings {
+		if err := validateEmbeddingDimensions("tei", embedding, p.config.Dimensions); err != nil {
+			return nil, err
+		}
+	}
+	return embeddings, nil
+}
+
+func (p *TEIProvider) Model() string {
+	return p.config.Model
+}
+
+func (p *TEIProvider) Dimensions() int {
+	return p.config.Dimensions
+}
+
+// Ping embeds a probe text, so an unreachable server or a served model whose
+// output size differs from embedding.dimensions is reported before indexing.
+func (p *TEIProvider) Ping(ctx context.Context) error {
+	if _, err := p.Embed(ctx, "test"); err != nil {
+		return NewProviderError("tei", "ping", err)
+	}
+	return nil
+}
+
+// Warmup is a no-op for the TEI provider; the server loads its model at
+// startup.
+func (p *TEIProvider) Warmup(ctx context.Context) (time.Duration, error) {
+	return 0, nil
+}
+
+type teiTokenizeRequest struct {
+	Inputs           []string `json:"inputs"`
+	AddSpecialTokens bool     `json:"add_special_tokens"`
+}
+
+type teiToken struct {
+	ID int `json:"id"`
+}
+
+type teiInfoResponse struct {
+	MaxInputLength int `json:"max_input_length"`
+}
+
+// CountTokens tokenizes texts with the served model's tokenizer through
+// TEI's /tokenize endpoint, counting the special tokens the model adds.
+func (p *TEIProvider) CountTokens(ctx context.Context, texts []string) ([]int, error) {
+	counts := make([]int, 0, len(texts))
+	for start := 0; start < len(texts); start += p.config.MaxBatchSize {
+		end := min(start+p.config.MaxBatchSize, len(texts))
+		var tokens [][]teiToken
+		if err := p.call(ctx, "POST", "/tokenize", teiTokenizeRequest{Inputs: texts[start:end], AddSpecialTokens: true}, &tokens); err != nil {
+			return nil, NewProviderError("tei", "tokenize", err)
+		}
+		if len(tokens) != end-start {
+			return nil, NewProviderError("tei", "tokenize", fmt.Errorf("server returned %d token lists for %d texts", len(tokens), end-start))
+		}
+		for _, list := range tokens {
+			counts = append(counts, len(list))
+		}
+	}
+	return counts, nil
+}
+
+// MaxInputTokens returns the served model's maximum sequence length from
+// TEI's /info endpoint.
+func (p *TEIProvider) MaxInputTokens(ctx context.Context) (int, error) {
+	var info teiInfoResponse
+	if err := p.call(ctx, "GET", "/info", nil, &info); err != nil {
+		return 0, NewProviderError("tei", "info", err)
+	}
+	return info.MaxInputLength, nil
+}
+
+// call sends one request to the TEI server and decodes its JSON response.
+func (p *TEIProvider) call(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.config.URL+path, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if p.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ErrContextCanceled
+		}
+		return fmt.Errorf("%w: %v", ErrProviderUnavailable, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, teiErrorMessage(data))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("unmarshal response: %w", err)
+	}
+	return nil
+}
+
+func teiErrorMessage(body []byte) string {
+	var errResp teiErrorResponse
+	if json.Unmarshal(body, &errResp) == nil && errResp.Error != "" {
+		return errResp.Error
+	}
+	return string(body)
+}
@@ -0,0 +1,167 @@
+package embed
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTEIProviderBatchesAndTruncates(t *testing.T) {
+	var requests []teiEmbedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/embed" {
+			t.Fatalf("request = %s %s, want POST /embed", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer tei-key" {
+			t.Fatalf("Authorization = %q, want Bearer tei-key", got)
+		}
+		var req teiEmbedRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		requests = append(requests, req)
+		embeddings := make([][]float32, len(req.Inputs))
+		for i := range embeddings {
+			embeddings[i] = []float32{float32(len(req.Inputs[i])), 0}
+		}
+		_ = json.NewEncoder(w).Encode(embeddings)
+	}))
+	defer server.Close()
+
+	provider, err := NewTEIProvider(TEIConfig{
+		URL:          server.URL + "/",
+		APIKey:       "tei-key",
+		Model:        "BAAI/bge-small-en-v1.5",
+		Dimensions:   2,
+		MaxBatchSize: 2,
+		Truncation:   TEITruncateLeft,
+	})
+	if err != nil {
+		t.Fatalf("NewTEIProvider: %v", err)
+	}
+
+	vecs, err := provider.EmbedBatch(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("EmbedBatch: %v", err)
+	}
+	if len(vecs) != 3 || vecs[0][0] != 1 || vecs[2][0] != 3 {
+		t.Fatalf("embeddings = %v, want input order preserved", vecs)
+	}
+	if len(requests) != 2 || len(requests[0].Inputs) != 2 || len(requests[1].Inputs) != 1 {
+		t.Fatalf("requests = %+v, want batches of 2 and 1", requests)
+	}
+	if req := requests[0]; !req.Truncate || req.TruncationDirection != "Left" || !req.Normalize {
+		t.Fatalf("request = %+v, want truncate Left and normalize", req)
+	}
+}
+
+func TestTEIProviderTruncationNone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if req["truncate"] != false {
+			t.Fatalf("truncate = %v, want false", req["truncate"])
+		}
+		if _, ok := req["truncation_direction"]; ok {
+			t.Fatalf("truncation_direction sent with truncation disabled")
+		}
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		_, _ = w.Write([]byte(`{"error":"batch size 3 > maximum allowed batch size 2","error_type":"Validation"}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewTEIProvider(TEIConfig{URL: server.URL, Dimensions: 2, Truncation: TEITruncateNone})
+	if err != nil {
+		t.Fatalf("NewTEIProvider: %v", err)
+	}
+	_, err = provider.Embed(context.Background(), "text")
+	if err == nil || !strings.Contains(err.Error(), "embedding.max_batch_size") || !strings.Contains(err.Error(), "maximum allowed batch size") {
+		t.Fatalf("Embed() error = %v, want batch size hint with server message", err)
+	}
+}
+
+func TestTEIProviderRetriesWhileServerBusy(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":"model is loading","error_type":"Unhealthy"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[[0.6,0.8]]`))
+	}))
+	defer server.Close()
+
+	provider, err := NewTEIProvider(TEIConfig{URL: server.URL, Dimensions: 2, RetryInterval: time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewTEIProvider: %v", err)
+	}
+	if err := provider.Ping(context.Background()); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Fatalf("calls = %d, want 2", calls.Load())
+	}
+}
+
+func TestNewTEIProviderValidatesConfig(t *testing.T) {
+	if _, err := NewTEIProvider(TEIConfig{}); err == nil {
+		t.Fatal("NewTEIProvider() without dimensions succeeded")
+	}
+	if _, err := NewTEIProvider(TEIConfig{Dimensions: 2, Truncation: "middle"}); err == nil {
+		t.Fatal("NewTEIProvider() with invalid truncation succeeded")
+	}
+}
+
+func TestTEIProviderCountsTokensAndReportsLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/info":
+			_, _ = w.Write([]byte(`{"model_id":"BAAI/bge-small-en-v1.5","max_input_length":512}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/tokenize":
+			var req teiTokenizeRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Fatalf("decode request: %v", err)
+			}
+			if !req.AddSpecialTokens {
+				t.Fatal("tokenize request left out special tokens")
+			}
+			tokens := make([][]teiToken, len(req.Inputs))
+			for i, input := range req.Inputs {
+				// One token per byte plus [CLS] and [SEP].
+				tokens[i] = make([]teiToken, len(input)+2)
+			}
+			_ = json.NewEncoder(w).Encode(tokens)
+		default:
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider, err := NewTEIProvider(TEIConfig{URL: server.URL, Dimensions: 2, MaxBatchSize: 2})
+	if err != nil {
+		t.Fatalf("NewTEIProvider: %v", err)
+	}
+	if TokenizerOf(NewMeteredProvider(NewNormalizedProvider(provider), NewUsageMeter())) == nil {
+		t.Fatal("wrapped TEI provider lost its tokenizer")
+	}
+
+	counts, err := provider.CountTokens(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("CountTokens: %v", err)
+	}
+	if len(counts) != 3 || counts[0] != 3 || counts[2] != 5 {
+		t.Fatalf("counts = %v, want [3 4 5]", counts)
+	}
+	limit, err := provider.MaxInputTokens(context.Background())
+	if err != nil || limit != 512 {
+		t.Fatalf("MaxInputTokens = %d, %v; want 512", limit, err)
+	}
+}
@@ -163,7 +163,7 @@ func NewThrottledProvider(inner Provider, cfg ThrottleConfig) *ThrottledProvider
 		if cfg.CachePath != "" {
 			dc, err := NewPersistentCache(cfg.CacheSize, cfg.CachePath)
 			if err == nil {
-				dc.SetModel(inner.Model())
+				dc.SetModel(diskCacheNamespace(inner))
 				p.diskCache = dc
 				p.cache = dc
 			} else {
@@ -409,6 +409,42 @@ func (p *ThrottledProvider) Cache() embeddingCache {
 	return p.cache
 }
 
+// Usage returns the inner provider's meter, or nil when it is not metered.
+// Cache hits never reach the inner provider, so they are not counted.
+func (p *ThrottledProvider) Usage() *UsageMeter {
+	return UsageOf(p.inner)
+}
+
+// Normalization reports the inner provider's normalization policy.
+func (p *ThrottledProvider) Normalization() string {
+	return NormalizationOf(p.inner)
+}
+
+// Tokenizer returns the inner provider's tokenizer, or nil.
+func (p *ThrottledProvider) Tokenizer() Tokenizer {
+	return TokenizerOf(p.inner)
+}
+
+// Uncached returns provider without its throttle layer, so probe texts reach
+// the model instead of the cache and are never stored in it. Other providers
+// are returned unchanged.
+func Uncached(provider Provider) Provider {
+	if throttled, ok := provider.(*ThrottledProvider); ok {
+		return throttled.inner
+	}
+	return provider
+}
+
+// diskCacheNamespace returns the model namespace for disk cache keys.
+// Normalized providers get their own namespace so vectors cached before
+// normalization was enforced are never served as normalized ones.
+func diskCacheNamespace(inner Provider) string {
+	if normalization := NormalizationOf(inner); normalization != "" {
+		return inner.Model() + ":" + normalization
+	}
+	return inner.Model()
+}
+
 // Flush persists every disk-cache write queued before this call without
 // shutting down the provider. In-memory caches require no flush.
 func (p *ThrottledProvider) Flush() error {
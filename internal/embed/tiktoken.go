@@ -0,0 +1,91 @@
+package embed
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktokenloader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// openAIMaxInputTokens is the input limit of OpenAI's text-embedding models.
+const openAIMaxInputTokens = 8191
+
+// useOfflineBPE makes tiktoken read its encodings from the ranks compiled
+// into the binary instead of downloading them.
+var useOfflineBPE sync.Once
+
+// tiktokenTokenizer counts tokens with a tiktoken encoding, cl100k_base or
+// o200k_base, the way OpenAI's API does. The encoding is loaded on first
+// use.
+type tiktokenTokenizer struct {
+	model    string
+	encoding string
+
+	once sync.Once
+	tk   *tiktoken.Tiktoken
+	err  error
+}
+
+// newTiktokenTokenizer returns a tokenizer for an OpenAI model, or nil when
+// the model uses neither cl100k_base nor o200k_base.
+func newTiktokenTokenizer(model string) *tiktokenTokenizer {
+	encoding := tiktokenEncoding(model)
+	if encoding != tiktoken.MODEL_CL100K_BASE && encoding != tiktoken.MODEL_O200K_BASE {
+		return nil
+	}
+	return &tiktokenTokenizer{model: model, encoding: encoding}
+}
+
+// tiktokenEncoding names the encoding tiktoken uses for model, or "" when
+// it does not know the model.
+func tiktokenEncoding(model string) string {
+	if encoding, ok := tiktoken.MODEL_TO_ENCODING[model]; ok {
+		return encoding
+	}
+	for prefix, encoding := range tiktoken.MODEL_PREFIX_TO_ENCODING {
+		if strings.HasPrefix(model, prefix) {
+			return encoding
+		}
+	}
+	return ""
+}
+
+func (t *tiktokenTokenizer) load() (*tiktoken.Tiktoken, error) {
+	t.once.Do(func() {
+		useOfflineBPE.Do(func() { tiktoken.SetBpeLoader(tiktokenloader.NewOfflineLoader()) })
+		t.tk, t.err = tiktoken.GetEncoding(t.encoding)
+		if t.err != nil {
+			t.err = NewProviderError("openai", "tokenize", fmt.Errorf("load %s: %w", t.encoding, t.err))
+		}
+	})
+	return t.tk, t.err
+}
+
+// CountTokens encodes each text, treating special-token text as ordinary
+// text as the embeddings API does.
+func (t *tiktokenTokenizer) CountTokens(ctx context.Context, texts []string) ([]int, error) {
+	tk, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]int, len(texts))
+	for i, text := range texts {
+		if ctx.Err() != nil {
+			return nil, ErrContextCanceled
+		}
+		counts[i] = len(tk.EncodeOrdinary(text))
+	}
+	return counts, nil
+}
+
+// MaxInputTokens returns the text-embedding models' limit, or 0 for other
+// models.
+func (t *tiktokenTokenizer) MaxInputTokens(context.Context) (int, error) {
+	if strings.HasPrefix(t.model, "text-embedding-") {
+		return openAIMaxInputTokens, nil
+	}
+	return 0, nil
+}
@@ -0,0 +1,52 @@
+package embed
+
+import "context"
+
+// Tokenizer counts tokens the way an embedding model does, so callers can
+// find inputs the model would truncate before sending them.
+type Tokenizer interface {
+	// CountTokens returns the token count of each text, in order.
+	CountTokens(ctx context.Context, texts []string) ([]int, error)
+	// MaxInputTokens returns the longest input the model embeds without
+	// truncating it, or 0 when the model does not say.
+	MaxInputTokens(ctx context.Context) (int, error)
+}
+
+// TokenizerReporter is implemented by provider wrappers that forward the
+// tokenizer of the provider they wrap.
+type TokenizerReporter interface {
+	Tokenizer() Tokenizer
+}
+
+// TokenizerOf returns the model tokenizer behind a provider chain, or nil
+// when the provider cannot count tokens exactly.
+func TokenizerOf(provider Provider) Tokenizer {
+	if reporter, ok := provider.(TokenizerReporter); ok {
+		return reporter.Tokenizer()
+	}
+	if tokenizer, ok := provider.(Tokenizer); ok {
+		return tokenizer
+	}
+	return nil
+}
+
+// EstimatedTokenizer approximates token counts from text length with
+// EstimateTokens, for providers that expose no tokenizer. Limit is the
+// configured input limit it reports.
+type EstimatedTokenizer struct {
+	Limit int
+}
+
+// CountTokens estimates the token count of each text.
+func (t EstimatedTokenizer) CountTokens(_ context.Context, texts []string) ([]int, error) {
+	counts := make([]int, len(texts))
+	for i, text := range texts {
+		counts[i] = int(EstimateTokens(text))
+	}
+	return counts, nil
+}
+
+// MaxInputTokens returns the configured limit.
+func (t EstimatedTokenizer) MaxInputTokens(context.Context) (int, error) {
+	return t.Limit, nil
+}
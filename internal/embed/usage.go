@@ -0,0 +1,320 @@
+package embed
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBudgetExceeded is returned by a metered provider when sending a request
+// would push the current run past its token budget.
+var ErrBudgetExceeded = errors.New("embedding budget exceeded")
+
+// approximateCharsPerToken mirrors the chunk-size conversion used by the
+// indexer. Providers do not all report usage, so token counts are estimates.
+const approximateCharsPerToken = 4
+
+// EstimateTokens approximates the billable token count of a text.
+func EstimateTokens(text string) int64 {
+	if text == "" {
+		return 0
+	}
+	return int64((len(text) + approximateCharsPerToken - 1) / approximateCharsPerToken)
+}
+
+// Usage is a snapshot of metered embedding traffic.
+type Usage struct {
+	Requests int64 `json:"requests"`
+	Texts    int64 `json:"texts"`
+	Tokens   int64 `json:"tokens"`
+}
+
+// Add returns the element-wise sum of two usage snapshots.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		Requests: u.Requests + other.Requests,
+		Texts:    u.Texts + other.Texts,
+		Tokens:   u.Tokens + other.Tokens,
+	}
+}
+
+// Sub returns the usage accumulated since an earlier snapshot.
+func (u Usage) Sub(earlier Usage) Usage {
+	return Usage{
+		Requests: u.Requests - earlier.Requests,
+		Texts:    u.Texts - earlier.Texts,
+		Tokens:   u.Tokens - earlier.Tokens,
+	}
+}
+
+// IsZero reports whether no traffic was recorded.
+func (u Usage) IsZero() bool {
+	return u.Requests == 0 && u.Texts == 0 && u.Tokens == 0
+}
+
+// UsageMeter counts requests, texts, and estimated tokens sent to an upstream
+// provider. It is safe for concurrent use.
+type UsageMeter struct {
+	requests atomic.Int64
+	texts    atomic.Int64
+	tokens   atomic.Int64
+
+	// mu makes checking the budget and reserving tokens one step, so
+	// concurrent batches cannot all pass the check and then overspend.
+	mu sync.Mutex
+	// runBaseline is the token count when the current budgeted run began.
+	runBaseline int64
+	// runBudget is the token budget for the current run; zero disables it.
+	runBudget int64
+	// reserved counts the tokens of requests admitted but not yet settled.
+	reserved int64
+}
+
+// NewUsageMeter returns an empty meter.
+func NewUsageMeter() *UsageMeter {
+	return &UsageMeter{}
+}
+
+// Snapshot returns the meter's cumulative counters.
+func (m *UsageMeter) Snapshot() Usage {
+	if m == nil {
+		return Usage{}
+	}
+	return Usage{
+		Requests: m.requests.Load(),
+		Texts:    m.texts.Load(),
+		Tokens:   m.tokens.Load(),
+	}
+}
+
+// BeginRun starts a budgeted run and returns the counters at its start. A
+// budget of zero or less disables enforcement for the run.
+func (m *UsageMeter) BeginRun(budgetTokens int64) Usage {
+	if m == nil {
+		return Usage{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	start := m.Snapshot()
+	m.runBaseline = start.Tokens
+	m.runBudget = max(budgetTokens, 0)
+	return start
+}
+
+// EndRun clears the run budget so later traffic (e.g. searches in a daemon)
+// is not rejected.
+func (m *UsageMeter) EndRun() {
+	if m != nil {
+		m.mu.Lock()
+		m.runBudget = 0
+		m.mu.Unlock()
+	}
+}
+
+// reserve holds tokens against the current run budget until record
+// settles them, failing when the recorded and reserved tokens would exceed
+// it.
+func (m *UsageMeter) reserve(tokens int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.runBudget > 0 && m.tokens.Load()-m.runBaseline+m.reserved+tokens > m.runBudget {
+		return ErrBudgetExceeded
+	}
+	m.reserved += tokens
+	return nil
+}
+
+// record releases a reservation of reserved tokens and, when the request
+// was sent, counts the tokens of texts in its place.
+func (m *UsageMeter) record(reserved int64, texts []string, sent bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.reserved -= reserved
+	if !sent {
+		return
+	}
+	m.requests.Add(1)
+	m.texts.Add(int64(len(texts)))
+	m.tokens.Add(estimateTokensOf(texts))
+}
+
+func estimateTokensOf(texts []string) int64 {
+	var tokens int64
+	for _, text := range texts {
+		tokens += EstimateTokens(text)
+	}
+	return tokens
+}
+
+// UsageReporter is implemented by providers that expose a UsageMeter.
+type UsageReporter interface {
+	Usage() *UsageMeter
+}
+
+// UsageOf returns the meter behind a provider chain, or nil when the provider
+// is not metered.
+func UsageOf(provider Provider) *UsageMeter {
+	if reporter, ok := provider.(UsageReporter); ok {
+		return reporter.Usage()
+	}
+	return nil
+}
+
+// MeteredProvider records upstream embedding traffic and enforces the run
+// budget. It belongs directly above the raw provider (below any cache) so
+// cache hits, which cost nothing, are not counted.
+type MeteredProvider struct {
+	inner Provider
+	meter *UsageMeter
+}
+
+// meteredDocumentProvider adds EmbedDocuments only when the inner provider
+// supports it, so callers that type-assert DocumentProvider (the throttle's
+// native batch path) see the same capabilities as the unwrapped provider.
+type meteredDocumentProvider struct {
+	*MeteredProvider
+	documents DocumentProvider
+}
+
+// NewMeteredProvider wraps inner so every request is counted by meter.
+func NewMeteredProvider(inner Provider, meter *UsageMeter) Provider {
+	if meter == nil {
+		meter = NewUsageMeter()
+	}
+	metered := &MeteredProvider{inner: inner, meter: meter}
+	if documents, ok := inner.(DocumentProvider); ok {
+		return &meteredDocumentProvider{MeteredProvider: metered, documents: documents}
+	}
+	return metered
+}
+
+// admit reserves the tokens of texts, returning how many for record.
+func (p *MeteredProvider) admit(texts []string) (int64, error) {
+	tokens := estimateTokensOf(texts)
+	if err := p.meter.reserve(tokens); err != nil {
+		return 0, NewProviderError(p.inner.Model(), "budget", err)
+	}
+	return tokens, nil
+}
+
+// Embed generates an embedding for one text.
+func (p *MeteredProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	texts := []string{text}
+	reserved, err := p.admit(texts)
+	if err != nil {
+		return nil, err
+	}
+	vec, err := p.inner.Embed(ctx, text)
+	p.meter.record(reserved, texts, err == nil)
+	return vec, err
+}
+
+// EmbedBatch generates embeddings for several texts in one request.
+func (p *MeteredProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	reserved, err := p.admit(texts)
+	if err != nil {
+		return nil, err
+	}
+	vecs, err := p.inner.EmbedBatch(ctx, texts)
+	p.meter.record(reserved, texts, err == nil)
+	return vecs, err
+}
+
+// EmbedQuery uses the inner provider's query embedding when available.
+func (p *MeteredProvider) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	queryProvider, ok := p.inner.(QueryProvider)
+	if !ok {
+		return p.Embed(ctx, text)
+	}
+	texts := []string{text}
+	reserved, err := p.admit(texts)
+	if err != nil {
+		return nil, err
+	}
+	vec, err := queryProvider.EmbedQuery(ctx, text)
+	p.meter.record(reserved, texts, err == nil)
+	return vec, err
+}
+
+// EmbedDocuments delegates to the inner DocumentProvider.
+func (p *meteredDocumentProvider) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	reserved, err := p.admit(texts)
+	if err != nil {
+		return nil, err
+	}
+	vecs, err := p.documents.EmbedDocuments(ctx, texts)
+	p.meter.record(reserved, texts, err == nil)
+	return vecs, err
+}
+
+// Model returns the inner provider's model.
+func (p *MeteredProvider) Model() string { return p.inner.Model() }
+
+// Dimensions returns the inner provider's dimensions.
+func (p *MeteredProvider) Dimensions() int { return p.inner.Dimensions() }
+
+// Ping checks the inner provider without counting traffic.
+func (p *MeteredProvider) Ping(ctx context.Context) error { return p.inner.Ping(ctx) }
+
+// Warmup delegates to the inner provider without counting traffic.
+func (p *MeteredProvider) Warmup(ctx context.Context) (time.Duration, error) {
+	return p.inner.Warmup(ctx)
+}
+
+// Usage returns the meter recording this provider's traffic.
+func (p *MeteredProvider) Usage() *UsageMeter { return p.meter }
+
+// Normalization reports the inner provider's normalization policy.
+func (p *MeteredProvider) Normalization() string { return NormalizationOf(p.inner) }
+
+// Tokenizer returns the inner provider's tokenizer, or nil.
+func (p *MeteredProvider) Tokenizer() Tokenizer { return TokenizerOf(p.inner) }
+
+// openAIPricePerMillionTokens lists published OpenAI embedding prices (USD per
+// one million input tokens). Local providers are free; other hosted providers
+// are not estimated.
+var openAIPricePerMillionTokens = map[string]float64{
+	"text-embedding-3-small": 0.02,
+	"text-embedding-3-large": 0.13,
+	"text-embedding-ada-002": 0.10,
+}
+
+// PricePerMillionTokens returns the estimated USD price per one million tokens
+// for a provider/model pair. ok is false when no estimate is known.
+func PricePerMillionTokens(provider, model string) (price float64, ok bool) {
+	switch provider {
+	case "ollama", "":
+		return 0, true
+	case "openai":
+		price, ok = openAIPricePerMillionTokens[strings.TrimSpace(model)]
+		return price, ok
+	default:
+		return 0, false
+	}
+}
+
+// EstimateCostUSD converts a token count into estimated spend.
+func EstimateCostUSD(provider, model string, tokens int64) (float64, bool) {
+	price, ok := PricePerMillionTokens(provider, model)
+	if !ok {
+		return 0, false
+	}
+	return float64(tokens) * price / 1_000_000, true
+}
+
+// BudgetTokens converts a USD budget into a token budget for the provider.
+// It returns zero (no enforcement) when the budget is unset or the provider's
+// price is unknown or free.
+func BudgetTokens(provider, model string, budgetUSD float64) int64 {
+	if budgetUSD <= 0 {
+		return 0
+	}
+	price, ok := PricePerMillionTokens(provider, model)
+	if !ok || price <= 0 {
+		return 0
+	}
+	return int64(budgetUSD / price * 1_000_000)
+}
@@ -0,0 +1,165 @@
+package embed
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// batchOnlyProvider hides mockProvider's EmbedDocuments so tests can check
+// that metering does not invent capabilities the inner provider lacks.
+type batchOnlyProvider struct {
+	Provider
+}
+
+func TestMeteredProviderCountsUpstreamTraffic(t *testing.T) {
+	inner := &mockProvider{}
+	meter := NewUsageMeter()
+	provider := NewMeteredProvider(inner, meter)
+
+	if _, err := provider.Embed(context.Background(), "abcdefgh"); err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	docs, ok := provider.(DocumentProvider)
+	if !ok {
+		t.Fatal("metered provider should expose EmbedDocuments when the inner provider does")
+	}
+	if _, err := docs.EmbedDocuments(context.Background(), []string{"abcd", "abcde"}); err != nil {
+		t.Fatalf("EmbedDocuments: %v", err)
+	}
+
+	got := meter.Snapshot()
+	want := Usage{Requests: 2, Texts: 3, Tokens: 2 + 1 + 2}
+	if got != want {
+		t.Fatalf("usage = %+v, want %+v", got, want)
+	}
+	if UsageOf(provider) != meter {
+		t.Fatal("UsageOf should return the provider's meter")
+	}
+}
+
+func TestMeteredProviderPreservesMissingDocumentCapability(t *testing.T) {
+	provider := NewMeteredProvider(batchOnlyProvider{Provider: &mockProvider{}}, nil)
+	if _, ok := provider.(DocumentProvider); ok {
+		t.Fatal("metered provider must not expose EmbedDocuments for a batch-only provider")
+	}
+}
+
+func TestMeteredProviderFailedRequestIsNotCounted(t *testing.T) {
+	inner := &mockProvider{embedFunc: func(context.Context, string) ([]float32, error) {
+		return nil, ErrProviderUnavailable
+	}}
+	meter := NewUsageMeter()
+	provider := NewMeteredProvider(inner, meter)
+	if _, err := provider.Embed(context.Background(), "text"); err == nil {
+		t.Fatal("expected error")
+	}
+	if !meter.Snapshot().IsZero() {
+		t.Fatalf("failed request was counted: %+v", meter.Snapshot())
+	}
+}
+
+func TestMeteredProviderEnforcesRunBudget(t *testing.T) {
+	inner := &mockProvider{}
+	meter := NewUsageMeter()
+	provider := NewMeteredProvider(inner, meter)
+
+	meter.BeginRun(3)
+	if _, err := provider.EmbedBatch(context.Background(), []string{"12345678"}); err != nil {
+		t.Fatalf("within budget: %v", err)
+	}
+	_, err := provider.EmbedBatch(context.Background(), []string{"12345678"})
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("err = %v, want ErrBudgetExceeded", err)
+	}
+	if inner.batchCalls.Load() != 1 {
+		t.Fatalf("over-budget request reached the provider: %d calls", inner.batchCalls.Load())
+	}
+
+	meter.EndRun()
+	if _, err := provider.EmbedBatch(context.Background(), []string{"12345678"}); err != nil {
+		t.Fatalf("after EndRun: %v", err)
+	}
+}
+
+func TestMeteredProviderConcurrentBatchesStayWithinBudget(t *testing.T) {
+	release := make(chan struct{})
+	inner := &mockProvider{embedBatchFunc: func(_ context.Context, texts []string) ([][]float32, error) {
+		<-release // hold every admitted batch in flight together
+		return make([][]float32, len(texts)), nil
+	}}
+	meter := NewUsageMeter()
+	provider := NewMeteredProvider(inner, meter)
+	meter.BeginRun(10) // room for five 2-token batches
+
+	const batches = 20
+	var (
+		wg       sync.WaitGroup
+		rejected atomic.Int32
+	)
+	for range batches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := provider.EmbedBatch(context.Background(), []string{"12345678"}); errors.Is(err, ErrBudgetExceeded) {
+				rejected.Add(1)
+			}
+		}()
+	}
+	for rejected.Load()+inner.batchCalls.Load() < batches {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := meter.Snapshot().Tokens; got != 10 {
+		t.Fatalf("tokens = %d, want the budget of 10", got)
+	}
+	if calls := inner.batchCalls.Load(); calls != 5 {
+		t.Fatalf("provider calls = %d, want 5", calls)
+	}
+}
+
+func TestThrottledProviderReportsInnerUsageWithoutCacheHits(t *testing.T) {
+	meter := NewUsageMeter()
+	throttled := NewThrottledProvider(NewMeteredProvider(&mockProvider{}, meter), ThrottleConfig{CacheSize: 10})
+	defer throttled.Close()
+
+	for range 2 {
+		if _, err := throttled.EmbedDocuments(context.Background(), []string{"same text"}); err != nil {
+			t.Fatalf("EmbedDocuments: %v", err)
+		}
+	}
+	if UsageOf(throttled) != meter {
+		t.Fatal("throttled provider should expose the inner meter")
+	}
+	if got := meter.Snapshot().Requests; got != 1 {
+		t.Fatalf("requests = %d, want 1 (cache hit must not be metered)", got)
+	}
+}
+
+func TestEstimateCostAndBudgetTokens(t *testing.T) {
+	cost, ok := EstimateCostUSD("openai", "text-embedding-3-small", 1_000_000)
+	if !ok || cost != 0.02 {
+		t.Fatalf("cost = %v, %v; want 0.02, true", cost, ok)
+	}
+	if _, ok := EstimateCostUSD("cohere", "embed-english-v3.0", 10); ok {
+		t.Fatal("unknown price should not be estimated")
+	}
+	if cost, ok := EstimateCostUSD("ollama", "nomic-embed-text", 1_000_000); !ok || cost != 0 {
+		t.Fatalf("local provider cost = %v, %v", cost, ok)
+	}
+	if got := BudgetTokens("openai", "text-embedding-3-small", 0.01); got != 500_000 {
+		t.Fatalf("BudgetTokens = %d, want 500000", got)
+	}
+	if got := BudgetTokens("ollama", "nomic-embed-text", 5); got != 0 {
+		t.Fatalf("free provider budget = %d, want 0", got)
+	}
+	if got := EstimateTokens(strings.Repeat("x", 9)); got != 3 {
+		t.Fatalf("EstimateTokens = %d, want 3", got)
+	}
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ResolveRef returns the full commit SHA that ref names in the repository
+// containing dir.
+func ResolveRef(ctx context.Context, dir, ref string) (string, error) {
+	if strings.TrimSpace(ref) == "" || strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid ref %q", ref)
+	}
+	sha, err := runGit(ctx, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown ref %q", ref)
+	}
+	return strings.TrimSpace(sha), nil
+}
+
+// ExportRef writes the tree of commit (as seen from dir, so a project in a
+// repository subdirectory exports only that subdirectory) into dest, which is
+// replaced. It reads the tree with git archive and never touches the working
+// tree, index, or HEAD of the repository.
+func ExportRef(ctx context.Context, dir, commit, dest string) error {
+	root, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	prefix, err := runGit(ctx, dir, "rev-parse", "--show-prefix")
+	if err != nil {
+		return fmt.Errorf("not a git repository: %w", err)
+	}
+	treeish := commit
+	if prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "/"); prefix != "" {
+		treeish += ":" + prefix
+	}
+
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("clear %s: %w", dest, err)
+	}
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", dest, err)
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "archive", "--format=tar", treeish)
+	// git archive limits itself to the working directory when run in a
+	// subdirectory, so run it from the top with an explicit tree path.
+	cmd.Dir = strings.TrimSpace(root)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git archive: %w", err)
+	}
+	extractErr := extractTar(stdout, dest)
+	// Drain so git is never blocked writing when extraction stopped early.
+	_, _ = io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git archive %s: %w: %s", treeish, err, strings.TrimSpace(stderr.String()))
+	}
+	return extractErr
+}
+
+// extractTar writes regular files and symlinks from r under dest, refusing
+// entries that would escape it.
+func extractTar(r io.Reader, dest string) error {
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read archive: %w", err)
+		}
+		name := filepath.FromSlash(header.Name)
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("archive entry %q escapes the export directory", header.Name)
+		}
+		target := filepath.Join(dest, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := writeArchiveFile(target, reader, header.FileInfo().Mode().Perm()); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeArchiveFile(path string, r io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm|0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveAndExportRef(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := t.TempDir()
+	ctx := context.Background()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(rel, content string) {
+		t.Helper()
+		path := filepath.Join(repo, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init")
+	run("config", "user.email", "test@test.com")
+	run("config", "user.name", "Test")
+	run("checkout", "-b", "main")
+	write("app/main.go", "package main // main\n")
+	write("README.md", "readme\n")
+	run("add", ".")
+	run("commit", "-m", "main")
+	run("checkout", "-b", "feature/x")
+	write("app/main.go", "package main // feature\n")
+	write("app/extra.go", "package main\n")
+	run("add", ".")
+	run("commit", "-m", "feature")
+	run("checkout", "main")
+
+	sha, err := ResolveRef(ctx, repo, "feature/x")
+	if err != nil || len(sha) != 40 {
+		t.Fatalf("ResolveRef = %q, %v; want a full SHA", sha, err)
+	}
+	if _, err := ResolveRef(ctx, repo, "missing"); err == nil {
+		t.Fatal("ResolveRef accepted an unknown ref")
+	}
+	if _, err := ResolveRef(ctx, repo, "--all"); err == nil {
+		t.Fatal("ResolveRef accepted an option as a ref")
+	}
+
+	// Exporting from a subdirectory exports only that subdirectory, and a
+	// re-export drops files left over from an earlier one.
+	dest := filepath.Join(t.TempDir(), "export")
+	if err := ExportRef(ctx, filepath.Join(repo, "app"), sha, dest); err != nil {
+		t.Fatalf("ExportRef failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "main.go")); err != nil || string(data) != "package main // feature\n" {
+		t.Fatalf("exported main.go = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "README.md")); !os.IsNotExist(err) {
+		t.Fatalf("export outside the project subdirectory: %v", err)
+	}
+	mainSHA, err := ResolveRef(ctx, repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportRef(ctx, filepath.Join(repo, "app"), mainSHA, dest); err != nil {
+		t.Fatalf("ExportRef(main) failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "extra.go")); !os.IsNotExist(err) {
+		t.Fatalf("stale file survived re-export: %v", err)
+	}
+
+	// The working tree is untouched.
+	if data, _ := os.ReadFile(filepath.Join(repo, "app", "main.go")); string(data) != "package main // main\n" {
+		t.Fatalf("working tree changed: %q", data)
+	}
+}
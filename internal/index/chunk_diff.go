@@ -0,0 +1,251 @@
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"sync"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+// Chunk change kinds reported in a ChunkDiff.
+const (
+	ChunkAdded    = "added"
+	ChunkRemoved  = "removed"
+	ChunkModified = "modified"
+)
+
+// ChunkFingerprint identifies one stored chunk of a file generation without
+// keeping its content.
+type ChunkFingerprint struct {
+	StartLine   int    `json:"start_line"`
+	EndLine     int    `json:"end_line"`
+	ChunkType   string `json:"chunk_type"`
+	SymbolName  string `json:"symbol_name,omitempty"`
+	ContentHash string `json:"content_hash"`
+}
+
+// ChunkChange is one chunk added, removed, or modified by an index run. Old is
+// nil for an added chunk and New for a removed one.
+type ChunkChange struct {
+	Kind string            `json:"kind"`
+	Old  *ChunkFingerprint `json:"old,omitempty"`
+	New  *ChunkFingerprint `json:"new,omitempty"`
+}
+
+// FileChunkDiff is how one file's chunks changed between the generation an
+// index run replaced and the one it stored. Root names the extra root the
+// file belongs to and is empty for the project itself.
+type FileChunkDiff struct {
+	Path      string        `json:"path"`
+	Root      string        `json:"root,omitempty"`
+	Changes   []ChunkChange `json:"changes"`
+	Unchanged int           `json:"unchanged"`
+}
+
+// ChunkDiff compares every file an index run touched with its previous
+// generation. Files whose chunks all survived unchanged are counted in
+// Unchanged but not listed.
+type ChunkDiff struct {
+	Files     []FileChunkDiff `json:"files"`
+	Added     int             `json:"added"`
+	Removed   int             `json:"removed"`
+	Modified  int             `json:"modified"`
+	Unchanged int             `json:"unchanged"`
+}
+
+// Merge appends other's files, labeled with root, and adds its totals.
+func (d *ChunkDiff) Merge(other *ChunkDiff, root string) {
+	if other == nil {
+		return
+	}
+	for _, file := range other.Files {
+		file.Root = root
+		d.Files = append(d.Files, file)
+	}
+	d.Added += other.Added
+	d.Removed += other.Removed
+	d.Modified += other.Modified
+	d.Unchanged += other.Unchanged
+}
+
+// chunkGenerations collects, for one index run, the previous generation of
+// every file the run touches, captured just before its chunks are dropped.
+type chunkGenerations struct {
+	mu       sync.Mutex
+	previous map[string][]ChunkFingerprint
+	touched  map[string]struct{}
+}
+
+func newChunkGenerations() *chunkGenerations {
+	return &chunkGenerations{
+		previous: make(map[string][]ChunkFingerprint),
+		touched:  make(map[string]struct{}),
+	}
+}
+
+// remember records relPath as touched and, the first time it is seen,
+// fingerprints its stored chunks as the previous generation. A lookup
+// failure only costs the diff its "before" side.
+func (g *chunkGenerations) remember(database *db.DB, projectRoot, relPath string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	_, seen := g.touched[relPath]
+	g.touched[relPath] = struct{}{}
+	g.mu.Unlock()
+	if seen {
+		return
+	}
+	// Each path reaches here once, so the read needs no lock.
+	if previous := storedFingerprints(database, projectRoot, relPath); len(previous) > 0 {
+		g.mu.Lock()
+		g.previous[relPath] = previous
+		g.mu.Unlock()
+	}
+}
+
+// rememberProject captures every indexed file of projectRoot, for a run that
+// resets the project before re-indexing it.
+func (g *chunkGenerations) rememberProject(database *db.DB, projectRoot string) {
+	if g == nil {
+		return
+	}
+	files, err := database.ListFiles(projectRoot)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		g.remember(database, projectRoot, file.RelativePath)
+	}
+}
+
+// touch records relPath as touched without capturing anything, for a file
+// that had no previous generation.
+func (g *chunkGenerations) touch(relPath string) {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.touched[relPath] = struct{}{}
+}
+
+// diff compares each touched file's previous generation with the chunks now
+// stored for it.
+func (g *chunkGenerations) diff(database *db.DB, projectRoot string) *ChunkDiff {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	paths := make([]string, 0, len(g.touched))
+	for path := range g.touched {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	result := &ChunkDiff{Files: []FileChunkDiff{}}
+	for _, path := range paths {
+		file := diffFingerprints(path, g.previous[path], storedFingerprints(database, projectRoot, path))
+		result.Unchanged += file.Unchanged
+		if len(file.Changes) == 0 {
+			continue
+		}
+		for _, change := range file.Changes {
+			switch change.Kind {
+			case ChunkAdded:
+				result.Added++
+			case ChunkRemoved:
+				result.Removed++
+			case ChunkModified:
+				result.Modified++
+			}
+		}
+		result.Files = append(result.Files, file)
+	}
+	return result
+}
+
+// storedFingerprints fingerprints relPath's stored chunks under projectRoot in
+// line order.
+func storedFingerprints(database *db.DB, projectRoot, relPath string) []ChunkFingerprint {
+	if database == nil {
+		return nil
+	}
+	chunks, err := database.GetChunksByFile(relPath)
+	if err != nil {
+		return nil
+	}
+	fingerprints := make([]ChunkFingerprint, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.ProjectRoot != projectRoot {
+			continue
+		}
+		sum := sha256.Sum256([]byte(chunk.Content))
+		fingerprints = append(fingerprints, ChunkFingerprint{
+			StartLine:   chunk.StartLine,
+			EndLine:     chunk.EndLine,
+			ChunkType:   chunk.ChunkType,
+			SymbolName:  chunk.SymbolName,
+			ContentHash: hex.EncodeToString(sum[:8]),
+		})
+	}
+	slices.SortStableFunc(fingerprints, func(a, b ChunkFingerprint) int { return a.StartLine - b.StartLine })
+	return fingerprints
+}
+
+// diffFingerprints matches the two generations of one file. Chunks with the
+// same content are unchanged even if they moved. Of the rest, an old and a
+// new chunk declaring the same symbol, or else starting on the same line, are
+// one modified chunk; anything left over was removed or added.
+func diffFingerprints(path string, before, after []ChunkFingerprint) FileChunkDiff {
+	file := FileChunkDiff{Path: path, Changes: []ChunkChange{}}
+	oldUsed := make([]bool, len(before))
+	newUsed := make([]bool, len(after))
+
+	match := func(same func(a, b ChunkFingerprint) bool, onMatch func(i, j int)) {
+		for j, b := range after {
+			if newUsed[j] {
+				continue
+			}
+			for i, a := range before {
+				if !oldUsed[i] && same(a, b) {
+					oldUsed[i], newUsed[j] = true, true
+					onMatch(i, j)
+					break
+				}
+			}
+		}
+	}
+	modified := func(i, j int) {
+		file.Changes = append(file.Changes, ChunkChange{Kind: ChunkModified, Old: &before[i], New: &after[j]})
+	}
+
+	match(func(a, b ChunkFingerprint) bool { return a.ContentHash == b.ContentHash }, func(int, int) { file.Unchanged++ })
+	match(func(a, b ChunkFingerprint) bool {
+		return a.SymbolName != "" && a.SymbolName == b.SymbolName && a.ChunkType == b.ChunkType
+	}, modified)
+	match(func(a, b ChunkFingerprint) bool { return a.StartLine == b.StartLine }, modified)
+
+	for i := range before {
+		if !oldUsed[i] {
+			file.Changes = append(file.Changes, ChunkChange{Kind: ChunkRemoved, Old: &before[i]})
+		}
+	}
+	for j := range after {
+		if !newUsed[j] {
+			file.Changes = append(file.Changes, ChunkChange{Kind: ChunkAdded, New: &after[j]})
+		}
+	}
+	slices.SortStableFunc(file.Changes, func(a, b ChunkChange) int { return changeLine(a) - changeLine(b) })
+	return file
+}
+
+// changeLine orders changes by where they sit in the new file, or the old
+// one for removed chunks.
+func changeLine(change ChunkChange) int {
+	if change.New != nil {
+		return change.New.StartLine
+	}
+	return change.Old.StartLine
+}
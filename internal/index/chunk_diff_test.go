@@ -0,0 +1,94 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffFingerprints(t *testing.T) {
+	before := []ChunkFingerprint{
+		{StartLine: 1, EndLine: 3, ChunkType: "function", SymbolName: "Keep", ContentHash: "aaa"},
+		{StartLine: 5, EndLine: 7, ChunkType: "function", SymbolName: "Edit", ContentHash: "bbb"},
+		{StartLine: 9, EndLine: 9, ChunkType: "block", ContentHash: "ccc"},
+		{StartLine: 11, EndLine: 12, ChunkType: "function", SymbolName: "Gone", ContentHash: "ddd"},
+	}
+	after := []ChunkFingerprint{
+		{StartLine: 1, EndLine: 3, ChunkType: "function", SymbolName: "Keep", ContentHash: "aaa"},
+		{StartLine: 5, EndLine: 8, ChunkType: "function", SymbolName: "Edit", ContentHash: "eee"},
+		{StartLine: 9, EndLine: 10, ChunkType: "block", ContentHash: "fff"},
+		{StartLine: 14, EndLine: 15, ChunkType: "function", SymbolName: "New", ContentHash: "ggg"},
+	}
+
+	diff := diffFingerprints("a.go", before, after)
+	if diff.Unchanged != 1 {
+		t.Errorf("Unchanged = %d, want 1", diff.Unchanged)
+	}
+	want := []struct {
+		kind string
+		line int
+	}{
+		{ChunkModified, 5}, {ChunkModified, 9}, {ChunkRemoved, 11}, {ChunkAdded, 14},
+	}
+	if len(diff.Changes) != len(want) {
+		t.Fatalf("changes = %+v, want %d", diff.Changes, len(want))
+	}
+	for i, w := range want {
+		if got := diff.Changes[i]; got.Kind != w.kind || changeLine(got) != w.line {
+			t.Errorf("change %d = %s at %d, want %s at %d", i, got.Kind, changeLine(got), w.kind, w.line)
+		}
+	}
+}
+
+func TestIndex_ReportsChunkDiff(t *testing.T) {
+	indexer, database, tmpDir := setupTestIndexer(t)
+	defer database.Close()
+	projectDir := filepath.Join(tmpDir, "testproject")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	setupTestFiles(t, projectDir)
+	ctx := context.Background()
+
+	first, err := indexer.Index(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("first index: %v", err)
+	}
+	if first.ChunkDiff == nil || first.ChunkDiff.Added != first.ChunksCreated || first.ChunkDiff.Removed != 0 {
+		t.Fatalf("first run diff = %+v, want %d added", first.ChunkDiff, first.ChunksCreated)
+	}
+
+	if err := os.WriteFile(filepath.Join(projectDir, "utils.go"), []byte("package main\n\nfunc add(a, b int) int {\n\treturn b + a\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(projectDir, "main.go")); err != nil {
+		t.Fatal(err)
+	}
+	second, err := indexer.Index(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("second index: %v", err)
+	}
+	diff := second.ChunkDiff
+	if diff == nil || diff.Modified == 0 || diff.Removed == 0 {
+		t.Fatalf("second run diff = %+v, want a modified and a removed chunk", diff)
+	}
+	files := map[string]FileChunkDiff{}
+	for _, file := range diff.Files {
+		files[file.Path] = file
+	}
+	if file, ok := files["main.go"]; !ok || file.Changes[0].Kind != ChunkRemoved {
+		t.Errorf("main.go diff = %+v, want removed chunks", file)
+	}
+	if file, ok := files["utils.go"]; !ok || file.Changes[0].Kind != ChunkModified || file.Changes[0].New.SymbolName != "add" {
+		t.Errorf("utils.go diff = %+v, want add modified", file)
+	}
+
+	full, err := indexer.ReindexAll(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("reindex: %v", err)
+	}
+	if full.ChunkDiff == nil || len(full.ChunkDiff.Files) != 0 || full.ChunkDiff.Unchanged != full.ChunksCreated {
+		t.Fatalf("unchanged full reindex diff = %+v, want %d unchanged and no files", full.ChunkDiff, full.ChunksCreated)
+	}
+}
@@ -0,0 +1,138 @@
+package index
+
+import "strings"
+
+// minDocCommentBytes is the smallest doc comment kept as its own comment
+// chunk. One-line summaries ("// New returns a server.") embed poorly on
+// their own, so they stay with the surrounding source.
+const minDocCommentBytes = 64
+
+// docComments returns a comment chunk for each documentation comment
+// attached to a semantic block: the comment lines directly above a Go,
+// JS/TS, or Rust declaration, and Python module, def, and class docstrings.
+// Each is named after the block it documents, so documentation searches can
+// filter on type:comment and still see which symbol a hit describes. Python
+// docstrings stay inside their function chunk as well.
+func (c *Chunker) docComments(content string, lang Language, blocks []Chunk) []Chunk {
+	var commentPrefixes []string
+	switch lang {
+	case LangGo, LangJavaScript, LangTypeScript, LangRust:
+		commentPrefixes = []string{"//", "/*", "*"}
+	case LangPython:
+		// Python documents with docstrings; comments above a def already
+		// trail the previous block.
+	default:
+		return nil
+	}
+
+	lines := strings.Split(content, "\n")
+	lineOffsets := make([]int, len(lines)+1)
+	byteOffset := 0
+	for i, line := range lines {
+		lineOffsets[i] = byteOffset
+		byteOffset += len(line) + 1
+	}
+	lineOffsets[len(lines)] = byteOffset
+
+	var docs []Chunk
+	// Comments end with their newline: the declaration follows directly, so
+	// no gap chunk would pick the separator up.
+	add := func(start, end int, symbol, reason string) {
+		text := content[lineOffsets[start]:min(lineOffsets[end+1], len(content))]
+		if len(strings.TrimSpace(text)) < minDocCommentBytes {
+			return
+		}
+		c.decide("doc", start+1, end+1, "%s: %s", blockLabel(ChunkTypeComment, symbol), reason)
+		docs = append(docs, Chunk{
+			Content:    text,
+			StartLine:  start + 1,
+			EndLine:    end + 1,
+			StartByte:  lineOffsets[start],
+			EndByte:    lineOffsets[end+1],
+			ChunkType:  ChunkTypeComment,
+			SymbolName: symbol,
+		})
+	}
+
+	seen := make(map[int]bool)
+	for _, block := range blocks {
+		first := block.StartLine - 1
+		if first <= 0 || first >= len(lines) || seen[first] {
+			continue
+		}
+		seen[first] = true
+		start := first
+		for start > 0 && isCommentLine(lines[start-1], commentPrefixes) {
+			start--
+		}
+		if start < first {
+			add(start, first-1, block.SymbolName, "comment above the declaration")
+		}
+	}
+
+	if lang == LangPython {
+		if start, end, ok := pythonDocstring(lines, 0); ok {
+			add(start, end, "", "module docstring")
+		}
+		for _, block := range blocks {
+			// The body starts after the line ending the signature, which
+			// may span several lines.
+			last := block.EndLine - 1
+			header := block.StartLine - 1
+			for header < last && !strings.HasSuffix(strings.TrimSpace(lines[header]), ":") {
+				header++
+			}
+			if start, end, ok := pythonDocstring(lines, header+1); ok && end <= last {
+				add(start, end, block.SymbolName, "docstring")
+			}
+		}
+	}
+	return docs
+}
+
+// isCommentLine reports whether line holds only a comment.
+func isCommentLine(line string, prefixes []string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// pythonDocstring finds a triple-quoted string that is the first statement at
+// or after line from, skipping blank lines and, at module level, comments. It
+// returns the string's first and last line.
+func pythonDocstring(lines []string, from int) (int, int, bool) {
+	i := from
+	for i < len(lines) {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed != "" && !(from == 0 && strings.HasPrefix(trimmed, "#")) {
+			break
+		}
+		i++
+	}
+	if i >= len(lines) {
+		return 0, 0, false
+	}
+	opening := strings.TrimLeft(strings.TrimSpace(lines[i]), "rRuUbB")
+	var quote string
+	switch {
+	case strings.HasPrefix(opening, `"""`):
+		quote = `"""`
+	case strings.HasPrefix(opening, "'''"):
+		quote = "'''"
+	default:
+		return 0, 0, false
+	}
+	if strings.Contains(opening[len(quote):], quote) {
+		return i, i, true
+	}
+	for end := i + 1; end < len(lines); end++ {
+		if strings.Contains(lines[end], quote) {
+			return i, end, true
+		}
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,177 @@
+package index
+
+import (
+	"regexp"
+	"strings"
+)
+
+// embeddedBlock is a region of a file written in another language: a fenced
+// code block in Markdown or a <script> or <style> section of a Vue or Svelte
+// component. Lines are 0-based and inclusive, and include the delimiters.
+type embeddedBlock struct {
+	start, end int
+	lang       Language
+}
+
+// fenceLanguageAliases names the fence info strings that are neither a
+// language name nor one of its file extensions.
+var fenceLanguageAliases = map[string]Language{
+	"golang":     LangGo,
+	"node":       LangJavaScript,
+	"c#":         LangCSharp,
+	"docker":     LangShell,
+	"dockerfile": LangShell,
+	"make":       LangShell,
+	"makefile":   LangShell,
+}
+
+// fenceLanguage maps the info string of a Markdown fence ("ts",
+// "python title=app.py", "{.rust}") to a language, or "" when it names no
+// language vecgrep recognizes. Markdown itself is not a separate language.
+func fenceLanguage(info string) Language {
+	fields := strings.Fields(strings.Trim(info, "{}"))
+	if len(fields) == 0 {
+		return ""
+	}
+	name := strings.ToLower(strings.TrimPrefix(fields[0], "."))
+	lang, ok := fenceLanguageAliases[name]
+	if !ok {
+		lang, ok = languageExtensions["."+name]
+	}
+	if !ok {
+		for _, known := range languageExtensions {
+			if string(known) == name {
+				lang, ok = known, true
+				break
+			}
+		}
+	}
+	if !ok || lang == LangUnknown || lang == LangMarkdown {
+		return ""
+	}
+	return lang
+}
+
+// componentSectionPattern matches the opening tag of a top-level <script>
+// or <style> section and captures its name and attributes.
+var componentSectionPattern = regexp.MustCompile(`^<(script|style)(\s[^>]*)?>`)
+
+// componentLangPattern captures the lang attribute of a section tag.
+var componentLangPattern = regexp.MustCompile(`\blang\s*=\s*["']?([\w-]+)`)
+
+// componentBlocks finds the <script> and <style> sections of a Vue or Svelte
+// component. A script is TypeScript when its lang attribute says so and
+// JavaScript otherwise; styles are CSS whatever their preprocessor. Sections
+// must start at the beginning of a line, which keeps tags inside the
+// template out, and a section with no body of its own is skipped.
+func componentBlocks(lines []string) []embeddedBlock {
+	var blocks []embeddedBlock
+	for i := 0; i < len(lines); i++ {
+		match := componentSectionPattern.FindStringSubmatch(lines[i])
+		if match == nil {
+			continue
+		}
+		closing := "</" + match[1] + ">"
+		if strings.Contains(lines[i], closing) {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !strings.Contains(lines[end], closing) {
+			end++
+		}
+		if end == len(lines) {
+			break
+		}
+		lang := LangCSS
+		if match[1] == "script" {
+			lang = LangJavaScript
+			if attr := componentLangPattern.FindStringSubmatch(match[2]); attr != nil && (attr[1] == "ts" || attr[1] == "typescript" || attr[1] == "tsx") {
+				lang = LangTypeScript
+			}
+		}
+		blocks = append(blocks, embeddedBlock{start: i, end: end, lang: lang})
+		i = end
+	}
+	return blocks
+}
+
+// chunkComponent chunks the script and style sections of a Vue or Svelte
+// component in their own languages. The template is left to the
+// uncovered-source pass and keeps the component's language.
+func (c *Chunker) chunkComponent(content string) []Chunk {
+	lines, lineOffsets := splitContentLines(content)
+	var chunks []Chunk
+	for _, block := range componentBlocks(lines) {
+		chunks = append(chunks, c.chunkEmbeddedBlock(lines, lineOffsets, block, "")...)
+	}
+	return chunks
+}
+
+// chunkEmbeddedBlock chunks the body of block with its language's chunker
+// and labels every chunk with that language. The first and last chunks take
+// in the delimiter lines, so the chunks cover the block exactly. A body with
+// no structure of its own becomes one block chunk named symbol.
+func (c *Chunker) chunkEmbeddedBlock(lines []string, lineOffsets []int, block embeddedBlock, symbol string) []Chunk {
+	body := strings.Join(lines[block.start+1:block.end], "\n")
+	if strings.TrimSpace(body) == "" {
+		return nil
+	}
+	c.decide("embedded", block.start+1, block.end+1, "%s block chunked as %s", blockLabel(ChunkTypeBlock, symbol), block.lang)
+
+	// Decisions inside the body would report body-relative lines.
+	trace := c.trace
+	c.trace = nil
+	chunks := c.semanticChunk(body, block.lang)
+	if len(chunks) > 0 {
+		chunks = c.withUncoveredSource(body, chunks)
+	}
+	c.trace = trace
+
+	if len(chunks) == 0 {
+		text := strings.Join(lines[block.start:block.end+1], "\n")
+		return []Chunk{{
+			Content:    text,
+			StartLine:  block.start + 1,
+			EndLine:    block.end + 1,
+			StartByte:  lineOffsets[block.start],
+			EndByte:    lineOffsets[block.start] + len(text),
+			ChunkType:  ChunkTypeBlock,
+			SymbolName: symbol,
+			Language:   block.lang,
+		}}
+	}
+
+	bodyStart := lineOffsets[block.start+1]
+	for i := range chunks {
+		chunks[i].StartLine += block.start + 1
+		chunks[i].EndLine += block.start + 1
+		chunks[i].StartByte += bodyStart
+		chunks[i].EndByte += bodyStart
+		chunks[i].Language = block.lang
+		if chunks[i].SymbolName == "" {
+			chunks[i].SymbolName = symbol
+		}
+	}
+	first, last := &chunks[0], &chunks[len(chunks)-1]
+	first.Content = lines[block.start] + "\n" + first.Content
+	first.StartLine = block.start + 1
+	first.StartByte = lineOffsets[block.start]
+	last.Content += "\n" + lines[block.end]
+	last.EndLine = block.end + 1
+	last.EndByte = lineOffsets[block.end] + len(lines[block.end])
+	return chunks
+}
+
+// splitContentLines splits content into lines and returns the byte offset
+// of each line start, plus one past the end.
+func splitContentLines(content string) ([]string, []int) {
+	lines := strings.Split(content, "\n")
+	lineOffsets := make([]int, len(lines)+1)
+	byteOffset := 0
+	for i, line := range lines {
+		lineOffsets[i] = byteOffset
+		byteOffset += len(line) + 1
+	}
+	lineOffsets[len(lines)] = byteOffset
+	return lines, lineOffsets
+}
@@ -0,0 +1,60 @@
+package index
+
+import "fmt"
+
+// BoundaryDecision records one choice the chunker made while splitting a
+// file: a recognized block, a line window, a gap kept as a generic chunk, or
+// a size split. StartLine and EndLine are 1-based and zero for decisions
+// about the whole file.
+type BoundaryDecision struct {
+	Stage     string `json:"stage"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Detail    string `json:"detail"`
+}
+
+// String renders the decision as one debug line.
+func (d BoundaryDecision) String() string {
+	if d.StartLine == 0 {
+		return fmt.Sprintf("%-10s %s", d.Stage, d.Detail)
+	}
+	return fmt.Sprintf("%-10s L%d-L%d %s", d.Stage, d.StartLine, d.EndLine, d.Detail)
+}
+
+// ExplainChunkFile chunks content like ChunkFile and also returns the
+// boundary decisions that produced the chunks, in the order they were made.
+func (c *Chunker) ExplainChunkFile(content string, filename string) ([]Chunk, []BoundaryDecision) {
+	var decisions []BoundaryDecision
+	traced := *c
+	traced.trace = &decisions
+	chunks := traced.ChunkFile(content, filename)
+	return chunks, decisions
+}
+
+// ExplainChunks chunks a file with the built-in chunker sized by cfg, the
+// way the indexer does when no external chunker is configured for it.
+func ExplainChunks(cfg IndexerConfig, content []byte, path string) ([]Chunk, []BoundaryDecision) {
+	chunker := NewChunker(ChunkerConfig{ChunkSize: cfg.ChunkSize, ChunkOverlap: cfg.ChunkOverlap, MaxChunkChars: cfg.MaxChunkChars})
+	return chunker.ExplainChunkFile(string(content), path)
+}
+
+// blockLabel names a recognized block in a decision, such as "function Run".
+func blockLabel(chunkType ChunkType, symbol string) string {
+	if symbol == "" {
+		return string(chunkType)
+	}
+	return string(chunkType) + " " + symbol
+}
+
+// decide records a boundary decision when the chunker is being explained.
+func (c *Chunker) decide(stage string, startLine, endLine int, format string, args ...any) {
+	if c.trace == nil {
+		return
+	}
+	*c.trace = append(*c.trace, BoundaryDecision{
+		Stage:     stage,
+		StartLine: startLine,
+		EndLine:   endLine,
+		Detail:    fmt.Sprintf(format, args...),
+	})
+}
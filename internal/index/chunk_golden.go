@@ -0,0 +1,79 @@
+package index
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// UpdateGoldenEnv names the environment variable that makes AssertChunks
+// rewrite golden files instead of comparing against them:
+//
+//	VECGREP_UPDATE_GOLDEN=1 go test ./internal/index -run TestChunkerGolden
+const UpdateGoldenEnv = "VECGREP_UPDATE_GOLDEN"
+
+// ChunkFixture is a source file whose chunks are pinned by a golden file
+// next to it (Path + ".golden").
+type ChunkFixture struct {
+	Path       string
+	Content    string
+	GoldenPath string
+}
+
+// LoadFixture reads the source file at path for a chunker golden test.
+func LoadFixture(t testing.TB, path string) ChunkFixture {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read chunker fixture: %v", err)
+	}
+	return ChunkFixture{Path: path, Content: string(content), GoldenPath: path + ".golden"}
+}
+
+// AssertChunks checks chunks against the fixture's golden file, one
+// ChunkBoundaries line per chunk. It also fails on chunks with inverted or
+// unordered line ranges, which no golden file should pin. With
+// VECGREP_UPDATE_GOLDEN=1 set it writes the golden file instead.
+func AssertChunks(t testing.TB, fixture ChunkFixture, chunks []Chunk) {
+	t.Helper()
+	for i, chunk := range chunks {
+		if chunk.StartLine < 1 || chunk.EndLine < chunk.StartLine {
+			t.Errorf("%s: chunk %d has invalid range L%d-L%d", fixture.Path, i, chunk.StartLine, chunk.EndLine)
+		}
+		if i > 0 && chunk.StartLine < chunks[i-1].StartLine {
+			t.Errorf("%s: chunk %d starts at L%d, before chunk %d at L%d", fixture.Path, i, chunk.StartLine, i-1, chunks[i-1].StartLine)
+		}
+	}
+
+	got := ChunkBoundaries(chunks)
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.WriteFile(fixture.GoldenPath, []byte(got), 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(fixture.GoldenPath)
+	if err != nil {
+		t.Fatalf("read golden (run with %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+	if got != string(want) {
+		t.Errorf("%s: chunks differ from %s (run with %s=1 to accept)\n--- want\n%s--- got\n%s",
+			fixture.Path, fixture.GoldenPath, UpdateGoldenEnv, want, got)
+	}
+}
+
+// ChunkBoundaries renders one line per chunk with its line range, type,
+// symbol, and size, plus the language of embedded code, the form golden
+// files and `vecgrep chunk` use.
+func ChunkBoundaries(chunks []Chunk) string {
+	var sb strings.Builder
+	for _, chunk := range chunks {
+		fmt.Fprintf(&sb, "L%d-L%d %s (%d bytes)", chunk.StartLine, chunk.EndLine, blockLabel(chunk.ChunkType, chunk.SymbolName), len(chunk.Content))
+		if chunk.Language != "" {
+			fmt.Fprintf(&sb, " [%s]", chunk.Language)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
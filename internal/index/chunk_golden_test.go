@@ -0,0 +1,49 @@
+package index
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestChunkerGolden pins the built-in chunker's boundaries for each fixture
+// in testdata/chunker. After an intended boundary change, regenerate with
+// VECGREP_UPDATE_GOLDEN=1 and review the golden diff.
+func TestChunkerGolden(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "chunker", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunker := NewChunker(DefaultChunkerConfig())
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".golden") {
+			continue
+		}
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			fixture := LoadFixture(t, path)
+			AssertChunks(t, fixture, chunker.ChunkFile(fixture.Content, path))
+		})
+	}
+}
+
+func TestExplainChunkFileRecordsDecisions(t *testing.T) {
+	fixture := LoadFixture(t, filepath.Join("testdata", "chunker", "server.go"))
+	chunker := NewChunker(DefaultChunkerConfig())
+
+	chunks, decisions := chunker.ExplainChunkFile(fixture.Content, fixture.Path)
+	if ChunkBoundaries(chunks) != ChunkBoundaries(chunker.ChunkFile(fixture.Content, fixture.Path)) {
+		t.Fatal("ExplainChunkFile chunked differently from ChunkFile")
+	}
+	stages := map[string]bool{}
+	for _, d := range decisions {
+		stages[d.Stage] = true
+	}
+	for _, stage := range []string{"language", "block", "gap"} {
+		if !stages[stage] {
+			t.Errorf("decisions = %v, missing a %s decision", decisions, stage)
+		}
+	}
+	if chunker.trace != nil {
+		t.Fatal("ExplainChunkFile left tracing enabled on the chunker")
+	}
+}
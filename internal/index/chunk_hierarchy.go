@@ -0,0 +1,158 @@
+package index
+
+import (
+	"sort"
+	"strings"
+)
+
+// ChunkParents links the chunks of one file into a tree and returns, for
+// each chunk, the index of its parent in chunks or -1 for a top-level chunk
+// (whose parent is the file itself). A chunk's parent is, in order:
+//
+//   - the first piece of an oversized block, for the later pieces it was
+//     split into (they share the symbol and overlap the previous piece);
+//   - the block a doc comment documents, for a comment chunk directly above
+//     a block of the same name;
+//   - the enclosing section, for a Markdown section ("Install > From source"
+//     belongs to "Install"), or the section's first piece, for fenced code
+//     split out of it and the prose that follows;
+//   - otherwise the smallest chunk whose lines contain it, such as the impl
+//     block around a Rust method or the function around a Python docstring.
+func ChunkParents(chunks []Chunk) []int {
+	parents := make([]int, len(chunks))
+	order := make([]int, len(chunks))
+	for i := range chunks {
+		parents[i] = -1
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ca, cb := chunks[order[a]], chunks[order[b]]
+		if ca.StartLine != cb.StartLine {
+			return ca.StartLine < cb.StartLine
+		}
+		return ca.EndLine > cb.EndLine
+	})
+
+	// head maps each chunk to the first piece of its split run.
+	head := make([]int, len(chunks))
+	for pos, i := range order {
+		head[i] = i
+		if pos == 0 {
+			continue
+		}
+		prev := order[pos-1]
+		if isSplitPiece(chunks[prev], chunks[i]) {
+			head[i] = head[prev]
+			parents[i] = head[prev]
+		}
+	}
+
+	sections := make(map[string]int)
+	for _, i := range order {
+		chunk := chunks[i]
+		if chunk.ChunkType == ChunkTypeBlock && chunk.SymbolName != "" {
+			if _, seen := sections[chunk.SymbolName]; !seen {
+				sections[chunk.SymbolName] = i
+			}
+		}
+	}
+
+	for pos, i := range order {
+		if parents[i] >= 0 {
+			continue
+		}
+		chunk := chunks[i]
+		if chunk.ChunkType == ChunkTypeComment && chunk.SymbolName != "" {
+			if documented := documentedBlock(chunks, chunk); documented >= 0 {
+				parents[i] = documented
+				continue
+			}
+		}
+		if chunk.ChunkType == ChunkTypeBlock {
+			// Fenced code split out of a section, and the prose after it,
+			// hang off the section's first piece.
+			if first, ok := sections[chunk.SymbolName]; ok && first != i && (chunk.Language != "" || (pos > 0 && chunks[order[pos-1]].Language != "")) {
+				parents[i] = first
+				continue
+			}
+			if section := enclosingSection(sections, chunk.SymbolName); section >= 0 {
+				parents[i] = section
+				continue
+			}
+		}
+		parents[i] = enclosingChunk(chunks, head, i)
+	}
+	return parents
+}
+
+// isSplitPiece reports whether next continues prev after an oversized block
+// was split: pieces keep the block's type and name and share at least one
+// line with the piece before them, which two distinct blocks never do.
+func isSplitPiece(prev, next Chunk) bool {
+	return next.SymbolName != "" &&
+		next.SymbolName == prev.SymbolName &&
+		next.ChunkType == prev.ChunkType &&
+		next.StartLine > prev.StartLine &&
+		next.StartLine <= prev.EndLine &&
+		next.EndLine > prev.EndLine
+}
+
+// documentedBlock returns the block that comment documents: the chunk of
+// the same name starting on the line after the comment, or -1.
+func documentedBlock(chunks []Chunk, comment Chunk) int {
+	for j, block := range chunks {
+		if block.ChunkType != ChunkTypeComment && block.SymbolName == comment.SymbolName && block.StartLine == comment.EndLine+1 {
+			return j
+		}
+	}
+	return -1
+}
+
+// enclosingSection returns the closest ancestor section of a Markdown
+// heading path that has a chunk of its own, or -1. A heading with no text
+// joins its first subsection, so "A > B > C" may belong to "A" directly.
+func enclosingSection(sections map[string]int, path string) int {
+	for {
+		cut := strings.LastIndex(path, markdownHeadingSeparator)
+		if cut < 0 {
+			return -1
+		}
+		path = path[:cut]
+		if section, ok := sections[path]; ok {
+			return section
+		}
+	}
+}
+
+// enclosingChunk returns the smallest chunk other than chunks[i] whose lines
+// contain it, or -1. Pieces of one split block never enclose each other.
+func enclosingChunk(chunks []Chunk, head []int, i int) int {
+	chunk := chunks[i]
+	// Separator lines merged into the chunk may run past a block it sits in
+	// (the blank line after the last method of an impl).
+	endLine := chunk.EndLine - trailingBlankLines(chunk.Content)
+	best, bestSpan := -1, 0
+	for j, outer := range chunks {
+		if j == i || head[j] == head[i] || outer.StartLine > chunk.StartLine || outer.EndLine < endLine {
+			continue
+		}
+		if outer.StartLine == chunk.StartLine && outer.EndLine == chunk.EndLine && j > i {
+			// Identical spans nest in chunk order.
+			continue
+		}
+		if span := outer.EndLine - outer.StartLine; best < 0 || span < bestSpan {
+			best, bestSpan = j, span
+		}
+	}
+	return best
+}
+
+// trailingBlankLines counts the whitespace-only lines ending content.
+func trailingBlankLines(content string) int {
+	lines := strings.Split(content, "\n")
+	n := 0
+	for n < len(lines)-1 && strings.TrimSpace(lines[len(lines)-1-n]) == "" {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,174 @@
+package index
+
+import "strings"
+
+// markdownHeadingSeparator joins the headings of a Markdown section's path
+// in its SymbolName, e.g. "Install > From source".
+const markdownHeadingSeparator = " > "
+
+// markdownSection is a heading and the lines up to the next heading.
+type markdownSection struct {
+	start, end int // 0-based, inclusive
+	path       string
+	hasBody    bool
+}
+
+// chunkMarkdown splits a Markdown document into one block per ATX heading
+// section, named by the section's heading path. Headings inside fenced code
+// blocks are ignored, and a heading with no text of its own before the next
+// heading (a title directly followed by a subheading) joins the next section
+// so no chunk is a bare heading. Text before the first heading is left to
+// the uncovered-source pass. Fenced code whose info string names a known
+// language is split out of its section and chunked in that language.
+func (c *Chunker) chunkMarkdown(content string) []Chunk {
+	lines, lineOffsets := splitContentLines(content)
+
+	var sections []markdownSection
+	var blocks []embeddedBlock
+	var headings []string // headings[level-1] is the current heading at that level
+	fence := ""
+	var fenced embeddedBlock
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+				if fenced.lang != "" && i > fenced.start+1 {
+					fenced.end = i
+					blocks = append(blocks, fenced)
+				}
+			}
+		} else if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			fenced = embeddedBlock{start: i, lang: fenceLanguage(strings.TrimLeft(trimmed, "`~"))}
+		} else if level, text := markdownHeading(line); level > 0 {
+			if len(headings) >= level {
+				headings = headings[:level-1]
+			}
+			for len(headings) < level-1 {
+				headings = append(headings, "")
+			}
+			headings = append(headings, text)
+			sections = append(sections, markdownSection{start: i, path: markdownHeadingPath(headings)})
+			continue
+		}
+		if len(sections) > 0 {
+			current := &sections[len(sections)-1]
+			current.end = i
+			if trimmed != "" {
+				current.hasBody = true
+			}
+		}
+	}
+
+	var chunks []Chunk
+	// Code before the first heading has no section to be split out of.
+	for _, block := range blocks {
+		if len(sections) == 0 || block.end < sections[0].start {
+			chunks = append(chunks, c.chunkEmbeddedBlock(lines, lineOffsets, block, "")...)
+		}
+	}
+	start := -1
+	for i, section := range sections {
+		if section.end < section.start {
+			section.end = section.start
+		}
+		if start < 0 {
+			start = section.start
+		}
+		if !section.hasBody && i+1 < len(sections) {
+			continue
+		}
+		// Keep the blank lines between sections in the gap pass, like the
+		// separators between code blocks.
+		end := section.end
+		for end > start && strings.TrimSpace(lines[end]) == "" {
+			end--
+		}
+		c.decide("section", start+1, end+1, "%s: heading section", blockLabel(ChunkTypeBlock, section.path))
+		chunks = append(chunks, c.markdownSectionChunks(lines, lineOffsets, start, end, section.path, blocks)...)
+		start = -1
+	}
+
+	// A chunk followed directly by the next one carries the newline between
+	// them, since no gap chunk would pick up the separator.
+	for i := 0; i+1 < len(chunks); i++ {
+		if chunks[i+1].StartLine == chunks[i].EndLine+1 && !strings.HasSuffix(chunks[i].Content, "\n") {
+			chunks[i].Content += "\n"
+		}
+	}
+	return chunks
+}
+
+// markdownSectionChunks chunks lines[start:end+1] of a section as one block,
+// or, when the section holds fenced code in a known language, as prose
+// blocks around chunks of that code in its own language. Every piece keeps
+// the section's heading path as its name.
+func (c *Chunker) markdownSectionChunks(lines []string, lineOffsets []int, start, end int, path string, blocks []embeddedBlock) []Chunk {
+	var chunks []Chunk
+	prose := func(from, to int) {
+		for from <= to && strings.TrimSpace(lines[from]) == "" {
+			from++
+		}
+		for to >= from && strings.TrimSpace(lines[to]) == "" {
+			to--
+		}
+		if from > to {
+			return
+		}
+		chunks = append(chunks, Chunk{
+			Content:    strings.Join(lines[from:to+1], "\n"),
+			StartLine:  from + 1,
+			EndLine:    to + 1,
+			StartByte:  lineOffsets[from],
+			EndByte:    lineOffsets[to+1],
+			ChunkType:  ChunkTypeBlock,
+			SymbolName: path,
+		})
+	}
+	next := start
+	for _, block := range blocks {
+		if block.start < start || block.end > end {
+			continue
+		}
+		prose(next, block.start-1)
+		chunks = append(chunks, c.chunkEmbeddedBlock(lines, lineOffsets, block, path)...)
+		next = block.end + 1
+	}
+	prose(next, end)
+	return chunks
+}
+
+// markdownHeading parses an ATX heading ("## Install ##") and returns its
+// level and text, or level 0 when line is not a heading.
+func markdownHeading(line string) (int, string) {
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if indent > 3 {
+		return 0, ""
+	}
+	rest := line[indent:]
+	level := 0
+	for level < len(rest) && rest[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(rest) && rest[level] != ' ' && rest[level] != '\t') {
+		return 0, ""
+	}
+	text := strings.TrimSpace(rest[level:])
+	// A closing sequence of #s is decoration, not text.
+	if trimmed := strings.TrimRight(text, "#"); trimmed == "" || strings.HasSuffix(trimmed, " ") {
+		text = strings.TrimSpace(trimmed)
+	}
+	return level, text
+}
+
+// markdownHeadingPath joins the non-empty headings of a section's path.
+func markdownHeadingPath(headings []string) string {
+	var parts []string
+	for _, heading := range headings {
+		if heading != "" {
+			parts = append(parts, heading)
+		}
+	}
+	return strings.Join(parts, markdownHeadingSeparator)
+}
@@ -13,11 +13,14 @@ import (
 type ChunkType string
 
 const (
-	ChunkTypeFunction ChunkType = "function"
-	ChunkTypeClass    ChunkType = "class"
-	ChunkTypeBlock    ChunkType = "block"
-	ChunkTypeComment  ChunkType = "comment"
-	ChunkTypeGeneric  ChunkType = "generic"
+	ChunkTypeFunction  ChunkType = "function"
+	ChunkTypeClass     ChunkType = "class"
+	ChunkTypeInterface ChunkType = "interface" // interfaces, traits, protocols
+	ChunkTypeConst     ChunkType = "const"     // constant blocks and enums
+	ChunkTypeConfig    ChunkType = "config"    // JSON, YAML, and TOML documents
+	ChunkTypeBlock     ChunkType = "block"
+	ChunkTypeComment   ChunkType = "comment"
+	ChunkTypeGeneric   ChunkType = "generic"
 )
 
 // ChunkOrigin identifies which ingestion path produced a chunk. It is kept in
@@ -47,6 +50,15 @@ type Chunk struct {
 	ChunkType        ChunkType
 	SymbolName       string
 	Origin           ChunkOrigin
+	// Truncated marks a chunk whose embedded text was cut to fit the
+	// model's input limit; Content still holds all of it.
+	Truncated bool
+	// Secrets names the secret-scanner rules the chunk matched; see
+	// scanSecrets.
+	Secrets []string
+	// Language is the language of code embedded in another file (a Markdown
+	// fence, a component's <script>); empty means the file's own language.
+	Language Language
 }
 
 // defaultMaxChunkChars is a hard upper bound on the bytes in any single chunk
@@ -83,6 +95,8 @@ func DefaultChunkerConfig() ChunkerConfig {
 // Chunker splits files into semantic chunks for embedding.
 type Chunker struct {
 	config ChunkerConfig
+	// trace, when set, collects the boundary decisions of ExplainChunkFile.
+	trace *[]BoundaryDecision
 }
 
 // NewChunker creates a new Chunker with the given configuration.
@@ -106,12 +120,20 @@ func (c *Chunker) ChunkFile(content string, filename string) []Chunk {
 	}
 
 	lang := DetectLanguage(filename)
+	c.decide("language", 0, 0, "detected %s", lang)
 
 	// For certain languages, try semantic chunking first; otherwise fall back
 	// to line-based chunking.
 	chunks := c.semanticChunk(content, lang)
 	if len(chunks) == 0 {
+		c.decide("fallback", 0, 0, "no semantic blocks for %s; using line windows", lang)
 		chunks = c.lineBasedChunk(content)
+		if isConfigLanguage(lang) {
+			c.decide("config", 0, 0, "%s is a config language; windows typed config", lang)
+			for i := range chunks {
+				chunks[i].ChunkType = ChunkTypeConfig
+			}
+		}
 	} else {
 		chunks = c.withUncoveredSource(content, chunks)
 	}
@@ -184,7 +206,9 @@ func (c *Chunker) withUncoveredSource(content string, semantic []Chunk) []Chunk
 			if maxBytes <= 0 {
 				maxBytes = defaultMaxChunkChars
 			}
-			chunks = append(chunks, splitByChars(gap, maxBytes)...)
+			parts := splitByChars(gap, maxBytes)
+			c.decide("gap", gap.StartLine, gap.EndLine, "uncovered source kept as %d generic chunk(s)", len(parts))
+			chunks = append(chunks, parts...)
 		}
 		start = end + 1
 	}
@@ -195,7 +219,11 @@ func (c *Chunker) withUncoveredSource(content string, semantic []Chunk) []Chunk
 		}
 		return chunks[i].EndLine < chunks[j].EndLine
 	})
-	return coalesceWhitespaceOnlyChunks(chunks)
+	coalesced := coalesceWhitespaceOnlyChunks(chunks)
+	if merged := len(chunks) - len(coalesced); merged > 0 {
+		c.decide("whitespace", 0, 0, "merged %d whitespace-only chunk(s) into neighbours", merged)
+	}
+	return coalesced
 }
 
 // coalesceWhitespaceOnlyChunks keeps the source partition lossless without
@@ -258,7 +286,9 @@ func (c *Chunker) enforceMaxChunkChars(chunks []Chunk) []Chunk {
 			out = append(out, chunk)
 			continue
 		}
-		out = append(out, splitByChars(chunk, maxChars)...)
+		parts := splitByChars(chunk, maxChars)
+		c.decide("max-bytes", chunk.StartLine, chunk.EndLine, "%d bytes exceed the %d-byte cap; split into %d", len(chunk.Content), maxChars, len(parts))
+		out = append(out, parts...)
 	}
 	return out
 }
@@ -319,6 +349,10 @@ func (c *Chunker) semanticChunk(content string, lang Language) []Chunk {
 		chunks = c.chunkJavaScript(content)
 	case LangRust:
 		chunks = c.chunkRust(content)
+	case LangMarkdown:
+		chunks = c.chunkMarkdown(content)
+	case LangVue, LangSvelte:
+		chunks = c.chunkComponent(content)
 	default:
 		return nil
 	}
@@ -327,6 +361,7 @@ func (c *Chunker) semanticChunk(content string, lang Language) []Chunk {
 	if len(chunks) == 0 {
 		return nil
 	}
+	chunks = append(chunks, c.docComments(content, lang, chunks)...)
 
 	// Split any oversized chunks
 	var result []Chunk
@@ -334,6 +369,7 @@ func (c *Chunker) semanticChunk(content string, lang Language) []Chunk {
 		if len(chunk.Content) > c.config.ChunkSize*2 {
 			// This chunk is too big, split it
 			subChunks := c.splitOversizedChunk(chunk)
+			c.decide("oversized", chunk.StartLine, chunk.EndLine, "%d bytes exceed twice the chunk size; split into %d", len(chunk.Content), len(subChunks))
 			result = append(result, subChunks...)
 		} else {
 			result = append(result, chunk)
@@ -343,11 +379,12 @@ func (c *Chunker) semanticChunk(content string, lang Language) []Chunk {
 	return result
 }
 
-// chunkGo extracts functions and types from Go code.
+// chunkGo extracts functions, types, and const blocks from Go code.
 func (c *Chunker) chunkGo(content string) []Chunk {
 	return c.extractByPatterns(content, []blockPattern{
 		{start: "func ", end: "\n}", chunkType: ChunkTypeFunction},
-		{start: "type ", end: "\n}", chunkType: ChunkTypeClass},
+		{start: "type ", end: "\n}", chunkType: ChunkTypeClass, classify: classifyGoType},
+		{start: "const (", end: "\n)", chunkType: ChunkTypeConst},
 	})
 }
 
@@ -361,18 +398,21 @@ func (c *Chunker) chunkJavaScript(content string) []Chunk {
 	return c.extractByPatterns(content, []blockPattern{
 		{start: "function ", end: "\n}", chunkType: ChunkTypeFunction},
 		{start: "class ", end: "\n}", chunkType: ChunkTypeClass},
-		{start: "const ", end: "\n}", chunkType: ChunkTypeBlock},
-		{start: "export ", end: "\n}", chunkType: ChunkTypeBlock},
+		{start: "interface ", end: "\n}", chunkType: ChunkTypeInterface},
+		{start: "enum ", end: "\n}", chunkType: ChunkTypeConst},
+		{start: "const ", end: "\n}", chunkType: ChunkTypeBlock, classify: classifyJSDeclaration},
+		{start: "export ", end: "\n}", chunkType: ChunkTypeBlock, classify: classifyJSDeclaration},
 	})
 }
 
-// chunkRust extracts functions and structs from Rust code.
+// chunkRust extracts functions, structs, traits, and enums from Rust code.
 func (c *Chunker) chunkRust(content string) []Chunk {
 	return c.extractByPatterns(content, []blockPattern{
 		{start: "fn ", end: "\n}", chunkType: ChunkTypeFunction},
 		{start: "struct ", end: "\n}", chunkType: ChunkTypeClass},
 		{start: "impl ", end: "\n}", chunkType: ChunkTypeClass},
-		{start: "enum ", end: "\n}", chunkType: ChunkTypeClass},
+		{start: "trait ", end: "\n}", chunkType: ChunkTypeInterface},
+		{start: "enum ", end: "\n}", chunkType: ChunkTypeConst},
 	})
 }
 
@@ -381,6 +421,45 @@ type blockPattern struct {
 	start     string
 	end       string
 	chunkType ChunkType
+	// classify optionally refines chunkType from the block's first line; an
+	// empty result keeps chunkType.
+	classify func(line string) ChunkType
+}
+
+// classifyGoType separates interface declarations from other Go types.
+func classifyGoType(line string) ChunkType {
+	if strings.Contains(line, " interface {") || strings.HasSuffix(line, " interface{}") {
+		return ChunkTypeInterface
+	}
+	return ""
+}
+
+// classifyJSDeclaration recognizes interfaces and enums behind export and
+// const keywords ("export interface", "const enum", "export default class").
+func classifyJSDeclaration(line string) ChunkType {
+	for _, field := range strings.Fields(line) {
+		switch field {
+		case "interface":
+			return ChunkTypeInterface
+		case "enum":
+			return ChunkTypeConst
+		case "class":
+			return ChunkTypeClass
+		case "function", "=", "{":
+			return ""
+		}
+	}
+	return ""
+}
+
+// isConfigLanguage reports whether files in lang are configuration documents
+// rather than code.
+func isConfigLanguage(lang Language) bool {
+	switch lang {
+	case LangJSON, LangYAML, LangTOML:
+		return true
+	}
+	return false
 }
 
 // extractByPatterns extracts code blocks matching the given patterns.
@@ -405,9 +484,19 @@ func (c *Chunker) extractByPatterns(content string, patterns []blockPattern) []C
 
 				// Find the symbol name
 				symbolName := extractSymbolName(trimmed, pattern.start)
+				chunkType := pattern.chunkType
+				if pattern.classify != nil {
+					if refined := pattern.classify(trimmed); refined != "" {
+						chunkType = refined
+					}
+				}
+				if chunkType != pattern.chunkType && (pattern.start == "export " || pattern.start == "const ") {
+					symbolName = declaredSymbolName(trimmed)
+				}
 
 				// Find the end of this block
 				endLine := c.findBlockEnd(lines, i, pattern.end)
+				c.decide("block", i+1, endLine+1, "%s: starts with %q, ends at %q", blockLabel(chunkType, symbolName), pattern.start, strings.TrimSpace(lines[min(endLine, len(lines)-1)]))
 
 				// Build the chunk content
 				var contentBuilder strings.Builder
@@ -424,7 +513,7 @@ func (c *Chunker) extractByPatterns(content string, patterns []blockPattern) []C
 					EndLine:    endLine + 1,
 					StartByte:  lineOffsets[i],
 					EndByte:    lineOffsets[min(endLine+1, len(lines))],
-					ChunkType:  pattern.chunkType,
+					ChunkType:  chunkType,
 					SymbolName: symbolName,
 				})
 			}
@@ -434,6 +523,21 @@ func (c *Chunker) extractByPatterns(content string, patterns []blockPattern) []C
 	return chunks
 }
 
+// declaredSymbolName returns the identifier following the declaration keyword
+// of a JS/TS line such as "export default abstract class Name".
+func declaredSymbolName(line string) string {
+	fields := strings.Fields(line)
+	for i, field := range fields {
+		switch field {
+		case "class", "interface", "enum":
+			if i+1 < len(fields) {
+				return extractSymbolName(fields[i+1], "")
+			}
+		}
+	}
+	return ""
+}
+
 // extractPythonBlocks extracts Python functions and classes using indentation.
 func (c *Chunker) extractPythonBlocks(content string) []Chunk {
 	var chunks []Chunk
@@ -459,7 +563,7 @@ func (c *Chunker) extractPythonBlocks(content string) []Chunk {
 			chunkType = ChunkTypeFunction
 			symbolName = extractPythonSymbol(trimmed, "def ")
 		} else if strings.HasPrefix(trimmed, "class ") {
-			chunkType = ChunkTypeClass
+			chunkType = classifyPythonClass(trimmed)
 			symbolName = extractPythonSymbol(trimmed, "class ")
 		} else if strings.HasPrefix(trimmed, "async def ") {
 			chunkType = ChunkTypeFunction
@@ -486,6 +590,7 @@ func (c *Chunker) extractPythonBlocks(content string) []Chunk {
 			}
 			endLine = j
 		}
+		c.decide("block", i+1, endLine+1, "%s: body indented past column %d", blockLabel(chunkType, symbolName), baseIndent)
 
 		// Build content
 		var contentBuilder strings.Builder
@@ -512,8 +617,37 @@ func (c *Chunker) extractPythonBlocks(content string) []Chunk {
 	return chunks
 }
 
-// findBlockEnd finds the closing brace for a block starting at line i.
+// classifyPythonClass maps Protocol and ABC subclasses to interfaces and Enum
+// subclasses to constants; other classes stay classes.
+func classifyPythonClass(line string) ChunkType {
+	open := strings.IndexByte(line, '(')
+	closing := strings.LastIndexByte(line, ')')
+	if open < 0 || closing < open {
+		return ChunkTypeClass
+	}
+	for _, base := range strings.Split(line[open+1:closing], ",") {
+		base = strings.TrimPrefix(strings.TrimSpace(base), "metaclass=")
+		if dot := strings.LastIndexByte(base, '.'); dot >= 0 {
+			base = base[dot+1:]
+		}
+		switch base {
+		case "Protocol", "ABC", "ABCMeta":
+			return ChunkTypeInterface
+		case "Enum", "IntEnum", "StrEnum", "Flag", "IntFlag":
+			return ChunkTypeConst
+		}
+	}
+	return ChunkTypeClass
+}
+
+// findBlockEnd finds the line closing a block starting at line i. Blocks whose
+// endPattern closes with ")" (Go const groups) balance parentheses; all
+// others balance braces.
 func (c *Chunker) findBlockEnd(lines []string, startLine int, endPattern string) int {
+	open, closing := byte('{'), byte('}')
+	if strings.HasSuffix(endPattern, ")") {
+		open, closing = '(', ')'
+	}
 	braceCount := 0
 	inString := false
 	stringChar := byte(0)
@@ -537,9 +671,9 @@ func (c *Chunker) findBlockEnd(lines []string, startLine int, endPattern string)
 			}
 
 			// Count braces
-			if ch == '{' {
+			if ch == open {
 				braceCount++
-			} else if ch == '}' {
+			} else if ch == closing {
 				braceCount--
 				if braceCount == 0 {
 					return i
@@ -574,6 +708,7 @@ func (c *Chunker) splitOversizedChunk(chunk Chunk) []Chunk {
 				EndLine:    chunk.StartLine + currentStart + currentLines - 1,
 				ChunkType:  chunk.ChunkType,
 				SymbolName: chunk.SymbolName,
+				Language:   chunk.Language,
 			})
 
 			// Start new chunk with overlap
@@ -608,6 +743,7 @@ func (c *Chunker) splitOversizedChunk(chunk Chunk) []Chunk {
 			EndLine:    chunk.EndLine,
 			ChunkType:  chunk.ChunkType,
 			SymbolName: chunk.SymbolName,
+			Language:   chunk.Language,
 		})
 	}
 
@@ -660,6 +796,14 @@ func (c *Chunker) lineBasedChunk(content string) []Chunk {
 				break
 			}
 		}
+		switch {
+		case end == len(lines):
+			c.decide("window", start+1, end, "window reaches the end of the file")
+		case end < start+linesPerChunk:
+			c.decide("window", start+1, end, "broke at blank line before the %d-line window limit", linesPerChunk)
+		default:
+			c.decide("window", start+1, end, "%d-line window", linesPerChunk)
+		}
 
 		var contentBuilder strings.Builder
 		for i := start; i < end; i++ {
@@ -202,6 +202,122 @@ func TestChunkFile_SemanticHintsPreserveUncoveredSource(t *testing.T) {
 	}
 }
 
+func TestChunkFile_DocCommentChunks(t *testing.T) {
+	c := NewChunker(DefaultChunkerConfig())
+	content := "package demo\n\n// Run starts the worker pool and blocks until every queued job has\n// finished or the context is cancelled.\nfunc Run() {\n}\n\n// Stop stops.\nfunc Stop() {\n}\n"
+	chunks := c.ChunkFile(content, "main.go")
+
+	var comments []Chunk
+	var reconstructed strings.Builder
+	for _, chunk := range chunks {
+		if chunk.ChunkType == ChunkTypeComment {
+			comments = append(comments, chunk)
+		}
+		reconstructed.WriteString(chunk.Content)
+	}
+	if len(comments) != 1 || comments[0].SymbolName != "Run" || comments[0].StartLine != 3 || comments[0].EndLine != 4 {
+		t.Fatalf("comment chunks = %+v, want Run's doc comment only (Stop's is too short)", comments)
+	}
+	if got := reconstructed.String(); got != content {
+		t.Fatalf("doc comment chunks broke the source partition\n--- got ---\n%s\n--- want ---\n%s", got, content)
+	}
+}
+
+func TestChunkFile_MarkdownSections(t *testing.T) {
+	c := NewChunker(DefaultChunkerConfig())
+	content := "# Guide\n\n## Install\n\nRun make.\n\n```sh\n# not a heading\n```\n## Usage\n\nRun the binary.\n"
+	chunks := c.ChunkFile(content, "README.md")
+	var symbols []string
+	var fenced []Chunk
+	var reconstructed strings.Builder
+	for _, chunk := range chunks {
+		if chunk.Language != "" {
+			fenced = append(fenced, chunk)
+		} else if chunk.ChunkType == ChunkTypeBlock {
+			symbols = append(symbols, chunk.SymbolName)
+		}
+		reconstructed.WriteString(chunk.Content)
+	}
+	if strings.Join(symbols, "|") != "Guide > Install|Guide > Usage" {
+		t.Fatalf("section symbols = %q, want the heading paths of Install and Usage", symbols)
+	}
+	if len(fenced) != 1 || fenced[0].Language != LangShell || fenced[0].SymbolName != "Guide > Install" || fenced[0].StartLine != 7 || fenced[0].EndLine != 9 {
+		t.Fatalf("fenced chunks = %+v, want the sh block as shell in the Install section", fenced)
+	}
+	if got := reconstructed.String(); got != content {
+		t.Fatalf("sections lost source\n--- got ---\n%s\n--- want ---\n%s", got, content)
+	}
+}
+
+func TestFenceLanguage(t *testing.T) {
+	tests := map[string]Language{
+		"ts":                      LangTypeScript,
+		"typescript":              LangTypeScript,
+		"Python title=\"app.py\"": LangPython,
+		"{.rust}":                 LangRust,
+		"golang":                  LangGo,
+		"bash":                    LangShell,
+		"markdown":                "",
+		"text":                    "",
+		"":                        "",
+	}
+	for info, want := range tests {
+		if got := fenceLanguage(info); got != want {
+			t.Errorf("fenceLanguage(%q) = %q, want %q", info, got, want)
+		}
+	}
+}
+
+func TestChunkFile_ComponentSections(t *testing.T) {
+	c := NewChunker(DefaultChunkerConfig())
+	content := "<template>\n  <script>not a section</script>\n</template>\n\n<script lang=\"ts\">\nexport interface Props {\n  title: string;\n}\n</script>\n\n<style lang=\"scss\">\n.title { color: red; }\n</style>\n"
+	chunks := c.ChunkFile(content, "Title.vue")
+	languages := map[Language]int{}
+	var reconstructed strings.Builder
+	for _, chunk := range chunks {
+		languages[chunk.Language]++
+		reconstructed.WriteString(chunk.Content)
+	}
+	if languages[LangTypeScript] == 0 || languages[LangCSS] != 1 || languages[""] == 0 {
+		t.Fatalf("chunk languages = %v, want the template, TypeScript, and CSS", languages)
+	}
+	if got := reconstructed.String(); got != content {
+		t.Fatalf("component sections lost source\n--- got ---\n%s\n--- want ---\n%s", got, content)
+	}
+}
+
+func TestChunkParents(t *testing.T) {
+	chunks := []Chunk{
+		{StartLine: 1, EndLine: 40, ChunkType: ChunkTypeClass, SymbolName: "Server"},
+		{StartLine: 3, EndLine: 5, ChunkType: ChunkTypeComment, SymbolName: "handle"},
+		{StartLine: 6, EndLine: 20, ChunkType: ChunkTypeFunction, SymbolName: "handle"},
+		{StartLine: 18, EndLine: 30, ChunkType: ChunkTypeFunction, SymbolName: "handle"},
+		{StartLine: 29, EndLine: 35, ChunkType: ChunkTypeFunction, SymbolName: "handle"},
+		{StartLine: 41, EndLine: 45, ChunkType: ChunkTypeGeneric},
+		{StartLine: 50, EndLine: 52, ChunkType: ChunkTypeBlock, SymbolName: "Guide"},
+		{StartLine: 53, EndLine: 60, ChunkType: ChunkTypeBlock, SymbolName: "Guide > Install > From source"},
+		{StartLine: 61, EndLine: 64, ChunkType: ChunkTypeBlock, SymbolName: "Guide > Install > From source", Language: LangShell},
+		{StartLine: 66, EndLine: 70, ChunkType: ChunkTypeBlock, SymbolName: "Guide > Install > From source"},
+	}
+	got := ChunkParents(chunks)
+	want := []int{-1, 2, 0, 2, 2, -1, -1, 6, 7, 7}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ChunkParents = %v, want %v", got, want)
+		}
+	}
+
+	c := NewChunker(DefaultChunkerConfig())
+	rust := "impl Server {\n    fn start(&self) {\n        run();\n    }\n}\n"
+	parsed := c.ChunkFile(rust, "server.rs")
+	parents := ChunkParents(parsed)
+	for i, chunk := range parsed {
+		if chunk.SymbolName == "start" && (parents[i] < 0 || parsed[parents[i]].ChunkType != ChunkTypeClass) {
+			t.Fatalf("fn start parent = %d in %+v, want the impl block", parents[i], parsed)
+		}
+	}
+}
+
 func TestChunkFile_GoType(t *testing.T) {
 	c := NewChunker(DefaultChunkerConfig())
 	content := `package main
@@ -345,6 +461,87 @@ impl User {
 	}
 }
 
+func TestChunkFile_InterfacesAndConstants(t *testing.T) {
+	c := NewChunker(DefaultChunkerConfig())
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     map[string]ChunkType
+	}{
+		{
+			name:     "go",
+			filename: "payment.go",
+			content:  "package payment\n\nconst (\n\tUSD = \"usd\"\n)\n\ntype Provider interface {\n\tCharge() error\n}\n\ntype Card struct {\n\tNumber string\n}\n",
+			want:     map[string]ChunkType{"Provider": ChunkTypeInterface, "Card": ChunkTypeClass},
+		},
+		{
+			name:     "typescript",
+			filename: "payment.ts",
+			content:  "export interface PaymentProvider {\n  charge(): void\n}\n\nexport enum Currency {\n  USD,\n}\n\nexport default class Stripe {\n}\n",
+			want:     map[string]ChunkType{"PaymentProvider": ChunkTypeInterface, "Currency": ChunkTypeConst, "Stripe": ChunkTypeClass},
+		},
+		{
+			name:     "rust",
+			filename: "payment.rs",
+			content:  "trait Provider {\n}\n\nenum Currency {\n    Usd,\n}\n",
+			want:     map[string]ChunkType{"Provider": ChunkTypeInterface, "Currency": ChunkTypeConst},
+		},
+		{
+			name:     "python",
+			filename: "payment.py",
+			content:  "class Provider(Protocol):\n    def charge(self): ...\n\nclass Currency(enum.Enum):\n    USD = 1\n\nclass Card:\n    pass\n",
+			want:     map[string]ChunkType{"Provider": ChunkTypeInterface, "Currency": ChunkTypeConst, "Card": ChunkTypeClass},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]ChunkType)
+			for _, chunk := range c.ChunkFile(tt.content, tt.filename) {
+				if chunk.SymbolName != "" {
+					got[chunk.SymbolName] = chunk.ChunkType
+				}
+			}
+			for symbol, want := range tt.want {
+				if got[symbol] != want {
+					t.Errorf("%s chunk type = %q, want %q (all: %v)", symbol, got[symbol], want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestChunkFile_GoConstBlock(t *testing.T) {
+	c := NewChunker(DefaultChunkerConfig())
+	content := "package payment\n\nconst (\n\tUSD = \"usd\"\n\tEUR = \"eur\"\n)\n\nfunc Charge() {}\n"
+	for _, chunk := range c.semanticChunk(content, LangGo) {
+		if chunk.ChunkType != ChunkTypeConst {
+			continue
+		}
+		if chunk.StartLine != 3 || chunk.EndLine != 6 {
+			t.Errorf("const block lines = %d-%d, want 3-6", chunk.StartLine, chunk.EndLine)
+		}
+		return
+	}
+	t.Fatal("Expected a const chunk")
+}
+
+func TestChunkFile_ConfigFiles(t *testing.T) {
+	c := NewChunker(DefaultChunkerConfig())
+	for _, filename := range []string{"config.yaml", "package.json", "Cargo.toml"} {
+		chunks := c.ChunkFile("name: vecgrep\nversion: 1\n", filename)
+		if len(chunks) == 0 {
+			t.Fatalf("%s: expected at least one chunk", filename)
+		}
+		for _, chunk := range chunks {
+			if chunk.ChunkType != ChunkTypeConfig {
+				t.Errorf("%s chunk type = %q, want %q", filename, chunk.ChunkType, ChunkTypeConfig)
+			}
+		}
+	}
+}
+
 func TestChunkFile_LineBasedFallback(t *testing.T) {
 	c := NewChunker(DefaultChunkerConfig())
 	// Use a file type that doesn't have semantic chunking
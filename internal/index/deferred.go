@@ -0,0 +1,103 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+)
+
+// PendingFile is a changed file that was chunked with its embeddings
+// deferred. Records hold the chunks exactly as they will be stored and Texts
+// the matching embedding inputs, so the file can be embedded later without
+// re-reading or re-chunking it.
+type PendingFile struct {
+	Path         string           `json:"path"`
+	RelativePath string           `json:"relative_path"`
+	ProjectRoot  string           `json:"project_root"`
+	Size         int64            `json:"size"`
+	Records      []db.ChunkRecord `json:"records"`
+	Texts        []string         `json:"texts"`
+}
+
+// SourceHash returns the SHA-256 of the file content the chunks were read
+// from.
+func (f PendingFile) SourceHash() string {
+	if len(f.Records) == 0 {
+		return ""
+	}
+	return f.Records[0].SourceHash
+}
+
+// DeferredEmbeddingSink receives files chunked while embeddings are deferred.
+// It is called from chunk workers, so it must be safe for concurrent use.
+type DeferredEmbeddingSink func(PendingFile) error
+
+// SetDeferredEmbeddingSink makes the indexer hand each changed file's chunks
+// to sink instead of embedding them. Deferred files keep their previous
+// chunks and stored hash until EmbedPendingFile stores the new ones, so they
+// still read as modified and search keeps serving the old content meanwhile.
+func (idx *Indexer) SetDeferredEmbeddingSink(sink DeferredEmbeddingSink) {
+	idx.deferred = sink
+}
+
+// deferFile hands task's chunks to the deferred-embedding sink and reports
+// the file without touching the database.
+func (idx *Indexer) deferFile(task *fileTask, texts []string, results chan<- fileResult) {
+	res := fileResult{path: task.path, size: task.size, warning: task.warning, chunksWithSecrets: task.secrets}
+	err := idx.deferred(PendingFile{
+		Path:         task.path,
+		RelativePath: task.relPath,
+		ProjectRoot:  task.projectRoot,
+		Size:         task.size,
+		Records:      task.records,
+		Texts:        texts,
+	})
+	if err != nil {
+		res.err = fmt.Errorf("queue deferred embeddings: %w", err)
+	} else {
+		res.chunksDeferred = len(task.records)
+	}
+	results <- res
+}
+
+// EmbedPendingFile embeds a file queued while embeddings were deferred and
+// stores it in place of the file's previous chunks. It returns the number of
+// chunks stored.
+func (idx *Indexer) EmbedPendingFile(ctx context.Context, file PendingFile) (int, error) {
+	if len(file.Records) == 0 {
+		return 0, nil
+	}
+	if len(file.Texts) != len(file.Records) {
+		return 0, fmt.Errorf("%s: %d embedding inputs for %d chunks", file.RelativePath, len(file.Texts), len(file.Records))
+	}
+	if idx.provider == nil {
+		return 0, fmt.Errorf("embedding provider is required")
+	}
+	batchSize := idx.config.BatchSize
+	embeddings := make([][]float32, 0, len(file.Texts))
+	for start := 0; start < len(file.Texts); start += batchSize {
+		end := min(start+batchSize, len(file.Texts))
+		batch, err := embedDocuments(ctx, idx.provider, file.Texts[start:end])
+		if err != nil {
+			return 0, fmt.Errorf("embed %s: %w", file.RelativePath, err)
+		}
+		if len(batch) != end-start {
+			return 0, fmt.Errorf("embed %s: provider returned %d embeddings for %d chunks", file.RelativePath, len(batch), end-start)
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	upserted, err := idx.db.UpsertChunkBatch(file.Records, embeddings, true)
+	if err != nil {
+		return 0, fmt.Errorf("store %s: %w", file.RelativePath, err)
+	}
+	return len(upserted.IDs), nil
+}
+
+// IsFatalEmbedError reports whether err should stop a run of pending
+// embeddings rather than leave one file queued and move on.
+func IsFatalEmbedError(err error) bool {
+	return errors.Is(err, embed.ErrBudgetExceeded) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,114 @@
+package index
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestDeferredEmbeddingsQueueFilesAndEmbedLater(t *testing.T) {
+	indexer, database, tmpDir := setupTestIndexer(t)
+	defer database.Close()
+	provider := indexer.provider.(*mockEmbedProvider)
+
+	projectDir := filepath.Join(tmpDir, "testproject")
+	setupTestFiles(t, projectDir)
+
+	var mu sync.Mutex
+	var pending []PendingFile
+	indexer.SetDeferredEmbeddingSink(func(file PendingFile) error {
+		mu.Lock()
+		defer mu.Unlock()
+		pending = append(pending, file)
+		return nil
+	})
+	var events []FileEvent
+	indexer.SetFileEventCallback(func(e FileEvent) { events = append(events, e) })
+
+	ctx := context.Background()
+	result, err := indexer.Index(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("deferred index failed: %v", err)
+	}
+	if provider.embedCount != 0 {
+		t.Fatalf("deferred index called the provider %d times", provider.embedCount)
+	}
+	if result.ChunksCreated != 0 || result.ChunksDeferred == 0 || len(pending) != 3 {
+		t.Fatalf("result = %+v with %d pending files, want 3 deferred files and no stored chunks", result, len(pending))
+	}
+	if stats, _ := database.Stats(); stats["chunks"] != 0 {
+		t.Fatalf("deferred index stored %d chunks", stats["chunks"])
+	}
+	deferredEvents := 0
+	for _, e := range events {
+		if e.Event == EventFileDeferred {
+			deferredEvents++
+		}
+	}
+	if deferredEvents != 3 {
+		t.Fatalf("got %d file_deferred events, want 3", deferredEvents)
+	}
+
+	// Deferred files keep no hash, so the next incremental run sees them again.
+	hashes, _ := database.GetFileHashes(projectDir)
+	if len(hashes) != 0 {
+		t.Fatalf("deferred files recorded hashes: %v", hashes)
+	}
+
+	stored := 0
+	for _, file := range pending {
+		if len(file.Texts) != len(file.Records) || file.SourceHash() == "" {
+			t.Fatalf("pending file %s has %d texts for %d records", file.RelativePath, len(file.Texts), len(file.Records))
+		}
+		n, err := indexer.EmbedPendingFile(ctx, file)
+		if err != nil {
+			t.Fatalf("EmbedPendingFile(%s) failed: %v", file.RelativePath, err)
+		}
+		stored += n
+	}
+	if stored != result.ChunksDeferred {
+		t.Fatalf("stored %d chunks, want %d", stored, result.ChunksDeferred)
+	}
+
+	indexer.SetDeferredEmbeddingSink(nil)
+	again, err := indexer.Index(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("incremental index failed: %v", err)
+	}
+	if again.FilesProcessed != 0 || again.FilesSkipped != 3 {
+		t.Fatalf("after embedding the queue, index = %+v, want all files unchanged", again)
+	}
+}
+
+func TestDeferredEmbeddingsKeepPreviousChunks(t *testing.T) {
+	indexer, database, tmpDir := setupTestIndexer(t)
+	defer database.Close()
+
+	projectDir := filepath.Join(tmpDir, "testproject")
+	setupTestFiles(t, projectDir)
+	ctx := context.Background()
+	if _, err := indexer.Index(ctx, projectDir); err != nil {
+		t.Fatalf("initial index failed: %v", err)
+	}
+	before, _ := database.Stats()
+
+	if err := os.WriteFile(filepath.Join(projectDir, "main.go"), []byte("package main\n\nfunc main() {\n\tprintln(\"changed\")\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var pending []PendingFile
+	indexer.SetDeferredEmbeddingSink(func(file PendingFile) error {
+		pending = append(pending, file)
+		return nil
+	})
+	if _, err := indexer.Index(ctx, projectDir); err != nil {
+		t.Fatalf("deferred index failed: %v", err)
+	}
+	if len(pending) != 1 || pending[0].RelativePath != "main.go" {
+		t.Fatalf("pending = %+v, want main.go", pending)
+	}
+	if after, _ := database.Stats(); after["chunks"] != before["chunks"] {
+		t.Fatalf("deferred index changed chunk count from %d to %d", before["chunks"], after["chunks"])
+	}
+}
@@ -0,0 +1,103 @@
+package index
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileEventType names one machine-readable indexing event.
+type FileEventType string
+
+const (
+	// EventRunStarted opens a run; Root is the absolute project root.
+	EventRunStarted FileEventType = "run_started"
+	// EventFileStarted reports that a changed file entered the chunker.
+	EventFileStarted FileEventType = "file_started"
+	// EventFileFinished reports a file whose chunks were embedded and stored;
+	// Warning carries a non-fatal issue such as truncation.
+	EventFileFinished FileEventType = "file_finished"
+	// EventFileDeferred reports a file whose chunks were queued for later
+	// embedding; Chunks counts the queued chunks.
+	EventFileDeferred FileEventType = "file_deferred"
+	// EventFileFailed reports a file that could not be indexed.
+	EventFileFailed FileEventType = "file_failed"
+	// EventFileDeleted reports an indexed file pruned because it no longer
+	// exists.
+	EventFileDeleted FileEventType = "file_deleted"
+	// EventRunFinished closes a run with its totals; Error is set when the
+	// run was aborted.
+	EventRunFinished FileEventType = "run_finished"
+)
+
+// FileEvent is one structured indexing event. Paths are project-relative with
+// forward slashes. Only the fields that apply to Event are set.
+type FileEvent struct {
+	Event   FileEventType `json:"event"`
+	Time    time.Time     `json:"time"`
+	Root    string        `json:"root,omitempty"`
+	Path    string        `json:"path,omitempty"`
+	Bytes   int64         `json:"bytes,omitempty"`
+	Chunks  int           `json:"chunks,omitempty"`
+	Error   string        `json:"error,omitempty"`
+	Warning string        `json:"warning,omitempty"`
+
+	// Run totals, set on EventRunFinished.
+	FilesProcessed int   `json:"files_processed,omitempty"`
+	FilesSkipped   int   `json:"files_skipped,omitempty"`
+	FilesDeleted   int   `json:"files_deleted,omitempty"`
+	ChunksCreated  int   `json:"chunks_created,omitempty"`
+	ChunksDeferred int   `json:"chunks_deferred,omitempty"`
+	Errors         int   `json:"errors,omitempty"`
+	DurationMS     int64 `json:"duration_ms,omitempty"`
+}
+
+// FileEventCallback receives indexing events. Calls are serialized, but
+// file_started events come from chunk workers, so a callback must not block
+// for long.
+type FileEventCallback func(FileEvent)
+
+// SetFileEventCallback sets a callback for per-file indexing events.
+func (idx *Indexer) SetFileEventCallback(cb FileEventCallback) {
+	idx.eventMu.Lock()
+	defer idx.eventMu.Unlock()
+	idx.events = cb
+}
+
+func (idx *Indexer) emitFileEvent(event FileEvent) {
+	idx.eventMu.Lock()
+	defer idx.eventMu.Unlock()
+	if idx.events == nil {
+		return
+	}
+	event.Time = time.Now().UTC()
+	idx.events(event)
+}
+
+// eventPath returns path relative to absRoot with forward slashes, or path
+// unchanged when it is already relative or lies outside the root.
+func eventPath(absRoot, path string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(absRoot, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// JSONLinesFileEvents returns a callback that writes each event to w as one
+// JSON object per line. Write errors are dropped so a closed reader cannot
+// fail the index run.
+func JSONLinesFileEvents(w io.Writer) FileEventCallback {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(event FileEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		_ = enc.Encode(event)
+	}
+}
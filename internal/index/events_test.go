@@ -0,0 +1,89 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func decodeFileEvents(t *testing.T, buf *bytes.Buffer) []FileEvent {
+	t.Helper()
+	var events []FileEvent
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var event FileEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("decode event line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	buf.Reset()
+	return events
+}
+
+func TestIndexEmitsFileEvents(t *testing.T) {
+	indexer, database, tmpDir := setupTestIndexer(t)
+	defer database.Close()
+	projectDir := filepath.Join(tmpDir, "events-project")
+	if err := os.MkdirAll(filepath.Join(projectDir, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		"main.go":     "package main\n\nfunc main() {}\n",
+		"pkg/util.go": "package pkg\n\nfunc Util() int { return 1 }\n",
+	} {
+		if err := os.WriteFile(filepath.Join(projectDir, path), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	indexer.SetFileEventCallback(JSONLinesFileEvents(&buf))
+
+	if _, err := indexer.Index(context.Background(), projectDir); err != nil {
+		t.Fatalf("Index: %v", err)
+	}
+	events := decodeFileEvents(t, &buf)
+	if len(events) < 2 || events[0].Event != EventRunStarted || events[len(events)-1].Event != EventRunFinished {
+		t.Fatalf("events = %+v, want run_started first and run_finished last", events)
+	}
+	started, finished := map[string]bool{}, map[string]FileEvent{}
+	for _, event := range events {
+		if event.Time.IsZero() {
+			t.Fatalf("event without time: %+v", event)
+		}
+		switch event.Event {
+		case EventFileStarted:
+			started[event.Path] = true
+		case EventFileFinished:
+			finished[event.Path] = event
+		}
+	}
+	for _, path := range []string{"main.go", "pkg/util.go"} {
+		if !started[path] || finished[path].Chunks == 0 {
+			t.Fatalf("%s: started=%v finished=%+v", path, started[path], finished[path])
+		}
+	}
+	if last := events[len(events)-1]; last.FilesProcessed != 2 || last.ChunksCreated == 0 || last.Error != "" {
+		t.Fatalf("run_finished = %+v", last)
+	}
+
+	if err := os.Remove(filepath.Join(projectDir, "pkg", "util.go")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := indexer.Index(context.Background(), projectDir); err != nil {
+		t.Fatalf("second Index: %v", err)
+	}
+	var deleted []string
+	for _, event := range decodeFileEvents(t, &buf) {
+		if event.Event == EventFileDeleted {
+			deleted = append(deleted, event.Path)
+		}
+	}
+	if len(deleted) != 1 || deleted[0] != "pkg/util.go" {
+		t.Fatalf("file_deleted paths = %v, want [pkg/util.go]", deleted)
+	}
+}
@@ -0,0 +1,150 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultExternalChunkerTimeout bounds one external chunker invocation.
+const defaultExternalChunkerTimeout = 30 * time.Second
+
+// ExternalChunkerSpec configures an external chunker process for one file
+// extension.
+//
+// Protocol: the command runs once per file (without a shell) with the file's
+// raw content on stdin and these environment variables set:
+//
+//	VECGREP_FILE           project-relative path of the file
+//	VECGREP_CHUNK_SIZE     target chunk size in characters
+//
+// It must exit 0 and write a JSON object to stdout:
+//
+//	{"language": "proto",
+//	 "chunks": [{"start_line": 1, "end_line": 12, "type": "class",
+//	             "symbol": "User", "content": "..."}]}
+//
+// language, type, symbol, and content are optional. When content is omitted
+// it is sliced from the file using the 1-based inclusive line range.
+type ExternalChunkerSpec struct {
+	Command []string
+	Timeout time.Duration
+}
+
+type externalChunkerOutput struct {
+	Language string                 `json:"language"`
+	Chunks   []externalChunkerChunk `json:"chunks"`
+}
+
+type externalChunkerChunk struct {
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Type      string `json:"type"`
+	Symbol    string `json:"symbol"`
+	Content   string `json:"content"`
+}
+
+// externalChunkerFor returns the external chunker configured for path's
+// extension, if any.
+func (idx *Indexer) externalChunkerFor(path string) (ExternalChunkerSpec, bool) {
+	if len(idx.config.ExternalChunkers) == 0 {
+		return ExternalChunkerSpec{}, false
+	}
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	if ext == "" {
+		return ExternalChunkerSpec{}, false
+	}
+	spec, ok := idx.config.ExternalChunkers[ext]
+	return spec, ok && len(spec.Command) > 0
+}
+
+// chunkContent splits a file with its configured external chunker, falling
+// back to the built-in chunker (with a warning) when the external one fails.
+// language is non-empty when the external chunker overrides detection.
+func (idx *Indexer) chunkContent(ctx context.Context, content []byte, path, relativePath string) (chunks []Chunk, language string, warning error) {
+	if spec, ok := idx.externalChunkerFor(path); ok {
+		extChunks, extLang, err := runExternalChunker(ctx, spec, content, relativePath, idx.chunker.config.ChunkSize)
+		if err == nil {
+			return idx.chunker.enforceMaxChunkChars(extChunks), extLang, nil
+		}
+		warning = fmt.Errorf("external chunker %q failed for %s; used built-in chunker: %w", spec.Command[0], relativePath, err)
+	}
+	return idx.chunker.ChunkFile(string(content), path), "", warning
+}
+
+func runExternalChunker(ctx context.Context, spec ExternalChunkerSpec, content []byte, relativePath string, chunkSize int) ([]Chunk, string, error) {
+	timeout := spec.Timeout
+	if timeout <= 0 {
+		timeout = defaultExternalChunkerTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, spec.Command[0], spec.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Env = append(os.Environ(),
+		"VECGREP_FILE="+relativePath,
+		fmt.Sprintf("VECGREP_CHUNK_SIZE=%d", chunkSize),
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, "", fmt.Errorf("timed out after %s", timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, "", err
+	}
+
+	var out externalChunkerOutput
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		return nil, "", fmt.Errorf("decode output: %w", err)
+	}
+	chunks, err := externalChunksToChunks(out.Chunks, string(content))
+	if err != nil {
+		return nil, "", err
+	}
+	return chunks, strings.TrimSpace(out.Language), nil
+}
+
+// externalChunksToChunks validates external output and fills in omitted
+// content from the file's lines.
+func externalChunksToChunks(external []externalChunkerChunk, content string) ([]Chunk, error) {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	chunks := make([]Chunk, 0, len(external))
+	for i, ec := range external {
+		if ec.StartLine < 1 || ec.EndLine < ec.StartLine || ec.EndLine > len(lines) {
+			return nil, fmt.Errorf("chunk %d: invalid line range %d-%d for a %d-line file", i, ec.StartLine, ec.EndLine, len(lines))
+		}
+		text := ec.Content
+		if text == "" {
+			text = strings.Join(lines[ec.StartLine-1:ec.EndLine], "")
+		}
+		chunkType := ChunkType(strings.TrimSpace(ec.Type))
+		if chunkType == "" {
+			chunkType = ChunkTypeGeneric
+		}
+		chunks = append(chunks, Chunk{
+			Content:    text,
+			StartLine:  ec.StartLine,
+			EndLine:    ec.EndLine,
+			ChunkType:  chunkType,
+			SymbolName: strings.TrimSpace(ec.Symbol),
+			Origin:     ChunkOriginLocal,
+		})
+	}
+	return chunks, nil
+}
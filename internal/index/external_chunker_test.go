@@ -0,0 +1,68 @@
+package index
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestExternalChunksToChunksSlicesOmittedContent(t *testing.T) {
+	content := "message A {}\n\nmessage B {\n  int32 x = 1;\n}\n"
+	chunks, err := externalChunksToChunks([]externalChunkerChunk{
+		{StartLine: 1, EndLine: 1, Type: "class", Symbol: "A"},
+		{StartLine: 3, EndLine: 5, Content: "custom"},
+	}, content)
+	if err != nil {
+		t.Fatalf("externalChunksToChunks: %v", err)
+	}
+	if chunks[0].Content != "message A {}\n" || chunks[0].ChunkType != ChunkTypeClass || chunks[0].SymbolName != "A" {
+		t.Fatalf("chunk 0 = %+v", chunks[0])
+	}
+	if chunks[1].Content != "custom" || chunks[1].ChunkType != ChunkTypeGeneric {
+		t.Fatalf("chunk 1 = %+v", chunks[1])
+	}
+
+	if _, err := externalChunksToChunks([]externalChunkerChunk{{StartLine: 4, EndLine: 6}}, content); err == nil {
+		t.Fatal("range past end of file should fail")
+	}
+}
+
+func TestChunkContentUsesExternalChunker(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	script := `cat >/dev/null; printf '{"language":"proto","chunks":[{"start_line":1,"end_line":2,"type":"class","symbol":"%s"}]}' "$VECGREP_FILE"`
+	idx := NewIndexer(nil, nil, IndexerConfig{
+		ExternalChunkers: map[string]ExternalChunkerSpec{"proto": {Command: []string{"sh", "-c", script}}},
+	})
+
+	chunks, lang, warning := idx.chunkContent(context.Background(), []byte("message A {\n}\n"), "/repo/api/a.proto", "api/a.proto")
+	if warning != nil {
+		t.Fatalf("unexpected warning: %v", warning)
+	}
+	if lang != "proto" || len(chunks) != 1 || chunks[0].SymbolName != "api/a.proto" || chunks[0].Content != "message A {\n}\n" {
+		t.Fatalf("chunkContent = %+v, %q", chunks, lang)
+	}
+
+	// Other extensions keep the built-in chunker.
+	if _, lang, warning := idx.chunkContent(context.Background(), []byte("package main\n"), "/repo/main.go", "main.go"); lang != "" || warning != nil {
+		t.Fatalf("go file = %q, %v; want built-in chunker", lang, warning)
+	}
+}
+
+func TestChunkContentFallsBackWhenExternalChunkerFails(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	idx := NewIndexer(nil, nil, IndexerConfig{
+		ExternalChunkers: map[string]ExternalChunkerSpec{"proto": {Command: []string{"sh", "-c", "echo boom >&2; exit 2"}}},
+	})
+	chunks, _, warning := idx.chunkContent(context.Background(), []byte("message A {}\n"), "/repo/a.proto", "a.proto")
+	if warning == nil || !strings.Contains(warning.Error(), "boom") {
+		t.Fatalf("warning = %v, want stderr surfaced", warning)
+	}
+	if len(chunks) == 0 {
+		t.Fatal("fallback should still chunk the file")
+	}
+}
@@ -95,6 +95,9 @@ func (idx *Indexer) scanRawFileHashes(ctx context.Context, absRoot string, ignor
 		if entry.IsDir() {
 			return nil
 		}
+		if !idx.languages.allows(relativePath) {
+			return nil
+		}
 		info, skip, err := indexableFileInfo(path, entry)
 		if err != nil {
 			return err
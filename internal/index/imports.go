@@ -0,0 +1,131 @@
+package index
+
+import "strings"
+
+// ParseImports extracts the import paths of a Go, JavaScript/TypeScript, or
+// Python source file, in the order they appear; ext is the file extension
+// with its dot. Aliases are dropped, and other languages have none.
+func ParseImports(content string, ext string) []string {
+	var imports []string
+	lines := strings.Split(content, "\n")
+
+	switch ext {
+	case ".go":
+		// Go imports
+		inImportBlock := false
+		for _, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "import (") {
+				inImportBlock = true
+				continue
+			}
+			if inImportBlock {
+				if trimmed == ")" {
+					inImportBlock = false
+					continue
+				}
+				// Extract import path
+				if imp := extractGoImport(trimmed); imp != "" {
+					imports = append(imports, imp)
+				}
+			} else if rest, found := strings.CutPrefix(trimmed, "import "); found {
+				// Single import
+				if imp := extractGoImport(rest); imp != "" {
+					imports = append(imports, imp)
+				}
+			}
+		}
+
+	case ".js", ".ts", ".jsx", ".tsx":
+		// JavaScript/TypeScript imports
+		for _, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "import ") || strings.HasPrefix(trimmed, "from ") {
+				if imp := extractJSImport(trimmed); imp != "" {
+					imports = append(imports, imp)
+				}
+			}
+			if strings.Contains(trimmed, "require(") {
+				if imp := extractRequire(trimmed); imp != "" {
+					imports = append(imports, imp)
+				}
+			}
+		}
+
+	case ".py":
+		// Python imports
+		for _, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "import ") || strings.HasPrefix(trimmed, "from ") {
+				if imp := extractPythonImport(trimmed); imp != "" {
+					imports = append(imports, imp)
+				}
+			}
+		}
+	}
+
+	return imports
+}
+
+func extractGoImport(line string) string {
+	line = strings.TrimSpace(line)
+	// Remove alias if present
+	parts := strings.Fields(line)
+	if len(parts) == 0 {
+		return ""
+	}
+	imp := parts[len(parts)-1]
+	// Remove quotes
+	imp = strings.Trim(imp, `"`)
+	if imp == "" || imp == "_" {
+		return ""
+	}
+	return imp
+}
+
+func extractJSImport(line string) string {
+	// Extract from: import ... from 'module' or import 'module'
+	if idx := strings.LastIndex(line, "from "); idx != -1 {
+		rest := strings.TrimSpace(line[idx+5:])
+		return strings.Trim(rest, `"';`)
+	}
+	if rest, found := strings.CutPrefix(line, "import "); found {
+		rest = strings.TrimSpace(rest)
+		return strings.Trim(rest, `"';`)
+	}
+	return ""
+}
+
+func extractRequire(line string) string {
+	// Extract from: require('module')
+	_, rest, found := strings.Cut(line, "require(")
+	if !found {
+		return ""
+	}
+	end := strings.IndexAny(rest, `"')`)
+	if end == -1 {
+		return ""
+	}
+	// Find the closing quote
+	closeIdx := strings.IndexAny(rest[end+1:], `"')`)
+	if closeIdx == -1 {
+		return strings.Trim(rest[:end+1], `"'`)
+	}
+	return strings.Trim(rest[end+1:end+1+closeIdx], `"'`)
+}
+
+func extractPythonImport(line string) string {
+	if strings.HasPrefix(line, "from ") {
+		parts := strings.Fields(line)
+		if len(parts) >= 2 {
+			return parts[1]
+		}
+	}
+	if rest, found := strings.CutPrefix(line, "import "); found {
+		parts := strings.Split(rest, ",")
+		if len(parts) > 0 {
+			return strings.TrimSpace(strings.Split(parts[0], " as ")[0])
+		}
+	}
+	return ""
+}
@@ -31,6 +31,7 @@ const (
 	defaultSourceBufferBytes    = 8 * 1024 * 1024
 	defaultSyncInterval         = 50
 	defaultSyncIntervalDuration = 30 * time.Second
+	defaultMaxChunksPerFile     = 500
 )
 
 // IndexerConfig holds configuration for the indexer.
@@ -39,8 +40,27 @@ type IndexerConfig struct {
 	ChunkOverlap   int
 	IgnorePatterns []string
 	MaxFileSize    int64
-	BatchSize      int
-	Workers        int
+	// MaxChunksPerFile caps the chunks a single file may contribute. Files
+	// beyond the cap are truncated and reported as a warning in
+	// IndexResult.Errors. Zero falls back to defaultMaxChunksPerFile.
+	MaxChunksPerFile int
+	// MaxChunkChars caps the bytes in one chunk, for providers that reject
+	// inputs shorter than the chunker's default cap. Zero keeps the default.
+	MaxChunkChars int
+	// MaxInputTokens is the model's input limit in tokens. Zero asks the
+	// provider, and without an answer chunk lengths are not checked.
+	MaxInputTokens int
+	// OverlongInput is what happens to a chunk over MaxInputTokens: one of
+	// OverlongTruncate (the default), OverlongSplit, or OverlongError.
+	OverlongInput string
+	// SecretsPolicy is what happens to chunks that look like they hold
+	// credentials: SecretsRedact (the default), SecretsSkip, SecretsFlag, or
+	// SecretsOff.
+	SecretsPolicy string
+	// TagRules label matching files at index time; see TagRule.
+	TagRules  []TagRule
+	BatchSize int
+	Workers   int
 	// SourceBufferBytes bounds source content retained by the walker and queue.
 	// Zero falls back to defaultSourceBufferBytes. A file larger than the budget
 	// consumes the whole budget while queued. Since workers release that charge
@@ -54,6 +74,21 @@ type IndexerConfig struct {
 	// SyncIntervalDuration is the maximum elapsed time between incremental
 	// db.Sync() calls. Zero falls back to defaultSyncIntervalDuration.
 	SyncIntervalDuration time.Duration
+	// ExternalChunkers maps lower-case file extensions (without the dot) to
+	// external chunker processes that replace the built-in chunker.
+	ExternalChunkers map[string]ExternalChunkerSpec
+	// EnabledLanguages, when non-empty, restricts indexing to files whose
+	// detected language is listed. DisabledLanguages skips the listed
+	// languages and wins over EnabledLanguages. Files with no recognized
+	// language are "unknown".
+	EnabledLanguages  []string
+	DisabledLanguages []string
+	// Ref stamps every chunk with the git ref its project root was exported
+	// from; empty for a working tree.
+	Ref string
+	// EmbedVersion stamps every chunk with the embedding configuration that
+	// produced its vector (see db.ChunkRecord.EmbedVersion).
+	EmbedVersion string
 }
 
 // DefaultIndexerConfig returns sensible defaults for indexing.
@@ -75,6 +110,7 @@ func DefaultIndexerConfig() IndexerConfig {
 			"yarn.lock",
 		},
 		MaxFileSize:          1024 * 1024, // 1MB
+		MaxChunksPerFile:     defaultMaxChunksPerFile,
 		BatchSize:            64,
 		Workers:              4,
 		SourceBufferBytes:    defaultSourceBufferBytes,
@@ -198,20 +234,46 @@ type ProgressCallback func(Progress)
 
 // Indexer orchestrates the file indexing process.
 type Indexer struct {
-	db       *db.DB
-	provider embed.Provider
-	chunker  *Chunker
-	config   IndexerConfig
-	progress ProgressCallback
+	db        *db.DB
+	provider  embed.Provider
+	chunker   *Chunker
+	config    IndexerConfig
+	languages languageFilter
+	progress  ProgressCallback
 
 	structuralMu       sync.RWMutex
 	structuralSource   StructuralChunkSource
 	structuralRequired bool
 
+	// chunkParams is the provenance parameter string stamped on every chunk.
+	chunkParams string
+	// forced holds relative paths re-indexed even when their hash is
+	// unchanged; see ForceReindex.
+	forced map[string]struct{}
+
 	observerMu sync.RWMutex
 	observer   IndexRunObserver
 	attemptID  string
 
+	eventMu sync.Mutex
+	events  FileEventCallback
+
+	// deferred, when set, receives chunked files instead of the embed
+	// pipeline; see SetDeferredEmbeddingSink.
+	deferred DeferredEmbeddingSink
+
+	// generations captures the chunks each run replaces so the run can
+	// report a ChunkDiff; nil outside a run and for deferred runs, whose
+	// chunks are not stored yet.
+	generations *chunkGenerations
+
+	// inputs checks chunk lengths against the model's input limit during a
+	// run; nil when no limit is known.
+	inputs *inputLimit
+
+	// tags holds the compiled TagRules during a run.
+	tags tagRules
+
 	// Test seams for observing storage calls without widening the public DB
 	// contract. Production leaves these nil and uses db directly.
 	syncFn       func() error
@@ -237,15 +299,19 @@ func NewIndexer(database *db.DB, provider embed.Provider, cfg IndexerConfig) *In
 		cfg.SyncIntervalDuration = defaults.SyncIntervalDuration
 	}
 	chunkerCfg := ChunkerConfig{
-		ChunkSize:    cfg.ChunkSize,
-		ChunkOverlap: cfg.ChunkOverlap,
+		ChunkSize:     cfg.ChunkSize,
+		ChunkOverlap:  cfg.ChunkOverlap,
+		MaxChunkChars: cfg.MaxChunkChars,
 	}
 
 	return &Indexer{
-		db:       database,
-		provider: provider,
-		chunker:  NewChunker(chunkerCfg),
-		config:   cfg,
+		db:        database,
+		provider:  provider,
+		chunker:   NewChunker(chunkerCfg),
+		config:    cfg,
+		languages: newLanguageFilter(cfg.EnabledLanguages, cfg.DisabledLanguages),
+
+		chunkParams: ChunkParams(cfg),
 	}
 }
 
@@ -265,7 +331,12 @@ func (idx *Indexer) deleteFile(ctx context.Context, projectRoot, path string) (i
 	if idx.deleteFileFn != nil {
 		return idx.deleteFileFn(ctx, projectRoot, path)
 	}
-	return idx.db.DeleteProjectFile(ctx, projectRoot, path)
+	deleted, err := idx.db.DeleteProjectFile(ctx, projectRoot, path)
+	if errors.Is(err, db.ErrFileNotIndexed) {
+		// New files have nothing to drop before their first insert.
+		return 0, nil
+	}
+	return deleted, err
 }
 
 // IndexResult contains the results of an indexing operation.
@@ -274,9 +345,41 @@ type IndexResult struct {
 	FilesSkipped   int
 	FilesDeleted   int
 	ChunksCreated  int
+	// ChunksReused counts stored chunks whose embedded text was unchanged,
+	// so their vectors were kept instead of being embedded again. They are
+	// included in ChunksCreated.
+	ChunksReused int
+	// ChunksTruncated counts chunks embedded from a prefix because they were
+	// over the model's input limit. They are stored whole and flagged
+	// Truncated.
+	ChunksTruncated int
+	// ChunksSplit counts overlong chunks divided into several that fit.
+	ChunksSplit int
+	// TokensEstimated reports that chunk lengths were checked with token
+	// counts estimated from text length, because the provider has no
+	// tokenizer, so ChunksTruncated and ChunksSplit are approximate.
+	TokensEstimated bool
+	// ChunksWithSecrets counts chunks the secret scanner matched, whether
+	// they were redacted, skipped, or flagged.
+	ChunksWithSecrets int
+	// ChunksDeferred counts chunks queued for later embedding instead of
+	// being stored; see SetDeferredEmbeddingSink.
+	ChunksDeferred int
 	Duration       time.Duration
 	Errors         []error
 	Ingestion      IngestionCounts
+	// ChunkDiff compares the chunks of every file the run touched with the
+	// generation it replaced. It is nil for deferred runs.
+	ChunkDiff *ChunkDiff
+}
+
+// EstimateNote returns " (estimated)" when the run's token counts were
+// estimated, for labeling its truncation and split counts.
+func (r *IndexResult) EstimateNote() string {
+	if r.TokensEstimated {
+		return " (estimated)"
+	}
+	return ""
 }
 
 // OriginCounts are exact counts for chunks written by one indexing attempt.
@@ -354,6 +457,64 @@ func (idx *Indexer) indexRunAttemptID() string {
 	return idx.attemptID
 }
 
+// beginChunkGenerations starts capturing replaced chunks for a run. Deferred
+// runs skip it: their new chunks are queued, not stored, so every touched
+// file would look emptied.
+func (idx *Indexer) beginChunkGenerations() {
+	idx.generations = nil
+	if idx.deferred == nil {
+		idx.generations = newChunkGenerations()
+	}
+}
+
+// recordIndexedEmbedding stamps the database with the provider's model and
+// dimensions unless they are already recorded. Deferred runs store no
+// vectors, so they leave the record alone.
+func (idx *Indexer) recordIndexedEmbedding() error {
+	if idx.deferred != nil || idx.provider == nil {
+		return nil
+	}
+	want := db.IndexedEmbedding{Model: idx.provider.Model(), Dimensions: idx.db.Dimensions()}
+	if current, ok := idx.db.IndexedEmbedding(); ok && current == want {
+		return nil
+	}
+	return idx.db.SetIndexedEmbedding(want)
+}
+
+// rebuildInPlace reports whether ReindexAll can replace the project's chunks
+// file by file instead of resetting it, and returns every indexed path to
+// force through the pipeline. Vectors from another model or with other
+// dimensions cannot share the collection with the new ones, and dirty file
+// state needs the reset to clear it.
+func (idx *Indexer) rebuildInPlace(absRoot string) (map[string]struct{}, bool) {
+	if idx.deferred != nil || idx.provider == nil {
+		return nil, false
+	}
+	want := db.IndexedEmbedding{Model: idx.provider.Model(), Dimensions: idx.db.Dimensions()}
+	if current, ok := idx.db.IndexedEmbedding(); !ok || current != want {
+		return nil, false
+	}
+	hashes, err := idx.db.GetFileHashes(absRoot)
+	if err != nil || len(hashes) == 0 {
+		return nil, false
+	}
+	forced := make(map[string]struct{}, len(hashes))
+	for relPath := range hashes {
+		forced[relPath] = struct{}{}
+	}
+	return forced, true
+}
+
+// finishChunkGenerations stores the run's ChunkDiff on result and stops
+// capturing.
+func (idx *Indexer) finishChunkGenerations(projectRoot string, result *IndexResult) {
+	generations := idx.generations
+	idx.generations = nil
+	if generations != nil && result != nil {
+		result.ChunkDiff = generations.diff(idx.db, projectRoot)
+	}
+}
+
 func (idx *Indexer) observeIndexRun(report IndexRunReport) error {
 	idx.observerMu.RLock()
 	observer := idx.observer
@@ -398,7 +559,9 @@ func (idx *Indexer) indexObserved(ctx context.Context, projectRoot string, paths
 		StructuralConfigured: structuralConfig.source != nil,
 		StructuralRequired:   structuralConfig.required,
 	}
+	idx.beginChunkGenerations()
 	defer func() {
+		idx.finishChunkGenerations(projectRoot, result)
 		report.FinishedAt = time.Now()
 		report.Result = result
 		report.Err = runErr
@@ -458,6 +621,10 @@ func (idx *Indexer) index(ctx context.Context, projectRoot string, deleteExistin
 // silently downgrade a symbol file to the built-in chunker.
 func (idx *Indexer) indexPrepared(ctx context.Context, projectRoot string, deleteExisting bool, structural *StructuralChunkSet, structuralWarning error, prepared *[]fileInfo, paths ...string) (*IndexResult, error) {
 	startTime := time.Now()
+	// An exhausted embedding budget aborts the run instead of failing every
+	// remaining file one batch at a time.
+	ctx, abort := context.WithCancelCause(ctx)
+	defer abort(nil)
 
 	absRoot, err := filepath.Abs(projectRoot)
 	if err != nil {
@@ -469,6 +636,9 @@ func (idx *Indexer) indexPrepared(ctx context.Context, projectRoot string, delet
 	if err != nil {
 		return nil, fmt.Errorf("build ignore matcher: %w", err)
 	}
+	if idx.tags, err = compileTagRules(idx.config.TagRules); err != nil {
+		return nil, err
+	}
 
 	// Get existing file hashes from veclite up front for incremental
 	// filtering. A durable dirty marker must fail closed: indexing everything
@@ -483,6 +653,14 @@ func (idx *Indexer) indexPrepared(ctx context.Context, projectRoot string, delet
 		existingHashes = map[string]string{}
 	}
 
+	// Record the model before any vector is stored, so the run's own syncs
+	// persist it and search can refuse query vectors from another model.
+	if err := idx.recordIndexedEmbedding(); err != nil {
+		return nil, fmt.Errorf("record embedding model: %w", err)
+	}
+	idx.inputs = idx.resolveInputLimit(ctx)
+	idx.emitFileEvent(FileEvent{Event: EventRunStarted, Root: absRoot})
+
 	batchSize := idx.config.BatchSize
 	if batchSize <= 0 {
 		batchSize = DefaultIndexerConfig().BatchSize
@@ -601,7 +779,7 @@ func (idx *Indexer) indexPrepared(ctx context.Context, projectRoot string, delet
 		go func() {
 			defer embedWG.Done()
 			for batch := range batchChan {
-				idx.embedBatch(ctx, batch, resultsChan)
+				idx.embedBatch(ctx, batch, resultsChan, abort)
 			}
 		}()
 	}
@@ -622,7 +800,7 @@ func (idx *Indexer) indexPrepared(ctx context.Context, projectRoot string, delet
 	go func() {
 		var err error
 		if prepared != nil {
-			err = feedPreparedFiles(ctx, *prepared, existingHashes, scan, fileChan, &totalDiscovered, &skippedCount, &walkedCount, &bytesWalked, &bytesQueued, &walkingFile, emitProgress)
+			err = feedPreparedFiles(ctx, *prepared, existingHashes, idx.forced, scan, fileChan, &totalDiscovered, &skippedCount, &walkedCount, &bytesWalked, &bytesQueued, &walkingFile, emitProgress)
 		} else {
 			err = idx.walkAndFilterStructural(ctx, projectRoot, absRoot, paths, ignoreMatcher, existingHashes, structural, scan, sourceBudget, sourceBudgetBytes, fileChan, &totalDiscovered, &skippedCount, &walkedCount, &bytesWalked, &bytesQueued, &walkingFile, emitProgress)
 		}
@@ -633,7 +811,7 @@ func (idx *Indexer) indexPrepared(ctx context.Context, projectRoot string, delet
 		walkErrCh <- err
 	}()
 
-	result := &IndexResult{}
+	result := &IndexResult{TokensEstimated: idx.inputs != nil && idx.inputs.estimated}
 	if structuralWarning != nil {
 		result.Errors = append(result.Errors, structuralWarning)
 		progressMu.Lock()
@@ -660,6 +838,11 @@ func (idx *Indexer) indexPrepared(ctx context.Context, projectRoot string, delet
 	for r := range resultsChan {
 		result.FilesProcessed++
 		result.ChunksCreated += r.chunksCreated
+		result.ChunksReused += r.chunksReused
+		result.ChunksTruncated += r.chunksTruncated
+		result.ChunksSplit += r.chunksSplit
+		result.ChunksWithSecrets += r.chunksWithSecrets
+		result.ChunksDeferred += r.chunksDeferred
 		result.Ingestion.add(r.ingestion)
 		atomic.StoreInt64(&processedCount, int64(result.FilesProcessed))
 		atomic.StoreInt64(&chunksCount, int64(result.ChunksCreated))
@@ -669,11 +852,25 @@ func (idx *Indexer) indexPrepared(ctx context.Context, projectRoot string, delet
 		if r.path != "" {
 			embeddingFile.Store(r.path)
 		}
-
+		event := FileEvent{Event: EventFileFinished, Path: eventPath(absRoot, r.path), Bytes: r.size, Chunks: r.chunksCreated}
+		if r.chunksDeferred > 0 {
+			event.Event, event.Chunks = EventFileDeferred, r.chunksDeferred
+		}
 		if r.err != nil {
-			result.Errors = append(result.Errors, r.err)
+			event.Event, event.Error = EventFileFailed, r.err.Error()
+		}
+		if r.warning != nil {
+			event.Warning = r.warning.Error()
+		}
+		idx.emitFileEvent(event)
+
+		for _, err := range []error{r.err, r.warning} {
+			if err == nil {
+				continue
+			}
+			result.Errors = append(result.Errors, err)
 			progressMu.Lock()
-			progressErrors = append(progressErrors, r.err)
+			progressErrors = append(progressErrors, err)
 			progressMu.Unlock()
 		}
 
@@ -721,8 +918,26 @@ func (idx *Indexer) indexPrepared(ctx context.Context, projectRoot string, delet
 	if fatalErr == nil && ctx.Err() != nil {
 		fatalErr = ctx.Err()
 	}
+	if cause := context.Cause(ctx); errors.Is(cause, embed.ErrBudgetExceeded) {
+		fatalErr = cause
+	}
 
 	result.Duration = time.Since(startTime)
+	finished := FileEvent{
+		Event:          EventRunFinished,
+		Root:           absRoot,
+		FilesProcessed: result.FilesProcessed,
+		FilesSkipped:   result.FilesSkipped,
+		FilesDeleted:   result.FilesDeleted,
+		ChunksCreated:  result.ChunksCreated,
+		ChunksDeferred: result.ChunksDeferred,
+		Errors:         len(result.Errors),
+		DurationMS:     result.Duration.Milliseconds(),
+	}
+	if fatalErr != nil {
+		finished.Error = fatalErr.Error()
+	}
+	idx.emitFileEvent(finished)
 	return result, fatalErr
 }
 
@@ -740,6 +955,9 @@ type fileInfo struct {
 	size       int64
 	content    []byte
 	queueBytes int64
+	// indexed reports that the file already has a stored hash, so its chunks
+	// can be replaced in place instead of deleted up front.
+	indexed bool
 }
 
 // fileResult holds the result of indexing a single file.
@@ -747,8 +965,21 @@ type fileResult struct {
 	path          string
 	size          int64
 	chunksCreated int
-	ingestion     IngestionCounts
-	err           error
+	// chunksReused counts chunks stored with their previous vector.
+	chunksReused int
+	// chunksTruncated and chunksSplit count chunks fitted to the model's
+	// input limit.
+	chunksTruncated int
+	chunksSplit     int
+	// chunksWithSecrets counts chunks the secret scanner matched.
+	chunksWithSecrets int
+	// chunksDeferred counts chunks handed to the deferred-embedding sink.
+	chunksDeferred int
+	ingestion      IngestionCounts
+	err            error
+	// warning is a non-fatal issue (e.g. truncation at MaxChunksPerFile); the
+	// file is still indexed and counted as processed.
+	warning error
 }
 
 // fileTask tracks one file's chunks as they are embedded across (potentially
@@ -756,11 +987,23 @@ type fileResult struct {
 // carry other files' chunks, so completion is reference-counted: the goroutine
 // that drives remaining to zero owns inserting the file and reporting it.
 type fileTask struct {
-	path      string
-	size      int64
-	records   []db.ChunkRecord // one per chunk, in chunk order
-	embeds    [][]float32      // filled in by slot as batches complete
-	ingestion IngestionCounts
+	path        string
+	relPath     string
+	projectRoot string
+	size        int64
+	records     []db.ChunkRecord // one per chunk, in chunk order
+	embeds      [][]float32      // filled in by slot as batches complete
+	ingestion   IngestionCounts
+	warning     error
+	// replace marks a previously indexed file whose stale chunks were kept
+	// so finishFile can upsert over them.
+	replace bool
+	// reused counts chunks whose embeds slot holds a stored vector.
+	reused int
+	// fitted records what fitting to the input limit changed.
+	fitted fitResult
+	// secrets counts chunks the secret scanner matched.
+	secrets int
 
 	mu        sync.Mutex
 	remaining int  // chunks not yet accounted for
@@ -817,6 +1060,7 @@ func (idx *Indexer) chunkWorker(ctx context.Context, projectRoot string, deleteE
 			return
 		default:
 		}
+		idx.emitFileEvent(FileEvent{Event: EventFileStarted, Path: file.relativePath, Bytes: file.size})
 		idx.chunkFile(ctx, projectRoot, deleteExisting, structural, file, items, results)
 	}
 }
@@ -838,13 +1082,22 @@ func (idx *Indexer) chunkFile(ctx context.Context, projectRoot string, deleteExi
 		}
 	}
 
-	// Drop stale chunks during incremental re-indexing. ReindexAll has already
-	// reset the project, so deleting every file again would be redundant.
+	// Drop stale chunks during incremental re-indexing. A ReindexAll that
+	// reset the project first passes deleteExisting=false, since deleting
+	// every file again would be redundant.
 	// This must happen before binary/empty eligibility checks: a file that was
 	// previously text can become binary (or otherwise chunkless), and a
 	// successful index must remove its old chunks/hash so raw freshness can
-	// converge instead of reporting it modified forever.
-	if deleteExisting {
+	// converge instead of reporting it modified forever. A previously indexed
+	// file that still has content is replaced in place by finishFile instead:
+	// chunks whose key and vector survive the edit keep their HNSW nodes.
+	replace := deleteExisting && file.indexed && isChunkEligibleContent(content)
+	if deleteExisting && file.indexed {
+		idx.generations.remember(idx.db, projectRoot, file.relativePath)
+	} else {
+		idx.generations.touch(file.relativePath)
+	}
+	if deleteExisting && !replace {
 		if _, err := idx.deleteFile(ctx, projectRoot, file.relativePath); err != nil {
 			results <- fileResult{path: file.path, size: file.size, err: fmt.Errorf("delete existing file chunks: %w", err)}
 			return
@@ -858,10 +1111,20 @@ func (idx *Indexer) chunkFile(ctx context.Context, projectRoot string, deleteExi
 
 	lang := DetectLanguage(file.path)
 
-	chunks := idx.chunker.ChunkFile(string(content), file.path)
+	chunks, externalLang, warning := idx.chunkContent(ctx, content, file.path, file.relativePath)
+	if externalLang != "" {
+		lang = Language(externalLang)
+	}
+	strategy := ChunkStrategyBuiltin
+	if _, ok := idx.externalChunkerFor(file.path); ok && warning == nil {
+		strategy = ChunkStrategyExternal
+	}
 	if structuralFile, ok := structuralFileForHash(structural, file.relativePath, file.sourceHash); ok {
 		chunks = structuralFile.Chunks
+		warning = nil
+		strategy = ChunkStrategyStructural
 	}
+	tags := idx.tags.match(file.relativePath, content)
 	// Release the content reference so it can be GC'd while chunks are embedded.
 	file.content = nil
 
@@ -880,39 +1143,106 @@ func (idx *Indexer) chunkFile(ctx context.Context, projectRoot string, deleteExi
 		chunks = kept
 	}
 
+	// Mask credentials before anything is stored, embedded, or counted.
+	chunks, secrets := scanSecrets(idx.config.SecretsPolicy, file.relativePath, chunks)
+	warning = errors.Join(warning, secrets.warning)
+
+	// Fit chunks to the model's input limit before capping them, since
+	// splitting adds chunks.
+	chunks, fitted, err := idx.inputs.fit(ctx, file.relativePath, chunks)
+	if err != nil {
+		results <- fileResult{path: file.path, size: file.size, err: err}
+		return
+	}
+	warning = errors.Join(warning, fitted.warning)
+
 	if len(chunks) == 0 {
-		results <- fileResult{path: file.path, size: file.size}
+		if replace {
+			if _, err := idx.deleteFile(ctx, projectRoot, file.relativePath); err != nil {
+				results <- fileResult{path: file.path, size: file.size, err: fmt.Errorf("delete existing file chunks: %w", err)}
+				return
+			}
+		}
+		results <- fileResult{path: file.path, size: file.size, warning: warning, chunksWithSecrets: secrets.found}
 		return
 	}
+	if limit := idx.maxChunksPerFile(); len(chunks) > limit {
+		warning = errors.Join(warning, fmt.Errorf("%s produced %d chunks; truncated to max_chunks_per_file=%d", file.relativePath, len(chunks), limit))
+		chunks = chunks[:limit]
+	}
 	ingestion := countChunkOrigins(chunks)
 
 	// Pre-build the records; embeddings are filled in as batches complete.
 	records := make([]db.ChunkRecord, len(chunks))
+	texts := make([]string, len(chunks))
+	parents := ChunkParents(chunks)
 	for i, chunk := range chunks {
+		texts[i] = embeddingContent(chunk)
+		chunkLang := lang
+		if chunk.Language != "" {
+			chunkLang = chunk.Language
+		}
 		records[i] = db.NewChunkRecord(
-			file.path, file.relativePath, file.hash, file.size, string(lang),
+			file.path, file.relativePath, file.hash, file.size, string(chunkLang),
 			chunk.Content, chunk.StartLine, chunk.EndLine, chunk.StartByte, chunk.EndByte,
 			string(chunk.ChunkType), chunk.SymbolName, projectRoot,
 		)
 		records[i].ChunkIndex = i
+		records[i].ParentIndex = parents[i]
 		records[i].SourceHash = file.sourceHash
-	}
+		records[i].Ref = idx.config.Ref
+		records[i].EmbedHash = idx.embedHash(texts[i])
+		records[i].EmbedVersion = idx.config.EmbedVersion
+		records[i].Truncated = chunk.Truncated
+		records[i].TruncationEstimated = chunk.Truncated && idx.inputs != nil && idx.inputs.estimated
+		records[i].Secrets = chunk.Secrets
+		records[i].Tags = tags
+	}
+	idx.setProvenance(records, strategy)
 	task := &fileTask{
-		path:      file.path,
-		size:      file.size,
-		records:   records,
-		embeds:    make([][]float32, len(chunks)),
-		remaining: len(chunks),
-		ingestion: ingestion,
+		path:        file.path,
+		relPath:     file.relativePath,
+		projectRoot: projectRoot,
+		size:        file.size,
+		records:     records,
+		embeds:      make([][]float32, len(chunks)),
+		remaining:   len(chunks),
+		ingestion:   ingestion,
+		warning:     warning,
+		replace:     replace,
+		fitted:      fitted,
+		secrets:     secrets.found,
+	}
+
+	if idx.deferred != nil {
+		idx.deferFile(task, texts, results)
+		return
 	}
 
-	for i, chunk := range chunks {
+	// An edited file keeps the vectors of the chunks the edit did not touch,
+	// so adding one function to a long file costs one embedding. A forced
+	// re-index (--full, --rechunk-stale) embeds everything again.
+	if replace && !idx.isForced(file.relativePath) {
+		task.reused = idx.reuseEmbeddings(projectRoot, file.relativePath, records, task.embeds)
+		task.remaining -= task.reused
+		if task.remaining == 0 {
+			idx.finishFile(task, results)
+			return
+		}
+	}
+
+	pending := task.remaining
+	for i := range chunks {
+		if task.embeds[i] != nil {
+			continue
+		}
 		select {
-		case items <- embedItem{task: task, slot: i, text: embeddingContent(chunk)}:
+		case items <- embedItem{task: task, slot: i, text: texts[i]}:
+			pending--
 		case <-ctx.Done():
 			// Stop feeding; let any already-queued chunks finish the file with
 			// what was embedded so far.
-			if task.skip(len(chunks) - i) {
+			if task.skip(pending) {
 				idx.finishFile(task, results)
 			}
 			return
@@ -920,6 +1250,54 @@ func (idx *Indexer) chunkFile(ctx context.Context, projectRoot string, deleteExi
 	}
 }
 
+// reuseEmbeddings copies into embeds the stored vectors of the file's chunks
+// whose embedded text is unchanged since it was last indexed, matched by
+// EmbedHash, and returns how many it filled. Chunks indexed before the hash
+// was recorded, under another embedding version, or whose vectors are
+// unavailable, are embedded again.
+func (idx *Indexer) reuseEmbeddings(projectRoot, relPath string, records []db.ChunkRecord, embeds [][]float32) int {
+	stored, err := idx.db.GetChunksByFile(relPath)
+	if err != nil {
+		return 0
+	}
+	dims := idx.db.Dimensions()
+	vectors := make(map[string][]float32, len(stored))
+	for _, chunk := range stored {
+		if chunk.EmbedHash != "" && chunk.ProjectRoot == projectRoot && chunk.Ref == idx.config.Ref &&
+			chunk.EmbedVersion == idx.config.EmbedVersion && len(chunk.Vector) == dims {
+			vectors[chunk.EmbedHash] = chunk.Vector
+		}
+	}
+	reused := 0
+	for i := range records {
+		if vector, ok := vectors[records[i].EmbedHash]; ok {
+			embeds[i] = vector
+			reused++
+		}
+	}
+	return reused
+}
+
+// embedHash identifies the exact text sent to the embedding provider and the
+// model that embeds it, so switching models never reuses an old vector.
+func (idx *Indexer) embedHash(text string) string {
+	model := ""
+	if idx.provider != nil {
+		model = idx.provider.Model()
+	}
+	sum := sha256.Sum256([]byte(model + "\x00" + text))
+	return hex.EncodeToString(sum[:16])
+}
+
+// maxChunksPerFile returns the configured per-file chunk cap, falling back to
+// the default when unset.
+func (idx *Indexer) maxChunksPerFile() int {
+	if idx.config.MaxChunksPerFile > 0 {
+		return idx.config.MaxChunksPerFile
+	}
+	return defaultMaxChunksPerFile
+}
+
 func countChunkOrigins(chunks []Chunk) IngestionCounts {
 	var counts IngestionCounts
 	for _, chunk := range chunks {
@@ -963,13 +1341,17 @@ func (idx *Indexer) batchItems(items <-chan embedItem, batches chan<- []embedIte
 }
 
 // embedBatch embeds one packed batch and scatters the results back to the files
-// the chunks came from, inserting and reporting each file as it completes.
-func (idx *Indexer) embedBatch(ctx context.Context, batch []embedItem, results chan<- fileResult) {
+// the chunks came from, inserting and reporting each file as it completes. A
+// budget rejection cancels the whole run through abort.
+func (idx *Indexer) embedBatch(ctx context.Context, batch []embedItem, results chan<- fileResult, abort context.CancelCauseFunc) {
 	texts := make([]string, len(batch))
 	for i, it := range batch {
 		texts[i] = it.text
 	}
 	embeddings, err := embedDocuments(ctx, idx.provider, texts)
+	if errors.Is(err, embed.ErrBudgetExceeded) && abort != nil {
+		abort(err)
+	}
 	for i, it := range batch {
 		var emb []float32
 		if err == nil && i < len(embeddings) {
@@ -994,20 +1376,42 @@ func (idx *Indexer) embedBatch(ctx context.Context, batch []embedItem, results c
 // only the good chunks would also persist the file's hash, so the next
 // incremental run would see the hash match and permanently skip the missing
 // chunks. Inserting nothing leaves the file un-hashed, so it is retried in full
-// next time (the stale chunks were already dropped by chunkFile's DeleteFile).
+// next time. Stale chunks were already dropped by chunkFile's DeleteFile, or
+// are dropped here for a file that was being replaced in place.
 func (idx *Indexer) finishFile(task *fileTask, results chan<- fileResult) {
 	if task.failed {
-		results <- fileResult{path: task.path, size: task.size, err: fmt.Errorf("embed: one or more chunks failed for %s", task.path)}
+		err := fmt.Errorf("embed: one or more chunks failed for %s", task.path)
+		if task.replace {
+			// Match the delete-first path: a file that failed to embed keeps
+			// neither its stale chunks nor its hash.
+			if _, delErr := idx.deleteFile(context.Background(), task.projectRoot, task.relPath); delErr != nil {
+				err = errors.Join(err, fmt.Errorf("delete existing file chunks: %w", delErr))
+			}
+		}
+		results <- fileResult{path: task.path, size: task.size, err: err}
 		return
 	}
 
-	ids, err := idx.db.InsertChunkBatch(task.records, task.embeds)
+	var ids []uint64
+	var err error
+	if task.replace {
+		var upserted db.UpsertBatchResult
+		upserted, err = idx.db.UpsertChunkBatch(task.records, task.embeds, true)
+		ids = upserted.IDs
+	} else {
+		ids, err = idx.db.InsertChunkBatch(task.records, task.embeds)
+	}
 	res := fileResult{path: task.path, size: task.size}
 	if err != nil {
 		res.err = fmt.Errorf("batch insert: %w", err)
 	} else {
 		res.chunksCreated = len(ids)
+		res.chunksReused = task.reused
+		res.chunksTruncated = task.fitted.truncated
+		res.chunksSplit = task.fitted.split
+		res.chunksWithSecrets = task.secrets
 		res.ingestion = task.ingestion
+		res.warning = task.warning
 	}
 	results <- res
 }
@@ -1102,6 +1506,9 @@ func (idx *Indexer) collectFiles(ctx context.Context, rootPath string, paths []s
 			if d.IsDir() {
 				return nil
 			}
+			if !idx.languages.allows(relPath) {
+				return nil
+			}
 
 			// Check file size. WalkDir does not follow symlinks: preserve
 			// symlink-to-file indexing, but do not pass a symlink-to-directory
@@ -1193,7 +1600,7 @@ func (idx *Indexer) prepareRequiredStructuralFiles(ctx context.Context, rootPath
 		byPath[files[i].relativePath] = i
 	}
 	for relPath, structuralFile := range structural.Files {
-		if !structuralPathSelected(absRoot, relPath, paths) || ignoreMatcher.MatchesPath(relPath) || structuralFile.FileSize > idx.config.MaxFileSize {
+		if !structuralPathSelected(absRoot, relPath, paths) || ignoreMatcher.MatchesPath(relPath) || !idx.languages.allows(relPath) || structuralFile.FileSize > idx.config.MaxFileSize {
 			continue
 		}
 		position, ok := byPath[relPath]
@@ -1236,6 +1643,7 @@ func feedPreparedFiles(
 	ctx context.Context,
 	files []fileInfo,
 	existingHashes map[string]string,
+	forced map[string]struct{},
 	scan *fullScanState,
 	fileChan chan<- fileInfo,
 	totalDiscovered, skippedCount, walkedCount, bytesWalked, bytesQueued *int64,
@@ -1259,7 +1667,9 @@ func feedPreparedFiles(
 		if scan != nil {
 			scan.observe(file.relativePath)
 		}
-		if existingHash, exists := existingHashes[file.relativePath]; exists && existingHash == file.hash {
+		existingHash, indexed := existingHashes[file.relativePath]
+		_, force := forced[file.relativePath]
+		if indexed && existingHash == file.hash && !force {
 			atomic.AddInt64(skippedCount, 1)
 			if tick != nil {
 				tick()
@@ -1267,6 +1677,7 @@ func feedPreparedFiles(
 			continue
 		}
 		file.queueBytes = 0 // content is already retained by the preflight slice
+		file.indexed = indexed
 		select {
 		case fileChan <- file:
 			atomic.AddInt64(totalDiscovered, 1)
@@ -1383,6 +1794,9 @@ func (idx *Indexer) walkAndFilterStructural(
 			if d.IsDir() {
 				return nil
 			}
+			if !idx.languages.allows(relPath) {
+				return nil
+			}
 
 			info, skip, err := indexableFileInfo(p, d)
 			if err != nil {
@@ -1456,7 +1870,8 @@ func (idx *Indexer) walkAndFilterStructural(
 
 			// Incremental filter: skip unchanged files inline so the
 			// worker pool never sees them.
-			if existingHash, exists := existingHashes[relPath]; exists && existingHash == hash {
+			existingHash, indexed := existingHashes[relPath]
+			if indexed && existingHash == hash && !idx.isForced(relPath) {
 				sourceBudget.Release(reservedBytes)
 				atomic.AddInt64(skippedCount, 1)
 				maybeTick()
@@ -1471,6 +1886,7 @@ func (idx *Indexer) walkAndFilterStructural(
 				size:         actualSize,
 				content:      content,
 				queueBytes:   reservedBytes,
+				indexed:      indexed,
 			}
 
 			select {
@@ -1547,10 +1963,12 @@ func (idx *Indexer) pruneDeletedFiles(ctx context.Context, projectRoot string, e
 	deleted := 0
 	var errs []error
 	for _, path := range stale {
+		idx.generations.remember(idx.db, projectRoot, path)
 		if _, err := idx.deleteFile(ctx, projectRoot, path); err != nil {
 			errs = append(errs, fmt.Errorf("prune deleted file %s: %w", path, err))
 			continue
 		}
+		idx.emitFileEvent(FileEvent{Event: EventFileDeleted, Path: path})
 		deleted++
 	}
 	return deleted, errs
@@ -1749,11 +2167,11 @@ func (idx *Indexer) DryRunPreview(ctx context.Context, projectRoot string) (*Dry
 
 		// Estimate chunks for this file
 		if currentFile.content != nil && IsTextFile(currentFile.content) {
-			chunks := idx.chunker.ChunkFile(string(currentFile.content), currentFile.path)
+			chunks, _, _ := idx.chunkContent(ctx, currentFile.content, currentFile.path, relPath)
 			if structuralFile, ok := structuralFileForHash(structural, relPath, currentFile.sourceHash); ok {
 				chunks = structuralFile.Chunks
 			}
-			preview.EstimatedChunks += len(chunks)
+			preview.EstimatedChunks += min(len(chunks), idx.maxChunksPerFile())
 		}
 	}
 
@@ -1778,6 +2196,11 @@ func applyStructuralFileHashes(files []fileInfo, structural *StructuralChunkSet)
 }
 
 // ReindexAll forces reindexing of all files in the project.
+//
+// When the index already holds vectors from the current embedding model it
+// is rebuilt in place (see rebuildInPlace); a first index, a model change, a
+// deferred run, or file state left dirty by an interrupted run resets the
+// project first instead.
 func (idx *Indexer) ReindexAll(ctx context.Context, projectRoot string) (result *IndexResult, runErr error) {
 	startedAt := time.Now()
 	// Resolve the external snapshot before Reset so required mode can fail
@@ -1791,7 +2214,9 @@ func (idx *Indexer) ReindexAll(ctx context.Context, projectRoot string) (result
 		StructuralConfigured: structuralConfig.source != nil,
 		StructuralRequired:   structuralConfig.required,
 	}
+	idx.beginChunkGenerations()
 	defer func() {
+		idx.finishChunkGenerations(projectRoot, result)
 		report.FinishedAt = time.Now()
 		report.Result = result
 		report.Err = runErr
@@ -1827,7 +2252,24 @@ func (idx *Indexer) ReindexAll(ctx context.Context, projectRoot string) (result
 		return nil, fmt.Errorf("abs path: %w", err)
 	}
 
-	// Delete all existing data for this project
+	// Rebuild in place when the stored vectors came from the current model:
+	// every file is re-chunked and re-embedded and swaps its old chunks for
+	// the new ones as it finishes, and files that no longer exist are pruned
+	// at the end. Searches running meanwhile see each file either before or
+	// after the rebuild, never an empty or half-empty project.
+	if existing, ok := idx.rebuildInPlace(absPath); ok {
+		previous := idx.forced
+		idx.forced = existing
+		defer func() { idx.forced = previous }()
+		if prepared != nil {
+			return idx.indexPrepared(ctx, projectRoot, true, structural, warning, prepared)
+		}
+		return idx.index(ctx, projectRoot, true, structural, warning)
+	}
+
+	// Otherwise delete all existing data for this project, keeping its
+	// fingerprints as the previous generation.
+	idx.generations.rememberProject(idx.db, projectRoot)
 	if err := idx.db.Reset(ctx, absPath); err != nil {
 		report.FailureStage = "storage_reset"
 		return nil, fmt.Errorf("reset project: %w", err)
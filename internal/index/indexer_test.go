@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -673,6 +675,48 @@ func TestIndex_NoStoredChunkExceedsCap(t *testing.T) {
 	}
 }
 
+// TestIndex_MaxChunksPerFileTruncatesWithWarning guards the per-file cap: a
+// file producing more chunks than MaxChunksPerFile is still indexed, but only
+// up to the cap, and the truncation is reported in result.Errors.
+func TestIndex_MaxChunksPerFileTruncatesWithWarning(t *testing.T) {
+	database := openTestDB(t, 8)
+	cfg := DefaultIndexerConfig()
+	cfg.MaxChunksPerFile = 3
+	indexer := NewIndexer(database, newMockEmbedProvider(8), cfg)
+
+	var b strings.Builder
+	b.WriteString("package p\n\n")
+	for i := 0; i < 12; i++ {
+		fmt.Fprintf(&b, "func F%d() int { return %d }\n", i, i)
+	}
+	projectDir := filepath.Join(t.TempDir(), "proj")
+	if err := os.MkdirAll(projectDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	fpath := filepath.Join(projectDir, "f.go")
+	if err := os.WriteFile(fpath, []byte(b.String()), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	result, err := indexer.Index(context.Background(), projectDir)
+	if err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if result.ChunksCreated != 3 {
+		t.Errorf("ChunksCreated = %d, want 3", result.ChunksCreated)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "max_chunks_per_file=3") {
+		t.Fatalf("expected one truncation warning, got %v", result.Errors)
+	}
+	hashes, err := database.GetFileHashes(projectDir)
+	if err != nil {
+		t.Fatalf("GetFileHashes: %v", err)
+	}
+	if _, ok := hashes["f.go"]; !ok {
+		t.Errorf("truncated file should still be recorded as indexed, hashes = %v", hashes)
+	}
+}
+
 // TestIndex_SingleFileSpansMultipleBatches exercises the hardest property of the
 // rewrite: one file whose chunks are scattered across SEVERAL embedding batches
 // must be gathered back and inserted exactly once, losing no chunk.
@@ -1117,6 +1161,51 @@ func TestWalkAndFilter_LargeFileConsumesWholeSourceBudget(t *testing.T) {
 	budget.Release(file.queueBytes)
 }
 
+func TestReindexAll_RebuildsInPlace(t *testing.T) {
+	indexer, database, tmpDir := setupTestIndexer(t)
+	defer database.Close()
+
+	projectDir := filepath.Join(tmpDir, "testproject")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("Failed to create project directory: %v", err)
+	}
+	setupTestFiles(t, projectDir)
+
+	ctx := context.Background()
+	first, err := indexer.Index(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("First index failed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(projectDir, "subdir", "helper.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	// A search during the rebuild must never find the project empty.
+	var emptyDuringRebuild atomic.Bool
+	indexer.SetProgressCallback(func(Progress) {
+		if stats, err := database.StatsForProject(projectDir); err == nil && stats["chunks"] == 0 {
+			emptyDuringRebuild.Store(true)
+		}
+	})
+	result, err := indexer.ReindexAll(ctx, projectDir)
+	if err != nil {
+		t.Fatalf("ReindexAll failed: %v", err)
+	}
+	if emptyDuringRebuild.Load() {
+		t.Error("project had no chunks while it was being rebuilt")
+	}
+	if result.FilesProcessed != first.FilesProcessed-1 || result.FilesDeleted != 1 {
+		t.Errorf("processed %d, deleted %d; want %d and 1", result.FilesProcessed, result.FilesDeleted, first.FilesProcessed-1)
+	}
+	stats, err := database.StatsForProject(projectDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats["files"] != int64(first.FilesProcessed-1) {
+		t.Errorf("files after rebuild = %d, want %d", stats["files"], first.FilesProcessed-1)
+	}
+}
+
 func TestReindexAll_SkipsPerFileDeleteAfterReset(t *testing.T) {
 	database := openTestDB(t, 8)
 	cfg := DefaultIndexerConfig()
@@ -1177,6 +1266,145 @@ func TestIndex_DeleteExistingFailurePreventsReplacementInsert(t *testing.T) {
 	}
 }
 
+func TestIndex_ReplacesIndexedFileInPlace(t *testing.T) {
+	database := openTestDB(t, 8)
+	idx := NewIndexer(database, newMockEmbedProvider(8), DefaultIndexerConfig())
+	root := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "a.go")
+	if err := os.WriteFile(path, []byte("package p\nfunc A() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idx.Index(context.Background(), root); err != nil {
+		t.Fatal(err)
+	}
+	before := chunkIDsByIndex(t, database, "a.go")
+
+	// Same byte ranges, new function name: every chunk keeps its key, so the
+	// file is rewritten over its existing records.
+	if err := os.WriteFile(path, []byte("package p\nfunc B() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	deleteCalls := 0
+	idx.deleteFileFn = func(context.Context, string, string) (int64, error) {
+		deleteCalls++
+		return 0, nil
+	}
+	result, err := idx.Index(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Errors) != 0 || result.ChunksCreated != len(before) {
+		t.Fatalf("result = %+v", result)
+	}
+	if deleteCalls != 0 {
+		t.Fatalf("delete calls = %d, want an in-place replacement", deleteCalls)
+	}
+	after := chunkIDsByIndex(t, database, "a.go")
+	if fmt.Sprint(after) != fmt.Sprint(before) {
+		t.Fatalf("chunk IDs after = %v, want %v", after, before)
+	}
+	chunks, _ := database.GetChunksByFile("a.go")
+	for _, chunk := range chunks {
+		if strings.Contains(chunk.Content, "func A") {
+			t.Fatalf("stale content survived: %q", chunk.Content)
+		}
+	}
+}
+
+func TestIndex_ReusesVectorsOfUnchangedChunks(t *testing.T) {
+	database := openTestDB(t, 8)
+	provider := newMockEmbedProvider(8)
+	idx := NewIndexer(database, provider, DefaultIndexerConfig())
+	root := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(root, "a.go")
+	original := "package p\n\nfunc A() int {\n\treturn 1\n}\n\nfunc B() int {\n\treturn 2\n}\n"
+	if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	first, err := idx.Index(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ChunksReused != 0 {
+		t.Fatalf("first run reused %d chunks", first.ChunksReused)
+	}
+
+	// A new function above the others shifts their lines but not their text.
+	edited := "package p\n\nfunc Z() int {\n\treturn 0\n}\n\n" + strings.TrimPrefix(original, "package p\n\n")
+	if err := os.WriteFile(path, []byte(edited), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	embedded := provider.embedCount
+	second, err := idx.Index(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ChunksReused == 0 || second.ChunksReused >= second.ChunksCreated {
+		t.Fatalf("result = %+v, want some but not all chunks reused", second)
+	}
+	if got, want := provider.embedCount-embedded, second.ChunksCreated-second.ChunksReused; got != want {
+		t.Fatalf("embedded %d chunks, want %d", got, want)
+	}
+
+	chunks, err := database.GetChunksByFile("a.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range chunks {
+		if chunk.EmbedHash == "" || len(chunk.Vector) != 8 {
+			t.Fatalf("chunk %d hash=%q vector=%d", chunk.ChunkIndex, chunk.EmbedHash, len(chunk.Vector))
+		}
+		if strings.Contains(chunk.Content, "func B") && chunk.StartLine != 11 {
+			t.Fatalf("func B starts at line %d, want 11", chunk.StartLine)
+		}
+	}
+}
+
+func TestIndex_StampsChunksWithRef(t *testing.T) {
+	database := openTestDB(t, 8)
+	cfg := DefaultIndexerConfig()
+	cfg.Ref = "feature/x"
+	idx := NewIndexer(database, newMockEmbedProvider(8), cfg)
+	root := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte("package p\nfunc A() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := idx.Index(context.Background(), root); err != nil {
+		t.Fatal(err)
+	}
+	chunks, err := database.GetChunksByFile("a.go")
+	if err != nil || len(chunks) == 0 {
+		t.Fatalf("chunks = %v, %v", chunks, err)
+	}
+	for _, chunk := range chunks {
+		if chunk.Ref != "feature/x" {
+			t.Fatalf("chunk %d ref = %q, want feature/x", chunk.ChunkIndex, chunk.Ref)
+		}
+	}
+}
+
+func chunkIDsByIndex(t *testing.T, database *db.DB, relPath string) map[int]uint64 {
+	t.Helper()
+	chunks, err := database.GetChunksByFile(relPath)
+	if err != nil || len(chunks) == 0 {
+		t.Fatalf("chunks for %s = %v, %v", relPath, chunks, err)
+	}
+	ids := make(map[int]uint64, len(chunks))
+	for _, chunk := range chunks {
+		ids[chunk.ChunkIndex] = chunk.ID
+	}
+	return ids
+}
+
 func TestIndex_FinalSyncRunsForEmptyProject(t *testing.T) {
 	database := openTestDB(t, 8)
 	idx := NewIndexer(database, newMockEmbedProvider(8), DefaultIndexerConfig())
@@ -1438,6 +1666,35 @@ func TestIndex_PeriodicSyncPolicy(t *testing.T) {
 	}
 }
 
+func TestIndex_RecordsEmbeddingModel(t *testing.T) {
+	dataDir := t.TempDir()
+	database, err := db.OpenWithOptions(db.OpenOptions{Dimensions: 8, DataDir: dataDir})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	provider := newMockEmbedProvider(8)
+	projectDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(projectDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewIndexer(database, provider, DefaultIndexerConfig()).Index(context.Background(), projectDir); err != nil {
+		t.Fatalf("index: %v", err)
+	}
+	if err := database.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	database, err = db.OpenWithOptions(db.OpenOptions{Dimensions: 8, DataDir: dataDir})
+	if err != nil {
+		t.Fatalf("reopen db: %v", err)
+	}
+	defer database.Close()
+	want := db.IndexedEmbedding{Model: provider.Model(), Dimensions: 8}
+	if got, ok := database.IndexedEmbedding(); !ok || got != want {
+		t.Fatalf("IndexedEmbedding() = %+v, %v; want %+v", got, ok, want)
+	}
+}
+
 func TestDryRunPreviewNeedsConfirm(t *testing.T) {
 	if (DryRunPreview{FilesToEmbed: 100}).NeedsConfirm() {
 		t.Fatal("small plan should not need confirm")
@@ -1467,3 +1724,301 @@ func TestProgressLargeScopeUsesSharedConstants(t *testing.T) {
 		t.Fatal("at byte threshold")
 	}
 }
+
+func TestIndex_SkipsDisabledLanguages(t *testing.T) {
+	database := openTestDB(t, 8)
+	cfg := DefaultIndexerConfig()
+	cfg.EnabledLanguages = []string{"go", "json"}
+	cfg.DisabledLanguages = []string{"JSON"}
+	idx := NewIndexer(database, newMockEmbedProvider(8), cfg)
+	root := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"main.go":     "package main\nfunc main() {}\n",
+		"data.json":   "{\"a\": 1}\n",
+		"config.yaml": "a: 1\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := idx.Index(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FilesProcessed != 1 || !database.HasFile("main.go") || database.HasFile("data.json") || database.HasFile("config.yaml") {
+		t.Fatalf("result = %+v, want only main.go indexed", result)
+	}
+
+	pending, err := idx.GetPendingChanges(context.Background(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending.TotalPending != 0 {
+		t.Fatalf("pending = %+v, want skipped languages to stay out of pending changes", pending)
+	}
+}
+
+func TestIndex_RecordsProvenanceAndRechunksStaleFiles(t *testing.T) {
+	database := openTestDB(t, 8)
+	root := filepath.Join(t.TempDir(), "project")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"a.go": "package a\nfunc A() {}\n",
+		"b.go": "package b\nfunc B() {}\n",
+	} {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	old := NewIndexer(database, newMockEmbedProvider(8), DefaultIndexerConfig())
+	if _, err := old.Index(context.Background(), root); err != nil {
+		t.Fatal(err)
+	}
+	files, err := database.ListFiles(absRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if f.ChunkStrategy != ChunkStrategyBuiltin || f.ChunkParams != ChunkParams(DefaultIndexerConfig()) || f.IndexerVersion == "" {
+			t.Fatalf("file %s provenance = %q %q %q, want builtin with default params", f.RelativePath, f.IndexerVersion, f.ChunkStrategy, f.ChunkParams)
+		}
+	}
+
+	cfg := DefaultIndexerConfig()
+	cfg.ChunkSize = 1024
+	current := NewIndexer(database, newMockEmbedProvider(8), cfg)
+	stale, err := current.StaleProvenanceFiles(absRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 2 {
+		t.Fatalf("stale = %v, want both files after a chunk size change", stale)
+	}
+
+	// Unchanged files are skipped unless forced past the hash check.
+	current.ForceReindex(stale[:1])
+	result, err := current.Index(context.Background(), root, filepath.Join(absRoot, stale[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FilesProcessed != 1 {
+		t.Fatalf("result = %+v, want the forced file re-chunked", result)
+	}
+	stale, err = current.StaleProvenanceFiles(absRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stale) != 1 || stale[0] != "b.go" {
+		t.Fatalf("stale after rechunk = %v, want only b.go", stale)
+	}
+}
+
+// wordTokenizerProvider counts one token per word and reports a fixed input
+// limit, recording the texts it embeds.
+type wordTokenizerProvider struct {
+	*mockEmbedProvider
+	limit    int
+	mu       sync.Mutex
+	embedded []string
+}
+
+func (p *wordTokenizerProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	p.mu.Lock()
+	p.embedded = append(p.embedded, texts...)
+	p.mu.Unlock()
+	return p.mockEmbedProvider.EmbedBatch(ctx, texts)
+}
+
+func (p *wordTokenizerProvider) CountTokens(_ context.Context, texts []string) ([]int, error) {
+	counts := make([]int, len(texts))
+	for i, text := range texts {
+		counts[i] = len(strings.Fields(text))
+	}
+	return counts, nil
+}
+
+func (p *wordTokenizerProvider) MaxInputTokens(context.Context) (int, error) {
+	return p.limit, nil
+}
+
+func TestIndex_FitsOverlongChunksToInputLimit(t *testing.T) {
+	// A 240-word chunk against a 100-token limit, plus the chunker's short
+	// overlap tail.
+	content := strings.Repeat("alpha beta gamma delta\n", 60)
+
+	setup := func(t *testing.T, policy string) (*Indexer, *wordTokenizerProvider, *db.DB, string) {
+		t.Helper()
+		database := openTestDB(t, 8)
+		provider := &wordTokenizerProvider{mockEmbedProvider: newMockEmbedProvider(8), limit: 100}
+		cfg := DefaultIndexerConfig()
+		cfg.ChunkSize = 8192
+		cfg.OverlongInput = policy
+		root := filepath.Join(t.TempDir(), "project")
+		if err := os.MkdirAll(root, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return NewIndexer(database, provider, cfg), provider, database, root
+	}
+
+	t.Run("truncate", func(t *testing.T) {
+		idx, provider, database, root := setup(t, "")
+		result, err := idx.Index(context.Background(), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ChunksTruncated != 1 || result.ChunksSplit != 0 || result.TokensEstimated {
+			t.Fatalf("result = %+v, want one truncated chunk by exact counts", result)
+		}
+		for _, text := range provider.embedded {
+			if n := len(strings.Fields(text)); n > provider.limit {
+				t.Fatalf("embedded %d words, over the %d limit", n, provider.limit)
+			}
+		}
+		chunks, err := database.GetChunksByFile("notes.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, chunk := range chunks {
+			overlong := chunk.StartLine == 1 && chunk.EndLine == 60
+			if chunk.Truncated != overlong || chunk.TruncationEstimated {
+				t.Fatalf("chunk %d-%d truncated=%v estimated=%v", chunk.StartLine, chunk.EndLine, chunk.Truncated, chunk.TruncationEstimated)
+			}
+			if overlong && len(strings.Fields(chunk.Content)) != 240 {
+				t.Fatalf("stored %d words, want the whole chunk", len(strings.Fields(chunk.Content)))
+			}
+		}
+	})
+
+	t.Run("estimated", func(t *testing.T) {
+		// A provider with no tokenizer is checked against the configured
+		// limit by estimated counts, and says so.
+		_, _, database, root := setup(t, "")
+		cfg := DefaultIndexerConfig()
+		cfg.ChunkSize = 8192
+		cfg.MaxInputTokens = 100
+		result, err := NewIndexer(database, newMockEmbedProvider(8), cfg).Index(context.Background(), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ChunksTruncated != 1 || !result.TokensEstimated || result.EstimateNote() != " (estimated)" {
+			t.Fatalf("result = %+v, want one truncated chunk labeled estimated", result)
+		}
+		chunks, err := database.GetChunksByFile("notes.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, chunk := range chunks {
+			if chunk.TruncationEstimated != chunk.Truncated {
+				t.Fatalf("chunk %d-%d truncated=%v estimated=%v, want truncation labeled estimated", chunk.StartLine, chunk.EndLine, chunk.Truncated, chunk.TruncationEstimated)
+			}
+		}
+		stats, err := database.GetDetailedStats(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.TruncatedChunks != 1 || stats.EstimatedTruncatedChunks != 1 {
+			t.Fatalf("stats truncated = %d, estimated = %d, want 1 and 1", stats.TruncatedChunks, stats.EstimatedTruncatedChunks)
+		}
+	})
+
+	t.Run("split", func(t *testing.T) {
+		idx, provider, database, root := setup(t, OverlongSplit)
+		result, err := idx.Index(context.Background(), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.ChunksSplit != 1 || result.ChunksTruncated != 0 {
+			t.Fatalf("result = %+v, want one split chunk", result)
+		}
+		chunks, err := database.GetChunksByFile("notes.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The pieces cover lines 1-60 back to back, each within the limit.
+		sort.Slice(chunks, func(i, j int) bool { return chunks[i].ChunkIndex < chunks[j].ChunkIndex })
+		line := 1
+		for _, chunk := range chunks {
+			if n := len(strings.Fields(chunk.Content)); n > provider.limit || chunk.Truncated {
+				t.Fatalf("chunk %d-%d has %d words, truncated=%v", chunk.StartLine, chunk.EndLine, n, chunk.Truncated)
+			}
+			if chunk.StartLine == line {
+				line = chunk.EndLine + 1
+			}
+		}
+		if line != 61 || len(chunks) < 4 {
+			t.Fatalf("%d chunks, pieces reach line %d, want three or more pieces covering lines 1-60", len(chunks), line-1)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		idx, _, database, root := setup(t, OverlongError)
+		result, err := idx.Index(context.Background(), root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Error(), "input limit") {
+			t.Fatalf("errors = %v, want the overlong chunk reported", result.Errors)
+		}
+		if chunks, _ := database.GetChunksByFile("notes.txt"); len(chunks) != 0 {
+			t.Fatalf("stored %d chunks of a failed file", len(chunks))
+		}
+	})
+}
+
+func TestProvenanceStale(t *testing.T) {
+	current := fmt.Sprintf("v%d size=1000 overlap=100 max=4000", ChunkerVersion)
+	older := fmt.Sprintf("v%d size=1000 overlap=100 max=4000", ChunkerVersion-1)
+	tests := []struct {
+		name     string
+		strategy string
+		params   string
+		stale    bool
+	}{
+		{"current builtin", ChunkStrategyBuiltin, current, false},
+		{"older builtin", ChunkStrategyBuiltin, older, true},
+		{"unrecorded", "", "", true},
+		{"structural with other sizes", ChunkStrategyStructural, fmt.Sprintf("v%d size=500 overlap=0 max=2000", ChunkerVersion), false},
+		{"structural from an older chunker", ChunkStrategyStructural, older, true},
+	}
+	for _, tt := range tests {
+		file := db.FileInfo{RelativePath: "a.go", ChunkStrategy: tt.strategy, ChunkParams: tt.params}
+		if got := ProvenanceStale(file, current); got != tt.stale {
+			t.Errorf("%s: ProvenanceStale = %v, want %v", tt.name, got, tt.stale)
+		}
+	}
+}
+
+func TestEmbeddingStale(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		legacy  string
+		stale   bool
+	}{
+		{"current", "ollama/a@8", "", false},
+		{"other model", "ollama/b@8", "ollama/a@8", true},
+		{"untagged under current profile", "", "ollama/a@8", false},
+		{"untagged under old profile", "", "ollama/b@8", true},
+		{"untagged without profile", "", "", true},
+	}
+	for _, tt := range tests {
+		file := db.FileInfo{RelativePath: "a.go", EmbedVersion: tt.version}
+		if got := EmbeddingStale(file, "ollama/a@8", tt.legacy); got != tt.stale {
+			t.Errorf("%s: EmbeddingStale = %v, want %v", tt.name, got, tt.stale)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+)
+
+// Policies for chunks longer than the model's input limit
+// (embedding.overlong_input).
+const (
+	// OverlongTruncate embeds the part of the chunk that fits and marks the
+	// chunk Truncated. The stored content stays whole.
+	OverlongTruncate = "truncate"
+	// OverlongSplit divides the chunk at line boundaries into chunks that
+	// each fit.
+	OverlongSplit = "split"
+	// OverlongError fails the file.
+	OverlongError = "error"
+)
+
+// fitAttempts bounds the count-and-shrink rounds spent fitting one chunk.
+const fitAttempts = 4
+
+// inputLimit checks chunk lengths against the model's input limit for one
+// run. A nil inputLimit checks nothing.
+type inputLimit struct {
+	tokenizer embed.Tokenizer
+	maxTokens int
+	policy    string
+	// estimated marks token counts approximated from text length because
+	// the provider has no tokenizer.
+	estimated bool
+}
+
+// fitResult reports what fitting did to a file's chunks.
+type fitResult struct {
+	truncated int
+	split     int
+	// warning is set when the chunks could not be checked and were left
+	// unchanged.
+	warning error
+}
+
+// resolveInputLimit picks the tokenizer and limit for a run: the provider's
+// own tokenizer when it has one, otherwise a length estimate, and the
+// configured MaxInputTokens or else the limit the provider reports. It
+// returns nil when no limit is known, which keeps the old behavior of letting
+// the provider truncate.
+func (idx *Indexer) resolveInputLimit(ctx context.Context) *inputLimit {
+	if idx.provider == nil || idx.deferred != nil {
+		return nil
+	}
+	limit := &inputLimit{
+		tokenizer: embed.TokenizerOf(idx.provider),
+		maxTokens: idx.config.MaxInputTokens,
+		policy:    idx.config.OverlongInput,
+	}
+	if limit.policy == "" {
+		limit.policy = OverlongTruncate
+	}
+	if limit.tokenizer == nil {
+		limit.tokenizer = embed.EstimatedTokenizer{Limit: limit.maxTokens}
+		limit.estimated = true
+	}
+	if limit.maxTokens <= 0 {
+		reported, err := limit.tokenizer.MaxInputTokens(ctx)
+		if err != nil {
+			slog.Warn("embedding input limit unavailable, chunk lengths are not checked", "err", err)
+			return nil
+		}
+		limit.maxTokens = reported
+	}
+	if limit.maxTokens <= 0 {
+		return nil
+	}
+	return limit
+}
+
+// fit makes every chunk fit the input limit according to the policy. It
+// returns the chunks to embed, what was changed, and an error when the policy
+// is OverlongError and a chunk is too long. A failed token count leaves the
+// chunks unchanged and is reported as the result's warning.
+func (l *inputLimit) fit(ctx context.Context, relPath string, chunks []Chunk) ([]Chunk, fitResult, error) {
+	var res fitResult
+	if l == nil || len(chunks) == 0 {
+		return chunks, res, nil
+	}
+	unchecked := func(what string, err error) ([]Chunk, fitResult, error) {
+		return chunks, fitResult{warning: fmt.Errorf("%s: %s, chunk lengths not checked: %w", relPath, what, err)}, nil
+	}
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = embeddingContent(chunk)
+	}
+	counts, err := l.tokenizer.CountTokens(ctx, texts)
+	if err != nil || len(counts) != len(texts) {
+		if err == nil {
+			err = fmt.Errorf("tokenizer returned %d counts for %d chunks", len(counts), len(texts))
+		}
+		return unchecked("count tokens", err)
+	}
+
+	fitted := make([]Chunk, 0, len(chunks))
+	for i, chunk := range chunks {
+		if counts[i] <= l.maxTokens {
+			fitted = append(fitted, chunk)
+			continue
+		}
+		switch l.policy {
+		case OverlongError:
+			return nil, res, fmt.Errorf("%s:%d-%d is %d tokens%s, over the model's %d-token input limit (embedding.overlong_input=error)",
+				relPath, chunk.StartLine, chunk.EndLine, counts[i], l.estimateNote(), l.maxTokens)
+		case OverlongSplit:
+			pieces, err := l.split(ctx, chunk, counts[i])
+			if err != nil {
+				return unchecked("split overlong chunk", err)
+			}
+			if len(pieces) > 1 {
+				res.split++
+			}
+			for _, piece := range pieces {
+				if piece.Truncated {
+					res.truncated++
+				}
+			}
+			fitted = append(fitted, pieces...)
+		default:
+			text, err := l.truncate(ctx, texts[i], counts[i])
+			if err != nil {
+				return unchecked("truncate overlong chunk", err)
+			}
+			chunk.EmbeddingContent = text
+			chunk.Truncated = true
+			res.truncated++
+			fitted = append(fitted, chunk)
+		}
+	}
+	return fitted, res, nil
+}
+
+func (l *inputLimit) estimateNote() string {
+	if l.estimated {
+		return " (estimated)"
+	}
+	return ""
+}
+
+// truncate returns the longest prefix of text, cut at a line break where
+// possible, that the tokenizer counts within the limit. Each round shrinks
+// the text in proportion to its overshoot; after fitAttempts rounds the last
+// cut is kept even if it still overshoots by a few tokens.
+func (l *inputLimit) truncate(ctx context.Context, text string, tokens int) (string, error) {
+	for range fitAttempts {
+		keep := int(float64(len(text)) * float64(l.maxTokens) / float64(tokens) * 0.95)
+		text = cutText(text, keep)
+		counts, err := l.tokenizer.CountTokens(ctx, []string{text})
+		if err != nil {
+			return "", err
+		}
+		if tokens = counts[0]; tokens <= l.maxTokens {
+			break
+		}
+	}
+	return text, nil
+}
+
+// split divides chunk at line boundaries into the fewest contiguous pieces
+// that each fit, doubling the piece count while any piece is too long. A
+// piece that is a single overlong line is truncated instead.
+func (l *inputLimit) split(ctx context.Context, chunk Chunk, tokens int) ([]Chunk, error) {
+	lines := strings.SplitAfter(chunk.Content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	parts := (tokens + l.maxTokens - 1) / l.maxTokens
+	var (
+		pieces []Chunk
+		counts []int
+	)
+	for attempt := range fitAttempts {
+		pieces = splitLines(chunk, lines, min(parts, len(lines)))
+		texts := make([]string, len(pieces))
+		for i, piece := range pieces {
+			texts[i] = embeddingContent(piece)
+		}
+		var err error
+		if counts, err = l.tokenizer.CountTokens(ctx, texts); err != nil {
+			return nil, err
+		}
+		over := false
+		for i, count := range counts {
+			if count > l.maxTokens && pieces[i].StartLine != pieces[i].EndLine {
+				over = true
+			}
+		}
+		if !over || parts >= len(lines) || attempt == fitAttempts-1 {
+			break
+		}
+		parts *= 2
+	}
+	for i := range pieces {
+		if counts[i] <= l.maxTokens {
+			continue
+		}
+		text, err := l.truncate(ctx, embeddingContent(pieces[i]), counts[i])
+		if err != nil {
+			return nil, err
+		}
+		pieces[i].EmbeddingContent = text
+		pieces[i].Truncated = true
+	}
+	return pieces, nil
+}
+
+// splitLines groups lines into parts contiguous pieces of about equal size,
+// keeping chunk's type, symbol, and origin and offsetting line and byte
+// positions. An enriched EmbeddingContent describes the whole chunk, so the
+// pieces embed their own content.
+func splitLines(chunk Chunk, lines []string, parts int) []Chunk {
+	target := (len(chunk.Content) + parts - 1) / parts
+	pieces := make([]Chunk, 0, parts)
+	start, size, offset := 0, 0, 0
+	flush := func(end int) {
+		content := strings.Join(lines[start:end], "")
+		pieces = append(pieces, Chunk{
+			Content:    content,
+			StartLine:  chunk.StartLine + start,
+			EndLine:    chunk.StartLine + end - 1,
+			StartByte:  chunk.StartByte + offset,
+			EndByte:    chunk.StartByte + offset + len(content),
+			ChunkType:  chunk.ChunkType,
+			SymbolName: chunk.SymbolName,
+			Origin:     chunk.Origin,
+			Language:   chunk.Language,
+		})
+		offset += len(content)
+		start, size = end, 0
+	}
+	for i, line := range lines {
+		size += len(line)
+		if size >= target && len(pieces) < parts-1 {
+			flush(i + 1)
+		}
+	}
+	if start < len(lines) {
+		flush(len(lines))
+	}
+	return pieces
+}
+
+// cutText shortens text to at most n bytes on a rune boundary, preferring to
+// end after a newline when one falls in the second half. It keeps at least
+// the first rune, since providers reject empty input.
+func cutText(text string, n int) string {
+	if n >= len(text) {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	if n == 0 {
+		_, n = utf8.DecodeRuneInString(text)
+	}
+	cut := text[:n]
+	if i := strings.LastIndexByte(cut, '\n'); i >= n/2 {
+		cut = cut[:i+1]
+	}
+	return cut
+}
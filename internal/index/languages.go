@@ -165,3 +165,41 @@ func DetectLanguage(filename string) Language {
 		return LangUnknown
 	}
 }
+
+// languageFilter applies the indexer's enabled/disabled language lists. The
+// zero value allows every file.
+type languageFilter struct {
+	enabled  map[Language]bool
+	disabled map[Language]bool
+}
+
+func newLanguageFilter(enabled, disabled []string) languageFilter {
+	return languageFilter{enabled: languageSet(enabled), disabled: languageSet(disabled)}
+}
+
+func languageSet(names []string) map[Language]bool {
+	var set map[Language]bool
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if set == nil {
+			set = make(map[Language]bool)
+		}
+		set[Language(name)] = true
+	}
+	return set
+}
+
+// allows reports whether a file at path should be indexed.
+func (f languageFilter) allows(path string) bool {
+	if f.enabled == nil && f.disabled == nil {
+		return true
+	}
+	lang := DetectLanguage(path)
+	if f.disabled[lang] {
+		return false
+	}
+	return f.enabled == nil || f.enabled[lang]
+}
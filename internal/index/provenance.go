@@ -0,0 +1,130 @@
+package index
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/version"
+)
+
+// ChunkerVersion identifies the built-in chunking behaviour and the record
+// layout stored for each chunk. Bump it in any change that alters the chunks
+// produced for unchanged input, or the payload stored for them, so indexes
+// built by older releases report stale provenance and
+// `vecgrep index --rechunk-stale` re-chunks only the affected files. Version
+// 4 added the search_terms payload that keyword search matches split
+// identifiers against.
+const ChunkerVersion = 4
+
+// Chunk strategies recorded in chunk provenance.
+const (
+	ChunkStrategyBuiltin    = "builtin"
+	ChunkStrategyExternal   = "external"
+	ChunkStrategyStructural = "structural"
+)
+
+// ChunkParams returns the provenance parameter string for cfg: the chunker
+// version plus the normalized size limits, in characters, and a digest of
+// the tag rules when there are any.
+func ChunkParams(cfg IndexerConfig) string {
+	c := NewChunker(ChunkerConfig{ChunkSize: cfg.ChunkSize, ChunkOverlap: cfg.ChunkOverlap, MaxChunkChars: cfg.MaxChunkChars}).config
+	params := fmt.Sprintf("v%d size=%d overlap=%d max=%d", ChunkerVersion, c.ChunkSize, c.ChunkOverlap, c.MaxChunkChars)
+	if digest := tagRulesDigest(cfg.TagRules); digest != "" {
+		params += " tags=" + digest
+	}
+	return params
+}
+
+// ProvenanceStale reports whether file was chunked with parameters other
+// than params. Files indexed before provenance was recorded are stale.
+// Structural chunks come from codemap, whose symbol data is already part of
+// the stored file hash, so they are only stale when an older ChunkerVersion
+// stored their records.
+func ProvenanceStale(file db.FileInfo, params string) bool {
+	if file.ChunkStrategy == ChunkStrategyStructural {
+		return chunkerVersionOf(file.ChunkParams) != ChunkerVersion
+	}
+	return file.ChunkParams != params
+}
+
+// chunkerVersionOf returns the ChunkerVersion recorded in chunk params, or
+// zero when there is none.
+func chunkerVersionOf(params string) int {
+	var v int
+	if _, err := fmt.Sscanf(params, "v%d", &v); err != nil {
+		return 0
+	}
+	return v
+}
+
+// StaleProvenanceFiles returns the relative paths of indexed files in
+// projectRoot whose chunk provenance differs from this indexer's, sorted.
+func (idx *Indexer) StaleProvenanceFiles(projectRoot string) ([]string, error) {
+	files, err := idx.db.ListFiles(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("list indexed files: %w", err)
+	}
+	var stale []string
+	for _, file := range files {
+		if ProvenanceStale(file, idx.chunkParams) {
+			stale = append(stale, file.RelativePath)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// EmbeddingStale reports whether file's vectors were produced by another
+// embedding version than current. Files indexed before versions were
+// recorded are taken to carry legacy, the version the index was built with;
+// with no legacy version known they are stale.
+func EmbeddingStale(file db.FileInfo, current, legacy string) bool {
+	version := file.EmbedVersion
+	if version == "" {
+		version = legacy
+	}
+	return version != current
+}
+
+// StaleEmbeddingFiles returns the relative paths of indexed files in
+// projectRoot whose vectors come from another embedding version than this
+// indexer's, sorted. legacy is as for EmbeddingStale.
+func (idx *Indexer) StaleEmbeddingFiles(projectRoot, legacy string) ([]string, error) {
+	files, err := idx.db.ListFiles(projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("list indexed files: %w", err)
+	}
+	var stale []string
+	for _, file := range files {
+		if EmbeddingStale(file, idx.config.EmbedVersion, legacy) {
+			stale = append(stale, file.RelativePath)
+		}
+	}
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// ForceReindex makes the next run re-chunk and re-embed the given relative
+// paths even when their content hash is unchanged.
+func (idx *Indexer) ForceReindex(relPaths []string) {
+	idx.forced = make(map[string]struct{}, len(relPaths))
+	for _, rel := range relPaths {
+		idx.forced[rel] = struct{}{}
+	}
+}
+
+func (idx *Indexer) isForced(relPath string) bool {
+	_, ok := idx.forced[relPath]
+	return ok
+}
+
+// setProvenance stamps records with the indexer version, strategy, and the
+// chunk parameters that produced them.
+func (idx *Indexer) setProvenance(records []db.ChunkRecord, strategy string) {
+	for i := range records {
+		records[i].IndexerVersion = version.Version
+		records[i].ChunkStrategy = strategy
+		records[i].ChunkParams = idx.chunkParams
+	}
+}
@@ -0,0 +1,196 @@
+package index
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Policies for chunks that look like they hold credentials
+// (indexing.secrets_policy).
+const (
+	// SecretsRedact masks each matched value before the chunk is stored or
+	// embedded, and records which rules matched. It is the default.
+	SecretsRedact = "redact"
+	// SecretsSkip leaves chunks with a match out of the index.
+	SecretsSkip = "skip"
+	// SecretsFlag stores chunks unchanged and records which rules matched.
+	SecretsFlag = "flag"
+	// SecretsOff disables the scan.
+	SecretsOff = "off"
+)
+
+// secretRule is one kind of credential the scanner recognizes. When the
+// pattern has a capture group, only the group is the secret; the rest is
+// context such as the variable name. minEntropy, when set, rejects values
+// that look like placeholders rather than random keys.
+type secretRule struct {
+	name       string
+	pattern    *regexp.Regexp
+	minEntropy float64
+}
+
+// secretRules are checked in order; an earlier rule wins where matches
+// overlap, so specific formats come before the generic assignment rule.
+var secretRules = []secretRule{
+	{name: "private-key", pattern: regexp.MustCompile(`(?s)-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----.*?(?:-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----|\z)`)},
+	{name: "aws-access-key-id", pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "github-token", pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{60,})\b`)},
+	{name: "gitlab-token", pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{name: "slack-token", pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{name: "stripe-key", pattern: regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{20,}`)},
+	{name: "google-api-key", pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{name: "openai-api-key", pattern: regexp.MustCompile(`\bsk-(?:proj-|ant-)?[A-Za-z0-9_-]{32,}`)},
+	{name: "jwt", pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{
+		name:       "generic-secret",
+		pattern:    regexp.MustCompile(`(?i)(?:api[_-]?key|secret|token|passw(?:or)?d|credentials?)["']?\s*(?::=|=>|[:=])\s*["']([^"'\s]{16,})["']`),
+		minEntropy: 3.5,
+	},
+}
+
+// secretMatch is the byte range of one detected secret.
+type secretMatch struct {
+	rule       string
+	start, end int
+}
+
+// findSecrets returns the non-overlapping secrets in text, in text order.
+func findSecrets(text string) []secretMatch {
+	var matches []secretMatch
+	overlaps := func(start, end int) bool {
+		for _, m := range matches {
+			if start < m.end && m.start < end {
+				return true
+			}
+		}
+		return false
+	}
+	for _, rule := range secretRules {
+		for _, loc := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if len(loc) >= 4 && loc[2] >= 0 {
+				start, end = loc[2], loc[3]
+			}
+			if rule.minEntropy > 0 && shannonEntropy(text[start:end]) < rule.minEntropy {
+				continue
+			}
+			if !overlaps(start, end) {
+				matches = append(matches, secretMatch{rule: rule.name, start: start, end: end})
+			}
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+	return matches
+}
+
+// redactSecrets replaces each match with a [REDACTED:<rule>] marker, keeping
+// the newlines of multi-line secrets so line numbers still line up.
+func redactSecrets(text string, matches []secretMatch) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		b.WriteString(text[last:m.start])
+		b.WriteString("[REDACTED:" + m.rule + "]")
+		b.WriteString(strings.Repeat("\n", strings.Count(text[m.start:m.end], "\n")))
+		last = m.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// shannonEntropy returns the bits per character of s.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// secretsResult reports what the scan did to a file's chunks.
+type secretsResult struct {
+	// found counts chunks with at least one match, whatever the policy.
+	found int
+	// warning names the file's affected lines and rules, never the values.
+	warning error
+}
+
+// scanSecrets applies policy to chunks that contain credentials and returns
+// the chunks to index. Redacted and flagged chunks record the matched rule
+// names in Secrets.
+func scanSecrets(policy, relPath string, chunks []Chunk) ([]Chunk, secretsResult) {
+	var res secretsResult
+	if policy == SecretsOff {
+		return chunks, res
+	}
+	if policy == "" {
+		policy = SecretsRedact
+	}
+	// A fresh slice: chunks may be a cached structural chunk set.
+	kept := make([]Chunk, 0, len(chunks))
+	var details []string
+	for _, chunk := range chunks {
+		content := findSecrets(chunk.Content)
+		var enriched []secretMatch
+		if chunk.EmbeddingContent != "" {
+			enriched = findSecrets(chunk.EmbeddingContent)
+		}
+		if len(content) == 0 && len(enriched) == 0 {
+			kept = append(kept, chunk)
+			continue
+		}
+		res.found++
+		chunk.Secrets = secretRuleNames(content, enriched)
+		details = append(details, fmt.Sprintf("%d-%d (%s)", chunk.StartLine, chunk.EndLine, strings.Join(chunk.Secrets, ", ")))
+		switch policy {
+		case SecretsSkip:
+			continue
+		case SecretsRedact:
+			chunk.Content = redactSecrets(chunk.Content, content)
+			if chunk.EmbeddingContent != "" {
+				chunk.EmbeddingContent = redactSecrets(chunk.EmbeddingContent, enriched)
+			}
+		}
+		kept = append(kept, chunk)
+	}
+	if res.found > 0 {
+		verb := "redacted"
+		switch policy {
+		case SecretsSkip:
+			verb = "skipped"
+		case SecretsFlag:
+			verb = "flagged"
+		}
+		res.warning = fmt.Errorf("%s: possible secrets %s at lines %s", relPath, verb, strings.Join(details, "; "))
+	}
+	return kept, res
+}
+
+// secretRuleNames returns the sorted, distinct rule names of the matches.
+func secretRuleNames(groups ...[]secretMatch) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, matches := range groups {
+		for _, m := range matches {
+			if !seen[m.rule] {
+				seen[m.rule] = true
+				names = append(names, m.rule)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,97 @@
+package index
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Fake credentials are assembled at run time so the test file itself does
+// not trip secret scanners.
+var (
+	fakeAWSKey    = "AKIA" + "IOSFODNN7EXAMPLE"
+	fakeGitHubPAT = "ghp_" + strings.Repeat("a1B2c3D4e5", 4)
+	fakePassword  = "Zq8" + "vT1xW4mN7pR2kL9s"
+	fakePEM       = "-----BEGIN RSA " + "PRIVATE KEY-----\nMIIBOgIBAAJBAK\nj5Yq3Xz\n-----END RSA " + "PRIVATE KEY-----"
+)
+
+func TestFindSecrets(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"aws key", "aws_access_key_id = " + fakeAWSKey, []string{"aws-access-key-id"}},
+		{"github token", "token: " + fakeGitHubPAT, []string{"github-token"}},
+		{"private key", "key := `" + fakePEM + "`", []string{"private-key"}},
+		{"generic assignment", `db_password = "` + fakePassword + `"`, []string{"generic-secret"}},
+		{"placeholder is not a secret", `api_key = "xxxxxxxxxxxxxxxxxxxx"`, nil},
+		{"identifier is not a secret", `token := strings.TrimSpace(os.Getenv("GITHUB_TOKEN"))`, nil},
+		{"two kinds", fakeAWSKey + "\n" + fakeGitHubPAT, []string{"aws-access-key-id", "github-token"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range findSecrets(tt.text) {
+				got = append(got, m.rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactSecretsKeepsLines(t *testing.T) {
+	text := "const key = `" + fakePEM + "`\nconst id = \"" + fakeAWSKey + "\"\n"
+	got := redactSecrets(text, findSecrets(text))
+	if strings.Contains(got, "MIIBOgIBAAJBAK") || strings.Contains(got, fakeAWSKey) {
+		t.Fatalf("secret survived redaction:\n%s", got)
+	}
+	if !strings.Contains(got, "[REDACTED:private-key]") || !strings.Contains(got, `const id = "[REDACTED:aws-access-key-id]"`) {
+		t.Fatalf("missing redaction markers:\n%s", got)
+	}
+	if strings.Count(got, "\n") != strings.Count(text, "\n") {
+		t.Fatalf("redaction changed the line count:\n%s", got)
+	}
+}
+
+func TestScanSecretsPolicies(t *testing.T) {
+	chunks := func() []Chunk {
+		return []Chunk{
+			{Content: "func A() {}", StartLine: 1, EndLine: 1},
+			{Content: `var password = "` + fakePassword + `"`, StartLine: 3, EndLine: 3},
+		}
+	}
+
+	kept, res := scanSecrets("", "a.go", chunks())
+	if len(kept) != 2 || res.found != 1 || res.warning == nil {
+		t.Fatalf("redact: kept=%d found=%d warning=%v", len(kept), res.found, res.warning)
+	}
+	if strings.Contains(kept[1].Content, fakePassword) || !reflect.DeepEqual(kept[1].Secrets, []string{"generic-secret"}) {
+		t.Fatalf("redact: chunk = %+v", kept[1])
+	}
+	if strings.Contains(res.warning.Error(), fakePassword) || !strings.Contains(res.warning.Error(), "a.go: possible secrets redacted at lines 3-3") {
+		t.Fatalf("redact: warning = %v", res.warning)
+	}
+
+	kept, res = scanSecrets(SecretsSkip, "a.go", chunks())
+	if len(kept) != 1 || kept[0].StartLine != 1 || res.found != 1 {
+		t.Fatalf("skip: kept=%+v found=%d", kept, res.found)
+	}
+
+	kept, _ = scanSecrets(SecretsFlag, "a.go", chunks())
+	if len(kept) != 2 || !strings.Contains(kept[1].Content, fakePassword) || len(kept[1].Secrets) != 1 {
+		t.Fatalf("flag: chunk = %+v", kept[1])
+	}
+
+	input := chunks()
+	kept, res = scanSecrets(SecretsOff, "a.go", input)
+	if len(kept) != 2 || res.found != 0 || kept[1].Secrets != nil {
+		t.Fatalf("off: kept=%+v found=%d", kept, res.found)
+	}
+	scanSecrets(SecretsRedact, "a.go", input)
+	if !strings.Contains(input[1].Content, fakePassword) {
+		t.Fatal("scanSecrets modified its input slice")
+	}
+}
@@ -0,0 +1,108 @@
+package index
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TagRule labels files at index time (indexing.tags). Path is a regular
+// expression matched against the file's slash-separated path relative to its
+// project root, Content one matched against the whole file; every pattern
+// that is set must match. Tags are stored lower-case on each chunk of a
+// matching file.
+type TagRule struct {
+	Tags    []string
+	Path    string
+	Content string
+}
+
+type compiledTagRule struct {
+	tags    []string
+	path    *regexp.Regexp
+	content *regexp.Regexp
+}
+
+// tagRules is the compiled form of IndexerConfig.TagRules.
+type tagRules []compiledTagRule
+
+// compileTagRules validates and compiles rules. A rule needs at least one
+// tag and at least one pattern.
+func compileTagRules(rules []TagRule) (tagRules, error) {
+	compiled := make(tagRules, 0, len(rules))
+	for i, rule := range rules {
+		c := compiledTagRule{tags: normalizeTags(rule.Tags)}
+		if len(c.tags) == 0 {
+			return nil, fmt.Errorf("indexing.tags[%d]: tags is required", i)
+		}
+		if rule.Path == "" && rule.Content == "" {
+			return nil, fmt.Errorf("indexing.tags[%d]: path or content is required", i)
+		}
+		var err error
+		if rule.Path != "" {
+			if c.path, err = regexp.Compile(rule.Path); err != nil {
+				return nil, fmt.Errorf("indexing.tags[%d]: path: %w", i, err)
+			}
+		}
+		if rule.Content != "" {
+			if c.content, err = regexp.Compile(rule.Content); err != nil {
+				return nil, fmt.Errorf("indexing.tags[%d]: content: %w", i, err)
+			}
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// match returns the sorted tags of every rule that matches the file.
+func (rules tagRules) match(relPath string, content []byte) []string {
+	if len(rules) == 0 {
+		return nil
+	}
+	relPath = filepath.ToSlash(relPath)
+	var tags []string
+	for _, rule := range rules {
+		if rule.path != nil && !rule.path.MatchString(relPath) {
+			continue
+		}
+		if rule.content != nil && !rule.content.Match(content) {
+			continue
+		}
+		tags = append(tags, rule.tags...)
+	}
+	return normalizeTags(tags)
+}
+
+// normalizeTags lower-cases, de-duplicates, and sorts tags. Commas are
+// dropped because the stored payload is comma-joined.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var out []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, ",", "")))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// tagRulesDigest identifies a rule set in chunk provenance, so editing the
+// rules marks indexed files stale; empty without rules.
+func tagRulesDigest(rules []TagRule) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	h := sha256.New()
+	for _, rule := range rules {
+		fmt.Fprintf(h, "%q %q %q\n", normalizeTags(rule.Tags), rule.Path, rule.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:8]
+}
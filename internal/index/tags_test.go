@@ -0,0 +1,65 @@
+package index
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTagRulesMatch(t *testing.T) {
+	rules, err := compileTagRules([]TagRule{
+		{Tags: []string{"API"}, Path: `^internal/(api|mcp)/`},
+		{Tags: []string{"infra", "api"}, Path: `\.tf$`},
+		{Tags: []string{"frontend"}, Content: `(?m)^import React`},
+		{Tags: []string{"http"}, Path: `^internal/`, Content: `net/http`},
+	})
+	if err != nil {
+		t.Fatalf("compileTagRules: %v", err)
+	}
+	tests := []struct {
+		path    string
+		content string
+		want    []string
+	}{
+		{"internal/api/handler.go", "package api", []string{"api"}},
+		{"internal/mcp/http.go", `import "net/http"`, []string{"api", "http"}},
+		{"deploy/main.tf", "resource {}", []string{"api", "infra"}},
+		{"web/App.tsx", "import React from 'react'", []string{"frontend"}},
+		{"cmd/server.go", `import "net/http"`, nil},
+	}
+	for _, tt := range tests {
+		if got := rules.match(tt.path, []byte(tt.content)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("match(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCompileTagRulesRejectsBadRules(t *testing.T) {
+	for _, tt := range []struct {
+		rule TagRule
+		want string
+	}{
+		{TagRule{Path: "^api/"}, "tags is required"},
+		{TagRule{Tags: []string{"api"}}, "path or content is required"},
+		{TagRule{Tags: []string{"api"}, Path: "("}, "indexing.tags[0]: path"},
+		{TagRule{Tags: []string{"api"}, Content: "["}, "indexing.tags[0]: content"},
+	} {
+		if _, err := compileTagRules([]TagRule{tt.rule}); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("compileTagRules(%+v) error = %v, want %q", tt.rule, err, tt.want)
+		}
+	}
+}
+
+func TestChunkParamsIncludeTagRules(t *testing.T) {
+	cfg := DefaultIndexerConfig()
+	base := ChunkParams(cfg)
+	cfg.TagRules = []TagRule{{Tags: []string{"api"}, Path: "^api/"}}
+	tagged := ChunkParams(cfg)
+	if tagged == base || !strings.HasPrefix(tagged, base+" tags=") {
+		t.Fatalf("ChunkParams with tag rules = %q, want %q plus a tags digest", tagged, base)
+	}
+	cfg.TagRules[0].Path = "^web/"
+	if ChunkParams(cfg) == tagged {
+		t.Fatal("editing a tag rule did not change ChunkParams")
+	}
+}
@@ -0,0 +1,24 @@
+use std::collections::HashMap;
+
+pub trait Store {
+    fn get(&self, key: &str) -> Option<String>;
+}
+
+pub struct Cache {
+    entries: HashMap<String, String>,
+}
+
+impl Cache {
+    pub fn new() -> Self {
+        Cache { entries: HashMap::new() }
+    }
+}
+
+pub enum Eviction {
+    Lru,
+    Fifo,
+}
+
+fn hash(key: &str) -> u64 {
+    key.len() as u64
+}
@@ -0,0 +1,26 @@
+import { fetch } from "./http";
+
+export interface Options {
+  baseUrl: string;
+  retries?: number;
+}
+
+export enum Method {
+  Get = "GET",
+  Post = "POST",
+}
+
+export class Client {
+  constructor(private readonly options: Options) {}
+
+  async get(path: string) {
+    return fetch(this.options.baseUrl + path, { method: Method.Get });
+  }
+}
+
+export function createClient(options: Options): Client {
+  return new Client(options);
+}
+
+const DEFAULT_URL = "http://localhost";
+export default createClient({ baseUrl: DEFAULT_URL });
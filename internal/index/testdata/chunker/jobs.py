@@ -0,0 +1,35 @@
+"""Background job runner."""
+
+import time
+from enum import Enum
+
+RETRY_LIMIT = 3
+
+
+class State(Enum):
+    PENDING = "pending"
+    DONE = "done"
+
+
+class Job:
+    def __init__(self, name):
+        self.name = name
+        self.state = State.PENDING
+
+    # Runs the job once.
+    def run(self):
+        self.state = State.DONE
+
+
+def run_all(jobs):
+    for job in jobs:
+        for attempt in range(RETRY_LIMIT):
+            try:
+                job.run()
+                break
+            except Exception:
+                time.sleep(attempt)
+
+
+async def run_later(job):
+    job.run()
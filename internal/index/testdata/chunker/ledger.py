@@ -0,0 +1,27 @@
+"""Ledger keeps balances for accounts.
+
+Entries are append-only; balances are derived by replaying them.
+"""
+
+from dataclasses import dataclass
+
+
+@dataclass
+class Entry:
+    """One posting against an account, positive for credits and negative for debits."""
+
+    account: str
+    amount: int
+
+
+def balance(entries, account):
+    """Return the balance of account.
+
+    Replays every entry in order, so the result reflects reversals too.
+    """
+    return sum(e.amount for e in entries if e.account == account)
+
+
+def post(entries, account, amount):
+    """Post amount."""
+    entries.append(Entry(account, amount))
@@ -0,0 +1,33 @@
+// Package retry runs operations until they succeed.
+package retry
+
+import "time"
+
+// Policy describes how often an operation is retried and how long to wait
+// between attempts. The zero value retries three times without waiting.
+type Policy struct {
+	Attempts int
+	Delay    time.Duration
+}
+
+// Do calls op until it returns nil or the policy's attempts are used up,
+// sleeping the policy's delay between attempts. It returns op's last error.
+func Do(p Policy, op func() error) error {
+	attempts := p.Attempts
+	if attempts == 0 {
+		attempts = 3
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		if err = op(); err == nil {
+			return nil
+		}
+		time.Sleep(p.Delay)
+	}
+	return err
+}
+
+// Once calls op a single time.
+func Once(op func() error) error {
+	return op()
+}
@@ -0,0 +1,51 @@
+// Package server serves the example API.
+package server
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ErrClosed is returned after Close.
+var ErrClosed = errors.New("server closed")
+
+const (
+	defaultPort = 8080
+	defaultHost = "localhost"
+)
+
+// Handler answers API requests.
+type Handler interface {
+	ServeAPI(w http.ResponseWriter, r *http.Request) error
+}
+
+// Server routes requests to handlers.
+type Server struct {
+	handlers map[string]Handler
+	closed   bool
+}
+
+// New returns an empty server.
+func New() *Server {
+	return &Server{handlers: map[string]Handler{}}
+}
+
+// Handle registers h for path.
+func (s *Server) Handle(path string, h Handler) {
+	s.handlers[path] = h
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.closed {
+		http.Error(w, ErrClosed.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	h, ok := s.handlers[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if err := h.ServeAPI(w, r); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
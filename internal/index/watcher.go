@@ -4,16 +4,22 @@ package index
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
+// DefaultPollInterval is how often a polling watcher rescans the tree when
+// WatcherConfig.PollInterval is unset.
+const DefaultPollInterval = 2 * time.Second
+
 // WatcherConfig configures the file watcher behavior.
 type WatcherConfig struct {
 	// Debounce is the duration to wait before processing changes.
@@ -28,6 +34,17 @@ type WatcherConfig struct {
 
 	// Recursive enables recursive directory watching.
 	Recursive bool
+
+	// Poll detects changes by rescanning the tree every PollInterval and
+	// comparing content hashes instead of relying on file system events.
+	// Polling is also chosen automatically when the root is on a network
+	// file system or events cannot be set up.
+	Poll bool
+
+	// PollInterval is the time between scans of a polling watcher. Each
+	// scan's changes are delivered as one batch, so it also takes the place
+	// of Debounce.
+	PollInterval time.Duration
 }
 
 // DefaultWatcherConfig returns sensible defaults for the watcher.
@@ -50,8 +67,9 @@ func DefaultWatcherConfig() WatcherConfig {
 			"*~",
 			".#*",
 		},
-		MaxFileSize: 1024 * 1024, // 1MB
-		Recursive:   true,
+		MaxFileSize:  1024 * 1024, // 1MB
+		Recursive:    true,
+		PollInterval: DefaultPollInterval,
 	}
 }
 
@@ -105,6 +123,12 @@ type Watcher struct {
 	pendingMu sync.Mutex
 	pending   map[string]WatchEvent
 
+	// polling is set when changes are found by rescanning, for pollReason;
+	// polled is the tree as of the last scan.
+	polling    bool
+	pollReason string
+	polled     map[string]pollEntry
+
 	lifecycleMu sync.Mutex
 	started     bool
 	stopped     bool
@@ -115,25 +139,56 @@ type Watcher struct {
 	doneCh      chan struct{}
 }
 
-// NewWatcher creates a new file watcher for the given root path.
+// NewWatcher creates a new file watcher for the given root path. It polls
+// when cfg.Poll is set, when the root is on a network file system, or when
+// file system events are unavailable.
 func NewWatcher(rootPath string, cfg WatcherConfig) (*Watcher, error) {
-	fsWatcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		return nil, err
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = DefaultPollInterval
 	}
-
 	w := &Watcher{
 		config:   cfg,
-		watcher:  fsWatcher,
 		rootPath: rootPath,
 		pending:  make(map[string]WatchEvent),
 		stopCh:   make(chan struct{}),
 		doneCh:   make(chan struct{}),
 	}
 
+	switch fsType, network := networkFilesystem(rootPath); {
+	case cfg.Poll:
+		w.usePolling("requested")
+	case network:
+		w.usePolling(fsType + " file system")
+	default:
+		fsWatcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			w.usePolling(fmt.Sprintf("file events unavailable: %v", err))
+			break
+		}
+		w.watcher = fsWatcher
+	}
+
 	return w, nil
 }
 
+func (w *Watcher) usePolling(reason string) {
+	w.polling = true
+	w.pollReason = reason
+}
+
+// Mode describes how the watcher finds changes: "events", or polling with
+// its interval and the reason it was chosen. A watcher that cannot register
+// event watches falls back to polling in Start, so the mode is settled once
+// Start returns.
+func (w *Watcher) Mode() string {
+	w.lifecycleMu.Lock()
+	defer w.lifecycleMu.Unlock()
+	if !w.polling {
+		return "events"
+	}
+	return fmt.Sprintf("polling every %s (%s)", w.config.PollInterval, w.pollReason)
+}
+
 // SetCallback sets the callback function for file change events.
 func (w *Watcher) SetCallback(cb WatchCallback) {
 	w.callback = cb
@@ -150,26 +205,49 @@ func (w *Watcher) Start(ctx context.Context) error {
 		return errors.New("watcher already stopped")
 	}
 
-	// Add root path and optionally recurse
-	if err := w.addPath(w.rootPath); err != nil {
-		w.stopped = true
-		return errors.Join(err, w.closeFSWatcher())
-	}
-
-	if w.config.Recursive {
-		if err := w.addRecursive(w.rootPath); err != nil {
+	if !w.polling {
+		// Add root path and optionally recurse
+		err := w.addPath(w.rootPath)
+		if err == nil && w.config.Recursive {
+			err = w.addRecursive(w.rootPath)
+		}
+		switch {
+		case err == nil:
+			// Start event processing goroutine
+			w.started = true
+			go w.processEvents(ctx)
+			return nil
+		case watchLimitReached(err):
+			if cerr := w.closeFSWatcher(); cerr != nil {
+				slog.Warn("watcher close failed", "err", cerr)
+			}
+			w.usePolling(fmt.Sprintf("file event watches unavailable: %v", err))
+		default:
 			w.stopped = true
 			return errors.Join(err, w.closeFSWatcher())
 		}
 	}
 
-	// Start event processing goroutine
+	// The first scan is the baseline: files already present are not changes.
+	polled, err := w.scanTree(nil)
+	if err != nil {
+		w.stopped = true
+		return err
+	}
+	w.polled = polled
 	w.started = true
-	go w.processEvents(ctx)
+	go w.pollEvents(ctx)
 
 	return nil
 }
 
+// watchLimitReached reports whether err means the system ran out of file
+// event watches or descriptors, as on large trees under a low
+// fs.inotify.max_user_watches, rather than that the tree cannot be read.
+func watchLimitReached(err error) bool {
+	return errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.EMFILE)
+}
+
 // Stop stops the watcher and releases resources.
 func (w *Watcher) Stop() error {
 	w.lifecycleMu.Lock()
@@ -186,7 +264,9 @@ func (w *Watcher) Stop() error {
 
 func (w *Watcher) closeFSWatcher() error {
 	w.closeOnce.Do(func() {
-		w.closeErr = w.watcher.Close()
+		if w.watcher != nil {
+			w.closeErr = w.watcher.Close()
+		}
 	})
 	return w.closeErr
 }
@@ -281,7 +361,7 @@ func (w *Watcher) processEvents(ctx context.Context) {
 			if !ok {
 				return
 			}
-			log.Printf("watcher error: %v", err)
+			slog.Warn("watcher error", "err", err)
 
 		case <-ticker.C:
 			w.flushPending()
@@ -310,7 +390,7 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 				// Add new directories to watch list
 				if event.Op&fsnotify.Create != 0 && w.config.Recursive {
 					if err := w.addRecursive(event.Name); err != nil {
-						log.Printf("failed to watch new directory %s: %v", event.Name, err)
+						slog.Warn("failed to watch new directory", "dir", event.Name, "err", err)
 					}
 				}
 				return
@@ -337,11 +417,17 @@ func (w *Watcher) handleEvent(event fsnotify.Event) {
 	}
 
 	// Add to pending events
+	w.addPending(event.Name, op, time.Now())
+}
+
+// addPending records a change to path for the next flush, replacing any
+// earlier change to it.
+func (w *Watcher) addPending(path string, op WatchOp, at time.Time) {
 	w.pendingMu.Lock()
-	w.pending[event.Name] = WatchEvent{
-		Path:      event.Name,
+	w.pending[path] = WatchEvent{
+		Path:      path,
 		Op:        op,
-		Timestamp: time.Now(),
+		Timestamp: at,
 	}
 	w.pendingMu.Unlock()
 }
@@ -0,0 +1,28 @@
+//go:build linux
+
+package index
+
+import "syscall"
+
+// networkFilesystemTypes names the statfs magic numbers of file systems on
+// which inotify misses changes made by other hosts or the container host.
+var networkFilesystemTypes = map[uint32]string{
+	0x6969:     "nfs",
+	0x517b:     "smb",
+	0xff534d42: "cifs",
+	0xfe534d42: "smb2",
+	0x01021997: "9p",
+	0x65735546: "fuse",
+	0x6a656a63: "virtiofs",
+}
+
+// networkFilesystem reports whether path is on a network or host-shared
+// file system, and which.
+func networkFilesystem(path string) (string, bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return "", false
+	}
+	name, ok := networkFilesystemTypes[uint32(st.Type)]
+	return name, ok
+}
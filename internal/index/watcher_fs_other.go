@@ -0,0 +1,8 @@
+//go:build !linux
+
+package index
+
+// networkFilesystem reports no network file systems where vecgrep cannot
+// identify them; polling is then chosen only on request or when events
+// cannot be set up.
+func networkFilesystem(path string) (string, bool) { return "", false }
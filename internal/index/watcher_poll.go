@@ -0,0 +1,126 @@
+package index
+
+import (
+	"context"
+	"crypto/sha256"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// pollEntry is what a polling watcher remembers of a file between scans.
+type pollEntry struct {
+	size    int64
+	modTime time.Time
+	hash    [sha256.Size]byte
+}
+
+// pollEvents rescans the tree every PollInterval and hands each scan's
+// changes to the callback as one batch.
+func (w *Watcher) pollEvents(ctx context.Context) {
+	defer close(w.doneCh)
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			w.pollChanges()
+			w.flushPending()
+		}
+	}
+}
+
+// pollChanges scans the tree and records every file created, removed, or
+// changed in content since the previous scan. A file whose modification
+// time moved but whose content hash did not is not a change. When the root
+// cannot be read, as while a network mount is away, the scan is skipped
+// rather than reporting every file removed.
+func (w *Watcher) pollChanges() {
+	next, err := w.scanTree(w.polled)
+	if err != nil {
+		slog.Warn("watcher poll failed", "err", err)
+		return
+	}
+
+	now := time.Now()
+	for path, entry := range next {
+		prev, known := w.polled[path]
+		switch {
+		case !known:
+			w.addPending(path, OpCreate, now)
+		case prev.hash != entry.hash:
+			w.addPending(path, OpWrite, now)
+		}
+	}
+	for path := range w.polled {
+		if _, ok := next[path]; !ok {
+			w.addPending(path, OpRemove, now)
+		}
+	}
+	w.polled = next
+}
+
+// scanTree walks the watched tree, honoring the ignore patterns, Recursive,
+// and MaxFileSize, and returns the state of every watched file. Files whose
+// size and modification time match their entry in prev are not read again.
+// A file over MaxFileSize or that cannot be read keeps its previous entry,
+// the same as an event watcher ignoring its writes.
+func (w *Watcher) scanTree(prev map[string]pollEntry) (map[string]pollEntry, error) {
+	next := make(map[string]pollEntry, len(prev))
+	err := filepath.WalkDir(w.rootPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if p == w.rootPath {
+				return err
+			}
+			return nil // Skip files we can't access
+		}
+		if p == w.rootPath {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(w.rootPath, p)
+		if err != nil {
+			relPath = p
+		}
+		if d.IsDir() {
+			if !w.config.Recursive || w.shouldIgnore(relPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || w.shouldIgnore(relPath) {
+			return nil
+		}
+
+		old, known := prev[p]
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if known && old.size == info.Size() && old.modTime.Equal(info.ModTime()) {
+			next[p] = old
+			return nil
+		}
+		var content []byte
+		if info.Size() <= w.config.MaxFileSize {
+			content, err = os.ReadFile(p)
+		}
+		if info.Size() > w.config.MaxFileSize || err != nil {
+			if known {
+				next[p] = old
+			}
+			return nil
+		}
+		next[p] = pollEntry{size: info.Size(), modTime: info.ModTime(), hash: sha256.Sum256(content)}
+		return nil
+	})
+	return next, err
+}
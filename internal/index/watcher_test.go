@@ -2,7 +2,9 @@ package index
 
 import (
 	"context"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -73,3 +75,88 @@ func TestWatcherStopAfterStartIsIdempotent(t *testing.T) {
 		t.Fatalf("second Stop() error = %v", err)
 	}
 }
+
+func TestPollingWatcherReportsContentChanges(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("edited.go", "package a\n")
+	write("touched.go", "package a\n")
+	write("removed.go", "package a\n")
+	write("ignored.tmp", "scratch\n")
+
+	cfg := DefaultWatcherConfig()
+	cfg.Poll = true
+	cfg.PollInterval = 20 * time.Millisecond
+	watcher, err := NewWatcher(root, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches := make(chan []WatchEvent, 16)
+	watcher.SetCallback(func(events []WatchEvent) { batches <- events })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := watcher.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Stop()
+	if mode := watcher.Mode(); !strings.HasPrefix(mode, "polling every 20ms") {
+		t.Fatalf("Mode() = %q, want polling", mode)
+	}
+
+	write("edited.go", "package a\n\nfunc Edited() {}\n")
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(root, "touched.go"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "removed.go")); err != nil {
+		t.Fatal(err)
+	}
+	write("created.go", "package a\n")
+	write("ignored.tmp", "changed\n")
+
+	want := map[string]WatchOp{
+		"edited.go":  OpWrite,
+		"removed.go": OpRemove,
+		"created.go": OpCreate,
+	}
+	got := make(map[string]WatchOp)
+	deadline := time.After(5 * time.Second)
+	for len(got) < len(want) {
+		select {
+		case events := <-batches:
+			for _, e := range events {
+				got[filepath.Base(e.Path)] = e.Op
+			}
+		case <-deadline:
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+	for name, op := range want {
+		if got[name] != op {
+			t.Errorf("%s op = %v, want %v", name, got[name], op)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("events = %v, want only %v", got, want)
+	}
+}
+
+func TestPollingWatcherStartFailsOnMissingRoot(t *testing.T) {
+	cfg := DefaultWatcherConfig()
+	cfg.Poll = true
+	watcher, err := NewWatcher(filepath.Join(t.TempDir(), "missing"), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := watcher.Start(context.Background()); err == nil {
+		t.Fatal("Start() with a missing root succeeded")
+	}
+	if err := watcher.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+}
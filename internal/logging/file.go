@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Defaults for OpenFile: a server's log rolls over at 10 MiB and keeps the
+// three previous files, so it never holds more than 40 MiB.
+const (
+	DefaultMaxFileBytes = 10 << 20
+	DefaultFileBackups  = 3
+)
+
+// File is an append-only log file that rolls over by size: once a write
+// would take it past maxBytes, path is renamed to path.1 (shifting older
+// backups up to path.<backups> and dropping the oldest) and a fresh file is
+// opened. It is safe for concurrent use.
+type File struct {
+	path     string
+	maxBytes int64
+	backups  int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// OpenFile opens (creating as needed) the log file at path. Non-positive
+// maxBytes or backups take the defaults.
+func OpenFile(path string, maxBytes int64, backups int) (*File, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxFileBytes
+	}
+	if backups <= 0 {
+		backups = DefaultFileBackups
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create log dir: %w", err)
+	}
+	lf := &File{path: path, maxBytes: maxBytes, backups: backups}
+	if err := lf.open(); err != nil {
+		return nil, err
+	}
+	return lf, nil
+}
+
+// Path returns the path of the current log file.
+func (lf *File) Path() string { return lf.path }
+
+// Write implements io.Writer. A record is never split across files. After
+// Close it drops writes so a late log line cannot fail.
+func (lf *File) Write(p []byte) (int, error) {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	if lf.f == nil {
+		return len(p), nil
+	}
+	if lf.size > 0 && lf.size+int64(len(p)) > lf.maxBytes {
+		if err := lf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := lf.f.Write(p)
+	lf.size += int64(n)
+	return n, err
+}
+
+// Close closes the file. Subsequent writes are dropped.
+func (lf *File) Close() error {
+	lf.mu.Lock()
+	defer lf.mu.Unlock()
+	if lf.f == nil {
+		return nil
+	}
+	err := lf.f.Close()
+	lf.f = nil
+	return err
+}
+
+// rotate shifts the backups up by one and reopens path. Callers hold mu.
+func (lf *File) rotate() error {
+	if err := lf.f.Close(); err != nil {
+		lf.f = nil
+		return err
+	}
+	_ = os.Remove(lf.backup(lf.backups))
+	for i := lf.backups - 1; i >= 1; i-- {
+		_ = os.Rename(lf.backup(i), lf.backup(i+1))
+	}
+	if err := os.Rename(lf.path, lf.backup(1)); err != nil {
+		// Keep logging to the original file rather than losing lines.
+		_ = lf.open()
+		return err
+	}
+	return lf.open()
+}
+
+func (lf *File) backup(n int) string {
+	return fmt.Sprintf("%s.%d", lf.path, n)
+}
+
+func (lf *File) open() error {
+	f, err := os.OpenFile(lf.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		lf.f = nil
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		lf.f = nil
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	lf.f, lf.size = f, info.Size()
+	return nil
+}
@@ -0,0 +1,109 @@
+// Package logging configures vecgrep's process-wide structured logger. Every
+// package logs through log/slog's default logger; the CLI picks the level and
+// format once with Setup, and long-running servers add a log file with
+// SetOutput. The standard log package is routed to the same handler, so
+// stray log.Printf lines keep the chosen format.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Output formats accepted by Setup.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+var (
+	mu     sync.Mutex
+	level  = new(slog.LevelVar)
+	format = FormatText
+	out    = io.Writer(os.Stderr)
+)
+
+// ParseLevel parses a level name: debug, info, warn (or warning), or error.
+// An empty name is info.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (use debug, info, warn, or error)", name)
+}
+
+// ParseFormat validates a format name: text or json. An empty name is text.
+func ParseFormat(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("unknown log format %q (use text or json)", name)
+}
+
+// NewHandler returns a handler writing records at or above leveler to w in
+// the given format.
+func NewHandler(w io.Writer, leveler slog.Leveler, format string) slog.Handler {
+	opts := &slog.HandlerOptions{Level: leveler}
+	if format == FormatJSON {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// Setup installs the default logger at the named level and format, writing
+// to w (stderr when nil).
+func Setup(w io.Writer, levelName, formatName string) error {
+	lvl, err := ParseLevel(levelName)
+	if err != nil {
+		return err
+	}
+	f, err := ParseFormat(formatName)
+	if err != nil {
+		return err
+	}
+	if w == nil {
+		w = os.Stderr
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	level.Set(lvl)
+	format, out = f, w
+	install()
+	return nil
+}
+
+// SetOutput points the default logger at w, keeping the level and format
+// chosen by Setup.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+	install()
+}
+
+// Output returns the writer the default logger writes to.
+func Output() io.Writer {
+	mu.Lock()
+	defer mu.Unlock()
+	return out
+}
+
+// install replaces slog's default logger; slog.SetDefault also sends the
+// standard log package through it. Callers hold mu.
+func install() {
+	slog.SetDefault(slog.New(NewHandler(out, level, format)))
+}
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetupWritesJSONAtTheChosenLevel(t *testing.T) {
+	prev := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	var buf bytes.Buffer
+	if err := Setup(&buf, "warn", "json"); err != nil {
+		t.Fatal(err)
+	}
+	slog.Info("dropped")
+	slog.Warn("kept", "path", "a.go")
+	log.Printf("std log line") // routed through the handler at info, so dropped
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("got %d lines, want 1:\n%s", len(lines), buf.String())
+	}
+	var rec map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &rec); err != nil {
+		t.Fatalf("not JSON: %v", err)
+	}
+	if rec["level"] != "WARN" || rec["msg"] != "kept" || rec["path"] != "a.go" {
+		t.Fatalf("record = %v", rec)
+	}
+
+	if err := Setup(&buf, "loud", "text"); err == nil {
+		t.Fatal("Setup accepted an unknown level")
+	}
+	if err := Setup(&buf, "info", "xml"); err == nil {
+		t.Fatal("Setup accepted an unknown format")
+	}
+}
+
+func TestFileRollsOverBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "serve.log")
+	lf, err := OpenFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lf.Close()
+	for _, line := range []string{"one\n", "two\n", "three\n", "six\n", "seven890\n", "end\n"} {
+		if _, err := lf.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read := func(p string) string {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return "<missing>"
+		}
+		return string(data)
+	}
+	// Each file holds what fits in 10 bytes; the third rollover drops the
+	// oldest backup, "one two".
+	for p, want := range map[string]string{
+		path:        "end\n",
+		path + ".1": "seven890\n",
+		path + ".2": "three\nsix\n",
+		path + ".3": "<missing>",
+	} {
+		if got := read(p); got != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(p), got, want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package mcp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AuditLog appends one JSON line per tool call (and per HTTP API request)
+// to a file: who called, what they asked, and how many results they got.
+// The file is only ever appended to, so it can be shipped or rotated by
+// external tooling while the server runs.
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// AuditEntry is one line of the audit log.
+type AuditEntry struct {
+	Time time.Time `json:"time"`
+	// Client identifies the caller by a fingerprint of its bearer token or
+	// auth cookie ("sha256:<12 hex>"); the token itself is never written.
+	Client       string `json:"client,omitempty"`
+	ClientName   string `json:"client_name,omitempty"`
+	Session      string `json:"session,omitempty"`
+	Remote       string `json:"remote,omitempty"`
+	ForwardedFor string `json:"forwarded_for,omitempty"`
+	UserAgent    string `json:"user_agent,omitempty"`
+	// Tool is the MCP tool name, "suggest" for SuggestPath, "link" for
+	// DeepLinkPath, or "context" for ContextPath.
+	Tool    string         `json:"tool"`
+	Query   string         `json:"query,omitempty"`
+	Queries []string       `json:"queries,omitempty"`
+	Filters map[string]any `json:"filters,omitempty"`
+	// Results is the number of hits returned by search-type tools; other
+	// tools leave it out.
+	Results *int `json:"results,omitempty"`
+	// NotModified marks an HTTP request answered 304 from its cache
+	// validators without running the query.
+	NotModified bool  `json:"not_modified,omitempty"`
+	Error       bool  `json:"error,omitempty"`
+	DurationMS  int64 `json:"duration_ms"`
+}
+
+// OpenAuditLog opens path for appending, creating it readable by its owner
+// only.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	return &AuditLog{file: file}, nil
+}
+
+// Close closes the audit log file.
+func (a *AuditLog) Close() error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
+
+// Write appends entry as one line. Each line is a single write, so entries
+// from concurrent requests never interleave.
+func (a *AuditLog) Write(entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err = a.file.Write(data)
+	return err
+}
+
+// auditEntryKey carries the in-flight *AuditEntry through a tool call so
+// handlers can record their result count.
+type auditEntryKey struct{}
+
+// auditNotModified records that an HTTP request was answered 304. It is a
+// no-op when the request is not being audited.
+func auditNotModified(ctx context.Context) {
+	if entry, ok := ctx.Value(auditEntryKey{}).(*AuditEntry); ok {
+		entry.NotModified = true
+	}
+}
+
+// auditResults records the number of results a search-type tool returned.
+// It is a no-op when the call is not being audited.
+func auditResults(ctx context.Context, n int) {
+	if entry, ok := ctx.Value(auditEntryKey{}).(*AuditEntry); ok {
+		entry.Results = &n
+	}
+}
+
+// auditMiddleware writes an audit entry for every tools/call request.
+func (s *SDKServer) auditMiddleware(next sdkmcp.MethodHandler) sdkmcp.MethodHandler {
+	return func(ctx context.Context, method string, req sdkmcp.Request) (sdkmcp.Result, error) {
+		call, ok := req.(*sdkmcp.CallToolRequest)
+		if method != "tools/call" || !ok || call.Params == nil {
+			return next(ctx, method, req)
+		}
+		entry := &AuditEntry{Time: time.Now().UTC(), Tool: call.Params.Name}
+		if call.Session != nil {
+			entry.Session = call.Session.ID()
+			if params := call.Session.InitializeParams(); params != nil && params.ClientInfo != nil {
+				entry.ClientName = params.ClientInfo.Name
+			}
+		}
+		if extra := call.GetExtra(); extra != nil {
+			auditCaller(entry, extra.Header)
+		}
+		auditArguments(entry, call.Params.Arguments)
+
+		result, err := next(context.WithValue(ctx, auditEntryKey{}, entry), method, req)
+		entry.DurationMS = time.Since(entry.Time).Milliseconds()
+		if res, ok := result.(*sdkmcp.CallToolResult); err != nil || (ok && res != nil && res.IsError) {
+			entry.Error = true
+		}
+		if writeErr := s.audit.Write(*entry); writeErr != nil {
+			slog.Error("audit log write failed", "err", writeErr)
+		}
+		return result, err
+	}
+}
+
+// auditCaller fills the caller fields of entry from request headers.
+func auditCaller(entry *AuditEntry, header http.Header) {
+	if header == nil {
+		return
+	}
+	if token := requestToken(header); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		entry.Client = "sha256:" + hex.EncodeToString(sum[:])[:12]
+	}
+	entry.ForwardedFor = header.Get("X-Forwarded-For")
+	entry.UserAgent = header.Get("User-Agent")
+}
+
+// auditArguments splits tool arguments into the query text and the
+// remaining arguments, which are the filters.
+func auditArguments(entry *AuditEntry, raw json.RawMessage) {
+	var args map[string]any
+	if len(raw) == 0 || json.Unmarshal(raw, &args) != nil {
+		return
+	}
+	if query, ok := args["query"].(string); ok {
+		entry.Query = query
+		delete(args, "query")
+	}
+	if queries, ok := args["queries"].([]any); ok {
+		for _, q := range queries {
+			if text, ok := q.(string); ok {
+				entry.Queries = append(entry.Queries, text)
+			}
+		}
+		delete(args, "queries")
+	}
+	if len(args) > 0 {
+		entry.Filters = args
+	}
+}
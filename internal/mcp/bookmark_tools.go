@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleBookmark handles the vecgrep_bookmark tool.
+func (s *SDKServer) handleBookmark(ctx context.Context, req *sdkmcp.CallToolRequest, input BookmarkInput) (*sdkmcp.CallToolResult, any, error) {
+	if err := s.ensureInitialized(ctx); err != nil {
+		return bookmarkError(err.Error()), nil, nil
+	}
+	state, stateErr := s.acquireProjectOperationSnapshot()
+	if stateErr != nil {
+		return bookmarkError(fmt.Sprintf("Failed to capture project session: %v", stateErr)), nil, nil
+	}
+	defer state.release()
+	s.observeStateSnapshot("bookmark", state.projectStateSnapshot)
+	dataDir := state.cfg.DataDir
+
+	var sb strings.Builder
+	switch strings.ToLower(strings.TrimSpace(input.Action)) {
+	case "add":
+		if input.Key == "" {
+			return bookmarkError("Error: 'key' parameter is required for add."), nil, nil
+		}
+		b, err := app.AddBookmark(dataDir, app.BookmarkInput{Key: input.Key, Note: input.Note, Tags: input.Tags})
+		if err != nil {
+			return bookmarkError(fmt.Sprintf("Failed to add bookmark: %v", err)), nil, nil
+		}
+		fmt.Fprintf(&sb, "Bookmarked %s (ID: %d)\n", b.Key, b.ID)
+		writeBookmarkDetails(&sb, b)
+	case "list":
+		bookmarks, err := app.ListBookmarks(dataDir, input.Tags)
+		if err != nil {
+			return bookmarkError(fmt.Sprintf("Failed to list bookmarks: %v", err)), nil, nil
+		}
+		if len(bookmarks) == 0 {
+			sb.WriteString("No bookmarks.\n")
+			break
+		}
+		fmt.Fprintf(&sb, "Found %d bookmarks:\n", len(bookmarks))
+		for _, b := range bookmarks {
+			fmt.Fprintf(&sb, "\n%d. %s\n", b.ID, b.Key)
+			writeBookmarkDetails(&sb, b)
+		}
+	case "remove":
+		if input.ID == 0 {
+			return bookmarkError("Error: 'id' parameter is required for remove."), nil, nil
+		}
+		removed, err := app.RemoveBookmark(dataDir, input.ID)
+		if err != nil {
+			return bookmarkError(fmt.Sprintf("Failed to remove bookmark: %v", err)), nil, nil
+		}
+		if !removed {
+			return bookmarkError(fmt.Sprintf("Bookmark %d not found.", input.ID)), nil, nil
+		}
+		fmt.Fprintf(&sb, "Removed bookmark %d\n", input.ID)
+	default:
+		return bookmarkError(fmt.Sprintf("Error: unknown action %q (expected add, list, or remove).", input.Action)), nil, nil
+	}
+
+	return &sdkmcp.CallToolResult{
+		Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: sb.String()}},
+	}, nil, nil
+}
+
+func writeBookmarkDetails(sb *strings.Builder, b app.Bookmark) {
+	if b.Note != "" {
+		fmt.Fprintf(sb, "- Note: %s\n", b.Note)
+	}
+	if len(b.Tags) > 0 {
+		fmt.Fprintf(sb, "- Tags: %s\n", strings.Join(b.Tags, ", "))
+	}
+}
+
+func bookmarkError(text string) *sdkmcp.CallToolResult {
+	return &sdkmcp.CallToolResult{
+		Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: text}},
+		IsError: true,
+	}
+}
@@ -147,9 +147,12 @@ type daemonSearchParams struct {
 	MinLine     int      `json:"min_line,omitempty"`
 	MaxLine     int      `json:"max_line,omitempty"`
 	MinScore    float32  `json:"min_score,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	EfSearch    int      `json:"ef_search,omitempty"`
 	Explain     bool     `json:"explain,omitempty"`
 	FilePaths   []string `json:"file_paths,omitempty"`
 	Symbol      string   `json:"symbol,omitempty"`
+	Rerank      *bool    `json:"rerank,omitempty"`
 }
 
 // search sends a daemon.search request and returns the raw JSON result.
@@ -173,6 +176,15 @@ type daemonReindexSyncResult struct {
 	ChunksCreated  int           `json:"chunks_created"`
 	Duration       time.Duration `json:"duration"`
 	Errors         []string      `json:"errors"`
+
+	// Chunks fitted to the model's input limit or matched by the secret
+	// scanner; omitted when zero.
+	ChunksTruncated   int `json:"chunks_truncated,omitempty"`
+	ChunksSplit       int `json:"chunks_split,omitempty"`
+	ChunksWithSecrets int `json:"chunks_with_secrets,omitempty"`
+	// TokensEstimated marks truncation and split counts based on
+	// estimated token counts.
+	TokensEstimated bool `json:"tokens_estimated,omitempty"`
 }
 
 // reindexSync waits for daemon.reindex_sync and decodes the complete index
@@ -203,6 +215,11 @@ func (c *daemonClient) reindexSync(ctx context.Context, full bool, structuralMod
 		FilesDeleted:   wire.FilesDeleted,
 		ChunksCreated:  wire.ChunksCreated,
 		Duration:       wire.Duration,
+
+		ChunksTruncated:   wire.ChunksTruncated,
+		ChunksSplit:       wire.ChunksSplit,
+		TokensEstimated:   wire.TokensEstimated,
+		ChunksWithSecrets: wire.ChunksWithSecrets,
 	}
 	for _, message := range wire.Errors {
 		result.Errors = append(result.Errors, errors.New(message))
@@ -258,7 +258,7 @@ func TestFormatDaemonSearchResult(t *testing.T) {
 		"mode":    "hybrid",
 	})
 
-	text := formatDaemonSearchResult(resultJSON, "")
+	text := formatDaemonSearchResult(resultJSON, "", DefaultMaxResponseBytes)
 	if text == "" {
 		t.Fatal("formatDaemonSearchResult should return non-empty text")
 	}
@@ -274,12 +274,30 @@ func TestFormatDaemonSearchResultWithScopeNote(t *testing.T) {
 		"mode":    "hybrid",
 	})
 
-	text := formatDaemonSearchResult(resultJSON, "Scoped: 5 files in blast radius")
+	text := formatDaemonSearchResult(resultJSON, "Scoped: 5 files in blast radius", DefaultMaxResponseBytes)
 	if !contains(text, "Scoped:") {
 		t.Fatal("scope note should appear in output")
 	}
 }
 
+func TestFormatDaemonSearchResultAsCitations(t *testing.T) {
+	resultJSON, _ := json.Marshal(map[string]any{
+		"results": []map[string]any{
+			{"relative_path": "search.go", "start_line": 10, "end_line": 20, "score": 0.9, "content": "func Search() {}"},
+			{"relative_path": "search.go", "start_line": 21, "end_line": 30, "score": 0.7, "content": "func helper() {}"},
+		},
+		"mode": "hybrid",
+	})
+
+	text, citations, _ := formatDaemonSearchResultAs(resultJSON, "", DefaultMaxResponseBytes, searchFormatCitations)
+	if len(citations) != 1 || citations[0].Citation != "search.go:L10-L30" {
+		t.Fatalf("citations = %+v, want adjacent hits merged into search.go:L10-L30", citations)
+	}
+	if !contains(text, "search.go:L10-L30") || contains(text, "func Search") {
+		t.Fatalf("citations text should list locations without snippets, got: %s", text)
+	}
+}
+
 func TestFormatStatsResult(t *testing.T) {
 	statsJSON, _ := json.Marshal(map[string]any{
 		"total_files":  10,
@@ -287,7 +305,9 @@ func TestFormatStatsResult(t *testing.T) {
 		"languages": map[string]int64{
 			"go": 7,
 		},
-		"backend": "veclite",
+		"language_lines": map[string]int64{"go": 420},
+		"language_bytes": map[string]int64{"go": 2048},
+		"backend":        "veclite",
 		"freshness": map[string]any{
 			"state":               "unknown",
 			"reason":              "structural_manifest_mismatch",
@@ -317,6 +337,9 @@ func TestFormatStatsResult(t *testing.T) {
 	if !contains(text, "go: 7") || contains(text, "stats parse error") {
 		t.Fatalf("heterogeneous stats should parse, got: %s", text)
 	}
+	if !contains(text, "go: 7 chunks, 420 lines, 2.0 KiB") {
+		t.Fatalf("expected per-language volume in output, got: %s", text)
+	}
 	if !contains(text, "Freshness: unknown (structural_manifest_mismatch)") || !contains(text, "force:true") {
 		t.Fatalf("daemon MCP status omitted conservative freshness: %s", text)
 	}
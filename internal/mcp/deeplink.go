@@ -0,0 +1,530 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// deepLinkParams are the query parameters a search link accepts: q for the
+// query, chunk for a result anchor, and the vecgrep_search arguments that
+// decide which results come back, under their argument names. Presentation
+// arguments (explain, format) are left out so equal searches share a link.
+var deepLinkParams = []string{
+	"q", "chunk", "mode", "limit", "language", "languages", "chunk_type", "chunk_types",
+	"file_pattern", "directory", "file_paths", "symbol", "min_line", "max_line",
+	"min_score", "tags", "expand", "rerank", "ref", "context_lines", "max_snippet_lines",
+}
+
+// deepLinkResponse is the JSON body of a DeepLinkPath response.
+type deepLinkResponse struct {
+	// URL is the canonical link of the search, relative to the server.
+	URL string `json:"url"`
+	// Bookmark holds the search's exact options as vecgrep_search
+	// arguments; POSTing it back to DeepLinkPath reruns the search.
+	Bookmark SearchInput      `json:"bookmark"`
+	Results  []deepLinkResult `json:"results"`
+	// Chunk is the chunk named by the link's chunk anchor, when it has one.
+	Chunk    *deepLinkChunk `json:"chunk,omitempty"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// deepLinkResult is a search result with its anchor and link.
+type deepLinkResult struct {
+	search.Result
+	Anchor string `json:"anchor,omitempty"`
+	URL    string `json:"url,omitempty"`
+}
+
+// deepLinkChunk is the chunk a link's anchor resolved to.
+type deepLinkChunk struct {
+	deepLinkResult
+	// Rank is the chunk's 1-based position in Results, or 0 when the
+	// search no longer returns it.
+	Rank int `json:"rank"`
+	// Exact is false when no chunk spans the anchor's lines any more, as
+	// after an edit, and the chunk now holding its first line is returned.
+	Exact bool `json:"exact"`
+}
+
+// serveDeepLink answers GET DeepLinkPath?q=<query>[&option=...][&chunk=<anchor>]
+// by running the search and returning its canonical URL, a bookmark of its
+// options, and each result with a link to it. A POST with a bookmark as its
+// body reruns that search. The chunk anchor, path:Lstart-Lend, names one
+// result; it is resolved against the index even when the search no longer
+// ranks the chunk, so a link shared in chat keeps pointing at the same code.
+// A GET whose Accept header prefers HTML, as a browser's does, gets the
+// results as a page that focuses the linked chunk; API clients get JSON.
+func (s *SDKServer) serveDeepLink(w http.ResponseWriter, r *http.Request) {
+	var (
+		input SearchInput
+		err   error
+	)
+	chunk := r.URL.Query().Get("chunk")
+	switch r.Method {
+	case http.MethodGet:
+		input, err = parseDeepLink(r.URL.Query())
+	case http.MethodPost:
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err = decoder.Decode(&input); err != nil {
+			err = fmt.Errorf("invalid bookmark: %w", err)
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err == nil {
+		input, err = canonicalBookmark(input)
+	}
+	if err == nil && chunk != "" {
+		_, _, _, err = parseChunkAnchor(chunk)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	raw, _ := json.Marshal(input)
+	var filters map[string]any
+	_ = json.Unmarshal(raw, &filters)
+	delete(filters, "query")
+	if chunk != "" {
+		filters["chunk"] = chunk
+	}
+	if len(filters) == 0 {
+		filters = nil
+	}
+	r, done := s.auditHTTP(r, "link", input.Query, filters)
+	defer done()
+
+	state, err := s.acquireResourceSnapshot(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer state.release()
+	readiness, err := serviceFromRead(state).Readiness(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("compute index readiness: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if readiness.BlocksSearch() {
+		http.Error(w, fmt.Sprintf("index is not searchable (%s): %s", readiness.State, readiness.JSON()), http.StatusServiceUnavailable)
+		return
+	}
+
+	// A GET names the whole search in its URL; a POST carries it in the
+	// body, so only GETs are keyed for revalidation. Pages are not, so
+	// they never share an ETag with the JSON of the same URL.
+	w.Header().Set("Vary", "Accept")
+	page := r.Method == http.MethodGet && prefersHTML(r.Header.Get("Accept"))
+	validators, cacheable := state.indexValidators(r)
+	cacheable = cacheable && r.Method == http.MethodGet && !page
+	if cacheable && validators.notModified(w, r) {
+		return
+	}
+	resp, err := state.deepLinkSearch(r.Context(), input, chunk)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	auditResults(r.Context(), len(resp.Results))
+	if page {
+		w.Header().Set("Cache-Control", "no-store")
+		renderPage(w, searchTemplate, newSearchPage(state.projectName, resp, chunk))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !cacheable {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// prefersHTML reports whether an Accept header ranks text/html above
+// application/json. Browsers list text/html first; API clients send JSON,
+// */*, or no Accept header at all.
+func prefersHTML(accept string) bool {
+	var htmlQ, jsonQ float64
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case "text/html":
+			htmlQ = max(htmlQ, q)
+		case "application/json":
+			jsonQ = max(jsonQ, q)
+		}
+	}
+	return htmlQ > jsonQ
+}
+
+// newSearchPage lays out a deep link response as a page, marking the chunk
+// the anchor resolved to. A linked chunk the search no longer ranks is shown
+// above the results. The page's form keeps the search's options, so a new
+// query runs with them.
+func newSearchPage(project string, resp *deepLinkResponse, chunk string) searchPage {
+	page := searchPage{
+		Title:    resp.Bookmark.Query,
+		Project:  project,
+		Query:    resp.Bookmark.Query,
+		URL:      resp.URL,
+		Warnings: resp.Warnings,
+		Results:  make([]searchPageResult, len(resp.Results)),
+	}
+	options, _ := url.ParseQuery(strings.TrimPrefix(resp.URL, DeepLinkPath+"?"))
+	for _, name := range slices.Sorted(maps.Keys(options)) {
+		if name == "q" {
+			continue
+		}
+		for _, value := range options[name] {
+			page.Options = append(page.Options, searchPageOption{Name: name, Value: value})
+		}
+	}
+	for i, result := range resp.Results {
+		page.Results[i] = searchPageResult{deepLinkResult: result, Rank: i + 1, Lines: snippetLines(result.Result)}
+	}
+	if resp.Chunk == nil {
+		return page
+	}
+	if !resp.Chunk.Exact {
+		page.ChunkNote = fmt.Sprintf("No chunk spans %s any more; this is the chunk now holding its first line.", chunk)
+	}
+	if resp.Chunk.Rank > 0 {
+		page.Results[resp.Chunk.Rank-1].Linked = true
+		if page.ChunkNote != "" {
+			page.Warnings = append(page.Warnings, page.ChunkNote)
+		}
+		return page
+	}
+	page.Chunk = &searchPageResult{deepLinkResult: resp.Chunk.deepLinkResult, Linked: true, Lines: snippetLines(resp.Chunk.Result)}
+	return page
+}
+
+// httpSearch runs a search for the plain HTTP API the way vecgrep_search
+// runs it locally, returning its results, the options it ran with, and its
+// warnings. The limit is clamped to maxHTTPSearchLimit and the results are
+// capped at server.max_response_bytes, lowest-scored first, with a warning
+// for each.
+func (state projectReadSnapshot) httpSearch(ctx context.Context, input SearchInput) ([]search.Result, search.SearchOptions, []string, error) {
+	var clamped []string
+	if input.Limit > maxHTTPSearchLimit {
+		clamped = append(clamped, fmt.Sprintf("limit %d exceeds the maximum of %d; returning at most %d results", input.Limit, maxHTTPSearchLimit, maxHTTPSearchLimit))
+		input.Limit = maxHTTPSearchLimit
+	}
+	opts, rootLabels, _, err := state.searchOptions(ctx, input)
+	if err != nil {
+		return nil, opts, nil, err
+	}
+	// The helpers write their warnings as markdown quotes for the tool's
+	// text output; they are returned as plain warnings here.
+	var notes strings.Builder
+	query, opts := state.translateWithService(ctx, input.Query, opts, &notes)
+	outcome, err := state.searcher.SearchWithOutcome(ctx, query, opts)
+	if err != nil {
+		return nil, opts, nil, fmt.Errorf("search: %w", err)
+	}
+	results := outcome.Results
+	search.LabelRoots(results, rootLabels)
+	results = state.rerankWithService(ctx, query, input.Rerank, results, &notes)
+	results = state.applySearchFeedback(input.Query, results, &notes)
+	search.ExpandResults(opts.ProjectRoot, results, input.ContextLines, input.ContextLines)
+	search.TruncateResults(results, query, input.MaxSnippetLines)
+	state.rerankWithCodemap(ctx, results, state.codemapStructuralWeight())
+	results, truncated := capHTTPResults(results, maxResponseBytes(state.cfg))
+	warnings := append(clamped, outcome.Warnings...)
+	warnings = append(warnings, markdownNotes(notes.String())...)
+	return results, opts, append(warnings, truncated...), nil
+}
+
+// deepLinkSearch runs a bookmarked search and resolves the chunk anchor, if
+// any.
+func (state projectReadSnapshot) deepLinkSearch(ctx context.Context, input SearchInput, chunk string) (*deepLinkResponse, error) {
+	results, opts, warnings, err := state.httpSearch(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &deepLinkResponse{
+		URL:      deepLinkURL(input, ""),
+		Bookmark: input,
+		Results:  make([]deepLinkResult, len(results)),
+		Warnings: warnings,
+	}
+	for i, result := range results {
+		resp.Results[i] = deepLinkResult{Result: result}
+		if !result.Annotation {
+			resp.Results[i].Anchor = chunkAnchor(result.RelativePath, result.StartLine, result.EndLine)
+			resp.Results[i].URL = deepLinkURL(input, resp.Results[i].Anchor)
+		}
+	}
+	if chunk == "" {
+		return resp, nil
+	}
+
+	roots := append([]string{opts.ProjectRoot}, opts.ProjectRoots...)
+	record, exact, err := resolveChunkAnchor(state.database, chunk, roots)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		resp.Warnings = append(resp.Warnings, fmt.Sprintf("chunk %s is no longer indexed", chunk))
+		return resp, nil
+	}
+	anchor := chunkAnchor(record.RelativePath, record.StartLine, record.EndLine)
+	for i, result := range resp.Results {
+		if result.Anchor == anchor {
+			resp.Chunk = &deepLinkChunk{deepLinkResult: result, Rank: i + 1, Exact: exact}
+			return resp, nil
+		}
+	}
+	resp.Chunk = &deepLinkChunk{
+		deepLinkResult: deepLinkResult{
+			Result: search.Result{
+				ChunkID:      int64(record.ID),
+				FilePath:     record.FilePath,
+				RelativePath: record.RelativePath,
+				Content:      record.Content,
+				StartLine:    record.StartLine,
+				EndLine:      record.EndLine,
+				ChunkType:    record.ChunkType,
+				SymbolName:   record.SymbolName,
+				Language:     record.Language,
+			},
+			Anchor: anchor,
+			URL:    deepLinkURL(input, anchor),
+		},
+		Exact: exact,
+	}
+	return resp, nil
+}
+
+// markdownNotes turns the "> **Warning:** ..." quotes the search helpers
+// write into plain strings.
+func markdownNotes(text string) []string {
+	var notes []string
+	for _, line := range strings.Split(text, "\n") {
+		if note, ok := strings.CutPrefix(line, "> "); ok {
+			notes = append(notes, strings.TrimPrefix(note, "**Warning:** "))
+		}
+	}
+	return notes
+}
+
+// parseDeepLink reads a search link's query parameters into the
+// vecgrep_search arguments they name. List arguments repeat their parameter.
+func parseDeepLink(values url.Values) (SearchInput, error) {
+	var input SearchInput
+	for key := range values {
+		if !slices.Contains(deepLinkParams, key) {
+			return input, fmt.Errorf("unknown parameter %q", key)
+		}
+	}
+	var err error
+	integer := func(key string) int {
+		raw := values.Get(key)
+		if raw == "" || err != nil {
+			return 0
+		}
+		n, convErr := strconv.Atoi(raw)
+		if convErr != nil || n < 0 {
+			err = fmt.Errorf("%s must be a non-negative integer", key)
+		}
+		return n
+	}
+	boolean := func(key string) *bool {
+		raw := values.Get(key)
+		if raw == "" || err != nil {
+			return nil
+		}
+		b, convErr := strconv.ParseBool(raw)
+		if convErr != nil {
+			err = fmt.Errorf("%s must be true or false", key)
+		}
+		return &b
+	}
+
+	input.Query = values.Get("q")
+	input.Mode = values.Get("mode")
+	input.Language = values.Get("language")
+	input.Languages = values["languages"]
+	input.ChunkType = values.Get("chunk_type")
+	input.ChunkTypes = values["chunk_types"]
+	input.FilePattern = values.Get("file_pattern")
+	input.Directory = values.Get("directory")
+	input.FilePaths = values["file_paths"]
+	input.Tags = values["tags"]
+	input.Symbol = values.Get("symbol")
+	input.Ref = values.Get("ref")
+	input.Limit = integer("limit")
+	input.MinLine = integer("min_line")
+	input.MaxLine = integer("max_line")
+	input.ContextLines = integer("context_lines")
+	input.MaxSnippetLines = integer("max_snippet_lines")
+	if expand := boolean("expand"); expand != nil {
+		input.Expand = *expand
+	}
+	input.Rerank = boolean("rerank")
+	if raw := values.Get("min_score"); raw != "" && err == nil {
+		score, convErr := strconv.ParseFloat(raw, 32)
+		if convErr != nil {
+			err = fmt.Errorf("min_score must be a number")
+		}
+		input.MinScore = float32(score)
+	}
+	return input, err
+}
+
+// canonicalBookmark validates a search's options and puts them in one form,
+// so equal searches get equal links: the default mode is left out, lists are
+// sorted without duplicates, and presentation arguments are cleared.
+func canonicalBookmark(input SearchInput) (SearchInput, error) {
+	if strings.TrimSpace(input.Query) == "" {
+		return input, fmt.Errorf("q is required")
+	}
+	input.Mode = strings.ToLower(input.Mode)
+	switch input.Mode {
+	case "hybrid":
+		input.Mode = ""
+	case "", "semantic", "keyword":
+	default:
+		return input, fmt.Errorf("invalid mode %q: want semantic, keyword, or hybrid", input.Mode)
+	}
+	if input.Limit < 0 || input.MinLine < 0 || input.MaxLine < 0 || input.ContextLines < 0 || input.MaxSnippetLines < 0 {
+		return input, fmt.Errorf("limit, line, and snippet options must not be negative")
+	}
+	if input.MinScore < 0 || input.MinScore > 1 {
+		return input, fmt.Errorf("min_score must be between 0 and 1")
+	}
+	for _, list := range []*[]string{&input.Languages, &input.ChunkTypes, &input.FilePaths, &input.Tags} {
+		if len(*list) == 0 {
+			*list = nil
+			continue
+		}
+		*list = slices.Compact(slices.Sorted(slices.Values(*list)))
+	}
+	input.Explain = false
+	input.Format = ""
+	return input, nil
+}
+
+// deepLinkURL returns the canonical link of a search, and of one of its
+// results when anchor is set. url.Values sorts the parameters, so equal
+// bookmarks give equal links.
+func deepLinkURL(input SearchInput, anchor string) string {
+	values := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	setInt := func(key string, n int) {
+		if n != 0 {
+			values.Set(key, strconv.Itoa(n))
+		}
+	}
+	set("q", input.Query)
+	set("chunk", anchor)
+	set("mode", input.Mode)
+	set("language", input.Language)
+	set("chunk_type", input.ChunkType)
+	set("file_pattern", input.FilePattern)
+	set("directory", input.Directory)
+	set("symbol", input.Symbol)
+	set("ref", input.Ref)
+	setInt("limit", input.Limit)
+	setInt("min_line", input.MinLine)
+	setInt("max_line", input.MaxLine)
+	setInt("context_lines", input.ContextLines)
+	setInt("max_snippet_lines", input.MaxSnippetLines)
+	if input.MinScore > 0 {
+		values.Set("min_score", strconv.FormatFloat(float64(input.MinScore), 'f', -1, 32))
+	}
+	if input.Expand {
+		values.Set("expand", "true")
+	}
+	if input.Rerank != nil {
+		values.Set("rerank", strconv.FormatBool(*input.Rerank))
+	}
+	for key, list := range map[string][]string{"languages": input.Languages, "chunk_types": input.ChunkTypes, "file_paths": input.FilePaths, "tags": input.Tags} {
+		if len(list) > 0 {
+			values[key] = list
+		}
+	}
+	return DeepLinkPath + "?" + values.Encode()
+}
+
+// chunkAnchor names a chunk by its location, path:Lstart-Lend, which
+// survives re-indexing as long as the chunk keeps its lines.
+func chunkAnchor(relPath string, startLine, endLine int) string {
+	return fmt.Sprintf("%s:L%d-L%d", relPath, startLine, endLine)
+}
+
+// parseChunkAnchor splits an anchor made by chunkAnchor.
+func parseChunkAnchor(anchor string) (relPath string, startLine, endLine int, err error) {
+	i := strings.LastIndex(anchor, ":L")
+	if i <= 0 {
+		return "", 0, 0, fmt.Errorf("invalid chunk anchor %q: want path:Lstart-Lend", anchor)
+	}
+	start, end, ok := strings.Cut(anchor[i+2:], "-L")
+	if ok {
+		startLine, err = strconv.Atoi(start)
+		if err == nil {
+			endLine, err = strconv.Atoi(end)
+		}
+	}
+	if !ok || err != nil || startLine <= 0 || endLine < startLine {
+		return "", 0, 0, fmt.Errorf("invalid chunk anchor %q: want path:Lstart-Lend", anchor)
+	}
+	return anchor[:i], startLine, endLine, nil
+}
+
+// resolveChunkAnchor finds the chunk an anchor names among the chunks
+// indexed under roots: the chunk spanning exactly its lines, or else the
+// smallest chunk holding its first line, with exact false. It returns nil
+// when the file has no such chunk.
+func resolveChunkAnchor(database *db.DB, anchor string, roots []string) (*db.ChunkRecord, bool, error) {
+	relPath, startLine, endLine, err := parseChunkAnchor(anchor)
+	if err != nil {
+		return nil, false, err
+	}
+	chunks, err := database.GetChunksByFile(relPath)
+	if err != nil {
+		return nil, false, fmt.Errorf("resolve chunk %s: %w", anchor, err)
+	}
+	var best *db.ChunkRecord
+	for i := range chunks {
+		c := &chunks[i]
+		if c.RelativePath != relPath || (c.ProjectRoot != "" && !slices.Contains(roots, c.ProjectRoot)) {
+			continue
+		}
+		if c.StartLine == startLine && c.EndLine == endLine {
+			return c, true, nil
+		}
+		if c.StartLine <= startLine && startLine <= c.EndLine && (best == nil || c.EndLine-c.StartLine < best.EndLine-best.StartLine) {
+			best = c
+		}
+	}
+	return best, false, nil
+}
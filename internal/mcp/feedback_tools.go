@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// handleFeedback handles the vecgrep_feedback tool.
+func (s *SDKServer) handleFeedback(ctx context.Context, req *sdkmcp.CallToolRequest, input FeedbackInput) (*sdkmcp.CallToolResult, any, error) {
+	if err := s.ensureInitialized(ctx); err != nil {
+		return feedbackError(err.Error()), nil, nil
+	}
+
+	var sb strings.Builder
+	switch action := strings.ToLower(strings.TrimSpace(input.Action)); action {
+	case "", "record":
+		if input.Relevant == nil {
+			return feedbackError("Error: 'relevant' parameter is required for record."), nil, nil
+		}
+		target := input.Key
+		if input.ChunkID > 0 {
+			target = strconv.FormatInt(input.ChunkID, 10)
+		}
+		if target == "" {
+			return feedbackError("Error: 'chunk_id' or 'key' parameter is required for record."), nil, nil
+		}
+		// The judgment keeps the chunk text, so recording reads the index.
+		readState, err := s.acquireProjectReadSnapshot(ctx)
+		if err != nil {
+			return feedbackError(fmt.Sprintf("Failed to open database: %v", err)), nil, nil
+		}
+		defer readState.release()
+		s.observeStateSnapshot("feedback", readState.projectStateSnapshot)
+
+		chunk, err := app.FeedbackChunk(readState.database, readState.projectRoot, target)
+		if err != nil {
+			return feedbackError(fmt.Sprintf("Failed to record feedback: %v", err)), nil, nil
+		}
+		j, err := app.RecordFeedback(readState.cfg.DataDir, app.JudgmentInput{
+			Query:    input.Query,
+			Key:      app.ChunkKey(chunk.RelativePath, chunk.StartLine, chunk.EndLine),
+			Relevant: *input.Relevant,
+			Language: chunk.Language,
+			Content:  chunk.Content,
+		})
+		if err != nil {
+			return feedbackError(fmt.Sprintf("Failed to record feedback: %v", err)), nil, nil
+		}
+		fmt.Fprintf(&sb, "Marked %s %s for %q (ID: %d)\n", j.Key, feedbackLabel(j.Relevant), j.Query, j.ID)
+	case "list", "export":
+		state, stateErr := s.acquireProjectOperationSnapshot()
+		if stateErr != nil {
+			return feedbackError(fmt.Sprintf("Failed to capture project session: %v", stateErr)), nil, nil
+		}
+		defer state.release()
+		s.observeStateSnapshot("feedback", state.projectStateSnapshot)
+		dataDir := state.cfg.DataDir
+
+		if action == "export" {
+			dataset, err := app.FeedbackDataset(dataDir)
+			if err != nil {
+				return feedbackError(fmt.Sprintf("Failed to export feedback: %v", err)), nil, nil
+			}
+			data, err := json.MarshalIndent(dataset, "", "  ")
+			if err != nil {
+				return feedbackError(fmt.Sprintf("Failed to encode dataset: %v", err)), nil, nil
+			}
+			sb.Write(data)
+			sb.WriteString("\n")
+			break
+		}
+		judgments, err := app.ListFeedback(dataDir, input.Query)
+		if err != nil {
+			return feedbackError(fmt.Sprintf("Failed to list feedback: %v", err)), nil, nil
+		}
+		if len(judgments) == 0 {
+			sb.WriteString("No feedback.\n")
+			break
+		}
+		fmt.Fprintf(&sb, "Found %d judgments:\n", len(judgments))
+		for _, j := range judgments {
+			fmt.Fprintf(&sb, "\n%d. %q → %s (%s)\n", j.ID, j.Query, j.Key, feedbackLabel(j.Relevant))
+		}
+	default:
+		return feedbackError(fmt.Sprintf("Error: unknown action %q (expected record, list, or export).", input.Action)), nil, nil
+	}
+
+	return &sdkmcp.CallToolResult{
+		Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: sb.String()}},
+	}, nil, nil
+}
+
+func feedbackLabel(relevant bool) string {
+	if relevant {
+		return app.FeedbackRelevant
+	}
+	return app.FeedbackIrrelevant
+}
+
+func feedbackError(text string) *sdkmcp.CallToolResult {
+	return &sdkmcp.CallToolResult{
+		Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: text}},
+		IsError: true,
+	}
+}
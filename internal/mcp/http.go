@@ -0,0 +1,326 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// HTTPPath serves the Streamable HTTP transport (MCP 2025-03-26 and later).
+	HTTPPath = "/mcp"
+	// SSEPath serves the older HTTP+SSE transport for clients that predate
+	// Streamable HTTP.
+	SSEPath = "/sse"
+	// SuggestPath serves typeahead suggestions over indexed symbol names and
+	// file paths (see serveSuggest).
+	SuggestPath = "/api/suggest"
+	// DeepLinkPath serves shareable search links (see serveDeepLink).
+	DeepLinkPath = "/s"
+	// StatusPath serves the vecgrep_status report as JSON (see serveStatus).
+	StatusPath = "/api/status"
+	// LanguagesPath serves per-language index totals (see serveLanguages).
+	LanguagesPath = "/api/languages"
+	// ContextPath serves token-budgeted context blocks (see serveContext).
+	ContextPath = "/api/context"
+	// EmbeddingsPath serves OpenAI-compatible embeddings from the configured
+	// provider (see serveEmbeddings).
+	EmbeddingsPath = "/v1/embeddings"
+	// VectorStoreSearchPath serves OpenAI-compatible vector store searches
+	// (see serveVectorStoreSearch).
+	VectorStoreSearchPath = "/v1/vector_stores/{id}/search"
+	// OpenPath serves editor links for indexed chunks (see serveOpen).
+	OpenPath = "/api/open"
+	// BookmarksAPIPath serves the project's bookmarks (see serveBookmarks).
+	BookmarksAPIPath = "/api/bookmarks"
+
+	// httpSessionTimeout closes HTTP sessions whose client stopped sending
+	// requests without ending the session.
+	httpSessionTimeout = 30 * time.Minute
+	// httpShutdownTimeout bounds how long RunHTTP waits for open requests
+	// after its context is canceled.
+	httpShutdownTimeout = 5 * time.Second
+)
+
+// HTTPHandler returns a handler serving this server over HTTP: Streamable
+// HTTP at HTTPPath, HTTP+SSE at SSEPath, typeahead suggestions at
+// SuggestPath, search links at DeepLinkPath, prompt context at ContextPath,
+// OpenAI-compatible retrieval at VectorStoreSearchPath, editor links at
+// OpenPath, bookmarks at BookmarksAPIPath (read-only servers refuse
+// changes), an HTML file browser at FilesPagePath and SimilarPagePath, index
+// status at StatusPath and LanguagesPath, and,
+// unless the server is ReadOnly, embeddings at EmbeddingsPath and indexing
+// jobs at IndexAPIPath, FilesAPIPath, CleanAPIPath, and ResetAPIPath,
+// polled at JobsPath, with index progress streamed at IndexProgressPath.
+// Every client session shares the same project state, exactly as successive
+// tool calls over stdio do. JSON GET responses carry
+// an ETag, and Last-Modified where the index alone decides them, so polling
+// clients can revalidate with a conditional request (see
+// cacheValidators.notModified).
+// Browser cross-origin requests are rejected, and the SDK refuses requests
+// that reach a loopback listener under a non-loopback Host header, which
+// blocks DNS rebinding. With an AuthToken every request needs it as a bearer
+// token, and with a RateLimit each client address is capped.
+func (s *SDKServer) HTTPHandler() http.Handler {
+	getServer := func(*http.Request) *sdkmcp.Server { return s.server }
+	protection := http.NewCrossOriginProtection()
+	mux := http.NewServeMux()
+	mux.Handle(HTTPPath, protection.Handler(sdkmcp.NewStreamableHTTPHandler(getServer, &sdkmcp.StreamableHTTPOptions{
+		SessionTimeout: httpSessionTimeout,
+	})))
+	mux.Handle(SSEPath, protection.Handler(sdkmcp.NewSSEHandler(getServer, nil)))
+	mux.Handle(SuggestPath, protection.Handler(http.HandlerFunc(s.serveSuggest)))
+	mux.Handle(DeepLinkPath, protection.Handler(http.HandlerFunc(s.serveDeepLink)))
+	mux.Handle(StatusPath, protection.Handler(http.HandlerFunc(s.serveStatus)))
+	mux.Handle(LanguagesPath, protection.Handler(http.HandlerFunc(s.serveLanguages)))
+	mux.Handle(ContextPath, protection.Handler(http.HandlerFunc(s.serveContext)))
+	mux.Handle(VectorStoreSearchPath, protection.Handler(http.HandlerFunc(s.serveVectorStoreSearch)))
+	mux.Handle(OpenPath, protection.Handler(http.HandlerFunc(s.serveOpen)))
+	mux.Handle(BookmarksAPIPath, protection.Handler(http.HandlerFunc(s.serveBookmarks)))
+	mux.Handle(BookmarksAPIPath+"/{id}", protection.Handler(http.HandlerFunc(s.serveBookmarks)))
+	mux.Handle(FilesPagePath, protection.Handler(http.HandlerFunc(s.serveFilesPage)))
+	mux.Handle(FilesPagePath+"/{path...}", protection.Handler(http.HandlerFunc(s.serveFilesPage)))
+	mux.Handle(SimilarPagePath, protection.Handler(http.HandlerFunc(s.serveSimilarPage)))
+	// A read-only server is meant to be shared: the embedding gateway would
+	// let its clients spend the operator's provider quota, and the indexing
+	// API would let them change the index.
+	if !s.readOnly {
+		mux.Handle(EmbeddingsPath, protection.Handler(http.HandlerFunc(s.serveEmbeddings)))
+		if s.jobs == nil {
+			s.jobs = newJobRegistry()
+		}
+		if s.progress == nil {
+			s.progress = newProgressHub()
+		}
+		mux.Handle(IndexAPIPath, protection.Handler(http.HandlerFunc(s.serveIndexAPI)))
+		mux.Handle(IndexProgressPath, protection.Handler(http.HandlerFunc(s.serveIndexProgress)))
+		mux.Handle(FilesAPIPath+"{path...}", protection.Handler(http.HandlerFunc(s.serveDeleteFileAPI)))
+		mux.Handle(CleanAPIPath, protection.Handler(http.HandlerFunc(s.serveCleanAPI)))
+		mux.Handle(ResetAPIPath, protection.Handler(http.HandlerFunc(s.serveResetAPI)))
+		mux.Handle(JobsPath, protection.Handler(http.HandlerFunc(s.serveJobs)))
+		mux.Handle(JobsPath+"/{id}", protection.Handler(http.HandlerFunc(s.serveJob)))
+	}
+
+	// Rate limiting runs first so that it also slows token guessing.
+	var handler http.Handler = mux
+	if s.authToken != "" {
+		handler = requireBearer(s.authToken, handler)
+	}
+	if s.limiter != nil {
+		handler = s.limiter.middleware(handler)
+	}
+	return handler
+}
+
+// suggestResponse is the JSON body of a SuggestPath response.
+type suggestResponse struct {
+	Query       string              `json:"query"`
+	Suggestions []search.Suggestion `json:"suggestions"`
+}
+
+// serveSuggest answers GET SuggestPath?q=<typed text>[&limit=N] with symbol
+// and file-path completions from the index. It never calls the embedding
+// provider, so a client can query it on every keystroke and run the
+// vecgrep_search tool only when the user submits.
+func (s *SDKServer) serveSuggest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query().Get("q")
+	limit := search.DefaultSuggestLimit
+	var filters map[string]any
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+		filters = map[string]any{"limit": limit}
+	}
+	r, done := s.auditHTTP(r, "suggest", query, filters)
+	defer done()
+
+	state, err := s.acquireResourceSnapshot(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer state.release()
+	validators, cacheable := state.indexValidators(r)
+	if cacheable && validators.notModified(w, r) {
+		return
+	}
+	suggestions, err := state.searcher.Suggest(r.Context(), query, state.projectRoot, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	auditResults(r.Context(), len(suggestions))
+
+	w.Header().Set("Content-Type", "application/json")
+	if !cacheable {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	_ = json.NewEncoder(w).Encode(suggestResponse{Query: query, Suggestions: suggestions})
+}
+
+// serveStatus answers GET StatusPath with the structured vecgrep_status
+// report. Freshness and pending changes depend on the working tree as well
+// as the index, so the ETag is a digest of the report rather than of the
+// index generation: a client polling with If-None-Match gets 304 until
+// something it would display changes.
+func (s *SDKServer) serveStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	state, err := s.acquireResourceSnapshot(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer state.release()
+	out, _, err := state.status(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("get index stats: %v", err), http.StatusInternalServerError)
+		return
+	}
+	body, err := json.Marshal(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if bodyValidators(body).notModified(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(append(body, '\n'))
+}
+
+// languagesResponse is the JSON body of a LanguagesPath response.
+type languagesResponse struct {
+	Languages []languageTotals `json:"languages"`
+}
+
+// languageTotals is how much of the index one language accounts for.
+type languageTotals struct {
+	Language string `json:"language"`
+	Chunks   int64  `json:"chunks"`
+	Lines    int64  `json:"lines,omitempty"`
+	Bytes    int64  `json:"bytes,omitempty"`
+}
+
+// serveLanguages answers GET LanguagesPath with the chunks, lines, and
+// bytes indexed per language, most chunks first. The answer depends only on
+// the index, so a conditional request is answered 304 without reading it.
+func (s *SDKServer) serveLanguages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	state, err := s.acquireResourceSnapshot(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer state.release()
+	validators, cacheable := state.indexValidators(r)
+	if cacheable && validators.notModified(w, r) {
+		return
+	}
+	stats, err := state.searcher.GetIndexStats(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("get index stats: %v", err), http.StatusInternalServerError)
+		return
+	}
+	chunks, _ := stats["languages"].(map[string]int64)
+	lines, _ := stats["language_lines"].(map[string]int64)
+	bytes, _ := stats["language_bytes"].(map[string]int64)
+	resp := languagesResponse{Languages: make([]languageTotals, 0, len(chunks))}
+	for lang, n := range chunks {
+		resp.Languages = append(resp.Languages, languageTotals{Language: lang, Chunks: n, Lines: lines[lang], Bytes: bytes[lang]})
+	}
+	sort.Slice(resp.Languages, func(i, j int) bool {
+		if resp.Languages[i].Chunks != resp.Languages[j].Chunks {
+			return resp.Languages[i].Chunks > resp.Languages[j].Chunks
+		}
+		return resp.Languages[i].Language < resp.Languages[j].Language
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if !cacheable {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// auditHTTP starts the audit entry of a plain HTTP request, returning the
+// request carrying it for auditResults and a func that writes it once the
+// response is sent. Without an audit log both are no-ops.
+func (s *SDKServer) auditHTTP(r *http.Request, tool, query string, filters map[string]any) (*http.Request, func()) {
+	if s.audit == nil {
+		return r, func() {}
+	}
+	entry := &AuditEntry{Time: time.Now().UTC(), Tool: tool, Query: query, Filters: filters, Remote: r.RemoteAddr}
+	auditCaller(entry, r.Header)
+	return r.WithContext(context.WithValue(r.Context(), auditEntryKey{}, entry)), func() {
+		entry.DurationMS = time.Since(entry.Time).Milliseconds()
+		entry.Error = entry.Results == nil && !entry.NotModified
+		if err := s.audit.Write(*entry); err != nil {
+			slog.Error("audit log write failed", "err", err)
+		}
+	}
+}
+
+// RunHTTP serves the MCP server on ln until ctx is canceled, then closes the
+// listener, waits briefly for in-flight requests, and releases the project
+// session like Run.
+func (s *SDKServer) RunHTTP(ctx context.Context, ln net.Listener) error {
+	stopEvictor := s.startIdleEvictor(ctx)
+	server := &http.Server{
+		Handler:           s.HTTPHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- server.Serve(ln) }()
+
+	var runErr error
+	select {
+	case runErr = <-serveErr:
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		runErr = server.Shutdown(shutdownCtx)
+		cancel()
+		if errors.Is(runErr, context.DeadlineExceeded) {
+			runErr = server.Close()
+		}
+	}
+	if errors.Is(runErr, http.ErrServerClosed) {
+		runErr = nil
+	}
+	stopEvictor()
+
+	state := s.snapshotProjectState()
+	var closeErr error
+	if state.session != nil {
+		closeErr = state.session.close()
+	}
+	return errors.Join(runErr, closeErr)
+}
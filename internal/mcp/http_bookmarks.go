@@ -0,0 +1,121 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+)
+
+// maxBookmarkRequestBytes caps a POST BookmarksAPIPath body.
+const maxBookmarkRequestBytes = 64 << 10
+
+// bookmarkRequest is the JSON body of a POST to BookmarksAPIPath, as
+// vecgrep_bookmark's add takes it.
+type bookmarkRequest struct {
+	Key  string   `json:"key"`
+	Note string   `json:"note,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// bookmarksResponse is the JSON body of a GET BookmarksAPIPath response.
+type bookmarksResponse struct {
+	Bookmarks []app.Bookmark `json:"bookmarks"`
+}
+
+// serveBookmarks answers BookmarksAPIPath with the project's bookmarks,
+// the same bookmarks.json that vecgrep bookmark and vecgrep_bookmark use:
+// GET lists them, oldest first, keeping those carrying every tag parameter;
+// POST adds a bookmarkRequest (or updates the bookmark of the same key) and
+// answers 201 with it; DELETE BookmarksAPIPath/<id> removes one and answers
+// 204. A read-only server answers writes with 403, as it leaves out the
+// tool.
+func (s *SDKServer) serveBookmarks(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	switch {
+	case r.Method == http.MethodGet && id == "":
+	case r.Method == http.MethodPost && id == "", r.Method == http.MethodDelete && id != "":
+		if s.readOnly {
+			http.Error(w, "bookmarks are read-only on this server", http.StatusForbidden)
+			return
+		}
+	case id != "":
+		w.Header().Set("Allow", http.MethodDelete)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := s.ensureInitialized(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	state, err := s.acquireProjectOperationSnapshot()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer state.release()
+	dataDir := state.cfg.DataDir
+
+	switch r.Method {
+	case http.MethodGet:
+		bookmarks, err := app.ListBookmarks(dataDir, r.URL.Query()["tag"])
+		if err != nil {
+			http.Error(w, fmt.Sprintf("list bookmarks: %v", err), http.StatusInternalServerError)
+			return
+		}
+		writeBookmarkJSON(w, http.StatusOK, bookmarksResponse{Bookmarks: bookmarks})
+	case http.MethodPost:
+		var req bookmarkRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBookmarkRequestBytes))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if strings.TrimSpace(req.Key) == "" {
+			http.Error(w, "key is required", http.StatusBadRequest)
+			return
+		}
+		if _, _, _, err := app.ParseChunkKey(req.Key); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		b, err := app.AddBookmark(dataDir, app.BookmarkInput{Key: req.Key, Note: req.Note, Tags: req.Tags})
+		if err != nil {
+			http.Error(w, fmt.Sprintf("add bookmark: %v", err), http.StatusInternalServerError)
+			return
+		}
+		writeBookmarkJSON(w, http.StatusCreated, b)
+	case http.MethodDelete:
+		n, err := strconv.ParseUint(id, 10, 64)
+		if err != nil || n == 0 {
+			http.Error(w, "bookmark id must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		removed, err := app.RemoveBookmark(dataDir, n)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("remove bookmark: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !removed {
+			http.Error(w, fmt.Sprintf("bookmark %d not found", n), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func writeBookmarkJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// cacheValidators are the ETag and Last-Modified of a JSON API response.
+// A zero lastModified leaves Last-Modified out.
+type cacheValidators struct {
+	etag         string
+	lastModified time.Time
+}
+
+// indexValidators keys a GET response on the index generation the read
+// snapshot was loaded from, the active project, and the request's path and
+// query, so the validators change exactly when reindexing could change the
+// answer. ok is false when the generation is unknown, and the response is
+// then sent without validators.
+func (state projectReadSnapshot) indexValidators(r *http.Request) (v cacheValidators, ok bool) {
+	if state.session == nil {
+		return cacheValidators{}, false
+	}
+	generation, ok := state.session.loadedGeneration()
+	if !ok {
+		return cacheValidators{}, false
+	}
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d\x00%d\x00%s\x00%s?%s", generation.info.ModTime().UnixNano(), generation.info.Size(),
+		state.projectRoot, r.URL.Path, r.URL.Query().Encode())
+	return cacheValidators{etag: fmt.Sprintf(`"%016x"`, h.Sum64()), lastModified: generation.info.ModTime()}, true
+}
+
+// bodyValidators keys a response on its encoded body, for answers that also
+// depend on the working tree and so cannot be keyed on the index alone.
+func bodyValidators(body []byte) cacheValidators {
+	sum := sha256.Sum256(body)
+	return cacheValidators{etag: `"` + hex.EncodeToString(sum[:8]) + `"`}
+}
+
+// notModified sets the validators on w and, when the request's conditional
+// headers show the client already holds this response, answers 304 Not
+// Modified and returns true. If-None-Match takes precedence over
+// If-Modified-Since, as RFC 9110 requires. Cache-Control no-cache lets
+// clients keep the response but makes them revalidate before reusing it.
+func (v cacheValidators) notModified(w http.ResponseWriter, r *http.Request) bool {
+	header := w.Header()
+	header.Set("ETag", v.etag)
+	header.Set("Cache-Control", "no-cache")
+	if !v.lastModified.IsZero() {
+		header.Set("Last-Modified", v.lastModified.UTC().Format(http.TimeFormat))
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	fresh := false
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		fresh = etagListMatches(match, v.etag)
+	} else if since, err := http.ParseTime(r.Header.Get("If-Modified-Since")); err == nil && !v.lastModified.IsZero() {
+		fresh = !v.lastModified.Truncate(time.Second).After(since)
+	}
+	if fresh {
+		auditNotModified(r.Context())
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return fresh
+}
+
+// etagListMatches reports whether an If-None-Match header names etag, using
+// the weak comparison RFC 9110 specifies for it.
+func etagListMatches(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
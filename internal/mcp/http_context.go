@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// contextRequest is the JSON body of a POST to ContextPath: the search as
+// vecgrep_search arguments plus the token budget.
+type contextRequest struct {
+	SearchInput
+	Budget int `json:"budget,omitempty"`
+}
+
+// serveContext answers GET ContextPath?q=<query>[&budget=N][&option=...]
+// with a citation-annotated block of code packed into the token budget (see
+// search.PackContext), for callers that put search results into a language
+// model prompt. The options are the search link's (see deepLinkParams);
+// limit sets how many results are considered and defaults to
+// search.DefaultContextCandidates. A POST carries the same request as a JSON
+// contextRequest.
+func (s *SDKServer) serveContext(w http.ResponseWriter, r *http.Request) {
+	var (
+		req contextRequest
+		err error
+	)
+	switch r.Method {
+	case http.MethodGet:
+		req, err = parseContextRequest(r.URL.Query())
+	case http.MethodPost:
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err = decoder.Decode(&req); err != nil {
+			err = fmt.Errorf("invalid request: %w", err)
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err == nil {
+		req.SearchInput, err = canonicalBookmark(req.SearchInput)
+	}
+	if err == nil && (req.ContextLines != 0 || req.MaxSnippetLines != 0) {
+		err = fmt.Errorf("context_lines and max_snippet_lines do not apply to context assembly")
+	}
+	if err == nil && req.Budget < 0 {
+		err = fmt.Errorf("budget must be a non-negative integer")
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = search.DefaultContextCandidates
+	}
+
+	raw, _ := json.Marshal(req)
+	var filters map[string]any
+	_ = json.Unmarshal(raw, &filters)
+	delete(filters, "query")
+	r, done := s.auditHTTP(r, "context", req.Query, filters)
+	defer done()
+
+	state, err := s.acquireResourceSnapshot(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer state.release()
+	readiness, err := serviceFromRead(state).Readiness(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("compute index readiness: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if readiness.BlocksSearch() {
+		http.Error(w, fmt.Sprintf("index is not searchable (%s): %s", readiness.State, readiness.JSON()), http.StatusServiceUnavailable)
+		return
+	}
+
+	validators, cacheable := state.indexValidators(r)
+	cacheable = cacheable && r.Method == http.MethodGet
+	if cacheable && validators.notModified(w, r) {
+		return
+	}
+	results, _, warnings, err := state.httpSearch(r.Context(), req.SearchInput)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	block := search.PackContext(req.Query, results, req.Budget)
+	block.Warnings = warnings
+	auditResults(r.Context(), len(block.Sections))
+
+	w.Header().Set("Content-Type", "application/json")
+	if !cacheable {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	_ = json.NewEncoder(w).Encode(block)
+}
+
+// parseContextRequest reads a context request's query parameters: budget,
+// and the search link parameters other than the presentation ones.
+func parseContextRequest(values url.Values) (contextRequest, error) {
+	var req contextRequest
+	params := url.Values{}
+	for key, value := range values {
+		switch {
+		case key == "budget":
+		case key == "chunk" || key == "context_lines" || key == "max_snippet_lines" || !slices.Contains(deepLinkParams, key):
+			return req, fmt.Errorf("unknown parameter %q", key)
+		default:
+			params[key] = value
+		}
+	}
+	if raw := values.Get("budget"); raw != "" {
+		budget, err := strconv.Atoi(raw)
+		if err != nil || budget < 0 {
+			return req, fmt.Errorf("budget must be a non-negative integer")
+		}
+		req.Budget = budget
+	}
+	input, err := parseDeepLink(params)
+	req.SearchInput = input
+	return req, err
+}
@@ -0,0 +1,203 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+)
+
+const (
+	// maxEmbeddingInputs caps the texts one EmbeddingsPath request may carry,
+	// matching the OpenAI API.
+	maxEmbeddingInputs = 2048
+	// maxEmbeddingRequestBytes caps an EmbeddingsPath request body.
+	maxEmbeddingRequestBytes = 8 << 20
+)
+
+// embeddingsRequest is the JSON body of an OpenAI-compatible embeddings
+// request. Input is a string or an array of strings; token arrays are not
+// accepted since vecgrep's providers take text.
+type embeddingsRequest struct {
+	Input          json.RawMessage `json:"input"`
+	Model          string          `json:"model,omitempty"`
+	EncodingFormat string          `json:"encoding_format,omitempty"`
+	Dimensions     int             `json:"dimensions,omitempty"`
+	User           string          `json:"user,omitempty"`
+}
+
+// embeddingsResponse is the JSON body of an EmbeddingsPath response, in the
+// OpenAI list shape.
+type embeddingsResponse struct {
+	Object string           `json:"object"`
+	Data   []embeddingDatum `json:"data"`
+	Model  string           `json:"model"`
+	Usage  embeddingsUsage  `json:"usage"`
+}
+
+// embeddingDatum is one input's vector: a float array, or a base64 string of
+// little-endian float32s when the request asked for encoding_format base64.
+type embeddingDatum struct {
+	Object    string `json:"object"`
+	Index     int    `json:"index"`
+	Embedding any    `json:"embedding"`
+}
+
+// embeddingsUsage reports estimated tokens; see embed.EstimateTokens.
+type embeddingsUsage struct {
+	PromptTokens int64 `json:"prompt_tokens"`
+	TotalTokens  int64 `json:"total_tokens"`
+}
+
+// serveEmbeddings answers POST EmbeddingsPath with OpenAI-compatible
+// embeddings from the served project's provider, so local tools can reuse
+// its configuration, throttling, and embedding cache as a gateway. The model
+// is the configured one: a request naming another model, or other
+// dimensions, is refused rather than silently answered with different
+// vectors. Inputs are embedded as they are, without the document or query
+// template, and vectors are L2-normalized like every vector vecgrep stores.
+// Errors use the OpenAI error shape so existing clients report them.
+func (s *SDKServer) serveEmbeddings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "method not allowed", "")
+		return
+	}
+	var req embeddingsRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxEmbeddingRequestBytes))
+	if err := decoder.Decode(&req); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err), "")
+		return
+	}
+	inputs, err := embeddingInputs(req.Input)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, err.Error(), "input")
+		return
+	}
+	if req.EncodingFormat != "" && req.EncodingFormat != "float" && req.EncodingFormat != "base64" {
+		writeOpenAIError(w, http.StatusBadRequest, "encoding_format must be float or base64", "encoding_format")
+		return
+	}
+
+	state, err := s.acquireProjectOperationSnapshot()
+	if err != nil {
+		writeOpenAIError(w, http.StatusServiceUnavailable, err.Error(), "")
+		return
+	}
+	defer state.release()
+	provider := state.provider
+	if provider == nil {
+		writeOpenAIError(w, http.StatusServiceUnavailable, "no embedding provider is configured", "")
+		return
+	}
+	if req.Model != "" && req.Model != provider.Model() {
+		writeOpenAIError(w, http.StatusBadRequest,
+			fmt.Sprintf("model %q is not served; this endpoint embeds with %q", req.Model, provider.Model()), "model")
+		return
+	}
+	if req.Dimensions != 0 && req.Dimensions != provider.Dimensions() {
+		writeOpenAIError(w, http.StatusBadRequest,
+			fmt.Sprintf("dimensions must be %d for %q", provider.Dimensions(), provider.Model()), "dimensions")
+		return
+	}
+
+	r, done := s.auditHTTP(r, "embeddings", "", map[string]any{"inputs": len(inputs)})
+	defer done()
+	vectors, err := provider.EmbedBatch(r.Context(), inputs)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadGateway, fmt.Sprintf("embedding provider: %v", err), "")
+		return
+	}
+	if len(vectors) != len(inputs) {
+		writeOpenAIError(w, http.StatusBadGateway,
+			fmt.Sprintf("embedding provider returned %d vectors for %d inputs", len(vectors), len(inputs)), "")
+		return
+	}
+	auditResults(r.Context(), len(vectors))
+
+	resp := embeddingsResponse{Object: "list", Data: make([]embeddingDatum, len(vectors)), Model: provider.Model()}
+	for i, vector := range vectors {
+		var embedding any = vector
+		if req.EncodingFormat == "base64" {
+			embedding = encodeEmbeddingBase64(vector)
+		}
+		resp.Data[i] = embeddingDatum{Object: "embedding", Index: i, Embedding: embedding}
+		resp.Usage.PromptTokens += embed.EstimateTokens(inputs[i])
+	}
+	resp.Usage.TotalTokens = resp.Usage.PromptTokens
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// embeddingInputs decodes an embeddings request's input: one string or an
+// array of up to maxEmbeddingInputs strings, none empty.
+func embeddingInputs(raw json.RawMessage) ([]string, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil, errors.New("input is required")
+	}
+	var inputs []string
+	if raw[0] == '[' {
+		if err := json.Unmarshal(raw, &inputs); err != nil {
+			return nil, errors.New("input must be a string or an array of strings")
+		}
+	} else {
+		var input string
+		if err := json.Unmarshal(raw, &input); err != nil {
+			return nil, errors.New("input must be a string or an array of strings")
+		}
+		inputs = []string{input}
+	}
+	if len(inputs) == 0 {
+		return nil, errors.New("input must not be empty")
+	}
+	if len(inputs) > maxEmbeddingInputs {
+		return nil, fmt.Errorf("input holds %d texts; at most %d are accepted", len(inputs), maxEmbeddingInputs)
+	}
+	for i, input := range inputs {
+		if input == "" {
+			return nil, fmt.Errorf("input[%d] is empty", i)
+		}
+	}
+	return inputs, nil
+}
+
+// encodeEmbeddingBase64 encodes vector as OpenAI's base64 format does: the
+// little-endian bytes of each float32.
+func encodeEmbeddingBase64(vector []float32) string {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// writeOpenAIError answers with an OpenAI-style error object naming the
+// offending request field, if any.
+func writeOpenAIError(w http.ResponseWriter, status int, message, param string) {
+	errorType := "invalid_request_error"
+	if status >= http.StatusInternalServerError {
+		errorType = "server_error"
+	}
+	body := map[string]any{"error": map[string]any{"message": message, "type": errorType, "param": nullableString(param), "code": nil}}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// nullableString is s, or nil for JSON null when s is empty.
+func nullableString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,453 @@
+package mcp
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+const (
+	// FilesPagePath serves the HTML file browser: the indexed files at
+	// FilesPagePath, and each file with its chunks at FilesPagePath/<path>
+	// (see serveFilesPage).
+	FilesPagePath = "/files"
+	// SimilarPagePath serves an HTML list of the chunks most similar to one
+	// chunk (see serveSimilarPage).
+	SimilarPagePath = "/similar"
+
+	// similarPageLimit is how many chunks SimilarPagePath lists by default.
+	similarPageLimit = 10
+)
+
+// pageFuncs are the helpers the HTML pages use to link to each other.
+var pageFuncs = template.FuncMap{
+	"fileURL":      fileURL,
+	"similarURL":   func(id any) string { return fmt.Sprintf("%s?chunk_id=%d", SimilarPagePath, id) },
+	"openURL":      func(id any) string { return fmt.Sprintf("%s?redirect=true&chunk_id=%d", OpenPath, id) },
+	"highlightCSS": func() template.CSS { return highlightCSS },
+}
+
+// pageLayout is the layout the HTML pages share, with a link back to the
+// file list. The pages work without scripts, so they keep working under any
+// Content-Security-Policy a proxy adds; the search page's script only adds
+// keyboard shortcuts. Each page defines "content".
+var pageLayout = template.Must(template.New("layout").Funcs(pageFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} · vecgrep</title>
+<style>
+{{highlightCSS}}
+body { font: 14px/1.5 system-ui, sans-serif; margin: 0 auto; max-width: 72rem; padding: 1rem; color: #1f2328; }
+a { color: #0969da; text-decoration: none; }
+a:hover { text-decoration: underline; }
+table.files { border-collapse: collapse; width: 100%; }
+table.files td, table.files th { padding: 0.2rem 0.6rem; text-align: left; border-bottom: 1px solid #d0d7de; }
+table.files td.num { text-align: right; font-variant-numeric: tabular-nums; }
+table.code { border-collapse: collapse; width: 100%; font: 12px/1.45 ui-monospace, monospace; }
+table.code td { padding: 0 0.6rem; white-space: pre; vertical-align: top; }
+table.code td.ln { text-align: right; user-select: none; width: 1%; }
+table.code td.ln a { color: #8c959f; }
+table.code tr:target td { background: #fff8c5; }
+tr.c0 td.src { background: #f6f8fa; }
+tr.c1 td.src { background: #eef6ff; }
+tr.boundary td { font-family: system-ui, sans-serif; background: #ddf4ff; border-top: 2px solid #54aeff; padding: 0.15rem 0.6rem; white-space: normal; }
+.meta { color: #57606a; }
+.button { border: 1px solid #d0d7de; border-radius: 4px; padding: 0 0.4rem; margin-left: 0.4rem; background: #fff; font-size: 12px; }
+.note { color: #9a6700; }
+table.snippet { background: #f6f8fa; display: block; overflow-x: auto; padding: 0.4rem 0; }
+form.search input[type=search] { font: inherit; padding: 0.2rem 0.4rem; width: 32rem; max-width: 100%; }
+section.result { border-left: 3px solid transparent; padding-left: 0.6rem; }
+section.result:focus-within { border-left-color: #0969da; }
+section.result.linked { border-left-color: #bf8700; background: #fff8c5; }
+kbd { border: 1px solid #d0d7de; border-radius: 3px; padding: 0 0.25rem; font-size: 12px; }
+</style>
+</head>
+<body>
+<p><a href="` + FilesPagePath + `">Indexed files</a>{{with .Project}} · {{.}}{{end}}</p>
+{{template "content" .}}
+</body>
+</html>
+{{define "chunk-actions"}}<a class="button" href="{{similarURL .}}">find similar</a><a class="button" href="{{openURL .}}">open in editor</a>{{end}}`))
+
+// pageTemplate parses a page's "content" into a copy of pageLayout.
+func pageTemplate(content string) *template.Template {
+	return template.Must(template.Must(pageLayout.Clone()).Parse(`{{define "content"}}` + content + `{{end}}`))
+}
+
+var (
+	filesTemplate = pageTemplate(`<h1>Indexed files</h1>
+<p>{{len .Files}} files</p>
+<table class="files">
+<tr><th>File</th><th>Language</th><th>Bytes</th><th>Indexed</th></tr>
+{{range .Files}}<tr><td><a href="{{fileURL .RelativePath}}">{{.RelativePath}}</a></td><td>{{.Language}}</td><td class="num">{{.Size}}</td><td>{{.IndexedAt.Format "2006-01-02 15:04"}}</td></tr>
+{{end}}</table>`)
+
+	fileTemplate = pageTemplate(`<h1>{{.Path}}</h1>
+<p>{{.Language}} · {{len .Chunks}} chunks</p>
+{{with .Note}}<p class="note">{{.}}</p>{{end}}
+<table class="code chroma">
+{{range .Lines}}{{range .Starts}}<tr class="boundary" id="chunk-{{.ID}}"><td></td><td>chunk {{.ID}} <span class="meta">L{{.StartLine}}–{{.EndLine}} {{.ChunkType}}{{with .SymbolName}} {{.}}{{end}}</span>{{template "chunk-actions" .ID}}</td></tr>
+{{end}}<tr class="c{{.Shade}}" id="L{{.Number}}"><td class="ln"><a href="#L{{.Number}}">{{.Number}}</a></td><td class="src">{{.Text}}</td></tr>
+{{end}}</table>`)
+
+	similarTemplate = pageTemplate(`<h1>Similar to chunk {{.Source.ID}}</h1>
+<p><a href="{{fileURL .Source.RelativePath}}#chunk-{{.Source.ID}}">{{.Source.RelativePath}}:{{.Source.StartLine}}-{{.Source.EndLine}}</a>{{with .Source.SymbolName}} {{.}}{{end}}</p>
+{{range .Results}}<h3><a href="{{fileURL .RelativePath}}#chunk-{{.ChunkID}}">{{.RelativePath}}:{{.StartLine}}-{{.EndLine}}</a>{{with .SymbolName}} {{.}}{{end}} <span class="meta">{{printf "%.3f" .Score}}</span>{{template "chunk-actions" .ChunkID}}</h3>
+<table class="code chroma snippet">
+{{$path := .RelativePath}}{{range .Lines}}<tr><td class="ln"><a href="{{fileURL $path}}#L{{.Number}}">{{.Number}}</a></td><td class="src">{{.Text}}</td></tr>
+{{end}}</table>
+{{else}}<p>No similar code found.</p>
+{{end}}`)
+
+	searchTemplate = template.Must(pageTemplate(`<form class="search" action="` + DeepLinkPath + `" method="get">
+<input type="search" id="q" name="q" value="{{.Query}}" aria-label="Search">{{range .Options}}<input type="hidden" name="{{.Name}}" value="{{.Value}}">{{end}}
+<button type="submit">Search</button> <a class="meta" href="{{.URL}}">link to this search</a>
+</form>
+<p class="meta"><kbd>j</kbd>/<kbd>k</kbd> or <kbd>↓</kbd>/<kbd>↑</kbd> move between results, <kbd>Enter</kbd> opens one, <kbd>/</kbd> edits the query.</p>
+{{range .Warnings}}<p class="note">{{.}}</p>
+{{end}}{{with .Chunk}}<h2>Linked chunk</h2>
+{{with $.ChunkNote}}<p class="note">{{.}}</p>
+{{end}}{{template "result" .}}<h2>Results</h2>
+{{end}}{{range .Results}}{{template "result" .}}{{else}}<p>No results found.</p>
+{{end}}<script>
+(function () {
+	var links = Array.prototype.slice.call(document.querySelectorAll("a.result"));
+	var linked = document.querySelector("a.result[autofocus]");
+	if (linked) {
+		linked.focus();
+		linked.scrollIntoView({block: "center"});
+	}
+	document.addEventListener("keydown", function (e) {
+		if (e.ctrlKey || e.metaKey || e.altKey || e.target.tagName === "INPUT") {
+			return;
+		}
+		var i = links.indexOf(document.activeElement);
+		if (e.key === "j" || e.key === "ArrowDown") {
+			i = Math.min(i + 1, links.length - 1);
+		} else if (e.key === "k" || e.key === "ArrowUp") {
+			i = Math.max(i - 1, 0);
+		} else if (e.key === "/") {
+			e.preventDefault();
+			document.getElementById("q").focus();
+			return;
+		} else {
+			return;
+		}
+		if (links[i]) {
+			e.preventDefault();
+			links[i].focus();
+			links[i].scrollIntoView({block: "nearest"});
+		}
+	});
+})();
+</script>`).Parse(`{{define "result"}}<section class="result{{if .Linked}} linked{{end}}" id="{{.Anchor}}">
+<h3>{{if .Rank}}{{.Rank}}. {{end}}<a class="result" href="{{if .ChunkID}}{{fileURL .RelativePath}}#chunk-{{.ChunkID}}{{else}}{{fileURL .RelativePath}}#L{{.StartLine}}{{end}}"{{if .Linked}} autofocus{{end}}>{{.RelativePath}}:{{.StartLine}}-{{.EndLine}}</a>{{with .SymbolName}} {{.}}{{end}} <span class="meta">{{.ChunkType}} {{printf "%.3f" .Score}}</span>{{with .URL}} <a class="meta" href="{{.}}">link</a>{{end}}{{if .ChunkID}}{{template "chunk-actions" .ChunkID}}{{end}}</h3>
+<table class="code chroma snippet">
+{{$path := .RelativePath}}{{range .Lines}}<tr><td class="ln"><a href="{{fileURL $path}}#L{{.Number}}">{{.Number}}</a></td><td class="src">{{.Text}}</td></tr>
+{{end}}</table>
+</section>
+{{end}}`))
+)
+
+// filesPage is the data of the file list.
+type filesPage struct {
+	Title   string
+	Project string
+	Files   []db.FileInfo
+}
+
+// filePage is the data of one file with its chunk boundaries.
+type filePage struct {
+	Title    string
+	Project  string
+	Path     string
+	Language string
+	Note     string
+	Chunks   []db.ChunkRecord
+	Lines    []pageLine
+}
+
+// pageLine is one source line, highlighted, after the boundaries of the
+// chunks starting on it. Shade alternates between neighboring chunks so
+// their extents can be told apart, and is -1 for lines no chunk holds.
+type pageLine struct {
+	Number int
+	Text   template.HTML
+	Starts []db.ChunkRecord
+	Shade  int
+}
+
+// similarPage is the data of the similar chunks list.
+type similarPage struct {
+	Title   string
+	Project string
+	Source  db.ChunkRecord
+	Results []similarResult
+}
+
+// similarResult is a similar chunk with its highlighted lines, numbered
+// from its StartLine.
+type similarResult struct {
+	search.Result
+	Lines []pageLine
+}
+
+// searchPage is the data of a search link opened in a browser.
+type searchPage struct {
+	Title    string
+	Project  string
+	Query    string
+	Options  []searchPageOption
+	URL      string
+	Warnings []string
+	// Chunk is the linked chunk when the search no longer ranks it, and
+	// ChunkNote says when it only holds the anchor's first line.
+	Chunk     *searchPageResult
+	ChunkNote string
+	Results   []searchPageResult
+}
+
+// searchPageOption is a search option the page's form resubmits with a
+// new query.
+type searchPageOption struct {
+	Name, Value string
+}
+
+// searchPageResult is a search result with its highlighted lines. Rank is
+// its 1-based position, or 0 for a linked chunk outside the results, and
+// Linked marks the chunk the link's anchor resolved to, which the page
+// focuses.
+type searchPageResult struct {
+	deepLinkResult
+	Rank   int
+	Linked bool
+	Lines  []pageLine
+}
+
+// serveFilesPage answers GET FilesPagePath with an HTML list of the indexed
+// files, and GET FilesPagePath/<path> with one file's source, each of its
+// chunks marked where it starts with its ID and buttons to find similar
+// code and open it in the editor. The source is read from disk; when the
+// file is gone, it is rebuilt from the indexed chunks.
+func (s *SDKServer) serveFilesPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	state, err := s.acquireResourceSnapshot(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer state.release()
+
+	relPath := r.PathValue("path")
+	if relPath == "" {
+		validators, cacheable := state.indexValidators(r)
+		if cacheable && validators.notModified(w, r) {
+			return
+		}
+		files, err := state.database.ListFiles(state.projectRoot)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("list files: %v", err), http.StatusInternalServerError)
+			return
+		}
+		sort.Slice(files, func(i, j int) bool { return files[i].RelativePath < files[j].RelativePath })
+		if !cacheable {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		renderPage(w, filesTemplate, filesPage{Title: "Indexed files", Project: state.projectName, Files: files})
+		return
+	}
+
+	page, err := state.filePage(relPath)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if page == nil {
+		http.Error(w, fmt.Sprintf("%s is not indexed", relPath), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	renderPage(w, fileTemplate, page)
+}
+
+// filePage loads a file's chunks and lines, or returns nil when the file is
+// not indexed in the active project.
+func (state projectReadSnapshot) filePage(relPath string) (*filePage, error) {
+	all, err := state.database.GetChunksByFile(relPath)
+	if err != nil {
+		return nil, fmt.Errorf("get chunks of %s: %w", relPath, err)
+	}
+	var chunks []db.ChunkRecord
+	for _, c := range all {
+		if c.RelativePath == relPath && (c.ProjectRoot == "" || c.ProjectRoot == state.projectRoot) && c.Ref == "" {
+			chunks = append(chunks, c)
+		}
+	}
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+	// Outer chunks first, so a boundary row reads before the rows of the
+	// chunks nested in it.
+	sort.Slice(chunks, func(i, j int) bool {
+		if chunks[i].StartLine != chunks[j].StartLine {
+			return chunks[i].StartLine < chunks[j].StartLine
+		}
+		return chunks[i].EndLine > chunks[j].EndLine
+	})
+
+	page := &filePage{Title: relPath, Project: state.projectName, Path: relPath, Language: chunks[0].Language, Chunks: chunks}
+	var lines []string
+	if data, readErr := os.ReadFile(chunks[0].FilePath); readErr == nil {
+		lines = strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	} else {
+		page.Note = fmt.Sprintf("The file could not be read (%v), so it is shown as indexed.", readErr)
+		lines = linesFromChunks(chunks)
+	}
+
+	page.Lines = make([]pageLine, len(lines))
+	highlighted := highlightLines(page.Language, relPath, lines)
+	next := 0
+	for i := range lines {
+		line := pageLine{Number: i + 1, Text: highlighted[i], Shade: -1}
+		for next < len(chunks) && chunks[next].StartLine <= line.Number {
+			line.Starts = append(line.Starts, chunks[next])
+			next++
+		}
+		// The innermost chunk holding the line decides its shade.
+		for k := next - 1; k >= 0; k-- {
+			if chunks[k].EndLine >= line.Number {
+				line.Shade = k % 2
+				break
+			}
+		}
+		page.Lines[i] = line
+	}
+	return page, nil
+}
+
+// linesFromChunks rebuilds a file's lines from its chunks, leaving lines no
+// chunk holds empty.
+func linesFromChunks(chunks []db.ChunkRecord) []string {
+	var lines []string
+	for _, c := range chunks {
+		for i, text := range strings.Split(c.Content, "\n") {
+			n := c.StartLine + i
+			if n > c.EndLine {
+				break
+			}
+			for len(lines) < n {
+				lines = append(lines, "")
+			}
+			if lines[n-1] == "" {
+				lines[n-1] = text
+			}
+		}
+	}
+	return lines
+}
+
+// serveSimilarPage answers GET SimilarPagePath?chunk_id=N[&limit=N] with an
+// HTML list of the chunks nearest to chunk N, as vecgrep_similar finds them,
+// each linking to its place in the file browser.
+func (s *SDKServer) serveSimilarPage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	chunkID, err := strconv.ParseInt(r.URL.Query().Get("chunk_id"), 10, 64)
+	if err != nil || chunkID <= 0 {
+		http.Error(w, "chunk_id must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	limit := similarPageLimit
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	r, done := s.auditHTTP(r, "similar", "", map[string]any{"chunk_id": chunkID, "limit": limit})
+	defer done()
+	state, err := s.acquireResourceSnapshot(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer state.release()
+	source, err := state.database.Backend().GetChunkByID(chunkID)
+	if err != nil || source == nil || (source.ProjectRoot != "" && source.ProjectRoot != state.projectRoot) {
+		http.Error(w, fmt.Sprintf("chunk %d is not indexed", chunkID), http.StatusNotFound)
+		return
+	}
+	validators, cacheable := state.indexValidators(r)
+	if cacheable && validators.notModified(w, r) {
+		return
+	}
+	results, err := state.searcher.SearchSimilarByID(r.Context(), chunkID, search.SimilarOptions{
+		SearchOptions:   search.SearchOptions{Limit: limit, ProjectRoot: state.projectRoot},
+		ExcludeSourceID: true,
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("search: %v", err), http.StatusInternalServerError)
+		return
+	}
+	auditResults(r.Context(), len(results))
+	similar := make([]similarResult, len(results))
+	for i, result := range results {
+		similar[i] = similarResult{Result: result, Lines: snippetLines(result)}
+	}
+
+	if !cacheable {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+	renderPage(w, similarTemplate, similarPage{
+		Title:   fmt.Sprintf("Similar to chunk %d", chunkID),
+		Project: state.projectName,
+		Source:  *source,
+		Results: similar,
+	})
+}
+
+// snippetLines highlights a result's content, numbering its lines from its
+// StartLine.
+func snippetLines(result search.Result) []pageLine {
+	lines := strings.Split(strings.TrimSuffix(result.Content, "\n"), "\n")
+	out := make([]pageLine, len(lines))
+	for i, text := range highlightLines(result.Language, result.RelativePath, lines) {
+		out[i] = pageLine{Number: result.StartLine + i, Text: text}
+	}
+	return out
+}
+
+// renderPage writes an HTML page, or a plain error when its template fails.
+func renderPage(w http.ResponseWriter, page *template.Template, data any) {
+	var sb strings.Builder
+	if err := page.Execute(&sb, data); err != nil {
+		http.Error(w, fmt.Sprintf("render page: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+// fileURL links to a file in the file browser, escaping each path segment.
+func fileURL(relPath string) string {
+	return FilesPagePath + "/" + (&url.URL{Path: relPath}).EscapedPath()
+}
@@ -0,0 +1,212 @@
+package mcp
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// readOnlyExcludedTools are the tools a ReadOnly server leaves out: every
+// tool that writes the index, the project registry, bookmarks, feedback, or
+// memories, plus the tools that reach beyond the served project (other
+// registered projects, the host user's memories).
+var readOnlyExcludedTools = []string{
+	"vecgrep_init",
+	"vecgrep_index",
+	"vecgrep_ensure",
+	"vecgrep_delete",
+	"vecgrep_clean",
+	"vecgrep_reset",
+	"vecgrep_search_all",
+	"vecgrep_bookmark",
+	"vecgrep_feedback",
+	"memory_remember",
+	"memory_recall",
+	"memory_forget",
+	"memory_stats",
+}
+
+// Browsers cannot attach a bearer token to a link they follow, so the HTML
+// pages and editor links sign in once: a GET or HEAD carrying the token in
+// authTokenParam is answered with the token as the authCookieName cookie and
+// a redirect to the same URL without it.
+const (
+	authCookieName = "vecgrep_token"
+	authTokenParam = "token"
+)
+
+// requireBearer rejects requests that carry token neither as a bearer token
+// in their Authorization header nor in the auth cookie, and signs browsers
+// in through authTokenParam. Comparisons are constant-time.
+func requireBearer(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if (r.Method == http.MethodGet || r.Method == http.MethodHead) && r.URL.Query().Has(authTokenParam) {
+			if !tokenMatches(r.URL.Query().Get(authTokenParam), token) {
+				unauthorized(w)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{
+				Name:     authCookieName,
+				Value:    token,
+				Path:     "/",
+				HttpOnly: true,
+				Secure:   r.TLS != nil,
+				SameSite: http.SameSiteLaxMode,
+			})
+			target := *r.URL
+			query := target.Query()
+			query.Del(authTokenParam)
+			target.RawQuery = query.Encode()
+			http.Redirect(w, r, target.RequestURI(), http.StatusSeeOther)
+			return
+		}
+		if !tokenMatches(requestToken(r.Header), token) {
+			unauthorized(w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestToken returns the token a request authenticates with: its bearer
+// token, or else its auth cookie.
+func requestToken(header http.Header) string {
+	scheme, got, _ := strings.Cut(header.Get("Authorization"), " ")
+	if strings.EqualFold(scheme, "Bearer") && strings.TrimSpace(got) != "" {
+		return strings.TrimSpace(got)
+	}
+	if cookie, err := (&http.Request{Header: header}).Cookie(authCookieName); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// tokenMatches compares a presented token with the server's in constant time.
+func tokenMatches(got, token string) bool {
+	return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="vecgrep"`)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// AuthTokenFile is the file in the data directory that keeps the token
+// generated for a server that binds beyond loopback without one configured.
+const AuthTokenFile = "serve_token"
+
+// GenerateAuthToken returns a random bearer token.
+func GenerateAuthToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate auth token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// LoadOrCreateAuthToken returns the token stored at path, first generating
+// and storing one, readable only by its owner, when there is none. created
+// reports that the token is new.
+func LoadOrCreateAuthToken(path string) (token string, created bool, err error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token = strings.TrimSpace(string(data)); token != "" {
+			return token, false, nil
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", false, fmt.Errorf("read auth token: %w", err)
+	}
+	if token, err = GenerateAuthToken(); err != nil {
+		return "", false, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", false, fmt.Errorf("store auth token: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return "", false, fmt.Errorf("store auth token: %w", err)
+	}
+	return token, true, nil
+}
+
+// clientLimiterIdle is how long a client's limiter is kept after its last
+// request.
+const clientLimiterIdle = 10 * time.Minute
+
+// clientLimiter caps HTTP requests per client address with a token bucket
+// each. A client may burst a quarter of its per-minute allowance, which
+// covers the handful of requests an MCP handshake makes.
+type clientLimiter struct {
+	mu      sync.Mutex
+	limit   rate.Limit
+	burst   int
+	clients map[string]*clientRate
+	swept   time.Time
+}
+
+type clientRate struct {
+	limiter *rate.Limiter
+	seen    time.Time
+}
+
+// newClientLimiter allows each client perMinute requests a minute.
+func newClientLimiter(perMinute int) *clientLimiter {
+	return &clientLimiter{
+		limit:   rate.Limit(float64(perMinute) / 60),
+		burst:   max(perMinute/4, 1),
+		clients: make(map[string]*clientRate),
+	}
+}
+
+// allow reports whether the client at addr may make a request now. At most
+// once a minute it also drops clients idle longer than clientLimiterIdle.
+func (l *clientLimiter) allow(addr string, now time.Time) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.swept) > time.Minute {
+		for key, client := range l.clients {
+			if now.Sub(client.seen) > clientLimiterIdle {
+				delete(l.clients, key)
+			}
+		}
+		l.swept = now
+	}
+	client, ok := l.clients[host]
+	if !ok {
+		client = &clientRate{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[host] = client
+	}
+	client.seen = now
+	return client.limiter.AllowN(now, 1)
+}
+
+// middleware answers 429 Too Many Requests, with a Retry-After of the time
+// one request takes to refill, to clients over their limit.
+func (l *clientLimiter) middleware(next http.Handler) http.Handler {
+	retryAfter := strconv.Itoa(int(math.Ceil(1 / float64(l.limit))))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.allow(r.RemoteAddr, time.Now()) {
+			w.Header().Set("Retry-After", retryAfter)
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"html/template"
+	"path"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+const (
+	// highlightStyle is the chroma style the HTML pages color code with.
+	highlightStyle = "github"
+	// maxHighlightBytes caps the source highlightLines colors; larger files
+	// are shown as plain text so one page cannot stall the server.
+	maxHighlightBytes = 1 << 20
+)
+
+// highlightCSS is the stylesheet for the classes highlightLines emits,
+// scoped to elements with class "chroma".
+var highlightCSS = func() template.CSS {
+	var sb strings.Builder
+	if err := chromahtml.New(chromahtml.WithClasses(true)).WriteCSS(&sb, styles.Get(highlightStyle)); err != nil {
+		return ""
+	}
+	return template.CSS(sb.String())
+}()
+
+// highlightLines renders source lines as HTML, one entry per line, with
+// spans carrying chroma's token classes (see highlightCSS). The lexer is
+// chosen by the indexed language, then by the file name. Lines are only
+// escaped when no lexer fits or the source is over maxHighlightBytes.
+func highlightLines(language, relPath string, lines []string) []template.HTML {
+	out := make([]template.HTML, len(lines))
+	for i, line := range lines {
+		out[i] = template.HTML(template.HTMLEscapeString(line))
+	}
+	source := strings.Join(lines, "\n") + "\n"
+	lexer := highlightLexer(language, relPath)
+	if lexer == nil || len(source) > maxHighlightBytes {
+		return out
+	}
+	tokens, err := chroma.Coalesce(lexer).Tokenise(nil, source)
+	if err != nil {
+		return out
+	}
+	for i, lineTokens := range chroma.SplitTokensIntoLines(tokens.Tokens()) {
+		if i >= len(out) {
+			break
+		}
+		var sb strings.Builder
+		for _, token := range lineTokens {
+			text := template.HTMLEscapeString(strings.TrimSuffix(token.Value, "\n"))
+			if text == "" {
+				continue
+			}
+			if class := highlightClass(token.Type); class != "" {
+				sb.WriteString(`<span class="` + class + `">` + text + `</span>`)
+			} else {
+				sb.WriteString(text)
+			}
+		}
+		out[i] = template.HTML(sb.String())
+	}
+	return out
+}
+
+// highlightLexer returns the lexer for an indexed language or, failing that,
+// for the file name, or nil when neither is known.
+func highlightLexer(language, relPath string) chroma.Lexer {
+	if language != "" {
+		if lexer := lexers.Get(language); lexer != nil {
+			return lexer
+		}
+	}
+	return lexers.Match(path.Base(relPath))
+}
+
+// highlightClass returns the CSS class of a token type, falling back to its
+// parent types as chroma's HTML formatter does.
+func highlightClass(t chroma.TokenType) string {
+	for ; t != 0; t = t.Parent() {
+		if class, ok := chroma.StandardTypes[t]; ok {
+			return class
+		}
+	}
+	return chroma.StandardTypes[t]
+}
@@ -0,0 +1,399 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+const (
+	// IndexAPIPath starts an indexing job (see serveIndexAPI).
+	IndexAPIPath = "/api/index"
+	// FilesAPIPath prefixes the file paths a DELETE removes from the index
+	// (see serveDeleteFileAPI).
+	FilesAPIPath = "/api/files/"
+	// CleanAPIPath starts a database sync job (see serveCleanAPI).
+	CleanAPIPath = "/api/clean"
+	// ResetAPIPath starts a job clearing the whole index (see serveResetAPI).
+	ResetAPIPath = "/api/reset"
+	// JobsPath lists jobs, and JobsPath/<id> reports one.
+	JobsPath = "/api/jobs"
+
+	// maxRetainedJobs is how many finished jobs are kept for polling; the
+	// oldest finished job is forgotten first.
+	maxRetainedJobs = 100
+	// maxJobRequestBytes caps the JSON body of a job request.
+	maxJobRequestBytes = 1 << 20
+)
+
+// Job states, in the order a job moves through them.
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+)
+
+// httpJob is an index mutation started over the REST API. Jobs run one at a
+// time in the order they were accepted, so automation can queue several
+// without them contending for the write lock.
+type httpJob struct {
+	ID       string     `json:"id"`
+	Kind     string     `json:"kind"`
+	Status   string     `json:"status"`
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	Result   any        `json:"result,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// jobRegistry tracks REST jobs and runs them one at a time.
+type jobRegistry struct {
+	mu    sync.Mutex
+	jobs  map[string]*httpJob
+	order []string
+	// run serializes job execution.
+	run sync.Mutex
+}
+
+func newJobRegistry() *jobRegistry {
+	return &jobRegistry{jobs: make(map[string]*httpJob)}
+}
+
+// start queues a job of kind that runs work in the background, detached
+// from the request that started it, and returns a copy of its state.
+func (r *jobRegistry) start(ctx context.Context, kind string, work func(context.Context) (any, error)) (httpJob, error) {
+	id, err := newJobID()
+	if err != nil {
+		return httpJob{}, err
+	}
+	job := &httpJob{ID: id, Kind: kind, Status: jobQueued, Created: time.Now().UTC()}
+	r.mu.Lock()
+	r.jobs[id] = job
+	r.order = append(r.order, id)
+	r.pruneLocked()
+	snapshot := *job
+	r.mu.Unlock()
+
+	ctx = context.WithValue(context.WithoutCancel(ctx), jobIDKey{}, id)
+	go func() {
+		r.run.Lock()
+		defer r.run.Unlock()
+		r.update(id, func(job *httpJob) {
+			now := time.Now().UTC()
+			job.Status, job.Started = jobRunning, &now
+		})
+		result, err := work(ctx)
+		r.update(id, func(job *httpJob) {
+			now := time.Now().UTC()
+			job.Finished = &now
+			if err != nil {
+				job.Status, job.Error = jobFailed, err.Error()
+				return
+			}
+			job.Status, job.Result = jobSucceeded, result
+		})
+	}()
+	return snapshot, nil
+}
+
+func (r *jobRegistry) update(id string, apply func(*httpJob)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if job, ok := r.jobs[id]; ok {
+		apply(job)
+	}
+}
+
+// get returns a copy of the job with id.
+func (r *jobRegistry) get(id string) (httpJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, ok := r.jobs[id]
+	if !ok {
+		return httpJob{}, false
+	}
+	return *job, true
+}
+
+// list returns copies of the retained jobs, oldest first.
+func (r *jobRegistry) list() []httpJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	jobs := make([]httpJob, 0, len(r.order))
+	for _, id := range r.order {
+		jobs = append(jobs, *r.jobs[id])
+	}
+	return jobs
+}
+
+// pruneLocked forgets the oldest finished jobs beyond maxRetainedJobs.
+// Queued and running jobs are always kept.
+func (r *jobRegistry) pruneLocked() {
+	excess := len(r.order) - maxRetainedJobs
+	if excess <= 0 {
+		return
+	}
+	kept := r.order[:0]
+	for _, id := range r.order {
+		if job := r.jobs[id]; excess > 0 && job.Finished != nil {
+			delete(r.jobs, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	r.order = kept
+}
+
+func newJobID() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("generate job id: %w", err)
+	}
+	return hex.EncodeToString(b[:]), nil
+}
+
+// indexJobRequest is the JSON body of a POST to IndexAPIPath, with the
+// vecgrep_index arguments.
+type indexJobRequest struct {
+	Paths []string `json:"paths,omitempty"`
+	Force bool     `json:"force,omitempty"`
+}
+
+// indexJobResult is an indexing job's result.
+type indexJobResult struct {
+	FilesProcessed int      `json:"files_processed"`
+	FilesSkipped   int      `json:"files_skipped"`
+	FilesDeleted   int      `json:"files_deleted"`
+	ChunksCreated  int      `json:"chunks_created"`
+	ChunksReused   int      `json:"chunks_reused,omitempty"`
+	DurationMS     int64    `json:"duration_ms"`
+	Errors         []string `json:"errors,omitempty"`
+}
+
+// serveIndexAPI answers POST IndexAPIPath by queuing an indexing job: the
+// whole project incrementally, the given paths, or with force a full
+// rebuild. Like vecgrep_index, it goes through a running daemon when there
+// is one. The job's progress streams to IndexProgressPath.
+func (s *SDKServer) serveIndexAPI(w http.ResponseWriter, r *http.Request) {
+	var req indexJobRequest
+	state, ok := s.jobRequest(w, r, &req)
+	if !ok {
+		return
+	}
+	structuralMode, err := app.ParseStructuralChunksMode(state.codemapCfg.StructuralChunks)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("configure structural chunks: %v", err), http.StatusInternalServerError)
+		return
+	}
+	r, done := s.auditHTTP(r, "index", "", map[string]any{"paths": req.Paths, "force": req.Force})
+	defer done()
+	s.startJob(w, r, "index", func(ctx context.Context) (any, error) {
+		job := jobIDFrom(ctx)
+		var result *index.IndexResult
+		var err error
+		if dc := state.daemon; dc != nil && dc.available() {
+			result, err = dc.reindexSync(ctx, req.Force, string(structuralMode), req.Paths)
+		} else {
+			result, err = state.session.index(ctx, app.IndexRequest{
+				Paths:            req.Paths,
+				FullReindex:      req.Force,
+				StructuralChunks: string(structuralMode),
+			}, s.progress.callback(job))
+		}
+		s.progress.finish(job, err)
+		if err != nil {
+			return nil, errors.New(formatLockError(err))
+		}
+		out := indexJobResult{
+			FilesProcessed: result.FilesProcessed,
+			FilesSkipped:   result.FilesSkipped,
+			FilesDeleted:   result.FilesDeleted,
+			ChunksCreated:  result.ChunksCreated,
+			ChunksReused:   result.ChunksReused,
+			DurationMS:     result.Duration.Milliseconds(),
+		}
+		for _, e := range result.Errors {
+			out.Errors = append(out.Errors, e.Error())
+		}
+		return out, nil
+	})
+}
+
+// serveDeleteFileAPI answers DELETE FilesAPIPath<path> by queuing a job that
+// removes the file's chunks from the index, as vecgrep_delete does. The
+// path is project-relative.
+func (s *SDKServer) serveDeleteFileAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		w.Header().Set("Allow", http.MethodDelete)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := r.PathValue("path")
+	if path == "" {
+		http.Error(w, "file path is required", http.StatusBadRequest)
+		return
+	}
+	state, ok := s.jobState(w, r)
+	if !ok {
+		return
+	}
+	r, done := s.auditHTTP(r, "delete", "", map[string]any{"file_path": path})
+	defer done()
+	s.startJob(w, r, "delete", func(ctx context.Context) (any, error) {
+		database, release, err := state.session.acquireWriteDB(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("open database for writing: %s", formatLockError(err))
+		}
+		defer func() { _ = release() }()
+		chunks, err := database.DeleteProjectFile(ctx, state.projectRoot, path)
+		if err != nil {
+			return nil, fmt.Errorf("delete file: %w", err)
+		}
+		return map[string]any{"file_path": path, "chunks_deleted": chunks}, nil
+	})
+}
+
+// serveCleanAPI answers POST CleanAPIPath by queuing a job that syncs the
+// database to disk, as vecgrep_clean does.
+func (s *SDKServer) serveCleanAPI(w http.ResponseWriter, r *http.Request) {
+	var req struct{}
+	state, ok := s.jobRequest(w, r, &req)
+	if !ok {
+		return
+	}
+	r, done := s.auditHTTP(r, "clean", "", nil)
+	defer done()
+	s.startJob(w, r, "clean", func(ctx context.Context) (any, error) {
+		database, release, err := state.session.acquireWriteDB(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("open database for writing: %s", formatLockError(err))
+		}
+		defer func() { _ = release() }()
+		stats, err := database.Clean(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("clean database: %w", err)
+		}
+		return map[string]any{"synced": stats.Synced, "records": stats.TotalRecords, "files": stats.TotalFiles}, nil
+	})
+}
+
+// serveResetAPI answers POST ResetAPIPath with {"confirm": "yes"} by queuing
+// a job that clears all indexed data, as vecgrep_reset does.
+func (s *SDKServer) serveResetAPI(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Confirm string `json:"confirm"`
+	}
+	state, ok := s.jobRequest(w, r, &req)
+	if !ok {
+		return
+	}
+	if req.Confirm != "yes" {
+		http.Error(w, `reset deletes all indexed data; send {"confirm": "yes"} to proceed`, http.StatusBadRequest)
+		return
+	}
+	r, done := s.auditHTTP(r, "reset", "", nil)
+	defer done()
+	s.startJob(w, r, "reset", func(ctx context.Context) (any, error) {
+		database, release, err := state.session.acquireWriteDB(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("open database for writing: %s", formatLockError(err))
+		}
+		defer func() { _ = release() }()
+		if err := database.ResetAll(ctx); err != nil {
+			return nil, fmt.Errorf("reset database: %w", err)
+		}
+		return map[string]any{"reset": true}, nil
+	})
+}
+
+// serveJobs answers GET JobsPath with every retained job, oldest first.
+func (s *SDKServer) serveJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJobJSON(w, http.StatusOK, map[string]any{"jobs": s.jobs.list()})
+}
+
+// serveJob answers GET JobsPath/<id> with one job, for polling until its
+// status is succeeded or failed.
+func (s *SDKServer) serveJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	job, ok := s.jobs.get(r.PathValue("id"))
+	if !ok {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	writeJobJSON(w, http.StatusOK, job)
+}
+
+// jobRequest checks that r is a POST, decodes its optional JSON body into
+// req, and captures the project state the job will run against. It answers
+// the request itself and returns false on failure.
+func (s *SDKServer) jobRequest(w http.ResponseWriter, r *http.Request, req any) (projectStateSnapshot, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return projectStateSnapshot{}, false
+	}
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJobRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return projectStateSnapshot{}, false
+	}
+	return s.jobState(w, r)
+}
+
+// jobState captures the active project for a job, answering 503 when there
+// is none.
+func (s *SDKServer) jobState(w http.ResponseWriter, r *http.Request) (projectStateSnapshot, bool) {
+	if err := s.ensureInitialized(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return projectStateSnapshot{}, false
+	}
+	state := s.snapshotProjectState()
+	if !state.initialized || state.session == nil || state.projectRoot == "" {
+		http.Error(w, "no active project session", http.StatusServiceUnavailable)
+		return projectStateSnapshot{}, false
+	}
+	return state, true
+}
+
+// startJob queues work and answers 202 Accepted with the job, its URL in
+// the Location header.
+func (s *SDKServer) startJob(w http.ResponseWriter, r *http.Request, kind string, work func(context.Context) (any, error)) {
+	job, err := s.jobs.start(r.Context(), kind, work)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	auditResults(r.Context(), 1)
+	w.Header().Set("Location", JobsPath+"/"+job.ID)
+	writeJobJSON(w, http.StatusAccepted, job)
+}
+
+func writeJobJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,231 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+)
+
+// defaultEditor is the editor OpenPath links to when server.editor is unset.
+const defaultEditor = "vscode"
+
+// editorSpec says how to open a file at a line in one editor: by URI, which
+// the browser hands to the editor, and by command, which the server runs
+// itself. {path}, {line}, and {column} are replaced in both.
+type editorSpec struct {
+	uri     string
+	command []string
+}
+
+// editors are the editors server.editor and the editor parameter name.
+// The parameter only ever names one of them; a URI template is accepted
+// from server.editor alone, so a link can never send a browser elsewhere.
+var editors = map[string]editorSpec{
+	"vscode":          {uri: "vscode://file{path}:{line}:{column}", command: []string{"code", "-g", "{path}:{line}:{column}"}},
+	"vscode-insiders": {uri: "vscode-insiders://file{path}:{line}:{column}", command: []string{"code-insiders", "-g", "{path}:{line}:{column}"}},
+	"cursor":          {uri: "cursor://file{path}:{line}:{column}", command: []string{"cursor", "-g", "{path}:{line}:{column}"}},
+	"zed":             {uri: "zed://file{path}:{line}:{column}", command: []string{"zed", "{path}:{line}:{column}"}},
+	"idea":            {uri: "idea://open?file={path}&line={line}&column={column}", command: []string{"idea", "--line", "{line}", "--column", "{column}", "{path}"}},
+	"goland":          {uri: "goland://open?file={path}&line={line}&column={column}", command: []string{"goland", "--line", "{line}", "--column", "{column}", "{path}"}},
+	"sublime":         {uri: "subl://open?url=file://{path}&line={line}&column={column}", command: []string{"subl", "{path}:{line}:{column}"}},
+}
+
+// editorSchemes are the URI schemes a server.editor template may use: those
+// of the named editors and of other editors that register a URL handler.
+var editorSchemes = map[string]bool{
+	"vscode": true, "vscode-insiders": true, "vscodium": true, "cursor": true,
+	"windsurf": true, "zed": true, "subl": true, "txmt": true, "mvim": true,
+	"idea": true, "goland": true, "pycharm": true, "webstorm": true,
+	"phpstorm": true, "rubymine": true, "clion": true, "rider": true,
+	"jetbrains": true, "emacs": true, "nova": true,
+}
+
+// startEditor runs an editor command without waiting for it to exit. It is
+// a package var so tests can record the command instead of running it.
+var startEditor = func(argv []string) error {
+	bin, err := config.ResolveBinary(argv[0])
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(bin, argv[1:]...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// openResponse is the JSON body of an OpenPath response.
+type openResponse struct {
+	ChunkID      int64  `json:"chunk_id"`
+	Path         string `json:"path"`
+	RelativePath string `json:"relative_path"`
+	Line         int    `json:"line"`
+	EndLine      int    `json:"end_line"`
+	Editor       string `json:"editor"`
+	URI          string `json:"uri"`
+	// Command is the command line the server ran to open the chunk, set
+	// only by a POST.
+	Command []string `json:"command,omitempty"`
+}
+
+// serveOpen answers GET OpenPath?chunk_id=N[&editor=name][&redirect=true]
+// with the location of an indexed chunk and a URI that opens it in the
+// editor, so a web page showing search results can link straight to the
+// code. With redirect the response is a 302 to that URI instead. The editor
+// defaults to server.editor. A POST opens the chunk itself, by running the
+// editor's command on the server; it is only accepted from a client on the
+// same machine, and never by a read-only server.
+func (s *SDKServer) serveOpen(w http.ResponseWriter, r *http.Request) {
+	launch := false
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		launch = true
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if launch && (s.readOnly || !loopbackClient(r)) {
+		http.Error(w, "opening an editor on the server is only allowed from the same machine", http.StatusForbidden)
+		return
+	}
+	query := r.URL.Query()
+	chunkID, err := strconv.ParseInt(query.Get("chunk_id"), 10, 64)
+	if err != nil || chunkID <= 0 {
+		http.Error(w, "chunk_id must be a positive integer", http.StatusBadRequest)
+		return
+	}
+	redirect := false
+	if raw := query.Get("redirect"); raw != "" {
+		if redirect, err = strconv.ParseBool(raw); err != nil {
+			http.Error(w, "redirect must be true or false", http.StatusBadRequest)
+			return
+		}
+	}
+
+	state, err := s.acquireResourceSnapshot(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	defer state.release()
+	editor := query.Get("editor")
+	fromConfig := false
+	if editor == "" && state.cfg != nil && state.cfg.Server.Editor != "" {
+		editor, fromConfig = state.cfg.Server.Editor, true
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+	spec, err := lookupEditor(editor, fromConfig)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	chunk, err := state.database.Backend().GetChunkByID(chunkID)
+	if err != nil || chunk == nil || (chunk.ProjectRoot != "" && chunk.ProjectRoot != state.projectRoot) {
+		http.Error(w, fmt.Sprintf("chunk %d is not indexed", chunkID), http.StatusNotFound)
+		return
+	}
+
+	path := chunk.FilePath
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(state.projectRoot, chunk.RelativePath)
+	}
+	resp := openResponse{
+		ChunkID:      chunkID,
+		Path:         path,
+		RelativePath: chunk.RelativePath,
+		Line:         chunk.StartLine,
+		EndLine:      chunk.EndLine,
+		Editor:       editor,
+		URI:          editorURI(spec.uri, path, chunk.StartLine),
+	}
+	if launch {
+		if spec.command == nil {
+			http.Error(w, fmt.Sprintf("editor %q has no command to run; open its uri instead", editor), http.StatusBadRequest)
+			return
+		}
+		resp.Command = expandEditorArgs(spec.command, path, chunk.StartLine)
+		if err := startEditor(resp.Command); err != nil {
+			http.Error(w, fmt.Sprintf("open editor: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if redirect && !launch {
+		http.Redirect(w, r, resp.URI, http.StatusFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// lookupEditor returns the spec of a named editor. An editor from
+// server.editor (fromConfig) may instead be a URI template holding {path}
+// whose scheme is in editorSchemes; a template has no command.
+func lookupEditor(editor string, fromConfig bool) (editorSpec, error) {
+	if spec, ok := editors[editor]; ok {
+		return spec, nil
+	}
+	if fromConfig && strings.Contains(editor, "{path}") {
+		scheme, _, _ := strings.Cut(editor, ":")
+		if !editorSchemes[strings.ToLower(scheme)] {
+			return editorSpec{}, fmt.Errorf("server.editor template scheme %q is not an editor scheme", scheme)
+		}
+		return editorSpec{uri: editor}, nil
+	}
+	names := make([]string, 0, len(editors))
+	for name := range editors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if fromConfig {
+		return editorSpec{}, fmt.Errorf("unknown server.editor %q: want %s, or a URI template with {path}", editor, strings.Join(names, ", "))
+	}
+	return editorSpec{}, fmt.Errorf("unknown editor %q: want %s", editor, strings.Join(names, ", "))
+}
+
+// editorURI fills a URI template. The path is written with forward slashes
+// and a leading slash, as file URIs want it on Windows too, and escaped.
+func editorURI(template, path string, line int) string {
+	slashed := filepath.ToSlash(path)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	escaped := (&url.URL{Path: slashed}).EscapedPath()
+	return strings.NewReplacer("{path}", escaped, "{line}", strconv.Itoa(line), "{column}", "1").Replace(template)
+}
+
+// expandEditorArgs fills an editor command's arguments.
+func expandEditorArgs(command []string, path string, line int) []string {
+	replacer := strings.NewReplacer("{path}", path, "{line}", strconv.Itoa(line), "{column}", "1")
+	argv := make([]string, len(command))
+	for i, arg := range command {
+		argv[i] = replacer.Replace(arg)
+	}
+	return argv
+}
+
+// loopbackClient reports whether a request came from the server's own
+// machine.
+func loopbackClient(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+)
+
+const (
+	// IndexProgressPath streams indexing progress as server-sent events (see
+	// serveIndexProgress).
+	IndexProgressPath = "/api/index/progress"
+
+	// progressInterval is the least time between two progress events of one
+	// run; the indexer reports far more often than a display can use.
+	progressInterval = 100 * time.Millisecond
+	// progressHeartbeat is how often an idle stream sends a comment, so
+	// proxies and clients do not time it out between runs.
+	progressHeartbeat = 15 * time.Second
+)
+
+// indexProgressEvent is the data of a "progress" event: the counters of
+// index.Progress for the running index. Job is the REST job running it,
+// empty for a vecgrep_index tool call.
+type indexProgressEvent struct {
+	Job            string `json:"job,omitempty"`
+	Phase          string `json:"phase"`
+	WalkedFiles    int    `json:"walked_files"`
+	QueuedFiles    int    `json:"queued_files"`
+	ProcessedFiles int    `json:"processed_files"`
+	SkippedFiles   int    `json:"skipped_files"`
+	TotalChunks    int    `json:"total_chunks"`
+	CurrentFile    string `json:"current_file,omitempty"`
+	BytesWalked    int64  `json:"bytes_walked"`
+	BytesQueued    int64  `json:"bytes_queued"`
+	BytesProcessed int64  `json:"bytes_processed"`
+	WalkComplete   bool   `json:"walk_complete"`
+	ElapsedMS      int64  `json:"elapsed_ms"`
+	Errors         int    `json:"errors,omitempty"`
+}
+
+// indexDoneEvent is the data of a "done" event, sent when a run ends.
+type indexDoneEvent struct {
+	Job    string `json:"job,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// progressMessage is one server-sent event, already encoded.
+type progressMessage struct {
+	event string
+	data  []byte
+}
+
+// progressHub fans index progress out to every IndexProgressPath stream. A
+// slow subscriber misses intermediate events rather than holding up the
+// indexer: each keeps only the latest one it has not read. A nil hub drops
+// everything.
+type progressHub struct {
+	mu          sync.Mutex
+	subscribers map[chan progressMessage]struct{}
+	// current is the latest progress event of the running index, sent to
+	// streams that open mid-run; nil between runs.
+	current   *progressMessage
+	lastSent  time.Time
+	lastPhase index.ProgressPhase
+}
+
+func newProgressHub() *progressHub {
+	return &progressHub{subscribers: make(map[chan progressMessage]struct{})}
+}
+
+// callback returns an index.ProgressCallback publishing a run's progress
+// tagged with job, or nil when h is nil.
+func (h *progressHub) callback(job string) index.ProgressCallback {
+	if h == nil {
+		return nil
+	}
+	return func(p index.Progress) {
+		h.publishProgress(job, p)
+	}
+}
+
+func (h *progressHub) publishProgress(job string, p index.Progress) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// A run's first event and phase changes always go out; within a phase,
+	// events are spaced by progressInterval.
+	now := time.Now()
+	if h.current != nil && now.Sub(h.lastSent) < progressInterval && p.Phase == h.lastPhase {
+		return
+	}
+	event := indexProgressEvent{
+		Job:            job,
+		Phase:          string(p.Phase),
+		WalkedFiles:    p.WalkedFiles,
+		QueuedFiles:    p.QueuedFiles,
+		ProcessedFiles: p.ProcessedFiles,
+		SkippedFiles:   p.SkippedFiles,
+		TotalChunks:    p.TotalChunks,
+		CurrentFile:    p.CurrentFile,
+		BytesWalked:    p.BytesWalked,
+		BytesQueued:    p.BytesQueued,
+		BytesProcessed: p.BytesProcessed,
+		WalkComplete:   p.WalkComplete,
+		Errors:         len(p.Errors),
+	}
+	if !p.StartTime.IsZero() {
+		event.ElapsedMS = now.Sub(p.StartTime).Milliseconds()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	msg := progressMessage{event: "progress", data: data}
+	h.current, h.lastSent, h.lastPhase = &msg, now, p.Phase
+	h.broadcastLocked(msg)
+}
+
+// finish publishes the end of a run, with err nil when it succeeded.
+func (h *progressHub) finish(job string, err error) {
+	if h == nil {
+		return
+	}
+	event := indexDoneEvent{Job: job, Status: jobSucceeded}
+	if err != nil {
+		event.Status, event.Error = jobFailed, err.Error()
+	}
+	data, _ := json.Marshal(event)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.current = nil
+	h.broadcastLocked(progressMessage{event: "done", data: data})
+}
+
+// broadcastLocked hands msg to every subscriber, replacing an unread event.
+// The caller must hold h.mu.
+func (h *progressHub) broadcastLocked(msg progressMessage) {
+	for ch := range h.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- msg
+	}
+}
+
+// subscribe registers a stream, returning its channel, the running index's
+// latest event if any, and a function that unregisters it.
+func (h *progressHub) subscribe() (<-chan progressMessage, *progressMessage, func()) {
+	ch := make(chan progressMessage, 1)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subscribers[ch] = struct{}{}
+	current := h.current
+	return ch, current, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers, ch)
+	}
+}
+
+// serveIndexProgress answers GET IndexProgressPath with a server-sent event
+// stream of the server's index runs, whether started at IndexAPIPath or by
+// the vecgrep_index tool: a "progress" event carrying file and chunk
+// counters as the run goes, and a "done" event when it ends. A stream opened
+// mid-run starts with the latest progress. Runs delegated to a daemon report
+// only "done", since the daemon does not share its progress.
+func (s *SDKServer) serveIndexProgress(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	events, current, unsubscribe := s.progress.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if current != nil {
+		writeProgressMessage(w, *current)
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(progressHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg := <-events:
+			writeProgressMessage(w, msg)
+		case <-heartbeat.C:
+			_, _ = fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+func writeProgressMessage(w http.ResponseWriter, msg progressMessage) {
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.event, msg.data)
+}
+
+// jobIDKey is the context key of the REST job a piece of work runs as.
+type jobIDKey struct{}
+
+// jobIDFrom returns the REST job ctx runs as, or "".
+func jobIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(jobIDKey{}).(string)
+	return id
+}
@@ -0,0 +1,1272 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHTTPHandlerServesStreamableMCP(t *testing.T) {
+	s := NewSDKServer(SDKServerConfig{})
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "test", Version: "0"}, nil)
+	session, err := client.Connect(context.Background(), &sdkmcp.StreamableClientTransport{Endpoint: server.URL + HTTPPath}, nil)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	found := false
+	for _, tool := range tools.Tools {
+		found = found || tool.Name == "vecgrep_search"
+	}
+	if !found {
+		t.Fatalf("vecgrep_search not listed over HTTP: %d tools", len(tools.Tools))
+	}
+	templates, err := session.ListResourceTemplates(context.Background(), nil)
+	if err != nil || len(templates.ResourceTemplates) == 0 {
+		t.Fatalf("list resource templates = %+v, %v", templates, err)
+	}
+}
+
+func TestReadOnlyServerOmitsWriteTools(t *testing.T) {
+	s := NewSDKServer(SDKServerConfig{ReadOnly: true})
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "test", Version: "0"}, nil)
+	session, err := client.Connect(context.Background(), &sdkmcp.StreamableClientTransport{Endpoint: server.URL + HTTPPath}, nil)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	tools, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	listed := make(map[string]bool)
+	for _, tool := range tools.Tools {
+		listed[tool.Name] = true
+	}
+	if !listed["vecgrep_search"] || !listed["vecgrep_status"] {
+		t.Errorf("read-only tools = %v, want vecgrep_search and vecgrep_status kept", listed)
+	}
+	for _, name := range readOnlyExcludedTools {
+		if listed[name] {
+			t.Errorf("read-only server lists %s", name)
+		}
+	}
+}
+
+func TestHTTPHandlerSignsBrowsersInWithCookie(t *testing.T) {
+	s := NewSDKServer(SDKServerConfig{AuthToken: "s3cret"})
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+
+	resp, err := client.Get(server.URL + SuggestPath + "?q=load&token=wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || len(resp.Cookies()) != 0 {
+		t.Fatalf("wrong token status = %d, cookies %v; want 401 and none", resp.StatusCode, resp.Cookies())
+	}
+
+	resp, err = client.Get(server.URL + SuggestPath + "?q=load&token=s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSeeOther || resp.Header.Get("Location") != SuggestPath+"?q=load" {
+		t.Fatalf("sign-in status = %d, Location %q; want 303 to the URL without the token", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	cookies := resp.Cookies()
+	if len(cookies) != 1 || cookies[0].Name != authCookieName || !cookies[0].HttpOnly {
+		t.Fatalf("sign-in cookies = %v", cookies)
+	}
+
+	for _, tc := range []struct {
+		cookie string
+		want   int
+	}{
+		{"s3cret", http.StatusMethodNotAllowed},
+		{"wrong", http.StatusUnauthorized},
+	} {
+		req, err := http.NewRequest(http.MethodPost, server.URL+SuggestPath, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.AddCookie(&http.Cookie{Name: authCookieName, Value: tc.cookie})
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("cookie %q status = %d, want %d", tc.cookie, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestLoadOrCreateAuthTokenKeepsTheFirstToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", AuthTokenFile)
+	token, created, err := LoadOrCreateAuthToken(path)
+	if err != nil || !created || token == "" {
+		t.Fatalf("first load = %q, %v, %v; want a new token", token, created, err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 && runtime.GOOS != "windows" {
+		t.Errorf("token file mode = %o, want 600", perm)
+	}
+	again, created, err := LoadOrCreateAuthToken(path)
+	if err != nil || created || again != token {
+		t.Fatalf("second load = %q, %v, %v; want %q kept", again, created, err, token)
+	}
+}
+
+func TestHTTPHandlerRequiresBearerToken(t *testing.T) {
+	s := NewSDKServer(SDKServerConfig{AuthToken: "s3cret"})
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	for _, tc := range []struct {
+		authorization string
+		want          int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Basic s3cret", http.StatusUnauthorized},
+		{"bearer s3cret", http.StatusMethodNotAllowed},
+	} {
+		req, err := http.NewRequest(http.MethodPost, server.URL+SuggestPath, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.authorization != "" {
+			req.Header.Set("Authorization", tc.authorization)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("Authorization %q status = %d, want %d", tc.authorization, resp.StatusCode, tc.want)
+		}
+		if tc.want == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+			t.Errorf("Authorization %q: 401 without WWW-Authenticate", tc.authorization)
+		}
+	}
+
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "test", Version: "0"}, nil)
+	transport := &sdkmcp.StreamableClientTransport{
+		Endpoint:   server.URL + HTTPPath,
+		HTTPClient: &http.Client{Transport: bearerTransport{token: "s3cret"}},
+	}
+	session, err := client.Connect(context.Background(), transport, nil)
+	if err != nil {
+		t.Fatalf("connect with token: %v", err)
+	}
+	_ = session.Close()
+}
+
+func TestClientLimiter(t *testing.T) {
+	limiter := newClientLimiter(60)
+	now := time.Now()
+	allowed := 0
+	for range 30 {
+		if limiter.allow("10.0.0.1:5000", now) {
+			allowed++
+		}
+	}
+	if allowed != 15 {
+		t.Errorf("burst allowed %d requests, want 15", allowed)
+	}
+	if !limiter.allow("10.0.0.2:5000", now) {
+		t.Error("a second client was limited by the first")
+	}
+	if !limiter.allow("10.0.0.1:6000", now.Add(time.Second)) {
+		t.Error("client still limited after a request's worth of refill")
+	}
+
+	limiter.allow("10.0.0.3:5000", now.Add(time.Second))
+	limiter.allow("10.0.0.2:5000", now.Add(clientLimiterIdle+2*time.Minute))
+	if _, ok := limiter.clients["10.0.0.3"]; ok {
+		t.Error("idle client was not dropped")
+	}
+}
+
+func TestHTTPHandlerRejectsCrossOriginRequests(t *testing.T) {
+	s := NewSDKServer(SDKServerConfig{})
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPost, server.URL+HTTPPath, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Sec-Fetch-Site", "cross-site")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("cross-origin status = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestHTTPHandlerServesSuggestions(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + SuggestPath + "?q=mai&limit=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var body suggestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Query != "mai" || len(body.Suggestions) == 0 || body.Suggestions[0].Text != "main.go" {
+		t.Fatalf("suggestions = %+v, want main.go", body)
+	}
+
+	for _, tc := range []struct {
+		method, query string
+		want          int
+	}{
+		{http.MethodPost, "?q=mai", http.StatusMethodNotAllowed},
+		{http.MethodGet, "?q=mai&limit=zero", http.StatusBadRequest},
+	} {
+		req, err := http.NewRequest(tc.method, server.URL+SuggestPath+tc.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s %s status = %d, want %d", tc.method, tc.query, resp.StatusCode, tc.want)
+		}
+	}
+}
+
+func TestHTTPHandlerRevalidatesJSONResponses(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	get := func(path string, header http.Header) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	for _, tc := range []struct {
+		path         string
+		lastModified bool
+	}{
+		{StatusPath, false},
+		{LanguagesPath, true},
+		{SuggestPath + "?q=mai", true},
+		{DeepLinkPath + "?q=main&mode=keyword", true},
+	} {
+		first := get(tc.path, nil)
+		etag := first.Header.Get("ETag")
+		if first.StatusCode != http.StatusOK || etag == "" {
+			t.Fatalf("GET %s = %d with ETag %q, want 200 with an ETag", tc.path, first.StatusCode, etag)
+		}
+		if got := first.Header.Get("Cache-Control"); got != "no-cache" {
+			t.Errorf("GET %s Cache-Control = %q, want no-cache", tc.path, got)
+		}
+		if again := get(tc.path, http.Header{"If-None-Match": {etag}}); again.StatusCode != http.StatusNotModified {
+			t.Errorf("GET %s If-None-Match %s = %d, want 304", tc.path, etag, again.StatusCode)
+		}
+		if stale := get(tc.path, http.Header{"If-None-Match": {`"stale"`}}); stale.StatusCode != http.StatusOK {
+			t.Errorf("GET %s with a stale ETag = %d, want 200", tc.path, stale.StatusCode)
+		}
+		lastModified := first.Header.Get("Last-Modified")
+		if (lastModified != "") != tc.lastModified {
+			t.Errorf("GET %s Last-Modified = %q, want set: %v", tc.path, lastModified, tc.lastModified)
+		}
+		if lastModified != "" {
+			if since := get(tc.path, http.Header{"If-Modified-Since": {lastModified}}); since.StatusCode != http.StatusNotModified {
+				t.Errorf("GET %s If-Modified-Since %s = %d, want 304", tc.path, lastModified, since.StatusCode)
+			}
+		}
+	}
+
+	if a, b := get(SuggestPath+"?q=mai", nil), get(SuggestPath+"?q=main", nil); a.Header.Get("ETag") == b.Header.Get("ETag") {
+		t.Error("different queries share an ETag")
+	}
+}
+
+func TestETagListMatches(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		want   bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"x", "abc"`, true},
+		{`*`, true},
+		{`"abcd"`, false},
+		{`abc`, false},
+	} {
+		if got := etagListMatches(tc.header, `"abc"`); got != tc.want {
+			t.Errorf("etagListMatches(%q) = %v, want %v", tc.header, got, tc.want)
+		}
+	}
+}
+
+// bearerTransport adds a bearer token, as an authenticating proxy would.
+type bearerTransport struct{ token string }
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHTTPHandlerWritesAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewSDKServer(SDKServerConfig{AuditLog: audit})
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	client := sdkmcp.NewClient(&sdkmcp.Implementation{Name: "audit-test", Version: "0"}, nil)
+	transport := &sdkmcp.StreamableClientTransport{
+		Endpoint:   server.URL + HTTPPath,
+		HTTPClient: &http.Client{Transport: bearerTransport{token: "secret-token"}},
+	}
+	session, err := client.Connect(context.Background(), transport, nil)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	_, err = session.CallTool(context.Background(), &sdkmcp.CallToolParams{
+		Name:      "vecgrep_search",
+		Arguments: map[string]any{"query": "retry backoff", "language": "go"},
+	})
+	if err != nil {
+		t.Fatalf("call tool: %v", err)
+	}
+	session.Close()
+	if err := audit.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-token") {
+		t.Fatalf("audit log leaked the bearer token: %s", data)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("audit log has %d lines, want 1:\n%s", len(lines), data)
+	}
+	var entry AuditEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Tool != "vecgrep_search" || entry.Query != "retry backoff" || entry.Filters["language"] != "go" {
+		t.Fatalf("entry = %+v, want the search call", entry)
+	}
+	if !strings.HasPrefix(entry.Client, "sha256:") || entry.ClientName != "audit-test" || entry.Session == "" {
+		t.Fatalf("caller = %q, %q, %q", entry.Client, entry.ClientName, entry.Session)
+	}
+}
+
+func TestHTTPHandlerServesDeepLinks(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	get := func(link string) deepLinkResponse {
+		t.Helper()
+		resp, err := http.Get(server.URL + link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want 200", link, resp.StatusCode)
+		}
+		var body deepLinkResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return body
+	}
+
+	body := get(DeepLinkPath + "?mode=Keyword&q=package&limit=5")
+	if body.URL != DeepLinkPath+"?limit=5&mode=keyword&q=package" {
+		t.Fatalf("canonical url = %q", body.URL)
+	}
+	if body.Bookmark.Query != "package" || body.Bookmark.Mode != "keyword" || body.Bookmark.Limit != 5 {
+		t.Fatalf("bookmark = %+v", body.Bookmark)
+	}
+	if len(body.Results) != 1 || body.Results[0].Anchor != "main.go:L1-L1" {
+		t.Fatalf("results = %+v, want main.go:L1-L1", body.Results)
+	}
+
+	linked := get(body.Results[0].URL)
+	if linked.Chunk == nil || linked.Chunk.Rank != 1 || !linked.Chunk.Exact || linked.Chunk.Content != "package main" {
+		t.Fatalf("chunk = %+v, want rank 1 exact", linked.Chunk)
+	}
+	moved := get(DeepLinkPath + "?q=package&mode=keyword&chunk=" + url.QueryEscape("main.go:L1-L3"))
+	if moved.Chunk == nil || moved.Chunk.Exact || moved.Chunk.Anchor != "main.go:L1-L1" {
+		t.Fatalf("moved chunk = %+v, want the chunk holding line 1", moved.Chunk)
+	}
+
+	bookmark, err := json.Marshal(body.Bookmark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(server.URL+DeepLinkPath, "application/json", strings.NewReader(string(bookmark)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reopened deepLinkResponse
+	err = json.NewDecoder(resp.Body).Decode(&reopened)
+	resp.Body.Close()
+	if err != nil || reopened.URL != body.URL || len(reopened.Results) != 1 {
+		t.Fatalf("reopened bookmark = %+v, %v", reopened, err)
+	}
+
+	for _, link := range []string{
+		DeepLinkPath + "?mode=keyword",
+		DeepLinkPath + "?q=package&limit=-1",
+		DeepLinkPath + "?q=package&colour=red",
+		DeepLinkPath + "?q=package&chunk=main.go",
+	} {
+		resp, err := http.Get(server.URL + link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", link, resp.StatusCode)
+		}
+	}
+}
+
+func TestHTTPHandlerServesDeepLinkPages(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	get := func(link, accept string) (*http.Response, string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, server.URL+link, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s status = %d, want 200: %s", link, resp.StatusCode, body)
+		}
+		return resp, string(body)
+	}
+
+	const browser = "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+	link := DeepLinkPath + "?q=package&mode=keyword&limit=5&chunk=" + url.QueryEscape("main.go:L1-L1")
+	resp, body := get(link, browser)
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/html") {
+		t.Fatalf("Content-Type = %q, want an HTML page for a browser", ct)
+	}
+	if resp.Header.Get("Vary") != "Accept" || resp.Header.Get("ETag") != "" {
+		t.Errorf("Vary = %q, ETag = %q, want a page negotiated on Accept and not revalidated", resp.Header.Get("Vary"), resp.Header.Get("ETag"))
+	}
+	for _, want := range []string{
+		`<section class="result linked" id="main.go:L1-L1">`,
+		`main.go:1-1</a>`,
+		` autofocus>`,
+		`scrollIntoView`,
+		`e.key === "j"`,
+		`name="q" value="package"`,
+		`<input type="hidden" name="limit" value="5">`,
+		`<input type="hidden" name="mode" value="keyword">`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("page lacks %q:\n%s", want, body)
+		}
+	}
+
+	moved, body := get(DeepLinkPath+"?q=nothing+matches+this&mode=keyword&chunk="+url.QueryEscape("main.go:L1-L3"), browser)
+	if !strings.HasPrefix(moved.Header.Get("Content-Type"), "text/html") || !strings.Contains(body, "<h2>Linked chunk</h2>") || !strings.Contains(body, "No chunk spans main.go:L1-L3 any more") || !strings.Contains(body, " autofocus>") {
+		t.Errorf("page for an unranked, moved chunk lacks the linked chunk:\n%s", body)
+	}
+
+	for _, accept := range []string{"", "*/*", "application/json", "application/json, text/html;q=0.5"} {
+		resp, body := get(link, accept)
+		var decoded deepLinkResponse
+		if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") || json.Unmarshal([]byte(body), &decoded) != nil || decoded.Chunk == nil {
+			t.Errorf("Accept %q: Content-Type = %q, want the JSON response", accept, resp.Header.Get("Content-Type"))
+		}
+	}
+}
+
+func TestHTTPHandlerServesContext(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + ContextPath + "?q=package&mode=keyword&budget=500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var block search.ContextBlock
+	if err := json.NewDecoder(resp.Body).Decode(&block); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if block.Budget != 500 || len(block.Sections) != 1 || block.Sections[0].Path != "main.go" {
+		t.Fatalf("block = %+v, want main.go within a budget of 500", block)
+	}
+	if !strings.HasPrefix(block.Text, "[1] main.go:L1\n") || !strings.Contains(block.Text, "package main") {
+		t.Errorf("text = %q, want a cited main.go section", block.Text)
+	}
+
+	post, err := http.Post(server.URL+ContextPath, "application/json", strings.NewReader(`{"query":"package","mode":"keyword","budget":500}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	post.Body.Close()
+	if post.StatusCode != http.StatusOK {
+		t.Errorf("POST status = %d, want 200", post.StatusCode)
+	}
+
+	for _, query := range []string{"?q=package&budget=-1", "?q=package&chunk=main.go:L1", "?q=package&context_lines=2", "?budget=100", "?q=package&color=red"} {
+		resp, err := http.Get(server.URL + ContextPath + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", query, resp.StatusCode)
+		}
+	}
+}
+
+func TestHTTPHandlerServesEditorLinks(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+	var launched []string
+	defer func(orig func([]string) error) { startEditor = orig }(startEditor)
+	startEditor = func(argv []string) error {
+		launched = argv
+		return nil
+	}
+
+	resp, err := http.Get(server.URL + DeepLinkPath + "?q=package&mode=keyword")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found deepLinkResponse
+	err = json.NewDecoder(resp.Body).Decode(&found)
+	resp.Body.Close()
+	if err != nil || len(found.Results) != 1 {
+		t.Fatalf("search = %+v, %v", found, err)
+	}
+	id := strconv.FormatInt(found.Results[0].ChunkID, 10)
+	mainPath := filepath.Join(root, "main.go")
+
+	resp, err = http.Get(server.URL + OpenPath + "?chunk_id=" + id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var opened openResponse
+	err = json.NewDecoder(resp.Body).Decode(&opened)
+	resp.Body.Close()
+	if err != nil || opened.Path != mainPath || opened.Line != 1 || opened.Editor != "vscode" {
+		t.Fatalf("open = %+v, %v", opened, err)
+	}
+	if want := "vscode://file" + filepath.ToSlash(mainPath) + ":1:1"; opened.URI != want {
+		t.Errorf("uri = %q, want %q", opened.URI, want)
+	}
+
+	noFollow := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	// A URI template is only taken from server.editor, and only with an
+	// editor's scheme, so the endpoint cannot redirect anywhere else.
+	session.cfg.Server.Editor = "txmt://open?url=file://{path}&line={line}"
+	resp, err = noFollow.Get(server.URL + OpenPath + "?redirect=true&chunk_id=" + id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if want := "txmt://open?url=file://" + filepath.ToSlash(mainPath) + "&line=1"; resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != want {
+		t.Errorf("redirect = %d %q, want 302 %q", resp.StatusCode, resp.Header.Get("Location"), want)
+	}
+	session.cfg.Server.Editor = "https://example.com/{path}"
+	resp, err = noFollow.Get(server.URL + OpenPath + "?redirect=true&chunk_id=" + id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("https template status = %d, want 400", resp.StatusCode)
+	}
+	session.cfg.Server.Editor = ""
+
+	resp, err = http.Post(server.URL+OpenPath+"?editor=zed&chunk_id="+id, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || strings.Join(launched, " ") != "zed "+mainPath+":1:1" {
+		t.Errorf("launch = %d %q, want zed %s:1:1", resp.StatusCode, launched, mainPath)
+	}
+
+	for query, want := range map[string]int{
+		"?chunk_id=x":                    http.StatusBadRequest,
+		"?chunk_id=" + id + "&editor=ed": http.StatusBadRequest,
+		"?redirect=true&chunk_id=" + id + "&editor=" + url.QueryEscape("https://evil.example/{path}"): http.StatusBadRequest,
+		"?redirect=true&chunk_id=" + id + "&editor=" + url.QueryEscape("vscode://{path}"):             http.StatusBadRequest,
+		"?chunk_id=987654": http.StatusNotFound,
+	} {
+		resp, err := http.Get(server.URL + OpenPath + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s status = %d, want %d", query, resp.StatusCode, want)
+		}
+	}
+
+	s.readOnly = true
+	resp, err = http.Post(server.URL+OpenPath+"?chunk_id="+id, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("read-only launch status = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestHTTPHandlerServesFileBrowser(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	get := func(path string, want int) string {
+		t.Helper()
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != want {
+			t.Fatalf("GET %s status = %d, want %d: %s", path, resp.StatusCode, want, body)
+		}
+		return string(body)
+	}
+
+	list := get(FilesPagePath, http.StatusOK)
+	if !strings.Contains(list, `href="/files/main.go"`) {
+		t.Fatalf("file list does not link main.go:\n%s", list)
+	}
+
+	// main.go is not on disk, so the page is rebuilt from its chunk, and
+	// highlighted as Go.
+	page := get(FilesPagePath+"/main.go", http.StatusOK)
+	for _, want := range []string{`class="boundary" id="chunk-`, "L1–1 generic", `<span class="kn">package</span>`, ".chroma .kn", SimilarPagePath + "?chunk_id=", "could not be read"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("file page lacks %q:\n%s", want, page)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	page = get(FilesPagePath+"/main.go", http.StatusOK)
+	if !strings.Contains(page, `id="L3"`) || strings.Contains(page, "could not be read") {
+		t.Errorf("file page does not show the file on disk:\n%s", page)
+	}
+	get(FilesPagePath+"/missing.go", http.StatusNotFound)
+
+	i := strings.Index(page, SimilarPagePath+"?chunk_id=")
+	link := page[i:]
+	link = link[:strings.IndexByte(link, '"')]
+	similar := get(link, http.StatusOK)
+	if !strings.Contains(similar, "No similar code found.") {
+		t.Errorf("similar page:\n%s", similar)
+	}
+	get(SimilarPagePath+"?chunk_id=x", http.StatusBadRequest)
+	get(SimilarPagePath+"?chunk_id=987654", http.StatusNotFound)
+}
+
+func TestHighlightLinesEscapesSource(t *testing.T) {
+	lines := highlightLines("go", "main.go", []string{`s := "<b>"`, "", "// done"})
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	if !strings.Contains(string(lines[0]), `&lt;b&gt;`) || strings.Contains(string(lines[0]), "<b>") {
+		t.Errorf("line 1 is not escaped: %s", lines[0])
+	}
+	if lines[1] != "" || !strings.Contains(string(lines[2]), `class="c1"`) {
+		t.Errorf("lines = %q", lines)
+	}
+
+	plain := highlightLines("", "notes.unknown-ext", []string{"<b> & x"})
+	if plain[0] != "&lt;b&gt; &amp; x" {
+		t.Errorf("unhighlighted line = %q", plain[0])
+	}
+}
+
+func TestDeepLinkURLIsCanonical(t *testing.T) {
+	rerank := false
+	input := SearchInput{
+		Query:     "retry backoff",
+		Mode:      "semantic",
+		Languages: []string{"rust", "go", "go"},
+		MinScore:  0.25,
+		Rerank:    &rerank,
+		Limit:     7,
+	}
+	input, err := canonicalBookmark(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link := deepLinkURL(input, "internal/retry.go:L10-L42")
+	want := DeepLinkPath + "?chunk=internal%2Fretry.go%3AL10-L42&languages=go&languages=rust&limit=7&min_score=0.25&mode=semantic&q=retry+backoff&rerank=false"
+	if link != want {
+		t.Fatalf("link = %s\nwant   %s", link, want)
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := parseDeepLink(parsed.Query())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err = canonicalBookmark(again); err != nil || deepLinkURL(again, parsed.Query().Get("chunk")) != link {
+		t.Fatalf("round trip = %s, %v", deepLinkURL(again, parsed.Query().Get("chunk")), err)
+	}
+	if path, start, end, err := parseChunkAnchor("a:b.go:L3-L9"); err != nil || path != "a:b.go" || start != 3 || end != 9 {
+		t.Fatalf("parseChunkAnchor = %q %d %d %v", path, start, end, err)
+	}
+}
+
+func TestHTTPHandlerServesVectorStoreSearch(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	endpoint := server.URL + "/v1/vector_stores/vs_project/search"
+	resp, err := http.Post(endpoint, "application/json", strings.NewReader(
+		`{"query":"package main","max_num_results":5,"filters":{"type":"and","filters":[{"type":"eq","key":"language","value":"go"}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var page map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if page["object"] != "vector_store.search_results.page" || page["search_query"] != "package main" || page["has_more"] != false || page["next_page"] != nil {
+		t.Fatalf("page = %v, want a single vector store search page", page)
+	}
+	data, _ := page["data"].([]any)
+	if len(data) != 1 {
+		t.Fatalf("data = %v, want one hit", page["data"])
+	}
+	item := data[0].(map[string]any)
+	attributes, _ := item["attributes"].(map[string]any)
+	content, _ := item["content"].([]any)
+	if item["file_id"] != "main.go" || item["filename"] != "main.go" || attributes["path"] != "main.go" || attributes["language"] != "go" || len(content) != 1 {
+		t.Fatalf("item = %v, want main.go with attributes and text content", item)
+	}
+	if part := content[0].(map[string]any); part["type"] != "text" || !strings.Contains(part["text"].(string), "package main") {
+		t.Errorf("content = %v, want the chunk text", content)
+	}
+
+	for _, body := range []string{`{}`, `{"query":[1]}`, `{"query":"a","max_num_results":51}`, `{"query":"a","filters":{"type":"gt","key":"start_line","value":3}}`, `{"query":"a","filters":{"type":"eq","key":"owner","value":"me"}}`} {
+		resp, err := http.Post(endpoint, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out struct {
+			Error struct {
+				Type string `json:"type"`
+			} `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&out)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest || out.Error.Type != "invalid_request_error" {
+			t.Errorf("%s: status = %d, error = %+v, want an OpenAI 400", body, resp.StatusCode, out.Error)
+		}
+	}
+}
+
+func TestHTTPSearchEndpointsCapLargeLimits(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	session.cfg.Server.MaxResponseBytes = 16 << 10
+	database, err := session.readWriteDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 40 chunks of about 2 KB each, so any limit over ten overflows the cap.
+	for i := range 40 {
+		rel := fmt.Sprintf("big%02d.go", i)
+		content := "package big\n" + strings.Repeat(fmt.Sprintf("// filler %d\n", i), 150)
+		chunk := db.NewChunkRecord(filepath.Join(root, rel), rel, "hash-"+rel, int64(len(content)), "go", content, 1, 151, 0, len(content), "generic", "", root)
+		if _, err := database.InsertChunk(chunk, make([]float32, session.cfg.Embedding.Dimensions)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := database.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	decode := func(into any, send func() (*http.Response, error)) {
+		t.Helper()
+		resp, err := send()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s status = %d, want 200", resp.Request.URL.Path, resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(into); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	warned := func(warnings []string, want string) bool {
+		for _, w := range warnings {
+			if strings.Contains(w, want) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var link deepLinkResponse
+	decode(&link, func() (*http.Response, error) {
+		return http.Get(server.URL + DeepLinkPath + "?q=package&mode=keyword&limit=1000")
+	})
+	if n := len(link.Results); n == 0 || n >= 40 {
+		t.Fatalf("/s returned %d results, want the 41 matches trimmed to the cap", n)
+	}
+	size := 0
+	for _, r := range link.Results {
+		size += resultRenderSize(r.Result)
+	}
+	if size > 16<<10 {
+		t.Errorf("/s results render to %d bytes, want at most %d", size, 16<<10)
+	}
+	if !warned(link.Warnings, "limit 1000 exceeds the maximum of 100") || !warned(link.Warnings, "lowest-scored result(s)") {
+		t.Errorf("/s warnings = %q, want the clamped limit and the truncation noted", link.Warnings)
+	}
+
+	var block search.ContextBlock
+	decode(&block, func() (*http.Response, error) {
+		return http.Get(server.URL + ContextPath + "?q=package&mode=keyword&limit=1000&budget=100000")
+	})
+	if n := len(block.Sections); n == 0 || n >= 40 || !warned(block.Warnings, "lowest-scored result(s)") {
+		t.Errorf("/api/context packed %d sections with warnings %q, want the candidates trimmed to the cap", n, block.Warnings)
+	}
+
+	var page search.OpenAISearchPage
+	decode(&page, func() (*http.Response, error) {
+		return http.Post(server.URL+"/v1/vector_stores/vs_project/search", "application/json", strings.NewReader(`{"query":"package","max_num_results":50}`))
+	})
+	if n := len(page.Data); n == 0 || n >= 40 || !warned(page.Warnings, "lowest-scored result(s)") {
+		t.Errorf("vector store search returned %d hits with warnings %q, want them trimmed to the cap", n, page.Warnings)
+	}
+}
+
+func TestHTTPHandlerServesBookmarks(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+BookmarksAPIPath, "application/json", strings.NewReader(`{"key":"main.go:1-3","note":"entry point","tags":["start"]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var added app.Bookmark
+	_ = json.NewDecoder(resp.Body).Decode(&added)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || added.ID == 0 || added.Key != "main.go:1-3" || added.Note != "entry point" {
+		t.Fatalf("POST status = %d, bookmark = %+v, want 201 with main.go:1-3", resp.StatusCode, added)
+	}
+
+	list := func(query string) []app.Bookmark {
+		t.Helper()
+		resp, err := http.Get(server.URL + BookmarksAPIPath + query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out bookmarksResponse
+		if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&out) != nil {
+			t.Fatalf("GET %s status = %d", query, resp.StatusCode)
+		}
+		return out.Bookmarks
+	}
+	if got := list("?tag=start"); len(got) != 1 || got[0].ID != added.ID {
+		t.Fatalf("bookmarks tagged start = %+v, want the added one", got)
+	}
+	if got := list("?tag=other"); len(got) != 0 {
+		t.Fatalf("bookmarks tagged other = %+v, want none", got)
+	}
+	if stored, err := app.ListBookmarks(session.cfg.DataDir, nil); err != nil || len(stored) != 1 {
+		t.Fatalf("bookmarks.json = %+v, %v, want the added bookmark", stored, err)
+	}
+
+	readOnly := httptest.NewServer((&SDKServer{session: session, projectRoot: root, initialized: true, readOnly: true}).HTTPHandler())
+	defer readOnly.Close()
+	resp, err = http.Post(readOnly.URL+BookmarksAPIPath, "application/json", strings.NewReader(`{"key":"main.go:1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("read-only POST status = %d, want 403", resp.StatusCode)
+	}
+	del := func(base string, id uint64) int {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s%s/%d", base, BookmarksAPIPath, id), nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if status := del(readOnly.URL, added.ID); status != http.StatusForbidden {
+		t.Errorf("read-only DELETE status = %d, want 403", status)
+	}
+	if resp, err := http.Get(readOnly.URL + BookmarksAPIPath); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("read-only GET = %v, %v, want 200", resp, err)
+	} else {
+		resp.Body.Close()
+	}
+
+	if status := del(server.URL, added.ID); status != http.StatusNoContent {
+		t.Errorf("DELETE status = %d, want 204", status)
+	}
+	if status := del(server.URL, added.ID); status != http.StatusNotFound {
+		t.Errorf("second DELETE status = %d, want 404", status)
+	}
+	if got := list(""); len(got) != 0 {
+		t.Fatalf("bookmarks after delete = %+v, want none", got)
+	}
+	resp, err = http.Post(server.URL+BookmarksAPIPath, "application/json", strings.NewReader(`{"key":"main.go"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("POST without a line range status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestHTTPHandlerServesEmbeddings(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	session.provider = mcpIndexProvider{dimensions: 8, model: "test-embed"}
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+EmbeddingsPath, "application/json", strings.NewReader(`{"input":["a","b"],"model":"test-embed"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	var out struct {
+		Object string `json:"object"`
+		Model  string `json:"model"`
+		Data   []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if out.Object != "list" || out.Model != "test-embed" || len(out.Data) != 2 || out.Data[1].Index != 1 || len(out.Data[1].Embedding) != 8 {
+		t.Fatalf("response = %+v, want two 8-dimension embeddings", out)
+	}
+
+	for _, body := range []string{`{"input":"a","model":"other"}`, `{"input":"a","dimensions":4}`, `{"input":[]}`, `{"input":[1,2]}`, `{"input":"a","encoding_format":"hex"}`} {
+		resp, err := http.Post(server.URL+EmbeddingsPath, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("POST %s status = %d, want 400", body, resp.StatusCode)
+		}
+	}
+
+	readOnly := httptest.NewServer((&SDKServer{session: session, projectRoot: root, initialized: true, readOnly: true}).HTTPHandler())
+	defer readOnly.Close()
+	resp, err = http.Post(readOnly.URL+EmbeddingsPath, "application/json", strings.NewReader(`{"input":"a"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("read-only status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestEncodeEmbeddingBase64(t *testing.T) {
+	if got := encodeEmbeddingBase64([]float32{1, -2}); got != "AACAPwAAAMA=" {
+		t.Errorf("encodeEmbeddingBase64 = %q, want little-endian float32 bytes", got)
+	}
+}
+
+func TestHTTPHandlerRunsIndexJobs(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+FilesAPIPath+"main.go", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var job httpJob
+	err = json.NewDecoder(resp.Body).Decode(&job)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusAccepted || job.Kind != "delete" || job.ID == "" {
+		t.Fatalf("DELETE = %d %+v, %v; want 202 with a delete job", resp.StatusCode, job, err)
+	}
+	if location := resp.Header.Get("Location"); location != JobsPath+"/"+job.ID {
+		t.Errorf("Location = %q, want the job URL", location)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != jobSucceeded && job.Status != jobFailed && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		resp, err := http.Get(server.URL + JobsPath + "/" + job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&job)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, _ := job.Result.(map[string]any)
+	if job.Status != jobSucceeded || result["chunks_deleted"] != float64(1) {
+		t.Fatalf("job = %+v, want one chunk deleted", job)
+	}
+
+	for _, tc := range []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodPost, ResetAPIPath, `{}`, http.StatusBadRequest},
+		{http.MethodPost, IndexAPIPath, `{"paths":"main.go"}`, http.StatusBadRequest},
+		{http.MethodGet, IndexAPIPath, ``, http.StatusMethodNotAllowed},
+		{http.MethodGet, JobsPath + "/missing", ``, http.StatusNotFound},
+	} {
+		req, err := http.NewRequest(tc.method, server.URL+tc.path, strings.NewReader(tc.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s %s %s = %d, want %d", tc.method, tc.path, tc.body, resp.StatusCode, tc.want)
+		}
+	}
+
+	readOnly := httptest.NewServer((&SDKServer{session: session, projectRoot: root, initialized: true, readOnly: true}).HTTPHandler())
+	defer readOnly.Close()
+	resp, err = http.Post(readOnly.URL+CleanAPIPath, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("read-only clean status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestJobRegistryKeepsUnfinishedJobs(t *testing.T) {
+	r := newJobRegistry()
+	now := time.Now()
+	for i := range maxRetainedJobs + 2 {
+		id := fmt.Sprintf("job-%d", i)
+		job := &httpJob{ID: id, Status: jobSucceeded, Finished: &now}
+		if i == 0 {
+			job.Status, job.Finished = jobRunning, nil
+		}
+		r.jobs[id] = job
+		r.order = append(r.order, id)
+	}
+	r.pruneLocked()
+	if len(r.order) != maxRetainedJobs || r.order[0] != "job-0" || r.order[1] != "job-3" {
+		t.Fatalf("retained %d jobs starting %v, want the running job kept and the two oldest finished dropped", len(r.order), r.order[:2])
+	}
+}
+
+func TestHTTPHandlerStreamsIndexProgress(t *testing.T) {
+	s := NewSDKServer(SDKServerConfig{})
+	server := httptest.NewServer(s.HTTPHandler())
+	defer server.Close()
+
+	// A stream opened mid-run starts with the run's latest progress.
+	s.progress.callback("job-1")(index.Progress{Phase: index.PhaseEmbed, QueuedFiles: 4, ProcessedFiles: 2, TotalChunks: 9})
+	resp, err := http.Get(server.URL + IndexProgressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET = %d %q, want an event stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (string, map[string]any) {
+		t.Helper()
+		var name string
+		var data map[string]any
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			line = strings.TrimSuffix(line, "\n")
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				name = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &data); err != nil {
+					t.Fatal(err)
+				}
+			case line == "" && name != "":
+				return name, data
+			}
+		}
+	}
+	name, data := readEvent()
+	if name != "progress" || data["job"] != "job-1" || data["processed_files"] != float64(2) || data["total_chunks"] != float64(9) {
+		t.Fatalf("first event = %s %v", name, data)
+	}
+
+	s.progress.finish("job-1", nil)
+	name, data = readEvent()
+	if name != "done" || data["job"] != "job-1" || data["status"] != jobSucceeded {
+		t.Fatalf("last event = %s %v", name, data)
+	}
+}
@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+const (
+	// defaultVectorStoreResults and maxVectorStoreResults are the default
+	// and the cap of max_num_results, matching the OpenAI API.
+	defaultVectorStoreResults = 10
+	maxVectorStoreResults     = 50
+	// maxVectorStoreRequestBytes caps a VectorStoreSearchPath request body.
+	maxVectorStoreRequestBytes = 1 << 20
+)
+
+// vectorStoreSearchRequest is the JSON body of an OpenAI vector store
+// search. Query is a string or an array of strings, which are searched as
+// one query.
+type vectorStoreSearchRequest struct {
+	Query          json.RawMessage            `json:"query"`
+	MaxNumResults  int                        `json:"max_num_results,omitempty"`
+	Filters        *vectorStoreFilter         `json:"filters,omitempty"`
+	RankingOptions *vectorStoreRankingOptions `json:"ranking_options,omitempty"`
+	RewriteQuery   bool                       `json:"rewrite_query,omitempty"`
+}
+
+// vectorStoreFilter is an OpenAI attribute filter: a comparison ("eq" of
+// Key and Value) or a compound ("and" of Filters).
+type vectorStoreFilter struct {
+	Type    string              `json:"type"`
+	Key     string              `json:"key,omitempty"`
+	Value   any                 `json:"value,omitempty"`
+	Filters []vectorStoreFilter `json:"filters,omitempty"`
+}
+
+// vectorStoreRankingOptions are a search's ranking options. The ranker is
+// accepted for compatibility; vecgrep ranks by its configured search mode.
+type vectorStoreRankingOptions struct {
+	Ranker         string  `json:"ranker,omitempty"`
+	ScoreThreshold float32 `json:"score_threshold,omitempty"`
+}
+
+// serveVectorStoreSearch answers POST VectorStoreSearchPath with a
+// search.OpenAISearchPage, so RAG clients written against OpenAI's vector
+// store search can use vecgrep as their retriever. The served project is the
+// only store, so any store id names it. Filters may compare the language,
+// chunk_type, and path attributes with "eq", alone or joined by "and";
+// score_threshold becomes min_score, and rewrite_query searches paraphrases
+// of the query as expand does. Results over server.max_response_bytes are
+// dropped, lowest-scored first, and noted in the page's warnings. Errors use
+// the OpenAI error shape.
+func (s *SDKServer) serveVectorStoreSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "method not allowed", "")
+		return
+	}
+	var req vectorStoreSearchRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxVectorStoreRequestBytes))
+	if err := decoder.Decode(&req); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, fmt.Sprintf("invalid request: %v", err), "")
+		return
+	}
+	input, param, err := vectorStoreSearchInput(req)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, err.Error(), param)
+		return
+	}
+
+	raw, _ := json.Marshal(input)
+	var filters map[string]any
+	_ = json.Unmarshal(raw, &filters)
+	delete(filters, "query")
+	r, done := s.auditHTTP(r, "vector_store_search", input.Query, filters)
+	defer done()
+
+	state, err := s.acquireResourceSnapshot(r.Context())
+	if err != nil {
+		writeOpenAIError(w, http.StatusServiceUnavailable, err.Error(), "")
+		return
+	}
+	defer state.release()
+	readiness, err := serviceFromRead(state).Readiness(r.Context())
+	if err != nil {
+		writeOpenAIError(w, http.StatusInternalServerError, fmt.Sprintf("compute index readiness: %v", err), "")
+		return
+	}
+	if readiness.BlocksSearch() {
+		writeOpenAIError(w, http.StatusServiceUnavailable, fmt.Sprintf("index is not searchable (%s)", readiness.State), "")
+		return
+	}
+	results, _, warnings, err := state.httpSearch(r.Context(), input)
+	if err != nil {
+		writeOpenAIError(w, http.StatusInternalServerError, err.Error(), "")
+		return
+	}
+	auditResults(r.Context(), len(results))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	page := search.NewOpenAISearchPage(input.Query, results)
+	page.Warnings = warnings
+	_ = json.NewEncoder(w).Encode(page)
+}
+
+// vectorStoreSearchInput translates a vector store search into
+// vecgrep_search arguments. On error it also returns the offending field.
+func vectorStoreSearchInput(req vectorStoreSearchRequest) (SearchInput, string, error) {
+	var input SearchInput
+	query, err := vectorStoreQuery(req.Query)
+	if err != nil {
+		return input, "query", err
+	}
+	input.Query = query
+	switch {
+	case req.MaxNumResults == 0:
+		input.Limit = defaultVectorStoreResults
+	case req.MaxNumResults < 1 || req.MaxNumResults > maxVectorStoreResults:
+		return input, "max_num_results", fmt.Errorf("max_num_results must be between 1 and %d", maxVectorStoreResults)
+	default:
+		input.Limit = req.MaxNumResults
+	}
+	if req.Filters != nil {
+		if err := applyVectorStoreFilter(&input, *req.Filters); err != nil {
+			return input, "filters", err
+		}
+	}
+	if req.RankingOptions != nil {
+		if t := req.RankingOptions.ScoreThreshold; t < 0 || t > 1 {
+			return input, "ranking_options.score_threshold", errors.New("score_threshold must be between 0 and 1")
+		}
+		input.MinScore = req.RankingOptions.ScoreThreshold
+	}
+	input.Expand = req.RewriteQuery
+	return input, "", nil
+}
+
+// vectorStoreQuery decodes a search's query: a string, or an array of
+// strings joined with spaces.
+func vectorStoreQuery(raw json.RawMessage) (string, error) {
+	raw = bytes.TrimSpace(raw)
+	var parts []string
+	if len(raw) > 0 && raw[0] == '[' {
+		if err := json.Unmarshal(raw, &parts); err != nil {
+			return "", errors.New("query must be a string or an array of strings")
+		}
+	} else if len(raw) > 0 && !bytes.Equal(raw, []byte("null")) {
+		var query string
+		if err := json.Unmarshal(raw, &query); err != nil {
+			return "", errors.New("query must be a string or an array of strings")
+		}
+		parts = []string{query}
+	}
+	query := strings.TrimSpace(strings.Join(parts, " "))
+	if query == "" {
+		return "", errors.New("query is required")
+	}
+	return query, nil
+}
+
+// applyVectorStoreFilter narrows input by an attribute filter. A key given
+// twice under "and" is refused, since no chunk could match both values.
+func applyVectorStoreFilter(input *SearchInput, filter vectorStoreFilter) error {
+	switch filter.Type {
+	case "and":
+		for _, f := range filter.Filters {
+			if err := applyVectorStoreFilter(input, f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "eq":
+	default:
+		return fmt.Errorf("filter type %q is not supported; use eq or and", filter.Type)
+	}
+	value, ok := filter.Value.(string)
+	if !ok || value == "" {
+		return fmt.Errorf("filter on %q needs a non-empty string value", filter.Key)
+	}
+	var field *string
+	switch filter.Key {
+	case "language":
+		field = &input.Language
+	case "chunk_type":
+		field = &input.ChunkType
+	case "path":
+		if len(input.FilePaths) > 0 {
+			return errors.New(`filter on "path" is given twice`)
+		}
+		input.FilePaths = []string{value}
+		return nil
+	default:
+		return fmt.Errorf("filter key %q is not supported; use language, chunk_type, or path", filter.Key)
+	}
+	if *field != "" {
+		return fmt.Errorf("filter on %q is given twice", filter.Key)
+	}
+	*field = value
+	return nil
+}
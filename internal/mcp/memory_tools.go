@@ -18,6 +18,13 @@ func (s *SDKServer) handleMemoryRemember(ctx context.Context, req *sdkmcp.CallTo
 			IsError: true,
 		}, nil, nil
 	}
+	store, scope, err := s.memoryStoreFor(ctx, input.Scope)
+	if err != nil {
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
 
 	if input.Content == "" {
 		return &sdkmcp.CallToolResult{
@@ -32,7 +39,7 @@ func (s *SDKServer) handleMemoryRemember(ctx context.Context, req *sdkmcp.CallTo
 		TTLHours:   input.TTLHours,
 	}
 
-	id, err := s.memoryStore.Remember(ctx, input.Content, opts)
+	id, err := store.Remember(ctx, input.Content, opts)
 	if err != nil {
 		return &sdkmcp.CallToolResult{
 			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Failed to store memory: %v", err)}},
@@ -44,6 +51,7 @@ func (s *SDKServer) handleMemoryRemember(ctx context.Context, req *sdkmcp.CallTo
 	fmt.Fprintf(&sb, "Memory stored successfully (ID: %d)\n\n", id)
 	fmt.Fprintf(&sb, "- Content: %s\n", truncateString(input.Content, 100))
 	fmt.Fprintf(&sb, "- Importance: %.2f\n", opts.Importance)
+	fmt.Fprintf(&sb, "- Scope: %s\n", scope)
 	if len(opts.Tags) > 0 {
 		fmt.Fprintf(&sb, "- Tags: %s\n", strings.Join(opts.Tags, ", "))
 	}
@@ -64,6 +72,13 @@ func (s *SDKServer) handleMemoryRecall(ctx context.Context, req *sdkmcp.CallTool
 			IsError: true,
 		}, nil, nil
 	}
+	store, scope, err := s.memoryStoreFor(ctx, input.Scope)
+	if err != nil {
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
 
 	if input.Query == "" {
 		return &sdkmcp.CallToolResult{
@@ -72,13 +87,22 @@ func (s *SDKServer) handleMemoryRecall(ctx context.Context, req *sdkmcp.CallTool
 		}, nil, nil
 	}
 
+	since, until, err := memory.ParseTimeWindow(input.Since, input.Until, time.Now())
+	if err != nil {
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
 	opts := memory.RecallOptions{
 		Limit:         input.Limit,
 		Tags:          input.Tags,
 		MinImportance: input.MinImportance,
+		Since:         since,
+		Until:         until,
 	}
 
-	memories, err := s.memoryStore.Recall(ctx, input.Query, opts)
+	memories, err := store.Recall(ctx, input.Query, opts)
 	if err != nil {
 		return &sdkmcp.CallToolResult{
 			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Search failed: %v", err)}},
@@ -93,7 +117,7 @@ func (s *SDKServer) handleMemoryRecall(ctx context.Context, req *sdkmcp.CallTool
 	}
 
 	var sb strings.Builder
-	fmt.Fprintf(&sb, "Found %d memories:\n\n", len(memories))
+	fmt.Fprintf(&sb, "Found %d %s memories:\n\n", len(memories), scope)
 
 	for i, m := range memories {
 		fmt.Fprintf(&sb, "### Memory %d (ID: %d, score: %.2f)\n", i+1, m.ID, m.Score)
@@ -123,11 +147,18 @@ func (s *SDKServer) handleMemoryForget(ctx context.Context, req *sdkmcp.CallTool
 			IsError: true,
 		}, nil, nil
 	}
+	store, scope, err := s.memoryStoreFor(ctx, input.Scope)
+	if err != nil {
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
 
 	// Single ID deletion doesn't require confirmation
 	if input.ID > 0 {
 		opts := memory.ForgetOptions{ID: input.ID}
-		deleted, err := s.memoryStore.Forget(ctx, opts)
+		deleted, err := store.Forget(ctx, opts)
 		if err != nil {
 			return &sdkmcp.CallToolResult{
 				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Failed to delete memory: %v", err)}},
@@ -135,7 +166,7 @@ func (s *SDKServer) handleMemoryForget(ctx context.Context, req *sdkmcp.CallTool
 			}, nil, nil
 		}
 		return &sdkmcp.CallToolResult{
-			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Deleted memory ID %d (%d memories removed)", input.ID, deleted)}},
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Deleted memory ID %d from the %s store (%d memories removed)", input.ID, scope, deleted)}},
 		}, nil, nil
 	}
 
@@ -161,7 +192,7 @@ func (s *SDKServer) handleMemoryForget(ctx context.Context, req *sdkmcp.CallTool
 			Tags:           input.Tags,
 			OlderThanHours: input.OlderThanHours,
 		}
-		deleted, err := s.memoryStore.Forget(ctx, opts)
+		deleted, err := store.Forget(ctx, opts)
 		if err != nil {
 			return &sdkmcp.CallToolResult{
 				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Failed to delete memories: %v", err)}},
@@ -169,7 +200,7 @@ func (s *SDKServer) handleMemoryForget(ctx context.Context, req *sdkmcp.CallTool
 			}, nil, nil
 		}
 		return &sdkmcp.CallToolResult{
-			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Deleted %d memories", deleted)}},
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Deleted %d memories from the %s store", deleted, scope)}},
 		}, nil, nil
 	}
 
@@ -187,8 +218,15 @@ func (s *SDKServer) handleMemoryStats(ctx context.Context, req *sdkmcp.CallToolR
 			IsError: true,
 		}, nil, nil
 	}
+	store, scope, err := s.memoryStoreFor(ctx, input.Scope)
+	if err != nil {
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
 
-	stats, err := s.memoryStore.Stats(ctx)
+	stats, err := store.Stats(ctx)
 	if err != nil {
 		return &sdkmcp.CallToolResult{
 			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Failed to get stats: %v", err)}},
@@ -197,7 +235,7 @@ func (s *SDKServer) handleMemoryStats(ctx context.Context, req *sdkmcp.CallToolR
 	}
 
 	var sb strings.Builder
-	sb.WriteString("Memory Store Statistics:\n\n")
+	fmt.Fprintf(&sb, "Memory Store Statistics (%s):\n\n", scope)
 	fmt.Fprintf(&sb, "- Total memories: %d\n", stats.TotalMemories)
 	fmt.Fprintf(&sb, "- Total unique tags: %d\n", stats.TotalTags)
 	fmt.Fprintf(&sb, "- Expired memories: %d\n", stats.ExpiredMemories)
@@ -6,6 +6,7 @@ type MemoryRememberInput struct {
 	Importance float64  `json:"importance,omitempty" jsonschema:"Importance level from 0.0 to 1.0. Higher importance memories are prioritized in recall. Default is 0.5."`
 	Tags       []string `json:"tags,omitempty" jsonschema:"Categorization tags for filtering and organizing memories."`
 	TTLHours   int      `json:"ttl_hours,omitempty" jsonschema:"Time to live in hours. Memory expires after this duration. 0 means no expiration."`
+	Scope      string   `json:"scope,omitempty" jsonschema:"Where to store the memory: global (default), shared by every project, or project, in the active project's vecgrep data directory so it travels with the codebase."`
 }
 
 // MemoryRecallInput is the input for memory_recall.
@@ -14,6 +15,9 @@ type MemoryRecallInput struct {
 	Limit         int      `json:"limit,omitempty" jsonschema:"Maximum number of results to return. Default is 10."`
 	Tags          []string `json:"tags,omitempty" jsonschema:"Filter results to only include memories with these tags."`
 	MinImportance float64  `json:"min_importance,omitempty" jsonschema:"Minimum importance threshold. Only return memories with importance >= this value."`
+	Since         string   `json:"since,omitempty" jsonschema:"Only return memories created at or after this time: an RFC 3339 timestamp, a YYYY-MM-DD date, or an age such as 24h, 7d, or 2w."`
+	Until         string   `json:"until,omitempty" jsonschema:"Only return memories created at or before this time, in the same formats as since. A date includes that whole day."`
+	Scope         string   `json:"scope,omitempty" jsonschema:"Which store to search: global (default) or project, the active project's own store."`
 }
 
 // MemoryForgetInput is the input for memory_forget.
@@ -22,10 +26,13 @@ type MemoryForgetInput struct {
 	Tags           []string `json:"tags,omitempty" jsonschema:"Delete all memories that have any of these tags."`
 	OlderThanHours int      `json:"older_than_hours,omitempty" jsonschema:"Delete memories older than this many hours."`
 	Confirm        string   `json:"confirm,omitempty" jsonschema:"Set to yes to confirm bulk deletion (required when deleting by tags or age)."`
+	Scope          string   `json:"scope,omitempty" jsonschema:"Which store to delete from: global (default) or project, the active project's own store."`
 }
 
-// MemoryStatsInput is the input for memory_stats (no parameters).
-type MemoryStatsInput struct{}
+// MemoryStatsInput is the input for memory_stats.
+type MemoryStatsInput struct {
+	Scope string `json:"scope,omitempty" jsonschema:"Which store to describe: global (default) or project, the active project's own store."`
+}
 
 // MemoryResult represents a memory in recall results.
 type MemoryResult struct {
@@ -1,12 +1,11 @@
 package mcp
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"go/parser"
 	"go/token"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
@@ -14,6 +13,8 @@ import (
 	"sync"
 	"time"
 
+	"github.com/abdul-hamid-achik/vecgrep/internal/app"
+	"github.com/abdul-hamid-achik/vecgrep/internal/index"
 	"github.com/abdul-hamid-achik/vecgrep/internal/search"
 	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -67,20 +68,27 @@ func (s *SDKServer) handleOverview(ctx context.Context, req *sdkmcp.CallToolRequ
 		// Language distribution
 		if langStats, ok := stats["languages"].(map[string]int64); ok && len(langStats) > 0 {
 			sb.WriteString("\n## Languages\n\n")
-			// Sort languages by count
+			langLines, _ := stats["language_lines"].(map[string]int64)
+			langBytes, _ := stats["language_bytes"].(map[string]int64)
+			// Sort languages by code volume, falling back to chunk count for
+			// indexes without size metadata.
 			type langCount struct {
 				lang  string
 				count int64
+				bytes int64
 			}
 			var langs []langCount
 			for lang, count := range langStats {
-				langs = append(langs, langCount{lang, count})
+				langs = append(langs, langCount{lang, count, langBytes[lang]})
 			}
 			sort.Slice(langs, func(i, j int) bool {
+				if langs[i].bytes != langs[j].bytes {
+					return langs[i].bytes > langs[j].bytes
+				}
 				return langs[i].count > langs[j].count
 			})
 			for _, lc := range langs {
-				fmt.Fprintf(&sb, "- **%s:** %d files\n", lc.lang, lc.count)
+				fmt.Fprintf(&sb, "- **%s:** %s\n", lc.lang, languageVolume(lc.count, langLines[lc.lang], lc.bytes))
 			}
 		}
 		sb.WriteString("\n")
@@ -93,6 +101,14 @@ func (s *SDKServer) handleOverview(ctx context.Context, req *sdkmcp.CallToolRequ
 		structure := buildDirectoryTree(state.projectRoot, maxDepth)
 		sb.WriteString(structure)
 		sb.WriteString("```\n\n")
+
+		if summaries, err := app.ListDirectorySummaries(state.cfg.DataDir); err == nil && len(summaries) > 0 {
+			sb.WriteString("## Module Summaries\n\n")
+			for _, summary := range summaries {
+				fmt.Fprintf(&sb, "- `%s/`: %s\n", summary.Dir, summary.Summary)
+			}
+			sb.WriteString("\n")
+		}
 	}
 
 	// Entry points
@@ -298,7 +314,20 @@ func (s *SDKServer) handleBatchSearch(ctx context.Context, req *sdkmcp.CallToolR
 	}
 
 	// Deduplicate defaults to true unless explicitly set to false
-	deduplicate := input.Deduplicate == nil || *input.Deduplicate
+	dedupeMode := search.DedupeNone
+	if input.Deduplicate == nil || *input.Deduplicate {
+		dedupeMode = search.DedupeChunk
+		if input.DedupeBy != "" {
+			mode, err := search.ParseDedupeMode(input.DedupeBy)
+			if err != nil {
+				return &sdkmcp.CallToolResult{
+					Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Error: %v", err)}},
+					IsError: true,
+				}, nil, nil
+			}
+			dedupeMode = mode
+		}
+	}
 	state, sErr := s.acquireProjectReadSnapshot(ctx)
 	if sErr != nil {
 		return &sdkmcp.CallToolResult{
@@ -332,6 +361,7 @@ func (s *SDKServer) handleBatchSearch(ctx context.Context, req *sdkmcp.CallToolR
 				Limit:       limitPerQuery,
 				Language:    input.Language,
 				ChunkType:   input.ChunkType,
+				Tags:        input.Tags,
 				ProjectRoot: state.projectRoot,
 				Mode:        search.SearchModeHybrid,
 			}
@@ -341,7 +371,12 @@ func (s *SDKServer) handleBatchSearch(ctx context.Context, req *sdkmcp.CallToolR
 				resultsChan <- queryResult{query: q, err: err}
 				return
 			}
-			resultsChan <- queryResult{query: q, results: outcome.Results, warnings: outcome.Warnings}
+			results, rerankWarning := app.RerankSearchResults(ctx, state.cfg, nil, q, outcome.Results)
+			warnings := outcome.Warnings
+			if rerankWarning != "" {
+				warnings = append(warnings, rerankWarning)
+			}
+			resultsChan <- queryResult{query: q, results: results, warnings: warnings}
 		}(query)
 	}
 
@@ -375,9 +410,33 @@ func (s *SDKServer) handleBatchSearch(ctx context.Context, req *sdkmcp.CallToolR
 		fmt.Fprintf(&sb, "> **Warning:** %s\n\n", w)
 	}
 
-	seen := make(map[int64]bool) // For deduplication
-	totalResults := 0
+	// Deduplicate across queries first, then apply the response cap to the
+	// whole batch so the lowest-scored results go first whichever query
+	// produced them.
+	deduper := search.NewDeduper(dedupeMode)
+	filtered := make(map[string][]search.Result, len(allResults))
+	var sizes []int
+	var scores []float32
+	for _, query := range input.Queries {
+		results, ok := allResults[query]
+		if !ok {
+			continue
+		}
+		filtered[query] = deduper.Filter(results)
+		for i := range filtered[query] {
+			filtered[query][i].Content = search.TruncateSnippet(filtered[query][i].Content, query, input.MaxSnippetLines)
+		}
+		for _, r := range filtered[query] {
+			sizes = append(sizes, resultRenderSize(r))
+			scores = append(scores, r.Score)
+		}
+	}
+	budget := maxResponseBytes(state.cfg)
+	drop, dropped := dropLowestScored(sizes, scores, budget)
+	writeTruncationNote(&sb, dropped, budget)
 
+	totalResults := 0
+	position := 0
 	for _, query := range input.Queries {
 		results, ok := allResults[query]
 		if !ok {
@@ -393,13 +452,13 @@ func (s *SDKServer) handleBatchSearch(ctx context.Context, req *sdkmcp.CallToolR
 		}
 
 		resultCount := 0
-		for _, r := range results {
-			// Skip duplicates if deduplication is enabled
-			if deduplicate && seen[r.ChunkID] {
+		omitted := 0
+		for _, r := range filtered[query] {
+			position++
+			if drop[position-1] {
+				omitted++
 				continue
 			}
-			seen[r.ChunkID] = true
-
 			resultCount++
 			totalResults++
 
@@ -416,12 +475,15 @@ func (s *SDKServer) handleBatchSearch(ctx context.Context, req *sdkmcp.CallToolR
 			sb.WriteString("\n```\n\n")
 		}
 
-		if resultCount == 0 {
+		if resultCount == 0 && omitted == 0 {
 			sb.WriteString("All results were duplicates of previous queries.\n\n")
+		} else if omitted > 0 {
+			fmt.Fprintf(&sb, "%d lower-scored result(s) omitted by the response size cap.\n\n", omitted)
 		}
 	}
 
 	fmt.Fprintf(&sb, "---\n**Total unique results:** %d\n", totalResults)
+	auditResults(ctx, totalResults)
 
 	return &sdkmcp.CallToolResult{
 		Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: sb.String()}},
@@ -497,7 +559,7 @@ func (s *SDKServer) handleRelatedFiles(ctx context.Context, req *sdkmcp.CallTool
 		case err != nil:
 			// State (b): real failure. Fall back to heuristics, but log so
 			// the degradation is not silent, and mark provenance in-band.
-			log.Printf("vecgrep: codemap related-files failed for %s: %v; falling back to import-regex", relPath, err)
+			slog.Warn("codemap related-files failed, falling back to import-regex", "path", relPath, "err", err)
 			sb.WriteString("> _codemap errored; used import-regex heuristics below._\n\n")
 		case res != nil && res.Indexed:
 			// State (c): a real graph answer (possibly empty).
@@ -626,129 +688,7 @@ func findImports(filePath string, ext string) []string {
 	if err != nil {
 		return nil
 	}
-
-	var imports []string
-	lines := strings.Split(string(content), "\n")
-
-	switch ext {
-	case ".go":
-		// Go imports
-		inImportBlock := false
-		for _, line := range lines {
-			trimmed := strings.TrimSpace(line)
-			if strings.HasPrefix(trimmed, "import (") {
-				inImportBlock = true
-				continue
-			}
-			if inImportBlock {
-				if trimmed == ")" {
-					inImportBlock = false
-					continue
-				}
-				// Extract import path
-				if imp := extractGoImport(trimmed); imp != "" {
-					imports = append(imports, imp)
-				}
-			} else if rest, found := strings.CutPrefix(trimmed, "import "); found {
-				// Single import
-				if imp := extractGoImport(rest); imp != "" {
-					imports = append(imports, imp)
-				}
-			}
-		}
-
-	case ".js", ".ts", ".jsx", ".tsx":
-		// JavaScript/TypeScript imports
-		for _, line := range lines {
-			trimmed := strings.TrimSpace(line)
-			if strings.HasPrefix(trimmed, "import ") || strings.HasPrefix(trimmed, "from ") {
-				if imp := extractJSImport(trimmed); imp != "" {
-					imports = append(imports, imp)
-				}
-			}
-			if strings.Contains(trimmed, "require(") {
-				if imp := extractRequire(trimmed); imp != "" {
-					imports = append(imports, imp)
-				}
-			}
-		}
-
-	case ".py":
-		// Python imports
-		for _, line := range lines {
-			trimmed := strings.TrimSpace(line)
-			if strings.HasPrefix(trimmed, "import ") || strings.HasPrefix(trimmed, "from ") {
-				if imp := extractPythonImport(trimmed); imp != "" {
-					imports = append(imports, imp)
-				}
-			}
-		}
-	}
-
-	return imports
-}
-
-func extractGoImport(line string) string {
-	line = strings.TrimSpace(line)
-	// Remove alias if present
-	parts := strings.Fields(line)
-	if len(parts) == 0 {
-		return ""
-	}
-	imp := parts[len(parts)-1]
-	// Remove quotes
-	imp = strings.Trim(imp, `"`)
-	if imp == "" || imp == "_" {
-		return ""
-	}
-	return imp
-}
-
-func extractJSImport(line string) string {
-	// Extract from: import ... from 'module' or import 'module'
-	if idx := strings.LastIndex(line, "from "); idx != -1 {
-		rest := strings.TrimSpace(line[idx+5:])
-		return strings.Trim(rest, `"';`)
-	}
-	if rest, found := strings.CutPrefix(line, "import "); found {
-		rest = strings.TrimSpace(rest)
-		return strings.Trim(rest, `"';`)
-	}
-	return ""
-}
-
-func extractRequire(line string) string {
-	// Extract from: require('module')
-	_, rest, found := strings.Cut(line, "require(")
-	if !found {
-		return ""
-	}
-	end := strings.IndexAny(rest, `"')`)
-	if end == -1 {
-		return ""
-	}
-	// Find the closing quote
-	closeIdx := strings.IndexAny(rest[end+1:], `"')`)
-	if closeIdx == -1 {
-		return strings.Trim(rest[:end+1], `"'`)
-	}
-	return strings.Trim(rest[end+1:end+1+closeIdx], `"'`)
-}
-
-func extractPythonImport(line string) string {
-	if strings.HasPrefix(line, "from ") {
-		parts := strings.Fields(line)
-		if len(parts) >= 2 {
-			return parts[1]
-		}
-	}
-	if rest, found := strings.CutPrefix(line, "import "); found {
-		parts := strings.Split(rest, ",")
-		if len(parts) > 0 {
-			return strings.TrimSpace(strings.Split(parts[0], " as ")[0])
-		}
-	}
-	return ""
+	return index.ParseImports(string(content), ext)
 }
 
 // findImportedBy searches for files that import the given file.
@@ -942,51 +882,3 @@ func findRelatedConfigs(root, relPath string) []string {
 
 	return configs
 }
-
-// expandContextLines expands a result's content to include surrounding lines.
-func expandContextLines(projectRoot string, result search.Result, contextLines int) string {
-	if contextLines <= 0 {
-		return result.Content
-	}
-
-	// Read the file
-	filePath := filepath.Join(projectRoot, result.RelativePath)
-	file, err := os.Open(filePath)
-	if err != nil {
-		return result.Content
-	}
-	defer file.Close()
-
-	// Read all lines
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-
-	if scanner.Err() != nil {
-		return result.Content
-	}
-
-	// Calculate line range (1-indexed to 0-indexed)
-	startLine := result.StartLine - 1 - contextLines
-	endLine := result.EndLine - 1 + contextLines
-
-	if startLine < 0 {
-		startLine = 0
-	}
-	if endLine >= len(lines) {
-		endLine = len(lines) - 1
-	}
-
-	// Build expanded content
-	var sb strings.Builder
-	for i := startLine; i <= endLine; i++ {
-		sb.WriteString(lines[i])
-		if i < endLine {
-			sb.WriteString("\n")
-		}
-	}
-
-	return sb.String()
-}
@@ -201,9 +201,16 @@ func TestHandleStatus_ExposesReadiness(t *testing.T) {
 			t.Fatalf("status missing %q:\n%s", want, text)
 		}
 	}
-	if r, ok := structured.(app.Readiness); !ok || r.State != app.ReadinessEmpty {
+	out, ok := structured.(*StatusOutput)
+	if !ok || out.Readiness == nil || out.Readiness.State != app.ReadinessEmpty {
 		t.Fatalf("structured = %#v, want empty readiness", structured)
 	}
+	if out.ProjectRoot != s.projectRoot || out.Chunks != 0 {
+		t.Fatalf("structured project/counts = %+v", out)
+	}
+	if !strings.Contains(text, "Project: ") {
+		t.Fatalf("status missing project line:\n%s", text)
+	}
 }
 
 func TestHandleEnsure_CheckEmpty(t *testing.T) {
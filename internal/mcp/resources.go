@@ -0,0 +1,218 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Resource URIs. File paths are project-relative and may contain slashes, so
+// the file template uses reserved expansion ({+path}); vecgrep://file/a/b.go
+// therefore reads a/b.go.
+const (
+	filesResourceURI      = "vecgrep://files"
+	fileResourcePrefix    = "vecgrep://file/"
+	chunkResourcePrefix   = "vecgrep://chunk/"
+	fileResourceTemplate  = fileResourcePrefix + "{+path}"
+	chunkResourceTemplate = chunkResourcePrefix + "{id}"
+)
+
+// registerResources exposes the active project's index through the MCP
+// resources API, so a client can list indexed files and read chunk contents
+// on demand instead of receiving them inline in tool output.
+func (s *SDKServer) registerResources() {
+	s.server.AddResource(&sdkmcp.Resource{
+		URI:         filesResourceURI,
+		Name:        "indexed-files",
+		Title:       "Indexed files",
+		Description: "JSON list of the files indexed for the active project, with language, size, and indexing time. Read vecgrep://file/{path} for one file's chunks.",
+		MIMEType:    "application/json",
+	}, s.readFilesResource)
+
+	s.server.AddResourceTemplate(&sdkmcp.ResourceTemplate{
+		URITemplate: fileResourceTemplate,
+		Name:        "indexed-file",
+		Title:       "Indexed file chunks",
+		Description: "The indexed chunks of one project-relative file, in line order, each headed by its chunk ID, line range, type, and symbol.",
+		MIMEType:    "text/plain",
+	}, s.readFileResource)
+
+	s.server.AddResourceTemplate(&sdkmcp.ResourceTemplate{
+		URITemplate: chunkResourceTemplate,
+		Name:        "indexed-chunk",
+		Title:       "Indexed chunk",
+		Description: "One indexed chunk by the chunk ID that search results report.",
+		MIMEType:    "text/plain",
+	}, s.readChunkResource)
+}
+
+type fileResourceEntry struct {
+	Path      string    `json:"path"`
+	URI       string    `json:"uri"`
+	Language  string    `json:"language,omitempty"`
+	Size      int64     `json:"size"`
+	IndexedAt time.Time `json:"indexed_at"`
+}
+
+func (s *SDKServer) readFilesResource(ctx context.Context, req *sdkmcp.ReadResourceRequest) (*sdkmcp.ReadResourceResult, error) {
+	state, err := s.acquireResourceSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer state.release()
+
+	files, err := state.database.ListFiles(state.projectRoot)
+	if err != nil {
+		return nil, fmt.Errorf("list indexed files: %w", err)
+	}
+	entries := make([]fileResourceEntry, 0, len(files))
+	for _, file := range files {
+		entries = append(entries, fileResourceEntry{
+			Path:      file.RelativePath,
+			URI:       fileResourceURI(file.RelativePath),
+			Language:  file.Language,
+			Size:      file.Size,
+			IndexedAt: file.IndexedAt,
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal file list: %w", err)
+	}
+	return &sdkmcp.ReadResourceResult{Contents: []*sdkmcp.ResourceContents{{
+		URI:      req.Params.URI,
+		MIMEType: "application/json",
+		Text:     string(data),
+	}}}, nil
+}
+
+func (s *SDKServer) readFileResource(ctx context.Context, req *sdkmcp.ReadResourceRequest) (*sdkmcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	escaped, ok := strings.CutPrefix(uri, fileResourcePrefix)
+	if !ok {
+		return nil, sdkmcp.ResourceNotFoundError(uri)
+	}
+	filePath, err := url.PathUnescape(escaped)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file resource %q: %w", uri, err)
+	}
+
+	state, err := s.acquireResourceSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer state.release()
+
+	relPath, err := db.ProjectRelativePath(state.projectRoot, filePath)
+	if err != nil {
+		return nil, sdkmcp.ResourceNotFoundError(uri)
+	}
+	all, err := state.database.GetChunksByFile(relPath)
+	if err != nil {
+		return nil, fmt.Errorf("get chunks for %s: %w", relPath, err)
+	}
+	chunks := all[:0]
+	for _, chunk := range all {
+		if chunk.ProjectRoot == state.projectRoot {
+			chunks = append(chunks, chunk)
+		}
+	}
+	if len(chunks) == 0 {
+		return nil, sdkmcp.ResourceNotFoundError(uri)
+	}
+	sort.Slice(chunks, func(i, j int) bool {
+		if chunks[i].StartLine != chunks[j].StartLine {
+			return chunks[i].StartLine < chunks[j].StartLine
+		}
+		return chunks[i].ChunkIndex < chunks[j].ChunkIndex
+	})
+
+	var sb strings.Builder
+	for i, chunk := range chunks {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		writeChunkResource(&sb, chunk)
+	}
+	return &sdkmcp.ReadResourceResult{Contents: []*sdkmcp.ResourceContents{{
+		URI:      uri,
+		MIMEType: "text/plain",
+		Text:     sb.String(),
+	}}}, nil
+}
+
+func (s *SDKServer) readChunkResource(ctx context.Context, req *sdkmcp.ReadResourceRequest) (*sdkmcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	idText, ok := strings.CutPrefix(uri, chunkResourcePrefix)
+	if !ok {
+		return nil, sdkmcp.ResourceNotFoundError(uri)
+	}
+	chunkID, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil || chunkID <= 0 {
+		return nil, sdkmcp.ResourceNotFoundError(uri)
+	}
+
+	state, err := s.acquireResourceSnapshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer state.release()
+
+	chunk, err := state.database.Backend().GetChunkByID(chunkID)
+	if err != nil || chunk == nil || chunk.ProjectRoot != state.projectRoot {
+		return nil, sdkmcp.ResourceNotFoundError(uri)
+	}
+	var sb strings.Builder
+	writeChunkResource(&sb, *chunk)
+	return &sdkmcp.ReadResourceResult{Contents: []*sdkmcp.ResourceContents{{
+		URI:      uri,
+		MIMEType: "text/plain",
+		Text:     sb.String(),
+	}}}, nil
+}
+
+// acquireResourceSnapshot is acquireProjectReadSnapshot for resource reads,
+// which have no tool result to carry a soft error.
+func (s *SDKServer) acquireResourceSnapshot(ctx context.Context) (projectReadSnapshot, error) {
+	if err := s.ensureInitialized(ctx); err != nil {
+		return projectReadSnapshot{}, err
+	}
+	state, err := s.acquireProjectReadSnapshot(ctx)
+	if err != nil {
+		return projectReadSnapshot{}, fmt.Errorf("open database: %w", err)
+	}
+	return state, nil
+}
+
+// writeChunkResource writes one chunk under a header naming its resource URI,
+// location, type, and symbol.
+func writeChunkResource(sb *strings.Builder, chunk db.ChunkRecord) {
+	fmt.Fprintf(sb, "// %s%d %s:%d-%d %s", chunkResourcePrefix, chunk.ID, chunk.RelativePath, chunk.StartLine, chunk.EndLine, chunk.ChunkType)
+	if chunk.SymbolName != "" {
+		fmt.Fprintf(sb, " %s", chunk.SymbolName)
+	}
+	sb.WriteString("\n")
+	sb.WriteString(chunk.Content)
+	if !strings.HasSuffix(chunk.Content, "\n") {
+		sb.WriteString("\n")
+	}
+}
+
+// fileResourceURI returns the vecgrep://file URI for a project-relative path,
+// escaping each segment but keeping the slashes.
+func fileResourceURI(relPath string) string {
+	segments := strings.Split(relPath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fileResourcePrefix + strings.Join(segments, "/")
+}
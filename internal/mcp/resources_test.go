@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func readTestResource(t *testing.T, handler sdkmcp.ResourceHandler, uri string) (*sdkmcp.ReadResourceResult, error) {
+	t.Helper()
+	return handler(context.Background(), &sdkmcp.ReadResourceRequest{Params: &sdkmcp.ReadResourceParams{URI: uri}})
+}
+
+func TestIndexResources(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+
+	result, err := readTestResource(t, s.readFilesResource, filesResourceURI)
+	if err != nil {
+		t.Fatalf("read %s: %v", filesResourceURI, err)
+	}
+	var files []fileResourceEntry
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &files); err != nil {
+		t.Fatalf("decode file list: %v", err)
+	}
+	if len(files) != 1 || files[0].Path != "main.go" || files[0].URI != "vecgrep://file/main.go" {
+		t.Fatalf("files = %+v, want main.go", files)
+	}
+
+	result, err = readTestResource(t, s.readFileResource, files[0].URI)
+	if err != nil {
+		t.Fatalf("read %s: %v", files[0].URI, err)
+	}
+	text := result.Contents[0].Text
+	if !strings.Contains(text, "package main") || !strings.HasPrefix(text, "// "+chunkResourcePrefix) {
+		t.Fatalf("file resource text = %q", text)
+	}
+
+	chunkURI := strings.Fields(strings.TrimPrefix(text, "// "))[0]
+	result, err = readTestResource(t, s.readChunkResource, chunkURI)
+	if err != nil {
+		t.Fatalf("read %s: %v", chunkURI, err)
+	}
+	if !strings.Contains(result.Contents[0].Text, "main.go:1-1") {
+		t.Fatalf("chunk resource text = %q", result.Contents[0].Text)
+	}
+
+	for _, read := range []struct {
+		handler sdkmcp.ResourceHandler
+		uri     string
+	}{
+		{s.readFileResource, "vecgrep://file/missing.go"},
+		{s.readFileResource, "vecgrep://file/../b/main.go"},
+		{s.readChunkResource, "vecgrep://chunk/999999"},
+		{s.readChunkResource, "vecgrep://chunk/abc"},
+	} {
+		if _, err := readTestResource(t, read.handler, read.uri); err == nil {
+			t.Fatalf("read %s succeeded, want resource not found", read.uri)
+		}
+	}
+}
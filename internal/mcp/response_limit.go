@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+// DefaultMaxResponseBytes caps the text a search-type tool returns when
+// server.max_response_bytes is unset. Some MCP clients fail outright on
+// multi-megabyte tool results, which a limit=100 search over large chunks can
+// produce.
+const DefaultMaxResponseBytes = 256 * 1024
+
+// maxHTTPSearchLimit caps the limit of a search served over plain HTTP
+// (DeepLinkPath, ContextPath, and VectorStoreSearchPath); larger limits are
+// clamped to it with a warning.
+const maxHTTPSearchLimit = 100
+
+// resultRenderOverhead approximates the markdown rendered around one result
+// (headings, file line, code fence) on top of its content.
+const resultRenderOverhead = 200
+
+// maxResponseBytes returns the configured response cap for search tools.
+func maxResponseBytes(cfg *config.Config) int {
+	if cfg != nil && cfg.Server.MaxResponseBytes > 0 {
+		return cfg.Server.MaxResponseBytes
+	}
+	return DefaultMaxResponseBytes
+}
+
+func resultRenderSize(r search.Result) int {
+	return len(r.Content) + len(r.RelativePath) + len(r.SymbolName) + resultRenderOverhead
+}
+
+// dropLowestScored marks entries to omit so the summed sizes fit in budget,
+// dropping the lowest scores first and, among equal scores, the later entry.
+// The highest-scored entry is always kept so a response is never empty just
+// because one chunk is large.
+func dropLowestScored(sizes []int, scores []float32, budget int) (drop []bool, dropped int) {
+	drop = make([]bool, len(sizes))
+	total := 0
+	for _, size := range sizes {
+		total += size
+	}
+	if total <= budget || len(sizes) <= 1 {
+		return drop, 0
+	}
+	order := make([]int, len(sizes))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if scores[order[a]] != scores[order[b]] {
+			return scores[order[a]] < scores[order[b]]
+		}
+		return order[a] > order[b]
+	})
+	for _, i := range order[:len(order)-1] {
+		if total <= budget {
+			break
+		}
+		drop[i] = true
+		total -= sizes[i]
+		dropped++
+	}
+	return drop, dropped
+}
+
+// capResults omits the lowest-scored results until the rendered response fits
+// in budget, keeping the remaining results in their original order.
+func capResults(results []search.Result, budget int) ([]search.Result, int) {
+	sizes := make([]int, len(results))
+	scores := make([]float32, len(results))
+	for i, r := range results {
+		sizes[i] = resultRenderSize(r)
+		scores[i] = r.Score
+	}
+	drop, dropped := dropLowestScored(sizes, scores, budget)
+	if dropped == 0 {
+		return results, 0
+	}
+	kept := make([]search.Result, 0, len(results)-dropped)
+	for i, r := range results {
+		if !drop[i] {
+			kept = append(kept, r)
+		}
+	}
+	return kept, dropped
+}
+
+// writeTruncationNote tells the caller that results were omitted and how to
+// get them back.
+func writeTruncationNote(sb *strings.Builder, dropped, budget int) {
+	if dropped == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "> **Truncated:** omitted %d lowest-scored result(s) to keep this response under %d KB (server.max_response_bytes). Lower limit, set max_snippet_lines, or narrow the query to see them.\n\n", dropped, budget/1024)
+}
+
+// capHTTPResults is capResults for the plain HTTP search endpoints, which
+// report the omitted results as a warning rather than a markdown note.
+func capHTTPResults(results []search.Result, budget int) ([]search.Result, []string) {
+	results, dropped := capResults(results, budget)
+	if dropped == 0 {
+		return results, nil
+	}
+	return results, []string{fmt.Sprintf("omitted %d lowest-scored result(s) to keep this response under %d KB (server.max_response_bytes); lower limit or narrow the query to see them", dropped, budget/1024)}
+}
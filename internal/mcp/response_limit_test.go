@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/config"
+	"github.com/abdul-hamid-achik/vecgrep/internal/search"
+)
+
+func TestCapResultsDropsLowestScoredFirst(t *testing.T) {
+	body := strings.Repeat("x", 1000)
+	results := []search.Result{
+		{RelativePath: "a.go", Content: body, Score: 0.9},
+		{RelativePath: "b.go", Content: body, Score: 0.4},
+		{RelativePath: "c.go", Content: body, Score: 0.7},
+		{RelativePath: "d.go", Content: body, Score: 0.4},
+	}
+	budget := 2 * resultRenderSize(results[0])
+
+	kept, dropped := capResults(results, budget)
+	if dropped != 2 || len(kept) != 2 || kept[0].RelativePath != "a.go" || kept[1].RelativePath != "c.go" {
+		t.Fatalf("kept = %+v, dropped = %d; want a.go and c.go in original order", kept, dropped)
+	}
+
+	if kept, dropped := capResults(results, 1<<20); dropped != 0 || len(kept) != len(results) {
+		t.Fatalf("under budget: kept %d, dropped %d", len(kept), dropped)
+	}
+	if kept, dropped := capResults(results, 10); dropped != 3 || len(kept) != 1 || kept[0].RelativePath != "a.go" {
+		t.Fatalf("tiny budget kept = %+v, want only the top result", kept)
+	}
+}
+
+func TestDaemonSearchResultNotesTruncation(t *testing.T) {
+	body := strings.Repeat("y", 2000)
+	raw := []byte(`{"results":[{"relative_path":"a.go","content":"` + body + `","score":0.9},{"relative_path":"b.go","content":"` + body + `","score":0.5}]}`)
+	text := formatDaemonSearchResult(raw, "", 3000)
+	if !strings.Contains(text, "omitted 1 lowest-scored result") || strings.Contains(text, "b.go") {
+		t.Fatalf("text does not report the omitted result:\n%s", text[:200])
+	}
+}
+
+func TestMaxResponseBytesDefault(t *testing.T) {
+	if got := maxResponseBytes(nil); got != DefaultMaxResponseBytes {
+		t.Fatalf("maxResponseBytes(nil) = %d", got)
+	}
+	cfg := &config.Config{Server: config.ServerConfig{MaxResponseBytes: 4096}}
+	if got := maxResponseBytes(cfg); got != 4096 {
+		t.Fatalf("maxResponseBytes(cfg) = %d, want 4096", got)
+	}
+}
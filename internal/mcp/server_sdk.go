@@ -6,7 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sort"
@@ -37,22 +37,41 @@ type InitInput struct {
 
 // SearchInput is the input for vecgrep_search.
 type SearchInput struct {
-	Query        string   `json:"query" jsonschema:"The search query. Can be natural language description of what you're looking for."`
-	Limit        int      `json:"limit,omitempty" jsonschema:"Maximum number of results to return."`
-	Language     string   `json:"language,omitempty" jsonschema:"Filter results by programming language."`
-	Languages    []string `json:"languages,omitempty" jsonschema:"Filter results by multiple languages (OR)."`
-	ChunkType    string   `json:"chunk_type,omitempty" jsonschema:"Filter results by chunk type."`
-	ChunkTypes   []string `json:"chunk_types,omitempty" jsonschema:"Filter results by multiple chunk types (OR)."`
-	FilePattern  string   `json:"file_pattern,omitempty" jsonschema:"Filter results by file path pattern (glob)."`
-	Directory    string   `json:"directory,omitempty" jsonschema:"Filter results by directory prefix."`
-	FilePaths    []string `json:"file_paths,omitempty" jsonschema:"Restrict search to these relative paths (allow-list). Used for blast-radius scoping from codemap impact."`
-	Symbol       string   `json:"symbol,omitempty" jsonschema:"When set, uses codemap impact to compute the blast radius of this symbol and scopes the search to affected files. Falls back to unscoped search if codemap is unavailable."`
-	MinLine      int      `json:"min_line,omitempty" jsonschema:"Filter by minimum start line."`
-	MaxLine      int      `json:"max_line,omitempty" jsonschema:"Filter by maximum start line."`
-	MinScore     float32  `json:"min_score,omitempty" jsonschema:"Drop matches below this score (0-1 in all modes). Hybrid and semantic scores are 0-1 similarities; keyword scores (including hybrid degraded by an unavailable embedder) are BM25 normalized to 0-1 within each result set, so they are only comparable within one search."`
-	Mode         string   `json:"mode,omitempty" jsonschema:"Search mode: 'semantic' (vector only), 'keyword' (text only), or 'hybrid' (combined, default)."`
-	Explain      bool     `json:"explain,omitempty" jsonschema:"Return search diagnostics including timing and index info."`
-	ContextLines int      `json:"context_lines,omitempty" jsonschema:"Number of lines to include before and after each result (default: 0)."`
+	Query           string   `json:"query" jsonschema:"The search query. Can be natural language description of what you're looking for. Inline filters such as lang:go type:function path:internal/** lines:10-200 score:0.5 tag:api narrow the search; explicit filter arguments take precedence."`
+	Limit           int      `json:"limit,omitempty" jsonschema:"Maximum number of results to return."`
+	Language        string   `json:"language,omitempty" jsonschema:"Filter results by programming language."`
+	Languages       []string `json:"languages,omitempty" jsonschema:"Filter results by multiple languages (OR)."`
+	ChunkType       string   `json:"chunk_type,omitempty" jsonschema:"Filter results by chunk type: function, class, interface, const, config, block, comment, or generic."`
+	ChunkTypes      []string `json:"chunk_types,omitempty" jsonschema:"Filter results by multiple chunk types (OR)."`
+	FilePattern     string   `json:"file_pattern,omitempty" jsonschema:"Filter results by file path pattern (glob)."`
+	Directory       string   `json:"directory,omitempty" jsonschema:"Filter results by directory prefix."`
+	FilePaths       []string `json:"file_paths,omitempty" jsonschema:"Restrict search to these relative paths (allow-list). Used for blast-radius scoping from codemap impact."`
+	Symbol          string   `json:"symbol,omitempty" jsonschema:"When set, uses codemap impact to compute the blast radius of this symbol and scopes the search to affected files. Falls back to unscoped search if codemap is unavailable."`
+	MinLine         int      `json:"min_line,omitempty" jsonschema:"Filter by minimum start line."`
+	MaxLine         int      `json:"max_line,omitempty" jsonschema:"Filter by maximum start line."`
+	MinScore        float32  `json:"min_score,omitempty" jsonschema:"Drop matches below this score (0-1 in all modes). Hybrid and semantic scores are 0-1 similarities; keyword scores (including hybrid degraded by an unavailable embedder) are BM25 normalized to 0-1 within each result set, so they are only comparable within one search. After 'vecgrep calibrate', scores in every mode are calibrated to the share of typical results they beat, so one threshold fits all modes."`
+	Tags            []string `json:"tags,omitempty" jsonschema:"Keep only results from files labeled with any of these tags by the indexing.tags rules."`
+	EfSearch        int      `json:"ef_search,omitempty" jsonschema:"HNSW candidate list size for this search (default: vector.veclite.ef_search). Raise it to trade latency for recall."`
+	Mode            string   `json:"mode,omitempty" jsonschema:"Search mode: 'semantic' (vector only), 'keyword' (text only), or 'hybrid' (combined, default)."`
+	Explain         bool     `json:"explain,omitempty" jsonschema:"Return search diagnostics including timing and index info."`
+	ContextLines    int      `json:"context_lines,omitempty" jsonschema:"Number of lines to include before and after each result (default: 0)."`
+	MaxSnippetLines int      `json:"max_snippet_lines,omitempty" jsonschema:"Trim each result to this many lines, keeping the signature and the lines that best match the query (default: 0, full chunk)."`
+	Rerank          *bool    `json:"rerank,omitempty" jsonschema:"Re-score the top results with the configured reranker (search.reranker). Omit to use the project default; false skips reranking."`
+	Expand          bool     `json:"expand,omitempty" jsonschema:"Also search paraphrases of the query (search.expander_model, or built-in code synonyms) and fuse the rankings. Improves recall for vague natural-language queries at the cost of a few extra searches."`
+	Ref             string   `json:"ref,omitempty" jsonschema:"Search a git ref indexed with 'vecgrep index --ref' instead of the working tree."`
+	Format          string   `json:"format,omitempty" jsonschema:"Result format: 'default' (snippets) or 'citations' (path:Lstart-Lend locations only, also returned as structured citations)."`
+	InferFilters    *bool    `json:"infer_filters,omitempty" jsonschema:"Read filters from the query's wording, so 'python tests for the auth flow' searches Python test files under auth-like directories for 'for the auth flow'. Filters that match no indexed file are ignored. Omit to use search.infer_filters (on by default); only applies when no language, directory, file, or inline filters are given."`
+}
+
+// searchFormatCitations is the vecgrep_search format that returns locations
+// without snippets.
+const searchFormatCitations = "citations"
+
+// SearchCitationsOutput is the structured result of vecgrep_search with
+// format "citations": the readiness payload plus the cited locations.
+type SearchCitationsOutput struct {
+	app.Readiness
+	Citations []search.Citation `json:"citations"`
 }
 
 // IndexInput is the input for vecgrep_index.
@@ -70,40 +89,66 @@ type EnsureInput struct {
 
 // InvestigateInput is the input for vecgrep_investigate.
 type InvestigateInput struct {
-	Symbol       string `json:"symbol" jsonschema:"The symbol to compute the blast radius for (e.g., 'pkg.FuncName' or 'FuncName'). codemap impact finds all files transitively affected by a change to this symbol."`
-	Query        string `json:"query" jsonschema:"The semantic search query to run within the scoped file set."`
-	Limit        int    `json:"limit,omitempty" jsonschema:"Maximum number of results to return (default: 10)."`
-	Mode         string `json:"mode,omitempty" jsonschema:"Search mode: 'semantic' (vector only), 'keyword' (text only), or 'hybrid' (combined, default)."`
-	ContextLines int    `json:"context_lines,omitempty" jsonschema:"Number of lines to include before and after each result (default: 0)."`
+	Symbol          string `json:"symbol" jsonschema:"The symbol to compute the blast radius for (e.g., 'pkg.FuncName' or 'FuncName'). codemap impact finds all files transitively affected by a change to this symbol."`
+	Query           string `json:"query" jsonschema:"The semantic search query to run within the scoped file set."`
+	Limit           int    `json:"limit,omitempty" jsonschema:"Maximum number of results to return (default: 10)."`
+	Mode            string `json:"mode,omitempty" jsonschema:"Search mode: 'semantic' (vector only), 'keyword' (text only), or 'hybrid' (combined, default)."`
+	ContextLines    int    `json:"context_lines,omitempty" jsonschema:"Number of lines to include before and after each result (default: 0)."`
+	MaxSnippetLines int    `json:"max_snippet_lines,omitempty" jsonschema:"Trim each result to this many lines, keeping the signature and the lines that best match the query (default: 0, full chunk)."`
 }
 
 // StatusInput is the input for vecgrep_status (empty).
 type StatusInput struct{}
 
+// StatusOutput is the structured result of vecgrep_status. It carries the
+// same facts as the text report so agents can decide whether to reindex
+// without parsing prose.
+type StatusOutput struct {
+	ProjectRoot string                    `json:"project_root"`
+	ProjectName string                    `json:"project_name,omitempty"`
+	Files       int64                     `json:"files"`
+	Chunks      int64                     `json:"chunks"`
+	Languages   map[string]int64          `json:"languages,omitempty"`
+	Model       string                    `json:"model,omitempty"`
+	Dimensions  int                       `json:"dimensions,omitempty"`
+	Readiness   *app.Readiness            `json:"readiness,omitempty"`
+	Freshness   *app.IndexFreshnessReport `json:"freshness,omitempty"`
+	Pending     *index.PendingChanges     `json:"pending,omitempty"`
+	Health      *app.IndexHealth          `json:"health,omitempty"`
+	// LastIndexedAt is the last successful ingestion recorded by the index
+	// receipt, when one exists.
+	LastIndexedAt *time.Time `json:"last_indexed_at,omitempty"`
+}
+
 // SimilarInput is the input for vecgrep_similar.
 type SimilarInput struct {
 	ChunkID         int64    `json:"chunk_id,omitempty" jsonschema:"Find code similar to this chunk ID."`
 	FileLocation    string   `json:"file_location,omitempty" jsonschema:"Find code similar to the chunk at this file:line location (e.g., 'search.go:50')."`
 	Text            string   `json:"text,omitempty" jsonschema:"Find code similar to this text snippet."`
+	Symbol          string   `json:"symbol,omitempty" jsonschema:"Find code similar to the definition of this symbol name (e.g., 'NewSearcher'). When several definitions match, the candidates are listed with chunk IDs."`
 	Limit           int      `json:"limit,omitempty" jsonschema:"Maximum number of results to return."`
 	Language        string   `json:"language,omitempty" jsonschema:"Filter results by programming language."`
 	Languages       []string `json:"languages,omitempty" jsonschema:"Filter results by multiple languages (OR)."`
-	ChunkType       string   `json:"chunk_type,omitempty" jsonschema:"Filter results by chunk type."`
+	ChunkType       string   `json:"chunk_type,omitempty" jsonschema:"Filter results by chunk type: function, class, interface, const, config, block, comment, or generic."`
 	ChunkTypes      []string `json:"chunk_types,omitempty" jsonschema:"Filter results by multiple chunk types (OR)."`
 	FilePattern     string   `json:"file_pattern,omitempty" jsonschema:"Filter results by file path pattern (glob)."`
 	Directory       string   `json:"directory,omitempty" jsonschema:"Filter results by directory prefix."`
 	MinLine         int      `json:"min_line,omitempty" jsonschema:"Filter by minimum start line."`
 	MaxLine         int      `json:"max_line,omitempty" jsonschema:"Filter by maximum start line."`
+	Tags            []string `json:"tags,omitempty" jsonschema:"Keep only results from files labeled with any of these tags by the indexing.tags rules."`
 	ExcludeSameFile bool     `json:"exclude_same_file,omitempty" jsonschema:"Exclude results from the same file as the source."`
 }
 
 // DeleteInput is the input for vecgrep_delete.
 type DeleteInput struct {
 	FilePath string `json:"file_path" jsonschema:"The file path to delete from the index (relative or absolute)."`
+	DryRun   bool   `json:"dry_run,omitempty" jsonschema:"Report how many chunks would be removed without changing the index."`
 }
 
-// CleanInput is the input for vecgrep_clean (empty).
-type CleanInput struct{}
+// CleanInput is the input for vecgrep_clean.
+type CleanInput struct {
+	DryRun bool `json:"dry_run,omitempty" jsonschema:"Report index statistics without syncing the database."`
+}
 
 // ResetInput is the input for vecgrep_reset.
 type ResetInput struct {
@@ -118,13 +163,28 @@ type OverviewInput struct {
 	IncludeKeyFiles    bool `json:"include_key_files,omitempty" jsonschema:"Include key files like README, config (default: true)."`
 }
 
+// SearchAllInput is the input for vecgrep_search_all.
+type SearchAllInput struct {
+	Query           string   `json:"query" jsonschema:"The search query."`
+	Limit           int      `json:"limit,omitempty" jsonschema:"Maximum number of merged results (default: 10)."`
+	Mode            string   `json:"mode,omitempty" jsonschema:"Search mode: semantic, keyword, or hybrid (default: each project's default mode)."`
+	Language        string   `json:"language,omitempty" jsonschema:"Filter results by programming language."`
+	ChunkType       string   `json:"chunk_type,omitempty" jsonschema:"Filter results by chunk type."`
+	MinScore        float32  `json:"min_score,omitempty" jsonschema:"Drop results scoring below this threshold (0-1)."`
+	Tags            []string `json:"tags,omitempty" jsonschema:"Keep only results from files labeled with any of these tags by the indexing.tags rules."`
+	MaxSnippetLines int      `json:"max_snippet_lines,omitempty" jsonschema:"Trim each result to this many lines (default: 0, full chunk)."`
+}
+
 // BatchSearchInput is the input for vecgrep_batch_search.
 type BatchSearchInput struct {
-	Queries       []string `json:"queries" jsonschema:"List of queries to search for."`
-	LimitPerQuery int      `json:"limit_per_query,omitempty" jsonschema:"Maximum results per query (default: 3)."`
-	Deduplicate   *bool    `json:"deduplicate,omitempty" jsonschema:"Remove duplicate results across queries (default: true)."`
-	Language      string   `json:"language,omitempty" jsonschema:"Filter results by programming language."`
-	ChunkType     string   `json:"chunk_type,omitempty" jsonschema:"Filter results by chunk type."`
+	Queries         []string `json:"queries" jsonschema:"List of queries to search for."`
+	LimitPerQuery   int      `json:"limit_per_query,omitempty" jsonschema:"Maximum results per query (default: 3)."`
+	Deduplicate     *bool    `json:"deduplicate,omitempty" jsonschema:"Remove duplicate results across queries (default: true)."`
+	DedupeBy        string   `json:"dedupe_by,omitempty" jsonschema:"What counts as a duplicate when deduplicating: 'chunk' or 'file' (default: 'chunk')."`
+	Language        string   `json:"language,omitempty" jsonschema:"Filter results by programming language."`
+	ChunkType       string   `json:"chunk_type,omitempty" jsonschema:"Filter results by chunk type: function, class, interface, const, config, block, comment, or generic."`
+	Tags            []string `json:"tags,omitempty" jsonschema:"Keep only results from files labeled with any of these tags by the indexing.tags rules."`
+	MaxSnippetLines int      `json:"max_snippet_lines,omitempty" jsonschema:"Trim each result to this many lines, keeping the signature and the lines that best match the query (default: 0, full chunk)."`
 }
 
 // RelatedFilesInput is the input for vecgrep_related_files.
@@ -137,6 +197,24 @@ type RelatedFilesInput struct {
 // BranchStatusInput is the input for vecgrep_branch_status (empty).
 type BranchStatusInput struct{}
 
+// BookmarkInput is the input for vecgrep_bookmark.
+type BookmarkInput struct {
+	Action string   `json:"action" jsonschema:"One of: 'add', 'list', or 'remove'."`
+	Key    string   `json:"key,omitempty" jsonschema:"For add: chunk location as relative_path:start_line-end_line, taken from a search result."`
+	Note   string   `json:"note,omitempty" jsonschema:"For add: why this chunk matters."`
+	Tags   []string `json:"tags,omitempty" jsonschema:"For add: tags to attach. For list: only return bookmarks carrying ALL of these tags."`
+	ID     uint64   `json:"id,omitempty" jsonschema:"For remove: the bookmark ID."`
+}
+
+// FeedbackInput is the input for vecgrep_feedback.
+type FeedbackInput struct {
+	Action   string `json:"action,omitempty" jsonschema:"One of: 'record' (default), 'list', or 'export'."`
+	Query    string `json:"query,omitempty" jsonschema:"For record: the query the chunk was returned for. For list: only return judgments for this query."`
+	Key      string `json:"key,omitempty" jsonschema:"For record: chunk location as relative_path:start_line-end_line, taken from a search result."`
+	ChunkID  int64  `json:"chunk_id,omitempty" jsonschema:"For record: chunk ID from a JSON search result, instead of key."`
+	Relevant *bool  `json:"relevant,omitempty" jsonschema:"For record: true if the chunk answers the query, false if it does not."`
+}
+
 // SDKServer wraps the official MCP SDK server.
 type SDKServer struct {
 	server *sdkmcp.Server
@@ -156,10 +234,26 @@ type SDKServer struct {
 	codemap    *CodemapClient
 	codemapCfg config.CodemapConfig
 
-	// Memory store (lazy initialized)
-	memoryStore   *memory.MemoryStore
-	memoryInitMu  sync.Mutex
-	memoryInitErr error
+	// Memory stores (lazy initialized): the global store, and the project
+	// stores opened so far keyed by database path
+	memoryStore         *memory.MemoryStore
+	memoryProvider      embed.Provider
+	projectMemoryStores map[string]*memory.MemoryStore
+	memoryInitMu        sync.Mutex
+	memoryInitErr       error
+
+	// audit records tool calls when serve runs with --audit-log.
+	audit *AuditLog
+
+	// readOnly, authToken, and limiter harden a shared server (see
+	// SDKServerConfig).
+	readOnly  bool
+	authToken string
+	limiter   *clientLimiter
+	// jobs runs the REST API's index mutations, and progress streams index
+	// runs to IndexProgressPath; both set up by HTTPHandler.
+	jobs     *jobRegistry
+	progress *progressHub
 
 	statusSnapshotHook func(projectReadSnapshot)          // tests only
 	readSnapshotHook   func(string, projectReadSnapshot)  // tests only
@@ -272,7 +366,7 @@ func (state projectOperationSnapshot) acquireRead(ctx context.Context) (projectR
 		return projectReadSnapshot{}, err
 	}
 	if rErr := state.session.reloadIfStale(); rErr != nil {
-		log.Printf("vecgrep: read-only index reload failed: %v (search may reflect stale persisted data)", rErr)
+		slog.Warn("read-only index reload failed; search may reflect stale persisted data", "err", rErr)
 	}
 	return projectReadSnapshot{
 		projectStateSnapshot: state.projectStateSnapshot,
@@ -304,6 +398,19 @@ type SDKServerConfig struct {
 	Provider    embed.Provider
 	ProjectRoot string
 	Codemap     config.CodemapConfig
+	// AuditLog, when set, records every tool call. The caller owns it and
+	// closes it after the server stops.
+	AuditLog *AuditLog
+	// ReadOnly leaves out every tool that writes or reaches beyond the
+	// served project (see readOnlyExcludedTools), and stops the server from
+	// registering a project on its own.
+	ReadOnly bool
+	// AuthToken, when set, is the bearer token every HTTP request must
+	// carry.
+	AuthToken string
+	// RateLimit, when positive, caps each client address at this many HTTP
+	// requests a minute.
+	RateLimit int
 }
 
 // NewSDKServer creates a new MCP server using the official SDK.
@@ -312,6 +419,12 @@ func NewSDKServer(cfg SDKServerConfig) *SDKServer {
 		projectRoot: cfg.ProjectRoot,
 		codemap:     NewCodemapClient(cfg.Codemap),
 		codemapCfg:  cfg.Codemap,
+		audit:       cfg.AuditLog,
+		readOnly:    cfg.ReadOnly,
+		authToken:   cfg.AuthToken,
+	}
+	if cfg.RateLimit > 0 {
+		s.limiter = newClientLimiter(cfg.RateLimit)
 	}
 
 	// When the project is known up front, set up a lazy session and daemon
@@ -366,6 +479,10 @@ func NewSDKServer(cfg SDKServerConfig) *SDKServer {
 			"Data defaults to ~/.vecgrep/projects/.",
 	})
 
+	if s.audit != nil {
+		s.server.AddReceivingMiddleware(s.auditMiddleware)
+	}
+
 	// Register tools using typed handlers
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
 		Name:        "vecgrep_init",
@@ -396,7 +513,7 @@ func NewSDKServer(cfg SDKServerConfig) *SDKServer {
 
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
 		Name:        "vecgrep_status",
-		Description: "Get index statistics, readiness.state/action, and conservative freshness evidence. Always call this before trusting search when unsure the index is searchable. Freshness is proven from raw source hashes, the last successful ingestion receipt, and codemap's bounded structural manifest when applicable.",
+		Description: "Get index statistics, readiness.state/action, and conservative freshness evidence for the active project. The structured result carries project_root/project_name, files, chunks, languages, model, readiness, freshness, pending changes, and last_indexed_at. Always call this before trusting search when unsure the index is searchable. Freshness is proven from raw source hashes, the last successful ingestion receipt, and codemap's bounded structural manifest when applicable.",
 	}, s.handleStatus)
 
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
@@ -406,12 +523,12 @@ func NewSDKServer(cfg SDKServerConfig) *SDKServer {
 
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
 		Name:        "vecgrep_delete",
-		Description: "Delete a file and all its chunks from the search index.",
+		Description: "Delete a file and all its chunks from the search index. Set dry_run to preview the chunk count without deleting.",
 	}, s.handleDelete)
 
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
 		Name:        "vecgrep_clean",
-		Description: "Sync the vector database to disk and report current index statistics (record/file counts). Pure veclite storage has no orphaned rows to vacuum; this is a flush-and-report operation.",
+		Description: "Sync the vector database to disk and report current index statistics (record/file counts). Pure veclite storage has no orphaned rows to vacuum; this is a flush-and-report operation. Set dry_run to report without flushing.",
 	}, s.handleClean)
 
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
@@ -421,7 +538,7 @@ func NewSDKServer(cfg SDKServerConfig) *SDKServer {
 
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
 		Name:        "vecgrep_overview",
-		Description: "Get high-level overview of the codebase structure including languages, directory structure, entry points, and key files.",
+		Description: "Get high-level overview of the codebase structure including languages, directory structure, stored module summaries, entry points, and key files.",
 	}, s.handleOverview)
 
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
@@ -429,6 +546,11 @@ func NewSDKServer(cfg SDKServerConfig) *SDKServer {
 		Description: "Search multiple queries in parallel. Returns results grouped by query with optional deduplication.",
 	}, s.handleBatchSearch)
 
+	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
+		Name:        "vecgrep_search_all",
+		Description: "Search every project registered in ~/.vecgrep/config.yaml with one query. Results are merged by score and each carries its project name; projects that cannot be searched are listed as warnings.",
+	}, s.handleSearchAll)
+
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
 		Name:        "vecgrep_related_files",
 		Description: "Find files related to a given file (imports, tests, configs). Useful for understanding code dependencies.",
@@ -444,15 +566,25 @@ func NewSDKServer(cfg SDKServerConfig) *SDKServer {
 		Description: "Investigate a changed symbol's blast radius: runs codemap impact to find all affected files, then scopes a semantic search to that file set. Falls back to unscoped search when codemap is unavailable or not indexed.",
 	}, s.handleInvestigate)
 
-	// Memory tools (global, not project-specific)
+	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
+		Name:        "vecgrep_bookmark",
+		Description: "Pin, list, or remove bookmarked chunks for the active project. Bookmarks are keyed by location (relative_path:start_line-end_line) with an optional note and tags, so findings can be recalled later without re-running a query.",
+	}, s.handleBookmark)
+
+	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
+		Name:        "vecgrep_feedback",
+		Description: "Record whether a search result answered a query, list recorded judgments, or export them as an embedding benchmark dataset. When the same query is searched again, chunks judged relevant are boosted and chunks judged irrelevant are demoted.",
+	}, s.handleFeedback)
+
+	// Memory tools (global by default; scope=project uses the active project's store)
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
 		Name:        "memory_remember",
-		Description: "Store a memory with optional importance, tags, and TTL. Memories are stored globally and persist across sessions.",
+		Description: "Store a memory with optional importance, tags, and TTL. Memories persist across sessions: globally by default, or with scope=project in the active project's data directory, where memories about that codebase travel with it.",
 	}, s.handleMemoryRemember)
 
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
 		Name:        "memory_recall",
-		Description: "Search memories semantically. Returns memories ranked by relevance to your query.",
+		Description: "Search memories semantically. Returns memories ranked by relevance to your query, optionally limited to a creation time window (since/until). Searches the global store unless scope=project.",
 	}, s.handleMemoryRecall)
 
 	sdkmcp.AddTool(s.server, &sdkmcp.Tool{
@@ -465,6 +597,12 @@ func NewSDKServer(cfg SDKServerConfig) *SDKServer {
 		Description: "Get memory store statistics including total count, tags, and age distribution.",
 	}, s.handleMemoryStats)
 
+	if s.readOnly {
+		s.server.RemoveTools(readOnlyExcludedTools...)
+	}
+
+	s.registerResources()
+
 	return s
 }
 
@@ -541,7 +679,7 @@ func (s *SDKServer) acquireProjectReadSnapshot(ctx context.Context) (projectRead
 		return projectReadSnapshot{}, err
 	}
 	if rErr := state.session.reloadIfStale(); rErr != nil {
-		log.Printf("vecgrep: read-only index reload failed: %v (status may reflect stale persisted data)", rErr)
+		slog.Warn("read-only index reload failed; status may reflect stale persisted data", "err", rErr)
 	}
 	return projectReadSnapshot{
 		projectStateSnapshot: state,
@@ -571,6 +709,9 @@ func (s *SDKServer) ensureInitialized(ctx context.Context) error {
 
 	// Try to auto-detect project from current working directory
 	projectRoot, err := config.GetProjectRoot()
+	if err != nil && s.readOnly {
+		return fmt.Errorf("no vecgrep project found; a read-only server only serves the project it was started in")
+	}
 	if err != nil {
 		// Not found via local markers or global config - try to auto-register globally
 		cwd, cwdErr := os.Getwd()
@@ -620,11 +761,11 @@ func (s *SDKServer) ensureMemoryInitialized(ctx context.Context) error {
 	cfg := memory.DefaultConfig()
 
 	// Create embedding provider for memory
-	provider := embed.NewOllamaProvider(embed.OllamaConfig{
+	provider := embed.NewNormalizedProvider(embed.NewOllamaProvider(embed.OllamaConfig{
 		URL:        cfg.OllamaURL,
 		Model:      cfg.EmbeddingModel,
 		Dimensions: cfg.EmbeddingDimensions,
-	})
+	}))
 
 	// Check if provider is available
 	if err := provider.Ping(ctx); err != nil {
@@ -640,9 +781,47 @@ func (s *SDKServer) ensureMemoryInitialized(ctx context.Context) error {
 	}
 
 	s.memoryStore = store
+	s.memoryProvider = provider
 	return nil
 }
 
+// memoryStoreFor returns the store a memory tool's scope argument selects,
+// after ensureMemoryInitialized has succeeded. The project store lives in the
+// active project's data directory and is opened on first use.
+func (s *SDKServer) memoryStoreFor(ctx context.Context, scope string) (*memory.MemoryStore, memory.Scope, error) {
+	parsed, err := memory.ParseScope(scope)
+	if err != nil {
+		return nil, "", err
+	}
+	if parsed == memory.ScopeGlobal {
+		return s.memoryStore, parsed, nil
+	}
+
+	if err := s.ensureInitialized(ctx); err != nil {
+		return nil, parsed, err
+	}
+	state := s.snapshotProjectState()
+	if state.cfg == nil || state.cfg.DataDir == "" {
+		return nil, parsed, fmt.Errorf("no active project for scope=project; run vecgrep_init or use scope=global")
+	}
+	cfg := memory.ProjectConfig(state.cfg.DataDir)
+
+	s.memoryInitMu.Lock()
+	defer s.memoryInitMu.Unlock()
+	if store, ok := s.projectMemoryStores[cfg.DBPath]; ok {
+		return store, parsed, nil
+	}
+	store, err := memory.NewMemoryStore(cfg, s.memoryProvider)
+	if err != nil {
+		return nil, parsed, fmt.Errorf("failed to open project memory store: %w", err)
+	}
+	if s.projectMemoryStores == nil {
+		s.projectMemoryStores = make(map[string]*memory.MemoryStore)
+	}
+	s.projectMemoryStores[cfg.DBPath] = store
+	return store, parsed, nil
+}
+
 // handleInit handles the vecgrep_init tool.
 func (s *SDKServer) handleInit(ctx context.Context, req *sdkmcp.CallToolRequest, input InitInput) (*sdkmcp.CallToolResult, any, error) {
 	path := input.Path
@@ -957,14 +1136,22 @@ func checkEmbeddingProvider(ctx context.Context, p embed.Provider) *sdkmcp.CallT
 // formatDaemonSearchResult formats the JSON result from a daemon.search
 // socket call into the same text format as the direct search path. The
 // result JSON has the shape {"results": [...], "mode": "...", "warnings": [...]}.
-func formatDaemonSearchResult(raw json.RawMessage, scopeNote string) string {
+func formatDaemonSearchResult(raw json.RawMessage, scopeNote string, budget int) string {
+	text, _, _ := formatDaemonSearchResultAs(raw, scopeNote, budget, "")
+	return text
+}
+
+// formatDaemonSearchResultAs is formatDaemonSearchResult for a vecgrep_search
+// format, returning the citations when format is "citations" and the number
+// of results written.
+func formatDaemonSearchResultAs(raw json.RawMessage, scopeNote string, budget int, format string) (string, []search.Citation, int) {
 	var resp struct {
 		Results  []search.Result `json:"results"`
 		Mode     string          `json:"mode"`
 		Warnings []string        `json:"warnings"`
 	}
 	if err := json.Unmarshal(raw, &resp); err != nil {
-		return fmt.Sprintf("daemon search result parse error: %v", err)
+		return fmt.Sprintf("daemon search result parse error: %v", err), nil, 0
 	}
 
 	var sb strings.Builder
@@ -975,20 +1162,33 @@ func formatDaemonSearchResult(raw json.RawMessage, scopeNote string) string {
 	for _, w := range resp.Warnings {
 		fmt.Fprintf(&sb, "> **Warning:** %s\n\n", w)
 	}
-	formatSearchResults(&sb, resp.Results)
-	return sb.String()
+	written, citations := writeSearchHits(&sb, resp.Results, format, budget)
+	return sb.String(), citations, len(written)
+}
+
+// writeSearchHits renders search results in a vecgrep_search format. It
+// returns the results that fit the response budget and, for the citations
+// format, the citations that were written. Citations carry no snippets, so
+// they are never trimmed to the budget.
+func writeSearchHits(sb *strings.Builder, results []search.Result, format string, budget int) ([]search.Result, []search.Citation) {
+	if format == searchFormatCitations {
+		citations := search.NewCitations(results)
+		if len(citations) == 0 {
+			sb.WriteString("No results found.\n")
+		}
+		sb.WriteString(search.CitationList(citations))
+		return results, citations
+	}
+	results, dropped := capResults(results, budget)
+	writeTruncationNote(sb, dropped, budget)
+	formatSearchResults(sb, results)
+	return results, nil
 }
 
 // formatStatsResult formats the JSON stats result from a daemon.stats socket
 // call into the same text format as the direct status path.
 func formatStatsResult(raw json.RawMessage, projectRoot string) string {
-	var stats struct {
-		TotalFiles     int64                     `json:"total_files"`
-		TotalChunks    int64                     `json:"total_chunks"`
-		Languages      map[string]int64          `json:"languages"`
-		Freshness      *app.IndexFreshnessReport `json:"freshness"`
-		PendingChanges *index.PendingChanges     `json:"pending_changes"`
-	}
+	var stats daemonStats
 	if err := json.Unmarshal(raw, &stats); err != nil {
 		return fmt.Sprintf("stats parse error: %v", err)
 	}
@@ -998,21 +1198,93 @@ func formatStatsResult(raw json.RawMessage, projectRoot string) string {
 
 	fmt.Fprintf(&sb, "Total files: %d\n", stats.TotalFiles)
 	fmt.Fprintf(&sb, "Total chunks: %d\n", stats.TotalChunks)
-	if len(stats.Languages) > 0 {
-		languages := make([]string, 0, len(stats.Languages))
-		for language := range stats.Languages {
-			languages = append(languages, language)
-		}
-		sort.Strings(languages)
-		sb.WriteString("\nBy language:\n")
-		for _, language := range languages {
-			fmt.Fprintf(&sb, "  %s: %d\n", language, stats.Languages[language])
-		}
-	}
+	writeLanguageStats(&sb, stats.Languages, stats.LanguageLines, stats.LanguageBytes)
 	writeFreshnessStatus(&sb, stats.Freshness, stats.PendingChanges)
 	return sb.String()
 }
 
+// daemonStats is the subset of a daemon.stats response used by vecgrep_status.
+type daemonStats struct {
+	TotalFiles     int64                     `json:"total_files"`
+	TotalChunks    int64                     `json:"total_chunks"`
+	Languages      map[string]int64          `json:"languages"`
+	LanguageLines  map[string]int64          `json:"language_lines"`
+	LanguageBytes  map[string]int64          `json:"language_bytes"`
+	Model          string                    `json:"embedding_model"`
+	Dimensions     int                       `json:"embedding_dimensions"`
+	Freshness      *app.IndexFreshnessReport `json:"freshness"`
+	PendingChanges *index.PendingChanges     `json:"pending_changes"`
+}
+
+// daemonStatusOutput builds the structured vecgrep_status result from a
+// daemon.stats response. Daemon stats carry no readiness.
+func daemonStatusOutput(raw json.RawMessage, state projectStateSnapshot) *StatusOutput {
+	var stats daemonStats
+	if err := json.Unmarshal(raw, &stats); err != nil {
+		return nil
+	}
+	out := &StatusOutput{
+		ProjectRoot: state.projectRoot,
+		ProjectName: state.projectName,
+		Files:       stats.TotalFiles,
+		Chunks:      stats.TotalChunks,
+		Languages:   stats.Languages,
+		Model:       stats.Model,
+		Dimensions:  stats.Dimensions,
+		Freshness:   stats.Freshness,
+		Pending:     stats.PendingChanges,
+	}
+	if stats.Freshness != nil {
+		out.LastIndexedAt = stats.Freshness.ReceiptLastSuccess
+	}
+	return out
+}
+
+// writeLanguageStats writes the per-language breakdown in name order: chunk
+// count plus, when known, the line and byte totals of the indexed files.
+func writeLanguageStats(sb *strings.Builder, chunks, lines, bytes map[string]int64) {
+	if len(chunks) == 0 {
+		return
+	}
+	languages := make([]string, 0, len(chunks))
+	for language := range chunks {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	sb.WriteString("\nBy language:\n")
+	for _, language := range languages {
+		fmt.Fprintf(sb, "  %s: %s\n", language, languageVolume(chunks[language], lines[language], bytes[language]))
+	}
+}
+
+// languageVolume renders "N chunks, L lines, S" for one language, dropping
+// line and size totals that are unknown.
+func languageVolume(chunks, lines, bytes int64) string {
+	parts := []string{fmt.Sprintf("%d chunks", chunks)}
+	if lines > 0 {
+		parts = append(parts, fmt.Sprintf("%d lines", lines))
+	}
+	if bytes > 0 {
+		parts = append(parts, formatBytes(bytes))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes)
+	for _, suffix := range []string{"KiB", "MiB", "GiB", "TiB"} {
+		value /= unit
+		if value < unit {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return fmt.Sprintf("%.1f PiB", value/unit)
+}
+
 func writeFreshnessStatus(sb *strings.Builder, freshness *app.IndexFreshnessReport, pending *index.PendingChanges) {
 	if sb == nil || freshness == nil {
 		return
@@ -1047,6 +1319,12 @@ func (s *SDKServer) handleSearch(ctx context.Context, req *sdkmcp.CallToolReques
 			IsError: true,
 		}, nil, nil
 	}
+	if input.Format != "" && input.Format != "default" && input.Format != searchFormatCitations {
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("invalid format %q: want default or citations", input.Format)}},
+			IsError: true,
+		}, nil, nil
+	}
 	state, err := s.acquireProjectOperationSnapshot()
 	if err != nil {
 		return &sdkmcp.CallToolResult{
@@ -1084,63 +1362,19 @@ func (s *SDKServer) handleSearch(ctx context.Context, req *sdkmcp.CallToolReques
 		return errResult, nil, nil
 	}
 
-	opts := search.DefaultSearchOptions()
-	opts.ProjectRoot = state.projectRoot
-	// Honor the project's configured hybrid weights, matching the app layer
-	// and daemon paths; zero values fall back to the defaults inside the
-	// search/db layers.
-	opts.VectorWeight = state.cfg.Search.VectorWeight
-	opts.TextWeight = state.cfg.Search.TextWeight
-
-	// Apply input options
-	if input.Limit > 0 {
-		opts.Limit = input.Limit
-	}
-	if input.Language != "" {
-		opts.Language = input.Language
-	}
-	if len(input.Languages) > 0 {
-		opts.Languages = input.Languages
-	}
-	if input.ChunkType != "" {
-		opts.ChunkType = input.ChunkType
-	}
-	if len(input.ChunkTypes) > 0 {
-		opts.ChunkTypes = input.ChunkTypes
-	}
-	if input.FilePattern != "" {
-		opts.FilePattern = input.FilePattern
-	}
-	if input.Directory != "" {
-		opts.Directory = input.Directory
-	}
-	if input.MinLine > 0 {
-		opts.MinLine = input.MinLine
-	}
-	if input.MaxLine > 0 {
-		opts.MaxLine = input.MaxLine
-	}
-	if input.MinScore > 0 {
-		opts.MinScore = input.MinScore
-	}
-
-	// Apply file scoping. Direct file_paths take precedence; otherwise,
-	// when symbol is set, resolve the blast radius via codemap impact.
-	scopeFiles, scopeNote := state.resolveSearchScope(ctx, input)
-	if len(scopeFiles) > 0 {
-		opts.FilePaths = scopeFiles
+	opts, rootLabels, scopeNote, err := state.searchOptions(ctx, input)
+	if err != nil {
+		readState.release()
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: err.Error()}},
+			IsError: true,
+		}, nil, nil
 	}
 
-	// Parse search mode
-	switch strings.ToLower(input.Mode) {
-	case "semantic":
-		opts.Mode = search.SearchModeSemantic
-	case "keyword":
-		opts.Mode = search.SearchModeKeyword
-	case "hybrid", "":
-		opts.Mode = search.SearchModeHybrid
-	default:
-		opts.Mode = search.SearchModeHybrid
+	var inferNote string
+	input, opts, inferNote = readState.inferSearchScope(input, opts)
+	if inferNote != "" {
+		scopeNote = strings.TrimPrefix(scopeNote+"\n\n"+inferNote, "\n\n")
 	}
 
 	var sb strings.Builder
@@ -1155,7 +1389,9 @@ func (s *SDKServer) handleSearch(ctx context.Context, req *sdkmcp.CallToolReques
 	// Prefer the daemon for the actual query when available (warm session).
 	// Release the readiness RO lease first so we do not hold a local lock
 	// across the socket round-trip; re-acquire RO only if the daemon fails.
-	if dc := state.daemon; dc != nil && dc.available() {
+	// The daemon searches the working tree only, so refs stay local, and it
+	// does not expand queries.
+	if dc := state.daemon; dc != nil && dc.available() && input.Ref == "" && !input.Expand {
 		readState.release()
 		params := daemonSearchParams{
 			Query:       input.Query,
@@ -1170,17 +1406,22 @@ func (s *SDKServer) handleSearch(ctx context.Context, req *sdkmcp.CallToolReques
 			MinLine:     input.MinLine,
 			MaxLine:     input.MaxLine,
 			MinScore:    input.MinScore,
+			Tags:        input.Tags,
+			EfSearch:    input.EfSearch,
 			Explain:     input.Explain,
 			FilePaths:   opts.FilePaths,
+			Rerank:      input.Rerank,
 		}
 		rawResult, dErr := dc.search(ctx, params)
 		if dErr == nil {
 			var body strings.Builder
 			writeReadiness(&body, readiness)
-			body.WriteString(formatDaemonSearchResult(rawResult, scopeNote))
+			text, citations, hits := formatDaemonSearchResultAs(rawResult, scopeNote, maxResponseBytes(state.cfg), input.Format)
+			auditResults(ctx, hits)
+			body.WriteString(text)
 			return &sdkmcp.CallToolResult{
 				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: body.String()}},
-			}, readiness, nil
+			}, searchOutput(readiness, input.Format, citations), nil
 		}
 		// Daemon failed: re-open RO for the local search path.
 		readState, err = state.acquireRead(ctx)
@@ -1193,16 +1434,19 @@ func (s *SDKServer) handleSearch(ctx context.Context, req *sdkmcp.CallToolReques
 	}
 	defer readState.release()
 	s.observeReadSnapshot("search", readState)
+	query, opts := state.translateWithService(ctx, input.Query, opts, &sb)
 
 	// Perform search with or without explanation
+	var citations []search.Citation
 	if input.Explain {
-		results, explanation, err := readState.searcher.SearchWithExplain(ctx, input.Query, opts)
+		results, explanation, err := readState.searcher.SearchWithExplain(ctx, query, opts)
 		if err != nil {
 			return &sdkmcp.CallToolResult{
 				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Search error: %v", err)}},
 				IsError: true,
 			}, readiness, nil
 		}
+		search.LabelRoots(results, rootLabels)
 
 		// Add explanation to output
 		sb.WriteString("**Search Diagnostics:**\n")
@@ -1211,18 +1455,18 @@ func (s *SDKServer) handleSearch(ctx context.Context, req *sdkmcp.CallToolReques
 		fmt.Fprintf(&sb, "- Duration: %v\n", explanation.Duration)
 		fmt.Fprintf(&sb, "- Mode: %s\n\n", explanation.Mode)
 
-		// Expand context lines if requested
-		if input.ContextLines > 0 {
-			for i := range results {
-				results[i].Content = expandContextLines(state.projectRoot, results[i], input.ContextLines)
-			}
-		}
+		results = state.rerankWithService(ctx, query, input.Rerank, results, &sb)
+		results = state.applySearchFeedback(input.Query, results, &sb)
+
+		search.ExpandResults(opts.ProjectRoot, results, input.ContextLines, input.ContextLines)
+		search.TruncateResults(results, query, input.MaxSnippetLines)
 
 		state.rerankWithCodemap(ctx, results, state.codemapStructuralWeight())
-		formatSearchResults(&sb, results)
-		state.annotateSearchHits(ctx, results, input.Query)
+		results, citations = writeSearchHits(&sb, results, input.Format, maxResponseBytes(state.cfg))
+		auditResults(ctx, len(results))
+		state.annotateSearchHits(ctx, results, query)
 	} else {
-		outcome, err := readState.searcher.SearchWithOutcome(ctx, input.Query, opts)
+		outcome, err := readState.searcher.SearchWithOutcome(ctx, query, opts)
 		if err != nil {
 			return &sdkmcp.CallToolResult{
 				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Search error: %v", err)}},
@@ -1230,6 +1474,7 @@ func (s *SDKServer) handleSearch(ctx context.Context, req *sdkmcp.CallToolReques
 			}, readiness, nil
 		}
 		results := outcome.Results
+		search.LabelRoots(results, rootLabels)
 
 		// Surface degraded-mode diagnostics (e.g. embedder unavailable →
 		// keyword-only results) so a fallback is never silent.
@@ -1237,21 +1482,119 @@ func (s *SDKServer) handleSearch(ctx context.Context, req *sdkmcp.CallToolReques
 			fmt.Fprintf(&sb, "> **Warning:** %s\n\n", w)
 		}
 
-		// Expand context lines if requested
-		if input.ContextLines > 0 {
-			for i := range results {
-				results[i].Content = expandContextLines(state.projectRoot, results[i], input.ContextLines)
-			}
-		}
+		results = state.rerankWithService(ctx, query, input.Rerank, results, &sb)
+		results = state.applySearchFeedback(input.Query, results, &sb)
+
+		search.ExpandResults(opts.ProjectRoot, results, input.ContextLines, input.ContextLines)
+		search.TruncateResults(results, query, input.MaxSnippetLines)
 
 		state.rerankWithCodemap(ctx, results, state.codemapStructuralWeight())
-		formatSearchResults(&sb, results)
-		state.annotateSearchHits(ctx, results, input.Query)
+		results, citations = writeSearchHits(&sb, results, input.Format, maxResponseBytes(state.cfg))
+		auditResults(ctx, len(results))
+		state.annotateSearchHits(ctx, results, query)
 	}
 
 	return &sdkmcp.CallToolResult{
 		Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: sb.String()}},
-	}, readiness, nil
+	}, searchOutput(readiness, input.Format, citations), nil
+}
+
+// searchOptions builds the search options of a vecgrep_search input: the
+// project's roots and hybrid settings, the input's filters, and its file
+// scope. It also returns the labels of extra roots and a note describing the
+// scope, and fails only when input.Ref was never indexed.
+func (state projectStateSnapshot) searchOptions(ctx context.Context, input SearchInput) (search.SearchOptions, map[string]string, string, error) {
+	opts := search.DefaultSearchOptions()
+	opts.ProjectRoot = state.projectRoot
+	var rootLabels map[string]string
+	opts.ProjectRoots, rootLabels = app.SearchRoots(state.projectRoot, state.cfg)
+	if input.Ref != "" {
+		refRoot, err := app.IndexedRefRoot(state.cfg.DataDir, input.Ref)
+		if err != nil {
+			return opts, nil, "", err
+		}
+		opts.ProjectRoot, opts.ProjectRoots, rootLabels = refRoot, nil, nil
+	}
+	// Honor the project's configured hybrid weights, matching the app layer
+	// and daemon paths; zero values fall back to the defaults inside the
+	// search/db layers.
+	opts.VectorWeight = state.cfg.Search.VectorWeight
+	opts.TextWeight = state.cfg.Search.TextWeight
+	opts.Fusion = state.cfg.Search.Fusion
+
+	// Apply input options
+	if input.Limit > 0 {
+		opts.Limit = input.Limit
+	}
+	if input.Language != "" {
+		opts.Language = input.Language
+	}
+	if len(input.Languages) > 0 {
+		opts.Languages = input.Languages
+	}
+	if input.ChunkType != "" {
+		opts.ChunkType = input.ChunkType
+	}
+	if len(input.ChunkTypes) > 0 {
+		opts.ChunkTypes = input.ChunkTypes
+	}
+	if input.FilePattern != "" {
+		opts.FilePattern = input.FilePattern
+	}
+	if input.Directory != "" {
+		opts.Directory = input.Directory
+	}
+	if input.MinLine > 0 {
+		opts.MinLine = input.MinLine
+	}
+	if input.MaxLine > 0 {
+		opts.MaxLine = input.MaxLine
+	}
+	if input.MinScore > 0 {
+		opts.MinScore = input.MinScore
+	}
+	if len(input.Tags) > 0 {
+		opts.Tags = input.Tags
+	}
+	if input.EfSearch > 0 {
+		opts.EfSearch = input.EfSearch
+	}
+	if input.Expand {
+		opts.Expand = true
+		opts.Expander = app.NewSearchExpander(state.cfg)
+	}
+
+	// Apply file scoping. Direct file_paths take precedence; otherwise,
+	// when symbol is set, resolve the blast radius via codemap impact.
+	scopeFiles, scopeNote := state.resolveSearchScope(ctx, input)
+	if len(scopeFiles) > 0 {
+		opts.FilePaths = scopeFiles
+	}
+
+	// Parse search mode
+	switch strings.ToLower(input.Mode) {
+	case "semantic":
+		opts.Mode = search.SearchModeSemantic
+	case "keyword":
+		opts.Mode = search.SearchModeKeyword
+	case "hybrid", "":
+		opts.Mode = search.SearchModeHybrid
+	default:
+		opts.Mode = search.SearchModeHybrid
+	}
+	return opts, rootLabels, scopeNote, nil
+}
+
+// searchOutput is the structured vecgrep_search result: the readiness
+// payload, with the citations alongside it for the citations format.
+func searchOutput(readiness app.Readiness, format string, citations []search.Citation) any {
+	if format != searchFormatCitations {
+		return readiness
+	}
+	if citations == nil {
+		citations = []search.Citation{}
+	}
+	return SearchCitationsOutput{Readiness: readiness, Citations: citations}
 }
 
 // formatSearchResults formats search results into markdown, including match
@@ -1270,6 +1613,9 @@ func formatSearchResults(sb *strings.Builder, results []search.Result) {
 	for i, r := range results {
 		fmt.Fprintf(sb, "### Result %d (score: %.2f)\n", i+1, r.Score)
 		fmt.Fprintf(sb, "**File:** %s (lines %d-%d)\n", r.RelativePath, r.StartLine, r.EndLine)
+		if r.Root != "" {
+			fmt.Fprintf(sb, "**Root:** %s\n", r.Root)
+		}
 		if r.SymbolName != "" {
 			fmt.Fprintf(sb, "**Symbol:** %s\n", r.SymbolName)
 		}
@@ -1460,6 +1806,7 @@ func (s *SDKServer) handleIndex(ctx context.Context, req *sdkmcp.CallToolRequest
 	if dc := state.daemon; dc != nil && dc.available() {
 		if structuralMode == app.StructuralChunksRequired || input.Force || len(input.Paths) > 0 {
 			result, err = dc.reindexSync(ctx, input.Force, string(structuralMode), input.Paths)
+			s.progress.finish("", err)
 			if err != nil {
 				return &sdkmcp.CallToolResult{
 					Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Indexing error: %v", err)}},
@@ -1482,7 +1829,8 @@ func (s *SDKServer) handleIndex(ctx context.Context, req *sdkmcp.CallToolRequest
 			Paths:            input.Paths,
 			FullReindex:      input.Force,
 			StructuralChunks: string(structuralMode),
-		})
+		}, s.progress.callback(""))
+		s.progress.finish("", err)
 		if err != nil {
 			return &sdkmcp.CallToolResult{
 				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Indexing error: %s", formatLockError(err))}},
@@ -1498,6 +1846,18 @@ func (s *SDKServer) handleIndex(ctx context.Context, req *sdkmcp.CallToolRequest
 	fmt.Fprintf(&sb, "- Files skipped (unchanged): %d\n", result.FilesSkipped)
 	fmt.Fprintf(&sb, "- Files deleted: %d\n", result.FilesDeleted)
 	fmt.Fprintf(&sb, "- Chunks created: %d\n", result.ChunksCreated)
+	if result.ChunksReused > 0 {
+		fmt.Fprintf(&sb, "- Chunks reused (unchanged): %d\n", result.ChunksReused)
+	}
+	if result.ChunksTruncated > 0 {
+		fmt.Fprintf(&sb, "- Chunks truncated to the model's input limit: %d%s\n", result.ChunksTruncated, result.EstimateNote())
+	}
+	if result.ChunksSplit > 0 {
+		fmt.Fprintf(&sb, "- Overlong chunks split: %d%s\n", result.ChunksSplit, result.EstimateNote())
+	}
+	if result.ChunksWithSecrets > 0 {
+		fmt.Fprintf(&sb, "- Chunks with possible secrets: %d\n", result.ChunksWithSecrets)
+	}
 	fmt.Fprintf(&sb, "- Duration: %s\n", result.Duration)
 
 	if len(result.Errors) > 0 {
@@ -1519,6 +1879,52 @@ func (s *SDKServer) handleIndex(ctx context.Context, req *sdkmcp.CallToolRequest
 	}, nil, nil
 }
 
+// handleSearchAll handles the vecgrep_search_all tool. It does not need an
+// active project: every registered project is opened read-only on its own.
+func (s *SDKServer) handleSearchAll(ctx context.Context, req *sdkmcp.CallToolRequest, input SearchAllInput) (*sdkmcp.CallToolResult, any, error) {
+	if input.Query == "" {
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: "query parameter is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	resp, err := app.SearchAllProjects(ctx, app.SearchRequest{
+		Query:     input.Query,
+		Limit:     limit,
+		Mode:      search.SearchMode(input.Mode),
+		Language:  input.Language,
+		ChunkType: input.ChunkType,
+		MinScore:  input.MinScore,
+		Tags:      input.Tags,
+	})
+	if err != nil {
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Cross-project search failed: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+	search.TruncateResults(resp.Results, input.Query, input.MaxSnippetLines)
+	budget := maxResponseBytes(s.snapshotProjectState().cfg)
+	results, dropped := capResults(resp.Results, budget)
+	auditResults(ctx, len(results))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Searched %d project(s).\n", resp.Searched)
+	for _, w := range resp.Warnings {
+		fmt.Fprintf(&sb, "**Warning:** %s\n", w)
+	}
+	sb.WriteString("\n")
+	writeTruncationNote(&sb, dropped, budget)
+	sb.WriteString(search.FormatResults(results, search.FormatDefault))
+	return &sdkmcp.CallToolResult{
+		Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: sb.String()}},
+	}, nil, nil
+}
+
 // handleStatus handles the vecgrep_status tool.
 func (s *SDKServer) handleStatus(ctx context.Context, req *sdkmcp.CallToolRequest, input StatusInput) (*sdkmcp.CallToolResult, any, error) {
 	if err := s.ensureInitialized(ctx); err != nil {
@@ -1536,9 +1942,13 @@ func (s *SDKServer) handleStatus(ctx context.Context, req *sdkmcp.CallToolReques
 		state := s.snapshotProjectState()
 		if dc := state.daemon; dc != nil && dc.available() {
 			if rawStats, dErr := dc.stats(ctx); dErr == nil {
+				var structured any
+				if out := daemonStatusOutput(rawStats, state); out != nil {
+					structured = out
+				}
 				return &sdkmcp.CallToolResult{
 					Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: formatStatsResult(rawStats, state.projectRoot)}},
-				}, nil, nil
+				}, structured, nil
 			}
 		}
 		return &sdkmcp.CallToolResult{
@@ -1551,54 +1961,97 @@ func (s *SDKServer) handleStatus(ctx context.Context, req *sdkmcp.CallToolReques
 		s.statusSnapshotHook(readState)
 	}
 
-	stats, err := readState.searcher.GetIndexStats(ctx)
+	out, text, err := readState.status(ctx)
 	if err != nil {
 		return &sdkmcp.CallToolResult{
 			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Error getting stats: %v", err)}},
 			IsError: true,
 		}, nil, nil
 	}
+	return &sdkmcp.CallToolResult{
+		Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: text}},
+	}, out, nil
+}
+
+// status builds the vecgrep_status report from one read snapshot: the
+// structured result and the text report carrying the same facts. The error
+// is from reading the index stats.
+func (state projectReadSnapshot) status(ctx context.Context) (*StatusOutput, string, error) {
+	stats, err := state.searcher.GetIndexStats(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	out := &StatusOutput{ProjectRoot: state.projectRoot, ProjectName: state.projectName}
+	out.Model, _ = stats["embedding_model"].(string)
+	out.Dimensions, _ = stats["embedding_dimensions"].(int)
 
 	// Format stats as text
 	var sb strings.Builder
 	sb.WriteString("Index Statistics:\n\n")
+	if out.ProjectName != "" {
+		fmt.Fprintf(&sb, "Project: %s (%s)\n", out.ProjectName, out.ProjectRoot)
+	} else {
+		fmt.Fprintf(&sb, "Project: %s\n", out.ProjectRoot)
+	}
+	if out.Model != "" {
+		fmt.Fprintf(&sb, "Model: %s (%d dimensions)\n", out.Model, out.Dimensions)
+	}
 
 	if totalFiles, ok := stats["total_files"].(int64); ok {
+		out.Files = totalFiles
 		fmt.Fprintf(&sb, "Total files: %d\n", totalFiles)
 	}
 	if totalChunks, ok := stats["total_chunks"].(int64); ok {
+		out.Chunks = totalChunks
 		fmt.Fprintf(&sb, "Total chunks: %d\n", totalChunks)
 	}
 	if langStats, ok := stats["languages"].(map[string]int64); ok {
-		sb.WriteString("\nBy language:\n")
-		for lang, count := range langStats {
-			fmt.Fprintf(&sb, "  %s: %d\n", lang, count)
-		}
+		out.Languages = langStats
+		langLines, _ := stats["language_lines"].(map[string]int64)
+		langBytes, _ := stats["language_bytes"].(map[string]int64)
+		writeLanguageStats(&sb, langStats, langLines, langBytes)
 	}
 
 	// The same leased database and activation config drive readiness + freshness.
-	statusService := serviceFromRead(readState)
+	statusService := serviceFromRead(state)
 	readiness, readinessErr := statusService.Readiness(ctx)
 	if readinessErr == nil {
 		writeReadiness(&sb, readiness)
+		out.Readiness = &readiness
 	}
 	freshness, pending, freshnessErr := statusService.IndexFreshness(ctx)
 	if freshnessErr == nil {
 		writeFreshnessStatus(&sb, freshness, pending)
+		out.Freshness = freshness
+		out.Pending = pending
+		if freshness != nil {
+			out.LastIndexedAt = freshness.ReceiptLastSuccess
+		}
+	}
+	if health, err := statusService.Health(ctx, pending); err == nil {
+		writeHealthStatus(&sb, health)
+		out.Health = health
 	}
 
 	// Report codemap integration from the same activation snapshot.
-	if readState.codemapCfg.Enabled {
-		writeCodemapStatusFor(ctx, &sb, readState.codemap, readState.projectRoot)
+	if state.codemapCfg.Enabled {
+		writeCodemapStatusFor(ctx, &sb, state.codemap, state.projectRoot)
 	}
 
-	var structured any
-	if readinessErr == nil {
-		structured = readiness
+	return out, sb.String(), nil
+}
+
+// writeHealthStatus reports the index health score and what took points off.
+func writeHealthStatus(sb *strings.Builder, health *app.IndexHealth) {
+	fmt.Fprintf(sb, "\nHealth: %d/100 (%s)\n", health.Score, health.Grade)
+	for _, reason := range health.Reasons {
+		fmt.Fprintf(sb, "  -%d %s", reason.Penalty, reason.Detail)
+		if reason.Fix != "" {
+			fmt.Fprintf(sb, " (fix: %s)", reason.Fix)
+		}
+		sb.WriteString("\n")
 	}
-	return &sdkmcp.CallToolResult{
-		Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: sb.String()}},
-	}, structured, nil
 }
 
 // writeCodemapStatus reports the peer codemap graph's state (G4 cross-read):
@@ -1649,16 +2102,19 @@ func (s *SDKServer) handleSimilar(ctx context.Context, req *sdkmcp.CallToolReque
 	if input.Text != "" {
 		specCount++
 	}
+	if input.Symbol != "" {
+		specCount++
+	}
 
 	if specCount == 0 {
 		return &sdkmcp.CallToolResult{
-			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: "Error: Provide exactly one of chunk_id, file_location, or text."}},
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: "Error: Provide exactly one of chunk_id, file_location, text, or symbol."}},
 			IsError: true,
 		}, nil, nil
 	}
 	if specCount > 1 {
 		return &sdkmcp.CallToolResult{
-			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: "Error: Provide only one of chunk_id, file_location, or text (not multiple)."}},
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: "Error: Provide only one of chunk_id, file_location, text, or symbol (not multiple)."}},
 			IsError: true,
 		}, nil, nil
 	}
@@ -1687,6 +2143,7 @@ func (s *SDKServer) handleSimilar(ctx context.Context, req *sdkmcp.CallToolReque
 			Directory:   input.Directory,
 			MinLine:     input.MinLine,
 			MaxLine:     input.MaxLine,
+			Tags:        input.Tags,
 			ProjectRoot: state.projectRoot,
 		},
 		ExcludeSameFile: input.ExcludeSameFile,
@@ -1699,6 +2156,17 @@ func (s *SDKServer) handleSimilar(ctx context.Context, req *sdkmcp.CallToolReque
 
 	var results []search.Result
 
+	if input.Symbol != "" {
+		match, resolveErr := serviceFromRead(state).ResolveSymbol(ctx, input.Symbol)
+		if resolveErr != nil {
+			return &sdkmcp.CallToolResult{
+				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: resolveErr.Error()}},
+				IsError: true,
+			}, nil, nil
+		}
+		input.ChunkID = match.ChunkID
+	}
+
 	if input.ChunkID != 0 {
 		results, err = state.searcher.SearchSimilarByID(ctx, input.ChunkID, opts)
 	} else if input.FileLocation != "" {
@@ -1731,12 +2199,17 @@ func (s *SDKServer) handleSimilar(ctx context.Context, req *sdkmcp.CallToolReque
 
 	// Format results
 	if len(results) == 0 {
+		auditResults(ctx, 0)
 		return &sdkmcp.CallToolResult{
 			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: "No similar code found."}},
 		}, nil, nil
 	}
 
+	budget := maxResponseBytes(state.cfg)
+	results, dropped := capResults(results, budget)
+	auditResults(ctx, len(results))
 	var sb strings.Builder
+	writeTruncationNote(&sb, dropped, budget)
 	fmt.Fprintf(&sb, "Found %d similar code chunks:\n\n", len(results))
 
 	for i, r := range results {
@@ -1780,6 +2253,27 @@ func (s *SDKServer) handleDelete(ctx context.Context, req *sdkmcp.CallToolReques
 		}, nil, nil
 	}
 
+	if input.DryRun {
+		readState, err := s.acquireProjectReadSnapshot(ctx)
+		if err != nil {
+			return &sdkmcp.CallToolResult{
+				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Failed to open database: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+		chunks, err := readState.database.CountProjectFile(ctx, readState.projectRoot, input.FilePath)
+		readState.release()
+		if err != nil {
+			return &sdkmcp.CallToolResult{
+				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Failed to preview delete: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Would delete %s (%d chunks; dry run, index unchanged)", input.FilePath, chunks)}},
+		}, nil, nil
+	}
+
 	state := s.snapshotProjectState()
 	if !state.initialized || state.session == nil || state.projectRoot == "" {
 		return &sdkmcp.CallToolResult{
@@ -1819,6 +2313,31 @@ func (s *SDKServer) handleClean(ctx context.Context, req *sdkmcp.CallToolRequest
 		}, nil, nil
 	}
 
+	if input.DryRun {
+		readState, err := s.acquireProjectReadSnapshot(ctx)
+		if err != nil {
+			return &sdkmcp.CallToolResult{
+				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Failed to open database: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+		stats, err := readState.database.CleanPreview(ctx)
+		readState.release()
+		if err != nil {
+			return &sdkmcp.CallToolResult{
+				Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: fmt.Sprintf("Failed to preview clean: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+		var sb strings.Builder
+		sb.WriteString("Dry run (database not synced, nothing removed):\n")
+		fmt.Fprintf(&sb, "- Records: %d\n", stats.TotalRecords)
+		fmt.Fprintf(&sb, "- Files:   %d\n", stats.TotalFiles)
+		return &sdkmcp.CallToolResult{
+			Content: []sdkmcp.Content{&sdkmcp.TextContent{Text: sb.String()}},
+		}, nil, nil
+	}
+
 	state := s.snapshotProjectState()
 	if !state.initialized || state.session == nil {
 		return &sdkmcp.CallToolResult{
@@ -2032,6 +2551,86 @@ func (state projectStateSnapshot) codemapStructuralWeight() float32 {
 	return 0.15
 }
 
+// inferSearchScope narrows a search by the filters its wording implies (see
+// app.InferScope), returning a note naming them, when search.infer_filters
+// or the infer_filters argument allows it. Callers that scope the search
+// themselves, by arguments or inline filters, get it as written.
+func (state projectReadSnapshot) inferSearchScope(input SearchInput, opts search.SearchOptions) (SearchInput, search.SearchOptions, string) {
+	enabled := state.cfg.Search.InferFiltersEnabled()
+	if input.InferFilters != nil {
+		enabled = *input.InferFilters
+	}
+	if !enabled || input.Language != "" || len(input.Languages) > 0 || input.Directory != "" ||
+		input.FilePattern != "" || len(input.FilePaths) > 0 || input.Symbol != "" || input.Ref != "" {
+		return input, opts, ""
+	}
+	if _, inline := search.ParseQuery(input.Query); !inline.IsZero() {
+		return input, opts, ""
+	}
+	roots := opts.ProjectRoots
+	if len(roots) == 0 {
+		roots = []string{opts.ProjectRoot}
+	}
+	scope, err := app.InferScope(state.database, roots, input.Query)
+	if err != nil || scope.Filters.IsZero() {
+		return input, opts, ""
+	}
+	input.Query = scope.Query
+	if len(scope.Filters.Languages) > 0 {
+		input.Languages = scope.Filters.Languages
+		opts.Languages = scope.Filters.Languages
+	}
+	if scope.FilePaths != nil {
+		opts.FilePaths = scope.FilePaths
+	}
+	return input, opts, fmt.Sprintf("Inferred from the query: %s. Pass infer_filters: false to search it as written.", scope.Filters)
+}
+
+// translateWithService rewrites a non-English query into English through the
+// configured search.translator_url. Inline filters are merged into opts first
+// so only the query text reaches the translator. Keyword searches keep the
+// query as written. The translation, or a warning when it fails, is written
+// to sb.
+func (state projectStateSnapshot) translateWithService(ctx context.Context, query string, opts search.SearchOptions, sb *strings.Builder) (string, search.SearchOptions) {
+	if opts.Mode == search.SearchModeKeyword {
+		return query, opts
+	}
+	translator := search.NewHTTPTranslator(state.cfg.Search.TranslatorURL, state.cfg.Search.TranslatorTimeout)
+	if translator == nil {
+		return query, opts
+	}
+	query, opts = search.ApplyInlineQuery(query, opts)
+	query, translation, warning := search.TranslateQuery(ctx, translator, query)
+	if warning != "" {
+		fmt.Fprintf(sb, "> **Warning:** %s\n\n", warning)
+	}
+	if translation != nil {
+		fmt.Fprintf(sb, "> Query translated from %s: %q\n\n", translation.Language, translation.Translated)
+	}
+	return query, opts
+}
+
+// rerankWithService applies the configured search.reranker_url before
+// context expansion, so the service scores the indexed chunk. A failing
+// service keeps the original order and writes a warning to sb.
+func (state projectStateSnapshot) rerankWithService(ctx context.Context, query string, override *bool, results []search.Result, sb *strings.Builder) []search.Result {
+	results, warning := app.RerankSearchResults(ctx, state.cfg, override, query, results)
+	if warning != "" {
+		fmt.Fprintf(sb, "> **Warning:** %s\n\n", warning)
+	}
+	return results
+}
+
+// applySearchFeedback moves results judged with `vecgrep feedback` for this
+// query. An unreadable feedback store writes a warning to sb.
+func (state projectStateSnapshot) applySearchFeedback(query string, results []search.Result, sb *strings.Builder) []search.Result {
+	results, warning := app.ApplySearchFeedback(state.cfg.DataDir, query, results)
+	if warning != "" {
+		fmt.Fprintf(sb, "> **Warning:** %s\n\n", warning)
+	}
+	return results
+}
+
 // rerankWithCodemap re-orders search results using codemap's structural
 // importance data (fan-in hub scores). The re-ranked results are written
 // back into the slice in-place. This is best-effort: if codemap is
@@ -2167,11 +2766,14 @@ func (s *SDKServer) handleInvestigate(ctx context.Context, req *sdkmcp.CallToolR
 	// Build search options from the investigate input
 	opts := search.DefaultSearchOptions()
 	opts.ProjectRoot = state.projectRoot
+	var rootLabels map[string]string
+	opts.ProjectRoots, rootLabels = app.SearchRoots(state.projectRoot, state.cfg)
 	// Honor the project's configured hybrid weights, matching the app layer
 	// and daemon paths; zero values fall back to the defaults inside the
 	// search/db layers.
 	opts.VectorWeight = state.cfg.Search.VectorWeight
 	opts.TextWeight = state.cfg.Search.TextWeight
+	opts.Fusion = state.cfg.Search.Fusion
 	if input.Limit > 0 {
 		opts.Limit = input.Limit
 	}
@@ -2213,20 +2815,20 @@ func (s *SDKServer) handleInvestigate(ctx context.Context, req *sdkmcp.CallToolR
 		}, nil, nil
 	}
 	results := outcome.Results
+	search.LabelRoots(results, rootLabels)
 
 	// Surface degraded-mode diagnostics so a fallback is never silent.
 	for _, w := range outcome.Warnings {
 		fmt.Fprintf(&sb, "> **Warning:** %s\n\n", w)
 	}
 
-	// Expand context lines if requested
-	if input.ContextLines > 0 {
-		for i := range results {
-			results[i].Content = expandContextLines(state.projectRoot, results[i], input.ContextLines)
-		}
-	}
+	search.ExpandResults(state.projectRoot, results, input.ContextLines, input.ContextLines)
+	search.TruncateResults(results, input.Query, input.MaxSnippetLines)
 
 	state.rerankWithCodemap(ctx, results, state.codemapStructuralWeight())
+	results, dropped := capResults(results, maxResponseBytes(state.cfg))
+	auditResults(ctx, len(results))
+	writeTruncationNote(&sb, dropped, maxResponseBytes(state.cfg))
 	formatSearchResults(&sb, results)
 	state.annotateSearchHits(ctx, results, input.Query)
 
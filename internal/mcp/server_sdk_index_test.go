@@ -331,6 +331,26 @@ func TestHandleDeleteKeepsDatabaseAndProjectRootFromSameSnapshot(t *testing.T) {
 	assertProjectChunkCount(t, sessionB, "main.go", 1)
 }
 
+func TestHandleDeleteDryRunLeavesIndexUnchanged(t *testing.T) {
+	session, root := newDeleteTestSession(t, "a")
+	defer func() { _ = session.close() }()
+	s := &SDKServer{session: session, projectRoot: root, initialized: true}
+
+	result, _, err := s.handleDelete(context.Background(), nil, DeleteInput{FilePath: "./main.go", DryRun: true})
+	if err != nil || result == nil || result.IsError {
+		t.Fatalf("handleDelete(dry_run) result = %#v, error = %v", result, err)
+	}
+	if text := result.Content[0].(*sdkmcp.TextContent).Text; !strings.Contains(text, "Would delete ./main.go (1 chunks") {
+		t.Fatalf("dry-run text = %q", text)
+	}
+	assertProjectChunkCount(t, session, "main.go", 1)
+
+	result, _, err = s.handleDelete(context.Background(), nil, DeleteInput{FilePath: "missing.go", DryRun: true})
+	if err != nil || result == nil || !result.IsError {
+		t.Fatalf("handleDelete(dry_run, missing) result = %#v, error = %v; want error result", result, err)
+	}
+}
+
 func newDeleteTestSession(t *testing.T, name string) (*mcpSession, string) {
 	t.Helper()
 	base := t.TempDir()
@@ -342,11 +362,15 @@ func newDeleteTestSession(t *testing.T, name string) (*mcpSession, string) {
 	cfg := config.DefaultConfig()
 	cfg.DataDir = dataDir
 	cfg.Embedding.Dimensions = 8
-	session := newMCPSession(cfg, root, nil)
+	session := newMCPSession(cfg, root, mcpIndexProvider{dimensions: cfg.Embedding.Dimensions, model: cfg.Embedding.Model})
 	database, err := session.readWriteDB()
 	if err != nil {
 		t.Fatal(err)
 	}
+	if err := app.SaveEmbeddingProfile(database, dataDir, app.CurrentEmbeddingProfile(cfg)); err != nil {
+		_ = database.Close()
+		t.Fatal(err)
+	}
 	chunk := db.NewChunkRecord(
 		filepath.Join(root, "main.go"), "main.go", "hash-"+name, 12, "go",
 		"package main", 1, 1, 0, 12, "generic", "", root,
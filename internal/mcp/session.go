@@ -116,6 +116,13 @@ func newMCPSession(cfg *config.Config, projectRoot string, provider embed.Provid
 		HNSWM:              cfg.Vector.VecLite.M,
 		HNSWEfConstruction: cfg.Vector.VecLite.EfConstruction,
 		HNSWEfSearch:       cfg.Vector.VecLite.EfSearch,
+		Quantization:       db.Quantization(cfg.Vector.VecLite.Quantization),
+		HotWindow:          cfg.Vector.VecLite.HotTierWindow(),
+		HotMaxChunks:       cfg.Vector.VecLite.HotMaxChunks,
+		Qdrant:             app.QdrantOptions(cfg),
+		Pgvector:           app.PgvectorOptions(cfg),
+		ProjectRoot:        projectRoot,
+		Encryption:         app.EncryptionOptions(cfg),
 	}
 
 	freshnessCheckInterval := 5 * time.Second
@@ -156,7 +163,8 @@ func (s *mcpSession) AcquireIndexDB(ctx context.Context) (app.IndexDBLease, erro
 
 // index holds a session operation lease around the entire coordinator run,
 // including provider preflight before the writable DB lease is acquired.
-func (s *mcpSession) index(ctx context.Context, req app.IndexRequest) (*index.IndexResult, error) {
+// progress, when set, receives the run's progress.
+func (s *mcpSession) index(ctx context.Context, req app.IndexRequest, progress index.ProgressCallback) (*index.IndexResult, error) {
 	if err := s.beginOperation(); err != nil {
 		return nil, err
 	}
@@ -164,7 +172,7 @@ func (s *mcpSession) index(ctx context.Context, req app.IndexRequest) (*index.In
 	if s.coordinator == nil {
 		return nil, fmt.Errorf("index coordinator is not configured")
 	}
-	return s.coordinator.Index(ctx, req, nil)
+	return s.coordinator.Index(ctx, req, progress)
 }
 
 func (s *mcpSession) beginOperation() error {
@@ -511,7 +519,7 @@ func (s *mcpSession) reloadIfStale() error {
 	databaseChanged := false
 	if checkDatabase {
 		s.lastFreshnessCheck = now
-		if generation, err := statFileGeneration(s.databasePath); err == nil {
+		if generation, err := statFileGeneration(db.AtRestPath(s.databasePath)); err == nil {
 			databaseGeneration = generation
 			databaseObserved = true
 			databaseChanged = s.databaseGeneration.differs(generation)
@@ -519,7 +527,7 @@ func (s *mcpSession) reloadIfStale() error {
 	} else if daemonChanged {
 		// Capture the database generation before Reload. Recording a post-reload
 		// stat could incorrectly mark a concurrent commit as already loaded.
-		if generation, err := statFileGeneration(s.databasePath); err == nil {
+		if generation, err := statFileGeneration(db.AtRestPath(s.databasePath)); err == nil {
 			databaseGeneration = generation
 			databaseObserved = true
 		}
@@ -545,10 +553,19 @@ func (s *mcpSession) reloadIfStale() error {
 	return nil
 }
 
+// loadedGeneration returns the persisted database generation the cached
+// read-only handle was loaded from. ok is false when no handle is open or
+// the database file was missing when it was.
+func (s *mcpSession) loadedGeneration() (generation fileGeneration, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.databaseGeneration, s.ro != nil && s.databaseGeneration.exists
+}
+
 // observeLoadedGeneration records the filesystem state represented by a newly
 // opened read-only handle. The caller must hold s.mu.
 func (s *mcpSession) observeLoadedGeneration(now time.Time) {
-	if generation, err := statFileGeneration(s.databasePath); err == nil {
+	if generation, err := statFileGeneration(db.AtRestPath(s.databasePath)); err == nil {
 		s.databaseGeneration = generation
 	}
 	if generation, err := statFileGeneration(s.daemonJSONPath); err == nil {
@@ -594,8 +611,7 @@ func closeMCPProvider(provider embed.Provider) error {
 
 // hasDatabase returns true if the veclite database file exists.
 func (s *mcpSession) hasDatabase() bool {
-	vecPath := db.VecLitePath(s.cfg.DataDir)
-	_, err := os.Stat(vecPath)
+	_, err := os.Stat(db.AtRestPath(db.VecLitePath(s.cfg.DataDir)))
 	return err == nil
 }
 
@@ -552,7 +552,7 @@ func TestMCPSessionCloseWaitsForIndexAndClosesProviderOnce(t *testing.T) {
 
 	indexErr := make(chan error, 1)
 	go func() {
-		_, err := sess.index(context.Background(), app.IndexRequest{StructuralChunks: string(app.StructuralChunksOff)})
+		_, err := sess.index(context.Background(), app.IndexRequest{StructuralChunks: string(app.StructuralChunksOff)}, nil)
 		indexErr <- err
 	}()
 	select {
@@ -564,7 +564,7 @@ func TestMCPSessionCloseWaitsForIndexAndClosesProviderOnce(t *testing.T) {
 	closed := make(chan error, 1)
 	go func() { closed <- sess.close() }()
 	waitForMCPSessionClosing(t, sess)
-	if _, err := sess.index(context.Background(), app.IndexRequest{}); !errors.Is(err, errMCPSessionClosing) {
+	if _, err := sess.index(context.Background(), app.IndexRequest{}, nil); !errors.Is(err, errMCPSessionClosing) {
 		t.Fatalf("new index during close error = %v, want session closing", err)
 	}
 	select {
@@ -2,6 +2,7 @@
 package memory
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -74,6 +75,37 @@ func DefaultConfig() *Config {
 	return cfg
 }
 
+// Scope selects which memory store a call reads or writes.
+type Scope string
+
+const (
+	// ScopeGlobal is the per-user store under ~/.vecai/memory, shared by
+	// every project.
+	ScopeGlobal Scope = "global"
+	// ScopeProject is a store in one project's vecgrep data directory, so
+	// memories about a codebase live, and travel, with its index.
+	ScopeProject Scope = "project"
+)
+
+// ParseScope parses a scope name. An empty name is ScopeGlobal.
+func ParseScope(name string) (Scope, error) {
+	switch Scope(strings.ToLower(strings.TrimSpace(name))) {
+	case "", ScopeGlobal:
+		return ScopeGlobal, nil
+	case ScopeProject:
+		return ScopeProject, nil
+	}
+	return "", fmt.Errorf("unknown memory scope %q (want global or project)", name)
+}
+
+// ProjectConfig returns the default configuration with the database in
+// dataDir, a project's vecgrep data directory.
+func ProjectConfig(dataDir string) *Config {
+	cfg := DefaultConfig()
+	cfg.DBPath = filepath.Join(dataDir, DefaultDBFile)
+	return cfg
+}
+
 // EnsureDir creates the memory directory if it doesn't exist.
 func (c *Config) EnsureDir() error {
 	dir := filepath.Dir(c.DBPath)
@@ -1,8 +1,10 @@
 package memory
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -38,9 +40,20 @@ type RememberOptions struct {
 
 // RecallOptions contains options for searching memories.
 type RecallOptions struct {
-	Limit         int      // Max results, default 10
-	Tags          []string // Filter by tags
-	MinImportance float64  // Minimum importance threshold
+	Limit         int       // Max results, default 10
+	Tags          []string  // Filter by tags
+	MinImportance float64   // Minimum importance threshold
+	Since         time.Time // Only memories created at or after this time (zero = unbounded)
+	Until         time.Time // Only memories created at or before this time (zero = unbounded)
+}
+
+// ListOptions contains options for listing memories without a query.
+type ListOptions struct {
+	Limit          int       // Max results (0 = all)
+	Tags           []string  // Only memories carrying all of these tags
+	Since          time.Time // Only memories created at or after this time (zero = unbounded)
+	Until          time.Time // Only memories created at or before this time (zero = unbounded)
+	IncludeExpired bool      // Also list memories past their expiration
 }
 
 // ForgetOptions contains options for deleting memories.
@@ -190,6 +203,15 @@ func (s *MemoryStore) Recall(ctx context.Context, query string, opts RecallOptio
 		filters = append(filters, veclite.GTE("importance", opts.MinImportance))
 	}
 
+	// Filter by creation time. created_at is stored in Unix seconds, so the
+	// window composes with the semantic query inside the same search.
+	if !opts.Since.IsZero() {
+		filters = append(filters, veclite.GTE("created_at", float64(opts.Since.Unix())))
+	}
+	if !opts.Until.IsZero() {
+		filters = append(filters, veclite.LTE("created_at", float64(opts.Until.Unix())))
+	}
+
 	// Build search options
 	searchOpts := []veclite.SearchOption{veclite.TopK(opts.Limit * 2)} // Get more for filtering
 	if len(filters) > 0 {
@@ -265,6 +287,40 @@ func hasAllTags(tags, want []string) bool {
 	return true
 }
 
+// List returns stored memories newest first, without embedding anything.
+// Tags match exactly with AND semantics, as in Recall. Listed memories have
+// no Score.
+func (s *MemoryStore) List(ctx context.Context, opts ListOptions) ([]Memory, error) {
+	now := time.Now().Unix()
+	var memories []Memory
+	for _, r := range s.coll.All() {
+		expiresAt := getInt64Payload(r.Payload, "expires_at")
+		if !opts.IncludeExpired && expiresAt > 0 && expiresAt < now {
+			continue
+		}
+		memory := recordToMemory(r, 0)
+		if !hasAllTags(memory.Tags, opts.Tags) {
+			continue
+		}
+		if (!opts.Since.IsZero() && memory.CreatedAt.Before(opts.Since)) ||
+			(!opts.Until.IsZero() && memory.CreatedAt.After(opts.Until)) {
+			continue
+		}
+		memories = append(memories, memory)
+	}
+
+	slices.SortFunc(memories, func(a, b Memory) int {
+		if c := b.CreatedAt.Compare(a.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+	if opts.Limit > 0 && len(memories) > opts.Limit {
+		memories = memories[:opts.Limit]
+	}
+	return memories, nil
+}
+
 // Forget deletes memories by criteria.
 func (s *MemoryStore) Forget(ctx context.Context, opts ForgetOptions) (int, error) {
 	var deleted int
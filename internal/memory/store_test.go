@@ -707,3 +707,164 @@ func TestEmptyTagsList(t *testing.T) {
 		t.Errorf("Expected 0 tags, got %d", len(memories[0].Tags))
 	}
 }
+
+func TestRecallTimeWindow(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, m := range []struct {
+		content string
+		age     time.Duration
+	}{
+		{"decided to shard the index by repo", 30 * 24 * time.Hour},
+		{"decided to keep one index per repo", 3 * 24 * time.Hour},
+		{"decided to drop the shard flag", time.Hour},
+	} {
+		embedding, _ := store.provider.Embed(ctx, m.content)
+		payload := map[string]any{
+			"content":    m.content,
+			"importance": 0.5,
+			"tags":       "",
+			"created_at": now.Add(-m.age).Unix(),
+			"expires_at": int64(0),
+		}
+		if _, err := store.coll.Insert(embedding, payload); err != nil {
+			t.Fatalf("insert %q: %v", m.content, err)
+		}
+	}
+
+	recall := func(since, until string) []string {
+		t.Helper()
+		from, to, err := ParseTimeWindow(since, until, now)
+		if err != nil {
+			t.Fatalf("ParseTimeWindow(%q, %q): %v", since, until, err)
+		}
+		memories, err := store.Recall(ctx, "decided", RecallOptions{Limit: 10, Since: from, Until: to})
+		if err != nil {
+			t.Fatalf("Recall: %v", err)
+		}
+		var contents []string
+		for _, m := range memories {
+			contents = append(contents, m.Content)
+		}
+		return contents
+	}
+
+	if got := recall("7d", ""); len(got) != 2 {
+		t.Errorf("since 7d = %v, want the two recent memories", got)
+	}
+	if got := recall("7d", "2h"); len(got) != 1 || got[0] != "decided to keep one index per repo" {
+		t.Errorf("since 7d until 2h = %v, want only the 3-day-old memory", got)
+	}
+	if got := recall("", "7d"); len(got) != 1 || got[0] != "decided to shard the index by repo" {
+		t.Errorf("until 7d = %v, want only the 30-day-old memory", got)
+	}
+	if got := recall("", ""); len(got) != 3 {
+		t.Errorf("no window = %v, want all three memories", got)
+	}
+}
+
+func TestListNewestFirstWithFilters(t *testing.T) {
+	store, cleanup := setupTestStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, m := range []struct {
+		content   string
+		tags      string
+		age       time.Duration
+		expiresAt int64
+	}{
+		{"oldest", "codemap,abc", 3 * time.Hour, 0},
+		{"newest", "codemap", time.Hour, 0},
+		{"middle", "codemap,abc", 2 * time.Hour, 0},
+		{"expired", "codemap,abc", 4 * time.Hour, now.Add(-time.Minute).Unix()},
+	} {
+		embedding, _ := store.provider.Embed(ctx, m.content)
+		payload := map[string]any{
+			"content":    m.content,
+			"importance": 0.5,
+			"tags":       m.tags,
+			"created_at": now.Add(-m.age).Unix(),
+			"expires_at": m.expiresAt,
+		}
+		if _, err := store.coll.Insert(embedding, payload); err != nil {
+			t.Fatalf("insert %q: %v", m.content, err)
+		}
+	}
+
+	list := func(opts ListOptions) string {
+		t.Helper()
+		memories, err := store.List(ctx, opts)
+		if err != nil {
+			t.Fatalf("List(%+v): %v", opts, err)
+		}
+		var contents []string
+		for _, m := range memories {
+			contents = append(contents, m.Content)
+		}
+		return strings.Join(contents, ",")
+	}
+
+	if got := list(ListOptions{}); got != "newest,middle,oldest" {
+		t.Errorf("List() = %s, want newest,middle,oldest", got)
+	}
+	if got := list(ListOptions{IncludeExpired: true}); got != "newest,middle,oldest,expired" {
+		t.Errorf("List(IncludeExpired) = %s", got)
+	}
+	if got := list(ListOptions{Tags: []string{"codemap", "abc"}}); got != "middle,oldest" {
+		t.Errorf("List(tags codemap,abc) = %s, want middle,oldest", got)
+	}
+	if got := list(ListOptions{Until: now.Add(-90 * time.Minute), Limit: 1}); got != "middle" {
+		t.Errorf("List(until 90m, limit 1) = %s, want middle", got)
+	}
+}
+
+func TestParseTimeWindow(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 30, 0, 0, time.UTC)
+
+	since, until, err := ParseTimeWindow("2026-10-12", "2026-10-16", now)
+	if err != nil {
+		t.Fatalf("ParseTimeWindow dates: %v", err)
+	}
+	if !since.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("since = %v, want start of 2026-10-12", since)
+	}
+	if !until.Equal(time.Date(2026, 10, 16, 23, 59, 59, 0, time.UTC)) {
+		t.Errorf("until = %v, want end of 2026-10-16", until)
+	}
+
+	since, until, err = ParseTimeWindow("2w", "2026-10-16T12:00:00Z", now)
+	if err != nil {
+		t.Fatalf("ParseTimeWindow relative: %v", err)
+	}
+	if !since.Equal(now.AddDate(0, 0, -14)) || until.Hour() != 12 {
+		t.Errorf("window = %v..%v, want 14 days ago until noon", since, until)
+	}
+
+	for _, tc := range [][2]string{{"yesterday", ""}, {"", "7x"}, {"1d", "3d"}} {
+		if _, _, err := ParseTimeWindow(tc[0], tc[1], now); err == nil {
+			t.Errorf("ParseTimeWindow(%q, %q) succeeded, want error", tc[0], tc[1])
+		}
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	for name, want := range map[string]Scope{"": ScopeGlobal, "global": ScopeGlobal, " Project ": ScopeProject} {
+		got, err := ParseScope(name)
+		if err != nil || got != want {
+			t.Errorf("ParseScope(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseScope("team"); err == nil {
+		t.Error("ParseScope(team) succeeded, want error")
+	}
+
+	dataDir := filepath.Join(t.TempDir(), ".vecgrep")
+	if got := ProjectConfig(dataDir).DBPath; got != filepath.Join(dataDir, DefaultDBFile) {
+		t.Errorf("ProjectConfig DBPath = %q, want it inside %s", got, dataDir)
+	}
+}
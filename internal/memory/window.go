@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseTimeWindow parses the since/until bounds accepted by memory recall.
+// Each bound is empty (unbounded), an RFC 3339 timestamp, a YYYY-MM-DD date
+// in local time, or a relative age such as "90m", "36h", "7d", or "2w"
+// meaning that long before now. A date as until covers that whole day, so
+// since=until=2026-10-16 selects one day.
+func ParseTimeWindow(since, until string, now time.Time) (time.Time, time.Time, error) {
+	from, _, err := parseTimeBound(since, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid since %q: %w", since, err)
+	}
+	to, dateOnly, err := parseTimeBound(until, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid until %q: %w", until, err)
+	}
+	if dateOnly {
+		to = to.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("since %q is after until %q", since, until)
+	}
+	return from, to, nil
+}
+
+func parseTimeBound(value string, now time.Time) (t time.Time, dateOnly bool, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.ParseInLocation(time.DateOnly, value, now.Location()); err == nil {
+		return t, true, nil
+	}
+	unit := map[byte]time.Duration{'m': time.Minute, 'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[value[len(value)-1]]
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if unit == 0 || err != nil || n < 0 {
+		return time.Time{}, false, fmt.Errorf("expected an RFC 3339 time, a YYYY-MM-DD date, or an age like 7d")
+	}
+	return now.Add(-time.Duration(n) * unit), false, nil
+}
@@ -5,9 +5,10 @@ import "github.com/abdul-hamid-achik/vecgrep/internal/search"
 type OutputFormat = search.OutputFormat
 
 const (
-	FormatDefault = search.FormatDefault
-	FormatJSON    = search.FormatJSON
-	FormatCompact = search.FormatCompact
+	FormatDefault   = search.FormatDefault
+	FormatJSON      = search.FormatJSON
+	FormatCompact   = search.FormatCompact
+	FormatCitations = search.FormatCitations
 )
 
 func Results(results []search.Result, format OutputFormat) string {
@@ -20,6 +21,8 @@ func ParseOutputFormat(format string) OutputFormat {
 		return FormatJSON
 	case "compact":
 		return FormatCompact
+	case "citations":
+		return FormatCitations
 	default:
 		return FormatDefault
 	}
@@ -0,0 +1,275 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/embed"
+)
+
+const (
+	// DefaultContextBudget is the token budget of an assembled context when
+	// the caller gives none.
+	DefaultContextBudget = 4000
+	// DefaultContextCandidates is how many search results AssembleContext
+	// considers when SearchOptions.Limit is unset.
+	DefaultContextCandidates = 20
+	// minTrimmedSectionLines is the fewest lines a section cut to fit the
+	// budget keeps; a shorter fragment rarely helps a reader.
+	minTrimmedSectionLines = 3
+)
+
+// ContextBlock is a token-budgeted block of code assembled from search
+// results for a language model prompt. Overlapping and adjacent hits in a
+// file are merged into one section, repeated code is kept once, and every
+// section is headed by a numbered citation the model can refer back to.
+type ContextBlock struct {
+	Query string `json:"query"`
+	// Text is the assembled block: each section's citation header and its
+	// code in a fence.
+	Text     string           `json:"text"`
+	Sections []ContextSection `json:"sections"`
+	// Tokens is the estimated size of Text; it never exceeds Budget.
+	Tokens int `json:"tokens"`
+	Budget int `json:"budget"`
+	// Duplicates counts sections dropped because an earlier one holds the
+	// same code, and Omitted those that did not fit the budget.
+	Duplicates int      `json:"duplicates,omitempty"`
+	Omitted    int      `json:"omitted,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+// ContextSection is one cited span of code in a ContextBlock.
+type ContextSection struct {
+	Citation
+	// Index is the section's [n] marker in the block's Text.
+	Index    int    `json:"index"`
+	Language string `json:"language,omitempty"`
+	Content  string `json:"content"`
+	Tokens   int    `json:"tokens"`
+	// Hits counts the search results merged into the section.
+	Hits int `json:"hits"`
+	// Trimmed marks a section cut short to fit the budget; EndLine is the
+	// last line kept.
+	Trimmed bool `json:"trimmed,omitempty"`
+}
+
+// AssembleContext searches for query and packs the results into a block of
+// at most budget estimated tokens (DefaultContextBudget when budget is not
+// positive). Up to opts.Limit results are considered, DefaultContextCandidates
+// when unset.
+func (s *Searcher) AssembleContext(ctx context.Context, query string, opts SearchOptions, budget int) (*ContextBlock, error) {
+	if opts.Limit <= 0 {
+		opts.Limit = DefaultContextCandidates
+	}
+	outcome, err := s.SearchWithOutcome(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	block := PackContext(query, outcome.Results, budget)
+	block.Warnings = outcome.Warnings
+	return block, nil
+}
+
+// contextSpan is a run of consecutive lines of one file gathered from one
+// or more results.
+type contextSpan struct {
+	citation Citation
+	language string
+	lines    []string
+	// exact is false when the content does not hold exactly the result's
+	// lines, as after context expansion, so it cannot be merged line by line.
+	exact bool
+	hits  int
+}
+
+// PackContext assembles ranked results into a ContextBlock of at most budget
+// estimated tokens. Whole sections are taken in rank order while they fit,
+// skipping any that do not so that smaller ones further down still can;
+// the leftover budget then goes to the best skipped sections, cut short, as
+// long as at least minTrimmedSectionLines lines survive. Sections keep the
+// rank of their best hit. Annotations are left out.
+func PackContext(query string, results []Result, budget int) *ContextBlock {
+	if budget <= 0 {
+		budget = DefaultContextBudget
+	}
+	block := &ContextBlock{Query: query, Budget: budget, Sections: []ContextSection{}}
+
+	var spans []contextSpan
+	seen := make(map[string]bool)
+	for _, span := range mergeContextSpans(results) {
+		content := strings.Join(span.lines, "\n")
+		if seen[content] {
+			block.Duplicates++
+			continue
+		}
+		seen[content] = true
+		spans = append(spans, span)
+	}
+
+	// Sizes are measured with the widest [n] marker any section can get,
+	// so the final numbering never pushes the block over budget.
+	widest := len(spans)
+	kept := make([]int, len(spans))
+	used := 0
+	for i, span := range spans {
+		if tokens := contextSpanTokens(widest, span, len(span.lines)); used+tokens <= budget {
+			kept[i] = len(span.lines)
+			used += tokens
+		}
+	}
+	for i, span := range spans {
+		if kept[i] > 0 {
+			continue
+		}
+		if n := trimContextSpan(widest, span, budget-used); n >= minTrimmedSectionLines {
+			kept[i] = n
+			used += contextSpanTokens(widest, span, n)
+		}
+	}
+
+	var sb strings.Builder
+	for i, span := range spans {
+		if kept[i] == 0 {
+			block.Omitted++
+			continue
+		}
+		section := ContextSection{
+			Citation: span.citation,
+			Index:    len(block.Sections) + 1,
+			Language: span.language,
+			Content:  strings.Join(span.lines[:kept[i]], "\n"),
+			Hits:     span.hits,
+			Trimmed:  kept[i] < len(span.lines),
+		}
+		if section.Trimmed {
+			section.EndLine = section.StartLine + kept[i] - 1
+			section.Citation.Citation = section.Citation.String()
+		}
+		text := contextSectionText(section.Index, section.Citation, section.Language, section.Content)
+		section.Tokens = int(embed.EstimateTokens(text))
+		block.Sections = append(block.Sections, section)
+		block.Tokens += section.Tokens
+		sb.WriteString(text)
+	}
+	block.Text = sb.String()
+	return block
+}
+
+// mergeContextSpans turns results into spans, merging each result into an
+// earlier span of the same file that it overlaps or directly follows or
+// precedes. Overlapping lines are kept once.
+func mergeContextSpans(results []Result) []contextSpan {
+	var spans []contextSpan
+	for _, r := range results {
+		if r.Annotation {
+			continue
+		}
+		lines := strings.Split(strings.TrimRight(r.Content, "\n"), "\n")
+		span := contextSpan{
+			citation: Citation{
+				Path:      r.RelativePath,
+				StartLine: r.StartLine,
+				EndLine:   r.EndLine,
+				Symbol:    r.SymbolName,
+				Score:     r.Score,
+				Project:   r.Project,
+				Root:      r.Root,
+				Ref:       r.Ref,
+			},
+			language: r.Language,
+			lines:    lines,
+			exact:    r.EndLine-r.StartLine+1 == len(lines),
+			hits:     1,
+		}
+		if span.language == "unknown" {
+			span.language = ""
+		}
+		if !span.exact {
+			span.citation.EndLine = span.citation.StartLine + len(lines) - 1
+		}
+
+		i := 0
+		for ; i < len(spans); i++ {
+			if spans[i].exact && span.exact && spans[i].citation.touches(span.citation) {
+				spans[i].absorb(span)
+				break
+			}
+		}
+		if i == len(spans) {
+			spans = append(spans, span)
+			continue
+		}
+		// A widened span can reach spans it did not touch before.
+		for j := i + 1; j < len(spans); {
+			if spans[j].exact && spans[i].citation.touches(spans[j].citation) {
+				spans[i].absorb(spans[j])
+				spans = append(spans[:j], spans[j+1:]...)
+				continue
+			}
+			j++
+		}
+	}
+	for i := range spans {
+		spans[i].citation.Citation = spans[i].citation.String()
+	}
+	return spans
+}
+
+// absorb merges o, which touches sp, into sp. Where both hold a line, sp's
+// copy is kept.
+func (sp *contextSpan) absorb(o contextSpan) {
+	start := min(sp.citation.StartLine, o.citation.StartLine)
+	end := max(sp.citation.EndLine, o.citation.EndLine)
+	lines := make([]string, end-start+1)
+	for i, line := range o.lines {
+		lines[o.citation.StartLine-start+i] = line
+	}
+	for i, line := range sp.lines {
+		lines[sp.citation.StartLine-start+i] = line
+	}
+	sp.lines = lines
+	sp.citation.absorb(o.citation)
+	if sp.language == "" {
+		sp.language = o.language
+	}
+	sp.hits += o.hits
+}
+
+// contextSpanTokens estimates the tokens of span's first n lines rendered as
+// section index.
+func contextSpanTokens(index int, span contextSpan, n int) int {
+	citation := span.citation
+	if n < len(span.lines) {
+		citation.EndLine = citation.StartLine + n - 1
+		citation.Citation = citation.String()
+	}
+	return int(embed.EstimateTokens(contextSectionText(index, citation, span.language, strings.Join(span.lines[:n], "\n"))))
+}
+
+// trimContextSpan returns how many of span's leading lines fit in budget
+// tokens once rendered as section index.
+func trimContextSpan(index int, span contextSpan, budget int) int {
+	kept := 0
+	for n := 1; n <= len(span.lines) && contextSpanTokens(index, span, n) <= budget; n++ {
+		kept = n
+	}
+	return kept
+}
+
+// contextSectionText renders one section: its [n] citation header, naming
+// the symbol when known, and its code in a fence.
+func contextSectionText(index int, citation Citation, language, content string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%d] %s", index, citation.Citation)
+	if citation.Project != "" {
+		fmt.Fprintf(&sb, " (%s)", citation.Project)
+	}
+	if citation.Symbol != "" {
+		fmt.Fprintf(&sb, " %s", citation.Symbol)
+	}
+	fence := markdownFence(content)
+	fmt.Fprintf(&sb, "\n%s%s\n%s\n%s\n\n", fence, language, content, fence)
+	return sb.String()
+}
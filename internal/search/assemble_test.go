@@ -0,0 +1,70 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+// numberedLines returns the text "line N" for lines start through end.
+func numberedLines(start, end int) string {
+	lines := make([]string, 0, end-start+1)
+	for n := start; n <= end; n++ {
+		lines = append(lines, "line "+strings.Repeat("x", n%3)+string(rune('a'+n%26)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestPackContextMergesAndDeduplicates(t *testing.T) {
+	results := []Result{
+		{RelativePath: "a.go", StartLine: 10, EndLine: 20, Content: numberedLines(10, 20), Score: 0.9, SymbolName: "Run", Language: "go"},
+		{RelativePath: "b.go", StartLine: 1, EndLine: 3, Content: numberedLines(1, 3), Score: 0.8, Language: "go"},
+		{RelativePath: "a.go", StartLine: 15, EndLine: 25, Content: numberedLines(15, 25), Score: 0.7, Language: "go"},
+		{RelativePath: "a.go", StartLine: 26, EndLine: 30, Content: numberedLines(26, 30), Score: 0.6, Language: "go"},
+		{RelativePath: "vendor/b.go", StartLine: 1, EndLine: 3, Content: numberedLines(1, 3), Score: 0.5, Language: "go"},
+		{RelativePath: "a.go", StartLine: 40, EndLine: 40, Content: "a note", Annotation: true},
+	}
+
+	block := PackContext("run", results, 10000)
+	if len(block.Sections) != 2 || block.Duplicates != 1 || block.Omitted != 0 {
+		t.Fatalf("sections = %+v, duplicates %d, omitted %d; want a.go and b.go with one duplicate", block.Sections, block.Duplicates, block.Omitted)
+	}
+	merged := block.Sections[0]
+	if merged.Citation.Citation != "a.go:L10-L30" || merged.Hits != 3 || merged.Score != 0.9 || merged.Symbol != "Run" {
+		t.Errorf("merged section = %+v, want a.go:L10-L30 from 3 hits", merged.Citation)
+	}
+	if merged.Content != numberedLines(10, 30) {
+		t.Errorf("merged content = %q, want lines 10-30 once each", merged.Content)
+	}
+	if !strings.HasPrefix(block.Text, "[1] a.go:L10-L30 Run\n```go\n") || !strings.Contains(block.Text, "[2] b.go:L1-L3\n") {
+		t.Errorf("text = %q, want numbered citation headers", block.Text)
+	}
+	if block.Tokens > block.Budget || block.Tokens != merged.Tokens+block.Sections[1].Tokens {
+		t.Errorf("tokens = %d of %d, want the sum of the sections within budget", block.Tokens, block.Budget)
+	}
+}
+
+func TestPackContextFitsBudget(t *testing.T) {
+	results := []Result{
+		{RelativePath: "big.go", StartLine: 1, EndLine: 200, Content: numberedLines(1, 200), Score: 0.9},
+		{RelativePath: "small.go", StartLine: 5, EndLine: 6, Content: numberedLines(5, 6), Score: 0.8},
+	}
+
+	block := PackContext("q", results, 120)
+	if block.Tokens > 120 {
+		t.Fatalf("tokens = %d, want at most the budget of 120", block.Tokens)
+	}
+	if len(block.Sections) != 2 {
+		t.Fatalf("sections = %+v, want a trimmed big.go and small.go", block.Sections)
+	}
+	trimmed := block.Sections[0]
+	if !trimmed.Trimmed || trimmed.EndLine >= 200 || trimmed.EndLine < trimmed.StartLine+minTrimmedSectionLines-1 {
+		t.Errorf("big.go section = %+v, want it trimmed", trimmed.Citation)
+	}
+	if !strings.Contains(block.Text, trimmed.Citation.Citation) {
+		t.Errorf("text does not cite the trimmed range %s", trimmed.Citation.Citation)
+	}
+
+	if block := PackContext("q", results, 10); len(block.Sections) != 0 || block.Omitted != 2 {
+		t.Errorf("tiny budget packed %+v, omitted %d; want nothing", block.Sections, block.Omitted)
+	}
+}
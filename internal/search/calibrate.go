@@ -0,0 +1,87 @@
+package search
+
+import (
+	"sort"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+// calibrationKnots is how many quantiles a calibration curve stores: one per
+// percentile, 0 through 100.
+const calibrationKnots = 101
+
+// MinCalibrationSamples is the fewest sampled scores a mode needs before a
+// curve is built for it; sparser modes stay uncalibrated.
+const MinCalibrationSamples = 20
+
+// ScoreCalibration maps raw scores onto a scale shared by every search mode.
+// Each mode has a curve of quantiles of the scores it returned for sample
+// queries, and a calibrated score is the fraction of those sampled results
+// the raw score beats. 0.9 therefore means "better than 90% of what this
+// mode usually returns" in semantic, keyword, and hybrid search alike, so a
+// --min-score threshold keeps a comparable share of results in each.
+type ScoreCalibration struct {
+	// Curves maps a CalibrationKey to its ascending quantiles.
+	Curves map[string][]float32 `json:"curves"`
+}
+
+// CalibrationKey names the curve for a mode. Hybrid searches fused with
+// Reciprocal Rank Fusion score on a different scale from weighted fusion, so
+// they get their own curve.
+func CalibrationKey(mode SearchMode, fusion string) string {
+	if mode == SearchModeHybrid && fusion == db.FusionRRF {
+		return string(mode) + "-" + fusion
+	}
+	return string(mode)
+}
+
+// NewScoreCalibration builds curves from raw scores sampled per
+// CalibrationKey. Keys with fewer than MinCalibrationSamples scores are left
+// out.
+func NewScoreCalibration(samples map[string][]float32) *ScoreCalibration {
+	c := &ScoreCalibration{Curves: make(map[string][]float32, len(samples))}
+	for key, scores := range samples {
+		if len(scores) < MinCalibrationSamples {
+			continue
+		}
+		sorted := append([]float32(nil), scores...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		curve := make([]float32, calibrationKnots)
+		for q := range curve {
+			pos := float64(q) / float64(calibrationKnots-1) * float64(len(sorted)-1)
+			lo := int(pos)
+			hi := min(lo+1, len(sorted)-1)
+			frac := float32(pos - float64(lo))
+			curve[q] = sorted[lo] + (sorted[hi]-sorted[lo])*frac
+		}
+		c.Curves[key] = curve
+	}
+	return c
+}
+
+// Has reports whether a curve exists for key.
+func (c *ScoreCalibration) Has(key string) bool {
+	return c != nil && len(c.Curves[key]) >= 2
+}
+
+// Calibrate returns score's position on key's curve, from 0 at or below the
+// lowest sampled score to 1 at or above the highest, interpolating between
+// percentiles. Without a curve for key the score is returned unchanged.
+func (c *ScoreCalibration) Calibrate(key string, score float32) float32 {
+	if !c.Has(key) {
+		return score
+	}
+	curve := c.Curves[key]
+	last := len(curve) - 1
+	if score < curve[0] {
+		return 0
+	}
+	if score >= curve[last] {
+		return 1
+	}
+	// curve[i] <= score < curve[j], so the span is never zero.
+	j := sort.Search(len(curve), func(k int) bool { return curve[k] > score })
+	i := j - 1
+	frac := (score - curve[i]) / (curve[j] - curve[i])
+	return (float32(i) + frac) / float32(last)
+}
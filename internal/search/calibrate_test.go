@@ -0,0 +1,103 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+func TestScoreCalibrationMapsModesOntoOneScale(t *testing.T) {
+	semantic := make([]float32, 0, 100)
+	keyword := make([]float32, 0, 100)
+	for i := range 100 {
+		semantic = append(semantic, 0.3+0.4*float32(i)/99) // 0.30-0.70
+		keyword = append(keyword, float32(i+1)/100)        // 0.01-1.00
+	}
+	c := NewScoreCalibration(map[string][]float32{
+		string(SearchModeSemantic): semantic,
+		string(SearchModeKeyword):  keyword,
+		string(SearchModeHybrid):   semantic[:MinCalibrationSamples-1],
+	})
+
+	if c.Has(string(SearchModeHybrid)) {
+		t.Fatal("a mode with too few samples got a curve")
+	}
+	if got := c.Calibrate(string(SearchModeHybrid), 0.42); got != 0.42 {
+		t.Fatalf("uncalibrated mode score = %v, want it unchanged", got)
+	}
+
+	// The median of each mode lands at 0.5 despite different raw scales.
+	for key, median := range map[string]float32{string(SearchModeSemantic): 0.5, string(SearchModeKeyword): 0.505} {
+		if got := c.Calibrate(key, median); got < 0.49 || got > 0.51 {
+			t.Fatalf("%s median calibrates to %v, want about 0.5", key, got)
+		}
+	}
+	if got := c.Calibrate(string(SearchModeSemantic), 0.1); got != 0 {
+		t.Fatalf("below-range score = %v, want 0", got)
+	}
+	if got := c.Calibrate(string(SearchModeSemantic), 0.9); got != 1 {
+		t.Fatalf("above-range score = %v, want 1", got)
+	}
+	prev := float32(-1)
+	for raw := float32(0.3); raw <= 0.7; raw += 0.01 {
+		got := c.Calibrate(string(SearchModeSemantic), raw)
+		if got < prev {
+			t.Fatalf("calibration not monotonic at %v: %v < %v", raw, got, prev)
+		}
+		prev = got
+	}
+}
+
+func TestScoreCalibrationHandlesTiedSamples(t *testing.T) {
+	scores := make([]float32, 40)
+	for i := range scores {
+		if i >= 30 {
+			scores[i] = 1
+		}
+	}
+	c := NewScoreCalibration(map[string][]float32{string(SearchModeKeyword): scores})
+	if got := c.Calibrate(string(SearchModeKeyword), 0); got < 0.7 || got > 0.8 {
+		t.Fatalf("tied low score = %v, want it to beat the tied samples (about 0.75)", got)
+	}
+	if got := c.Calibrate(string(SearchModeKeyword), 0.5); got < 0.7 || got >= 1 {
+		t.Fatalf("score between ties = %v", got)
+	}
+}
+
+func TestCalibrationKeySeparatesRRF(t *testing.T) {
+	if got := CalibrationKey(SearchModeHybrid, ""); got != "hybrid" {
+		t.Fatalf("weighted hybrid key = %q", got)
+	}
+	if got := CalibrationKey(SearchModeHybrid, db.FusionRRF); got != "hybrid-rrf" {
+		t.Fatalf("rrf hybrid key = %q", got)
+	}
+	if got := CalibrationKey(SearchModeKeyword, db.FusionRRF); got != "keyword" {
+		t.Fatalf("keyword key = %q", got)
+	}
+}
+
+func TestConvertOutcomeResultsCalibratesBeforeMinScore(t *testing.T) {
+	samples := make([]float32, 100)
+	for i := range samples {
+		samples[i] = float32(i) / 99
+	}
+	opts := SearchOptions{
+		Limit:       10,
+		MinScore:    0.5,
+		Calibration: NewScoreCalibration(map[string][]float32{string(SearchModeSemantic): samples}),
+	}
+	// Calibrated against a uniform 0-1 sample the scale barely moves, but
+	// raw_score must be kept and the threshold applied to the calibrated
+	// value.
+	searchResults := []db.SearchResult{
+		{ChunkID: 1, Distance: 0.9, Chunk: &db.ChunkRecord{RelativePath: "a.go"}},
+		{ChunkID: 2, Distance: 0.2, Chunk: &db.ChunkRecord{RelativePath: "b.go"}},
+	}
+	results := convertOutcomeResults(searchResults, SearchModeSemantic, opts)
+	if len(results) != 1 || results[0].ChunkID != 1 {
+		t.Fatalf("results = %+v, want only chunk 1", results)
+	}
+	if results[0].RawScore != 0.9 || results[0].Score < 0.89 || results[0].Score > 0.91 {
+		t.Fatalf("score = %v raw = %v", results[0].Score, results[0].RawScore)
+	}
+}
@@ -0,0 +1,120 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Citation is a code location an agent can cite in generated prose without
+// carrying the snippet. Overlapping and adjacent hits in the same file are
+// merged into one citation, ranked by the best hit among them.
+type Citation struct {
+	// Citation is the rendered location, path:Lstart-Lend (path:Lline for a
+	// single line), prefixed with the extra root like compact output.
+	Citation  string  `json:"citation"`
+	Path      string  `json:"path"`
+	StartLine int     `json:"start_line"`
+	EndLine   int     `json:"end_line"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Score     float32 `json:"score"`
+	Project   string  `json:"project,omitempty"`
+	Root      string  `json:"root,omitempty"`
+	Ref       string  `json:"ref,omitempty"`
+}
+
+// NewCitations converts ranked results to citations, keeping the order of
+// each citation's first hit. Annotations are user notes, not code, and are
+// left out.
+func NewCitations(results []Result) []Citation {
+	citations := make([]Citation, 0, len(results))
+	for _, r := range results {
+		if r.Annotation {
+			continue
+		}
+		c := Citation{
+			Path:      r.RelativePath,
+			StartLine: r.StartLine,
+			EndLine:   r.EndLine,
+			Symbol:    r.SymbolName,
+			Score:     r.Score,
+			Project:   r.Project,
+			Root:      r.Root,
+			Ref:       r.Ref,
+		}
+		i := 0
+		for ; i < len(citations); i++ {
+			if citations[i].touches(c) {
+				citations[i].absorb(c)
+				break
+			}
+		}
+		if i == len(citations) {
+			citations = append(citations, c)
+			continue
+		}
+		// A widened range can reach citations it did not touch before.
+		for j := i + 1; j < len(citations); {
+			if citations[i].touches(citations[j]) {
+				citations[i].absorb(citations[j])
+				citations = append(citations[:j], citations[j+1:]...)
+				continue
+			}
+			j++
+		}
+	}
+	for i := range citations {
+		citations[i].Citation = citations[i].String()
+	}
+	return citations
+}
+
+// String renders the citation as path:Lstart-Lend.
+func (c Citation) String() string {
+	var sb strings.Builder
+	if c.Root != "" {
+		fmt.Fprintf(&sb, "%s:", c.Root)
+	}
+	if c.StartLine == c.EndLine {
+		fmt.Fprintf(&sb, "%s:L%d", c.Path, c.StartLine)
+	} else {
+		fmt.Fprintf(&sb, "%s:L%d-L%d", c.Path, c.StartLine, c.EndLine)
+	}
+	return sb.String()
+}
+
+// touches reports whether o is in the same file as c and overlaps or
+// directly follows or precedes its lines.
+func (c Citation) touches(o Citation) bool {
+	if c.Path != o.Path || c.Project != o.Project || c.Root != o.Root || c.Ref != o.Ref {
+		return false
+	}
+	return o.StartLine <= c.EndLine+1 && c.StartLine <= o.EndLine+1
+}
+
+func (c *Citation) absorb(o Citation) {
+	c.StartLine = min(c.StartLine, o.StartLine)
+	c.EndLine = max(c.EndLine, o.EndLine)
+	c.Score = max(c.Score, o.Score)
+	if c.Symbol == "" {
+		c.Symbol = o.Symbol
+	}
+}
+
+// formatCitations produces the citations output format.
+func formatCitations(results []Result) string {
+	return CitationList(NewCitations(results))
+}
+
+// CitationList renders one citation per line, preceded by the project name
+// and a tab in cross-project searches.
+func CitationList(citations []Citation) string {
+	var sb strings.Builder
+	for _, c := range citations {
+		if c.Project != "" {
+			fmt.Fprintf(&sb, "%s\t", c.Project)
+		}
+		sb.WriteString(c.Citation)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
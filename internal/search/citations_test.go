@@ -0,0 +1,41 @@
+package search
+
+import "testing"
+
+func TestNewCitationsMergesNearbyHits(t *testing.T) {
+	results := []Result{
+		{RelativePath: "a.go", StartLine: 40, EndLine: 50, Score: 0.9, SymbolName: "Run"},
+		{RelativePath: "b.go", StartLine: 7, EndLine: 7, Score: 0.8},
+		{RelativePath: "a.go", StartLine: 1, EndLine: 10, Score: 0.7},
+		{RelativePath: "a.go", StartLine: 11, EndLine: 39, Score: 0.95},
+		{RelativePath: "a.go", StartLine: 60, EndLine: 70, Score: 0.6, Root: "lib"},
+		{RelativePath: "a.go", StartLine: 80, EndLine: 80, Score: 0.5, Annotation: true},
+	}
+
+	citations := NewCitations(results)
+	want := []string{"a.go:L1-L50", "b.go:L7", "lib:a.go:L60-L70"}
+	if len(citations) != len(want) {
+		t.Fatalf("citations = %+v, want %v", citations, want)
+	}
+	for i, c := range citations {
+		if c.Citation != want[i] {
+			t.Errorf("citation %d = %q, want %q", i, c.Citation, want[i])
+		}
+	}
+	if citations[0].Score != 0.95 || citations[0].Symbol != "Run" {
+		t.Errorf("merged citation = %+v, want the best score and the first symbol", citations[0])
+	}
+}
+
+func TestFormatResultsCitations(t *testing.T) {
+	results := []Result{
+		{RelativePath: "a.go", StartLine: 3, EndLine: 9, Content: "func A() {}", Project: "api"},
+		{RelativePath: "b.go", StartLine: 1, EndLine: 2, Content: "func B() {}"},
+	}
+	if got, want := FormatResults(results, FormatCitations), "api\ta.go:L3-L9\nb.go:L1-L2\n"; got != want {
+		t.Fatalf("FormatResults(citations) = %q, want %q", got, want)
+	}
+	if got := FormatResults(nil, FormatCitations); got != "" {
+		t.Fatalf("FormatResults(citations) with no results = %q, want empty", got)
+	}
+}
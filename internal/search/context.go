@@ -0,0 +1,47 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandContext returns result's content widened to include up to before
+// lines above and after lines below the chunk, read from the file on disk.
+// Extra-root and cross-project results are read from their absolute
+// FilePath; others from projectRoot. The original content is returned when
+// no expansion is requested, the file cannot be read, or the result is an
+// annotation or comes from a git ref (whose lines are not on disk).
+func ExpandContext(projectRoot string, result Result, before, after int) string {
+	if before <= 0 && after <= 0 || result.Annotation || result.Ref != "" {
+		return result.Content
+	}
+
+	filePath := filepath.Join(projectRoot, result.RelativePath)
+	if (projectRoot == "" || result.Root != "" || result.Project != "") && filepath.IsAbs(result.FilePath) {
+		filePath = result.FilePath
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return result.Content
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+
+	// Line numbers are 1-indexed; the range is clamped to the file.
+	start := max(result.StartLine-1-max(before, 0), 0)
+	end := min(result.EndLine-1+max(after, 0), len(lines)-1)
+	if start > end {
+		return result.Content
+	}
+	return strings.Join(lines[start:end+1], "\n")
+}
+
+// ExpandResults applies ExpandContext to every result in place.
+func ExpandResults(projectRoot string, results []Result, before, after int) {
+	if before <= 0 && after <= 0 {
+		return
+	}
+	for i := range results {
+		results[i].Content = ExpandContext(projectRoot, results[i], before, after)
+	}
+}
@@ -0,0 +1,49 @@
+package search
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandContext(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte("l1\nl2\nl3\nl4\nl5\nl6\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	result := Result{RelativePath: "a.go", StartLine: 3, EndLine: 4, Content: "l3\nl4"}
+
+	cases := []struct {
+		before, after int
+		want          string
+	}{
+		{0, 0, "l3\nl4"},
+		{1, 1, "l2\nl3\nl4\nl5"},
+		{2, 0, "l1\nl2\nl3\nl4"},
+		{0, 5, "l3\nl4\nl5\nl6"},
+		{9, 9, "l1\nl2\nl3\nl4\nl5\nl6"},
+	}
+	for _, tc := range cases {
+		if got := ExpandContext(root, result, tc.before, tc.after); got != tc.want {
+			t.Errorf("ExpandContext(B=%d, A=%d) = %q, want %q", tc.before, tc.after, got, tc.want)
+		}
+	}
+
+	// Results whose lines are not on disk keep their content.
+	missing := Result{RelativePath: "gone.go", StartLine: 1, EndLine: 1, Content: "x"}
+	if got := ExpandContext(root, missing, 2, 2); got != "x" {
+		t.Errorf("missing file = %q", got)
+	}
+	fromRef := result
+	fromRef.Ref = "v1.0.0"
+	if got := ExpandContext(root, fromRef, 2, 2); got != result.Content {
+		t.Errorf("ref result = %q", got)
+	}
+
+	// Without a project root, the absolute FilePath is used.
+	absolute := result
+	absolute.FilePath = filepath.Join(root, "a.go")
+	if got := ExpandContext("", absolute, 1, 0); got != "l2\nl3\nl4" {
+		t.Errorf("absolute path = %q", got)
+	}
+}
@@ -0,0 +1,272 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reranker kinds accepted by search.reranker.
+const (
+	RerankerHTTP   = "http"
+	RerankerOllama = "ollama"
+	RerankerOpenAI = "openai"
+	RerankerNone   = "none"
+)
+
+const (
+	// DefaultRerankTopN is how many leading candidates a model reranker
+	// scores when search.rerank_top_n is unset.
+	DefaultRerankTopN = 20
+	// DefaultModelRerankerTimeout bounds one model reranker request; it is
+	// longer than the HTTP default because a model reads every candidate.
+	DefaultModelRerankerTimeout = 30 * time.Second
+	// rerankContentChars caps the candidate text sent to a model, which
+	// keeps one oversized chunk from dominating the pass.
+	rerankContentChars = 4000
+	// ollamaRerankParallel bounds concurrent Ollama scoring requests.
+	ollamaRerankParallel = 4
+)
+
+// Reranker reorders retrieved results for a query. On error the input is
+// left untouched and callers keep the original order.
+type Reranker interface {
+	Rerank(ctx context.Context, query string, results []Result) ([]Result, error)
+}
+
+// RerankerConfig selects and configures a reranker.
+type RerankerConfig struct {
+	// Kind is RerankerHTTP, RerankerOllama, RerankerOpenAI, or RerankerNone.
+	// Empty means RerankerHTTP when URL is set.
+	Kind    string
+	URL     string
+	Model   string
+	APIKey  string
+	Timeout time.Duration
+	// TopN limits model rerankers to the leading candidates; the rest
+	// follow in their original order.
+	TopN int
+}
+
+// NewReranker returns the reranker described by cfg, or nil when none is
+// configured.
+func NewReranker(cfg RerankerConfig) (Reranker, error) {
+	kind := cfg.Kind
+	if kind == "" && strings.TrimSpace(cfg.URL) != "" {
+		kind = RerankerHTTP
+	}
+	switch kind {
+	case "", RerankerNone:
+		return nil, nil
+	case RerankerHTTP:
+		r := NewHTTPReranker(cfg.URL, cfg.Timeout)
+		if r == nil {
+			return nil, fmt.Errorf("search.reranker %q requires search.reranker_url", kind)
+		}
+		return r, nil
+	case RerankerOllama, RerankerOpenAI:
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("search.reranker %q requires search.reranker_model", kind)
+		}
+		if strings.TrimSpace(cfg.URL) == "" {
+			return nil, fmt.Errorf("search.reranker %q requires search.reranker_url", kind)
+		}
+		if cfg.Timeout <= 0 {
+			cfg.Timeout = DefaultModelRerankerTimeout
+		}
+		if cfg.TopN <= 0 {
+			cfg.TopN = DefaultRerankTopN
+		}
+		cfg.Kind = kind
+		cfg.URL = strings.TrimRight(strings.TrimSpace(cfg.URL), "/")
+		return &CrossEncoderReranker{config: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+	default:
+		return nil, fmt.Errorf("unknown search.reranker %q: expected http, ollama, openai, or none", kind)
+	}
+}
+
+// CrossEncoderReranker scores each (query, candidate) pair with a model and
+// reorders the leading TopN candidates by that score. Unlike the embedding
+// similarity used for retrieval, the model reads the query and the code
+// together, which separates close matches far better.
+//
+// RerankerOpenAI POSTs to a rerank endpoint in the format served by
+// llama.cpp (--reranking), vLLM, LocalAI, Jina, and Cohere-compatible
+// gateways:
+//
+//	{"model": "bge-reranker-v2-m3", "query": "...", "documents": ["..."], "top_n": 20}
+//	-> {"results": [{"index": 0, "relevance_score": 0.93}]}
+//
+// RerankerOllama has no rerank endpoint to call, so it asks a local model
+// through /api/generate for a 0-10 relevance grade per candidate.
+type CrossEncoderReranker struct {
+	config RerankerConfig
+	client *http.Client
+}
+
+// Rerank implements Reranker.
+func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, results []Result) ([]Result, error) {
+	if len(results) < 2 {
+		return results, nil
+	}
+	head := results[:min(r.config.TopN, len(results))]
+	var (
+		resp rerankResponse
+		err  error
+	)
+	if r.config.Kind == RerankerOllama {
+		resp, err = r.scoreWithOllama(ctx, query, head)
+	} else {
+		resp, err = r.scoreWithRerankAPI(ctx, query, head)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return applyRerankScores(results, resp)
+}
+
+type rerankAPIRequest struct {
+	Model     string   `json:"model"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n"`
+}
+
+type rerankAPIResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float32 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+func (r *CrossEncoderReranker) scoreWithRerankAPI(ctx context.Context, query string, results []Result) (rerankResponse, error) {
+	req := rerankAPIRequest{Model: r.config.Model, Query: query, TopN: len(results)}
+	for _, res := range results {
+		req.Documents = append(req.Documents, rerankDocument(res))
+	}
+	var apiResp rerankAPIResponse
+	if err := r.postJSON(ctx, r.config.URL, req, &apiResp); err != nil {
+		return rerankResponse{}, err
+	}
+	var resp rerankResponse
+	for _, rr := range apiResp.Results {
+		resp.Results = append(resp.Results, rerankScore{Index: rr.Index, Score: rr.RelevanceScore})
+	}
+	return resp, nil
+}
+
+type ollamaGenerateRequest struct {
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	Stream  bool           `json:"stream"`
+	Format  map[string]any `json:"format"`
+	Options map[string]any `json:"options"`
+}
+
+type ollamaGenerateResponse struct {
+	Response string `json:"response"`
+}
+
+// ollamaRelevanceFormat constrains the model's answer to {"score": 0-10}.
+var ollamaRelevanceFormat = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"score": map[string]any{"type": "integer", "minimum": 0, "maximum": 10},
+	},
+	"required": []string{"score"},
+}
+
+func (r *CrossEncoderReranker) scoreWithOllama(ctx context.Context, query string, results []Result) (rerankResponse, error) {
+	scores := make([]float32, len(results))
+	errs := make([]error, len(results))
+	sem := make(chan struct{}, ollamaRerankParallel)
+	var wg sync.WaitGroup
+	for i, res := range results {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, res Result) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			scores[i], errs[i] = r.ollamaScore(ctx, query, res)
+		}(i, res)
+	}
+	wg.Wait()
+
+	var resp rerankResponse
+	for i, err := range errs {
+		if err != nil {
+			return rerankResponse{}, err
+		}
+		resp.Results = append(resp.Results, rerankScore{Index: i, Score: scores[i]})
+	}
+	return resp, nil
+}
+
+func (r *CrossEncoderReranker) ollamaScore(ctx context.Context, query string, res Result) (float32, error) {
+	prompt := fmt.Sprintf("Rate how well the code answers the search query, from 0 (unrelated) to 10 (exactly what was asked for).\n\nQuery: %s\n\nCode:\n%s\n", query, rerankDocument(res))
+	req := ollamaGenerateRequest{
+		Model:   r.config.Model,
+		Prompt:  prompt,
+		Format:  ollamaRelevanceFormat,
+		Options: map[string]any{"temperature": 0},
+	}
+	var genResp ollamaGenerateResponse
+	if err := r.postJSON(ctx, r.config.URL+"/api/generate", req, &genResp); err != nil {
+		return 0, err
+	}
+	var grade struct {
+		Score float32 `json:"score"`
+	}
+	if err := json.Unmarshal([]byte(genResp.Response), &grade); err != nil {
+		return 0, fmt.Errorf("decode relevance grade %q: %w", genResp.Response, err)
+	}
+	return min(max(grade.Score, 0), 10) / 10, nil
+}
+
+func (r *CrossEncoderReranker) postJSON(ctx context.Context, url string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal rerank request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("create rerank request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if r.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+r.config.APIKey)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("rerank request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("reranker returned %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode rerank response: %w", err)
+	}
+	return nil
+}
+
+// rerankDocument is the candidate text a model reranker reads: the location
+// and symbol for context, then the chunk, capped at rerankContentChars.
+func rerankDocument(res Result) string {
+	content := res.Content
+	if len(content) > rerankContentChars {
+		content = content[:rerankContentChars]
+	}
+	header := res.RelativePath
+	if res.SymbolName != "" {
+		header += " " + res.SymbolName
+	}
+	return header + "\n" + content
+}
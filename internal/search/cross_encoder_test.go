@@ -0,0 +1,102 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCrossEncoderRerankerRerankAPIScoresTopN(t *testing.T) {
+	var got rerankAPIRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/rerank" {
+			t.Errorf("path = %s, want /v1/rerank", r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"results":[{"index":1,"relevance_score":0.95},{"index":0,"relevance_score":0.2}]}`))
+	}))
+	defer server.Close()
+
+	reranker, err := NewReranker(RerankerConfig{Kind: RerankerOpenAI, URL: server.URL + "/v1/rerank", Model: "bge-reranker-v2-m3", TopN: 2})
+	if err != nil {
+		t.Fatalf("NewReranker: %v", err)
+	}
+	results := []Result{
+		{RelativePath: "a.go", SymbolName: "Alpha", Content: "func Alpha()", Score: 0.8},
+		{RelativePath: "b.go", Content: "func Beta()", Score: 0.7},
+		{RelativePath: "c.go", Content: "func Gamma()", Score: 0.6},
+	}
+	reranked, warning := RerankResults(context.Background(), reranker, "beta", results)
+	if warning != "" {
+		t.Fatalf("unexpected warning: %s", warning)
+	}
+	if got.Model != "bge-reranker-v2-m3" || got.Query != "beta" || len(got.Documents) != 2 {
+		t.Fatalf("request = %+v, want the top 2 candidates", got)
+	}
+	if !strings.HasPrefix(got.Documents[0], "a.go Alpha\n") {
+		t.Fatalf("document = %q, want path and symbol header", got.Documents[0])
+	}
+	order := []string{reranked[0].RelativePath, reranked[1].RelativePath, reranked[2].RelativePath}
+	if strings.Join(order, ",") != "b.go,a.go,c.go" {
+		t.Fatalf("order = %v, want b.go,a.go,c.go", order)
+	}
+}
+
+func TestCrossEncoderRerankerOllamaGrades(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaGenerateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		grade := `{"score": 2}`
+		if strings.Contains(req.Prompt, "retryWithBackoff") {
+			grade = `{"score": 9}`
+		}
+		_ = json.NewEncoder(w).Encode(ollamaGenerateResponse{Response: grade})
+	}))
+	defer server.Close()
+
+	reranker, err := NewReranker(RerankerConfig{Kind: RerankerOllama, URL: server.URL, Model: "qwen2.5-coder:1.5b"})
+	if err != nil {
+		t.Fatalf("NewReranker: %v", err)
+	}
+	results := []Result{
+		{RelativePath: "a.go", Content: "func parseConfig()"},
+		{RelativePath: "b.go", Content: "func retryWithBackoff()"},
+	}
+	reranked, warning := RerankResults(context.Background(), reranker, "retry backoff", results)
+	if warning != "" {
+		t.Fatalf("unexpected warning: %s", warning)
+	}
+	if reranked[0].RelativePath != "b.go" || reranked[0].Score != 0.9 {
+		t.Fatalf("top = %s (%.2f), want b.go (0.90)", reranked[0].RelativePath, reranked[0].Score)
+	}
+}
+
+func TestNewRerankerConfig(t *testing.T) {
+	if r, err := NewReranker(RerankerConfig{}); r != nil || err != nil {
+		t.Fatalf("empty config = %v, %v; want no reranker", r, err)
+	}
+	if r, err := NewReranker(RerankerConfig{URL: "http://localhost:9100/rerank"}); err != nil {
+		t.Fatalf("reranker_url alone: %v", err)
+	} else if _, ok := r.(*HTTPReranker); !ok {
+		t.Fatalf("reranker_url alone = %T, want *HTTPReranker", r)
+	}
+	if r, err := NewReranker(RerankerConfig{Kind: RerankerNone, URL: "http://localhost:9100/rerank"}); r != nil || err != nil {
+		t.Fatalf("none = %v, %v; want no reranker", r, err)
+	}
+	for _, cfg := range []RerankerConfig{
+		{Kind: RerankerOllama, URL: "http://localhost:11434"},
+		{Kind: RerankerOpenAI, Model: "bge-reranker-v2-m3"},
+		{Kind: "cohere"},
+	} {
+		if _, err := NewReranker(cfg); err == nil {
+			t.Errorf("NewReranker(%+v) succeeded, want error", cfg)
+		}
+	}
+}
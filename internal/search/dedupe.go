@@ -0,0 +1,84 @@
+package search
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DedupeMode selects how repeated hits are collapsed.
+type DedupeMode string
+
+const (
+	// DedupeNone keeps every result.
+	DedupeNone DedupeMode = "none"
+	// DedupeChunk drops results whose chunk was already returned.
+	DedupeChunk DedupeMode = "chunk"
+	// DedupeFile keeps only the first (best-ranked) result per file.
+	DedupeFile DedupeMode = "file"
+)
+
+// ParseDedupeMode parses a --dedupe flag value. An empty value is DedupeNone.
+func ParseDedupeMode(value string) (DedupeMode, error) {
+	switch DedupeMode(strings.ToLower(strings.TrimSpace(value))) {
+	case "", DedupeNone:
+		return DedupeNone, nil
+	case DedupeChunk:
+		return DedupeChunk, nil
+	case DedupeFile:
+		return DedupeFile, nil
+	default:
+		return DedupeNone, fmt.Errorf("invalid dedupe mode %q: expected none, chunk, or file", value)
+	}
+}
+
+// Deduper removes results already seen across a sequence of result sets, so a
+// batch of queries returns each chunk (or file) at most once overall. Results
+// are assumed to be ranked best-first; the first occurrence wins. A Deduper is
+// not safe for concurrent use.
+type Deduper struct {
+	mode DedupeMode
+	seen map[string]struct{}
+}
+
+// NewDeduper returns a Deduper for mode. DedupeNone passes results through.
+func NewDeduper(mode DedupeMode) *Deduper {
+	return &Deduper{mode: mode, seen: make(map[string]struct{})}
+}
+
+// Filter returns the results not seen by earlier calls, in order.
+func (d *Deduper) Filter(results []Result) []Result {
+	if d == nil || d.mode == DedupeNone || d.mode == "" {
+		return results
+	}
+	kept := make([]Result, 0, len(results))
+	for _, r := range results {
+		key := d.key(r)
+		if _, dup := d.seen[key]; dup {
+			continue
+		}
+		d.seen[key] = struct{}{}
+		kept = append(kept, r)
+	}
+	return kept
+}
+
+func (d *Deduper) key(r Result) string {
+	if d.mode == DedupeFile {
+		if r.FilePath != "" {
+			return r.FilePath
+		}
+		return r.RelativePath
+	}
+	return strconv.FormatInt(r.ChunkID, 10)
+}
+
+// DedupeByChunk drops repeated chunks from one result list.
+func DedupeByChunk(results []Result) []Result {
+	return NewDeduper(DedupeChunk).Filter(results)
+}
+
+// DedupeByFile keeps only the best-ranked result per file.
+func DedupeByFile(results []Result) []Result {
+	return NewDeduper(DedupeFile).Filter(results)
+}
@@ -0,0 +1,55 @@
+package search
+
+import "testing"
+
+func TestDedupeByChunkKeepsFirstOccurrence(t *testing.T) {
+	results := []Result{
+		{ChunkID: 1, FilePath: "/p/a.go", Score: 0.9},
+		{ChunkID: 2, FilePath: "/p/a.go", Score: 0.8},
+		{ChunkID: 1, FilePath: "/p/a.go", Score: 0.7},
+	}
+	got := DedupeByChunk(results)
+	if len(got) != 2 || got[0].ChunkID != 1 || got[0].Score != 0.9 || got[1].ChunkID != 2 {
+		t.Fatalf("DedupeByChunk = %+v", got)
+	}
+}
+
+func TestDedupeByFileKeepsBestRankedChunkPerFile(t *testing.T) {
+	results := []Result{
+		{ChunkID: 1, FilePath: "/p/a.go"},
+		{ChunkID: 2, FilePath: "/p/a.go"},
+		{ChunkID: 3, FilePath: "/p/b.go"},
+	}
+	got := DedupeByFile(results)
+	if len(got) != 2 || got[0].ChunkID != 1 || got[1].ChunkID != 3 {
+		t.Fatalf("DedupeByFile = %+v", got)
+	}
+}
+
+func TestDeduperSpansMultipleQueries(t *testing.T) {
+	d := NewDeduper(DedupeChunk)
+	first := d.Filter([]Result{{ChunkID: 1}, {ChunkID: 2}})
+	second := d.Filter([]Result{{ChunkID: 2}, {ChunkID: 3}})
+	if len(first) != 2 || len(second) != 1 || second[0].ChunkID != 3 {
+		t.Fatalf("first = %+v, second = %+v", first, second)
+	}
+
+	passthrough := NewDeduper(DedupeNone)
+	passthrough.Filter([]Result{{ChunkID: 1}})
+	if got := passthrough.Filter([]Result{{ChunkID: 1}}); len(got) != 1 {
+		t.Fatalf("DedupeNone filtered results: %+v", got)
+	}
+}
+
+func TestParseDedupeMode(t *testing.T) {
+	cases := map[string]DedupeMode{"": DedupeNone, "none": DedupeNone, "chunk": DedupeChunk, "FILE": DedupeFile}
+	for in, want := range cases {
+		got, err := ParseDedupeMode(in)
+		if err != nil || got != want {
+			t.Errorf("ParseDedupeMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseDedupeMode("symbol"); err == nil {
+		t.Error("ParseDedupeMode accepted an unknown mode")
+	}
+}
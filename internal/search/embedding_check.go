@@ -0,0 +1,46 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+// ErrEmbeddingMismatch is returned, wrapped in an *EmbeddingMismatchError,
+// when a query is embedded by a different model than the index was built with.
+var ErrEmbeddingMismatch = errors.New("embedding model mismatch")
+
+// EmbeddingMismatchError reports that the query vector cannot be compared with
+// the stored vectors: similarity between two models' vectors is meaningless
+// even when their dimensions happen to agree.
+type EmbeddingMismatchError struct {
+	Indexed db.IndexedEmbedding
+	Query   db.IndexedEmbedding
+}
+
+func (e *EmbeddingMismatchError) Error() string {
+	return fmt.Sprintf("index was built with model %q (%d dimensions) but search is using %q (%d dimensions); "+
+		"run 'vecgrep index --full' to rebuild the index with the new model, or set embedding.model back to %q "+
+		"and embedding.dimensions to %d",
+		e.Indexed.Model, e.Indexed.Dimensions, e.Query.Model, e.Query.Dimensions,
+		e.Indexed.Model, e.Indexed.Dimensions)
+}
+
+func (e *EmbeddingMismatchError) Unwrap() error {
+	return ErrEmbeddingMismatch
+}
+
+// checkQueryEmbedding refuses a query vector from another model than the one
+// recorded at index time. Indexes built before the model was recorded pass.
+func (s *Searcher) checkQueryEmbedding(queryEmbedding []float32) error {
+	indexed, ok := s.db.IndexedEmbedding()
+	if !ok {
+		return nil
+	}
+	query := db.IndexedEmbedding{Model: s.provider.Model(), Dimensions: len(queryEmbedding)}
+	if query != indexed {
+		return &EmbeddingMismatchError{Indexed: indexed, Query: query}
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+func TestSearchRefusesMismatchedEmbedding(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+	setupTestData(t, database)
+	provider := newMockProvider(768)
+	searcher := NewSearcher(database, provider)
+	ctx := context.Background()
+
+	if err := database.SetIndexedEmbedding(db.IndexedEmbedding{Model: provider.Model(), Dimensions: 768}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := searcher.Search(ctx, "error handling", DefaultSearchOptions()); err != nil {
+		t.Fatalf("search with the indexed model failed: %v", err)
+	}
+
+	if err := database.SetIndexedEmbedding(db.IndexedEmbedding{Model: "other-embed", Dimensions: 768}); err != nil {
+		t.Fatal(err)
+	}
+	for _, mode := range []SearchMode{SearchModeSemantic, SearchModeHybrid} {
+		opts := DefaultSearchOptions()
+		opts.Mode = mode
+		// Hybrid search must refuse rather than degrade to keyword results.
+		_, err := searcher.SearchWithOutcome(ctx, "error handling", opts)
+		if !errors.Is(err, ErrEmbeddingMismatch) {
+			t.Fatalf("%s search error = %v, want ErrEmbeddingMismatch", mode, err)
+		}
+		if !strings.Contains(err.Error(), "vecgrep index --full") || !strings.Contains(err.Error(), `"other-embed"`) {
+			t.Errorf("%s search error lacks remediation: %v", mode, err)
+		}
+	}
+	if _, err := searcher.SearchSimilarByText(ctx, "func main() {}", SimilarOptions{}); !errors.Is(err, ErrEmbeddingMismatch) {
+		t.Errorf("similar-by-text error = %v, want ErrEmbeddingMismatch", err)
+	}
+
+	opts := DefaultSearchOptions()
+	opts.Mode = SearchModeKeyword
+	if _, err := searcher.Search(ctx, "error", opts); err != nil {
+		t.Errorf("keyword search needs no embedding but failed: %v", err)
+	}
+}
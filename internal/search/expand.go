@@ -0,0 +1,341 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	// DefaultExpandVariants is how many rewrites of the query an expanded
+	// search runs alongside the original.
+	DefaultExpandVariants = 3
+	// DefaultExpanderTimeout bounds one expansion model request.
+	DefaultExpanderTimeout = 10 * time.Second
+	// expandRRFK is the Reciprocal Rank Fusion constant for merging the
+	// variants' rankings, matching hybrid RRF fusion.
+	expandRRFK = 60
+)
+
+// QueryExpander rewrites a search query into up to n alternative phrasings
+// for an expanded (--expand) search. The original query is searched as well,
+// so implementations return only the rewrites.
+type QueryExpander interface {
+	Expand(ctx context.Context, query string, n int) ([]string, error)
+}
+
+// TemplateExpander rewrites queries without a model: one variant keeps only
+// the query's content words, the others swap words for common code-domain
+// synonyms ("delete" -> "remove", "config" -> "settings").
+type TemplateExpander struct{}
+
+// querySynonyms groups interchangeable words in code search queries. Each
+// word appears in one group only.
+var querySynonyms = [][]string{
+	{"error", "err", "failure", "exception"},
+	{"auth", "authentication", "login", "authenticate"},
+	{"config", "configuration", "settings", "options"},
+	{"delete", "remove", "drop"},
+	{"create", "new", "add", "insert"},
+	{"fetch", "get", "load", "retrieve"},
+	{"save", "store", "persist", "write"},
+	{"validate", "check", "verify"},
+	{"start", "begin", "init", "initialize"},
+	{"stop", "shutdown", "close", "terminate"},
+	{"send", "emit", "publish"},
+	{"parse", "decode", "unmarshal"},
+	{"serialize", "encode", "marshal"},
+	{"handle", "process"},
+	{"handler", "controller"},
+	{"request", "req"},
+	{"response", "resp", "reply"},
+	{"user", "account"},
+	{"database", "db", "storage"},
+	{"log", "logger", "logging"},
+	{"function", "func", "method"},
+	{"connect", "dial", "open"},
+	{"token", "jwt", "credential"},
+	{"update", "modify", "change"},
+	{"search", "query", "find", "lookup"},
+	{"timeout", "deadline"},
+	{"retry", "backoff"},
+	{"test", "spec"},
+}
+
+// expandFillerWords are dropped from the keyword-only variant on top of the
+// English stopwords used for language detection.
+var expandFillerWords = []string{"a", "an", "be", "can", "code", "find", "i", "it", "me", "show", "we", "where's", "our", "my", "there", "which", "implemented", "implementation"}
+
+var (
+	synonymIndexOnce sync.Once
+	synonymIndex     map[string][]string
+	expandStopwords  map[string]bool
+)
+
+func loadSynonymIndex() {
+	synonymIndex = make(map[string][]string)
+	for _, group := range querySynonyms {
+		for _, word := range group {
+			synonymIndex[word] = group
+		}
+	}
+	expandStopwords = make(map[string]bool)
+	for _, word := range queryStopwords["en"] {
+		expandStopwords[word] = true
+	}
+	for _, word := range expandFillerWords {
+		expandStopwords[word] = true
+	}
+}
+
+// Expand implements QueryExpander.
+func (TemplateExpander) Expand(_ context.Context, query string, n int) ([]string, error) {
+	synonymIndexOnce.Do(loadSynonymIndex)
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '\''
+	})
+
+	var keywords []string
+	for _, word := range words {
+		if !expandStopwords[word] {
+			keywords = append(keywords, word)
+		}
+	}
+	if len(keywords) == 0 {
+		keywords = words
+	}
+
+	var variants []string
+	if len(keywords) < len(words) {
+		variants = append(variants, strings.Join(keywords, " "))
+	}
+	// The i-th synonym variant replaces every word that has an i-th
+	// alternative; words with fewer alternatives stay as they are.
+	for i := 0; len(variants) < n; i++ {
+		replaced := false
+		rewritten := make([]string, len(keywords))
+		for j, word := range keywords {
+			rewritten[j] = word
+			if alternatives := synonymAlternatives(word); i < len(alternatives) {
+				rewritten[j] = alternatives[i]
+				replaced = true
+			}
+		}
+		if !replaced {
+			break
+		}
+		variants = append(variants, strings.Join(rewritten, " "))
+	}
+	return variants, nil
+}
+
+// synonymAlternatives returns word's synonyms other than itself.
+func synonymAlternatives(word string) []string {
+	var alternatives []string
+	for _, synonym := range synonymIndex[word] {
+		if synonym != word {
+			alternatives = append(alternatives, synonym)
+		}
+	}
+	return alternatives
+}
+
+// OllamaExpander asks a local model, through Ollama's /api/generate, for
+// paraphrases of the query, configured by search.expander_model.
+type OllamaExpander struct {
+	url    string
+	model  string
+	client *http.Client
+}
+
+// NewOllamaExpander returns an expander using model on the Ollama server at
+// url, or nil when model is empty so callers fall back to TemplateExpander.
+func NewOllamaExpander(url, model string, timeout time.Duration) *OllamaExpander {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = DefaultExpanderTimeout
+	}
+	return &OllamaExpander{
+		url:    strings.TrimRight(strings.TrimSpace(url), "/"),
+		model:  model,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// ollamaExpansionFormat constrains the model's answer to {"queries": [...]}.
+var ollamaExpansionFormat = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"queries": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+	},
+	"required": []string{"queries"},
+}
+
+// Expand implements QueryExpander.
+func (e *OllamaExpander) Expand(ctx context.Context, query string, n int) ([]string, error) {
+	prompt := fmt.Sprintf("Rewrite this code search query %d different ways. Use other words a programmer might use for the same code: synonyms, identifier-style terms, or a more specific description. Do not answer the query.\n\nQuery: %s\n", n, query)
+	body, err := json.Marshal(ollamaGenerateRequest{
+		Model:   e.model,
+		Prompt:  prompt,
+		Format:  ollamaExpansionFormat,
+		Options: map[string]any{"temperature": 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal expansion request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url+"/api/generate", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create expansion request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := e.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("expansion request: %w", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(httpResp.Body, 512))
+		return nil, fmt.Errorf("expansion model returned %s: %s", httpResp.Status, strings.TrimSpace(string(snippet)))
+	}
+	var genResp ollamaGenerateResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&genResp); err != nil {
+		return nil, fmt.Errorf("decode expansion response: %w", err)
+	}
+	var answer struct {
+		Queries []string `json:"queries"`
+	}
+	if err := json.Unmarshal([]byte(genResp.Response), &answer); err != nil {
+		return nil, fmt.Errorf("decode query rewrites %q: %w", genResp.Response, err)
+	}
+	return answer.Queries, nil
+}
+
+// ExpandQuery returns query followed by up to n distinct rewrites from
+// expander, or from TemplateExpander when expander is nil. A failed expander
+// falls back to the templates and returns a warning for the caller to
+// surface alongside other degraded-mode diagnostics.
+func ExpandQuery(ctx context.Context, expander QueryExpander, query string, n int) ([]string, string) {
+	if n <= 0 {
+		n = DefaultExpandVariants
+	}
+	var warning string
+	if expander == nil {
+		expander = TemplateExpander{}
+	}
+	rewrites, err := expander.Expand(ctx, query, n)
+	if err != nil {
+		warning = fmt.Sprintf("query expansion model unavailable, used synonym templates: %v", err)
+		rewrites, _ = TemplateExpander{}.Expand(ctx, query, n)
+	}
+
+	variants := []string{query}
+	seen := map[string]bool{strings.ToLower(strings.TrimSpace(query)): true}
+	for _, rewrite := range rewrites {
+		rewrite = strings.TrimSpace(rewrite)
+		key := strings.ToLower(rewrite)
+		if rewrite == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		variants = append(variants, rewrite)
+		if len(variants) > n {
+			break
+		}
+	}
+	return variants, warning
+}
+
+// expandedOutcome runs query and its rewrites in parallel and fuses their
+// rankings. The original query's failure fails the search; a failed rewrite
+// is dropped with a warning.
+func (s *Searcher) expandedOutcome(ctx context.Context, query string, opts SearchOptions, degradeOnEmbedError bool) (*SearchOutcome, error) {
+	variants, expandWarning := ExpandQuery(ctx, opts.Expander, query, DefaultExpandVariants)
+	variantOpts := opts
+	variantOpts.Expand = false
+
+	outcomes := make([]*SearchOutcome, len(variants))
+	errs := make([]error, len(variants))
+	var wg sync.WaitGroup
+	for i, variant := range variants {
+		wg.Add(1)
+		go func(i int, variant string) {
+			defer wg.Done()
+			outcomes[i], errs[i] = s.searchOutcome(ctx, variant, variantOpts, degradeOnEmbedError)
+		}(i, variant)
+	}
+	wg.Wait()
+	if errs[0] != nil {
+		return nil, errs[0]
+	}
+
+	outcome := &SearchOutcome{Mode: outcomes[0].Mode}
+	seenWarnings := make(map[string]bool)
+	addWarning := func(warning string) {
+		if warning != "" && !seenWarnings[warning] {
+			seenWarnings[warning] = true
+			outcome.Warnings = append(outcome.Warnings, warning)
+		}
+	}
+	addWarning(expandWarning)
+	var rankings [][]Result
+	for i, variantOutcome := range outcomes {
+		if errs[i] != nil {
+			addWarning(fmt.Sprintf("expanded query %q failed and was left out: %v", variants[i], errs[i]))
+			continue
+		}
+		for _, warning := range variantOutcome.Warnings {
+			addWarning(warning)
+		}
+		outcome.Partial = outcome.Partial || variantOutcome.Partial
+		rankings = append(rankings, variantOutcome.Results)
+	}
+	outcome.Results = fuseExpandedResults(rankings, opts.Limit)
+	return outcome, nil
+}
+
+// fuseExpandedResults merges the variants' rankings with Reciprocal Rank
+// Fusion: each ranking contributes 1/(k+rank). The sum is rescaled by
+// (k+1)/len(rankings), so a chunk ranked first for every variant scores 1.0.
+// A chunk keeps the fields of the first ranking that found it; ties break on
+// chunk ID.
+func fuseExpandedResults(rankings [][]Result, limit int) []Result {
+	if len(rankings) == 0 {
+		return nil
+	}
+	scores := make(map[int64]float64)
+	var fused []Result
+	for _, ranking := range rankings {
+		for rank, result := range ranking {
+			if _, ok := scores[result.ChunkID]; !ok {
+				fused = append(fused, result)
+			}
+			scores[result.ChunkID] += 1 / float64(expandRRFK+rank+1)
+		}
+	}
+	scale := float64(expandRRFK+1) / float64(len(rankings))
+	for i := range fused {
+		fused[i].Score = float32(scores[fused[i].ChunkID] * scale)
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		if fused[i].Score != fused[j].Score {
+			return fused[i].Score > fused[j].Score
+		}
+		return fused[i].ChunkID < fused[j].ChunkID
+	})
+	if limit > 0 && len(fused) > limit {
+		fused = fused[:limit]
+	}
+	return fused
+}
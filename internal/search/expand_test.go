@@ -0,0 +1,117 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplateExpander(t *testing.T) {
+	got, err := TemplateExpander{}.Expand(context.Background(), "how do we delete a user", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"delete user", "remove account", "drop user"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expand() = %q, want %q", got, want)
+	}
+
+	got, _ = TemplateExpander{}.Expand(context.Background(), "zebra", 3)
+	if len(got) != 0 {
+		t.Fatalf("Expand(no synonyms) = %q, want none", got)
+	}
+}
+
+type failingExpander struct{}
+
+func (failingExpander) Expand(context.Context, string, int) ([]string, error) {
+	return nil, errors.New("model offline")
+}
+
+func TestExpandQueryFallsBackToTemplates(t *testing.T) {
+	variants, warning := ExpandQuery(context.Background(), failingExpander{}, "parse config", 2)
+	if want := []string{"parse config", "decode configuration", "unmarshal settings"}; !reflect.DeepEqual(variants, want) {
+		t.Fatalf("variants = %q, want %q", variants, want)
+	}
+	if !strings.Contains(warning, "model offline") {
+		t.Fatalf("warning = %q, want the expander error", warning)
+	}
+}
+
+func TestOllamaExpander(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaGenerateRequest
+		if r.URL.Path != "/api/generate" || json.NewDecoder(r.Body).Decode(&req) != nil || req.Model != "llama3.2" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ollamaGenerateResponse{
+			Response: `{"queries": ["remove account", "Delete User", " ", "deleteUser handler"]}`,
+		})
+	}))
+	defer server.Close()
+
+	expander := NewOllamaExpander(server.URL+"/", "llama3.2", 0)
+	variants, warning := ExpandQuery(context.Background(), expander, "delete user", 3)
+	if warning != "" {
+		t.Fatalf("unexpected warning %q", warning)
+	}
+	// Blank rewrites and case-insensitive repeats of the query are dropped.
+	if want := []string{"delete user", "remove account", "deleteUser handler"}; !reflect.DeepEqual(variants, want) {
+		t.Fatalf("variants = %q, want %q", variants, want)
+	}
+	if NewOllamaExpander(server.URL, "", 0) != nil {
+		t.Fatal("NewOllamaExpander without a model should be nil")
+	}
+}
+
+func TestFuseExpandedResults(t *testing.T) {
+	a, b, c := Result{ChunkID: 1}, Result{ChunkID: 2}, Result{ChunkID: 3}
+	fused := fuseExpandedResults([][]Result{{a, b}, {a, c}, {b, a}}, 2)
+	if len(fused) != 2 || fused[0].ChunkID != 1 || fused[1].ChunkID != 2 {
+		t.Fatalf("fused = %+v, want chunks 1 then 2", fused)
+	}
+	if fused[0].Score >= 1 || fused[0].Score < 0.9 {
+		t.Errorf("top score = %v, want just under 1 for a hit not ranked first everywhere", fused[0].Score)
+	}
+
+	fused = fuseExpandedResults([][]Result{{a}, {a}}, 10)
+	if fused[0].Score < 0.9999 || fused[0].Score > 1.0001 {
+		t.Errorf("score = %v, want 1.0 for a hit ranked first by every variant", fused[0].Score)
+	}
+}
+
+func TestSearchExpand(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+	setupTestData(t, database)
+	searcher := NewSearcher(database, newMockProvider(768))
+
+	for _, mode := range []SearchMode{SearchModeKeyword, SearchModeHybrid} {
+		opts := DefaultSearchOptions()
+		opts.Mode = mode
+		opts.Expand = true
+		outcome, err := searcher.SearchWithOutcome(context.Background(), "how do we handle an error", opts)
+		if err != nil {
+			t.Fatalf("%s expanded search: %v", mode, err)
+		}
+		if len(outcome.Results) == 0 {
+			t.Fatalf("%s expanded search returned no results", mode)
+		}
+		seen := make(map[int64]bool)
+		for _, result := range outcome.Results {
+			if seen[result.ChunkID] {
+				t.Fatalf("%s expanded search returned chunk %d twice", mode, result.ChunkID)
+			}
+			seen[result.ChunkID] = true
+			if result.Score <= 0 || result.Score > 1 {
+				t.Errorf("%s fused score %v outside (0, 1]", mode, result.Score)
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package search
+
+import (
+	"slices"
+	"strings"
+)
+
+// InferredFilters are filters read from the wording of a natural-language
+// query, such as "python tests for the auth flow". Unlike InlineFilters they
+// are guesses: callers resolve them against the index and drop the ones that
+// match nothing.
+type InferredFilters struct {
+	// Languages are the languages the query names.
+	Languages []string
+	// TestsOnly is set when the query asks for tests.
+	TestsOnly bool
+	// Directory is a word the query uses to name part of the project, to be
+	// matched loosely against directory names ("auth" for internal/authn).
+	Directory string
+}
+
+// IsZero reports whether nothing was inferred.
+func (f InferredFilters) IsZero() bool {
+	return len(f.Languages) == 0 && !f.TestsOnly && f.Directory == ""
+}
+
+// String lists the inferred filters, as "lang=python, tests only,
+// directory≈auth".
+func (f InferredFilters) String() string {
+	var parts []string
+	if len(f.Languages) > 0 {
+		parts = append(parts, "lang="+strings.Join(f.Languages, ","))
+	}
+	if f.TestsOnly {
+		parts = append(parts, "tests only")
+	}
+	if f.Directory != "" {
+		parts = append(parts, "directory≈"+f.Directory)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// inferLanguages maps language names to language IDs. Names that are also
+// ordinary words or data formats ("go", "swift", "json") only count when a
+// code noun follows them: "go tests" names a language, "go to definition"
+// and "parse json" do not.
+var inferLanguages = map[string]struct {
+	lang      string
+	ambiguous bool
+}{
+	"python": {lang: "python"}, "golang": {lang: "go"}, "javascript": {lang: "javascript"},
+	"typescript": {lang: "typescript"}, "rust": {lang: "rust"}, "java": {lang: "java"},
+	"kotlin": {lang: "kotlin"}, "scala": {lang: "scala"}, "c++": {lang: "cpp"}, "cpp": {lang: "cpp"},
+	"c#": {lang: "csharp"}, "csharp": {lang: "csharp"}, "ruby": {lang: "ruby"}, "php": {lang: "php"},
+	"dart": {lang: "dart"}, "lua": {lang: "lua"}, "elixir": {lang: "elixir"}, "vue": {lang: "vue"},
+	"svelte": {lang: "svelte"}, "terraform": {lang: "terraform"}, "bash": {lang: "shell"},
+	"go": {lang: "go", ambiguous: true}, "c": {lang: "c", ambiguous: true},
+	"swift": {lang: "swift", ambiguous: true}, "shell": {lang: "shell", ambiguous: true},
+	"sql": {lang: "sql", ambiguous: true}, "js": {lang: "javascript", ambiguous: true},
+	"ts": {lang: "typescript", ambiguous: true}, "py": {lang: "python", ambiguous: true},
+	"markdown": {lang: "markdown", ambiguous: true}, "yaml": {lang: "yaml", ambiguous: true},
+	"json": {lang: "json", ambiguous: true}, "html": {lang: "html", ambiguous: true},
+	"css": {lang: "css", ambiguous: true},
+}
+
+// codeNouns confirm an ambiguous language name or the word "test".
+var codeNouns = map[string]bool{
+	"code": true, "file": true, "files": true, "source": true, "test": true, "tests": true,
+	"function": true, "functions": true, "script": true, "scripts": true, "module": true,
+	"modules": true, "package": true, "packages": true, "class": true, "classes": true,
+	"struct": true, "structs": true, "types": true, "snippets": true, "queries": true,
+	"templates": true, "handlers": true, "case": true, "cases": true, "suite": true,
+	"suites": true, "helpers": true, "config": true,
+}
+
+// testWords ask for tests on their own; "test" alone is often a verb and
+// needs a code noun after it ("test helpers", "test cases").
+var testWords = map[string]bool{"tests": true, "specs": true, "unittests": true, "testcases": true}
+
+// areaNouns follow a word naming a part of the project: "the auth flow",
+// "the billing module".
+var areaNouns = map[string]bool{
+	"flow": true, "flows": true, "module": true, "package": true, "directory": true, "dir": true,
+	"folder": true, "service": true, "subsystem": true, "component": true, "layer": true, "area": true,
+}
+
+// inferStopwords never name a project area.
+var inferStopwords = map[string]bool{
+	"the": true, "a": true, "an": true, "this": true, "that": true, "our": true, "my": true,
+	"main": true, "whole": true, "entire": true, "same": true, "new": true, "old": true,
+}
+
+// InferFilters reads filters from the wording of a query: language names,
+// a request for tests, and a word naming a part of the project ("the auth
+// flow", "the billing module"). It returns the query with the language and
+// test words removed (see InferredFilters.Strip) and the filters. Quoted
+// text and inline key:value filters are left alone.
+func InferFilters(query string) (string, InferredFilters) {
+	var filters InferredFilters
+	words := inferWords(query)
+	for i, word := range words {
+		lang, test := classifyInferWord(words, i)
+		switch {
+		case lang != "":
+			if !slices.Contains(filters.Languages, lang) {
+				filters.Languages = append(filters.Languages, lang)
+			}
+		case test:
+			filters.TestsOnly = true
+		case filters.Directory == "" && i+1 < len(words) && areaNouns[words[i+1]] && isAreaWord(word):
+			filters.Directory = word
+		}
+	}
+	return filters.Strip(query), filters
+}
+
+// Strip removes the words that gave f its languages and its request for
+// tests, since they describe where to look rather than what. The area word
+// is kept: directory matching is loose, and the word still helps the
+// embedding. A query that would be left empty is returned unchanged.
+func (f InferredFilters) Strip(query string) string {
+	if len(f.Languages) == 0 && !f.TestsOnly {
+		return query
+	}
+	tokens := splitQueryTokens(query)
+	words := inferWords(query)
+	var kept []string
+	for i, token := range tokens {
+		lang, test := classifyInferWord(words, i)
+		if (lang != "" && slices.Contains(f.Languages, lang)) || (test && f.TestsOnly) {
+			continue
+		}
+		kept = append(kept, token)
+	}
+	if len(kept) == 0 {
+		return query
+	}
+	return strings.Join(kept, " ")
+}
+
+// inferWords lower-cases the query's tokens and trims trailing punctuation.
+// Quoted tokens and inline filters become empty so nothing is read from
+// them.
+func inferWords(query string) []string {
+	tokens := splitQueryTokens(query)
+	words := make([]string, len(tokens))
+	for i, token := range tokens {
+		if !strings.ContainsAny(token, `":`) {
+			words[i] = strings.ToLower(strings.TrimRight(token, ".,;!?)"))
+		}
+	}
+	return words
+}
+
+// classifyInferWord reports the language words[i] names, or whether it asks
+// for tests.
+func classifyInferWord(words []string, i int) (lang string, test bool) {
+	word, next := words[i], ""
+	if i+1 < len(words) {
+		next = words[i+1]
+	}
+	if entry, ok := inferLanguages[word]; ok && (!entry.ambiguous || codeNouns[next]) {
+		return entry.lang, false
+	}
+	return "", testWords[word] || (word == "test" && codeNouns[next])
+}
+
+// isAreaWord reports whether a word can name a part of the project.
+func isAreaWord(word string) bool {
+	if len(word) < 3 || inferStopwords[word] || codeNouns[word] || testWords[word] {
+		return false
+	}
+	if _, ok := inferLanguages[word]; ok {
+		return false
+	}
+	for _, r := range word {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,80 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestInferFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		text    string
+		filters InferredFilters
+	}{
+		{
+			name:    "language tests and area",
+			query:   "python tests for the auth flow",
+			text:    "for the auth flow",
+			filters: InferredFilters{Languages: []string{"python"}, TestsOnly: true, Directory: "auth"},
+		},
+		{
+			name:    "ambiguous language needs a code noun",
+			query:   "go to definition handler",
+			text:    "go to definition handler",
+			filters: InferredFilters{},
+		},
+		{
+			name:    "ambiguous language with a code noun",
+			query:   "go code that parses json",
+			text:    "code that parses json",
+			filters: InferredFilters{Languages: []string{"go"}},
+		},
+		{
+			name:    "test as a verb",
+			query:   "how do we test retries",
+			text:    "how do we test retries",
+			filters: InferredFilters{},
+		},
+		{
+			name:    "area noun",
+			query:   "rate limiting in the billing module",
+			text:    "rate limiting in the billing module",
+			filters: InferredFilters{Directory: "billing"},
+		},
+		{
+			name:    "quoted and inline text is left alone",
+			query:   `"python tests" lang:go`,
+			text:    `"python tests" lang:go`,
+			filters: InferredFilters{},
+		},
+		{
+			name:    "query made only of filter words is kept",
+			query:   "rust tests",
+			text:    "rust tests",
+			filters: InferredFilters{Languages: []string{"rust"}, TestsOnly: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, filters := InferFilters(tt.query)
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			if !reflect.DeepEqual(filters, tt.filters) {
+				t.Errorf("filters = %+v, want %+v", filters, tt.filters)
+			}
+		})
+	}
+}
+
+func TestInferredFiltersStripKeepsUnmatchedWords(t *testing.T) {
+	// Only the filters that survived resolution are stripped.
+	kept := InferredFilters{TestsOnly: true}
+	if got := kept.Strip("python tests for the auth flow"); got != "python for the auth flow" {
+		t.Errorf("Strip = %q", got)
+	}
+	if got := (InferredFilters{Directory: "auth"}).String(); got != "directory≈auth" {
+		t.Errorf("String = %q", got)
+	}
+}
@@ -32,7 +32,7 @@ func TestConvertOutcomeResults_KeywordScoresNormalized(t *testing.T) {
 		keywordSearchResult(3, "c.go", 1.0),
 	}
 
-	results := convertOutcomeResults(searchResults, SearchModeKeyword, 0.4, 10)
+	results := convertOutcomeResults(searchResults, SearchModeKeyword, SearchOptions{MinScore: 0.4, Limit: 10})
 	if len(results) != 2 {
 		t.Fatalf("got %d results, want 2 (MinScore=0.4 should drop the 1.0/8.0 hit)", len(results))
 	}
@@ -58,7 +58,7 @@ func TestConvertOutcomeResults_NonKeywordModesUntouched(t *testing.T) {
 	}
 
 	for _, mode := range []SearchMode{SearchModeHybrid, SearchModeSemantic} {
-		results := convertOutcomeResults(searchResults, mode, 0, 10)
+		results := convertOutcomeResults(searchResults, mode, SearchOptions{Limit: 10})
 		if len(results) != 2 {
 			t.Fatalf("mode %s: got %d results, want 2", mode, len(results))
 		}
@@ -0,0 +1,159 @@
+package search
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// MarkdownOptions configures FormatMarkdownReport.
+type MarkdownOptions struct {
+	// GroupByFile puts results under one heading per file, in order of each
+	// file's best result.
+	GroupByFile bool
+	// LinkBase is joined in front of result paths in links, such as "../.."
+	// for a report written two directories below the project root. Empty
+	// leaves links project-relative.
+	LinkBase string
+}
+
+// FormatMarkdownReport renders results as a shareable Markdown report: a
+// heading with the query, then each result as a linked file:line heading and
+// a syntax-fenced snippet. Annotations are rendered as quoted notes.
+func FormatMarkdownReport(query string, results []Result, opts MarkdownOptions) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Search report: %s\n\n", markdownCode(query))
+	if len(results) == 0 {
+		sb.WriteString("No results found.\n")
+		return sb.String()
+	}
+
+	var files []string
+	byFile := make(map[string][]Result)
+	for _, r := range results {
+		if _, seen := byFile[r.RelativePath]; !seen {
+			files = append(files, r.RelativePath)
+		}
+		byFile[r.RelativePath] = append(byFile[r.RelativePath], r)
+	}
+	fmt.Fprintf(&sb, "%d %s across %d %s.\n\n", len(results), plural(len(results), "result", "results"), len(files), plural(len(files), "file", "files"))
+
+	if !opts.GroupByFile {
+		for i, r := range results {
+			writeMarkdownResult(&sb, "###", fmt.Sprintf("%d. ", i+1), r, opts)
+		}
+		return sb.String()
+	}
+	for _, file := range files {
+		group := byFile[file]
+		fmt.Fprintf(&sb, "## %s (%d %s)\n\n", markdownCode(file), len(group), plural(len(group), "result", "results"))
+		for _, r := range group {
+			writeMarkdownResult(&sb, "###", "", r, opts)
+		}
+	}
+	return sb.String()
+}
+
+// QueryResults is one query's results in a batch search. Error is set, and
+// Results empty, when the query's search failed.
+type QueryResults struct {
+	Query   string   `json:"query"`
+	Results []Result `json:"results"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// FormatBatchMarkdownReport renders a batch search as one Markdown report:
+// a section per query, in order, holding its results as FormatMarkdownReport
+// would.
+func FormatBatchMarkdownReport(groups []QueryResults, opts MarkdownOptions) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Batch search report (%d %s)\n\n", len(groups), plural(len(groups), "query", "queries"))
+	for _, group := range groups {
+		fmt.Fprintf(&sb, "## %s\n\n", markdownCode(group.Query))
+		switch {
+		case group.Error != "":
+			fmt.Fprintf(&sb, "Search failed: %s\n\n", group.Error)
+		case len(group.Results) == 0:
+			sb.WriteString("No results found.\n\n")
+		default:
+			for i, r := range group.Results {
+				writeMarkdownResult(&sb, "###", fmt.Sprintf("%d. ", i+1), r, opts)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func writeMarkdownResult(sb *strings.Builder, heading, prefix string, r Result, opts MarkdownOptions) {
+	location := fmt.Sprintf("%s:%d-%d", r.RelativePath, r.StartLine, r.EndLine)
+	link := fmt.Sprintf("[%s](%s)", location, markdownLink(r, opts.LinkBase))
+	if r.Annotation {
+		fmt.Fprintf(sb, "%s %sAnnotation on %s\n\n", heading, prefix, link)
+		for _, line := range strings.Split(r.Content, "\n") {
+			fmt.Fprintf(sb, "> %s\n", line)
+		}
+		sb.WriteString("\n")
+		return
+	}
+
+	meta := []string{fmt.Sprintf("score %.2f", r.Score)}
+	if r.ChunkType != "" && r.ChunkType != "generic" {
+		meta = append([]string{r.ChunkType}, meta...)
+	}
+	if r.SymbolName != "" {
+		meta = append([]string{markdownCode(r.SymbolName)}, meta...)
+	}
+	fmt.Fprintf(sb, "%s %s%s — %s\n\n", heading, prefix, link, strings.Join(meta, " · "))
+
+	fence := markdownFence(r.Content)
+	lang := r.Language
+	if lang == "unknown" {
+		lang = ""
+	}
+	fmt.Fprintf(sb, "%s%s\n%s\n%s\n\n", fence, lang, strings.TrimRight(r.Content, "\n"), fence)
+}
+
+// markdownLink points at the result's lines using the #Lstart-Lend anchors
+// GitHub, GitLab, and most Markdown viewers understand.
+func markdownLink(r Result, base string) string {
+	target := r.RelativePath
+	if base != "" && base != "." {
+		target = path.Join(base, target)
+	}
+	target = strings.ReplaceAll(target, " ", "%20")
+	if r.EndLine > r.StartLine {
+		return fmt.Sprintf("%s#L%d-L%d", target, r.StartLine, r.EndLine)
+	}
+	return fmt.Sprintf("%s#L%d", target, r.StartLine)
+}
+
+// markdownFence returns a backtick fence longer than any backtick run in
+// content, so snippets containing fences render intact.
+func markdownFence(content string) string {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
+
+// markdownCode wraps text in a code span, widening the delimiters when the
+// text itself contains backticks.
+func markdownCode(text string) string {
+	if !strings.Contains(text, "`") {
+		return "`" + text + "`"
+	}
+	return "`` " + text + " ``"
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
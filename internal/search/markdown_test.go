@@ -0,0 +1,63 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatMarkdownReport(t *testing.T) {
+	results := []Result{
+		{RelativePath: "internal/log/log.go", StartLine: 10, EndLine: 14, Language: "go", ChunkType: "function", SymbolName: "LogUser", Score: 0.82, Content: "func LogUser(u User) {\n\tlog.Printf(\"%s\", u.Email)\n}"},
+		{RelativePath: "docs/logging.md", StartLine: 3, EndLine: 3, Language: "markdown", Score: 0.61, Content: "Use ```redact``` for PII."},
+		{RelativePath: "internal/log/log.go", StartLine: 30, EndLine: 32, Language: "go", Score: 0.55, Content: "log.Println(email)"},
+		{RelativePath: "internal/log/log.go", StartLine: 10, EndLine: 14, Annotation: true, Score: 0.5, Content: "emails must be hashed"},
+	}
+
+	flat := FormatMarkdownReport("log PII", results, MarkdownOptions{LinkBase: ".."})
+	for _, want := range []string{
+		"# Search report: `log PII`",
+		"4 results across 2 files.",
+		"### 1. [internal/log/log.go:10-14](../internal/log/log.go#L10-L14) — `LogUser` · function · score 0.82",
+		"```go\nfunc LogUser(u User) {",
+		"[docs/logging.md:3-3](../docs/logging.md#L3)",
+		"````markdown\nUse ```redact``` for PII.\n````",
+		"### 4. Annotation on [internal/log/log.go:10-14]",
+		"> emails must be hashed",
+	} {
+		if !strings.Contains(flat, want) {
+			t.Errorf("flat report missing %q:\n%s", want, flat)
+		}
+	}
+
+	grouped := FormatMarkdownReport("log PII", results, MarkdownOptions{GroupByFile: true})
+	logHeading := strings.Index(grouped, "## `internal/log/log.go` (3 results)")
+	docsHeading := strings.Index(grouped, "## `docs/logging.md` (1 result)")
+	if logHeading < 0 || docsHeading < 0 || docsHeading < logHeading {
+		t.Fatalf("grouped report headings missing or out of order:\n%s", grouped)
+	}
+	if !strings.Contains(grouped, "(internal/log/log.go#L30-L32)") {
+		t.Errorf("grouped report should keep project-relative links:\n%s", grouped)
+	}
+
+	if empty := FormatMarkdownReport("nothing", nil, MarkdownOptions{}); !strings.Contains(empty, "No results found.") {
+		t.Errorf("empty report = %q", empty)
+	}
+}
+
+func TestFormatBatchMarkdownReport(t *testing.T) {
+	report := FormatBatchMarkdownReport([]QueryResults{
+		{Query: "retry", Results: []Result{{RelativePath: "retry.go", StartLine: 1, EndLine: 4, Language: "go", Score: 0.9, Content: "func Retry() {}"}}},
+		{Query: "nothing", Results: []Result{}},
+		{Query: "broken", Error: "embedding provider unavailable"},
+	}, MarkdownOptions{})
+	for _, want := range []string{
+		"# Batch search report (3 queries)",
+		"## `retry`\n\n### 1. [retry.go:1-4](retry.go#L1-L4) — score 0.90",
+		"## `nothing`\n\nNo results found.",
+		"## `broken`\n\nSearch failed: embedding provider unavailable",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("batch report missing %q:\n%s", want, report)
+		}
+	}
+}
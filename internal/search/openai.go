@@ -0,0 +1,69 @@
+package search
+
+import "path/filepath"
+
+// OpenAISearchPage mirrors the response of OpenAI's vector store search
+// endpoint (POST /v1/vector_stores/{id}/search), so RAG clients written
+// against that API can consume vecgrep results without an adapter.
+type OpenAISearchPage struct {
+	Object      string             `json:"object"`
+	SearchQuery string             `json:"search_query"`
+	Data        []OpenAISearchItem `json:"data"`
+	HasMore     bool               `json:"has_more"`
+	NextPage    *string            `json:"next_page"`
+	// Warnings is a vecgrep extension carrying the search's warnings, such
+	// as results omitted to fit the response size cap.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// OpenAISearchItem is one hit in an OpenAISearchPage. vecgrep's location
+// and chunk metadata travel in Attributes.
+type OpenAISearchItem struct {
+	FileID     string                `json:"file_id"`
+	Filename   string                `json:"filename"`
+	Score      float32               `json:"score"`
+	Attributes map[string]any        `json:"attributes"`
+	Content    []OpenAISearchContent `json:"content"`
+}
+
+// OpenAISearchContent is a content part of an OpenAISearchItem.
+type OpenAISearchContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// NewOpenAISearchPage converts results to the OpenAI vector store search
+// shape. file_id is the project-relative path, which is stable across
+// re-indexing; vecgrep returns a single page.
+func NewOpenAISearchPage(query string, results []Result) OpenAISearchPage {
+	page := OpenAISearchPage{
+		Object:      "vector_store.search_results.page",
+		SearchQuery: query,
+		Data:        make([]OpenAISearchItem, 0, len(results)),
+	}
+	for _, r := range results {
+		attributes := map[string]any{
+			"path":       r.RelativePath,
+			"start_line": r.StartLine,
+			"end_line":   r.EndLine,
+			"chunk_type": r.ChunkType,
+		}
+		if r.Language != "" && r.Language != "unknown" {
+			attributes["language"] = r.Language
+		}
+		if r.SymbolName != "" {
+			attributes["symbol"] = r.SymbolName
+		}
+		if r.Annotation {
+			attributes["annotation"] = true
+		}
+		page.Data = append(page.Data, OpenAISearchItem{
+			FileID:     r.RelativePath,
+			Filename:   filepath.Base(r.RelativePath),
+			Score:      r.Score,
+			Attributes: attributes,
+			Content:    []OpenAISearchContent{{Type: "text", Text: r.Content}},
+		})
+	}
+	return page
+}
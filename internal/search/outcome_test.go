@@ -124,3 +124,56 @@ func TestSearchWithOutcome_HealthyHybridHasNoWarnings(t *testing.T) {
 		t.Errorf("outcome.Mode = %q, want %q", outcome.Mode, SearchModeHybrid)
 	}
 }
+
+// blockingProvider embeds nothing until its context ends, simulating a
+// provider too slow for the caller's search deadline.
+type blockingProvider struct {
+	failingProvider
+}
+
+func (p *blockingProvider) Embed(ctx context.Context, text string) ([]float32, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// TestSearchWithOutcome_DeadlineReturnsPartialResults verifies a time-bounded
+// search answers with keyword results marked Partial when its deadline passes
+// while the query is still being embedded, even in semantic mode.
+func TestSearchWithOutcome_DeadlineReturnsPartialResults(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+	setupTestData(t, database)
+
+	searcher := NewSearcher(database, &blockingProvider{failingProvider{dimensions: 768}})
+
+	for _, mode := range []SearchMode{SearchModeHybrid, SearchModeSemantic} {
+		opts := DefaultSearchOptions()
+		opts.Mode = mode
+		opts.AllowPartial = true
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		outcome, err := searcher.SearchWithOutcome(ctx, "HandleError", opts)
+		cancel()
+		if err != nil {
+			t.Fatalf("%s: SearchWithOutcome should return partial results, got %v", mode, err)
+		}
+		if !outcome.Partial || outcome.Mode != SearchModeKeyword {
+			t.Errorf("%s: outcome = partial %v, mode %q; want partial keyword results", mode, outcome.Partial, outcome.Mode)
+		}
+		if len(outcome.Results) == 0 {
+			t.Errorf("%s: partial search found no keyword results", mode)
+		}
+		if len(outcome.Warnings) != 1 || !strings.Contains(outcome.Warnings[0], "deadline") {
+			t.Errorf("%s: warnings = %v, want the deadline explained", mode, outcome.Warnings)
+		}
+	}
+
+	// Without AllowPartial the deadline stays an error.
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	opts := DefaultSearchOptions()
+	opts.Mode = SearchModeSemantic
+	if _, err := searcher.SearchWithOutcome(ctx, "HandleError", opts); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SearchWithOutcome() error = %v, want the deadline", err)
+	}
+}
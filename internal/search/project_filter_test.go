@@ -8,29 +8,30 @@ import (
 	"github.com/abdul-hamid-achik/vecgrep/internal/db"
 )
 
+func insertRootChunk(t *testing.T, database *db.DB, root, rel, content string) {
+	t.Helper()
+	chunk := db.NewChunkRecord(
+		filepath.Join(root, rel),
+		rel,
+		"hash",
+		int64(len(content)),
+		"go",
+		content,
+		1, 1, 0, len(content),
+		"function",
+		"LoadConfig",
+		root,
+	)
+	if _, err := database.InsertChunk(chunk, make([]float32, database.Dimensions())); err != nil {
+		t.Fatalf("InsertChunk failed: %v", err)
+	}
+}
+
 func TestSearchFiltersByProjectRoot(t *testing.T) {
 	database := setupTestDB(t)
 	defer database.Close()
 
-	dims := database.Dimensions()
-	insert := func(root, rel, content string) {
-		t.Helper()
-		chunk := db.NewChunkRecord(
-			filepath.Join(root, rel),
-			rel,
-			"hash",
-			int64(len(content)),
-			"go",
-			content,
-			1, 1, 0, len(content),
-			"function",
-			"LoadConfig",
-			root,
-		)
-		if _, err := database.InsertChunk(chunk, make([]float32, dims)); err != nil {
-			t.Fatalf("InsertChunk failed: %v", err)
-		}
-	}
+	insert := func(root, rel, content string) { insertRootChunk(t, database, root, rel, content) }
 
 	insert("/tmp/project-a", "main.go", "func LoadConfig() {}")
 	insert("/tmp/project-b", "main.go", "func LoadConfig() {}")
@@ -51,3 +52,41 @@ func TestSearchFiltersByProjectRoot(t *testing.T) {
 		t.Fatalf("unexpected project result: %s", results[0].FilePath)
 	}
 }
+
+func TestSearchAcrossProjectRootsLabelsExtraRoots(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+
+	insertRootChunk(t, database, "/tmp/app", "main.go", "func LoadConfig() {}")
+	insertRootChunk(t, database, "/tmp/shared", "config/load.go", "func LoadConfig() {}")
+	insertRootChunk(t, database, "/tmp/other", "main.go", "func LoadConfig() {}")
+
+	searcher := NewSearcher(database, nil)
+	results, err := searcher.Search(context.Background(), "LoadConfig", SearchOptions{
+		Mode:         SearchModeKeyword,
+		Limit:        10,
+		ProjectRoot:  "/tmp/app",
+		ProjectRoots: []string{"/tmp/app", "/tmp/shared"},
+	})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected results from both roots, got %d", len(results))
+	}
+
+	LabelRoots(results, map[string]string{"/tmp/shared": "shared"})
+	roots := map[string]string{}
+	for _, r := range results {
+		roots[r.FilePath] = r.Root
+	}
+	if got := roots["/tmp/app/main.go"]; got != "" {
+		t.Errorf("project root result labeled %q, want unlabeled", got)
+	}
+	if got := roots["/tmp/shared/config/load.go"]; got != "shared" {
+		t.Errorf("extra root result labeled %q, want shared", got)
+	}
+	if _, ok := roots["/tmp/other/main.go"]; ok {
+		t.Error("search leaked a result from an unrelated project root")
+	}
+}
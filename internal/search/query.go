@@ -0,0 +1,228 @@
+package search
+
+import (
+	"strconv"
+	"strings"
+)
+
+// InlineFilters are the filters written into a query string, such as
+// `lang:go type:function path:internal/** "retry backoff"`.
+type InlineFilters struct {
+	Languages   []string
+	ChunkTypes  []string
+	FilePattern string
+	Directory   string
+	MinLine     int
+	MaxLine     int
+	MinScore    float32
+	Symbol      string
+	Tags        []string
+}
+
+// IsZero reports whether no inline filter was found.
+func (f InlineFilters) IsZero() bool {
+	return len(f.Languages) == 0 && len(f.ChunkTypes) == 0 && f.FilePattern == "" &&
+		f.Directory == "" && f.MinLine == 0 && f.MaxLine == 0 && f.MinScore == 0 &&
+		f.Symbol == "" && len(f.Tags) == 0
+}
+
+// ParseQuery splits inline filters out of a query and returns the remaining
+// search text with the filters. Recognized filters:
+//
+//	lang:go,rust     language (also language:)
+//	type:function    chunk type
+//	path:glob        file glob; a plain path or one ending in /** is a
+//	                 directory prefix (also file:)
+//	dir:internal/x   directory prefix
+//	lines:10-200     start-line range (lines:10- and lines:-200 work too)
+//	score:0.5        minimum score (also min-score:)
+//	symbol:Name      symbol name, case-insensitive; globs like New* work
+//	                 (also sym:)
+//	tag:api,infra    indexing.tags label
+//
+// Comma-separated values and repeated filters are ORed. Double quotes group
+// words, and a quoted token is always search text, so `"lang:go"` searches
+// for the literal string. Tokens with unknown keys or unparseable values stay
+// in the text, so code like `std::vector` or a URL is searched as written.
+// Quotes are kept in the returned text, which makes ParseQuery idempotent.
+func ParseQuery(raw string) (string, InlineFilters) {
+	var (
+		filters InlineFilters
+		text    []string
+	)
+	for _, token := range splitQueryTokens(raw) {
+		if !applyInlineFilter(&filters, token) {
+			text = append(text, token)
+		}
+	}
+	return strings.Join(text, " "), filters
+}
+
+// ApplyTo fills the options that are still unset with the inline filters;
+// explicit options (CLI flags, MCP arguments) take precedence.
+func (f InlineFilters) ApplyTo(opts SearchOptions) SearchOptions {
+	if opts.Language == "" && len(opts.Languages) == 0 {
+		opts.Languages = f.Languages
+	}
+	if opts.ChunkType == "" && len(opts.ChunkTypes) == 0 {
+		opts.ChunkTypes = f.ChunkTypes
+	}
+	if opts.FilePattern == "" {
+		opts.FilePattern = f.FilePattern
+	}
+	if opts.Directory == "" {
+		opts.Directory = f.Directory
+	}
+	if opts.MinLine == 0 && opts.MaxLine == 0 {
+		opts.MinLine, opts.MaxLine = f.MinLine, f.MaxLine
+	}
+	if opts.MinScore == 0 {
+		opts.MinScore = f.MinScore
+	}
+	if opts.Symbol == "" {
+		opts.Symbol = f.Symbol
+	}
+	if len(opts.Tags) == 0 {
+		opts.Tags = f.Tags
+	}
+	return opts
+}
+
+// ApplyInlineQuery strips inline filters from query and merges them into
+// opts. A query made only of filters keeps its original text so the search
+// still has something to embed or match, except that a bare symbol filter
+// searches for the symbol name itself.
+func ApplyInlineQuery(query string, opts SearchOptions) (string, SearchOptions) {
+	text, filters := ParseQuery(query)
+	if filters.IsZero() {
+		return query, opts
+	}
+	if strings.TrimSpace(text) == "" {
+		if filters.Symbol != "" && !strings.ContainsAny(filters.Symbol, "*?[") {
+			return filters.Symbol, filters.ApplyTo(opts)
+		}
+		return query, filters.ApplyTo(opts)
+	}
+	return text, filters.ApplyTo(opts)
+}
+
+// splitQueryTokens splits on whitespace outside double quotes, keeping the
+// quotes in each token.
+func splitQueryTokens(raw string) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+		quoted  bool
+	)
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case !quoted && (r == ' ' || r == '\t' || r == '\n' || r == '\r'):
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
+
+// applyInlineFilter records token in filters when it is a recognized
+// key:value filter and reports whether it was consumed.
+func applyInlineFilter(filters *InlineFilters, token string) bool {
+	key, value, ok := strings.Cut(token, ":")
+	if !ok || key == "" || strings.HasPrefix(key, `"`) {
+		return false
+	}
+	value = strings.Trim(value, `"`)
+	if value == "" {
+		return false
+	}
+	switch strings.ToLower(key) {
+	case "lang", "language":
+		filters.Languages = appendCSV(filters.Languages, strings.ToLower(value))
+	case "type":
+		filters.ChunkTypes = appendCSV(filters.ChunkTypes, strings.ToLower(value))
+	case "path", "file":
+		if dir, isDir := directoryGlob(value); isDir {
+			filters.Directory = dir
+		} else {
+			filters.FilePattern = value
+		}
+	case "dir":
+		filters.Directory = strings.TrimSuffix(strings.TrimPrefix(value, "./"), "/")
+	case "lines":
+		minLine, maxLine, ok := parseLineRange(value)
+		if !ok {
+			return false
+		}
+		filters.MinLine, filters.MaxLine = minLine, maxLine
+	case "score", "min-score":
+		score, err := strconv.ParseFloat(value, 32)
+		if err != nil || score < 0 || score > 1 {
+			return false
+		}
+		filters.MinScore = float32(score)
+	case "symbol", "sym":
+		filters.Symbol = value
+	case "tag":
+		filters.Tags = appendCSV(filters.Tags, strings.ToLower(value))
+	default:
+		return false
+	}
+	return true
+}
+
+// directoryGlob reports whether a path filter names a directory: a path
+// without glob characters, or a prefix followed by "/**".
+func directoryGlob(value string) (string, bool) {
+	value = strings.TrimPrefix(value, "./")
+	prefix := strings.TrimSuffix(value, "/**")
+	if strings.ContainsAny(prefix, "*?[") {
+		return "", false
+	}
+	if prefix != value || strings.HasSuffix(value, "/") {
+		return strings.TrimSuffix(prefix, "/"), true
+	}
+	// A plain path with an extension is a file, not a directory.
+	if strings.Contains(value[strings.LastIndex(value, "/")+1:], ".") {
+		return "", false
+	}
+	return value, true
+}
+
+func parseLineRange(value string) (int, int, bool) {
+	lo, hi, found := strings.Cut(value, "-")
+	parse := func(s string) (int, bool) {
+		if s == "" {
+			return 0, true
+		}
+		n, err := strconv.Atoi(s)
+		return n, err == nil && n > 0
+	}
+	minLine, okMin := parse(lo)
+	if !found {
+		return minLine, minLine, okMin && minLine > 0
+	}
+	maxLine, okMax := parse(hi)
+	if !okMin || !okMax || (minLine == 0 && maxLine == 0) || (maxLine > 0 && maxLine < minLine) {
+		return 0, 0, false
+	}
+	return minLine, maxLine, true
+}
+
+func appendCSV(dst []string, value string) []string {
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			dst = append(dst, part)
+		}
+	}
+	return dst
+}
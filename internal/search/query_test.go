@@ -0,0 +1,112 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		text    string
+		filters InlineFilters
+	}{
+		{
+			name:    "all filters",
+			raw:     `lang:go type:function path:internal/** "retry backoff" lines:10-200 score:0.4`,
+			text:    `"retry backoff"`,
+			filters: InlineFilters{Languages: []string{"go"}, ChunkTypes: []string{"function"}, Directory: "internal", MinLine: 10, MaxLine: 200, MinScore: 0.4},
+		},
+		{
+			name:    "repeated and comma-separated values",
+			raw:     "Lang:Go lang:rust,python type:interface,class payment provider",
+			text:    "payment provider",
+			filters: InlineFilters{Languages: []string{"go", "rust", "python"}, ChunkTypes: []string{"interface", "class"}},
+		},
+		{
+			name:    "path glob",
+			raw:     `retry path:"internal/*/*_test.go"`,
+			text:    "retry",
+			filters: InlineFilters{FilePattern: "internal/*/*_test.go"},
+		},
+		{
+			name:    "plain file path is a glob",
+			raw:     "file:cmd/vecgrep/main.go flags",
+			text:    "flags",
+			filters: InlineFilters{FilePattern: "cmd/vecgrep/main.go"},
+		},
+		{
+			name:    "dir and open line range",
+			raw:     "dir:./internal/search/ lines:-50 scoring",
+			text:    "scoring",
+			filters: InlineFilters{Directory: "internal/search", MaxLine: 50},
+		},
+		{
+			name:    "symbol filter",
+			raw:     "symbol:NewSearcher retry logic",
+			text:    "retry logic",
+			filters: InlineFilters{Symbol: "NewSearcher"},
+		},
+		{
+			name:    "tag filter",
+			raw:     "tag:API,infra rate limit tag:frontend",
+			text:    "rate limit",
+			filters: InlineFilters{Tags: []string{"api", "infra", "frontend"}},
+		},
+		{
+			name: "code and unknown keys stay in text",
+			raw:  `std::vector http://example.com lang: "type:function" lines:abc score:2`,
+			text: `std::vector http://example.com lang: "type:function" lines:abc score:2`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, filters := ParseQuery(tt.raw)
+			if text != tt.text {
+				t.Errorf("text = %q, want %q", text, tt.text)
+			}
+			if !reflect.DeepEqual(filters, tt.filters) {
+				t.Errorf("filters = %+v, want %+v", filters, tt.filters)
+			}
+			again, refilters := ParseQuery(text)
+			if again != text || !refilters.IsZero() {
+				t.Errorf("ParseQuery is not idempotent: %q -> %q %+v", text, again, refilters)
+			}
+		})
+	}
+}
+
+func TestApplyInlineQueryKeepsExplicitOptions(t *testing.T) {
+	query, opts := ApplyInlineQuery("lang:go type:function dir:internal retry", SearchOptions{Language: "rust", Directory: "cmd"})
+	if query != "retry" {
+		t.Fatalf("query = %q, want retry", query)
+	}
+	if opts.Language != "rust" || len(opts.Languages) != 0 {
+		t.Errorf("explicit language overridden: %+v", opts)
+	}
+	if opts.Directory != "cmd" {
+		t.Errorf("explicit directory overridden: %q", opts.Directory)
+	}
+	if !reflect.DeepEqual(opts.ChunkTypes, []string{"function"}) {
+		t.Errorf("ChunkTypes = %v, want [function]", opts.ChunkTypes)
+	}
+
+	// A query of only filters keeps its text so there is still something to
+	// search for.
+	query, opts = ApplyInlineQuery("type:interface", SearchOptions{})
+	if query != "type:interface" || !reflect.DeepEqual(opts.ChunkTypes, []string{"interface"}) {
+		t.Errorf("filters-only query = %q %+v", query, opts)
+	}
+}
+
+func TestApplyInlineQueryBareSymbolSearchesName(t *testing.T) {
+	query, opts := ApplyInlineQuery("sym:NewSearcher", SearchOptions{})
+	if query != "NewSearcher" || opts.Symbol != "NewSearcher" {
+		t.Fatalf("got query %q symbol %q, want the symbol name as both", query, opts.Symbol)
+	}
+	query, opts = ApplyInlineQuery("symbol:New*", SearchOptions{})
+	if query != "symbol:New*" || opts.Symbol != "New*" {
+		t.Fatalf("glob-only query = %q symbol %q, want the raw query kept", query, opts.Symbol)
+	}
+}
@@ -0,0 +1,160 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultRerankerTimeout bounds one reranker request when search.reranker_timeout
+// is unset.
+const DefaultRerankerTimeout = 5 * time.Second
+
+// HTTPReranker reorders search results through an external HTTP service
+// configured by search.reranker_url. It POSTs the query and candidates:
+//
+//	{"query": "retry backoff",
+//	 "candidates": [{"index": 0, "file": "net/retry.go", "start_line": 10,
+//	                 "end_line": 42, "language": "go", "symbol": "Backoff",
+//	                 "content": "...", "score": 0.71}]}
+//
+// and expects a 2xx response with new scores keyed by candidate index:
+//
+//	{"results": [{"index": 0, "score": 0.93}]}
+//
+// Scored candidates are ordered by descending score; candidates the service
+// omits keep their original score and order after the scored ones.
+type HTTPReranker struct {
+	url    string
+	client *http.Client
+}
+
+type rerankRequest struct {
+	Query      string            `json:"query"`
+	Candidates []rerankCandidate `json:"candidates"`
+}
+
+type rerankCandidate struct {
+	Index     int     `json:"index"`
+	File      string  `json:"file"`
+	StartLine int     `json:"start_line"`
+	EndLine   int     `json:"end_line"`
+	Language  string  `json:"language,omitempty"`
+	Symbol    string  `json:"symbol,omitempty"`
+	Content   string  `json:"content"`
+	Score     float32 `json:"score"`
+}
+
+type rerankResponse struct {
+	Results []rerankScore `json:"results"`
+}
+
+type rerankScore struct {
+	Index int     `json:"index"`
+	Score float32 `json:"score"`
+}
+
+// NewHTTPReranker returns a reranker for url, or nil when url is empty so
+// callers can skip reranking with a nil check.
+func NewHTTPReranker(url string, timeout time.Duration) *HTTPReranker {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = DefaultRerankerTimeout
+	}
+	return &HTTPReranker{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Rerank returns results reordered by the service. On error the input is
+// left untouched and callers should keep the original order.
+func (r *HTTPReranker) Rerank(ctx context.Context, query string, results []Result) ([]Result, error) {
+	if r == nil || len(results) < 2 {
+		return results, nil
+	}
+	req := rerankRequest{Query: query, Candidates: make([]rerankCandidate, len(results))}
+	for i, res := range results {
+		req.Candidates[i] = rerankCandidate{
+			Index:     i,
+			File:      res.RelativePath,
+			StartLine: res.StartLine,
+			EndLine:   res.EndLine,
+			Language:  res.Language,
+			Symbol:    res.SymbolName,
+			Content:   res.Content,
+			Score:     res.Score,
+		}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal rerank request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create rerank request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := r.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("rerank request: %w", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(httpResp.Body, 512))
+		return nil, fmt.Errorf("reranker returned %s: %s", httpResp.Status, strings.TrimSpace(string(snippet)))
+	}
+	var resp rerankResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decode rerank response: %w", err)
+	}
+	return applyRerankScores(results, resp)
+}
+
+func applyRerankScores(results []Result, resp rerankResponse) ([]Result, error) {
+	scored := make([]Result, 0, len(resp.Results))
+	seen := make([]bool, len(results))
+	for _, rr := range resp.Results {
+		if rr.Index < 0 || rr.Index >= len(results) {
+			return nil, fmt.Errorf("reranker returned unknown candidate index %d", rr.Index)
+		}
+		if seen[rr.Index] {
+			return nil, fmt.Errorf("reranker returned candidate index %d twice", rr.Index)
+		}
+		seen[rr.Index] = true
+		res := results[rr.Index]
+		res.Score = rr.Score
+		scored = append(scored, res)
+	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+	for i, res := range results {
+		if !seen[i] {
+			scored = append(scored, res)
+		}
+	}
+	return scored, nil
+}
+
+// RerankResults applies r to results. A nil reranker returns results
+// unchanged; a failing one keeps the original order and returns a warning
+// for the caller to surface alongside other degraded-mode diagnostics.
+func RerankResults(ctx context.Context, r Reranker, query string, results []Result) ([]Result, string) {
+	if r == nil {
+		return results, ""
+	}
+	if err := ctx.Err(); err != nil {
+		return results, fmt.Sprintf("reranking skipped, kept original order: %v", err)
+	}
+	reranked, err := r.Rerank(ctx, query, results)
+	if err != nil {
+		return results, fmt.Sprintf("reranker unavailable, kept original order: %v", err)
+	}
+	return reranked, ""
+}
@@ -0,0 +1,62 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPRerankerReordersByServiceScores(t *testing.T) {
+	var got rerankRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		// Promote the last candidate, keep the first, omit the middle one.
+		_, _ = w.Write([]byte(`{"results":[{"index":0,"score":0.4},{"index":2,"score":0.9}]}`))
+	}))
+	defer server.Close()
+
+	results := []Result{
+		{RelativePath: "a.go", Content: "a", Score: 0.8},
+		{RelativePath: "b.go", Content: "b", Score: 0.7},
+		{RelativePath: "c.go", Content: "c", Score: 0.6},
+	}
+	reranked, warning := RerankResults(context.Background(), NewHTTPReranker(server.URL, 0), "query", results)
+	if warning != "" {
+		t.Fatalf("unexpected warning: %s", warning)
+	}
+	if got.Query != "query" || len(got.Candidates) != 3 || got.Candidates[1].File != "b.go" {
+		t.Fatalf("request = %+v", got)
+	}
+	order := []string{reranked[0].RelativePath, reranked[1].RelativePath, reranked[2].RelativePath}
+	if strings.Join(order, ",") != "c.go,a.go,b.go" {
+		t.Fatalf("order = %v, want c.go,a.go,b.go", order)
+	}
+	if reranked[0].Score != 0.9 || reranked[2].Score != 0.7 {
+		t.Fatalf("scores = %v, %v", reranked[0].Score, reranked[2].Score)
+	}
+}
+
+func TestHTTPRerankerFailureKeepsOriginalOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"results":[{"index":7,"score":1}]}`))
+	}))
+	defer server.Close()
+
+	results := []Result{{RelativePath: "a.go"}, {RelativePath: "b.go"}}
+	kept, warning := RerankResults(context.Background(), NewHTTPReranker(server.URL, 0), "q", results)
+	if !strings.Contains(warning, "unknown candidate index 7") {
+		t.Fatalf("warning = %q", warning)
+	}
+	if kept[0].RelativePath != "a.go" || kept[1].RelativePath != "b.go" {
+		t.Fatalf("order changed on failure: %+v", kept)
+	}
+
+	if NewHTTPReranker("  ", 0) != nil {
+		t.Fatal("empty URL should disable reranking")
+	}
+}
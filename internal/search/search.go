@@ -4,7 +4,9 @@ package search
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
 	"github.com/abdul-hamid-achik/vecgrep/internal/db"
@@ -44,8 +46,12 @@ type Result struct {
 	// similarity. Hybrid mode: calibrated weighted fusion of cosine similarity
 	// and normalized BM25 (see db.VecLiteBackend.HybridSearch). Keyword mode:
 	// BM25 normalized to 0-1 within the result set (top hit = 1.0); Distance
-	// keeps the raw BM25 value.
-	Score float32 `json:"score"`
+	// keeps the raw BM25 value. Expanded searches: Reciprocal Rank Fusion
+	// across the query variants, rescaled so a hit ranked first by every
+	// variant scores 1.0. When a score calibration is stored (`vecgrep
+	// calibrate`), Score is the calibrated value and RawScore the above.
+	Score    float32 `json:"score"`
+	RawScore float32 `json:"raw_score,omitempty"`
 
 	// StructuralScore is codemap's normalized fan-in hub score (0..1) when
 	// structural reranking ran; 0 when codemap was unavailable. Exposed so
@@ -54,6 +60,30 @@ type Result struct {
 	// Reranked marks that this result's position was influenced by
 	// codemap structural blending, not pure semantic similarity.
 	Reranked bool `json:"reranked,omitempty"`
+
+	// Annotation marks a user-written note attached to this location rather
+	// than indexed code; Content holds the note text.
+	Annotation bool `json:"annotation,omitempty"`
+
+	// Project names the registered project a result came from in a
+	// cross-project search; empty for single-project searches.
+	Project string `json:"project,omitempty"`
+
+	// Root names the extra project root (indexing.extra_roots) a result came
+	// from; empty for results under the project root itself. RelativePath is
+	// relative to that root.
+	Root string `json:"root,omitempty"`
+
+	// Ref is the git ref a result was indexed from with `vecgrep index
+	// --ref`; empty for the working tree.
+	Ref string `json:"ref,omitempty"`
+
+	// Tags are the indexing.tags labels of the result's file.
+	Tags []string `json:"tags,omitempty"`
+
+	// Feedback is "relevant" or "irrelevant" when `vecgrep feedback`
+	// judgments for this query moved the result's score.
+	Feedback string `json:"feedback,omitempty"`
 }
 
 // SearchOptions configures search behavior.
@@ -65,17 +95,43 @@ type SearchOptions struct {
 	ChunkTypes  []string // Filter by multiple chunk types (OR)
 	FilePattern string   // Filter by file path pattern (glob)
 	Directory   string   // Filter by directory prefix
+	Directories []string // Filter by any of several directory prefixes (OR)
 	FilePaths   []string // Filter by an allow-list of relative paths (blast-radius scoping)
 	MinLine     int      // Filter by minimum start line
 	MaxLine     int      // Filter by maximum start line
 	MinScore    float32  // Minimum similarity score (0-1)
 	ProjectRoot string   // Project root for relative path filtering
+	// ProjectRoots, when set, replaces ProjectRoot with any of several roots
+	// (a project plus its extra roots).
+	ProjectRoots []string
+	// Symbol keeps only chunks whose symbol name matches, ignoring case; a
+	// glob such as "New*" is allowed.
+	Symbol string
+	// Tags keeps only chunks labeled with any of these indexing.tags tags.
+	Tags []string
+	// EfSearch overrides the HNSW candidate list size for this search (0 =
+	// vector.veclite.ef_search); higher raises recall at some latency.
+	EfSearch int
 
 	// Search mode and hybrid settings
 	Mode         SearchMode // Search mode: semantic, keyword, or hybrid
 	VectorWeight float32    // Weight for vector similarity in hybrid mode (0-1)
 	TextWeight   float32    // Weight for text matching in hybrid mode (0-1)
+	Fusion       string     // Hybrid fusion: "weighted" (default) or "rrf"
 	Explain      bool       // Return search explanation for debugging
+	// AllowPartial makes a search whose context deadline passes before the
+	// query is embedded return keyword-only results marked Partial instead
+	// of failing. Time-bounded interactive searches set it.
+	AllowPartial bool
+	// Expand also searches rewrites of the query from Expander (synonym
+	// templates when nil) and fuses all rankings with Reciprocal Rank
+	// Fusion. MinScore then applies to each variant's own scores, and
+	// Result.Score is the fused score.
+	Expand   bool
+	Expander QueryExpander
+	// Calibration, when set, maps scores onto a scale shared by all modes
+	// before MinScore applies; see ScoreCalibration.
+	Calibration *ScoreCalibration
 }
 
 // SimilarOptions configures similar code search behavior.
@@ -123,6 +179,10 @@ type SearchOutcome struct {
 	// Mode is the search mode actually executed, which may differ from the
 	// requested mode when hybrid search degraded to keyword-only.
 	Mode SearchMode
+	// Partial reports that the search deadline passed before the requested
+	// mode could run, so Results are the best found in time rather than the
+	// full answer. Only set when SearchOptions.AllowPartial is.
+	Partial bool
 }
 
 // Search performs a search for the given query using the specified mode.
@@ -149,6 +209,7 @@ func (s *Searcher) searchOutcome(ctx context.Context, query string, opts SearchO
 	if query == "" {
 		return nil, fmt.Errorf("query cannot be empty")
 	}
+	query, opts = ApplyInlineQuery(query, opts)
 
 	if opts.Limit == 0 {
 		opts.Limit = DefaultSearchOptions().Limit
@@ -161,19 +222,27 @@ func (s *Searcher) searchOutcome(ctx context.Context, query string, opts SearchO
 	if opts.VectorWeight == 0 {
 		opts.VectorWeight = DefaultSearchOptions().VectorWeight
 	}
+	if opts.Expand {
+		return s.expandedOutcome(ctx, query, opts, degradeOnEmbedError)
+	}
 
 	// Build filter options with extended fields
 	filterOpts := db.FilterOptions{
-		Language:    opts.Language,
-		Languages:   opts.Languages,
-		ChunkType:   opts.ChunkType,
-		ChunkTypes:  opts.ChunkTypes,
-		FilePattern: opts.FilePattern,
-		Directory:   opts.Directory,
-		FilePaths:   opts.FilePaths,
-		MinLine:     opts.MinLine,
-		MaxLine:     opts.MaxLine,
-		ProjectRoot: opts.ProjectRoot,
+		Language:     opts.Language,
+		Languages:    opts.Languages,
+		ChunkType:    opts.ChunkType,
+		ChunkTypes:   opts.ChunkTypes,
+		FilePattern:  opts.FilePattern,
+		Directory:    opts.Directory,
+		Directories:  opts.Directories,
+		FilePaths:    opts.FilePaths,
+		MinLine:      opts.MinLine,
+		MaxLine:      opts.MaxLine,
+		ProjectRoot:  opts.ProjectRoot,
+		ProjectRoots: opts.ProjectRoots,
+		Symbol:       opts.Symbol,
+		Tags:         opts.Tags,
+		EfSearch:     opts.EfSearch,
 	}
 
 	outcome := &SearchOutcome{Mode: opts.Mode}
@@ -193,7 +262,13 @@ func (s *Searcher) searchOutcome(ctx context.Context, query string, opts SearchO
 		// Pure vector search
 		queryEmbedding, embedErr := embedQuery(ctx, s.provider, query)
 		if embedErr != nil {
-			return nil, fmt.Errorf("embed query: %w", embedErr)
+			if !partialAllowed(ctx, opts) {
+				return nil, fmt.Errorf("embed query: %w", embedErr)
+			}
+			return s.partialOutcome(query, opts, filterOpts)
+		}
+		if err := s.checkQueryEmbedding(queryEmbedding); err != nil {
+			return nil, err
 		}
 		searchResults, err = s.db.SearchWithFilter(queryEmbedding, opts.Limit, filterOpts)
 		if err != nil {
@@ -206,6 +281,9 @@ func (s *Searcher) searchOutcome(ctx context.Context, query string, opts SearchO
 		// Hybrid search: combine vector + text
 		queryEmbedding, embedErr := embedQuery(ctx, s.provider, query)
 		if embedErr != nil {
+			if partialAllowed(ctx, opts) {
+				return s.partialOutcome(query, opts, filterOpts)
+			}
 			if !degradeOnEmbedError {
 				return nil, fmt.Errorf("embed query: %w", embedErr)
 			}
@@ -220,19 +298,44 @@ func (s *Searcher) searchOutcome(ctx context.Context, query string, opts SearchO
 			outcome.Warnings = append(outcome.Warnings, fmt.Sprintf(
 				"embedding provider unavailable at query time (%v): results are keyword-only (BM25 normalized to 0-1 within this result set; top hit = 1.0); semantic ranking was skipped", embedErr))
 		} else {
-			searchResults, err = s.db.HybridSearch(queryEmbedding, query, opts.Limit, filterOpts, opts.VectorWeight, opts.TextWeight)
+			if err := s.checkQueryEmbedding(queryEmbedding); err != nil {
+				return nil, err
+			}
+			searchResults, err = s.db.HybridSearchWithFusion(queryEmbedding, query, opts.Limit, filterOpts, opts.VectorWeight, opts.TextWeight, opts.Fusion)
 			if err != nil {
 				return nil, fmt.Errorf("hybrid search: %w", err)
 			}
 		}
 	}
 
-	outcome.Results = convertOutcomeResults(searchResults, outcome.Mode, opts.MinScore, opts.Limit)
+	outcome.Results = convertOutcomeResults(searchResults, outcome.Mode, opts)
 	return outcome, nil
 }
 
+// partialAllowed reports whether a failed query embedding should yield
+// partial results: the caller opted in and the failure is its deadline.
+func partialAllowed(ctx context.Context, opts SearchOptions) bool {
+	return opts.AllowPartial && errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// partialOutcome answers a search that ran out of time before its query was
+// embedded with the keyword results, which need no provider round-trip.
+func (s *Searcher) partialOutcome(query string, opts SearchOptions, filterOpts db.FilterOptions) (*SearchOutcome, error) {
+	searchResults, err := s.db.TextSearch(query, opts.Limit, filterOpts)
+	if err != nil {
+		return nil, fmt.Errorf("search deadline exceeded and keyword fallback failed: %w", err)
+	}
+	return &SearchOutcome{
+		Results:  convertOutcomeResults(searchResults, SearchModeKeyword, opts),
+		Warnings: []string{"search deadline reached before the query was embedded: partial results are keyword-only"},
+		Mode:     SearchModeKeyword,
+		Partial:  true,
+	}, nil
+}
+
 // convertOutcomeResults converts raw backend results to Results, applying
-// keyword-mode score normalization, the MinScore filter, and the limit.
+// keyword-mode score normalization, calibration, the MinScore filter, and the
+// limit.
 //
 // Raw BM25 scores are unbounded and only comparable within one result set, so
 // keyword mode (including hybrid searches that degraded to keyword-only)
@@ -240,7 +343,8 @@ func (s *Searcher) searchOutcome(ctx context.Context, query string, opts SearchO
 // mirroring the bm25/maxBM25 normalization hybrid fusion applies in
 // db.fuseWeightedScores. This makes MinScore meaningful for keyword results;
 // Distance keeps the raw BM25 value.
-func convertOutcomeResults(searchResults []db.SearchResult, mode SearchMode, minScore float32, limit int) []Result {
+func convertOutcomeResults(searchResults []db.SearchResult, mode SearchMode, opts SearchOptions) []Result {
+	key := CalibrationKey(mode, opts.Fusion)
 	var maxBM25 float32
 	if mode == SearchModeKeyword {
 		for _, sr := range searchResults {
@@ -257,15 +361,19 @@ func convertOutcomeResults(searchResults []db.SearchResult, mode SearchMode, min
 		if mode == SearchModeKeyword && maxBM25 > 0 {
 			result.Score /= maxBM25
 		}
+		if opts.Calibration.Has(key) {
+			result.RawScore = result.Score
+			result.Score = opts.Calibration.Calibrate(key, result.Score)
+		}
 
 		// Apply minimum score filter
-		if minScore > 0 && result.Score < minScore {
+		if opts.MinScore > 0 && result.Score < opts.MinScore {
 			continue
 		}
 
 		results = append(results, result)
 
-		if len(results) >= limit {
+		if len(results) >= opts.Limit {
 			break
 		}
 	}
@@ -278,6 +386,7 @@ func (s *Searcher) SearchWithExplain(ctx context.Context, query string, opts Sea
 	if query == "" {
 		return nil, nil, fmt.Errorf("query cannot be empty")
 	}
+	query, opts = ApplyInlineQuery(query, opts)
 
 	if opts.Limit == 0 {
 		opts.Limit = DefaultSearchOptions().Limit
@@ -288,19 +397,27 @@ func (s *Searcher) SearchWithExplain(ctx context.Context, query string, opts Sea
 	if err != nil {
 		return nil, nil, fmt.Errorf("embed query: %w", err)
 	}
+	if err := s.checkQueryEmbedding(queryEmbedding); err != nil {
+		return nil, nil, err
+	}
 
 	// Build filter options
 	filterOpts := db.FilterOptions{
-		Language:    opts.Language,
-		Languages:   opts.Languages,
-		ChunkType:   opts.ChunkType,
-		ChunkTypes:  opts.ChunkTypes,
-		FilePattern: opts.FilePattern,
-		Directory:   opts.Directory,
-		FilePaths:   opts.FilePaths,
-		MinLine:     opts.MinLine,
-		MaxLine:     opts.MaxLine,
-		ProjectRoot: opts.ProjectRoot,
+		Language:     opts.Language,
+		Languages:    opts.Languages,
+		ChunkType:    opts.ChunkType,
+		ChunkTypes:   opts.ChunkTypes,
+		FilePattern:  opts.FilePattern,
+		Directory:    opts.Directory,
+		Directories:  opts.Directories,
+		FilePaths:    opts.FilePaths,
+		MinLine:      opts.MinLine,
+		MaxLine:      opts.MaxLine,
+		ProjectRoot:  opts.ProjectRoot,
+		ProjectRoots: opts.ProjectRoots,
+		Symbol:       opts.Symbol,
+		Tags:         opts.Tags,
+		EfSearch:     opts.EfSearch,
 	}
 
 	// Get results with explanation
@@ -309,10 +426,15 @@ func (s *Searcher) SearchWithExplain(ctx context.Context, query string, opts Sea
 		return nil, nil, fmt.Errorf("search with explain: %w", err)
 	}
 
-	// Convert to Result format
+	// Convert to Result format; explained searches are vector-only.
+	key := CalibrationKey(SearchModeSemantic, "")
 	results := make([]Result, 0, len(searchResults))
 	for _, sr := range searchResults {
 		result := searchResultToResult(sr)
+		if opts.Calibration.Has(key) {
+			result.RawScore = result.Score
+			result.Score = opts.Calibration.Calibrate(key, result.Score)
+		}
 
 		if opts.MinScore > 0 && result.Score < opts.MinScore {
 			continue
@@ -350,22 +472,31 @@ func (s *Searcher) SearchSimilarByID(ctx context.Context, chunkID int64, opts Si
 
 	// Build filter options with extended fields
 	filterOpts := db.FilterOptions{
-		Language:    opts.Language,
-		Languages:   opts.Languages,
-		ChunkType:   opts.ChunkType,
-		ChunkTypes:  opts.ChunkTypes,
-		FilePattern: opts.FilePattern,
-		Directory:   opts.Directory,
-		FilePaths:   opts.FilePaths,
-		MinLine:     opts.MinLine,
-		MaxLine:     opts.MaxLine,
-		ProjectRoot: opts.ProjectRoot,
-	}
-
-	// Request more results to account for filtering
-	searchLimit := max(opts.Limit*3, 50)
-
-	searchResults, err := s.db.SearchWithFilter(embedding, searchLimit, filterOpts)
+		Language:     opts.Language,
+		Languages:    opts.Languages,
+		ChunkType:    opts.ChunkType,
+		ChunkTypes:   opts.ChunkTypes,
+		FilePattern:  opts.FilePattern,
+		Directory:    opts.Directory,
+		Directories:  opts.Directories,
+		FilePaths:    opts.FilePaths,
+		MinLine:      opts.MinLine,
+		MaxLine:      opts.MaxLine,
+		ProjectRoot:  opts.ProjectRoot,
+		ProjectRoots: opts.ProjectRoots,
+		Symbol:       opts.Symbol,
+		Tags:         opts.Tags,
+	}
+	// The backend grows its candidate pool until the exclusions leave
+	// opts.Limit results.
+	if opts.ExcludeSourceID {
+		filterOpts.ExcludeIDs = []int64{chunkID}
+	}
+	if opts.ExcludeSameFile && opts.SourceFilePath != "" {
+		filterOpts.ExcludePaths = []string{opts.SourceFilePath}
+	}
+
+	searchResults, err := s.db.SearchWithFilter(embedding, opts.Limit, filterOpts)
 	if err != nil {
 		return nil, fmt.Errorf("search embeddings: %w", err)
 	}
@@ -373,18 +504,8 @@ func (s *Searcher) SearchSimilarByID(ctx context.Context, chunkID int64, opts Si
 	// Convert to Result format
 	results := make([]Result, 0, len(searchResults))
 	for _, sr := range searchResults {
-		// Exclude source chunk by default
-		if opts.ExcludeSourceID && sr.ChunkID == chunkID {
-			continue
-		}
-
 		result := searchResultToResult(sr)
 
-		// Exclude same file if requested
-		if opts.ExcludeSameFile && result.RelativePath == opts.SourceFilePath {
-			continue
-		}
-
 		// Apply minimum score filter
 		if opts.MinScore > 0 && result.Score < opts.MinScore {
 			continue
@@ -431,19 +552,26 @@ func (s *Searcher) SearchSimilarByText(ctx context.Context, text string, opts Si
 	if err != nil {
 		return nil, fmt.Errorf("embed text: %w", err)
 	}
+	if err := s.checkQueryEmbedding(embedding); err != nil {
+		return nil, err
+	}
 
 	// Build filter options with extended fields
 	filterOpts := db.FilterOptions{
-		Language:    opts.Language,
-		Languages:   opts.Languages,
-		ChunkType:   opts.ChunkType,
-		ChunkTypes:  opts.ChunkTypes,
-		FilePattern: opts.FilePattern,
-		Directory:   opts.Directory,
-		FilePaths:   opts.FilePaths,
-		MinLine:     opts.MinLine,
-		MaxLine:     opts.MaxLine,
-		ProjectRoot: opts.ProjectRoot,
+		Language:     opts.Language,
+		Languages:    opts.Languages,
+		ChunkType:    opts.ChunkType,
+		ChunkTypes:   opts.ChunkTypes,
+		FilePattern:  opts.FilePattern,
+		Directory:    opts.Directory,
+		Directories:  opts.Directories,
+		FilePaths:    opts.FilePaths,
+		MinLine:      opts.MinLine,
+		MaxLine:      opts.MaxLine,
+		ProjectRoot:  opts.ProjectRoot,
+		ProjectRoots: opts.ProjectRoots,
+		Symbol:       opts.Symbol,
+		Tags:         opts.Tags,
 	}
 
 	searchResults, err := s.db.SearchWithFilter(embedding, opts.Limit, filterOpts)
@@ -496,11 +624,29 @@ func searchResultToResult(sr db.SearchResult) Result {
 		result.ChunkType = sr.Chunk.ChunkType
 		result.SymbolName = sr.Chunk.SymbolName
 		result.Language = sr.Chunk.Language
+		result.Ref = sr.Chunk.Ref
+		result.Tags = sr.Chunk.Tags
 	}
 
 	return result
 }
 
+// LabelRoots sets Root on each result whose file lies under one of the root
+// directories in labels, which maps an absolute root path to its name.
+func LabelRoots(results []Result, labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	for i := range results {
+		for root, name := range labels {
+			if strings.HasPrefix(results[i].FilePath, root+string(filepath.Separator)) {
+				results[i].Root = name
+				break
+			}
+		}
+	}
+}
+
 // OutputFormat specifies the output format for search results.
 type OutputFormat string
 
@@ -508,15 +654,24 @@ const (
 	FormatDefault OutputFormat = "default"
 	FormatJSON    OutputFormat = "json"
 	FormatCompact OutputFormat = "compact"
+	// FormatCitations lists path:Lstart-Lend locations without snippets.
+	FormatCitations OutputFormat = "citations"
+	// FormatTemplate renders each result with a user template; see
+	// ParseResultTemplate and FormatResultsTemplate.
+	FormatTemplate OutputFormat = "template"
 )
 
 // FormatResults formats search results according to the specified format.
+// FormatTemplate needs the template itself, so callers render it with
+// FormatResultsTemplate; here it falls back to the default format.
 func FormatResults(results []Result, format OutputFormat) string {
 	switch format {
 	case FormatJSON:
 		return formatJSON(results)
 	case FormatCompact:
 		return formatCompact(results)
+	case FormatCitations:
+		return formatCitations(results)
 	default:
 		return formatDefault(results)
 	}
@@ -531,7 +686,23 @@ func formatDefault(results []Result) string {
 	var sb strings.Builder
 
 	for i, r := range results {
+		if r.Annotation {
+			fmt.Fprintf(&sb, "=== Annotation (score: %.2f) ===\n", r.Score)
+			fmt.Fprintf(&sb, "File: %s\n", r.RelativePath)
+			fmt.Fprintf(&sb, "Lines: %d-%d\n\n", r.StartLine, r.EndLine)
+			fmt.Fprintf(&sb, "  # %s\n\n", strings.ReplaceAll(r.Content, "\n", "\n  # "))
+			continue
+		}
 		fmt.Fprintf(&sb, "=== Result %d (score: %.2f) ===\n", i+1, r.Score)
+		if r.Project != "" {
+			fmt.Fprintf(&sb, "Project: %s\n", r.Project)
+		}
+		if r.Root != "" {
+			fmt.Fprintf(&sb, "Root: %s\n", r.Root)
+		}
+		if r.Ref != "" {
+			fmt.Fprintf(&sb, "Ref: %s\n", r.Ref)
+		}
 		fmt.Fprintf(&sb, "File: %s\n", r.RelativePath)
 		fmt.Fprintf(&sb, "Lines: %d-%d", r.StartLine, r.EndLine)
 
@@ -544,6 +715,12 @@ func formatDefault(results []Result) string {
 		if r.Language != "" && r.Language != "unknown" {
 			fmt.Fprintf(&sb, " | Lang: %s", r.Language)
 		}
+		if len(r.Tags) > 0 {
+			fmt.Fprintf(&sb, " | Tags: %s", strings.Join(r.Tags, ","))
+		}
+		if r.Feedback != "" {
+			fmt.Fprintf(&sb, " | Feedback: %s", r.Feedback)
+		}
 		sb.WriteString("\n\n")
 
 		// Indent content
@@ -577,11 +754,20 @@ func formatCompact(results []Result) string {
 	var sb strings.Builder
 
 	for _, r := range results {
-		// Format: file:startLine-endLine score symbol
+		// Format: [project] [root:]file:startLine-endLine score symbol
+		if r.Project != "" {
+			fmt.Fprintf(&sb, "%s\t", r.Project)
+		}
+		if r.Root != "" {
+			fmt.Fprintf(&sb, "%s:", r.Root)
+		}
 		fmt.Fprintf(&sb, "%s:%d-%d\t%.2f", r.RelativePath, r.StartLine, r.EndLine, r.Score)
 		if r.SymbolName != "" {
 			fmt.Fprintf(&sb, "\t%s", r.SymbolName)
 		}
+		if r.Annotation {
+			sb.WriteString("\tannotation")
+		}
 		sb.WriteString("\n")
 	}
 
@@ -607,6 +793,8 @@ func (s *Searcher) GetIndexStats(ctx context.Context) (map[string]interface{}, e
 
 	// Language distribution
 	stats["languages"] = detailedStats.Languages
+	stats["language_lines"] = detailedStats.LanguageLines
+	stats["language_bytes"] = detailedStats.LanguageBytes
 
 	// Chunk type distribution
 	stats["chunk_types"] = detailedStats.ChunkTypes
@@ -659,9 +847,12 @@ const (
 
 // ChunkTypeFilter constants.
 const (
-	ChunkTypeFunction = string(index.ChunkTypeFunction)
-	ChunkTypeClass    = string(index.ChunkTypeClass)
-	ChunkTypeBlock    = string(index.ChunkTypeBlock)
-	ChunkTypeComment  = string(index.ChunkTypeComment)
-	ChunkTypeGeneric  = string(index.ChunkTypeGeneric)
+	ChunkTypeFunction  = string(index.ChunkTypeFunction)
+	ChunkTypeClass     = string(index.ChunkTypeClass)
+	ChunkTypeInterface = string(index.ChunkTypeInterface)
+	ChunkTypeConst     = string(index.ChunkTypeConst)
+	ChunkTypeConfig    = string(index.ChunkTypeConfig)
+	ChunkTypeBlock     = string(index.ChunkTypeBlock)
+	ChunkTypeComment   = string(index.ChunkTypeComment)
+	ChunkTypeGeneric   = string(index.ChunkTypeGeneric)
 )
@@ -2,6 +2,7 @@ package search
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -412,3 +413,38 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func TestNewOpenAISearchPage(t *testing.T) {
+	page := NewOpenAISearchPage("retry backoff", []Result{{
+		RelativePath: "net/retry.go",
+		Content:      "func Backoff() {}",
+		StartLine:    10,
+		EndLine:      12,
+		ChunkType:    "function",
+		SymbolName:   "Backoff",
+		Language:     "go",
+		Score:        0.82,
+	}})
+	data, err := json.Marshal(page)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded["object"] != "vector_store.search_results.page" || decoded["search_query"] != "retry backoff" || decoded["has_more"] != false {
+		t.Fatalf("page = %s", data)
+	}
+	if _, ok := decoded["next_page"]; !ok {
+		t.Fatal("next_page must be present (null) for OpenAI clients")
+	}
+	item := page.Data[0]
+	if item.FileID != "net/retry.go" || item.Filename != "retry.go" || item.Content[0].Type != "text" || item.Attributes["symbol"] != "Backoff" {
+		t.Fatalf("item = %+v", item)
+	}
+
+	if empty := NewOpenAISearchPage("q", nil); empty.Data == nil {
+		t.Fatal("data must be an empty array, not null")
+	}
+}
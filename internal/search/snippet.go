@@ -0,0 +1,132 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// TruncateSnippet shortens content to at most maxLines lines for display.
+// The first non-blank line (usually the symbol signature) is always kept,
+// and the remaining budget goes to the window of lines with the most query
+// term matches, falling back to the lines right after the signature. Elided
+// spans are replaced by a "... (N lines)" marker, which counts toward the
+// budget. maxLines <= 0 or content already within budget is returned as-is.
+func TruncateSnippet(content, query string, maxLines int) string {
+	if maxLines <= 0 {
+		return content
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) <= maxLines {
+		return content
+	}
+
+	sig := 0
+	for sig < len(lines)-1 && strings.TrimSpace(lines[sig]) == "" {
+		sig++
+	}
+	switch maxLines {
+	case 1:
+		return lines[sig]
+	case 2:
+		return lines[sig] + "\n" + omittedMarker(len(lines)-sig-1)
+	}
+
+	// Budget after the signature line, reserving one line for a marker on
+	// each side of the window.
+	window := maxLines - 3
+	start := bestSnippetWindow(lines[sig+1:], query, window) + sig + 1
+	if start == sig+1 {
+		// Window touches the signature: no leading marker, one more line.
+		window++
+	}
+	end := min(start+window, len(lines))
+	if end == len(lines) && start > sig+1 {
+		// Window reaches the end: no trailing marker, one more line.
+		start--
+	}
+
+	out := make([]string, 0, maxLines)
+	out = append(out, lines[sig])
+	if gap := start - sig - 1; gap > 0 {
+		out = append(out, omittedMarker(gap))
+	}
+	out = append(out, lines[start:end]...)
+	if gap := len(lines) - end; gap > 0 {
+		out = append(out, omittedMarker(gap))
+	}
+	return strings.Join(out, "\n")
+}
+
+// TruncateResults applies TruncateSnippet to each result's content.
+func TruncateResults(results []Result, query string, maxLines int) {
+	if maxLines <= 0 {
+		return
+	}
+	for i := range results {
+		if !results[i].Annotation {
+			results[i].Content = TruncateSnippet(results[i].Content, query, maxLines)
+		}
+	}
+}
+
+func omittedMarker(n int) string {
+	if n == 1 {
+		return "... (1 line)"
+	}
+	return fmt.Sprintf("... (%d lines)", n)
+}
+
+// bestSnippetWindow returns the offset of the size-line window of lines with
+// the most query-term matches, centred within the run of equally good
+// windows so the matching lines get context on both sides. It returns 0 when
+// no line matches.
+func bestSnippetWindow(lines []string, query string, size int) int {
+	terms := snippetTerms(query)
+	if len(terms) == 0 || size == 0 || len(lines) <= size {
+		return 0
+	}
+	hits := make([]int, len(lines))
+	for i, line := range lines {
+		lower := strings.ToLower(line)
+		for _, term := range terms {
+			if strings.Contains(lower, term) {
+				hits[i]++
+			}
+		}
+	}
+	sums := make([]int, len(lines)-size+1)
+	for i := 0; i < size; i++ {
+		sums[0] += hits[i]
+	}
+	best := 0
+	for i := 1; i < len(sums); i++ {
+		sums[i] = sums[i-1] + hits[i+size-1] - hits[i-1]
+		if sums[i] > sums[best] {
+			best = i
+		}
+	}
+	if sums[best] == 0 {
+		return 0
+	}
+	last := best
+	for last+1 < len(sums) && sums[last+1] == sums[best] {
+		last++
+	}
+	return (best + last) / 2
+}
+
+// snippetTerms splits a query into lowercase terms of at least three
+// characters, so stop-word-sized fragments do not steer the window.
+func snippetTerms(query string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	terms := fields[:0]
+	for _, f := range fields {
+		if len(f) >= 3 {
+			terms = append(terms, f)
+		}
+	}
+	return terms
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func snippetFixture() string {
+	lines := []string{"func Retry(ctx context.Context) error {"}
+	for i := 1; i <= 30; i++ {
+		lines = append(lines, fmt.Sprintf("\tstep%d()", i))
+	}
+	lines[20] = "\tdelay := nextBackoff(attempt)"
+	return strings.Join(append(lines, "}"), "\n")
+}
+
+func TestTruncateSnippetKeepsSignatureAndRelevantRegion(t *testing.T) {
+	content := snippetFixture()
+	for _, maxLines := range []int{1, 2, 3, 4, 8, 31} {
+		got := TruncateSnippet(content, "retry backoff", maxLines)
+		lines := strings.Split(got, "\n")
+		if len(lines) > maxLines {
+			t.Fatalf("maxLines=%d produced %d lines:\n%s", maxLines, len(lines), got)
+		}
+		if lines[0] != "func Retry(ctx context.Context) error {" {
+			t.Fatalf("maxLines=%d dropped the signature:\n%s", maxLines, got)
+		}
+		if maxLines >= 4 && !strings.Contains(got, "nextBackoff") {
+			t.Fatalf("maxLines=%d dropped the matching line:\n%s", maxLines, got)
+		}
+	}
+
+	got := TruncateSnippet(content, "retry backoff", 8)
+	if !strings.Contains(got, "... (17 lines)") || !strings.Contains(got, "\tstep19()\n\tdelay") || !strings.Contains(got, "\tstep21()") {
+		t.Fatalf("window should be centred on the match:\n%s", got)
+	}
+
+	// Without a match the head of the chunk is kept.
+	got = TruncateSnippet(content, "unrelated", 5)
+	if !strings.HasPrefix(got, "func Retry(ctx context.Context) error {\n\tstep1()") || !strings.HasSuffix(got, "... (28 lines)") {
+		t.Fatalf("no-match snippet:\n%s", got)
+	}
+}
+
+func TestTruncateSnippetLeavesShortContentAlone(t *testing.T) {
+	content := "a\nb\nc\n"
+	if got := TruncateSnippet(content, "b", 3); got != content {
+		t.Fatalf("got %q", got)
+	}
+	if got := TruncateSnippet(snippetFixture(), "q", 0); got != snippetFixture() {
+		t.Fatal("maxLines=0 must not truncate")
+	}
+}
@@ -0,0 +1,203 @@
+package search
+
+import (
+	"context"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/abdul-hamid-achik/vecgrep/internal/db"
+)
+
+// Suggestion kinds.
+const (
+	SuggestionSymbol = "symbol"
+	SuggestionFile   = "file"
+)
+
+// DefaultSuggestLimit and MaxSuggestLimit bound Suggest results.
+const (
+	DefaultSuggestLimit = 10
+	MaxSuggestLimit     = 50
+)
+
+// Suggestion is one typeahead completion: an indexed symbol name or file
+// path that matches what the user has typed so far.
+type Suggestion struct {
+	Kind         string  `json:"kind"` // SuggestionSymbol or SuggestionFile
+	Text         string  `json:"text"`
+	RelativePath string  `json:"relative_path"`
+	StartLine    int     `json:"start_line,omitempty"`
+	ChunkType    string  `json:"chunk_type,omitempty"`
+	Language     string  `json:"language,omitempty"`
+	Score        float64 `json:"score"`
+}
+
+// Suggest returns up to limit symbols and files of projectRoot whose names
+// match query as a prefix, a word-part prefix, a substring, or a fuzzy
+// subsequence, best first. It reads only the index and never embeds, so it
+// is cheap enough to run on every keystroke; a submitted query still goes
+// through Search.
+func (s *Searcher) Suggest(ctx context.Context, query, projectRoot string, limit int) ([]Suggestion, error) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	// Glob characters would change the index pre-filter below.
+	query = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`*?[]\`, r) || unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, query)
+	if query == "" {
+		return []Suggestion{}, nil
+	}
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+	limit = min(limit, MaxSuggestLimit)
+
+	// "*a*b*c*" keeps exactly the symbols that contain the query as a
+	// subsequence, so only plausible candidates leave the index.
+	pattern := "*" + strings.Join(strings.Split(query, ""), "*") + "*"
+	symbols, err := s.db.FindSymbols(pattern, 0, db.FilterOptions{ProjectRoot: projectRoot})
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	files, err := s.db.ListFiles(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Suggestion, 0, len(symbols)+len(files))
+	seen := make(map[string]bool, len(symbols))
+	for _, sym := range symbols {
+		key := sym.Name + "\x00" + sym.RelativePath
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		candidates = append(candidates, Suggestion{
+			Kind:         SuggestionSymbol,
+			Text:         sym.Name,
+			RelativePath: sym.RelativePath,
+			StartLine:    sym.StartLine,
+			ChunkType:    sym.ChunkType,
+			Language:     sym.Language,
+		})
+	}
+	for _, f := range files {
+		candidates = append(candidates, Suggestion{
+			Kind:         SuggestionFile,
+			Text:         f.RelativePath,
+			RelativePath: f.RelativePath,
+			Language:     f.Language,
+		})
+	}
+	return RankSuggestions(query, candidates, limit), nil
+}
+
+// RankSuggestions scores candidates against query with suggestScore, drops
+// those that do not match, and returns the best limit. Ties prefer shorter
+// text, then symbols over files, then alphabetical order.
+func RankSuggestions(query string, candidates []Suggestion, limit int) []Suggestion {
+	query = strings.ToLower(query)
+	ranked := make([]Suggestion, 0, len(candidates))
+	for _, c := range candidates {
+		text := c.Text
+		if c.Kind == SuggestionFile {
+			// A file is typed by its name more often than its directory.
+			if score := suggestScore(query, path.Base(text)); score > 0 {
+				c.Score = score
+				ranked = append(ranked, c)
+				continue
+			}
+		}
+		if c.Score = suggestScore(query, text); c.Score > 0 {
+			if c.Kind == SuggestionFile {
+				c.Score *= 0.9
+			}
+			ranked = append(ranked, c)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Text) != len(b.Text) {
+			return len(a.Text) < len(b.Text)
+		}
+		if a.Kind != b.Kind {
+			return a.Kind == SuggestionSymbol
+		}
+		return a.Text < b.Text
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	return ranked
+}
+
+// suggestScore rates how well text completes the lower-case query, from 0
+// (no match) to 1 (exact): a prefix beats the prefix of a later word part
+// ("Cfg" in "loadCfg", "util" in "str_util"), which beats a substring, which
+// beats a fuzzy subsequence. Within a tier, covering more of text scores
+// higher.
+func suggestScore(query, text string) float64 {
+	lower := strings.ToLower(text)
+	coverage := float64(len(query)) / float64(max(len(lower), 1))
+	var tier float64
+	switch {
+	case lower == query:
+		return 1
+	case strings.HasPrefix(lower, query):
+		tier = 0.8
+	case wordPartPrefix(text, query):
+		tier = 0.6
+	case strings.Contains(lower, query):
+		tier = 0.4
+	default:
+		span := subsequenceSpan(lower, query)
+		if span == 0 {
+			return 0
+		}
+		tier = 0.2 * float64(len(query)) / float64(span)
+	}
+	return tier + 0.1*coverage
+}
+
+// wordPartPrefix reports whether query is a prefix of a word part of text
+// that starts after a separator or at a lower-to-upper case change.
+func wordPartPrefix(text, query string) bool {
+	runes := []rune(text)
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		boundary := strings.ContainsRune("._-/:", prev) || (unicode.IsLower(prev) && unicode.IsUpper(cur))
+		if boundary && strings.HasPrefix(strings.ToLower(string(runes[i:])), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// subsequenceSpan returns the length of the shortest prefix-anchored window
+// of text containing query's characters in order, found greedily, or 0 when
+// query is not a subsequence of text.
+func subsequenceSpan(text, query string) int {
+	start, qi := -1, 0
+	for i := 0; i < len(text) && qi < len(query); i++ {
+		if text[i] == query[qi] {
+			if start < 0 {
+				start = i
+			}
+			qi++
+			if qi == len(query) {
+				return i - start + 1
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package search
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRankSuggestions_OrdersByMatchQuality(t *testing.T) {
+	candidates := []Suggestion{
+		{Kind: SuggestionSymbol, Text: "parseConfigValue"},
+		{Kind: SuggestionSymbol, Text: "LoadConfig"},
+		{Kind: SuggestionSymbol, Text: "Config"},
+		{Kind: SuggestionSymbol, Text: "reconfigure"},
+		{Kind: SuggestionSymbol, Text: "CountFiles"},
+		{Kind: SuggestionSymbol, Text: "Unrelated"},
+		{Kind: SuggestionFile, Text: "internal/config/config.go"},
+	}
+
+	got := RankSuggestions("conf", candidates, 0)
+	want := []string{"Config", "internal/config/config.go", "LoadConfig", "parseConfigValue", "reconfigure", "CountFiles"}
+	if len(got) != len(want) {
+		t.Fatalf("RankSuggestions returned %d suggestions, want %d: %+v", len(got), len(want), got)
+	}
+	for i, text := range want {
+		if got[i].Text != text {
+			t.Errorf("suggestion %d = %q, want %q (all: %+v)", i, got[i].Text, text, got)
+		}
+	}
+}
+
+func TestRankSuggestions_FuzzyAndLimit(t *testing.T) {
+	candidates := []Suggestion{
+		{Kind: SuggestionSymbol, Text: "HandleError"},
+		{Kind: SuggestionSymbol, Text: "HashEntry"},
+		{Kind: SuggestionSymbol, Text: "Config"},
+	}
+
+	got := RankSuggestions("hde", candidates, 0)
+	if len(got) != 1 || got[0].Text != "HandleError" {
+		t.Fatalf("fuzzy match = %+v, want only HandleError", got)
+	}
+	if got := RankSuggestions("h", candidates, 1); len(got) != 1 {
+		t.Fatalf("limit 1 returned %d suggestions", len(got))
+	}
+}
+
+func TestSuggest_SymbolsAndFiles(t *testing.T) {
+	database := setupTestDB(t)
+	defer database.Close()
+	setupTestData(t, database)
+
+	searcher := NewSearcher(database, nil)
+	got, err := searcher.Suggest(context.Background(), "proc", "/tmp/test", 0)
+	if err != nil {
+		t.Fatalf("Suggest: %v", err)
+	}
+	if len(got) == 0 || got[0].Text != "ProcessData" || got[0].Kind != SuggestionSymbol {
+		t.Fatalf("Suggest(proc) = %+v, want ProcessData first", got)
+	}
+	if got[0].RelativePath != "main.go" || got[0].StartLine != 5 {
+		t.Errorf("ProcessData location = %s:%d, want main.go:5", got[0].RelativePath, got[0].StartLine)
+	}
+
+	got, err = searcher.Suggest(context.Background(), "main", "/tmp/test", 0)
+	if err != nil {
+		t.Fatalf("Suggest: %v", err)
+	}
+	if len(got) == 0 || got[0].Kind != SuggestionFile || got[0].Text != "main.go" {
+		t.Fatalf("Suggest(main) = %+v, want main.go file first", got)
+	}
+
+	got, err = searcher.Suggest(context.Background(), "  *  ", "/tmp/test", 0)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("Suggest of glob-only query = %+v, %v; want no suggestions", got, err)
+	}
+}
@@ -0,0 +1,59 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// resultTemplateFuncs are the functions result templates may call besides
+// text/template's builtins such as printf.
+var resultTemplateFuncs = template.FuncMap{
+	"join": strings.Join,
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"oneline": func(s string) string {
+		return strings.Join(strings.Fields(s), " ")
+	},
+}
+
+// ParseResultTemplate parses text as a Go text/template for the template
+// output format. The template runs once per result with the Result as its
+// data, e.g. '{{.RelativePath}}:{{.StartLine}} {{printf "%.2f" .Score}}'.
+// Besides the builtins it may call join (strings.Join), json, and oneline,
+// which collapses whitespace so Content fits on one line. The template is
+// run once against an empty result, so a misspelled field is reported here
+// rather than after a search.
+func ParseResultTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("result").Funcs(resultTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse result template: %w", err)
+	}
+	if err := tmpl.Execute(io.Discard, Result{}); err != nil {
+		return nil, fmt.Errorf("result template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// FormatResultsTemplate renders each result with tmpl and ends every
+// rendering with a newline, so each result is a line for tools such as fzf.
+// A result the template renders as nothing is left out, which lets a
+// template filter with {{if}}. On an execution error it returns the results
+// rendered before it.
+func FormatResultsTemplate(results []Result, tmpl *template.Template) (string, error) {
+	var sb strings.Builder
+	for _, r := range results {
+		start := sb.Len()
+		if err := tmpl.Execute(&sb, r); err != nil {
+			return sb.String()[:start], fmt.Errorf("result template: %w", err)
+		}
+		if sb.Len() > start && !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,43 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatResultsTemplate(t *testing.T) {
+	results := []Result{
+		{RelativePath: "a.go", StartLine: 3, Score: 0.912, Language: "go", Content: "func A() {\n\treturn\n}"},
+		{RelativePath: "b.py", StartLine: 10, Score: 0.5, Language: "python", Tags: []string{"api", "v2"}},
+	}
+
+	tmpl, err := ParseResultTemplate(`{{.RelativePath}}:{{.StartLine}} {{printf "%.2f" .Score}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := FormatResultsTemplate(results, tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a.go:3 0.91\nb.py:10 0.50\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// Empty renderings are skipped; helpers are available.
+	tmpl, err = ParseResultTemplate(`{{if eq .Language "go"}}{{oneline .Content}}{{end}}{{if .Tags}}{{join .Tags ","}}{{"\n"}}{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := FormatResultsTemplate(results, tmpl); got != "func A() { return }\napi,v2\n" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestParseResultTemplateRejectsUnknownFields(t *testing.T) {
+	if _, err := ParseResultTemplate(`{{.Path}}`); err == nil || !strings.Contains(err.Error(), "Path") {
+		t.Fatalf("err = %v, want an error naming the unknown field", err)
+	}
+	if _, err := ParseResultTemplate(`{{.RelativePath`); err == nil {
+		t.Fatal("want a parse error")
+	}
+}
@@ -0,0 +1,206 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// DefaultTranslatorTimeout bounds one translation request when
+// search.translator_timeout is unset.
+const DefaultTranslatorTimeout = 5 * time.Second
+
+// queryStopwords are short function words that identify a query's language.
+// Code identifiers rarely collide with them, so two or more hits are a
+// reliable signal even in mixed queries like "dónde se valida el JWT".
+var queryStopwords = map[string][]string{
+	"en": {"the", "and", "how", "where", "what", "which", "when", "is", "are", "does", "do", "of", "to", "in", "for", "with", "from", "that", "this"},
+	"es": {"el", "la", "los", "las", "de", "del", "que", "cómo", "como", "dónde", "donde", "cuándo", "qué", "para", "con", "por", "una", "un", "se", "es", "en", "y", "al"},
+	"de": {"der", "die", "das", "und", "wie", "wo", "wann", "was", "wird", "werden", "ist", "mit", "für", "von", "den", "dem", "ein", "eine", "nicht", "auf", "im"},
+	"fr": {"le", "la", "les", "des", "du", "et", "comment", "où", "quand", "est", "sont", "pour", "avec", "une", "un", "qui", "dans", "sur", "au"},
+	"pt": {"o", "a", "os", "as", "do", "da", "dos", "das", "que", "como", "onde", "quando", "para", "com", "por", "uma", "um", "é", "em", "e", "não", "no", "na"},
+	"it": {"il", "lo", "gli", "della", "del", "che", "come", "dove", "quando", "per", "con", "una", "un", "è", "sono", "nel", "non"},
+}
+
+// queryLetterHints are letters used by one language only among those above.
+var queryLetterHints = map[rune]string{
+	'ñ': "es", '¿': "es", '¡': "es",
+	'ä': "de", 'ö': "de", 'ü': "de", 'ß': "de",
+	'ç': "fr", 'û': "fr", 'ë': "fr",
+	'ã': "pt", 'õ': "pt",
+	'ì': "it", 'ò': "it",
+}
+
+// DetectQueryLanguage guesses the natural language of a search query and
+// returns an ISO 639-1 code such as "en", "es", or "de". Queries in
+// non-Latin scripts are identified by script ("zh", "ja", "ko", "ru", "ar").
+// It returns "" when the query carries too little prose to tell, which is
+// the case for most identifier-only queries.
+func DetectQueryLanguage(query string) string {
+	scores := make(map[string]int)
+	scripts := make(map[string]int)
+	for _, r := range strings.ToLower(query) {
+		switch {
+		case unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r):
+			scripts["ja"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["ko"]++
+		case unicode.Is(unicode.Han, r):
+			scripts["zh"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["ru"]++
+		case unicode.Is(unicode.Arabic, r):
+			scripts["ar"]++
+		}
+		if lang, ok := queryLetterHints[r]; ok {
+			scores[lang] += 2
+		}
+	}
+	// Kana is the only way to tell Japanese from Chinese; any kana wins.
+	if scripts["ja"] > 0 {
+		return "ja"
+	}
+	if lang := topLanguage(scripts, 1); lang != "" {
+		return lang
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		for lang, stopwords := range queryStopwords {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					scores[lang]++
+					break
+				}
+			}
+		}
+	}
+	return topLanguage(scores, 2)
+}
+
+// topLanguage returns the highest-scoring language when it reaches minScore
+// and is not tied.
+func topLanguage(scores map[string]int, minScore int) string {
+	best, bestScore, tied := "", 0, false
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = lang, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if bestScore < minScore || tied {
+		return ""
+	}
+	return best
+}
+
+// QueryTranslation records a query rewritten into English before search.
+type QueryTranslation struct {
+	Original   string `json:"original"`
+	Translated string `json:"translated"`
+	Language   string `json:"language"`
+}
+
+// HTTPTranslator translates non-English queries through a service speaking
+// the LibreTranslate API, configured by search.translator_url. A local
+// LibreTranslate server keeps queries on the machine; hosted services with the
+// same request shape work too. It POSTs:
+//
+//	{"q": "dónde se valida el token", "source": "es", "target": "en", "format": "text"}
+//
+// and expects a 2xx response:
+//
+//	{"translatedText": "where the token is validated"}
+type HTTPTranslator struct {
+	url    string
+	client *http.Client
+}
+
+type translateRequest struct {
+	Q      string `json:"q"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Format string `json:"format"`
+}
+
+type translateResponse struct {
+	TranslatedText string `json:"translatedText"`
+}
+
+// NewHTTPTranslator returns a translator for url, or nil when url is empty so
+// callers can skip translation with a nil check.
+func NewHTTPTranslator(url string, timeout time.Duration) *HTTPTranslator {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return nil
+	}
+	if timeout <= 0 {
+		timeout = DefaultTranslatorTimeout
+	}
+	return &HTTPTranslator{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Translate returns query translated from source into English.
+func (t *HTTPTranslator) Translate(ctx context.Context, query, source string) (string, error) {
+	body, err := json.Marshal(translateRequest{Q: query, Source: source, Target: "en", Format: "text"})
+	if err != nil {
+		return "", fmt.Errorf("marshal translate request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("create translate request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := t.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("translate request: %w", err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(httpResp.Body, 512))
+		return "", fmt.Errorf("translator returned %s: %s", httpResp.Status, strings.TrimSpace(string(snippet)))
+	}
+	var resp translateResponse
+	if err := json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return "", fmt.Errorf("decode translate response: %w", err)
+	}
+	translated := strings.TrimSpace(resp.TranslatedText)
+	if translated == "" {
+		return "", fmt.Errorf("translator returned an empty translation")
+	}
+	return translated, nil
+}
+
+// TranslateQuery rewrites a non-English query into English with t. Inline
+// filters must already be stripped from query. A nil translator, an English
+// or undetected query, or a failed request returns query unchanged; a failure
+// also returns a warning for the caller to surface alongside other
+// degraded-mode diagnostics.
+func TranslateQuery(ctx context.Context, t *HTTPTranslator, query string) (string, *QueryTranslation, string) {
+	if t == nil {
+		return query, nil, ""
+	}
+	lang := DetectQueryLanguage(query)
+	if lang == "" || lang == "en" {
+		return query, nil, ""
+	}
+	translated, err := t.Translate(ctx, query, lang)
+	if err != nil {
+		return query, nil, fmt.Sprintf("query translation unavailable, searched the original query: %v", err)
+	}
+	if translated == query {
+		return query, nil, ""
+	}
+	return translated, &QueryTranslation{Original: query, Translated: translated, Language: lang}, ""
+}
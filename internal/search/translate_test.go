@@ -0,0 +1,87 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDetectQueryLanguage(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"where is the token validated", "en"},
+		{"dónde se valida el token JWT", "es"},
+		{"cómo se reintenta la conexión", "es"},
+		{"wo wird die Konfiguration geladen", "de"},
+		{"Größe prüfen", "de"},
+		{"comment est validé le jeton", "fr"},
+		{"como o token é validado", "pt"},
+		{"dove viene letto il file di configurazione", "it"},
+		{"トークンを検証する場所", "ja"},
+		{"验证令牌", "zh"},
+		{"где проверяется токен", "ru"},
+		{"retry backoff", ""},
+		{"parseConfig", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectQueryLanguage(tt.query); got != tt.want {
+			t.Errorf("DetectQueryLanguage(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateQuerySendsDetectedLanguage(t *testing.T) {
+	var got translateRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"translatedText":" where the token is validated "}`))
+	}))
+	defer server.Close()
+
+	translator := NewHTTPTranslator(server.URL, 0)
+	query, translation, warning := TranslateQuery(context.Background(), translator, "dónde se valida el token")
+	if warning != "" {
+		t.Fatalf("unexpected warning: %s", warning)
+	}
+	if got.Q != "dónde se valida el token" || got.Source != "es" || got.Target != "en" || got.Format != "text" {
+		t.Fatalf("request = %+v", got)
+	}
+	if query != "where the token is validated" {
+		t.Fatalf("query = %q", query)
+	}
+	if translation == nil || translation.Language != "es" || translation.Original != "dónde se valida el token" {
+		t.Fatalf("translation = %+v", translation)
+	}
+}
+
+func TestTranslateQuerySkipsEnglishAndFailures(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "model not loaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	translator := NewHTTPTranslator(server.URL, 0)
+
+	query, translation, warning := TranslateQuery(context.Background(), translator, "where is the token validated")
+	if query != "where is the token validated" || translation != nil || warning != "" || calls != 0 {
+		t.Fatalf("English query: query=%q translation=%+v warning=%q calls=%d", query, translation, warning, calls)
+	}
+
+	query, translation, warning = TranslateQuery(context.Background(), translator, "wo wird die Konfiguration geladen")
+	if query != "wo wird die Konfiguration geladen" || translation != nil || !strings.Contains(warning, "model not loaded") {
+		t.Fatalf("failed translation: query=%q translation=%+v warning=%q", query, translation, warning)
+	}
+
+	if NewHTTPTranslator("  ", 0) != nil {
+		t.Fatal("empty URL should disable translation")
+	}
+}
@@ -257,7 +257,7 @@ func NewModel(ctx context.Context, startDir string) Model {
 		effectiveMode:  search.SearchModeHybrid,
 		limit:          10,
 		languages:      []string{"", "go", "python", "javascript", "typescript", "rust", "markdown"},
-		types:          []string{"", "function", "class", "block", "comment", "generic"},
+		types:          []string{"", "function", "class", "interface", "const", "config", "block", "comment", "generic"},
 		sessionLoading: true,
 		historyIdx:     -1,
 	}
@@ -415,6 +415,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		m.pushQueryHistory(msg.query)
 		m.statusMessage = fmt.Sprintf("%d results in %s", len(m.results), msg.response.Duration.Round(time.Millisecond))
+		if msg.response.Partial {
+			m.statusMessage += " (partial)"
+		}
 		if len(m.warnings) > 0 {
 			m.statusMessage += "  " + m.warnings[0]
 		}
@@ -745,6 +745,15 @@ func (m Model) configBody() string {
 			fmt.Sprintf("embedding.voyage_api_key: %s", secretStatus(cfg.Embedding.VoyageAPIKey)),
 			fmt.Sprintf("embedding.voyage_base_url: %s", cfg.Embedding.VoyageBaseURL),
 		)
+	case "tei":
+		lines = append(lines,
+			fmt.Sprintf("embedding.tei_url: %s", cfg.Embedding.TEIURL),
+			fmt.Sprintf("embedding.tei_api_key: %s", secretStatus(cfg.Embedding.TEIAPIKey)),
+		)
+	case "exec":
+		lines = append(lines, fmt.Sprintf("embedding.exec_command: %s", cfg.Embedding.ExecCommand))
+	case "onnx":
+		lines = append(lines, fmt.Sprintf("embedding.onnx_model_dir: %s", cfg.Embedding.ONNXModelDir))
 	}
 	lines = append(lines,
 		"",